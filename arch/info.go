@@ -19,7 +19,9 @@ package arch
 
 import (
 	"fmt"
+	"iter"
 	"runtime"
+	"sort"
 	"strings"
 )
 
@@ -31,6 +33,57 @@ type Info struct {
 	SyscallNames   map[string]int // Mapping of syscall names to numbers.
 	SyscallNumbers map[int]string // Mapping of syscall numbers to names.
 	SeccompMask    int            // A mask to apply to syscall numbers in BPF instructions (e.g. X32_SYSCALL_BIT).
+
+	syscalls       []syscallEntry // Sorted by number.
+	syscallsByName []syscallEntry // Sorted by name.
+}
+
+// syscallEntry is an entry of the generated syscall tables.
+type syscallEntry struct {
+	num  int
+	name string
+}
+
+// SyscallNumber returns the number of the named syscall, without the
+// SeccompMask. It returns false if the arch does not have the syscall.
+func (i *Info) SyscallNumber(name string) (int, bool) {
+	n := sort.Search(len(i.syscallsByName), func(j int) bool {
+		return i.syscallsByName[j].name >= name
+	})
+	if n < len(i.syscallsByName) && i.syscallsByName[n].name == name {
+		return i.syscallsByName[n].num, true
+	}
+	return 0, false
+}
+
+// SyscallName returns the name of the syscall number, without the
+// SeccompMask, or an empty string if the number is unknown.
+func (i *Info) SyscallName(nr int) string {
+	n := sort.Search(len(i.syscalls), func(j int) bool {
+		return i.syscalls[j].num >= nr
+	})
+	if n < len(i.syscalls) && i.syscalls[n].num == nr {
+		return i.syscalls[n].name
+	}
+	return ""
+}
+
+// Syscalls returns an iterator over the numbers and names of the syscalls of
+// the arch in ascending order of the numbers.
+func (i *Info) Syscalls() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for _, s := range i.syscalls {
+			if !yield(s.num, s.name) {
+				return
+			}
+		}
+	}
+}
+
+// NumSyscalls returns the number of syscalls in the tables of the arch. It
+// is zero for arches that are not fully implemented.
+func (i *Info) NumSyscalls() int {
+	return len(i.syscalls)
 }
 
 // Linux architecture types.
@@ -38,20 +91,26 @@ var (
 	ARM = &Info{
 		Name:           "arm",
 		ID:             auditArchARM,
-		SyscallNumbers: syscallsARM,
-		SyscallNames:   invert(syscallsARM),
+		SyscallNumbers: numbers(syscallsARM),
+		SyscallNames:   names(syscallsARM),
+		syscalls:       syscallsARM,
+		syscallsByName: syscallsByNameARM,
 	}
 	AARCH64 = &Info{
 		Name:           "aarch64",
 		ID:             auditArchAARCH64,
-		SyscallNumbers: syscallsAARCH64,
-		SyscallNames:   invert(syscallsAARCH64),
+		SyscallNumbers: numbers(syscallsAARCH64),
+		SyscallNames:   names(syscallsAARCH64),
+		syscalls:       syscallsAARCH64,
+		syscallsByName: syscallsByNameAARCH64,
 	}
 	I386 = &Info{
 		Name:           "i386",
 		ID:             auditArchI386,
-		SyscallNumbers: syscalls386,
-		SyscallNames:   invert(syscalls386),
+		SyscallNumbers: numbers(syscalls386),
+		SyscallNames:   names(syscalls386),
+		syscalls:       syscalls386,
+		syscallsByName: syscallsByName386,
 	}
 	X32 = &Info{
 		// Not a valid GOARCH, but an amd64 binary can use the 32-bit ABI so
@@ -59,14 +118,18 @@ var (
 		Name:           "x32",
 		ID:             auditArchX86_64,
 		SeccompMask:    x32SyscallMask,
-		SyscallNumbers: syscallsX32,
-		SyscallNames:   invert(syscallsX32),
+		SyscallNumbers: numbers(syscallsX32),
+		SyscallNames:   names(syscallsX32),
+		syscalls:       syscallsX32,
+		syscallsByName: syscallsByNameX32,
 	}
 	X86_64 = &Info{
 		Name:           "x86_64",
 		ID:             auditArchX86_64,
-		SyscallNumbers: syscallsX86_64,
-		SyscallNames:   invert(syscallsX86_64),
+		SyscallNumbers: numbers(syscallsX86_64),
+		SyscallNames:   names(syscallsX86_64),
+		syscalls:       syscallsX86_64,
+		syscallsByName: syscallsByNameX86_64,
 	}
 
 	// The following architectures are not fully implemented. Syscall tables
@@ -117,11 +180,20 @@ var (
 	}
 )
 
-// invert a map[int]string to map[string]int.
-func invert(in map[int]string) map[string]int {
-	out := make(map[string]int, len(in))
-	for k, v := range in {
-		out[v] = k
+// numbers returns a mapping of the syscall numbers to names of the table.
+func numbers(syscalls []syscallEntry) map[int]string {
+	out := make(map[int]string, len(syscalls))
+	for _, s := range syscalls {
+		out[s.num] = s.name
+	}
+	return out
+}
+
+// names returns a mapping of the syscall names to numbers of the table.
+func names(syscalls []syscallEntry) map[string]int {
+	out := make(map[string]int, len(syscalls))
+	for _, s := range syscalls {
+		out[s.name] = s.num
 	}
 	return out
 }
@@ -169,7 +241,7 @@ func GetInfo(name string) (*Info, error) {
 	}
 
 	arch, found := arches[name]
-	if !found || len(arch.SyscallNames) == 0 {
+	if !found || arch.NumSyscalls() == 0 {
 		return nil, fmt.Errorf("unsupported arch: %v", name)
 	}
 	return arch, nil
@@ -0,0 +1,102 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package arch
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSyscallLookupAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		X86_64.SyscallNumber("write")
+		X86_64.SyscallName(1)
+	})
+	if allocs != 0 {
+		t.Errorf("lookups allocate %v times", allocs)
+	}
+}
+
+func TestSyscallTables(t *testing.T) {
+	for _, info := range []*Info{ARM, AARCH64, I386, X32, X86_64} {
+		t.Run(info.Name, func(t *testing.T) {
+			if info.NumSyscalls() == 0 {
+				t.Fatal("empty syscall table")
+			}
+			if !sort.SliceIsSorted(info.syscalls, func(i, j int) bool {
+				return info.syscalls[i].num < info.syscalls[j].num
+			}) {
+				t.Error("syscalls are not sorted by number")
+			}
+			if !sort.SliceIsSorted(info.syscallsByName, func(i, j int) bool {
+				return info.syscallsByName[i].name < info.syscallsByName[j].name
+			}) {
+				t.Error("syscalls are not sorted by name")
+			}
+			if len(info.SyscallNumbers) != info.NumSyscalls() {
+				t.Errorf("%d syscall numbers, want %d", len(info.SyscallNumbers), info.NumSyscalls())
+			}
+			if len(info.syscallsByName) != info.NumSyscalls() {
+				t.Errorf("%d syscalls by name, want %d", len(info.syscallsByName), info.NumSyscalls())
+			}
+
+			for nr, name := range info.Syscalls() {
+				if got := info.SyscallName(nr); got != name {
+					t.Errorf("SyscallName(%d) = %q, want %q", nr, got, name)
+				}
+				if got, found := info.SyscallNumber(name); !found || got != nr {
+					t.Errorf("SyscallNumber(%q) = %d, %v, want %d", name, got, found, nr)
+				}
+				if info.SyscallNumbers[nr] != name || info.SyscallNames[name] != nr {
+					t.Errorf("maps disagree for %d %q", nr, name)
+				}
+			}
+		})
+	}
+}
+
+func TestSyscallNumberUnknown(t *testing.T) {
+	if nr, found := X86_64.SyscallNumber("no_such_syscall"); found {
+		t.Errorf("found unknown syscall as %d", nr)
+	}
+	if name := X86_64.SyscallName(-1); name != "" {
+		t.Errorf("found unknown syscall number as %q", name)
+	}
+	if _, found := PPC.SyscallNumber("read"); found {
+		t.Error("found syscall on arch without tables")
+	}
+}
+
+func TestSyscallNumberX32(t *testing.T) {
+	// The x32 ABI has its own numbers for syscalls whose arguments differ
+	// from the 64-bit ABI.
+	nr, found := X32.SyscallNumber("execve")
+	if !found || nr != 520 {
+		t.Errorf("SyscallNumber(execve) = %d, %v, want 520", nr, found)
+	}
+	if name := X32.SyscallName(59); name != "" {
+		t.Errorf("SyscallName(59) = %q, want no syscall", name)
+	}
+}
+
+func BenchmarkSyscallNumber(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		X86_64.SyscallNumber("write")
+	}
+}
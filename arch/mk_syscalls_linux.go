@@ -49,8 +49,9 @@ type TemplateParams struct {
 
 // Arch contains all the syscalls for a single architecture.
 type Arch struct {
-	Name     string
-	Syscalls []*Syscall
+	Name           string
+	Syscalls       []*Syscall // Sorted by number.
+	SyscallsByName []*Syscall // Sorted by name.
 }
 
 // Syscall represents a single system call.
@@ -82,9 +83,15 @@ package arch
 
 // Based on Linux {{ .LinuxVersion }}.
 {{ range $arch := .Arches }}
-var syscalls{{ $arch.Name }} = map[int]string{
+var syscalls{{ $arch.Name }} = []syscallEntry{
 {{- range $s := $arch.Syscalls }}
-	{{ $s.Num }}: "{{ $s.Name }}",
+	{ {{- $s.Num }}, "{{ $s.Name }}"},
+{{- end }}
+}
+
+var syscallsByName{{ $arch.Name }} = []syscallEntry{
+{{- range $s := $arch.SyscallsByName }}
+	{ {{- $s.Num }}, "{{ $s.Name }}"},
 {{- end }}
 }
 {{ end }}
@@ -262,7 +269,8 @@ func buildX32(dir string) (*Arch, error) {
 			return nil, fmt.Errorf("unexpected line format: %v", line)
 		}
 
-		if fields[1] == "x64" {
+		// Filter out the 64-bit ABI, which has separate x32 syscalls.
+		if fields[1] == "64" {
 			return nil, nil
 		}
 
@@ -426,6 +434,10 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
+		arch.SyscallsByName = append([]*Syscall(nil), arch.Syscalls...)
+		sort.Slice(arch.SyscallsByName, func(i, j int) bool {
+			return arch.SyscallsByName[i].Name < arch.SyscallsByName[j].Name
+		})
 		params.Arches = append(params.Arches, *arch)
 	}
 
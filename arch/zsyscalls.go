@@ -21,2040 +21,4006 @@ package arch
 
 // Based on Linux v6.15.
 
-var syscallsARM = map[int]string{
-	0:      "restart_syscall",
-	1:      "exit",
-	2:      "fork",
-	3:      "read",
-	4:      "write",
-	5:      "open",
-	6:      "close",
-	8:      "creat",
-	9:      "link",
-	10:     "unlink",
-	11:     "execve",
-	12:     "chdir",
-	14:     "mknod",
-	15:     "chmod",
-	16:     "lchown",
-	19:     "lseek",
-	20:     "getpid",
-	21:     "mount",
-	23:     "setuid",
-	24:     "getuid",
-	26:     "ptrace",
-	29:     "pause",
-	33:     "access",
-	34:     "nice",
-	36:     "sync",
-	37:     "kill",
-	38:     "rename",
-	39:     "mkdir",
-	40:     "rmdir",
-	41:     "dup",
-	42:     "pipe",
-	43:     "times",
-	45:     "brk",
-	46:     "setgid",
-	47:     "getgid",
-	49:     "geteuid",
-	50:     "getegid",
-	51:     "acct",
-	52:     "umount2",
-	54:     "ioctl",
-	55:     "fcntl",
-	57:     "setpgid",
-	60:     "umask",
-	61:     "chroot",
-	62:     "ustat",
-	63:     "dup2",
-	64:     "getppid",
-	65:     "getpgrp",
-	66:     "setsid",
-	67:     "sigaction",
-	70:     "setreuid",
-	71:     "setregid",
-	72:     "sigsuspend",
-	73:     "sigpending",
-	74:     "sethostname",
-	75:     "setrlimit",
-	77:     "getrusage",
-	78:     "gettimeofday",
-	79:     "settimeofday",
-	80:     "getgroups",
-	81:     "setgroups",
-	83:     "symlink",
-	85:     "readlink",
-	86:     "uselib",
-	87:     "swapon",
-	88:     "reboot",
-	91:     "munmap",
-	92:     "truncate",
-	93:     "ftruncate",
-	94:     "fchmod",
-	95:     "fchown",
-	96:     "getpriority",
-	97:     "setpriority",
-	99:     "statfs",
-	100:    "fstatfs",
-	103:    "syslog",
-	104:    "setitimer",
-	105:    "getitimer",
-	106:    "stat",
-	107:    "lstat",
-	108:    "fstat",
-	111:    "vhangup",
-	114:    "wait4",
-	115:    "swapoff",
-	116:    "sysinfo",
-	118:    "fsync",
-	119:    "sigreturn",
-	120:    "clone",
-	121:    "setdomainname",
-	122:    "uname",
-	124:    "adjtimex",
-	125:    "mprotect",
-	126:    "sigprocmask",
-	128:    "init_module",
-	129:    "delete_module",
-	131:    "quotactl",
-	132:    "getpgid",
-	133:    "fchdir",
-	134:    "bdflush",
-	135:    "sysfs",
-	136:    "personality",
-	138:    "setfsuid",
-	139:    "setfsgid",
-	140:    "_llseek",
-	141:    "getdents",
-	142:    "_newselect",
-	143:    "flock",
-	144:    "msync",
-	145:    "readv",
-	146:    "writev",
-	147:    "getsid",
-	148:    "fdatasync",
-	149:    "_sysctl",
-	150:    "mlock",
-	151:    "munlock",
-	152:    "mlockall",
-	153:    "munlockall",
-	154:    "sched_setparam",
-	155:    "sched_getparam",
-	156:    "sched_setscheduler",
-	157:    "sched_getscheduler",
-	158:    "sched_yield",
-	159:    "sched_get_priority_max",
-	160:    "sched_get_priority_min",
-	161:    "sched_rr_get_interval",
-	162:    "nanosleep",
-	163:    "mremap",
-	164:    "setresuid",
-	165:    "getresuid",
-	168:    "poll",
-	169:    "nfsservctl",
-	170:    "setresgid",
-	171:    "getresgid",
-	172:    "prctl",
-	173:    "rt_sigreturn",
-	174:    "rt_sigaction",
-	175:    "rt_sigprocmask",
-	176:    "rt_sigpending",
-	177:    "rt_sigtimedwait",
-	178:    "rt_sigqueueinfo",
-	179:    "rt_sigsuspend",
-	180:    "pread64",
-	181:    "pwrite64",
-	182:    "chown",
-	183:    "getcwd",
-	184:    "capget",
-	185:    "capset",
-	186:    "sigaltstack",
-	187:    "sendfile",
-	190:    "vfork",
-	191:    "ugetrlimit",
-	192:    "mmap2",
-	193:    "truncate64",
-	194:    "ftruncate64",
-	195:    "stat64",
-	196:    "lstat64",
-	197:    "fstat64",
-	198:    "lchown32",
-	199:    "getuid32",
-	200:    "getgid32",
-	201:    "geteuid32",
-	202:    "getegid32",
-	203:    "setreuid32",
-	204:    "setregid32",
-	205:    "getgroups32",
-	206:    "setgroups32",
-	207:    "fchown32",
-	208:    "setresuid32",
-	209:    "getresuid32",
-	210:    "setresgid32",
-	211:    "getresgid32",
-	212:    "chown32",
-	213:    "setuid32",
-	214:    "setgid32",
-	215:    "setfsuid32",
-	216:    "setfsgid32",
-	217:    "getdents64",
-	218:    "pivot_root",
-	219:    "mincore",
-	220:    "madvise",
-	221:    "fcntl64",
-	224:    "gettid",
-	225:    "readahead",
-	226:    "setxattr",
-	227:    "lsetxattr",
-	228:    "fsetxattr",
-	229:    "getxattr",
-	230:    "lgetxattr",
-	231:    "fgetxattr",
-	232:    "listxattr",
-	233:    "llistxattr",
-	234:    "flistxattr",
-	235:    "removexattr",
-	236:    "lremovexattr",
-	237:    "fremovexattr",
-	238:    "tkill",
-	239:    "sendfile64",
-	240:    "futex",
-	241:    "sched_setaffinity",
-	242:    "sched_getaffinity",
-	243:    "io_setup",
-	244:    "io_destroy",
-	245:    "io_getevents",
-	246:    "io_submit",
-	247:    "io_cancel",
-	248:    "exit_group",
-	249:    "lookup_dcookie",
-	250:    "epoll_create",
-	251:    "epoll_ctl",
-	252:    "epoll_wait",
-	253:    "remap_file_pages",
-	256:    "set_tid_address",
-	257:    "timer_create",
-	258:    "timer_settime",
-	259:    "timer_gettime",
-	260:    "timer_getoverrun",
-	261:    "timer_delete",
-	262:    "clock_settime",
-	263:    "clock_gettime",
-	264:    "clock_getres",
-	265:    "clock_nanosleep",
-	266:    "statfs64",
-	267:    "fstatfs64",
-	268:    "tgkill",
-	269:    "utimes",
-	270:    "arm_fadvise64_64",
-	271:    "pciconfig_iobase",
-	272:    "pciconfig_read",
-	273:    "pciconfig_write",
-	274:    "mq_open",
-	275:    "mq_unlink",
-	276:    "mq_timedsend",
-	277:    "mq_timedreceive",
-	278:    "mq_notify",
-	279:    "mq_getsetattr",
-	280:    "waitid",
-	281:    "socket",
-	282:    "bind",
-	283:    "connect",
-	284:    "listen",
-	285:    "accept",
-	286:    "getsockname",
-	287:    "getpeername",
-	288:    "socketpair",
-	289:    "send",
-	290:    "sendto",
-	291:    "recv",
-	292:    "recvfrom",
-	293:    "shutdown",
-	294:    "setsockopt",
-	295:    "getsockopt",
-	296:    "sendmsg",
-	297:    "recvmsg",
-	298:    "semop",
-	299:    "semget",
-	300:    "semctl",
-	301:    "msgsnd",
-	302:    "msgrcv",
-	303:    "msgget",
-	304:    "msgctl",
-	305:    "shmat",
-	306:    "shmdt",
-	307:    "shmget",
-	308:    "shmctl",
-	309:    "add_key",
-	310:    "request_key",
-	311:    "keyctl",
-	312:    "semtimedop",
-	313:    "vserver",
-	314:    "ioprio_set",
-	315:    "ioprio_get",
-	316:    "inotify_init",
-	317:    "inotify_add_watch",
-	318:    "inotify_rm_watch",
-	319:    "mbind",
-	320:    "get_mempolicy",
-	321:    "set_mempolicy",
-	322:    "openat",
-	323:    "mkdirat",
-	324:    "mknodat",
-	325:    "fchownat",
-	326:    "futimesat",
-	327:    "fstatat64",
-	328:    "unlinkat",
-	329:    "renameat",
-	330:    "linkat",
-	331:    "symlinkat",
-	332:    "readlinkat",
-	333:    "fchmodat",
-	334:    "faccessat",
-	335:    "pselect6",
-	336:    "ppoll",
-	337:    "unshare",
-	338:    "set_robust_list",
-	339:    "get_robust_list",
-	340:    "splice",
-	341:    "arm_sync_file_range",
-	342:    "tee",
-	343:    "vmsplice",
-	344:    "move_pages",
-	345:    "getcpu",
-	346:    "epoll_pwait",
-	347:    "kexec_load",
-	348:    "utimensat",
-	349:    "signalfd",
-	350:    "timerfd_create",
-	351:    "eventfd",
-	352:    "fallocate",
-	353:    "timerfd_settime",
-	354:    "timerfd_gettime",
-	355:    "signalfd4",
-	356:    "eventfd2",
-	357:    "epoll_create1",
-	358:    "dup3",
-	359:    "pipe2",
-	360:    "inotify_init1",
-	361:    "preadv",
-	362:    "pwritev",
-	363:    "rt_tgsigqueueinfo",
-	364:    "perf_event_open",
-	365:    "recvmmsg",
-	366:    "accept4",
-	367:    "fanotify_init",
-	368:    "fanotify_mark",
-	369:    "prlimit64",
-	370:    "name_to_handle_at",
-	371:    "open_by_handle_at",
-	372:    "clock_adjtime",
-	373:    "syncfs",
-	374:    "sendmmsg",
-	375:    "setns",
-	376:    "process_vm_readv",
-	377:    "process_vm_writev",
-	378:    "kcmp",
-	379:    "finit_module",
-	380:    "sched_setattr",
-	381:    "sched_getattr",
-	382:    "renameat2",
-	383:    "seccomp",
-	384:    "getrandom",
-	385:    "memfd_create",
-	386:    "bpf",
-	387:    "execveat",
-	388:    "userfaultfd",
-	389:    "membarrier",
-	390:    "mlock2",
-	391:    "copy_file_range",
-	392:    "preadv2",
-	393:    "pwritev2",
-	394:    "pkey_mprotect",
-	395:    "pkey_alloc",
-	396:    "pkey_free",
-	397:    "statx",
-	398:    "rseq",
-	399:    "io_pgetevents",
-	400:    "migrate_pages",
-	401:    "kexec_file_load",
-	403:    "clock_gettime64",
-	404:    "clock_settime64",
-	405:    "clock_adjtime64",
-	406:    "clock_getres_time64",
-	407:    "clock_nanosleep_time64",
-	408:    "timer_gettime64",
-	409:    "timer_settime64",
-	410:    "timerfd_gettime64",
-	411:    "timerfd_settime64",
-	412:    "utimensat_time64",
-	413:    "pselect6_time64",
-	414:    "ppoll_time64",
-	416:    "io_pgetevents_time64",
-	417:    "recvmmsg_time64",
-	418:    "mq_timedsend_time64",
-	419:    "mq_timedreceive_time64",
-	420:    "semtimedop_time64",
-	421:    "rt_sigtimedwait_time64",
-	422:    "futex_time64",
-	423:    "sched_rr_get_interval_time64",
-	424:    "pidfd_send_signal",
-	425:    "io_uring_setup",
-	426:    "io_uring_enter",
-	427:    "io_uring_register",
-	428:    "open_tree",
-	429:    "move_mount",
-	430:    "fsopen",
-	431:    "fsconfig",
-	432:    "fsmount",
-	433:    "fspick",
-	434:    "pidfd_open",
-	435:    "clone3",
-	436:    "close_range",
-	437:    "openat2",
-	438:    "pidfd_getfd",
-	439:    "faccessat2",
-	440:    "process_madvise",
-	441:    "epoll_pwait2",
-	442:    "mount_setattr",
-	443:    "quotactl_fd",
-	444:    "landlock_create_ruleset",
-	445:    "landlock_add_rule",
-	446:    "landlock_restrict_self",
-	448:    "process_mrelease",
-	449:    "futex_waitv",
-	450:    "set_mempolicy_home_node",
-	451:    "cachestat",
-	452:    "fchmodat2",
-	453:    "map_shadow_stack",
-	454:    "futex_wake",
-	455:    "futex_wait",
-	456:    "futex_requeue",
-	457:    "statmount",
-	458:    "listmount",
-	459:    "lsm_get_self_attr",
-	460:    "lsm_set_self_attr",
-	461:    "lsm_list_modules",
-	462:    "mseal",
-	463:    "setxattrat",
-	464:    "getxattrat",
-	465:    "listxattrat",
-	466:    "removexattrat",
-	467:    "open_tree_attr",
-	983041: "breakpoint",
-	983042: "cacheflush",
-	983043: "usr26",
-	983044: "usr32",
-	983045: "set_tls",
-	983046: "get_tls",
+var syscallsARM = []syscallEntry{
+	{0, "restart_syscall"},
+	{1, "exit"},
+	{2, "fork"},
+	{3, "read"},
+	{4, "write"},
+	{5, "open"},
+	{6, "close"},
+	{8, "creat"},
+	{9, "link"},
+	{10, "unlink"},
+	{11, "execve"},
+	{12, "chdir"},
+	{14, "mknod"},
+	{15, "chmod"},
+	{16, "lchown"},
+	{19, "lseek"},
+	{20, "getpid"},
+	{21, "mount"},
+	{23, "setuid"},
+	{24, "getuid"},
+	{26, "ptrace"},
+	{29, "pause"},
+	{33, "access"},
+	{34, "nice"},
+	{36, "sync"},
+	{37, "kill"},
+	{38, "rename"},
+	{39, "mkdir"},
+	{40, "rmdir"},
+	{41, "dup"},
+	{42, "pipe"},
+	{43, "times"},
+	{45, "brk"},
+	{46, "setgid"},
+	{47, "getgid"},
+	{49, "geteuid"},
+	{50, "getegid"},
+	{51, "acct"},
+	{52, "umount2"},
+	{54, "ioctl"},
+	{55, "fcntl"},
+	{57, "setpgid"},
+	{60, "umask"},
+	{61, "chroot"},
+	{62, "ustat"},
+	{63, "dup2"},
+	{64, "getppid"},
+	{65, "getpgrp"},
+	{66, "setsid"},
+	{67, "sigaction"},
+	{70, "setreuid"},
+	{71, "setregid"},
+	{72, "sigsuspend"},
+	{73, "sigpending"},
+	{74, "sethostname"},
+	{75, "setrlimit"},
+	{77, "getrusage"},
+	{78, "gettimeofday"},
+	{79, "settimeofday"},
+	{80, "getgroups"},
+	{81, "setgroups"},
+	{83, "symlink"},
+	{85, "readlink"},
+	{86, "uselib"},
+	{87, "swapon"},
+	{88, "reboot"},
+	{91, "munmap"},
+	{92, "truncate"},
+	{93, "ftruncate"},
+	{94, "fchmod"},
+	{95, "fchown"},
+	{96, "getpriority"},
+	{97, "setpriority"},
+	{99, "statfs"},
+	{100, "fstatfs"},
+	{103, "syslog"},
+	{104, "setitimer"},
+	{105, "getitimer"},
+	{106, "stat"},
+	{107, "lstat"},
+	{108, "fstat"},
+	{111, "vhangup"},
+	{114, "wait4"},
+	{115, "swapoff"},
+	{116, "sysinfo"},
+	{118, "fsync"},
+	{119, "sigreturn"},
+	{120, "clone"},
+	{121, "setdomainname"},
+	{122, "uname"},
+	{124, "adjtimex"},
+	{125, "mprotect"},
+	{126, "sigprocmask"},
+	{128, "init_module"},
+	{129, "delete_module"},
+	{131, "quotactl"},
+	{132, "getpgid"},
+	{133, "fchdir"},
+	{134, "bdflush"},
+	{135, "sysfs"},
+	{136, "personality"},
+	{138, "setfsuid"},
+	{139, "setfsgid"},
+	{140, "_llseek"},
+	{141, "getdents"},
+	{142, "_newselect"},
+	{143, "flock"},
+	{144, "msync"},
+	{145, "readv"},
+	{146, "writev"},
+	{147, "getsid"},
+	{148, "fdatasync"},
+	{149, "_sysctl"},
+	{150, "mlock"},
+	{151, "munlock"},
+	{152, "mlockall"},
+	{153, "munlockall"},
+	{154, "sched_setparam"},
+	{155, "sched_getparam"},
+	{156, "sched_setscheduler"},
+	{157, "sched_getscheduler"},
+	{158, "sched_yield"},
+	{159, "sched_get_priority_max"},
+	{160, "sched_get_priority_min"},
+	{161, "sched_rr_get_interval"},
+	{162, "nanosleep"},
+	{163, "mremap"},
+	{164, "setresuid"},
+	{165, "getresuid"},
+	{168, "poll"},
+	{169, "nfsservctl"},
+	{170, "setresgid"},
+	{171, "getresgid"},
+	{172, "prctl"},
+	{173, "rt_sigreturn"},
+	{174, "rt_sigaction"},
+	{175, "rt_sigprocmask"},
+	{176, "rt_sigpending"},
+	{177, "rt_sigtimedwait"},
+	{178, "rt_sigqueueinfo"},
+	{179, "rt_sigsuspend"},
+	{180, "pread64"},
+	{181, "pwrite64"},
+	{182, "chown"},
+	{183, "getcwd"},
+	{184, "capget"},
+	{185, "capset"},
+	{186, "sigaltstack"},
+	{187, "sendfile"},
+	{190, "vfork"},
+	{191, "ugetrlimit"},
+	{192, "mmap2"},
+	{193, "truncate64"},
+	{194, "ftruncate64"},
+	{195, "stat64"},
+	{196, "lstat64"},
+	{197, "fstat64"},
+	{198, "lchown32"},
+	{199, "getuid32"},
+	{200, "getgid32"},
+	{201, "geteuid32"},
+	{202, "getegid32"},
+	{203, "setreuid32"},
+	{204, "setregid32"},
+	{205, "getgroups32"},
+	{206, "setgroups32"},
+	{207, "fchown32"},
+	{208, "setresuid32"},
+	{209, "getresuid32"},
+	{210, "setresgid32"},
+	{211, "getresgid32"},
+	{212, "chown32"},
+	{213, "setuid32"},
+	{214, "setgid32"},
+	{215, "setfsuid32"},
+	{216, "setfsgid32"},
+	{217, "getdents64"},
+	{218, "pivot_root"},
+	{219, "mincore"},
+	{220, "madvise"},
+	{221, "fcntl64"},
+	{224, "gettid"},
+	{225, "readahead"},
+	{226, "setxattr"},
+	{227, "lsetxattr"},
+	{228, "fsetxattr"},
+	{229, "getxattr"},
+	{230, "lgetxattr"},
+	{231, "fgetxattr"},
+	{232, "listxattr"},
+	{233, "llistxattr"},
+	{234, "flistxattr"},
+	{235, "removexattr"},
+	{236, "lremovexattr"},
+	{237, "fremovexattr"},
+	{238, "tkill"},
+	{239, "sendfile64"},
+	{240, "futex"},
+	{241, "sched_setaffinity"},
+	{242, "sched_getaffinity"},
+	{243, "io_setup"},
+	{244, "io_destroy"},
+	{245, "io_getevents"},
+	{246, "io_submit"},
+	{247, "io_cancel"},
+	{248, "exit_group"},
+	{249, "lookup_dcookie"},
+	{250, "epoll_create"},
+	{251, "epoll_ctl"},
+	{252, "epoll_wait"},
+	{253, "remap_file_pages"},
+	{256, "set_tid_address"},
+	{257, "timer_create"},
+	{258, "timer_settime"},
+	{259, "timer_gettime"},
+	{260, "timer_getoverrun"},
+	{261, "timer_delete"},
+	{262, "clock_settime"},
+	{263, "clock_gettime"},
+	{264, "clock_getres"},
+	{265, "clock_nanosleep"},
+	{266, "statfs64"},
+	{267, "fstatfs64"},
+	{268, "tgkill"},
+	{269, "utimes"},
+	{270, "arm_fadvise64_64"},
+	{271, "pciconfig_iobase"},
+	{272, "pciconfig_read"},
+	{273, "pciconfig_write"},
+	{274, "mq_open"},
+	{275, "mq_unlink"},
+	{276, "mq_timedsend"},
+	{277, "mq_timedreceive"},
+	{278, "mq_notify"},
+	{279, "mq_getsetattr"},
+	{280, "waitid"},
+	{281, "socket"},
+	{282, "bind"},
+	{283, "connect"},
+	{284, "listen"},
+	{285, "accept"},
+	{286, "getsockname"},
+	{287, "getpeername"},
+	{288, "socketpair"},
+	{289, "send"},
+	{290, "sendto"},
+	{291, "recv"},
+	{292, "recvfrom"},
+	{293, "shutdown"},
+	{294, "setsockopt"},
+	{295, "getsockopt"},
+	{296, "sendmsg"},
+	{297, "recvmsg"},
+	{298, "semop"},
+	{299, "semget"},
+	{300, "semctl"},
+	{301, "msgsnd"},
+	{302, "msgrcv"},
+	{303, "msgget"},
+	{304, "msgctl"},
+	{305, "shmat"},
+	{306, "shmdt"},
+	{307, "shmget"},
+	{308, "shmctl"},
+	{309, "add_key"},
+	{310, "request_key"},
+	{311, "keyctl"},
+	{312, "semtimedop"},
+	{313, "vserver"},
+	{314, "ioprio_set"},
+	{315, "ioprio_get"},
+	{316, "inotify_init"},
+	{317, "inotify_add_watch"},
+	{318, "inotify_rm_watch"},
+	{319, "mbind"},
+	{320, "get_mempolicy"},
+	{321, "set_mempolicy"},
+	{322, "openat"},
+	{323, "mkdirat"},
+	{324, "mknodat"},
+	{325, "fchownat"},
+	{326, "futimesat"},
+	{327, "fstatat64"},
+	{328, "unlinkat"},
+	{329, "renameat"},
+	{330, "linkat"},
+	{331, "symlinkat"},
+	{332, "readlinkat"},
+	{333, "fchmodat"},
+	{334, "faccessat"},
+	{335, "pselect6"},
+	{336, "ppoll"},
+	{337, "unshare"},
+	{338, "set_robust_list"},
+	{339, "get_robust_list"},
+	{340, "splice"},
+	{341, "arm_sync_file_range"},
+	{342, "tee"},
+	{343, "vmsplice"},
+	{344, "move_pages"},
+	{345, "getcpu"},
+	{346, "epoll_pwait"},
+	{347, "kexec_load"},
+	{348, "utimensat"},
+	{349, "signalfd"},
+	{350, "timerfd_create"},
+	{351, "eventfd"},
+	{352, "fallocate"},
+	{353, "timerfd_settime"},
+	{354, "timerfd_gettime"},
+	{355, "signalfd4"},
+	{356, "eventfd2"},
+	{357, "epoll_create1"},
+	{358, "dup3"},
+	{359, "pipe2"},
+	{360, "inotify_init1"},
+	{361, "preadv"},
+	{362, "pwritev"},
+	{363, "rt_tgsigqueueinfo"},
+	{364, "perf_event_open"},
+	{365, "recvmmsg"},
+	{366, "accept4"},
+	{367, "fanotify_init"},
+	{368, "fanotify_mark"},
+	{369, "prlimit64"},
+	{370, "name_to_handle_at"},
+	{371, "open_by_handle_at"},
+	{372, "clock_adjtime"},
+	{373, "syncfs"},
+	{374, "sendmmsg"},
+	{375, "setns"},
+	{376, "process_vm_readv"},
+	{377, "process_vm_writev"},
+	{378, "kcmp"},
+	{379, "finit_module"},
+	{380, "sched_setattr"},
+	{381, "sched_getattr"},
+	{382, "renameat2"},
+	{383, "seccomp"},
+	{384, "getrandom"},
+	{385, "memfd_create"},
+	{386, "bpf"},
+	{387, "execveat"},
+	{388, "userfaultfd"},
+	{389, "membarrier"},
+	{390, "mlock2"},
+	{391, "copy_file_range"},
+	{392, "preadv2"},
+	{393, "pwritev2"},
+	{394, "pkey_mprotect"},
+	{395, "pkey_alloc"},
+	{396, "pkey_free"},
+	{397, "statx"},
+	{398, "rseq"},
+	{399, "io_pgetevents"},
+	{400, "migrate_pages"},
+	{401, "kexec_file_load"},
+	{403, "clock_gettime64"},
+	{404, "clock_settime64"},
+	{405, "clock_adjtime64"},
+	{406, "clock_getres_time64"},
+	{407, "clock_nanosleep_time64"},
+	{408, "timer_gettime64"},
+	{409, "timer_settime64"},
+	{410, "timerfd_gettime64"},
+	{411, "timerfd_settime64"},
+	{412, "utimensat_time64"},
+	{413, "pselect6_time64"},
+	{414, "ppoll_time64"},
+	{416, "io_pgetevents_time64"},
+	{417, "recvmmsg_time64"},
+	{418, "mq_timedsend_time64"},
+	{419, "mq_timedreceive_time64"},
+	{420, "semtimedop_time64"},
+	{421, "rt_sigtimedwait_time64"},
+	{422, "futex_time64"},
+	{423, "sched_rr_get_interval_time64"},
+	{424, "pidfd_send_signal"},
+	{425, "io_uring_setup"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{428, "open_tree"},
+	{429, "move_mount"},
+	{430, "fsopen"},
+	{431, "fsconfig"},
+	{432, "fsmount"},
+	{433, "fspick"},
+	{434, "pidfd_open"},
+	{435, "clone3"},
+	{436, "close_range"},
+	{437, "openat2"},
+	{438, "pidfd_getfd"},
+	{439, "faccessat2"},
+	{440, "process_madvise"},
+	{441, "epoll_pwait2"},
+	{442, "mount_setattr"},
+	{443, "quotactl_fd"},
+	{444, "landlock_create_ruleset"},
+	{445, "landlock_add_rule"},
+	{446, "landlock_restrict_self"},
+	{448, "process_mrelease"},
+	{449, "futex_waitv"},
+	{450, "set_mempolicy_home_node"},
+	{451, "cachestat"},
+	{452, "fchmodat2"},
+	{453, "map_shadow_stack"},
+	{454, "futex_wake"},
+	{455, "futex_wait"},
+	{456, "futex_requeue"},
+	{457, "statmount"},
+	{458, "listmount"},
+	{459, "lsm_get_self_attr"},
+	{460, "lsm_set_self_attr"},
+	{461, "lsm_list_modules"},
+	{462, "mseal"},
+	{463, "setxattrat"},
+	{464, "getxattrat"},
+	{465, "listxattrat"},
+	{466, "removexattrat"},
+	{467, "open_tree_attr"},
+	{983041, "breakpoint"},
+	{983042, "cacheflush"},
+	{983043, "usr26"},
+	{983044, "usr32"},
+	{983045, "set_tls"},
+	{983046, "get_tls"},
 }
 
-var syscallsAARCH64 = map[int]string{
-	0:   "io_setup",
-	1:   "io_destroy",
-	2:   "io_submit",
-	3:   "io_cancel",
-	4:   "io_getevents",
-	5:   "setxattr",
-	6:   "lsetxattr",
-	7:   "fsetxattr",
-	8:   "getxattr",
-	9:   "lgetxattr",
-	10:  "fgetxattr",
-	11:  "listxattr",
-	12:  "llistxattr",
-	13:  "flistxattr",
-	14:  "removexattr",
-	15:  "lremovexattr",
-	16:  "fremovexattr",
-	17:  "getcwd",
-	18:  "lookup_dcookie",
-	19:  "eventfd2",
-	20:  "epoll_create1",
-	21:  "epoll_ctl",
-	22:  "epoll_pwait",
-	23:  "dup",
-	24:  "dup3",
-	25:  "fcntl",
-	26:  "inotify_init1",
-	27:  "inotify_add_watch",
-	28:  "inotify_rm_watch",
-	29:  "ioctl",
-	30:  "ioprio_set",
-	31:  "ioprio_get",
-	32:  "flock",
-	33:  "mknodat",
-	34:  "mkdirat",
-	35:  "unlinkat",
-	36:  "symlinkat",
-	37:  "linkat",
-	38:  "renameat",
-	39:  "umount2",
-	40:  "mount",
-	41:  "pivot_root",
-	42:  "nfsservctl",
-	43:  "statfs",
-	44:  "fstatfs",
-	45:  "truncate",
-	46:  "ftruncate",
-	47:  "fallocate",
-	48:  "faccessat",
-	49:  "chdir",
-	50:  "fchdir",
-	51:  "chroot",
-	52:  "fchmod",
-	53:  "fchmodat",
-	54:  "fchownat",
-	55:  "fchown",
-	56:  "openat",
-	57:  "close",
-	58:  "vhangup",
-	59:  "pipe2",
-	60:  "quotactl",
-	61:  "getdents64",
-	62:  "lseek",
-	63:  "read",
-	64:  "write",
-	65:  "readv",
-	66:  "writev",
-	67:  "pread64",
-	68:  "pwrite64",
-	69:  "preadv",
-	70:  "pwritev",
-	71:  "sendfile",
-	72:  "pselect6",
-	73:  "ppoll",
-	74:  "signalfd4",
-	75:  "vmsplice",
-	76:  "splice",
-	77:  "tee",
-	78:  "readlinkat",
-	79:  "fstatat",
-	80:  "fstat",
-	81:  "sync",
-	82:  "fsync",
-	83:  "fdatasync",
-	84:  "sync_file_range",
-	85:  "timerfd_create",
-	86:  "timerfd_settime",
-	87:  "timerfd_gettime",
-	88:  "utimensat",
-	89:  "acct",
-	90:  "capget",
-	91:  "capset",
-	92:  "personality",
-	93:  "exit",
-	94:  "exit_group",
-	95:  "waitid",
-	96:  "set_tid_address",
-	97:  "unshare",
-	98:  "futex",
-	99:  "set_robust_list",
-	100: "get_robust_list",
-	101: "nanosleep",
-	102: "getitimer",
-	103: "setitimer",
-	104: "kexec_load",
-	105: "init_module",
-	106: "delete_module",
-	107: "timer_create",
-	108: "timer_gettime",
-	109: "timer_getoverrun",
-	110: "timer_settime",
-	111: "timer_delete",
-	112: "clock_settime",
-	113: "clock_gettime",
-	114: "clock_getres",
-	115: "clock_nanosleep",
-	116: "syslog",
-	117: "ptrace",
-	118: "sched_setparam",
-	119: "sched_setscheduler",
-	120: "sched_getscheduler",
-	121: "sched_getparam",
-	122: "sched_setaffinity",
-	123: "sched_getaffinity",
-	124: "sched_yield",
-	125: "sched_get_priority_max",
-	126: "sched_get_priority_min",
-	127: "sched_rr_get_interval",
-	128: "restart_syscall",
-	129: "kill",
-	130: "tkill",
-	131: "tgkill",
-	132: "sigaltstack",
-	133: "rt_sigsuspend",
-	134: "rt_sigaction",
-	135: "rt_sigprocmask",
-	136: "rt_sigpending",
-	137: "rt_sigtimedwait",
-	138: "rt_sigqueueinfo",
-	139: "rt_sigreturn",
-	140: "setpriority",
-	141: "getpriority",
-	142: "reboot",
-	143: "setregid",
-	144: "setgid",
-	145: "setreuid",
-	146: "setuid",
-	147: "setresuid",
-	148: "getresuid",
-	149: "setresgid",
-	150: "getresgid",
-	151: "setfsuid",
-	152: "setfsgid",
-	153: "times",
-	154: "setpgid",
-	155: "getpgid",
-	156: "getsid",
-	157: "setsid",
-	158: "getgroups",
-	159: "setgroups",
-	160: "uname",
-	161: "sethostname",
-	162: "setdomainname",
-	163: "getrlimit",
-	164: "setrlimit",
-	165: "getrusage",
-	166: "umask",
-	167: "prctl",
-	168: "getcpu",
-	169: "gettimeofday",
-	170: "settimeofday",
-	171: "adjtimex",
-	172: "getpid",
-	173: "getppid",
-	174: "getuid",
-	175: "geteuid",
-	176: "getgid",
-	177: "getegid",
-	178: "gettid",
-	179: "sysinfo",
-	180: "mq_open",
-	181: "mq_unlink",
-	182: "mq_timedsend",
-	183: "mq_timedreceive",
-	184: "mq_notify",
-	185: "mq_getsetattr",
-	186: "msgget",
-	187: "msgctl",
-	188: "msgrcv",
-	189: "msgsnd",
-	190: "semget",
-	191: "semctl",
-	192: "semtimedop",
-	193: "semop",
-	194: "shmget",
-	195: "shmctl",
-	196: "shmat",
-	197: "shmdt",
-	198: "socket",
-	199: "socketpair",
-	200: "bind",
-	201: "listen",
-	202: "accept",
-	203: "connect",
-	204: "getsockname",
-	205: "getpeername",
-	206: "sendto",
-	207: "recvfrom",
-	208: "setsockopt",
-	209: "getsockopt",
-	210: "shutdown",
-	211: "sendmsg",
-	212: "recvmsg",
-	213: "readahead",
-	214: "brk",
-	215: "munmap",
-	216: "mremap",
-	217: "add_key",
-	218: "request_key",
-	219: "keyctl",
-	220: "clone",
-	221: "execve",
-	222: "mmap",
-	223: "fadvise64",
-	224: "swapon",
-	225: "swapoff",
-	226: "mprotect",
-	227: "msync",
-	228: "mlock",
-	229: "munlock",
-	230: "mlockall",
-	231: "munlockall",
-	232: "mincore",
-	233: "madvise",
-	234: "remap_file_pages",
-	235: "mbind",
-	236: "get_mempolicy",
-	237: "set_mempolicy",
-	238: "migrate_pages",
-	239: "move_pages",
-	240: "rt_tgsigqueueinfo",
-	241: "perf_event_open",
-	242: "accept4",
-	243: "recvmmsg",
-	244: "arch_specific_syscall",
-	260: "wait4",
-	261: "prlimit64",
-	262: "fanotify_init",
-	263: "fanotify_mark",
-	264: "name_to_handle_at",
-	265: "open_by_handle_at",
-	266: "clock_adjtime",
-	267: "syncfs",
-	268: "setns",
-	269: "sendmmsg",
-	270: "process_vm_readv",
-	271: "process_vm_writev",
-	272: "kcmp",
-	273: "finit_module",
-	274: "sched_setattr",
-	275: "sched_getattr",
-	276: "renameat2",
-	277: "seccomp",
-	278: "getrandom",
-	279: "memfd_create",
-	280: "bpf",
-	281: "execveat",
-	282: "userfaultfd",
-	283: "membarrier",
-	284: "mlock2",
-	285: "copy_file_range",
-	286: "preadv2",
-	287: "pwritev2",
-	288: "pkey_mprotect",
-	289: "pkey_alloc",
-	290: "pkey_free",
-	291: "statx",
-	292: "io_pgetevents",
-	293: "rseq",
-	294: "kexec_file_load",
-	403: "clock_gettime64",
-	404: "clock_settime64",
-	405: "clock_adjtime64",
-	406: "clock_getres_time64",
-	407: "clock_nanosleep_time64",
-	408: "timer_gettime64",
-	409: "timer_settime64",
-	410: "timerfd_gettime64",
-	411: "timerfd_settime64",
-	412: "utimensat_time64",
-	413: "pselect6_time64",
-	414: "ppoll_time64",
-	416: "io_pgetevents_time64",
-	417: "recvmmsg_time64",
-	418: "mq_timedsend_time64",
-	419: "mq_timedreceive_time64",
-	420: "semtimedop_time64",
-	421: "rt_sigtimedwait_time64",
-	422: "futex_time64",
-	423: "sched_rr_get_interval_time64",
-	424: "pidfd_send_signal",
-	425: "io_uring_setup",
-	426: "io_uring_enter",
-	427: "io_uring_register",
-	428: "open_tree",
-	429: "move_mount",
-	430: "fsopen",
-	431: "fsconfig",
-	432: "fsmount",
-	433: "fspick",
-	434: "pidfd_open",
-	435: "clone3",
-	436: "close_range",
-	437: "openat2",
-	438: "pidfd_getfd",
-	439: "faccessat2",
-	440: "process_madvise",
-	441: "epoll_pwait2",
-	442: "mount_setattr",
-	443: "quotactl_fd",
-	444: "landlock_create_ruleset",
-	445: "landlock_add_rule",
-	446: "landlock_restrict_self",
-	447: "memfd_secret",
-	448: "process_mrelease",
-	449: "futex_waitv",
-	450: "set_mempolicy_home_node",
-	451: "cachestat",
-	452: "fchmodat2",
-	453: "map_shadow_stack",
-	454: "futex_wake",
-	455: "futex_wait",
-	456: "futex_requeue",
-	457: "statmount",
-	458: "listmount",
-	459: "lsm_get_self_attr",
-	460: "lsm_set_self_attr",
-	461: "lsm_list_modules",
-	462: "mseal",
-	463: "setxattrat",
-	464: "getxattrat",
-	465: "listxattrat",
-	466: "removexattrat",
-	467: "open_tree_attr",
+var syscallsByNameARM = []syscallEntry{
+	{140, "_llseek"},
+	{142, "_newselect"},
+	{149, "_sysctl"},
+	{285, "accept"},
+	{366, "accept4"},
+	{33, "access"},
+	{51, "acct"},
+	{309, "add_key"},
+	{124, "adjtimex"},
+	{270, "arm_fadvise64_64"},
+	{341, "arm_sync_file_range"},
+	{134, "bdflush"},
+	{282, "bind"},
+	{386, "bpf"},
+	{983041, "breakpoint"},
+	{45, "brk"},
+	{983042, "cacheflush"},
+	{451, "cachestat"},
+	{184, "capget"},
+	{185, "capset"},
+	{12, "chdir"},
+	{15, "chmod"},
+	{182, "chown"},
+	{212, "chown32"},
+	{61, "chroot"},
+	{372, "clock_adjtime"},
+	{405, "clock_adjtime64"},
+	{264, "clock_getres"},
+	{406, "clock_getres_time64"},
+	{263, "clock_gettime"},
+	{403, "clock_gettime64"},
+	{265, "clock_nanosleep"},
+	{407, "clock_nanosleep_time64"},
+	{262, "clock_settime"},
+	{404, "clock_settime64"},
+	{120, "clone"},
+	{435, "clone3"},
+	{6, "close"},
+	{436, "close_range"},
+	{283, "connect"},
+	{391, "copy_file_range"},
+	{8, "creat"},
+	{129, "delete_module"},
+	{41, "dup"},
+	{63, "dup2"},
+	{358, "dup3"},
+	{250, "epoll_create"},
+	{357, "epoll_create1"},
+	{251, "epoll_ctl"},
+	{346, "epoll_pwait"},
+	{441, "epoll_pwait2"},
+	{252, "epoll_wait"},
+	{351, "eventfd"},
+	{356, "eventfd2"},
+	{11, "execve"},
+	{387, "execveat"},
+	{1, "exit"},
+	{248, "exit_group"},
+	{334, "faccessat"},
+	{439, "faccessat2"},
+	{352, "fallocate"},
+	{367, "fanotify_init"},
+	{368, "fanotify_mark"},
+	{133, "fchdir"},
+	{94, "fchmod"},
+	{333, "fchmodat"},
+	{452, "fchmodat2"},
+	{95, "fchown"},
+	{207, "fchown32"},
+	{325, "fchownat"},
+	{55, "fcntl"},
+	{221, "fcntl64"},
+	{148, "fdatasync"},
+	{231, "fgetxattr"},
+	{379, "finit_module"},
+	{234, "flistxattr"},
+	{143, "flock"},
+	{2, "fork"},
+	{237, "fremovexattr"},
+	{431, "fsconfig"},
+	{228, "fsetxattr"},
+	{432, "fsmount"},
+	{430, "fsopen"},
+	{433, "fspick"},
+	{108, "fstat"},
+	{197, "fstat64"},
+	{327, "fstatat64"},
+	{100, "fstatfs"},
+	{267, "fstatfs64"},
+	{118, "fsync"},
+	{93, "ftruncate"},
+	{194, "ftruncate64"},
+	{240, "futex"},
+	{456, "futex_requeue"},
+	{422, "futex_time64"},
+	{455, "futex_wait"},
+	{449, "futex_waitv"},
+	{454, "futex_wake"},
+	{326, "futimesat"},
+	{320, "get_mempolicy"},
+	{339, "get_robust_list"},
+	{983046, "get_tls"},
+	{345, "getcpu"},
+	{183, "getcwd"},
+	{141, "getdents"},
+	{217, "getdents64"},
+	{50, "getegid"},
+	{202, "getegid32"},
+	{49, "geteuid"},
+	{201, "geteuid32"},
+	{47, "getgid"},
+	{200, "getgid32"},
+	{80, "getgroups"},
+	{205, "getgroups32"},
+	{105, "getitimer"},
+	{287, "getpeername"},
+	{132, "getpgid"},
+	{65, "getpgrp"},
+	{20, "getpid"},
+	{64, "getppid"},
+	{96, "getpriority"},
+	{384, "getrandom"},
+	{171, "getresgid"},
+	{211, "getresgid32"},
+	{165, "getresuid"},
+	{209, "getresuid32"},
+	{77, "getrusage"},
+	{147, "getsid"},
+	{286, "getsockname"},
+	{295, "getsockopt"},
+	{224, "gettid"},
+	{78, "gettimeofday"},
+	{24, "getuid"},
+	{199, "getuid32"},
+	{229, "getxattr"},
+	{464, "getxattrat"},
+	{128, "init_module"},
+	{317, "inotify_add_watch"},
+	{316, "inotify_init"},
+	{360, "inotify_init1"},
+	{318, "inotify_rm_watch"},
+	{247, "io_cancel"},
+	{244, "io_destroy"},
+	{245, "io_getevents"},
+	{399, "io_pgetevents"},
+	{416, "io_pgetevents_time64"},
+	{243, "io_setup"},
+	{246, "io_submit"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{425, "io_uring_setup"},
+	{54, "ioctl"},
+	{315, "ioprio_get"},
+	{314, "ioprio_set"},
+	{378, "kcmp"},
+	{401, "kexec_file_load"},
+	{347, "kexec_load"},
+	{311, "keyctl"},
+	{37, "kill"},
+	{445, "landlock_add_rule"},
+	{444, "landlock_create_ruleset"},
+	{446, "landlock_restrict_self"},
+	{16, "lchown"},
+	{198, "lchown32"},
+	{230, "lgetxattr"},
+	{9, "link"},
+	{330, "linkat"},
+	{284, "listen"},
+	{458, "listmount"},
+	{232, "listxattr"},
+	{465, "listxattrat"},
+	{233, "llistxattr"},
+	{249, "lookup_dcookie"},
+	{236, "lremovexattr"},
+	{19, "lseek"},
+	{227, "lsetxattr"},
+	{459, "lsm_get_self_attr"},
+	{461, "lsm_list_modules"},
+	{460, "lsm_set_self_attr"},
+	{107, "lstat"},
+	{196, "lstat64"},
+	{220, "madvise"},
+	{453, "map_shadow_stack"},
+	{319, "mbind"},
+	{389, "membarrier"},
+	{385, "memfd_create"},
+	{400, "migrate_pages"},
+	{219, "mincore"},
+	{39, "mkdir"},
+	{323, "mkdirat"},
+	{14, "mknod"},
+	{324, "mknodat"},
+	{150, "mlock"},
+	{390, "mlock2"},
+	{152, "mlockall"},
+	{192, "mmap2"},
+	{21, "mount"},
+	{442, "mount_setattr"},
+	{429, "move_mount"},
+	{344, "move_pages"},
+	{125, "mprotect"},
+	{279, "mq_getsetattr"},
+	{278, "mq_notify"},
+	{274, "mq_open"},
+	{277, "mq_timedreceive"},
+	{419, "mq_timedreceive_time64"},
+	{276, "mq_timedsend"},
+	{418, "mq_timedsend_time64"},
+	{275, "mq_unlink"},
+	{163, "mremap"},
+	{462, "mseal"},
+	{304, "msgctl"},
+	{303, "msgget"},
+	{302, "msgrcv"},
+	{301, "msgsnd"},
+	{144, "msync"},
+	{151, "munlock"},
+	{153, "munlockall"},
+	{91, "munmap"},
+	{370, "name_to_handle_at"},
+	{162, "nanosleep"},
+	{169, "nfsservctl"},
+	{34, "nice"},
+	{5, "open"},
+	{371, "open_by_handle_at"},
+	{428, "open_tree"},
+	{467, "open_tree_attr"},
+	{322, "openat"},
+	{437, "openat2"},
+	{29, "pause"},
+	{271, "pciconfig_iobase"},
+	{272, "pciconfig_read"},
+	{273, "pciconfig_write"},
+	{364, "perf_event_open"},
+	{136, "personality"},
+	{438, "pidfd_getfd"},
+	{434, "pidfd_open"},
+	{424, "pidfd_send_signal"},
+	{42, "pipe"},
+	{359, "pipe2"},
+	{218, "pivot_root"},
+	{395, "pkey_alloc"},
+	{396, "pkey_free"},
+	{394, "pkey_mprotect"},
+	{168, "poll"},
+	{336, "ppoll"},
+	{414, "ppoll_time64"},
+	{172, "prctl"},
+	{180, "pread64"},
+	{361, "preadv"},
+	{392, "preadv2"},
+	{369, "prlimit64"},
+	{440, "process_madvise"},
+	{448, "process_mrelease"},
+	{376, "process_vm_readv"},
+	{377, "process_vm_writev"},
+	{335, "pselect6"},
+	{413, "pselect6_time64"},
+	{26, "ptrace"},
+	{181, "pwrite64"},
+	{362, "pwritev"},
+	{393, "pwritev2"},
+	{131, "quotactl"},
+	{443, "quotactl_fd"},
+	{3, "read"},
+	{225, "readahead"},
+	{85, "readlink"},
+	{332, "readlinkat"},
+	{145, "readv"},
+	{88, "reboot"},
+	{291, "recv"},
+	{292, "recvfrom"},
+	{365, "recvmmsg"},
+	{417, "recvmmsg_time64"},
+	{297, "recvmsg"},
+	{253, "remap_file_pages"},
+	{235, "removexattr"},
+	{466, "removexattrat"},
+	{38, "rename"},
+	{329, "renameat"},
+	{382, "renameat2"},
+	{310, "request_key"},
+	{0, "restart_syscall"},
+	{40, "rmdir"},
+	{398, "rseq"},
+	{174, "rt_sigaction"},
+	{176, "rt_sigpending"},
+	{175, "rt_sigprocmask"},
+	{178, "rt_sigqueueinfo"},
+	{173, "rt_sigreturn"},
+	{179, "rt_sigsuspend"},
+	{177, "rt_sigtimedwait"},
+	{421, "rt_sigtimedwait_time64"},
+	{363, "rt_tgsigqueueinfo"},
+	{159, "sched_get_priority_max"},
+	{160, "sched_get_priority_min"},
+	{242, "sched_getaffinity"},
+	{381, "sched_getattr"},
+	{155, "sched_getparam"},
+	{157, "sched_getscheduler"},
+	{161, "sched_rr_get_interval"},
+	{423, "sched_rr_get_interval_time64"},
+	{241, "sched_setaffinity"},
+	{380, "sched_setattr"},
+	{154, "sched_setparam"},
+	{156, "sched_setscheduler"},
+	{158, "sched_yield"},
+	{383, "seccomp"},
+	{300, "semctl"},
+	{299, "semget"},
+	{298, "semop"},
+	{312, "semtimedop"},
+	{420, "semtimedop_time64"},
+	{289, "send"},
+	{187, "sendfile"},
+	{239, "sendfile64"},
+	{374, "sendmmsg"},
+	{296, "sendmsg"},
+	{290, "sendto"},
+	{321, "set_mempolicy"},
+	{450, "set_mempolicy_home_node"},
+	{338, "set_robust_list"},
+	{256, "set_tid_address"},
+	{983045, "set_tls"},
+	{121, "setdomainname"},
+	{139, "setfsgid"},
+	{216, "setfsgid32"},
+	{138, "setfsuid"},
+	{215, "setfsuid32"},
+	{46, "setgid"},
+	{214, "setgid32"},
+	{81, "setgroups"},
+	{206, "setgroups32"},
+	{74, "sethostname"},
+	{104, "setitimer"},
+	{375, "setns"},
+	{57, "setpgid"},
+	{97, "setpriority"},
+	{71, "setregid"},
+	{204, "setregid32"},
+	{170, "setresgid"},
+	{210, "setresgid32"},
+	{164, "setresuid"},
+	{208, "setresuid32"},
+	{70, "setreuid"},
+	{203, "setreuid32"},
+	{75, "setrlimit"},
+	{66, "setsid"},
+	{294, "setsockopt"},
+	{79, "settimeofday"},
+	{23, "setuid"},
+	{213, "setuid32"},
+	{226, "setxattr"},
+	{463, "setxattrat"},
+	{305, "shmat"},
+	{308, "shmctl"},
+	{306, "shmdt"},
+	{307, "shmget"},
+	{293, "shutdown"},
+	{67, "sigaction"},
+	{186, "sigaltstack"},
+	{349, "signalfd"},
+	{355, "signalfd4"},
+	{73, "sigpending"},
+	{126, "sigprocmask"},
+	{119, "sigreturn"},
+	{72, "sigsuspend"},
+	{281, "socket"},
+	{288, "socketpair"},
+	{340, "splice"},
+	{106, "stat"},
+	{195, "stat64"},
+	{99, "statfs"},
+	{266, "statfs64"},
+	{457, "statmount"},
+	{397, "statx"},
+	{115, "swapoff"},
+	{87, "swapon"},
+	{83, "symlink"},
+	{331, "symlinkat"},
+	{36, "sync"},
+	{373, "syncfs"},
+	{135, "sysfs"},
+	{116, "sysinfo"},
+	{103, "syslog"},
+	{342, "tee"},
+	{268, "tgkill"},
+	{257, "timer_create"},
+	{261, "timer_delete"},
+	{260, "timer_getoverrun"},
+	{259, "timer_gettime"},
+	{408, "timer_gettime64"},
+	{258, "timer_settime"},
+	{409, "timer_settime64"},
+	{350, "timerfd_create"},
+	{354, "timerfd_gettime"},
+	{410, "timerfd_gettime64"},
+	{353, "timerfd_settime"},
+	{411, "timerfd_settime64"},
+	{43, "times"},
+	{238, "tkill"},
+	{92, "truncate"},
+	{193, "truncate64"},
+	{191, "ugetrlimit"},
+	{60, "umask"},
+	{52, "umount2"},
+	{122, "uname"},
+	{10, "unlink"},
+	{328, "unlinkat"},
+	{337, "unshare"},
+	{86, "uselib"},
+	{388, "userfaultfd"},
+	{983043, "usr26"},
+	{983044, "usr32"},
+	{62, "ustat"},
+	{348, "utimensat"},
+	{412, "utimensat_time64"},
+	{269, "utimes"},
+	{190, "vfork"},
+	{111, "vhangup"},
+	{343, "vmsplice"},
+	{313, "vserver"},
+	{114, "wait4"},
+	{280, "waitid"},
+	{4, "write"},
+	{146, "writev"},
 }
 
-var syscalls386 = map[int]string{
-	0:   "restart_syscall",
-	1:   "exit",
-	2:   "fork",
-	3:   "read",
-	4:   "write",
-	5:   "open",
-	6:   "close",
-	7:   "waitpid",
-	8:   "creat",
-	9:   "link",
-	10:  "unlink",
-	11:  "execve",
-	12:  "chdir",
-	13:  "time",
-	14:  "mknod",
-	15:  "chmod",
-	16:  "lchown",
-	17:  "break",
-	18:  "oldstat",
-	19:  "lseek",
-	20:  "getpid",
-	21:  "mount",
-	22:  "umount",
-	23:  "setuid",
-	24:  "getuid",
-	25:  "stime",
-	26:  "ptrace",
-	27:  "alarm",
-	28:  "oldfstat",
-	29:  "pause",
-	30:  "utime",
-	31:  "stty",
-	32:  "gtty",
-	33:  "access",
-	34:  "nice",
-	35:  "ftime",
-	36:  "sync",
-	37:  "kill",
-	38:  "rename",
-	39:  "mkdir",
-	40:  "rmdir",
-	41:  "dup",
-	42:  "pipe",
-	43:  "times",
-	44:  "prof",
-	45:  "brk",
-	46:  "setgid",
-	47:  "getgid",
-	48:  "signal",
-	49:  "geteuid",
-	50:  "getegid",
-	51:  "acct",
-	52:  "umount2",
-	53:  "lock",
-	54:  "ioctl",
-	55:  "fcntl",
-	56:  "mpx",
-	57:  "setpgid",
-	58:  "ulimit",
-	59:  "oldolduname",
-	60:  "umask",
-	61:  "chroot",
-	62:  "ustat",
-	63:  "dup2",
-	64:  "getppid",
-	65:  "getpgrp",
-	66:  "setsid",
-	67:  "sigaction",
-	68:  "sgetmask",
-	69:  "ssetmask",
-	70:  "setreuid",
-	71:  "setregid",
-	72:  "sigsuspend",
-	73:  "sigpending",
-	74:  "sethostname",
-	75:  "setrlimit",
-	76:  "getrlimit",
-	77:  "getrusage",
-	78:  "gettimeofday",
-	79:  "settimeofday",
-	80:  "getgroups",
-	81:  "setgroups",
-	82:  "select",
-	83:  "symlink",
-	84:  "oldlstat",
-	85:  "readlink",
-	86:  "uselib",
-	87:  "swapon",
-	88:  "reboot",
-	89:  "readdir",
-	90:  "mmap",
-	91:  "munmap",
-	92:  "truncate",
-	93:  "ftruncate",
-	94:  "fchmod",
-	95:  "fchown",
-	96:  "getpriority",
-	97:  "setpriority",
-	98:  "profil",
-	99:  "statfs",
-	100: "fstatfs",
-	101: "ioperm",
-	102: "socketcall",
-	103: "syslog",
-	104: "setitimer",
-	105: "getitimer",
-	106: "stat",
-	107: "lstat",
-	108: "fstat",
-	109: "olduname",
-	110: "iopl",
-	111: "vhangup",
-	112: "idle",
-	113: "vm86old",
-	114: "wait4",
-	115: "swapoff",
-	116: "sysinfo",
-	117: "ipc",
-	118: "fsync",
-	119: "sigreturn",
-	120: "clone",
-	121: "setdomainname",
-	122: "uname",
-	123: "modify_ldt",
-	124: "adjtimex",
-	125: "mprotect",
-	126: "sigprocmask",
-	127: "create_module",
-	128: "init_module",
-	129: "delete_module",
-	130: "get_kernel_syms",
-	131: "quotactl",
-	132: "getpgid",
-	133: "fchdir",
-	134: "bdflush",
-	135: "sysfs",
-	136: "personality",
-	137: "afs_syscall",
-	138: "setfsuid",
-	139: "setfsgid",
-	140: "_llseek",
-	141: "getdents",
-	142: "_newselect",
-	143: "flock",
-	144: "msync",
-	145: "readv",
-	146: "writev",
-	147: "getsid",
-	148: "fdatasync",
-	149: "_sysctl",
-	150: "mlock",
-	151: "munlock",
-	152: "mlockall",
-	153: "munlockall",
-	154: "sched_setparam",
-	155: "sched_getparam",
-	156: "sched_setscheduler",
-	157: "sched_getscheduler",
-	158: "sched_yield",
-	159: "sched_get_priority_max",
-	160: "sched_get_priority_min",
-	161: "sched_rr_get_interval",
-	162: "nanosleep",
-	163: "mremap",
-	164: "setresuid",
-	165: "getresuid",
-	166: "vm86",
-	167: "query_module",
-	168: "poll",
-	169: "nfsservctl",
-	170: "setresgid",
-	171: "getresgid",
-	172: "prctl",
-	173: "rt_sigreturn",
-	174: "rt_sigaction",
-	175: "rt_sigprocmask",
-	176: "rt_sigpending",
-	177: "rt_sigtimedwait",
-	178: "rt_sigqueueinfo",
-	179: "rt_sigsuspend",
-	180: "pread64",
-	181: "pwrite64",
-	182: "chown",
-	183: "getcwd",
-	184: "capget",
-	185: "capset",
-	186: "sigaltstack",
-	187: "sendfile",
-	188: "getpmsg",
-	189: "putpmsg",
-	190: "vfork",
-	191: "ugetrlimit",
-	192: "mmap2",
-	193: "truncate64",
-	194: "ftruncate64",
-	195: "stat64",
-	196: "lstat64",
-	197: "fstat64",
-	198: "lchown32",
-	199: "getuid32",
-	200: "getgid32",
-	201: "geteuid32",
-	202: "getegid32",
-	203: "setreuid32",
-	204: "setregid32",
-	205: "getgroups32",
-	206: "setgroups32",
-	207: "fchown32",
-	208: "setresuid32",
-	209: "getresuid32",
-	210: "setresgid32",
-	211: "getresgid32",
-	212: "chown32",
-	213: "setuid32",
-	214: "setgid32",
-	215: "setfsuid32",
-	216: "setfsgid32",
-	217: "pivot_root",
-	218: "mincore",
-	219: "madvise",
-	220: "getdents64",
-	221: "fcntl64",
-	224: "gettid",
-	225: "readahead",
-	226: "setxattr",
-	227: "lsetxattr",
-	228: "fsetxattr",
-	229: "getxattr",
-	230: "lgetxattr",
-	231: "fgetxattr",
-	232: "listxattr",
-	233: "llistxattr",
-	234: "flistxattr",
-	235: "removexattr",
-	236: "lremovexattr",
-	237: "fremovexattr",
-	238: "tkill",
-	239: "sendfile64",
-	240: "futex",
-	241: "sched_setaffinity",
-	242: "sched_getaffinity",
-	243: "set_thread_area",
-	244: "get_thread_area",
-	245: "io_setup",
-	246: "io_destroy",
-	247: "io_getevents",
-	248: "io_submit",
-	249: "io_cancel",
-	250: "fadvise64",
-	252: "exit_group",
-	253: "lookup_dcookie",
-	254: "epoll_create",
-	255: "epoll_ctl",
-	256: "epoll_wait",
-	257: "remap_file_pages",
-	258: "set_tid_address",
-	259: "timer_create",
-	260: "timer_settime",
-	261: "timer_gettime",
-	262: "timer_getoverrun",
-	263: "timer_delete",
-	264: "clock_settime",
-	265: "clock_gettime",
-	266: "clock_getres",
-	267: "clock_nanosleep",
-	268: "statfs64",
-	269: "fstatfs64",
-	270: "tgkill",
-	271: "utimes",
-	272: "fadvise64_64",
-	273: "vserver",
-	274: "mbind",
-	275: "get_mempolicy",
-	276: "set_mempolicy",
-	277: "mq_open",
-	278: "mq_unlink",
-	279: "mq_timedsend",
-	280: "mq_timedreceive",
-	281: "mq_notify",
-	282: "mq_getsetattr",
-	283: "kexec_load",
-	284: "waitid",
-	286: "add_key",
-	287: "request_key",
-	288: "keyctl",
-	289: "ioprio_set",
-	290: "ioprio_get",
-	291: "inotify_init",
-	292: "inotify_add_watch",
-	293: "inotify_rm_watch",
-	294: "migrate_pages",
-	295: "openat",
-	296: "mkdirat",
-	297: "mknodat",
-	298: "fchownat",
-	299: "futimesat",
-	300: "fstatat64",
-	301: "unlinkat",
-	302: "renameat",
-	303: "linkat",
-	304: "symlinkat",
-	305: "readlinkat",
-	306: "fchmodat",
-	307: "faccessat",
-	308: "pselect6",
-	309: "ppoll",
-	310: "unshare",
-	311: "set_robust_list",
-	312: "get_robust_list",
-	313: "splice",
-	314: "sync_file_range",
-	315: "tee",
-	316: "vmsplice",
-	317: "move_pages",
-	318: "getcpu",
-	319: "epoll_pwait",
-	320: "utimensat",
-	321: "signalfd",
-	322: "timerfd_create",
-	323: "eventfd",
-	324: "fallocate",
-	325: "timerfd_settime",
-	326: "timerfd_gettime",
-	327: "signalfd4",
-	328: "eventfd2",
-	329: "epoll_create1",
-	330: "dup3",
-	331: "pipe2",
-	332: "inotify_init1",
-	333: "preadv",
-	334: "pwritev",
-	335: "rt_tgsigqueueinfo",
-	336: "perf_event_open",
-	337: "recvmmsg",
-	338: "fanotify_init",
-	339: "fanotify_mark",
-	340: "prlimit64",
-	341: "name_to_handle_at",
-	342: "open_by_handle_at",
-	343: "clock_adjtime",
-	344: "syncfs",
-	345: "sendmmsg",
-	346: "setns",
-	347: "process_vm_readv",
-	348: "process_vm_writev",
-	349: "kcmp",
-	350: "finit_module",
-	351: "sched_setattr",
-	352: "sched_getattr",
-	353: "renameat2",
-	354: "seccomp",
-	355: "getrandom",
-	356: "memfd_create",
-	357: "bpf",
-	358: "execveat",
-	359: "socket",
-	360: "socketpair",
-	361: "bind",
-	362: "connect",
-	363: "listen",
-	364: "accept4",
-	365: "getsockopt",
-	366: "setsockopt",
-	367: "getsockname",
-	368: "getpeername",
-	369: "sendto",
-	370: "sendmsg",
-	371: "recvfrom",
-	372: "recvmsg",
-	373: "shutdown",
-	374: "userfaultfd",
-	375: "membarrier",
-	376: "mlock2",
-	377: "copy_file_range",
-	378: "preadv2",
-	379: "pwritev2",
-	380: "pkey_mprotect",
-	381: "pkey_alloc",
-	382: "pkey_free",
-	383: "statx",
-	384: "arch_prctl",
-	385: "io_pgetevents",
-	386: "rseq",
-	393: "semget",
-	394: "semctl",
-	395: "shmget",
-	396: "shmctl",
-	397: "shmat",
-	398: "shmdt",
-	399: "msgget",
-	400: "msgsnd",
-	401: "msgrcv",
-	402: "msgctl",
-	403: "clock_gettime64",
-	404: "clock_settime64",
-	405: "clock_adjtime64",
-	406: "clock_getres_time64",
-	407: "clock_nanosleep_time64",
-	408: "timer_gettime64",
-	409: "timer_settime64",
-	410: "timerfd_gettime64",
-	411: "timerfd_settime64",
-	412: "utimensat_time64",
-	413: "pselect6_time64",
-	414: "ppoll_time64",
-	416: "io_pgetevents_time64",
-	417: "recvmmsg_time64",
-	418: "mq_timedsend_time64",
-	419: "mq_timedreceive_time64",
-	420: "semtimedop_time64",
-	421: "rt_sigtimedwait_time64",
-	422: "futex_time64",
-	423: "sched_rr_get_interval_time64",
-	424: "pidfd_send_signal",
-	425: "io_uring_setup",
-	426: "io_uring_enter",
-	427: "io_uring_register",
-	428: "open_tree",
-	429: "move_mount",
-	430: "fsopen",
-	431: "fsconfig",
-	432: "fsmount",
-	433: "fspick",
-	434: "pidfd_open",
-	435: "clone3",
-	436: "close_range",
-	437: "openat2",
-	438: "pidfd_getfd",
-	439: "faccessat2",
-	440: "process_madvise",
-	441: "epoll_pwait2",
-	442: "mount_setattr",
-	443: "quotactl_fd",
-	444: "landlock_create_ruleset",
-	445: "landlock_add_rule",
-	446: "landlock_restrict_self",
-	447: "memfd_secret",
-	448: "process_mrelease",
-	449: "futex_waitv",
-	450: "set_mempolicy_home_node",
-	451: "cachestat",
-	452: "fchmodat2",
-	453: "map_shadow_stack",
-	454: "futex_wake",
-	455: "futex_wait",
-	456: "futex_requeue",
-	457: "statmount",
-	458: "listmount",
-	459: "lsm_get_self_attr",
-	460: "lsm_set_self_attr",
-	461: "lsm_list_modules",
-	462: "mseal",
-	463: "setxattrat",
-	464: "getxattrat",
-	465: "listxattrat",
-	466: "removexattrat",
-	467: "open_tree_attr",
+var syscallsAARCH64 = []syscallEntry{
+	{0, "io_setup"},
+	{1, "io_destroy"},
+	{2, "io_submit"},
+	{3, "io_cancel"},
+	{4, "io_getevents"},
+	{5, "setxattr"},
+	{6, "lsetxattr"},
+	{7, "fsetxattr"},
+	{8, "getxattr"},
+	{9, "lgetxattr"},
+	{10, "fgetxattr"},
+	{11, "listxattr"},
+	{12, "llistxattr"},
+	{13, "flistxattr"},
+	{14, "removexattr"},
+	{15, "lremovexattr"},
+	{16, "fremovexattr"},
+	{17, "getcwd"},
+	{18, "lookup_dcookie"},
+	{19, "eventfd2"},
+	{20, "epoll_create1"},
+	{21, "epoll_ctl"},
+	{22, "epoll_pwait"},
+	{23, "dup"},
+	{24, "dup3"},
+	{25, "fcntl"},
+	{26, "inotify_init1"},
+	{27, "inotify_add_watch"},
+	{28, "inotify_rm_watch"},
+	{29, "ioctl"},
+	{30, "ioprio_set"},
+	{31, "ioprio_get"},
+	{32, "flock"},
+	{33, "mknodat"},
+	{34, "mkdirat"},
+	{35, "unlinkat"},
+	{36, "symlinkat"},
+	{37, "linkat"},
+	{38, "renameat"},
+	{39, "umount2"},
+	{40, "mount"},
+	{41, "pivot_root"},
+	{42, "nfsservctl"},
+	{43, "statfs"},
+	{44, "fstatfs"},
+	{45, "truncate"},
+	{46, "ftruncate"},
+	{47, "fallocate"},
+	{48, "faccessat"},
+	{49, "chdir"},
+	{50, "fchdir"},
+	{51, "chroot"},
+	{52, "fchmod"},
+	{53, "fchmodat"},
+	{54, "fchownat"},
+	{55, "fchown"},
+	{56, "openat"},
+	{57, "close"},
+	{58, "vhangup"},
+	{59, "pipe2"},
+	{60, "quotactl"},
+	{61, "getdents64"},
+	{62, "lseek"},
+	{63, "read"},
+	{64, "write"},
+	{65, "readv"},
+	{66, "writev"},
+	{67, "pread64"},
+	{68, "pwrite64"},
+	{69, "preadv"},
+	{70, "pwritev"},
+	{71, "sendfile"},
+	{72, "pselect6"},
+	{73, "ppoll"},
+	{74, "signalfd4"},
+	{75, "vmsplice"},
+	{76, "splice"},
+	{77, "tee"},
+	{78, "readlinkat"},
+	{79, "fstatat"},
+	{80, "fstat"},
+	{81, "sync"},
+	{82, "fsync"},
+	{83, "fdatasync"},
+	{84, "sync_file_range"},
+	{85, "timerfd_create"},
+	{86, "timerfd_settime"},
+	{87, "timerfd_gettime"},
+	{88, "utimensat"},
+	{89, "acct"},
+	{90, "capget"},
+	{91, "capset"},
+	{92, "personality"},
+	{93, "exit"},
+	{94, "exit_group"},
+	{95, "waitid"},
+	{96, "set_tid_address"},
+	{97, "unshare"},
+	{98, "futex"},
+	{99, "set_robust_list"},
+	{100, "get_robust_list"},
+	{101, "nanosleep"},
+	{102, "getitimer"},
+	{103, "setitimer"},
+	{104, "kexec_load"},
+	{105, "init_module"},
+	{106, "delete_module"},
+	{107, "timer_create"},
+	{108, "timer_gettime"},
+	{109, "timer_getoverrun"},
+	{110, "timer_settime"},
+	{111, "timer_delete"},
+	{112, "clock_settime"},
+	{113, "clock_gettime"},
+	{114, "clock_getres"},
+	{115, "clock_nanosleep"},
+	{116, "syslog"},
+	{117, "ptrace"},
+	{118, "sched_setparam"},
+	{119, "sched_setscheduler"},
+	{120, "sched_getscheduler"},
+	{121, "sched_getparam"},
+	{122, "sched_setaffinity"},
+	{123, "sched_getaffinity"},
+	{124, "sched_yield"},
+	{125, "sched_get_priority_max"},
+	{126, "sched_get_priority_min"},
+	{127, "sched_rr_get_interval"},
+	{128, "restart_syscall"},
+	{129, "kill"},
+	{130, "tkill"},
+	{131, "tgkill"},
+	{132, "sigaltstack"},
+	{133, "rt_sigsuspend"},
+	{134, "rt_sigaction"},
+	{135, "rt_sigprocmask"},
+	{136, "rt_sigpending"},
+	{137, "rt_sigtimedwait"},
+	{138, "rt_sigqueueinfo"},
+	{139, "rt_sigreturn"},
+	{140, "setpriority"},
+	{141, "getpriority"},
+	{142, "reboot"},
+	{143, "setregid"},
+	{144, "setgid"},
+	{145, "setreuid"},
+	{146, "setuid"},
+	{147, "setresuid"},
+	{148, "getresuid"},
+	{149, "setresgid"},
+	{150, "getresgid"},
+	{151, "setfsuid"},
+	{152, "setfsgid"},
+	{153, "times"},
+	{154, "setpgid"},
+	{155, "getpgid"},
+	{156, "getsid"},
+	{157, "setsid"},
+	{158, "getgroups"},
+	{159, "setgroups"},
+	{160, "uname"},
+	{161, "sethostname"},
+	{162, "setdomainname"},
+	{163, "getrlimit"},
+	{164, "setrlimit"},
+	{165, "getrusage"},
+	{166, "umask"},
+	{167, "prctl"},
+	{168, "getcpu"},
+	{169, "gettimeofday"},
+	{170, "settimeofday"},
+	{171, "adjtimex"},
+	{172, "getpid"},
+	{173, "getppid"},
+	{174, "getuid"},
+	{175, "geteuid"},
+	{176, "getgid"},
+	{177, "getegid"},
+	{178, "gettid"},
+	{179, "sysinfo"},
+	{180, "mq_open"},
+	{181, "mq_unlink"},
+	{182, "mq_timedsend"},
+	{183, "mq_timedreceive"},
+	{184, "mq_notify"},
+	{185, "mq_getsetattr"},
+	{186, "msgget"},
+	{187, "msgctl"},
+	{188, "msgrcv"},
+	{189, "msgsnd"},
+	{190, "semget"},
+	{191, "semctl"},
+	{192, "semtimedop"},
+	{193, "semop"},
+	{194, "shmget"},
+	{195, "shmctl"},
+	{196, "shmat"},
+	{197, "shmdt"},
+	{198, "socket"},
+	{199, "socketpair"},
+	{200, "bind"},
+	{201, "listen"},
+	{202, "accept"},
+	{203, "connect"},
+	{204, "getsockname"},
+	{205, "getpeername"},
+	{206, "sendto"},
+	{207, "recvfrom"},
+	{208, "setsockopt"},
+	{209, "getsockopt"},
+	{210, "shutdown"},
+	{211, "sendmsg"},
+	{212, "recvmsg"},
+	{213, "readahead"},
+	{214, "brk"},
+	{215, "munmap"},
+	{216, "mremap"},
+	{217, "add_key"},
+	{218, "request_key"},
+	{219, "keyctl"},
+	{220, "clone"},
+	{221, "execve"},
+	{222, "mmap"},
+	{223, "fadvise64"},
+	{224, "swapon"},
+	{225, "swapoff"},
+	{226, "mprotect"},
+	{227, "msync"},
+	{228, "mlock"},
+	{229, "munlock"},
+	{230, "mlockall"},
+	{231, "munlockall"},
+	{232, "mincore"},
+	{233, "madvise"},
+	{234, "remap_file_pages"},
+	{235, "mbind"},
+	{236, "get_mempolicy"},
+	{237, "set_mempolicy"},
+	{238, "migrate_pages"},
+	{239, "move_pages"},
+	{240, "rt_tgsigqueueinfo"},
+	{241, "perf_event_open"},
+	{242, "accept4"},
+	{243, "recvmmsg"},
+	{244, "arch_specific_syscall"},
+	{260, "wait4"},
+	{261, "prlimit64"},
+	{262, "fanotify_init"},
+	{263, "fanotify_mark"},
+	{264, "name_to_handle_at"},
+	{265, "open_by_handle_at"},
+	{266, "clock_adjtime"},
+	{267, "syncfs"},
+	{268, "setns"},
+	{269, "sendmmsg"},
+	{270, "process_vm_readv"},
+	{271, "process_vm_writev"},
+	{272, "kcmp"},
+	{273, "finit_module"},
+	{274, "sched_setattr"},
+	{275, "sched_getattr"},
+	{276, "renameat2"},
+	{277, "seccomp"},
+	{278, "getrandom"},
+	{279, "memfd_create"},
+	{280, "bpf"},
+	{281, "execveat"},
+	{282, "userfaultfd"},
+	{283, "membarrier"},
+	{284, "mlock2"},
+	{285, "copy_file_range"},
+	{286, "preadv2"},
+	{287, "pwritev2"},
+	{288, "pkey_mprotect"},
+	{289, "pkey_alloc"},
+	{290, "pkey_free"},
+	{291, "statx"},
+	{292, "io_pgetevents"},
+	{293, "rseq"},
+	{294, "kexec_file_load"},
+	{403, "clock_gettime64"},
+	{404, "clock_settime64"},
+	{405, "clock_adjtime64"},
+	{406, "clock_getres_time64"},
+	{407, "clock_nanosleep_time64"},
+	{408, "timer_gettime64"},
+	{409, "timer_settime64"},
+	{410, "timerfd_gettime64"},
+	{411, "timerfd_settime64"},
+	{412, "utimensat_time64"},
+	{413, "pselect6_time64"},
+	{414, "ppoll_time64"},
+	{416, "io_pgetevents_time64"},
+	{417, "recvmmsg_time64"},
+	{418, "mq_timedsend_time64"},
+	{419, "mq_timedreceive_time64"},
+	{420, "semtimedop_time64"},
+	{421, "rt_sigtimedwait_time64"},
+	{422, "futex_time64"},
+	{423, "sched_rr_get_interval_time64"},
+	{424, "pidfd_send_signal"},
+	{425, "io_uring_setup"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{428, "open_tree"},
+	{429, "move_mount"},
+	{430, "fsopen"},
+	{431, "fsconfig"},
+	{432, "fsmount"},
+	{433, "fspick"},
+	{434, "pidfd_open"},
+	{435, "clone3"},
+	{436, "close_range"},
+	{437, "openat2"},
+	{438, "pidfd_getfd"},
+	{439, "faccessat2"},
+	{440, "process_madvise"},
+	{441, "epoll_pwait2"},
+	{442, "mount_setattr"},
+	{443, "quotactl_fd"},
+	{444, "landlock_create_ruleset"},
+	{445, "landlock_add_rule"},
+	{446, "landlock_restrict_self"},
+	{447, "memfd_secret"},
+	{448, "process_mrelease"},
+	{449, "futex_waitv"},
+	{450, "set_mempolicy_home_node"},
+	{451, "cachestat"},
+	{452, "fchmodat2"},
+	{453, "map_shadow_stack"},
+	{454, "futex_wake"},
+	{455, "futex_wait"},
+	{456, "futex_requeue"},
+	{457, "statmount"},
+	{458, "listmount"},
+	{459, "lsm_get_self_attr"},
+	{460, "lsm_set_self_attr"},
+	{461, "lsm_list_modules"},
+	{462, "mseal"},
+	{463, "setxattrat"},
+	{464, "getxattrat"},
+	{465, "listxattrat"},
+	{466, "removexattrat"},
+	{467, "open_tree_attr"},
 }
 
-var syscallsX32 = map[int]string{
-	0:   "read",
-	1:   "write",
-	2:   "open",
-	3:   "close",
-	4:   "stat",
-	5:   "fstat",
-	6:   "lstat",
-	7:   "poll",
-	8:   "lseek",
-	9:   "mmap",
-	10:  "mprotect",
-	11:  "munmap",
-	12:  "brk",
-	13:  "rt_sigaction",
-	14:  "rt_sigprocmask",
-	15:  "rt_sigreturn",
-	16:  "ioctl",
-	17:  "pread64",
-	18:  "pwrite64",
-	19:  "readv",
-	20:  "writev",
-	21:  "access",
-	22:  "pipe",
-	23:  "select",
-	24:  "sched_yield",
-	25:  "mremap",
-	26:  "msync",
-	27:  "mincore",
-	28:  "madvise",
-	29:  "shmget",
-	30:  "shmat",
-	31:  "shmctl",
-	32:  "dup",
-	33:  "dup2",
-	34:  "pause",
-	35:  "nanosleep",
-	36:  "getitimer",
-	37:  "alarm",
-	38:  "setitimer",
-	39:  "getpid",
-	40:  "sendfile",
-	41:  "socket",
-	42:  "connect",
-	43:  "accept",
-	44:  "sendto",
-	45:  "recvfrom",
-	46:  "sendmsg",
-	47:  "recvmsg",
-	48:  "shutdown",
-	49:  "bind",
-	50:  "listen",
-	51:  "getsockname",
-	52:  "getpeername",
-	53:  "socketpair",
-	54:  "setsockopt",
-	55:  "getsockopt",
-	56:  "clone",
-	57:  "fork",
-	58:  "vfork",
-	59:  "execve",
-	60:  "exit",
-	61:  "wait4",
-	62:  "kill",
-	63:  "uname",
-	64:  "semget",
-	65:  "semop",
-	66:  "semctl",
-	67:  "shmdt",
-	68:  "msgget",
-	69:  "msgsnd",
-	70:  "msgrcv",
-	71:  "msgctl",
-	72:  "fcntl",
-	73:  "flock",
-	74:  "fsync",
-	75:  "fdatasync",
-	76:  "truncate",
-	77:  "ftruncate",
-	78:  "getdents",
-	79:  "getcwd",
-	80:  "chdir",
-	81:  "fchdir",
-	82:  "rename",
-	83:  "mkdir",
-	84:  "rmdir",
-	85:  "creat",
-	86:  "link",
-	87:  "unlink",
-	88:  "symlink",
-	89:  "readlink",
-	90:  "chmod",
-	91:  "fchmod",
-	92:  "chown",
-	93:  "fchown",
-	94:  "lchown",
-	95:  "umask",
-	96:  "gettimeofday",
-	97:  "getrlimit",
-	98:  "getrusage",
-	99:  "sysinfo",
-	100: "times",
-	101: "ptrace",
-	102: "getuid",
-	103: "syslog",
-	104: "getgid",
-	105: "setuid",
-	106: "setgid",
-	107: "geteuid",
-	108: "getegid",
-	109: "setpgid",
-	110: "getppid",
-	111: "getpgrp",
-	112: "setsid",
-	113: "setreuid",
-	114: "setregid",
-	115: "getgroups",
-	116: "setgroups",
-	117: "setresuid",
-	118: "getresuid",
-	119: "setresgid",
-	120: "getresgid",
-	121: "getpgid",
-	122: "setfsuid",
-	123: "setfsgid",
-	124: "getsid",
-	125: "capget",
-	126: "capset",
-	127: "rt_sigpending",
-	128: "rt_sigtimedwait",
-	129: "rt_sigqueueinfo",
-	130: "rt_sigsuspend",
-	131: "sigaltstack",
-	132: "utime",
-	133: "mknod",
-	134: "uselib",
-	135: "personality",
-	136: "ustat",
-	137: "statfs",
-	138: "fstatfs",
-	139: "sysfs",
-	140: "getpriority",
-	141: "setpriority",
-	142: "sched_setparam",
-	143: "sched_getparam",
-	144: "sched_setscheduler",
-	145: "sched_getscheduler",
-	146: "sched_get_priority_max",
-	147: "sched_get_priority_min",
-	148: "sched_rr_get_interval",
-	149: "mlock",
-	150: "munlock",
-	151: "mlockall",
-	152: "munlockall",
-	153: "vhangup",
-	154: "modify_ldt",
-	155: "pivot_root",
-	156: "_sysctl",
-	157: "prctl",
-	158: "arch_prctl",
-	159: "adjtimex",
-	160: "setrlimit",
-	161: "chroot",
-	162: "sync",
-	163: "acct",
-	164: "settimeofday",
-	165: "mount",
-	166: "umount2",
-	167: "swapon",
-	168: "swapoff",
-	169: "reboot",
-	170: "sethostname",
-	171: "setdomainname",
-	172: "iopl",
-	173: "ioperm",
-	174: "create_module",
-	175: "init_module",
-	176: "delete_module",
-	177: "get_kernel_syms",
-	178: "query_module",
-	179: "quotactl",
-	180: "nfsservctl",
-	181: "getpmsg",
-	182: "putpmsg",
-	183: "afs_syscall",
-	184: "tuxcall",
-	185: "security",
-	186: "gettid",
-	187: "readahead",
-	188: "setxattr",
-	189: "lsetxattr",
-	190: "fsetxattr",
-	191: "getxattr",
-	192: "lgetxattr",
-	193: "fgetxattr",
-	194: "listxattr",
-	195: "llistxattr",
-	196: "flistxattr",
-	197: "removexattr",
-	198: "lremovexattr",
-	199: "fremovexattr",
-	200: "tkill",
-	201: "time",
-	202: "futex",
-	203: "sched_setaffinity",
-	204: "sched_getaffinity",
-	205: "set_thread_area",
-	206: "io_setup",
-	207: "io_destroy",
-	208: "io_getevents",
-	209: "io_submit",
-	210: "io_cancel",
-	211: "get_thread_area",
-	212: "lookup_dcookie",
-	213: "epoll_create",
-	214: "epoll_ctl_old",
-	215: "epoll_wait_old",
-	216: "remap_file_pages",
-	217: "getdents64",
-	218: "set_tid_address",
-	219: "restart_syscall",
-	220: "semtimedop",
-	221: "fadvise64",
-	222: "timer_create",
-	223: "timer_settime",
-	224: "timer_gettime",
-	225: "timer_getoverrun",
-	226: "timer_delete",
-	227: "clock_settime",
-	228: "clock_gettime",
-	229: "clock_getres",
-	230: "clock_nanosleep",
-	231: "exit_group",
-	232: "epoll_wait",
-	233: "epoll_ctl",
-	234: "tgkill",
-	235: "utimes",
-	236: "vserver",
-	237: "mbind",
-	238: "set_mempolicy",
-	239: "get_mempolicy",
-	240: "mq_open",
-	241: "mq_unlink",
-	242: "mq_timedsend",
-	243: "mq_timedreceive",
-	244: "mq_notify",
-	245: "mq_getsetattr",
-	246: "kexec_load",
-	247: "waitid",
-	248: "add_key",
-	249: "request_key",
-	250: "keyctl",
-	251: "ioprio_set",
-	252: "ioprio_get",
-	253: "inotify_init",
-	254: "inotify_add_watch",
-	255: "inotify_rm_watch",
-	256: "migrate_pages",
-	257: "openat",
-	258: "mkdirat",
-	259: "mknodat",
-	260: "fchownat",
-	261: "futimesat",
-	262: "newfstatat",
-	263: "unlinkat",
-	264: "renameat",
-	265: "linkat",
-	266: "symlinkat",
-	267: "readlinkat",
-	268: "fchmodat",
-	269: "faccessat",
-	270: "pselect6",
-	271: "ppoll",
-	272: "unshare",
-	273: "set_robust_list",
-	274: "get_robust_list",
-	275: "splice",
-	276: "tee",
-	277: "sync_file_range",
-	278: "vmsplice",
-	279: "move_pages",
-	280: "utimensat",
-	281: "epoll_pwait",
-	282: "signalfd",
-	283: "timerfd_create",
-	284: "eventfd",
-	285: "fallocate",
-	286: "timerfd_settime",
-	287: "timerfd_gettime",
-	288: "accept4",
-	289: "signalfd4",
-	290: "eventfd2",
-	291: "epoll_create1",
-	292: "dup3",
-	293: "pipe2",
-	294: "inotify_init1",
-	295: "preadv",
-	296: "pwritev",
-	297: "rt_tgsigqueueinfo",
-	298: "perf_event_open",
-	299: "recvmmsg",
-	300: "fanotify_init",
-	301: "fanotify_mark",
-	302: "prlimit64",
-	303: "name_to_handle_at",
-	304: "open_by_handle_at",
-	305: "clock_adjtime",
-	306: "syncfs",
-	307: "sendmmsg",
-	308: "setns",
-	309: "getcpu",
-	310: "process_vm_readv",
-	311: "process_vm_writev",
-	312: "kcmp",
-	313: "finit_module",
-	314: "sched_setattr",
-	315: "sched_getattr",
-	316: "renameat2",
-	317: "seccomp",
-	318: "getrandom",
-	319: "memfd_create",
-	320: "kexec_file_load",
-	321: "bpf",
-	322: "execveat",
-	323: "userfaultfd",
-	324: "membarrier",
-	325: "mlock2",
-	326: "copy_file_range",
-	327: "preadv2",
-	328: "pwritev2",
-	329: "pkey_mprotect",
-	330: "pkey_alloc",
-	331: "pkey_free",
-	332: "statx",
-	333: "io_pgetevents",
-	334: "rseq",
-	335: "uretprobe",
-	424: "pidfd_send_signal",
-	425: "io_uring_setup",
-	426: "io_uring_enter",
-	427: "io_uring_register",
-	428: "open_tree",
-	429: "move_mount",
-	430: "fsopen",
-	431: "fsconfig",
-	432: "fsmount",
-	433: "fspick",
-	434: "pidfd_open",
-	435: "clone3",
-	436: "close_range",
-	437: "openat2",
-	438: "pidfd_getfd",
-	439: "faccessat2",
-	440: "process_madvise",
-	441: "epoll_pwait2",
-	442: "mount_setattr",
-	443: "quotactl_fd",
-	444: "landlock_create_ruleset",
-	445: "landlock_add_rule",
-	446: "landlock_restrict_self",
-	447: "memfd_secret",
-	448: "process_mrelease",
-	449: "futex_waitv",
-	450: "set_mempolicy_home_node",
-	451: "cachestat",
-	452: "fchmodat2",
-	453: "map_shadow_stack",
-	454: "futex_wake",
-	455: "futex_wait",
-	456: "futex_requeue",
-	457: "statmount",
-	458: "listmount",
-	459: "lsm_get_self_attr",
-	460: "lsm_set_self_attr",
-	461: "lsm_list_modules",
-	462: "mseal",
-	463: "setxattrat",
-	464: "getxattrat",
-	465: "listxattrat",
-	466: "removexattrat",
-	467: "open_tree_attr",
-	512: "rt_sigaction",
-	513: "rt_sigreturn",
-	514: "ioctl",
-	515: "readv",
-	516: "writev",
-	517: "recvfrom",
-	518: "sendmsg",
-	519: "recvmsg",
-	520: "execve",
-	521: "ptrace",
-	522: "rt_sigpending",
-	523: "rt_sigtimedwait",
-	524: "rt_sigqueueinfo",
-	525: "sigaltstack",
-	526: "timer_create",
-	527: "mq_notify",
-	528: "kexec_load",
-	529: "waitid",
-	530: "set_robust_list",
-	531: "get_robust_list",
-	532: "vmsplice",
-	533: "move_pages",
-	534: "preadv",
-	535: "pwritev",
-	536: "rt_tgsigqueueinfo",
-	537: "recvmmsg",
-	538: "sendmmsg",
-	539: "process_vm_readv",
-	540: "process_vm_writev",
-	541: "setsockopt",
-	542: "getsockopt",
-	543: "io_setup",
-	544: "io_submit",
-	545: "execveat",
-	546: "preadv2",
-	547: "pwritev2",
+var syscallsByNameAARCH64 = []syscallEntry{
+	{202, "accept"},
+	{242, "accept4"},
+	{89, "acct"},
+	{217, "add_key"},
+	{171, "adjtimex"},
+	{244, "arch_specific_syscall"},
+	{200, "bind"},
+	{280, "bpf"},
+	{214, "brk"},
+	{451, "cachestat"},
+	{90, "capget"},
+	{91, "capset"},
+	{49, "chdir"},
+	{51, "chroot"},
+	{266, "clock_adjtime"},
+	{405, "clock_adjtime64"},
+	{114, "clock_getres"},
+	{406, "clock_getres_time64"},
+	{113, "clock_gettime"},
+	{403, "clock_gettime64"},
+	{115, "clock_nanosleep"},
+	{407, "clock_nanosleep_time64"},
+	{112, "clock_settime"},
+	{404, "clock_settime64"},
+	{220, "clone"},
+	{435, "clone3"},
+	{57, "close"},
+	{436, "close_range"},
+	{203, "connect"},
+	{285, "copy_file_range"},
+	{106, "delete_module"},
+	{23, "dup"},
+	{24, "dup3"},
+	{20, "epoll_create1"},
+	{21, "epoll_ctl"},
+	{22, "epoll_pwait"},
+	{441, "epoll_pwait2"},
+	{19, "eventfd2"},
+	{221, "execve"},
+	{281, "execveat"},
+	{93, "exit"},
+	{94, "exit_group"},
+	{48, "faccessat"},
+	{439, "faccessat2"},
+	{223, "fadvise64"},
+	{47, "fallocate"},
+	{262, "fanotify_init"},
+	{263, "fanotify_mark"},
+	{50, "fchdir"},
+	{52, "fchmod"},
+	{53, "fchmodat"},
+	{452, "fchmodat2"},
+	{55, "fchown"},
+	{54, "fchownat"},
+	{25, "fcntl"},
+	{83, "fdatasync"},
+	{10, "fgetxattr"},
+	{273, "finit_module"},
+	{13, "flistxattr"},
+	{32, "flock"},
+	{16, "fremovexattr"},
+	{431, "fsconfig"},
+	{7, "fsetxattr"},
+	{432, "fsmount"},
+	{430, "fsopen"},
+	{433, "fspick"},
+	{80, "fstat"},
+	{79, "fstatat"},
+	{44, "fstatfs"},
+	{82, "fsync"},
+	{46, "ftruncate"},
+	{98, "futex"},
+	{456, "futex_requeue"},
+	{422, "futex_time64"},
+	{455, "futex_wait"},
+	{449, "futex_waitv"},
+	{454, "futex_wake"},
+	{236, "get_mempolicy"},
+	{100, "get_robust_list"},
+	{168, "getcpu"},
+	{17, "getcwd"},
+	{61, "getdents64"},
+	{177, "getegid"},
+	{175, "geteuid"},
+	{176, "getgid"},
+	{158, "getgroups"},
+	{102, "getitimer"},
+	{205, "getpeername"},
+	{155, "getpgid"},
+	{172, "getpid"},
+	{173, "getppid"},
+	{141, "getpriority"},
+	{278, "getrandom"},
+	{150, "getresgid"},
+	{148, "getresuid"},
+	{163, "getrlimit"},
+	{165, "getrusage"},
+	{156, "getsid"},
+	{204, "getsockname"},
+	{209, "getsockopt"},
+	{178, "gettid"},
+	{169, "gettimeofday"},
+	{174, "getuid"},
+	{8, "getxattr"},
+	{464, "getxattrat"},
+	{105, "init_module"},
+	{27, "inotify_add_watch"},
+	{26, "inotify_init1"},
+	{28, "inotify_rm_watch"},
+	{3, "io_cancel"},
+	{1, "io_destroy"},
+	{4, "io_getevents"},
+	{292, "io_pgetevents"},
+	{416, "io_pgetevents_time64"},
+	{0, "io_setup"},
+	{2, "io_submit"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{425, "io_uring_setup"},
+	{29, "ioctl"},
+	{31, "ioprio_get"},
+	{30, "ioprio_set"},
+	{272, "kcmp"},
+	{294, "kexec_file_load"},
+	{104, "kexec_load"},
+	{219, "keyctl"},
+	{129, "kill"},
+	{445, "landlock_add_rule"},
+	{444, "landlock_create_ruleset"},
+	{446, "landlock_restrict_self"},
+	{9, "lgetxattr"},
+	{37, "linkat"},
+	{201, "listen"},
+	{458, "listmount"},
+	{11, "listxattr"},
+	{465, "listxattrat"},
+	{12, "llistxattr"},
+	{18, "lookup_dcookie"},
+	{15, "lremovexattr"},
+	{62, "lseek"},
+	{6, "lsetxattr"},
+	{459, "lsm_get_self_attr"},
+	{461, "lsm_list_modules"},
+	{460, "lsm_set_self_attr"},
+	{233, "madvise"},
+	{453, "map_shadow_stack"},
+	{235, "mbind"},
+	{283, "membarrier"},
+	{279, "memfd_create"},
+	{447, "memfd_secret"},
+	{238, "migrate_pages"},
+	{232, "mincore"},
+	{34, "mkdirat"},
+	{33, "mknodat"},
+	{228, "mlock"},
+	{284, "mlock2"},
+	{230, "mlockall"},
+	{222, "mmap"},
+	{40, "mount"},
+	{442, "mount_setattr"},
+	{429, "move_mount"},
+	{239, "move_pages"},
+	{226, "mprotect"},
+	{185, "mq_getsetattr"},
+	{184, "mq_notify"},
+	{180, "mq_open"},
+	{183, "mq_timedreceive"},
+	{419, "mq_timedreceive_time64"},
+	{182, "mq_timedsend"},
+	{418, "mq_timedsend_time64"},
+	{181, "mq_unlink"},
+	{216, "mremap"},
+	{462, "mseal"},
+	{187, "msgctl"},
+	{186, "msgget"},
+	{188, "msgrcv"},
+	{189, "msgsnd"},
+	{227, "msync"},
+	{229, "munlock"},
+	{231, "munlockall"},
+	{215, "munmap"},
+	{264, "name_to_handle_at"},
+	{101, "nanosleep"},
+	{42, "nfsservctl"},
+	{265, "open_by_handle_at"},
+	{428, "open_tree"},
+	{467, "open_tree_attr"},
+	{56, "openat"},
+	{437, "openat2"},
+	{241, "perf_event_open"},
+	{92, "personality"},
+	{438, "pidfd_getfd"},
+	{434, "pidfd_open"},
+	{424, "pidfd_send_signal"},
+	{59, "pipe2"},
+	{41, "pivot_root"},
+	{289, "pkey_alloc"},
+	{290, "pkey_free"},
+	{288, "pkey_mprotect"},
+	{73, "ppoll"},
+	{414, "ppoll_time64"},
+	{167, "prctl"},
+	{67, "pread64"},
+	{69, "preadv"},
+	{286, "preadv2"},
+	{261, "prlimit64"},
+	{440, "process_madvise"},
+	{448, "process_mrelease"},
+	{270, "process_vm_readv"},
+	{271, "process_vm_writev"},
+	{72, "pselect6"},
+	{413, "pselect6_time64"},
+	{117, "ptrace"},
+	{68, "pwrite64"},
+	{70, "pwritev"},
+	{287, "pwritev2"},
+	{60, "quotactl"},
+	{443, "quotactl_fd"},
+	{63, "read"},
+	{213, "readahead"},
+	{78, "readlinkat"},
+	{65, "readv"},
+	{142, "reboot"},
+	{207, "recvfrom"},
+	{243, "recvmmsg"},
+	{417, "recvmmsg_time64"},
+	{212, "recvmsg"},
+	{234, "remap_file_pages"},
+	{14, "removexattr"},
+	{466, "removexattrat"},
+	{38, "renameat"},
+	{276, "renameat2"},
+	{218, "request_key"},
+	{128, "restart_syscall"},
+	{293, "rseq"},
+	{134, "rt_sigaction"},
+	{136, "rt_sigpending"},
+	{135, "rt_sigprocmask"},
+	{138, "rt_sigqueueinfo"},
+	{139, "rt_sigreturn"},
+	{133, "rt_sigsuspend"},
+	{137, "rt_sigtimedwait"},
+	{421, "rt_sigtimedwait_time64"},
+	{240, "rt_tgsigqueueinfo"},
+	{125, "sched_get_priority_max"},
+	{126, "sched_get_priority_min"},
+	{123, "sched_getaffinity"},
+	{275, "sched_getattr"},
+	{121, "sched_getparam"},
+	{120, "sched_getscheduler"},
+	{127, "sched_rr_get_interval"},
+	{423, "sched_rr_get_interval_time64"},
+	{122, "sched_setaffinity"},
+	{274, "sched_setattr"},
+	{118, "sched_setparam"},
+	{119, "sched_setscheduler"},
+	{124, "sched_yield"},
+	{277, "seccomp"},
+	{191, "semctl"},
+	{190, "semget"},
+	{193, "semop"},
+	{192, "semtimedop"},
+	{420, "semtimedop_time64"},
+	{71, "sendfile"},
+	{269, "sendmmsg"},
+	{211, "sendmsg"},
+	{206, "sendto"},
+	{237, "set_mempolicy"},
+	{450, "set_mempolicy_home_node"},
+	{99, "set_robust_list"},
+	{96, "set_tid_address"},
+	{162, "setdomainname"},
+	{152, "setfsgid"},
+	{151, "setfsuid"},
+	{144, "setgid"},
+	{159, "setgroups"},
+	{161, "sethostname"},
+	{103, "setitimer"},
+	{268, "setns"},
+	{154, "setpgid"},
+	{140, "setpriority"},
+	{143, "setregid"},
+	{149, "setresgid"},
+	{147, "setresuid"},
+	{145, "setreuid"},
+	{164, "setrlimit"},
+	{157, "setsid"},
+	{208, "setsockopt"},
+	{170, "settimeofday"},
+	{146, "setuid"},
+	{5, "setxattr"},
+	{463, "setxattrat"},
+	{196, "shmat"},
+	{195, "shmctl"},
+	{197, "shmdt"},
+	{194, "shmget"},
+	{210, "shutdown"},
+	{132, "sigaltstack"},
+	{74, "signalfd4"},
+	{198, "socket"},
+	{199, "socketpair"},
+	{76, "splice"},
+	{43, "statfs"},
+	{457, "statmount"},
+	{291, "statx"},
+	{225, "swapoff"},
+	{224, "swapon"},
+	{36, "symlinkat"},
+	{81, "sync"},
+	{84, "sync_file_range"},
+	{267, "syncfs"},
+	{179, "sysinfo"},
+	{116, "syslog"},
+	{77, "tee"},
+	{131, "tgkill"},
+	{107, "timer_create"},
+	{111, "timer_delete"},
+	{109, "timer_getoverrun"},
+	{108, "timer_gettime"},
+	{408, "timer_gettime64"},
+	{110, "timer_settime"},
+	{409, "timer_settime64"},
+	{85, "timerfd_create"},
+	{87, "timerfd_gettime"},
+	{410, "timerfd_gettime64"},
+	{86, "timerfd_settime"},
+	{411, "timerfd_settime64"},
+	{153, "times"},
+	{130, "tkill"},
+	{45, "truncate"},
+	{166, "umask"},
+	{39, "umount2"},
+	{160, "uname"},
+	{35, "unlinkat"},
+	{97, "unshare"},
+	{282, "userfaultfd"},
+	{88, "utimensat"},
+	{412, "utimensat_time64"},
+	{58, "vhangup"},
+	{75, "vmsplice"},
+	{260, "wait4"},
+	{95, "waitid"},
+	{64, "write"},
+	{66, "writev"},
 }
 
-var syscallsX86_64 = map[int]string{
-	0:   "read",
-	1:   "write",
-	2:   "open",
-	3:   "close",
-	4:   "stat",
-	5:   "fstat",
-	6:   "lstat",
-	7:   "poll",
-	8:   "lseek",
-	9:   "mmap",
-	10:  "mprotect",
-	11:  "munmap",
-	12:  "brk",
-	13:  "rt_sigaction",
-	14:  "rt_sigprocmask",
-	15:  "rt_sigreturn",
-	16:  "ioctl",
-	17:  "pread64",
-	18:  "pwrite64",
-	19:  "readv",
-	20:  "writev",
-	21:  "access",
-	22:  "pipe",
-	23:  "select",
-	24:  "sched_yield",
-	25:  "mremap",
-	26:  "msync",
-	27:  "mincore",
-	28:  "madvise",
-	29:  "shmget",
-	30:  "shmat",
-	31:  "shmctl",
-	32:  "dup",
-	33:  "dup2",
-	34:  "pause",
-	35:  "nanosleep",
-	36:  "getitimer",
-	37:  "alarm",
-	38:  "setitimer",
-	39:  "getpid",
-	40:  "sendfile",
-	41:  "socket",
-	42:  "connect",
-	43:  "accept",
-	44:  "sendto",
-	45:  "recvfrom",
-	46:  "sendmsg",
-	47:  "recvmsg",
-	48:  "shutdown",
-	49:  "bind",
-	50:  "listen",
-	51:  "getsockname",
-	52:  "getpeername",
-	53:  "socketpair",
-	54:  "setsockopt",
-	55:  "getsockopt",
-	56:  "clone",
-	57:  "fork",
-	58:  "vfork",
-	59:  "execve",
-	60:  "exit",
-	61:  "wait4",
-	62:  "kill",
-	63:  "uname",
-	64:  "semget",
-	65:  "semop",
-	66:  "semctl",
-	67:  "shmdt",
-	68:  "msgget",
-	69:  "msgsnd",
-	70:  "msgrcv",
-	71:  "msgctl",
-	72:  "fcntl",
-	73:  "flock",
-	74:  "fsync",
-	75:  "fdatasync",
-	76:  "truncate",
-	77:  "ftruncate",
-	78:  "getdents",
-	79:  "getcwd",
-	80:  "chdir",
-	81:  "fchdir",
-	82:  "rename",
-	83:  "mkdir",
-	84:  "rmdir",
-	85:  "creat",
-	86:  "link",
-	87:  "unlink",
-	88:  "symlink",
-	89:  "readlink",
-	90:  "chmod",
-	91:  "fchmod",
-	92:  "chown",
-	93:  "fchown",
-	94:  "lchown",
-	95:  "umask",
-	96:  "gettimeofday",
-	97:  "getrlimit",
-	98:  "getrusage",
-	99:  "sysinfo",
-	100: "times",
-	101: "ptrace",
-	102: "getuid",
-	103: "syslog",
-	104: "getgid",
-	105: "setuid",
-	106: "setgid",
-	107: "geteuid",
-	108: "getegid",
-	109: "setpgid",
-	110: "getppid",
-	111: "getpgrp",
-	112: "setsid",
-	113: "setreuid",
-	114: "setregid",
-	115: "getgroups",
-	116: "setgroups",
-	117: "setresuid",
-	118: "getresuid",
-	119: "setresgid",
-	120: "getresgid",
-	121: "getpgid",
-	122: "setfsuid",
-	123: "setfsgid",
-	124: "getsid",
-	125: "capget",
-	126: "capset",
-	127: "rt_sigpending",
-	128: "rt_sigtimedwait",
-	129: "rt_sigqueueinfo",
-	130: "rt_sigsuspend",
-	131: "sigaltstack",
-	132: "utime",
-	133: "mknod",
-	134: "uselib",
-	135: "personality",
-	136: "ustat",
-	137: "statfs",
-	138: "fstatfs",
-	139: "sysfs",
-	140: "getpriority",
-	141: "setpriority",
-	142: "sched_setparam",
-	143: "sched_getparam",
-	144: "sched_setscheduler",
-	145: "sched_getscheduler",
-	146: "sched_get_priority_max",
-	147: "sched_get_priority_min",
-	148: "sched_rr_get_interval",
-	149: "mlock",
-	150: "munlock",
-	151: "mlockall",
-	152: "munlockall",
-	153: "vhangup",
-	154: "modify_ldt",
-	155: "pivot_root",
-	156: "_sysctl",
-	157: "prctl",
-	158: "arch_prctl",
-	159: "adjtimex",
-	160: "setrlimit",
-	161: "chroot",
-	162: "sync",
-	163: "acct",
-	164: "settimeofday",
-	165: "mount",
-	166: "umount2",
-	167: "swapon",
-	168: "swapoff",
-	169: "reboot",
-	170: "sethostname",
-	171: "setdomainname",
-	172: "iopl",
-	173: "ioperm",
-	174: "create_module",
-	175: "init_module",
-	176: "delete_module",
-	177: "get_kernel_syms",
-	178: "query_module",
-	179: "quotactl",
-	180: "nfsservctl",
-	181: "getpmsg",
-	182: "putpmsg",
-	183: "afs_syscall",
-	184: "tuxcall",
-	185: "security",
-	186: "gettid",
-	187: "readahead",
-	188: "setxattr",
-	189: "lsetxattr",
-	190: "fsetxattr",
-	191: "getxattr",
-	192: "lgetxattr",
-	193: "fgetxattr",
-	194: "listxattr",
-	195: "llistxattr",
-	196: "flistxattr",
-	197: "removexattr",
-	198: "lremovexattr",
-	199: "fremovexattr",
-	200: "tkill",
-	201: "time",
-	202: "futex",
-	203: "sched_setaffinity",
-	204: "sched_getaffinity",
-	205: "set_thread_area",
-	206: "io_setup",
-	207: "io_destroy",
-	208: "io_getevents",
-	209: "io_submit",
-	210: "io_cancel",
-	211: "get_thread_area",
-	212: "lookup_dcookie",
-	213: "epoll_create",
-	214: "epoll_ctl_old",
-	215: "epoll_wait_old",
-	216: "remap_file_pages",
-	217: "getdents64",
-	218: "set_tid_address",
-	219: "restart_syscall",
-	220: "semtimedop",
-	221: "fadvise64",
-	222: "timer_create",
-	223: "timer_settime",
-	224: "timer_gettime",
-	225: "timer_getoverrun",
-	226: "timer_delete",
-	227: "clock_settime",
-	228: "clock_gettime",
-	229: "clock_getres",
-	230: "clock_nanosleep",
-	231: "exit_group",
-	232: "epoll_wait",
-	233: "epoll_ctl",
-	234: "tgkill",
-	235: "utimes",
-	236: "vserver",
-	237: "mbind",
-	238: "set_mempolicy",
-	239: "get_mempolicy",
-	240: "mq_open",
-	241: "mq_unlink",
-	242: "mq_timedsend",
-	243: "mq_timedreceive",
-	244: "mq_notify",
-	245: "mq_getsetattr",
-	246: "kexec_load",
-	247: "waitid",
-	248: "add_key",
-	249: "request_key",
-	250: "keyctl",
-	251: "ioprio_set",
-	252: "ioprio_get",
-	253: "inotify_init",
-	254: "inotify_add_watch",
-	255: "inotify_rm_watch",
-	256: "migrate_pages",
-	257: "openat",
-	258: "mkdirat",
-	259: "mknodat",
-	260: "fchownat",
-	261: "futimesat",
-	262: "newfstatat",
-	263: "unlinkat",
-	264: "renameat",
-	265: "linkat",
-	266: "symlinkat",
-	267: "readlinkat",
-	268: "fchmodat",
-	269: "faccessat",
-	270: "pselect6",
-	271: "ppoll",
-	272: "unshare",
-	273: "set_robust_list",
-	274: "get_robust_list",
-	275: "splice",
-	276: "tee",
-	277: "sync_file_range",
-	278: "vmsplice",
-	279: "move_pages",
-	280: "utimensat",
-	281: "epoll_pwait",
-	282: "signalfd",
-	283: "timerfd_create",
-	284: "eventfd",
-	285: "fallocate",
-	286: "timerfd_settime",
-	287: "timerfd_gettime",
-	288: "accept4",
-	289: "signalfd4",
-	290: "eventfd2",
-	291: "epoll_create1",
-	292: "dup3",
-	293: "pipe2",
-	294: "inotify_init1",
-	295: "preadv",
-	296: "pwritev",
-	297: "rt_tgsigqueueinfo",
-	298: "perf_event_open",
-	299: "recvmmsg",
-	300: "fanotify_init",
-	301: "fanotify_mark",
-	302: "prlimit64",
-	303: "name_to_handle_at",
-	304: "open_by_handle_at",
-	305: "clock_adjtime",
-	306: "syncfs",
-	307: "sendmmsg",
-	308: "setns",
-	309: "getcpu",
-	310: "process_vm_readv",
-	311: "process_vm_writev",
-	312: "kcmp",
-	313: "finit_module",
-	314: "sched_setattr",
-	315: "sched_getattr",
-	316: "renameat2",
-	317: "seccomp",
-	318: "getrandom",
-	319: "memfd_create",
-	320: "kexec_file_load",
-	321: "bpf",
-	322: "execveat",
-	323: "userfaultfd",
-	324: "membarrier",
-	325: "mlock2",
-	326: "copy_file_range",
-	327: "preadv2",
-	328: "pwritev2",
-	329: "pkey_mprotect",
-	330: "pkey_alloc",
-	331: "pkey_free",
-	332: "statx",
-	333: "io_pgetevents",
-	334: "rseq",
-	335: "uretprobe",
-	424: "pidfd_send_signal",
-	425: "io_uring_setup",
-	426: "io_uring_enter",
-	427: "io_uring_register",
-	428: "open_tree",
-	429: "move_mount",
-	430: "fsopen",
-	431: "fsconfig",
-	432: "fsmount",
-	433: "fspick",
-	434: "pidfd_open",
-	435: "clone3",
-	436: "close_range",
-	437: "openat2",
-	438: "pidfd_getfd",
-	439: "faccessat2",
-	440: "process_madvise",
-	441: "epoll_pwait2",
-	442: "mount_setattr",
-	443: "quotactl_fd",
-	444: "landlock_create_ruleset",
-	445: "landlock_add_rule",
-	446: "landlock_restrict_self",
-	447: "memfd_secret",
-	448: "process_mrelease",
-	449: "futex_waitv",
-	450: "set_mempolicy_home_node",
-	451: "cachestat",
-	452: "fchmodat2",
-	453: "map_shadow_stack",
-	454: "futex_wake",
-	455: "futex_wait",
-	456: "futex_requeue",
-	457: "statmount",
-	458: "listmount",
-	459: "lsm_get_self_attr",
-	460: "lsm_set_self_attr",
-	461: "lsm_list_modules",
-	462: "mseal",
-	463: "setxattrat",
-	464: "getxattrat",
-	465: "listxattrat",
-	466: "removexattrat",
-	467: "open_tree_attr",
+var syscalls386 = []syscallEntry{
+	{0, "restart_syscall"},
+	{1, "exit"},
+	{2, "fork"},
+	{3, "read"},
+	{4, "write"},
+	{5, "open"},
+	{6, "close"},
+	{7, "waitpid"},
+	{8, "creat"},
+	{9, "link"},
+	{10, "unlink"},
+	{11, "execve"},
+	{12, "chdir"},
+	{13, "time"},
+	{14, "mknod"},
+	{15, "chmod"},
+	{16, "lchown"},
+	{17, "break"},
+	{18, "oldstat"},
+	{19, "lseek"},
+	{20, "getpid"},
+	{21, "mount"},
+	{22, "umount"},
+	{23, "setuid"},
+	{24, "getuid"},
+	{25, "stime"},
+	{26, "ptrace"},
+	{27, "alarm"},
+	{28, "oldfstat"},
+	{29, "pause"},
+	{30, "utime"},
+	{31, "stty"},
+	{32, "gtty"},
+	{33, "access"},
+	{34, "nice"},
+	{35, "ftime"},
+	{36, "sync"},
+	{37, "kill"},
+	{38, "rename"},
+	{39, "mkdir"},
+	{40, "rmdir"},
+	{41, "dup"},
+	{42, "pipe"},
+	{43, "times"},
+	{44, "prof"},
+	{45, "brk"},
+	{46, "setgid"},
+	{47, "getgid"},
+	{48, "signal"},
+	{49, "geteuid"},
+	{50, "getegid"},
+	{51, "acct"},
+	{52, "umount2"},
+	{53, "lock"},
+	{54, "ioctl"},
+	{55, "fcntl"},
+	{56, "mpx"},
+	{57, "setpgid"},
+	{58, "ulimit"},
+	{59, "oldolduname"},
+	{60, "umask"},
+	{61, "chroot"},
+	{62, "ustat"},
+	{63, "dup2"},
+	{64, "getppid"},
+	{65, "getpgrp"},
+	{66, "setsid"},
+	{67, "sigaction"},
+	{68, "sgetmask"},
+	{69, "ssetmask"},
+	{70, "setreuid"},
+	{71, "setregid"},
+	{72, "sigsuspend"},
+	{73, "sigpending"},
+	{74, "sethostname"},
+	{75, "setrlimit"},
+	{76, "getrlimit"},
+	{77, "getrusage"},
+	{78, "gettimeofday"},
+	{79, "settimeofday"},
+	{80, "getgroups"},
+	{81, "setgroups"},
+	{82, "select"},
+	{83, "symlink"},
+	{84, "oldlstat"},
+	{85, "readlink"},
+	{86, "uselib"},
+	{87, "swapon"},
+	{88, "reboot"},
+	{89, "readdir"},
+	{90, "mmap"},
+	{91, "munmap"},
+	{92, "truncate"},
+	{93, "ftruncate"},
+	{94, "fchmod"},
+	{95, "fchown"},
+	{96, "getpriority"},
+	{97, "setpriority"},
+	{98, "profil"},
+	{99, "statfs"},
+	{100, "fstatfs"},
+	{101, "ioperm"},
+	{102, "socketcall"},
+	{103, "syslog"},
+	{104, "setitimer"},
+	{105, "getitimer"},
+	{106, "stat"},
+	{107, "lstat"},
+	{108, "fstat"},
+	{109, "olduname"},
+	{110, "iopl"},
+	{111, "vhangup"},
+	{112, "idle"},
+	{113, "vm86old"},
+	{114, "wait4"},
+	{115, "swapoff"},
+	{116, "sysinfo"},
+	{117, "ipc"},
+	{118, "fsync"},
+	{119, "sigreturn"},
+	{120, "clone"},
+	{121, "setdomainname"},
+	{122, "uname"},
+	{123, "modify_ldt"},
+	{124, "adjtimex"},
+	{125, "mprotect"},
+	{126, "sigprocmask"},
+	{127, "create_module"},
+	{128, "init_module"},
+	{129, "delete_module"},
+	{130, "get_kernel_syms"},
+	{131, "quotactl"},
+	{132, "getpgid"},
+	{133, "fchdir"},
+	{134, "bdflush"},
+	{135, "sysfs"},
+	{136, "personality"},
+	{137, "afs_syscall"},
+	{138, "setfsuid"},
+	{139, "setfsgid"},
+	{140, "_llseek"},
+	{141, "getdents"},
+	{142, "_newselect"},
+	{143, "flock"},
+	{144, "msync"},
+	{145, "readv"},
+	{146, "writev"},
+	{147, "getsid"},
+	{148, "fdatasync"},
+	{149, "_sysctl"},
+	{150, "mlock"},
+	{151, "munlock"},
+	{152, "mlockall"},
+	{153, "munlockall"},
+	{154, "sched_setparam"},
+	{155, "sched_getparam"},
+	{156, "sched_setscheduler"},
+	{157, "sched_getscheduler"},
+	{158, "sched_yield"},
+	{159, "sched_get_priority_max"},
+	{160, "sched_get_priority_min"},
+	{161, "sched_rr_get_interval"},
+	{162, "nanosleep"},
+	{163, "mremap"},
+	{164, "setresuid"},
+	{165, "getresuid"},
+	{166, "vm86"},
+	{167, "query_module"},
+	{168, "poll"},
+	{169, "nfsservctl"},
+	{170, "setresgid"},
+	{171, "getresgid"},
+	{172, "prctl"},
+	{173, "rt_sigreturn"},
+	{174, "rt_sigaction"},
+	{175, "rt_sigprocmask"},
+	{176, "rt_sigpending"},
+	{177, "rt_sigtimedwait"},
+	{178, "rt_sigqueueinfo"},
+	{179, "rt_sigsuspend"},
+	{180, "pread64"},
+	{181, "pwrite64"},
+	{182, "chown"},
+	{183, "getcwd"},
+	{184, "capget"},
+	{185, "capset"},
+	{186, "sigaltstack"},
+	{187, "sendfile"},
+	{188, "getpmsg"},
+	{189, "putpmsg"},
+	{190, "vfork"},
+	{191, "ugetrlimit"},
+	{192, "mmap2"},
+	{193, "truncate64"},
+	{194, "ftruncate64"},
+	{195, "stat64"},
+	{196, "lstat64"},
+	{197, "fstat64"},
+	{198, "lchown32"},
+	{199, "getuid32"},
+	{200, "getgid32"},
+	{201, "geteuid32"},
+	{202, "getegid32"},
+	{203, "setreuid32"},
+	{204, "setregid32"},
+	{205, "getgroups32"},
+	{206, "setgroups32"},
+	{207, "fchown32"},
+	{208, "setresuid32"},
+	{209, "getresuid32"},
+	{210, "setresgid32"},
+	{211, "getresgid32"},
+	{212, "chown32"},
+	{213, "setuid32"},
+	{214, "setgid32"},
+	{215, "setfsuid32"},
+	{216, "setfsgid32"},
+	{217, "pivot_root"},
+	{218, "mincore"},
+	{219, "madvise"},
+	{220, "getdents64"},
+	{221, "fcntl64"},
+	{224, "gettid"},
+	{225, "readahead"},
+	{226, "setxattr"},
+	{227, "lsetxattr"},
+	{228, "fsetxattr"},
+	{229, "getxattr"},
+	{230, "lgetxattr"},
+	{231, "fgetxattr"},
+	{232, "listxattr"},
+	{233, "llistxattr"},
+	{234, "flistxattr"},
+	{235, "removexattr"},
+	{236, "lremovexattr"},
+	{237, "fremovexattr"},
+	{238, "tkill"},
+	{239, "sendfile64"},
+	{240, "futex"},
+	{241, "sched_setaffinity"},
+	{242, "sched_getaffinity"},
+	{243, "set_thread_area"},
+	{244, "get_thread_area"},
+	{245, "io_setup"},
+	{246, "io_destroy"},
+	{247, "io_getevents"},
+	{248, "io_submit"},
+	{249, "io_cancel"},
+	{250, "fadvise64"},
+	{252, "exit_group"},
+	{253, "lookup_dcookie"},
+	{254, "epoll_create"},
+	{255, "epoll_ctl"},
+	{256, "epoll_wait"},
+	{257, "remap_file_pages"},
+	{258, "set_tid_address"},
+	{259, "timer_create"},
+	{260, "timer_settime"},
+	{261, "timer_gettime"},
+	{262, "timer_getoverrun"},
+	{263, "timer_delete"},
+	{264, "clock_settime"},
+	{265, "clock_gettime"},
+	{266, "clock_getres"},
+	{267, "clock_nanosleep"},
+	{268, "statfs64"},
+	{269, "fstatfs64"},
+	{270, "tgkill"},
+	{271, "utimes"},
+	{272, "fadvise64_64"},
+	{273, "vserver"},
+	{274, "mbind"},
+	{275, "get_mempolicy"},
+	{276, "set_mempolicy"},
+	{277, "mq_open"},
+	{278, "mq_unlink"},
+	{279, "mq_timedsend"},
+	{280, "mq_timedreceive"},
+	{281, "mq_notify"},
+	{282, "mq_getsetattr"},
+	{283, "kexec_load"},
+	{284, "waitid"},
+	{286, "add_key"},
+	{287, "request_key"},
+	{288, "keyctl"},
+	{289, "ioprio_set"},
+	{290, "ioprio_get"},
+	{291, "inotify_init"},
+	{292, "inotify_add_watch"},
+	{293, "inotify_rm_watch"},
+	{294, "migrate_pages"},
+	{295, "openat"},
+	{296, "mkdirat"},
+	{297, "mknodat"},
+	{298, "fchownat"},
+	{299, "futimesat"},
+	{300, "fstatat64"},
+	{301, "unlinkat"},
+	{302, "renameat"},
+	{303, "linkat"},
+	{304, "symlinkat"},
+	{305, "readlinkat"},
+	{306, "fchmodat"},
+	{307, "faccessat"},
+	{308, "pselect6"},
+	{309, "ppoll"},
+	{310, "unshare"},
+	{311, "set_robust_list"},
+	{312, "get_robust_list"},
+	{313, "splice"},
+	{314, "sync_file_range"},
+	{315, "tee"},
+	{316, "vmsplice"},
+	{317, "move_pages"},
+	{318, "getcpu"},
+	{319, "epoll_pwait"},
+	{320, "utimensat"},
+	{321, "signalfd"},
+	{322, "timerfd_create"},
+	{323, "eventfd"},
+	{324, "fallocate"},
+	{325, "timerfd_settime"},
+	{326, "timerfd_gettime"},
+	{327, "signalfd4"},
+	{328, "eventfd2"},
+	{329, "epoll_create1"},
+	{330, "dup3"},
+	{331, "pipe2"},
+	{332, "inotify_init1"},
+	{333, "preadv"},
+	{334, "pwritev"},
+	{335, "rt_tgsigqueueinfo"},
+	{336, "perf_event_open"},
+	{337, "recvmmsg"},
+	{338, "fanotify_init"},
+	{339, "fanotify_mark"},
+	{340, "prlimit64"},
+	{341, "name_to_handle_at"},
+	{342, "open_by_handle_at"},
+	{343, "clock_adjtime"},
+	{344, "syncfs"},
+	{345, "sendmmsg"},
+	{346, "setns"},
+	{347, "process_vm_readv"},
+	{348, "process_vm_writev"},
+	{349, "kcmp"},
+	{350, "finit_module"},
+	{351, "sched_setattr"},
+	{352, "sched_getattr"},
+	{353, "renameat2"},
+	{354, "seccomp"},
+	{355, "getrandom"},
+	{356, "memfd_create"},
+	{357, "bpf"},
+	{358, "execveat"},
+	{359, "socket"},
+	{360, "socketpair"},
+	{361, "bind"},
+	{362, "connect"},
+	{363, "listen"},
+	{364, "accept4"},
+	{365, "getsockopt"},
+	{366, "setsockopt"},
+	{367, "getsockname"},
+	{368, "getpeername"},
+	{369, "sendto"},
+	{370, "sendmsg"},
+	{371, "recvfrom"},
+	{372, "recvmsg"},
+	{373, "shutdown"},
+	{374, "userfaultfd"},
+	{375, "membarrier"},
+	{376, "mlock2"},
+	{377, "copy_file_range"},
+	{378, "preadv2"},
+	{379, "pwritev2"},
+	{380, "pkey_mprotect"},
+	{381, "pkey_alloc"},
+	{382, "pkey_free"},
+	{383, "statx"},
+	{384, "arch_prctl"},
+	{385, "io_pgetevents"},
+	{386, "rseq"},
+	{393, "semget"},
+	{394, "semctl"},
+	{395, "shmget"},
+	{396, "shmctl"},
+	{397, "shmat"},
+	{398, "shmdt"},
+	{399, "msgget"},
+	{400, "msgsnd"},
+	{401, "msgrcv"},
+	{402, "msgctl"},
+	{403, "clock_gettime64"},
+	{404, "clock_settime64"},
+	{405, "clock_adjtime64"},
+	{406, "clock_getres_time64"},
+	{407, "clock_nanosleep_time64"},
+	{408, "timer_gettime64"},
+	{409, "timer_settime64"},
+	{410, "timerfd_gettime64"},
+	{411, "timerfd_settime64"},
+	{412, "utimensat_time64"},
+	{413, "pselect6_time64"},
+	{414, "ppoll_time64"},
+	{416, "io_pgetevents_time64"},
+	{417, "recvmmsg_time64"},
+	{418, "mq_timedsend_time64"},
+	{419, "mq_timedreceive_time64"},
+	{420, "semtimedop_time64"},
+	{421, "rt_sigtimedwait_time64"},
+	{422, "futex_time64"},
+	{423, "sched_rr_get_interval_time64"},
+	{424, "pidfd_send_signal"},
+	{425, "io_uring_setup"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{428, "open_tree"},
+	{429, "move_mount"},
+	{430, "fsopen"},
+	{431, "fsconfig"},
+	{432, "fsmount"},
+	{433, "fspick"},
+	{434, "pidfd_open"},
+	{435, "clone3"},
+	{436, "close_range"},
+	{437, "openat2"},
+	{438, "pidfd_getfd"},
+	{439, "faccessat2"},
+	{440, "process_madvise"},
+	{441, "epoll_pwait2"},
+	{442, "mount_setattr"},
+	{443, "quotactl_fd"},
+	{444, "landlock_create_ruleset"},
+	{445, "landlock_add_rule"},
+	{446, "landlock_restrict_self"},
+	{447, "memfd_secret"},
+	{448, "process_mrelease"},
+	{449, "futex_waitv"},
+	{450, "set_mempolicy_home_node"},
+	{451, "cachestat"},
+	{452, "fchmodat2"},
+	{453, "map_shadow_stack"},
+	{454, "futex_wake"},
+	{455, "futex_wait"},
+	{456, "futex_requeue"},
+	{457, "statmount"},
+	{458, "listmount"},
+	{459, "lsm_get_self_attr"},
+	{460, "lsm_set_self_attr"},
+	{461, "lsm_list_modules"},
+	{462, "mseal"},
+	{463, "setxattrat"},
+	{464, "getxattrat"},
+	{465, "listxattrat"},
+	{466, "removexattrat"},
+	{467, "open_tree_attr"},
+}
+
+var syscallsByName386 = []syscallEntry{
+	{140, "_llseek"},
+	{142, "_newselect"},
+	{149, "_sysctl"},
+	{364, "accept4"},
+	{33, "access"},
+	{51, "acct"},
+	{286, "add_key"},
+	{124, "adjtimex"},
+	{137, "afs_syscall"},
+	{27, "alarm"},
+	{384, "arch_prctl"},
+	{134, "bdflush"},
+	{361, "bind"},
+	{357, "bpf"},
+	{17, "break"},
+	{45, "brk"},
+	{451, "cachestat"},
+	{184, "capget"},
+	{185, "capset"},
+	{12, "chdir"},
+	{15, "chmod"},
+	{182, "chown"},
+	{212, "chown32"},
+	{61, "chroot"},
+	{343, "clock_adjtime"},
+	{405, "clock_adjtime64"},
+	{266, "clock_getres"},
+	{406, "clock_getres_time64"},
+	{265, "clock_gettime"},
+	{403, "clock_gettime64"},
+	{267, "clock_nanosleep"},
+	{407, "clock_nanosleep_time64"},
+	{264, "clock_settime"},
+	{404, "clock_settime64"},
+	{120, "clone"},
+	{435, "clone3"},
+	{6, "close"},
+	{436, "close_range"},
+	{362, "connect"},
+	{377, "copy_file_range"},
+	{8, "creat"},
+	{127, "create_module"},
+	{129, "delete_module"},
+	{41, "dup"},
+	{63, "dup2"},
+	{330, "dup3"},
+	{254, "epoll_create"},
+	{329, "epoll_create1"},
+	{255, "epoll_ctl"},
+	{319, "epoll_pwait"},
+	{441, "epoll_pwait2"},
+	{256, "epoll_wait"},
+	{323, "eventfd"},
+	{328, "eventfd2"},
+	{11, "execve"},
+	{358, "execveat"},
+	{1, "exit"},
+	{252, "exit_group"},
+	{307, "faccessat"},
+	{439, "faccessat2"},
+	{250, "fadvise64"},
+	{272, "fadvise64_64"},
+	{324, "fallocate"},
+	{338, "fanotify_init"},
+	{339, "fanotify_mark"},
+	{133, "fchdir"},
+	{94, "fchmod"},
+	{306, "fchmodat"},
+	{452, "fchmodat2"},
+	{95, "fchown"},
+	{207, "fchown32"},
+	{298, "fchownat"},
+	{55, "fcntl"},
+	{221, "fcntl64"},
+	{148, "fdatasync"},
+	{231, "fgetxattr"},
+	{350, "finit_module"},
+	{234, "flistxattr"},
+	{143, "flock"},
+	{2, "fork"},
+	{237, "fremovexattr"},
+	{431, "fsconfig"},
+	{228, "fsetxattr"},
+	{432, "fsmount"},
+	{430, "fsopen"},
+	{433, "fspick"},
+	{108, "fstat"},
+	{197, "fstat64"},
+	{300, "fstatat64"},
+	{100, "fstatfs"},
+	{269, "fstatfs64"},
+	{118, "fsync"},
+	{35, "ftime"},
+	{93, "ftruncate"},
+	{194, "ftruncate64"},
+	{240, "futex"},
+	{456, "futex_requeue"},
+	{422, "futex_time64"},
+	{455, "futex_wait"},
+	{449, "futex_waitv"},
+	{454, "futex_wake"},
+	{299, "futimesat"},
+	{130, "get_kernel_syms"},
+	{275, "get_mempolicy"},
+	{312, "get_robust_list"},
+	{244, "get_thread_area"},
+	{318, "getcpu"},
+	{183, "getcwd"},
+	{141, "getdents"},
+	{220, "getdents64"},
+	{50, "getegid"},
+	{202, "getegid32"},
+	{49, "geteuid"},
+	{201, "geteuid32"},
+	{47, "getgid"},
+	{200, "getgid32"},
+	{80, "getgroups"},
+	{205, "getgroups32"},
+	{105, "getitimer"},
+	{368, "getpeername"},
+	{132, "getpgid"},
+	{65, "getpgrp"},
+	{20, "getpid"},
+	{188, "getpmsg"},
+	{64, "getppid"},
+	{96, "getpriority"},
+	{355, "getrandom"},
+	{171, "getresgid"},
+	{211, "getresgid32"},
+	{165, "getresuid"},
+	{209, "getresuid32"},
+	{76, "getrlimit"},
+	{77, "getrusage"},
+	{147, "getsid"},
+	{367, "getsockname"},
+	{365, "getsockopt"},
+	{224, "gettid"},
+	{78, "gettimeofday"},
+	{24, "getuid"},
+	{199, "getuid32"},
+	{229, "getxattr"},
+	{464, "getxattrat"},
+	{32, "gtty"},
+	{112, "idle"},
+	{128, "init_module"},
+	{292, "inotify_add_watch"},
+	{291, "inotify_init"},
+	{332, "inotify_init1"},
+	{293, "inotify_rm_watch"},
+	{249, "io_cancel"},
+	{246, "io_destroy"},
+	{247, "io_getevents"},
+	{385, "io_pgetevents"},
+	{416, "io_pgetevents_time64"},
+	{245, "io_setup"},
+	{248, "io_submit"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{425, "io_uring_setup"},
+	{54, "ioctl"},
+	{101, "ioperm"},
+	{110, "iopl"},
+	{290, "ioprio_get"},
+	{289, "ioprio_set"},
+	{117, "ipc"},
+	{349, "kcmp"},
+	{283, "kexec_load"},
+	{288, "keyctl"},
+	{37, "kill"},
+	{445, "landlock_add_rule"},
+	{444, "landlock_create_ruleset"},
+	{446, "landlock_restrict_self"},
+	{16, "lchown"},
+	{198, "lchown32"},
+	{230, "lgetxattr"},
+	{9, "link"},
+	{303, "linkat"},
+	{363, "listen"},
+	{458, "listmount"},
+	{232, "listxattr"},
+	{465, "listxattrat"},
+	{233, "llistxattr"},
+	{53, "lock"},
+	{253, "lookup_dcookie"},
+	{236, "lremovexattr"},
+	{19, "lseek"},
+	{227, "lsetxattr"},
+	{459, "lsm_get_self_attr"},
+	{461, "lsm_list_modules"},
+	{460, "lsm_set_self_attr"},
+	{107, "lstat"},
+	{196, "lstat64"},
+	{219, "madvise"},
+	{453, "map_shadow_stack"},
+	{274, "mbind"},
+	{375, "membarrier"},
+	{356, "memfd_create"},
+	{447, "memfd_secret"},
+	{294, "migrate_pages"},
+	{218, "mincore"},
+	{39, "mkdir"},
+	{296, "mkdirat"},
+	{14, "mknod"},
+	{297, "mknodat"},
+	{150, "mlock"},
+	{376, "mlock2"},
+	{152, "mlockall"},
+	{90, "mmap"},
+	{192, "mmap2"},
+	{123, "modify_ldt"},
+	{21, "mount"},
+	{442, "mount_setattr"},
+	{429, "move_mount"},
+	{317, "move_pages"},
+	{125, "mprotect"},
+	{56, "mpx"},
+	{282, "mq_getsetattr"},
+	{281, "mq_notify"},
+	{277, "mq_open"},
+	{280, "mq_timedreceive"},
+	{419, "mq_timedreceive_time64"},
+	{279, "mq_timedsend"},
+	{418, "mq_timedsend_time64"},
+	{278, "mq_unlink"},
+	{163, "mremap"},
+	{462, "mseal"},
+	{402, "msgctl"},
+	{399, "msgget"},
+	{401, "msgrcv"},
+	{400, "msgsnd"},
+	{144, "msync"},
+	{151, "munlock"},
+	{153, "munlockall"},
+	{91, "munmap"},
+	{341, "name_to_handle_at"},
+	{162, "nanosleep"},
+	{169, "nfsservctl"},
+	{34, "nice"},
+	{28, "oldfstat"},
+	{84, "oldlstat"},
+	{59, "oldolduname"},
+	{18, "oldstat"},
+	{109, "olduname"},
+	{5, "open"},
+	{342, "open_by_handle_at"},
+	{428, "open_tree"},
+	{467, "open_tree_attr"},
+	{295, "openat"},
+	{437, "openat2"},
+	{29, "pause"},
+	{336, "perf_event_open"},
+	{136, "personality"},
+	{438, "pidfd_getfd"},
+	{434, "pidfd_open"},
+	{424, "pidfd_send_signal"},
+	{42, "pipe"},
+	{331, "pipe2"},
+	{217, "pivot_root"},
+	{381, "pkey_alloc"},
+	{382, "pkey_free"},
+	{380, "pkey_mprotect"},
+	{168, "poll"},
+	{309, "ppoll"},
+	{414, "ppoll_time64"},
+	{172, "prctl"},
+	{180, "pread64"},
+	{333, "preadv"},
+	{378, "preadv2"},
+	{340, "prlimit64"},
+	{440, "process_madvise"},
+	{448, "process_mrelease"},
+	{347, "process_vm_readv"},
+	{348, "process_vm_writev"},
+	{44, "prof"},
+	{98, "profil"},
+	{308, "pselect6"},
+	{413, "pselect6_time64"},
+	{26, "ptrace"},
+	{189, "putpmsg"},
+	{181, "pwrite64"},
+	{334, "pwritev"},
+	{379, "pwritev2"},
+	{167, "query_module"},
+	{131, "quotactl"},
+	{443, "quotactl_fd"},
+	{3, "read"},
+	{225, "readahead"},
+	{89, "readdir"},
+	{85, "readlink"},
+	{305, "readlinkat"},
+	{145, "readv"},
+	{88, "reboot"},
+	{371, "recvfrom"},
+	{337, "recvmmsg"},
+	{417, "recvmmsg_time64"},
+	{372, "recvmsg"},
+	{257, "remap_file_pages"},
+	{235, "removexattr"},
+	{466, "removexattrat"},
+	{38, "rename"},
+	{302, "renameat"},
+	{353, "renameat2"},
+	{287, "request_key"},
+	{0, "restart_syscall"},
+	{40, "rmdir"},
+	{386, "rseq"},
+	{174, "rt_sigaction"},
+	{176, "rt_sigpending"},
+	{175, "rt_sigprocmask"},
+	{178, "rt_sigqueueinfo"},
+	{173, "rt_sigreturn"},
+	{179, "rt_sigsuspend"},
+	{177, "rt_sigtimedwait"},
+	{421, "rt_sigtimedwait_time64"},
+	{335, "rt_tgsigqueueinfo"},
+	{159, "sched_get_priority_max"},
+	{160, "sched_get_priority_min"},
+	{242, "sched_getaffinity"},
+	{352, "sched_getattr"},
+	{155, "sched_getparam"},
+	{157, "sched_getscheduler"},
+	{161, "sched_rr_get_interval"},
+	{423, "sched_rr_get_interval_time64"},
+	{241, "sched_setaffinity"},
+	{351, "sched_setattr"},
+	{154, "sched_setparam"},
+	{156, "sched_setscheduler"},
+	{158, "sched_yield"},
+	{354, "seccomp"},
+	{82, "select"},
+	{394, "semctl"},
+	{393, "semget"},
+	{420, "semtimedop_time64"},
+	{187, "sendfile"},
+	{239, "sendfile64"},
+	{345, "sendmmsg"},
+	{370, "sendmsg"},
+	{369, "sendto"},
+	{276, "set_mempolicy"},
+	{450, "set_mempolicy_home_node"},
+	{311, "set_robust_list"},
+	{243, "set_thread_area"},
+	{258, "set_tid_address"},
+	{121, "setdomainname"},
+	{139, "setfsgid"},
+	{216, "setfsgid32"},
+	{138, "setfsuid"},
+	{215, "setfsuid32"},
+	{46, "setgid"},
+	{214, "setgid32"},
+	{81, "setgroups"},
+	{206, "setgroups32"},
+	{74, "sethostname"},
+	{104, "setitimer"},
+	{346, "setns"},
+	{57, "setpgid"},
+	{97, "setpriority"},
+	{71, "setregid"},
+	{204, "setregid32"},
+	{170, "setresgid"},
+	{210, "setresgid32"},
+	{164, "setresuid"},
+	{208, "setresuid32"},
+	{70, "setreuid"},
+	{203, "setreuid32"},
+	{75, "setrlimit"},
+	{66, "setsid"},
+	{366, "setsockopt"},
+	{79, "settimeofday"},
+	{23, "setuid"},
+	{213, "setuid32"},
+	{226, "setxattr"},
+	{463, "setxattrat"},
+	{68, "sgetmask"},
+	{397, "shmat"},
+	{396, "shmctl"},
+	{398, "shmdt"},
+	{395, "shmget"},
+	{373, "shutdown"},
+	{67, "sigaction"},
+	{186, "sigaltstack"},
+	{48, "signal"},
+	{321, "signalfd"},
+	{327, "signalfd4"},
+	{73, "sigpending"},
+	{126, "sigprocmask"},
+	{119, "sigreturn"},
+	{72, "sigsuspend"},
+	{359, "socket"},
+	{102, "socketcall"},
+	{360, "socketpair"},
+	{313, "splice"},
+	{69, "ssetmask"},
+	{106, "stat"},
+	{195, "stat64"},
+	{99, "statfs"},
+	{268, "statfs64"},
+	{457, "statmount"},
+	{383, "statx"},
+	{25, "stime"},
+	{31, "stty"},
+	{115, "swapoff"},
+	{87, "swapon"},
+	{83, "symlink"},
+	{304, "symlinkat"},
+	{36, "sync"},
+	{314, "sync_file_range"},
+	{344, "syncfs"},
+	{135, "sysfs"},
+	{116, "sysinfo"},
+	{103, "syslog"},
+	{315, "tee"},
+	{270, "tgkill"},
+	{13, "time"},
+	{259, "timer_create"},
+	{263, "timer_delete"},
+	{262, "timer_getoverrun"},
+	{261, "timer_gettime"},
+	{408, "timer_gettime64"},
+	{260, "timer_settime"},
+	{409, "timer_settime64"},
+	{322, "timerfd_create"},
+	{326, "timerfd_gettime"},
+	{410, "timerfd_gettime64"},
+	{325, "timerfd_settime"},
+	{411, "timerfd_settime64"},
+	{43, "times"},
+	{238, "tkill"},
+	{92, "truncate"},
+	{193, "truncate64"},
+	{191, "ugetrlimit"},
+	{58, "ulimit"},
+	{60, "umask"},
+	{22, "umount"},
+	{52, "umount2"},
+	{122, "uname"},
+	{10, "unlink"},
+	{301, "unlinkat"},
+	{310, "unshare"},
+	{86, "uselib"},
+	{374, "userfaultfd"},
+	{62, "ustat"},
+	{30, "utime"},
+	{320, "utimensat"},
+	{412, "utimensat_time64"},
+	{271, "utimes"},
+	{190, "vfork"},
+	{111, "vhangup"},
+	{166, "vm86"},
+	{113, "vm86old"},
+	{316, "vmsplice"},
+	{273, "vserver"},
+	{114, "wait4"},
+	{284, "waitid"},
+	{7, "waitpid"},
+	{4, "write"},
+	{146, "writev"},
+}
+
+var syscallsX32 = []syscallEntry{
+	{0, "read"},
+	{1, "write"},
+	{2, "open"},
+	{3, "close"},
+	{4, "stat"},
+	{5, "fstat"},
+	{6, "lstat"},
+	{7, "poll"},
+	{8, "lseek"},
+	{9, "mmap"},
+	{10, "mprotect"},
+	{11, "munmap"},
+	{12, "brk"},
+	{14, "rt_sigprocmask"},
+	{17, "pread64"},
+	{18, "pwrite64"},
+	{21, "access"},
+	{22, "pipe"},
+	{23, "select"},
+	{24, "sched_yield"},
+	{25, "mremap"},
+	{26, "msync"},
+	{27, "mincore"},
+	{28, "madvise"},
+	{29, "shmget"},
+	{30, "shmat"},
+	{31, "shmctl"},
+	{32, "dup"},
+	{33, "dup2"},
+	{34, "pause"},
+	{35, "nanosleep"},
+	{36, "getitimer"},
+	{37, "alarm"},
+	{38, "setitimer"},
+	{39, "getpid"},
+	{40, "sendfile"},
+	{41, "socket"},
+	{42, "connect"},
+	{43, "accept"},
+	{44, "sendto"},
+	{48, "shutdown"},
+	{49, "bind"},
+	{50, "listen"},
+	{51, "getsockname"},
+	{52, "getpeername"},
+	{53, "socketpair"},
+	{56, "clone"},
+	{57, "fork"},
+	{58, "vfork"},
+	{60, "exit"},
+	{61, "wait4"},
+	{62, "kill"},
+	{63, "uname"},
+	{64, "semget"},
+	{65, "semop"},
+	{66, "semctl"},
+	{67, "shmdt"},
+	{68, "msgget"},
+	{69, "msgsnd"},
+	{70, "msgrcv"},
+	{71, "msgctl"},
+	{72, "fcntl"},
+	{73, "flock"},
+	{74, "fsync"},
+	{75, "fdatasync"},
+	{76, "truncate"},
+	{77, "ftruncate"},
+	{78, "getdents"},
+	{79, "getcwd"},
+	{80, "chdir"},
+	{81, "fchdir"},
+	{82, "rename"},
+	{83, "mkdir"},
+	{84, "rmdir"},
+	{85, "creat"},
+	{86, "link"},
+	{87, "unlink"},
+	{88, "symlink"},
+	{89, "readlink"},
+	{90, "chmod"},
+	{91, "fchmod"},
+	{92, "chown"},
+	{93, "fchown"},
+	{94, "lchown"},
+	{95, "umask"},
+	{96, "gettimeofday"},
+	{97, "getrlimit"},
+	{98, "getrusage"},
+	{99, "sysinfo"},
+	{100, "times"},
+	{102, "getuid"},
+	{103, "syslog"},
+	{104, "getgid"},
+	{105, "setuid"},
+	{106, "setgid"},
+	{107, "geteuid"},
+	{108, "getegid"},
+	{109, "setpgid"},
+	{110, "getppid"},
+	{111, "getpgrp"},
+	{112, "setsid"},
+	{113, "setreuid"},
+	{114, "setregid"},
+	{115, "getgroups"},
+	{116, "setgroups"},
+	{117, "setresuid"},
+	{118, "getresuid"},
+	{119, "setresgid"},
+	{120, "getresgid"},
+	{121, "getpgid"},
+	{122, "setfsuid"},
+	{123, "setfsgid"},
+	{124, "getsid"},
+	{125, "capget"},
+	{126, "capset"},
+	{130, "rt_sigsuspend"},
+	{132, "utime"},
+	{133, "mknod"},
+	{134, "uselib"},
+	{135, "personality"},
+	{136, "ustat"},
+	{137, "statfs"},
+	{138, "fstatfs"},
+	{139, "sysfs"},
+	{140, "getpriority"},
+	{141, "setpriority"},
+	{142, "sched_setparam"},
+	{143, "sched_getparam"},
+	{144, "sched_setscheduler"},
+	{145, "sched_getscheduler"},
+	{146, "sched_get_priority_max"},
+	{147, "sched_get_priority_min"},
+	{148, "sched_rr_get_interval"},
+	{149, "mlock"},
+	{150, "munlock"},
+	{151, "mlockall"},
+	{152, "munlockall"},
+	{153, "vhangup"},
+	{154, "modify_ldt"},
+	{155, "pivot_root"},
+	{156, "_sysctl"},
+	{157, "prctl"},
+	{158, "arch_prctl"},
+	{159, "adjtimex"},
+	{160, "setrlimit"},
+	{161, "chroot"},
+	{162, "sync"},
+	{163, "acct"},
+	{164, "settimeofday"},
+	{165, "mount"},
+	{166, "umount2"},
+	{167, "swapon"},
+	{168, "swapoff"},
+	{169, "reboot"},
+	{170, "sethostname"},
+	{171, "setdomainname"},
+	{172, "iopl"},
+	{173, "ioperm"},
+	{174, "create_module"},
+	{175, "init_module"},
+	{176, "delete_module"},
+	{177, "get_kernel_syms"},
+	{178, "query_module"},
+	{179, "quotactl"},
+	{180, "nfsservctl"},
+	{181, "getpmsg"},
+	{182, "putpmsg"},
+	{183, "afs_syscall"},
+	{184, "tuxcall"},
+	{185, "security"},
+	{186, "gettid"},
+	{187, "readahead"},
+	{188, "setxattr"},
+	{189, "lsetxattr"},
+	{190, "fsetxattr"},
+	{191, "getxattr"},
+	{192, "lgetxattr"},
+	{193, "fgetxattr"},
+	{194, "listxattr"},
+	{195, "llistxattr"},
+	{196, "flistxattr"},
+	{197, "removexattr"},
+	{198, "lremovexattr"},
+	{199, "fremovexattr"},
+	{200, "tkill"},
+	{201, "time"},
+	{202, "futex"},
+	{203, "sched_setaffinity"},
+	{204, "sched_getaffinity"},
+	{205, "set_thread_area"},
+	{207, "io_destroy"},
+	{208, "io_getevents"},
+	{210, "io_cancel"},
+	{211, "get_thread_area"},
+	{212, "lookup_dcookie"},
+	{213, "epoll_create"},
+	{214, "epoll_ctl_old"},
+	{215, "epoll_wait_old"},
+	{216, "remap_file_pages"},
+	{217, "getdents64"},
+	{218, "set_tid_address"},
+	{219, "restart_syscall"},
+	{220, "semtimedop"},
+	{221, "fadvise64"},
+	{223, "timer_settime"},
+	{224, "timer_gettime"},
+	{225, "timer_getoverrun"},
+	{226, "timer_delete"},
+	{227, "clock_settime"},
+	{228, "clock_gettime"},
+	{229, "clock_getres"},
+	{230, "clock_nanosleep"},
+	{231, "exit_group"},
+	{232, "epoll_wait"},
+	{233, "epoll_ctl"},
+	{234, "tgkill"},
+	{235, "utimes"},
+	{236, "vserver"},
+	{237, "mbind"},
+	{238, "set_mempolicy"},
+	{239, "get_mempolicy"},
+	{240, "mq_open"},
+	{241, "mq_unlink"},
+	{242, "mq_timedsend"},
+	{243, "mq_timedreceive"},
+	{245, "mq_getsetattr"},
+	{248, "add_key"},
+	{249, "request_key"},
+	{250, "keyctl"},
+	{251, "ioprio_set"},
+	{252, "ioprio_get"},
+	{253, "inotify_init"},
+	{254, "inotify_add_watch"},
+	{255, "inotify_rm_watch"},
+	{256, "migrate_pages"},
+	{257, "openat"},
+	{258, "mkdirat"},
+	{259, "mknodat"},
+	{260, "fchownat"},
+	{261, "futimesat"},
+	{262, "newfstatat"},
+	{263, "unlinkat"},
+	{264, "renameat"},
+	{265, "linkat"},
+	{266, "symlinkat"},
+	{267, "readlinkat"},
+	{268, "fchmodat"},
+	{269, "faccessat"},
+	{270, "pselect6"},
+	{271, "ppoll"},
+	{272, "unshare"},
+	{275, "splice"},
+	{276, "tee"},
+	{277, "sync_file_range"},
+	{280, "utimensat"},
+	{281, "epoll_pwait"},
+	{282, "signalfd"},
+	{283, "timerfd_create"},
+	{284, "eventfd"},
+	{285, "fallocate"},
+	{286, "timerfd_settime"},
+	{287, "timerfd_gettime"},
+	{288, "accept4"},
+	{289, "signalfd4"},
+	{290, "eventfd2"},
+	{291, "epoll_create1"},
+	{292, "dup3"},
+	{293, "pipe2"},
+	{294, "inotify_init1"},
+	{298, "perf_event_open"},
+	{300, "fanotify_init"},
+	{301, "fanotify_mark"},
+	{302, "prlimit64"},
+	{303, "name_to_handle_at"},
+	{304, "open_by_handle_at"},
+	{305, "clock_adjtime"},
+	{306, "syncfs"},
+	{308, "setns"},
+	{309, "getcpu"},
+	{312, "kcmp"},
+	{313, "finit_module"},
+	{314, "sched_setattr"},
+	{315, "sched_getattr"},
+	{316, "renameat2"},
+	{317, "seccomp"},
+	{318, "getrandom"},
+	{319, "memfd_create"},
+	{320, "kexec_file_load"},
+	{321, "bpf"},
+	{323, "userfaultfd"},
+	{324, "membarrier"},
+	{325, "mlock2"},
+	{326, "copy_file_range"},
+	{329, "pkey_mprotect"},
+	{330, "pkey_alloc"},
+	{331, "pkey_free"},
+	{332, "statx"},
+	{333, "io_pgetevents"},
+	{334, "rseq"},
+	{335, "uretprobe"},
+	{424, "pidfd_send_signal"},
+	{425, "io_uring_setup"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{428, "open_tree"},
+	{429, "move_mount"},
+	{430, "fsopen"},
+	{431, "fsconfig"},
+	{432, "fsmount"},
+	{433, "fspick"},
+	{434, "pidfd_open"},
+	{435, "clone3"},
+	{436, "close_range"},
+	{437, "openat2"},
+	{438, "pidfd_getfd"},
+	{439, "faccessat2"},
+	{440, "process_madvise"},
+	{441, "epoll_pwait2"},
+	{442, "mount_setattr"},
+	{443, "quotactl_fd"},
+	{444, "landlock_create_ruleset"},
+	{445, "landlock_add_rule"},
+	{446, "landlock_restrict_self"},
+	{447, "memfd_secret"},
+	{448, "process_mrelease"},
+	{449, "futex_waitv"},
+	{450, "set_mempolicy_home_node"},
+	{451, "cachestat"},
+	{452, "fchmodat2"},
+	{453, "map_shadow_stack"},
+	{454, "futex_wake"},
+	{455, "futex_wait"},
+	{456, "futex_requeue"},
+	{457, "statmount"},
+	{458, "listmount"},
+	{459, "lsm_get_self_attr"},
+	{460, "lsm_set_self_attr"},
+	{461, "lsm_list_modules"},
+	{462, "mseal"},
+	{463, "setxattrat"},
+	{464, "getxattrat"},
+	{465, "listxattrat"},
+	{466, "removexattrat"},
+	{467, "open_tree_attr"},
+	{512, "rt_sigaction"},
+	{513, "rt_sigreturn"},
+	{514, "ioctl"},
+	{515, "readv"},
+	{516, "writev"},
+	{517, "recvfrom"},
+	{518, "sendmsg"},
+	{519, "recvmsg"},
+	{520, "execve"},
+	{521, "ptrace"},
+	{522, "rt_sigpending"},
+	{523, "rt_sigtimedwait"},
+	{524, "rt_sigqueueinfo"},
+	{525, "sigaltstack"},
+	{526, "timer_create"},
+	{527, "mq_notify"},
+	{528, "kexec_load"},
+	{529, "waitid"},
+	{530, "set_robust_list"},
+	{531, "get_robust_list"},
+	{532, "vmsplice"},
+	{533, "move_pages"},
+	{534, "preadv"},
+	{535, "pwritev"},
+	{536, "rt_tgsigqueueinfo"},
+	{537, "recvmmsg"},
+	{538, "sendmmsg"},
+	{539, "process_vm_readv"},
+	{540, "process_vm_writev"},
+	{541, "setsockopt"},
+	{542, "getsockopt"},
+	{543, "io_setup"},
+	{544, "io_submit"},
+	{545, "execveat"},
+	{546, "preadv2"},
+	{547, "pwritev2"},
+}
+
+var syscallsByNameX32 = []syscallEntry{
+	{156, "_sysctl"},
+	{43, "accept"},
+	{288, "accept4"},
+	{21, "access"},
+	{163, "acct"},
+	{248, "add_key"},
+	{159, "adjtimex"},
+	{183, "afs_syscall"},
+	{37, "alarm"},
+	{158, "arch_prctl"},
+	{49, "bind"},
+	{321, "bpf"},
+	{12, "brk"},
+	{451, "cachestat"},
+	{125, "capget"},
+	{126, "capset"},
+	{80, "chdir"},
+	{90, "chmod"},
+	{92, "chown"},
+	{161, "chroot"},
+	{305, "clock_adjtime"},
+	{229, "clock_getres"},
+	{228, "clock_gettime"},
+	{230, "clock_nanosleep"},
+	{227, "clock_settime"},
+	{56, "clone"},
+	{435, "clone3"},
+	{3, "close"},
+	{436, "close_range"},
+	{42, "connect"},
+	{326, "copy_file_range"},
+	{85, "creat"},
+	{174, "create_module"},
+	{176, "delete_module"},
+	{32, "dup"},
+	{33, "dup2"},
+	{292, "dup3"},
+	{213, "epoll_create"},
+	{291, "epoll_create1"},
+	{233, "epoll_ctl"},
+	{214, "epoll_ctl_old"},
+	{281, "epoll_pwait"},
+	{441, "epoll_pwait2"},
+	{232, "epoll_wait"},
+	{215, "epoll_wait_old"},
+	{284, "eventfd"},
+	{290, "eventfd2"},
+	{520, "execve"},
+	{545, "execveat"},
+	{60, "exit"},
+	{231, "exit_group"},
+	{269, "faccessat"},
+	{439, "faccessat2"},
+	{221, "fadvise64"},
+	{285, "fallocate"},
+	{300, "fanotify_init"},
+	{301, "fanotify_mark"},
+	{81, "fchdir"},
+	{91, "fchmod"},
+	{268, "fchmodat"},
+	{452, "fchmodat2"},
+	{93, "fchown"},
+	{260, "fchownat"},
+	{72, "fcntl"},
+	{75, "fdatasync"},
+	{193, "fgetxattr"},
+	{313, "finit_module"},
+	{196, "flistxattr"},
+	{73, "flock"},
+	{57, "fork"},
+	{199, "fremovexattr"},
+	{431, "fsconfig"},
+	{190, "fsetxattr"},
+	{432, "fsmount"},
+	{430, "fsopen"},
+	{433, "fspick"},
+	{5, "fstat"},
+	{138, "fstatfs"},
+	{74, "fsync"},
+	{77, "ftruncate"},
+	{202, "futex"},
+	{456, "futex_requeue"},
+	{455, "futex_wait"},
+	{449, "futex_waitv"},
+	{454, "futex_wake"},
+	{261, "futimesat"},
+	{177, "get_kernel_syms"},
+	{239, "get_mempolicy"},
+	{531, "get_robust_list"},
+	{211, "get_thread_area"},
+	{309, "getcpu"},
+	{79, "getcwd"},
+	{78, "getdents"},
+	{217, "getdents64"},
+	{108, "getegid"},
+	{107, "geteuid"},
+	{104, "getgid"},
+	{115, "getgroups"},
+	{36, "getitimer"},
+	{52, "getpeername"},
+	{121, "getpgid"},
+	{111, "getpgrp"},
+	{39, "getpid"},
+	{181, "getpmsg"},
+	{110, "getppid"},
+	{140, "getpriority"},
+	{318, "getrandom"},
+	{120, "getresgid"},
+	{118, "getresuid"},
+	{97, "getrlimit"},
+	{98, "getrusage"},
+	{124, "getsid"},
+	{51, "getsockname"},
+	{542, "getsockopt"},
+	{186, "gettid"},
+	{96, "gettimeofday"},
+	{102, "getuid"},
+	{191, "getxattr"},
+	{464, "getxattrat"},
+	{175, "init_module"},
+	{254, "inotify_add_watch"},
+	{253, "inotify_init"},
+	{294, "inotify_init1"},
+	{255, "inotify_rm_watch"},
+	{210, "io_cancel"},
+	{207, "io_destroy"},
+	{208, "io_getevents"},
+	{333, "io_pgetevents"},
+	{543, "io_setup"},
+	{544, "io_submit"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{425, "io_uring_setup"},
+	{514, "ioctl"},
+	{173, "ioperm"},
+	{172, "iopl"},
+	{252, "ioprio_get"},
+	{251, "ioprio_set"},
+	{312, "kcmp"},
+	{320, "kexec_file_load"},
+	{528, "kexec_load"},
+	{250, "keyctl"},
+	{62, "kill"},
+	{445, "landlock_add_rule"},
+	{444, "landlock_create_ruleset"},
+	{446, "landlock_restrict_self"},
+	{94, "lchown"},
+	{192, "lgetxattr"},
+	{86, "link"},
+	{265, "linkat"},
+	{50, "listen"},
+	{458, "listmount"},
+	{194, "listxattr"},
+	{465, "listxattrat"},
+	{195, "llistxattr"},
+	{212, "lookup_dcookie"},
+	{198, "lremovexattr"},
+	{8, "lseek"},
+	{189, "lsetxattr"},
+	{459, "lsm_get_self_attr"},
+	{461, "lsm_list_modules"},
+	{460, "lsm_set_self_attr"},
+	{6, "lstat"},
+	{28, "madvise"},
+	{453, "map_shadow_stack"},
+	{237, "mbind"},
+	{324, "membarrier"},
+	{319, "memfd_create"},
+	{447, "memfd_secret"},
+	{256, "migrate_pages"},
+	{27, "mincore"},
+	{83, "mkdir"},
+	{258, "mkdirat"},
+	{133, "mknod"},
+	{259, "mknodat"},
+	{149, "mlock"},
+	{325, "mlock2"},
+	{151, "mlockall"},
+	{9, "mmap"},
+	{154, "modify_ldt"},
+	{165, "mount"},
+	{442, "mount_setattr"},
+	{429, "move_mount"},
+	{533, "move_pages"},
+	{10, "mprotect"},
+	{245, "mq_getsetattr"},
+	{527, "mq_notify"},
+	{240, "mq_open"},
+	{243, "mq_timedreceive"},
+	{242, "mq_timedsend"},
+	{241, "mq_unlink"},
+	{25, "mremap"},
+	{462, "mseal"},
+	{71, "msgctl"},
+	{68, "msgget"},
+	{70, "msgrcv"},
+	{69, "msgsnd"},
+	{26, "msync"},
+	{150, "munlock"},
+	{152, "munlockall"},
+	{11, "munmap"},
+	{303, "name_to_handle_at"},
+	{35, "nanosleep"},
+	{262, "newfstatat"},
+	{180, "nfsservctl"},
+	{2, "open"},
+	{304, "open_by_handle_at"},
+	{428, "open_tree"},
+	{467, "open_tree_attr"},
+	{257, "openat"},
+	{437, "openat2"},
+	{34, "pause"},
+	{298, "perf_event_open"},
+	{135, "personality"},
+	{438, "pidfd_getfd"},
+	{434, "pidfd_open"},
+	{424, "pidfd_send_signal"},
+	{22, "pipe"},
+	{293, "pipe2"},
+	{155, "pivot_root"},
+	{330, "pkey_alloc"},
+	{331, "pkey_free"},
+	{329, "pkey_mprotect"},
+	{7, "poll"},
+	{271, "ppoll"},
+	{157, "prctl"},
+	{17, "pread64"},
+	{534, "preadv"},
+	{546, "preadv2"},
+	{302, "prlimit64"},
+	{440, "process_madvise"},
+	{448, "process_mrelease"},
+	{539, "process_vm_readv"},
+	{540, "process_vm_writev"},
+	{270, "pselect6"},
+	{521, "ptrace"},
+	{182, "putpmsg"},
+	{18, "pwrite64"},
+	{535, "pwritev"},
+	{547, "pwritev2"},
+	{178, "query_module"},
+	{179, "quotactl"},
+	{443, "quotactl_fd"},
+	{0, "read"},
+	{187, "readahead"},
+	{89, "readlink"},
+	{267, "readlinkat"},
+	{515, "readv"},
+	{169, "reboot"},
+	{517, "recvfrom"},
+	{537, "recvmmsg"},
+	{519, "recvmsg"},
+	{216, "remap_file_pages"},
+	{197, "removexattr"},
+	{466, "removexattrat"},
+	{82, "rename"},
+	{264, "renameat"},
+	{316, "renameat2"},
+	{249, "request_key"},
+	{219, "restart_syscall"},
+	{84, "rmdir"},
+	{334, "rseq"},
+	{512, "rt_sigaction"},
+	{522, "rt_sigpending"},
+	{14, "rt_sigprocmask"},
+	{524, "rt_sigqueueinfo"},
+	{513, "rt_sigreturn"},
+	{130, "rt_sigsuspend"},
+	{523, "rt_sigtimedwait"},
+	{536, "rt_tgsigqueueinfo"},
+	{146, "sched_get_priority_max"},
+	{147, "sched_get_priority_min"},
+	{204, "sched_getaffinity"},
+	{315, "sched_getattr"},
+	{143, "sched_getparam"},
+	{145, "sched_getscheduler"},
+	{148, "sched_rr_get_interval"},
+	{203, "sched_setaffinity"},
+	{314, "sched_setattr"},
+	{142, "sched_setparam"},
+	{144, "sched_setscheduler"},
+	{24, "sched_yield"},
+	{317, "seccomp"},
+	{185, "security"},
+	{23, "select"},
+	{66, "semctl"},
+	{64, "semget"},
+	{65, "semop"},
+	{220, "semtimedop"},
+	{40, "sendfile"},
+	{538, "sendmmsg"},
+	{518, "sendmsg"},
+	{44, "sendto"},
+	{238, "set_mempolicy"},
+	{450, "set_mempolicy_home_node"},
+	{530, "set_robust_list"},
+	{205, "set_thread_area"},
+	{218, "set_tid_address"},
+	{171, "setdomainname"},
+	{123, "setfsgid"},
+	{122, "setfsuid"},
+	{106, "setgid"},
+	{116, "setgroups"},
+	{170, "sethostname"},
+	{38, "setitimer"},
+	{308, "setns"},
+	{109, "setpgid"},
+	{141, "setpriority"},
+	{114, "setregid"},
+	{119, "setresgid"},
+	{117, "setresuid"},
+	{113, "setreuid"},
+	{160, "setrlimit"},
+	{112, "setsid"},
+	{541, "setsockopt"},
+	{164, "settimeofday"},
+	{105, "setuid"},
+	{188, "setxattr"},
+	{463, "setxattrat"},
+	{30, "shmat"},
+	{31, "shmctl"},
+	{67, "shmdt"},
+	{29, "shmget"},
+	{48, "shutdown"},
+	{525, "sigaltstack"},
+	{282, "signalfd"},
+	{289, "signalfd4"},
+	{41, "socket"},
+	{53, "socketpair"},
+	{275, "splice"},
+	{4, "stat"},
+	{137, "statfs"},
+	{457, "statmount"},
+	{332, "statx"},
+	{168, "swapoff"},
+	{167, "swapon"},
+	{88, "symlink"},
+	{266, "symlinkat"},
+	{162, "sync"},
+	{277, "sync_file_range"},
+	{306, "syncfs"},
+	{139, "sysfs"},
+	{99, "sysinfo"},
+	{103, "syslog"},
+	{276, "tee"},
+	{234, "tgkill"},
+	{201, "time"},
+	{526, "timer_create"},
+	{226, "timer_delete"},
+	{225, "timer_getoverrun"},
+	{224, "timer_gettime"},
+	{223, "timer_settime"},
+	{283, "timerfd_create"},
+	{287, "timerfd_gettime"},
+	{286, "timerfd_settime"},
+	{100, "times"},
+	{200, "tkill"},
+	{76, "truncate"},
+	{184, "tuxcall"},
+	{95, "umask"},
+	{166, "umount2"},
+	{63, "uname"},
+	{87, "unlink"},
+	{263, "unlinkat"},
+	{272, "unshare"},
+	{335, "uretprobe"},
+	{134, "uselib"},
+	{323, "userfaultfd"},
+	{136, "ustat"},
+	{132, "utime"},
+	{280, "utimensat"},
+	{235, "utimes"},
+	{58, "vfork"},
+	{153, "vhangup"},
+	{532, "vmsplice"},
+	{236, "vserver"},
+	{61, "wait4"},
+	{529, "waitid"},
+	{1, "write"},
+	{516, "writev"},
+}
+
+var syscallsX86_64 = []syscallEntry{
+	{0, "read"},
+	{1, "write"},
+	{2, "open"},
+	{3, "close"},
+	{4, "stat"},
+	{5, "fstat"},
+	{6, "lstat"},
+	{7, "poll"},
+	{8, "lseek"},
+	{9, "mmap"},
+	{10, "mprotect"},
+	{11, "munmap"},
+	{12, "brk"},
+	{13, "rt_sigaction"},
+	{14, "rt_sigprocmask"},
+	{15, "rt_sigreturn"},
+	{16, "ioctl"},
+	{17, "pread64"},
+	{18, "pwrite64"},
+	{19, "readv"},
+	{20, "writev"},
+	{21, "access"},
+	{22, "pipe"},
+	{23, "select"},
+	{24, "sched_yield"},
+	{25, "mremap"},
+	{26, "msync"},
+	{27, "mincore"},
+	{28, "madvise"},
+	{29, "shmget"},
+	{30, "shmat"},
+	{31, "shmctl"},
+	{32, "dup"},
+	{33, "dup2"},
+	{34, "pause"},
+	{35, "nanosleep"},
+	{36, "getitimer"},
+	{37, "alarm"},
+	{38, "setitimer"},
+	{39, "getpid"},
+	{40, "sendfile"},
+	{41, "socket"},
+	{42, "connect"},
+	{43, "accept"},
+	{44, "sendto"},
+	{45, "recvfrom"},
+	{46, "sendmsg"},
+	{47, "recvmsg"},
+	{48, "shutdown"},
+	{49, "bind"},
+	{50, "listen"},
+	{51, "getsockname"},
+	{52, "getpeername"},
+	{53, "socketpair"},
+	{54, "setsockopt"},
+	{55, "getsockopt"},
+	{56, "clone"},
+	{57, "fork"},
+	{58, "vfork"},
+	{59, "execve"},
+	{60, "exit"},
+	{61, "wait4"},
+	{62, "kill"},
+	{63, "uname"},
+	{64, "semget"},
+	{65, "semop"},
+	{66, "semctl"},
+	{67, "shmdt"},
+	{68, "msgget"},
+	{69, "msgsnd"},
+	{70, "msgrcv"},
+	{71, "msgctl"},
+	{72, "fcntl"},
+	{73, "flock"},
+	{74, "fsync"},
+	{75, "fdatasync"},
+	{76, "truncate"},
+	{77, "ftruncate"},
+	{78, "getdents"},
+	{79, "getcwd"},
+	{80, "chdir"},
+	{81, "fchdir"},
+	{82, "rename"},
+	{83, "mkdir"},
+	{84, "rmdir"},
+	{85, "creat"},
+	{86, "link"},
+	{87, "unlink"},
+	{88, "symlink"},
+	{89, "readlink"},
+	{90, "chmod"},
+	{91, "fchmod"},
+	{92, "chown"},
+	{93, "fchown"},
+	{94, "lchown"},
+	{95, "umask"},
+	{96, "gettimeofday"},
+	{97, "getrlimit"},
+	{98, "getrusage"},
+	{99, "sysinfo"},
+	{100, "times"},
+	{101, "ptrace"},
+	{102, "getuid"},
+	{103, "syslog"},
+	{104, "getgid"},
+	{105, "setuid"},
+	{106, "setgid"},
+	{107, "geteuid"},
+	{108, "getegid"},
+	{109, "setpgid"},
+	{110, "getppid"},
+	{111, "getpgrp"},
+	{112, "setsid"},
+	{113, "setreuid"},
+	{114, "setregid"},
+	{115, "getgroups"},
+	{116, "setgroups"},
+	{117, "setresuid"},
+	{118, "getresuid"},
+	{119, "setresgid"},
+	{120, "getresgid"},
+	{121, "getpgid"},
+	{122, "setfsuid"},
+	{123, "setfsgid"},
+	{124, "getsid"},
+	{125, "capget"},
+	{126, "capset"},
+	{127, "rt_sigpending"},
+	{128, "rt_sigtimedwait"},
+	{129, "rt_sigqueueinfo"},
+	{130, "rt_sigsuspend"},
+	{131, "sigaltstack"},
+	{132, "utime"},
+	{133, "mknod"},
+	{134, "uselib"},
+	{135, "personality"},
+	{136, "ustat"},
+	{137, "statfs"},
+	{138, "fstatfs"},
+	{139, "sysfs"},
+	{140, "getpriority"},
+	{141, "setpriority"},
+	{142, "sched_setparam"},
+	{143, "sched_getparam"},
+	{144, "sched_setscheduler"},
+	{145, "sched_getscheduler"},
+	{146, "sched_get_priority_max"},
+	{147, "sched_get_priority_min"},
+	{148, "sched_rr_get_interval"},
+	{149, "mlock"},
+	{150, "munlock"},
+	{151, "mlockall"},
+	{152, "munlockall"},
+	{153, "vhangup"},
+	{154, "modify_ldt"},
+	{155, "pivot_root"},
+	{156, "_sysctl"},
+	{157, "prctl"},
+	{158, "arch_prctl"},
+	{159, "adjtimex"},
+	{160, "setrlimit"},
+	{161, "chroot"},
+	{162, "sync"},
+	{163, "acct"},
+	{164, "settimeofday"},
+	{165, "mount"},
+	{166, "umount2"},
+	{167, "swapon"},
+	{168, "swapoff"},
+	{169, "reboot"},
+	{170, "sethostname"},
+	{171, "setdomainname"},
+	{172, "iopl"},
+	{173, "ioperm"},
+	{174, "create_module"},
+	{175, "init_module"},
+	{176, "delete_module"},
+	{177, "get_kernel_syms"},
+	{178, "query_module"},
+	{179, "quotactl"},
+	{180, "nfsservctl"},
+	{181, "getpmsg"},
+	{182, "putpmsg"},
+	{183, "afs_syscall"},
+	{184, "tuxcall"},
+	{185, "security"},
+	{186, "gettid"},
+	{187, "readahead"},
+	{188, "setxattr"},
+	{189, "lsetxattr"},
+	{190, "fsetxattr"},
+	{191, "getxattr"},
+	{192, "lgetxattr"},
+	{193, "fgetxattr"},
+	{194, "listxattr"},
+	{195, "llistxattr"},
+	{196, "flistxattr"},
+	{197, "removexattr"},
+	{198, "lremovexattr"},
+	{199, "fremovexattr"},
+	{200, "tkill"},
+	{201, "time"},
+	{202, "futex"},
+	{203, "sched_setaffinity"},
+	{204, "sched_getaffinity"},
+	{205, "set_thread_area"},
+	{206, "io_setup"},
+	{207, "io_destroy"},
+	{208, "io_getevents"},
+	{209, "io_submit"},
+	{210, "io_cancel"},
+	{211, "get_thread_area"},
+	{212, "lookup_dcookie"},
+	{213, "epoll_create"},
+	{214, "epoll_ctl_old"},
+	{215, "epoll_wait_old"},
+	{216, "remap_file_pages"},
+	{217, "getdents64"},
+	{218, "set_tid_address"},
+	{219, "restart_syscall"},
+	{220, "semtimedop"},
+	{221, "fadvise64"},
+	{222, "timer_create"},
+	{223, "timer_settime"},
+	{224, "timer_gettime"},
+	{225, "timer_getoverrun"},
+	{226, "timer_delete"},
+	{227, "clock_settime"},
+	{228, "clock_gettime"},
+	{229, "clock_getres"},
+	{230, "clock_nanosleep"},
+	{231, "exit_group"},
+	{232, "epoll_wait"},
+	{233, "epoll_ctl"},
+	{234, "tgkill"},
+	{235, "utimes"},
+	{236, "vserver"},
+	{237, "mbind"},
+	{238, "set_mempolicy"},
+	{239, "get_mempolicy"},
+	{240, "mq_open"},
+	{241, "mq_unlink"},
+	{242, "mq_timedsend"},
+	{243, "mq_timedreceive"},
+	{244, "mq_notify"},
+	{245, "mq_getsetattr"},
+	{246, "kexec_load"},
+	{247, "waitid"},
+	{248, "add_key"},
+	{249, "request_key"},
+	{250, "keyctl"},
+	{251, "ioprio_set"},
+	{252, "ioprio_get"},
+	{253, "inotify_init"},
+	{254, "inotify_add_watch"},
+	{255, "inotify_rm_watch"},
+	{256, "migrate_pages"},
+	{257, "openat"},
+	{258, "mkdirat"},
+	{259, "mknodat"},
+	{260, "fchownat"},
+	{261, "futimesat"},
+	{262, "newfstatat"},
+	{263, "unlinkat"},
+	{264, "renameat"},
+	{265, "linkat"},
+	{266, "symlinkat"},
+	{267, "readlinkat"},
+	{268, "fchmodat"},
+	{269, "faccessat"},
+	{270, "pselect6"},
+	{271, "ppoll"},
+	{272, "unshare"},
+	{273, "set_robust_list"},
+	{274, "get_robust_list"},
+	{275, "splice"},
+	{276, "tee"},
+	{277, "sync_file_range"},
+	{278, "vmsplice"},
+	{279, "move_pages"},
+	{280, "utimensat"},
+	{281, "epoll_pwait"},
+	{282, "signalfd"},
+	{283, "timerfd_create"},
+	{284, "eventfd"},
+	{285, "fallocate"},
+	{286, "timerfd_settime"},
+	{287, "timerfd_gettime"},
+	{288, "accept4"},
+	{289, "signalfd4"},
+	{290, "eventfd2"},
+	{291, "epoll_create1"},
+	{292, "dup3"},
+	{293, "pipe2"},
+	{294, "inotify_init1"},
+	{295, "preadv"},
+	{296, "pwritev"},
+	{297, "rt_tgsigqueueinfo"},
+	{298, "perf_event_open"},
+	{299, "recvmmsg"},
+	{300, "fanotify_init"},
+	{301, "fanotify_mark"},
+	{302, "prlimit64"},
+	{303, "name_to_handle_at"},
+	{304, "open_by_handle_at"},
+	{305, "clock_adjtime"},
+	{306, "syncfs"},
+	{307, "sendmmsg"},
+	{308, "setns"},
+	{309, "getcpu"},
+	{310, "process_vm_readv"},
+	{311, "process_vm_writev"},
+	{312, "kcmp"},
+	{313, "finit_module"},
+	{314, "sched_setattr"},
+	{315, "sched_getattr"},
+	{316, "renameat2"},
+	{317, "seccomp"},
+	{318, "getrandom"},
+	{319, "memfd_create"},
+	{320, "kexec_file_load"},
+	{321, "bpf"},
+	{322, "execveat"},
+	{323, "userfaultfd"},
+	{324, "membarrier"},
+	{325, "mlock2"},
+	{326, "copy_file_range"},
+	{327, "preadv2"},
+	{328, "pwritev2"},
+	{329, "pkey_mprotect"},
+	{330, "pkey_alloc"},
+	{331, "pkey_free"},
+	{332, "statx"},
+	{333, "io_pgetevents"},
+	{334, "rseq"},
+	{335, "uretprobe"},
+	{424, "pidfd_send_signal"},
+	{425, "io_uring_setup"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{428, "open_tree"},
+	{429, "move_mount"},
+	{430, "fsopen"},
+	{431, "fsconfig"},
+	{432, "fsmount"},
+	{433, "fspick"},
+	{434, "pidfd_open"},
+	{435, "clone3"},
+	{436, "close_range"},
+	{437, "openat2"},
+	{438, "pidfd_getfd"},
+	{439, "faccessat2"},
+	{440, "process_madvise"},
+	{441, "epoll_pwait2"},
+	{442, "mount_setattr"},
+	{443, "quotactl_fd"},
+	{444, "landlock_create_ruleset"},
+	{445, "landlock_add_rule"},
+	{446, "landlock_restrict_self"},
+	{447, "memfd_secret"},
+	{448, "process_mrelease"},
+	{449, "futex_waitv"},
+	{450, "set_mempolicy_home_node"},
+	{451, "cachestat"},
+	{452, "fchmodat2"},
+	{453, "map_shadow_stack"},
+	{454, "futex_wake"},
+	{455, "futex_wait"},
+	{456, "futex_requeue"},
+	{457, "statmount"},
+	{458, "listmount"},
+	{459, "lsm_get_self_attr"},
+	{460, "lsm_set_self_attr"},
+	{461, "lsm_list_modules"},
+	{462, "mseal"},
+	{463, "setxattrat"},
+	{464, "getxattrat"},
+	{465, "listxattrat"},
+	{466, "removexattrat"},
+	{467, "open_tree_attr"},
+}
+
+var syscallsByNameX86_64 = []syscallEntry{
+	{156, "_sysctl"},
+	{43, "accept"},
+	{288, "accept4"},
+	{21, "access"},
+	{163, "acct"},
+	{248, "add_key"},
+	{159, "adjtimex"},
+	{183, "afs_syscall"},
+	{37, "alarm"},
+	{158, "arch_prctl"},
+	{49, "bind"},
+	{321, "bpf"},
+	{12, "brk"},
+	{451, "cachestat"},
+	{125, "capget"},
+	{126, "capset"},
+	{80, "chdir"},
+	{90, "chmod"},
+	{92, "chown"},
+	{161, "chroot"},
+	{305, "clock_adjtime"},
+	{229, "clock_getres"},
+	{228, "clock_gettime"},
+	{230, "clock_nanosleep"},
+	{227, "clock_settime"},
+	{56, "clone"},
+	{435, "clone3"},
+	{3, "close"},
+	{436, "close_range"},
+	{42, "connect"},
+	{326, "copy_file_range"},
+	{85, "creat"},
+	{174, "create_module"},
+	{176, "delete_module"},
+	{32, "dup"},
+	{33, "dup2"},
+	{292, "dup3"},
+	{213, "epoll_create"},
+	{291, "epoll_create1"},
+	{233, "epoll_ctl"},
+	{214, "epoll_ctl_old"},
+	{281, "epoll_pwait"},
+	{441, "epoll_pwait2"},
+	{232, "epoll_wait"},
+	{215, "epoll_wait_old"},
+	{284, "eventfd"},
+	{290, "eventfd2"},
+	{59, "execve"},
+	{322, "execveat"},
+	{60, "exit"},
+	{231, "exit_group"},
+	{269, "faccessat"},
+	{439, "faccessat2"},
+	{221, "fadvise64"},
+	{285, "fallocate"},
+	{300, "fanotify_init"},
+	{301, "fanotify_mark"},
+	{81, "fchdir"},
+	{91, "fchmod"},
+	{268, "fchmodat"},
+	{452, "fchmodat2"},
+	{93, "fchown"},
+	{260, "fchownat"},
+	{72, "fcntl"},
+	{75, "fdatasync"},
+	{193, "fgetxattr"},
+	{313, "finit_module"},
+	{196, "flistxattr"},
+	{73, "flock"},
+	{57, "fork"},
+	{199, "fremovexattr"},
+	{431, "fsconfig"},
+	{190, "fsetxattr"},
+	{432, "fsmount"},
+	{430, "fsopen"},
+	{433, "fspick"},
+	{5, "fstat"},
+	{138, "fstatfs"},
+	{74, "fsync"},
+	{77, "ftruncate"},
+	{202, "futex"},
+	{456, "futex_requeue"},
+	{455, "futex_wait"},
+	{449, "futex_waitv"},
+	{454, "futex_wake"},
+	{261, "futimesat"},
+	{177, "get_kernel_syms"},
+	{239, "get_mempolicy"},
+	{274, "get_robust_list"},
+	{211, "get_thread_area"},
+	{309, "getcpu"},
+	{79, "getcwd"},
+	{78, "getdents"},
+	{217, "getdents64"},
+	{108, "getegid"},
+	{107, "geteuid"},
+	{104, "getgid"},
+	{115, "getgroups"},
+	{36, "getitimer"},
+	{52, "getpeername"},
+	{121, "getpgid"},
+	{111, "getpgrp"},
+	{39, "getpid"},
+	{181, "getpmsg"},
+	{110, "getppid"},
+	{140, "getpriority"},
+	{318, "getrandom"},
+	{120, "getresgid"},
+	{118, "getresuid"},
+	{97, "getrlimit"},
+	{98, "getrusage"},
+	{124, "getsid"},
+	{51, "getsockname"},
+	{55, "getsockopt"},
+	{186, "gettid"},
+	{96, "gettimeofday"},
+	{102, "getuid"},
+	{191, "getxattr"},
+	{464, "getxattrat"},
+	{175, "init_module"},
+	{254, "inotify_add_watch"},
+	{253, "inotify_init"},
+	{294, "inotify_init1"},
+	{255, "inotify_rm_watch"},
+	{210, "io_cancel"},
+	{207, "io_destroy"},
+	{208, "io_getevents"},
+	{333, "io_pgetevents"},
+	{206, "io_setup"},
+	{209, "io_submit"},
+	{426, "io_uring_enter"},
+	{427, "io_uring_register"},
+	{425, "io_uring_setup"},
+	{16, "ioctl"},
+	{173, "ioperm"},
+	{172, "iopl"},
+	{252, "ioprio_get"},
+	{251, "ioprio_set"},
+	{312, "kcmp"},
+	{320, "kexec_file_load"},
+	{246, "kexec_load"},
+	{250, "keyctl"},
+	{62, "kill"},
+	{445, "landlock_add_rule"},
+	{444, "landlock_create_ruleset"},
+	{446, "landlock_restrict_self"},
+	{94, "lchown"},
+	{192, "lgetxattr"},
+	{86, "link"},
+	{265, "linkat"},
+	{50, "listen"},
+	{458, "listmount"},
+	{194, "listxattr"},
+	{465, "listxattrat"},
+	{195, "llistxattr"},
+	{212, "lookup_dcookie"},
+	{198, "lremovexattr"},
+	{8, "lseek"},
+	{189, "lsetxattr"},
+	{459, "lsm_get_self_attr"},
+	{461, "lsm_list_modules"},
+	{460, "lsm_set_self_attr"},
+	{6, "lstat"},
+	{28, "madvise"},
+	{453, "map_shadow_stack"},
+	{237, "mbind"},
+	{324, "membarrier"},
+	{319, "memfd_create"},
+	{447, "memfd_secret"},
+	{256, "migrate_pages"},
+	{27, "mincore"},
+	{83, "mkdir"},
+	{258, "mkdirat"},
+	{133, "mknod"},
+	{259, "mknodat"},
+	{149, "mlock"},
+	{325, "mlock2"},
+	{151, "mlockall"},
+	{9, "mmap"},
+	{154, "modify_ldt"},
+	{165, "mount"},
+	{442, "mount_setattr"},
+	{429, "move_mount"},
+	{279, "move_pages"},
+	{10, "mprotect"},
+	{245, "mq_getsetattr"},
+	{244, "mq_notify"},
+	{240, "mq_open"},
+	{243, "mq_timedreceive"},
+	{242, "mq_timedsend"},
+	{241, "mq_unlink"},
+	{25, "mremap"},
+	{462, "mseal"},
+	{71, "msgctl"},
+	{68, "msgget"},
+	{70, "msgrcv"},
+	{69, "msgsnd"},
+	{26, "msync"},
+	{150, "munlock"},
+	{152, "munlockall"},
+	{11, "munmap"},
+	{303, "name_to_handle_at"},
+	{35, "nanosleep"},
+	{262, "newfstatat"},
+	{180, "nfsservctl"},
+	{2, "open"},
+	{304, "open_by_handle_at"},
+	{428, "open_tree"},
+	{467, "open_tree_attr"},
+	{257, "openat"},
+	{437, "openat2"},
+	{34, "pause"},
+	{298, "perf_event_open"},
+	{135, "personality"},
+	{438, "pidfd_getfd"},
+	{434, "pidfd_open"},
+	{424, "pidfd_send_signal"},
+	{22, "pipe"},
+	{293, "pipe2"},
+	{155, "pivot_root"},
+	{330, "pkey_alloc"},
+	{331, "pkey_free"},
+	{329, "pkey_mprotect"},
+	{7, "poll"},
+	{271, "ppoll"},
+	{157, "prctl"},
+	{17, "pread64"},
+	{295, "preadv"},
+	{327, "preadv2"},
+	{302, "prlimit64"},
+	{440, "process_madvise"},
+	{448, "process_mrelease"},
+	{310, "process_vm_readv"},
+	{311, "process_vm_writev"},
+	{270, "pselect6"},
+	{101, "ptrace"},
+	{182, "putpmsg"},
+	{18, "pwrite64"},
+	{296, "pwritev"},
+	{328, "pwritev2"},
+	{178, "query_module"},
+	{179, "quotactl"},
+	{443, "quotactl_fd"},
+	{0, "read"},
+	{187, "readahead"},
+	{89, "readlink"},
+	{267, "readlinkat"},
+	{19, "readv"},
+	{169, "reboot"},
+	{45, "recvfrom"},
+	{299, "recvmmsg"},
+	{47, "recvmsg"},
+	{216, "remap_file_pages"},
+	{197, "removexattr"},
+	{466, "removexattrat"},
+	{82, "rename"},
+	{264, "renameat"},
+	{316, "renameat2"},
+	{249, "request_key"},
+	{219, "restart_syscall"},
+	{84, "rmdir"},
+	{334, "rseq"},
+	{13, "rt_sigaction"},
+	{127, "rt_sigpending"},
+	{14, "rt_sigprocmask"},
+	{129, "rt_sigqueueinfo"},
+	{15, "rt_sigreturn"},
+	{130, "rt_sigsuspend"},
+	{128, "rt_sigtimedwait"},
+	{297, "rt_tgsigqueueinfo"},
+	{146, "sched_get_priority_max"},
+	{147, "sched_get_priority_min"},
+	{204, "sched_getaffinity"},
+	{315, "sched_getattr"},
+	{143, "sched_getparam"},
+	{145, "sched_getscheduler"},
+	{148, "sched_rr_get_interval"},
+	{203, "sched_setaffinity"},
+	{314, "sched_setattr"},
+	{142, "sched_setparam"},
+	{144, "sched_setscheduler"},
+	{24, "sched_yield"},
+	{317, "seccomp"},
+	{185, "security"},
+	{23, "select"},
+	{66, "semctl"},
+	{64, "semget"},
+	{65, "semop"},
+	{220, "semtimedop"},
+	{40, "sendfile"},
+	{307, "sendmmsg"},
+	{46, "sendmsg"},
+	{44, "sendto"},
+	{238, "set_mempolicy"},
+	{450, "set_mempolicy_home_node"},
+	{273, "set_robust_list"},
+	{205, "set_thread_area"},
+	{218, "set_tid_address"},
+	{171, "setdomainname"},
+	{123, "setfsgid"},
+	{122, "setfsuid"},
+	{106, "setgid"},
+	{116, "setgroups"},
+	{170, "sethostname"},
+	{38, "setitimer"},
+	{308, "setns"},
+	{109, "setpgid"},
+	{141, "setpriority"},
+	{114, "setregid"},
+	{119, "setresgid"},
+	{117, "setresuid"},
+	{113, "setreuid"},
+	{160, "setrlimit"},
+	{112, "setsid"},
+	{54, "setsockopt"},
+	{164, "settimeofday"},
+	{105, "setuid"},
+	{188, "setxattr"},
+	{463, "setxattrat"},
+	{30, "shmat"},
+	{31, "shmctl"},
+	{67, "shmdt"},
+	{29, "shmget"},
+	{48, "shutdown"},
+	{131, "sigaltstack"},
+	{282, "signalfd"},
+	{289, "signalfd4"},
+	{41, "socket"},
+	{53, "socketpair"},
+	{275, "splice"},
+	{4, "stat"},
+	{137, "statfs"},
+	{457, "statmount"},
+	{332, "statx"},
+	{168, "swapoff"},
+	{167, "swapon"},
+	{88, "symlink"},
+	{266, "symlinkat"},
+	{162, "sync"},
+	{277, "sync_file_range"},
+	{306, "syncfs"},
+	{139, "sysfs"},
+	{99, "sysinfo"},
+	{103, "syslog"},
+	{276, "tee"},
+	{234, "tgkill"},
+	{201, "time"},
+	{222, "timer_create"},
+	{226, "timer_delete"},
+	{225, "timer_getoverrun"},
+	{224, "timer_gettime"},
+	{223, "timer_settime"},
+	{283, "timerfd_create"},
+	{287, "timerfd_gettime"},
+	{286, "timerfd_settime"},
+	{100, "times"},
+	{200, "tkill"},
+	{76, "truncate"},
+	{184, "tuxcall"},
+	{95, "umask"},
+	{166, "umount2"},
+	{63, "uname"},
+	{87, "unlink"},
+	{263, "unlinkat"},
+	{272, "unshare"},
+	{335, "uretprobe"},
+	{134, "uselib"},
+	{323, "userfaultfd"},
+	{136, "ustat"},
+	{132, "utime"},
+	{280, "utimensat"},
+	{235, "utimes"},
+	{58, "vfork"},
+	{153, "vhangup"},
+	{278, "vmsplice"},
+	{236, "vserver"},
+	{61, "wait4"},
+	{247, "waitid"},
+	{1, "write"},
+	{20, "writev"},
 }
@@ -62,6 +62,12 @@ type JumpIf struct {
 	falseLabel Label
 }
 
+// Jump jumps unconditionally to the label.
+type Jump struct {
+	index Index
+	label Label
+}
+
 // The Program consists of a list of bpf.Instructions.
 // Conditional jumps can point to different labels in the program and must be resolved by calling ResolveJumps.
 //
@@ -72,6 +78,7 @@ type JumpIf struct {
 type Program struct {
 	instructions []bpf.Instruction
 	jumps        []JumpIf
+	gotos        []Jump
 	labels       map[Label][]Index
 	nextLabel    Label
 }
@@ -103,6 +110,12 @@ func (p *Program) JmpIf(cond bpf.JumpTest, val uint32, trueLabel Label, falseLab
 	p.instructions = append(p.instructions, inst)
 }
 
+// Jmp inserts an unconditional jump to the given label.
+func (p *Program) Jmp(label Label) {
+	p.gotos = append(p.gotos, Jump{index: p.currentIndex(), label: label})
+	p.instructions = append(p.instructions, bpf.Jump{})
+}
+
 // SetLabel sets the label to the latest instruction.
 func (p *Program) SetLabel(label Label) {
 	index := p.currentIndex()
@@ -111,10 +124,7 @@ func (p *Program) SetLabel(label Label) {
 
 // Ret inserts a return instruction.
 func (p *Program) Ret(action Action) {
-	if action == ActionErrno {
-		action |= Action(errnoEPERM)
-	}
-	p.instructions = append(p.instructions, bpf.RetConstant{Val: uint32(action)})
+	p.instructions = append(p.instructions, bpf.RetConstant{Val: uint32(action.returnValue())})
 }
 
 // LdHi inserts an instruction to load the most significant 32-bit of the 64-bit argument.
@@ -135,6 +145,11 @@ func (p *Program) LdLo(arg uint32) {
 	p.instructions = append(p.instructions, bpf.LoadAbsolute{Off: offset, Size: sizeOfUint32})
 }
 
+// LdNr inserts an instruction to load the syscall number.
+func (p *Program) LdNr() {
+	p.instructions = append(p.instructions, bpf.LoadAbsolute{Off: syscallNumOffset, Size: sizeOfUint32})
+}
+
 // NewLabel creates a new label. It must be used with SetLabel.
 func (p *Program) NewLabel() Label {
 	p.nextLabel++
@@ -167,6 +182,16 @@ func (p *Program) Assemble() ([]bpf.Instruction, error) {
 		p.instructions[jump.index] = jumpInst
 	}
 
+	// Unconditional jumps are resolved last because resolving the
+	// conditional jumps may insert instructions. They cannot be too long.
+	for _, jump := range p.gotos {
+		skip, err := p.resolveJump(jump)
+		if err != nil {
+			return nil, err
+		}
+		p.instructions[jump.index] = bpf.Jump{Skip: skip}
+	}
+
 	return p.instructions, nil
 }
 
@@ -202,6 +227,16 @@ func (p *Program) resolveLabel(jump JumpIf, label Label) (uint8, error) {
 	return uint8(skipN), nil
 }
 
+// resolveJump resolves the label of an unconditional jump.
+func (p *Program) resolveJump(jump Jump) (uint32, error) {
+	for _, dest := range p.labels[jump.label] {
+		if dest > jump.index {
+			return uint32(dest-jump.index) - 1, nil
+		}
+	}
+	return 0, fmt.Errorf("backward jumps are not supported")
+}
+
 // Inserts the instruction after the instruction indicated by index, which must come from p.jumps.
 func (p *Program) insertAfter(index Index, inst bpf.Instruction) Index {
 	// This is safe since we are only accessing instructions that were inserted as bpf.JumpIf.
@@ -224,6 +259,12 @@ func (p *Program) updateIndices(after Index) {
 		}
 	}
 
+	for i := range p.gotos {
+		if p.gotos[i].index >= after {
+			p.gotos[i].index++
+		}
+	}
+
 	for _, v := range p.labels {
 		for i := range v {
 			if v[i] >= after {
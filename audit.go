@@ -0,0 +1,243 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// auditArches are the architectures that audit records are resolved for.
+// x32 shares the audit arch of x86_64 and must be checked first.
+var auditArches = []*arch.Info{arch.X32, arch.X86_64, arch.I386, arch.ARM, arch.AARCH64}
+
+// AuditRecord is a SECCOMP record of the Linux audit log. The kernel emits
+// one for syscalls matching ActionLog, for other actions except
+// ActionAllow if the filter uses FilterFlagLog, and for kills.
+type AuditRecord struct {
+	Time    time.Time // Time of the record, zero if it could not be parsed.
+	PID     int
+	Comm    string     // Command name of the process.
+	Exe     string     // Path of the executable.
+	Arch    *arch.Info // Architecture of the syscall, nil if unsupported.
+	Syscall string     // Name of the syscall, empty if unknown.
+	Code    Action     // Action taken by the filter.
+}
+
+// ParseAuditRecord parses a SECCOMP record, in raw or interpreted (ausearch
+// -i) form or as the kernel logs it to dmesg (type=1326) when auditd is not
+// running. It returns false if the line is not a SECCOMP record.
+func ParseAuditRecord(line string) (*AuditRecord, bool, error) {
+	if !strings.Contains(line, "type=SECCOMP ") && !strings.Contains(line, "type=1326 ") {
+		return nil, false, nil
+	}
+	// Drop the enriched fields that follow a group separator.
+	line, _, _ = strings.Cut(line, "\x1d")
+
+	fields := auditFields(line)
+	record := &AuditRecord{
+		Time: auditTime(line),
+		Comm: auditString(fields["comm"]),
+		Exe:  auditString(fields["exe"]),
+	}
+	record.PID, _ = strconv.Atoi(fields["pid"])
+	if code, err := strconv.ParseUint(fields["code"], 0, 32); err == nil {
+		record.Code = Action(code)
+	}
+
+	if id, err := strconv.ParseUint(fields["arch"], 16, 32); err == nil {
+		nr, _ := strconv.Atoi(fields["syscall"])
+		for _, info := range auditArches {
+			if uint64(info.ID) == id && (info.SeccompMask == 0 || nr&info.SeccompMask != 0) {
+				record.Arch = info
+				break
+			}
+		}
+	} else if info, err := arch.GetInfo(fields["arch"]); err == nil {
+		record.Arch = info
+	}
+
+	syscall := fields["syscall"]
+	if syscall == "" {
+		return nil, true, fmt.Errorf("SECCOMP record without syscall: %q", line)
+	}
+	if nr, err := strconv.Atoi(syscall); err != nil {
+		record.Syscall = syscall
+	} else if record.Arch != nil {
+		record.Syscall = record.Arch.SyscallName(nr &^ record.Arch.SeccompMask)
+	}
+	return record, true, nil
+}
+
+// auditFields splits a record into its key=value fields.
+func auditFields(line string) map[string]string {
+	fields := map[string]string{}
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		key, rest, found := strings.Cut(line, "=")
+		if !found {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			value, line = rest[:end+2], rest[min(end+2, len(rest)):]
+		} else {
+			value, line, _ = strings.Cut(rest, " ")
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// auditTime parses the timestamp of msg=audit(1700000000.123:456), or of
+// msg=audit(10/16/2026 10:00:00.123:456) in interpreted records, which use
+// local time.
+func auditTime(line string) time.Time {
+	_, rest, found := strings.Cut(line, "msg=audit(")
+	if !found {
+		return time.Time{}
+	}
+	stamp, _, _ := strings.Cut(rest, ")")
+	if i := strings.LastIndexByte(stamp, ':'); i >= 0 {
+		stamp = stamp[:i]
+	}
+
+	if sec, frac, found := strings.Cut(stamp, "."); found && !strings.Contains(sec, "/") {
+		s, err1 := strconv.ParseInt(sec, 10, 64)
+		ms, err2 := strconv.ParseInt(frac, 10, 64)
+		if err1 != nil || err2 != nil {
+			return time.Time{}
+		}
+		return time.Unix(s, ms*int64(time.Millisecond))
+	}
+	t, err := time.ParseInLocation("01/02/2006 15:04:05.000", stamp, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// auditString decodes a quoted or hex encoded value.
+func auditString(v string) string {
+	if unquoted, found := strings.CutPrefix(v, `"`); found {
+		return strings.TrimSuffix(unquoted, `"`)
+	}
+	if decoded, err := hex.DecodeString(v); err == nil {
+		return string(decoded)
+	}
+	return v
+}
+
+// AuditOptions select the records that ReadAuditLog uses.
+type AuditOptions struct {
+	// Arch is the architecture of the records. It defaults to the native
+	// architecture.
+	Arch string
+
+	// Exe only selects the records of this executable if it is set.
+	Exe string
+}
+
+// ReadAuditLog reads the SECCOMP records from an audit log (e.g.
+// /var/log/audit/audit.log or the output of ausearch -m SECCOMP) into a
+// trace of the syscalls that were not allowed by a filter. Combined with
+// Policy.MergeTrace it supports tightening a policy iteratively: deploy it
+// with ActionLog as the default action, collect the records, and allow the
+// syscalls that were logged.
+func ReadAuditLog(r io.Reader, opts AuditOptions) (*SyscallTrace, error) {
+	info, err := arch.GetInfo(opts.Arch)
+	if err != nil {
+		return nil, err
+	}
+	trace := NewSyscallTrace()
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		record, ok, err := ParseAuditRecord(s.Text())
+		if err != nil {
+			return nil, err
+		}
+		if !ok || record.Arch != info || record.Syscall == "" || record.Code == ActionAllow {
+			continue
+		}
+		if opts.Exe != "" && record.Exe != opts.Exe {
+			continue
+		}
+		trace.AddCount(record.Syscall, 1)
+	}
+	return trace, s.Err()
+}
+
+// MergeTrace allows the traced syscalls that the policy does not name and
+// returns their sorted names. They are added to the first group that allows
+// syscalls without conditions, or to a new group at the end. Syscalls that
+// the policy names, with any action or conditions, are left alone because
+// their rules are deliberate. Syscalls that do not exist on the policy's
+// arch are ignored.
+func (p *Policy) MergeTrace(trace *SyscallTrace) []string {
+	if p.arch == nil {
+		info, err := arch.GetInfo("")
+		if err != nil {
+			return nil
+		}
+		p.arch = info
+	}
+
+	named := map[string]bool{}
+	allow := -1
+	for i, group := range p.Syscalls {
+		for _, name := range group.Names {
+			named[name] = true
+		}
+		for _, s := range group.NamesWithCondtions {
+			named[s.Name] = true
+		}
+		if allow < 0 && group.Action == ActionAllow && len(group.NamesWithCondtions) == 0 {
+			allow = i
+		}
+	}
+
+	var added []string
+	for _, name := range trace.Names() {
+		if _, found := p.arch.SyscallNumber(name); found && !named[name] {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	if allow < 0 {
+		p.Syscalls = append(p.Syscalls, SyscallGroup{Action: ActionAllow})
+		allow = len(p.Syscalls) - 1
+	}
+	p.Syscalls[allow].Names = append(p.Syscalls[allow].Names, added...)
+	return added
+}
@@ -0,0 +1,118 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+const testAuditLog = `type=SYSCALL msg=audit(1700000000.100:10): arch=c000003e syscall=59 success=yes exit=0
+type=SECCOMP msg=audit(1700000000.123:456): auid=1000 uid=1000 gid=1000 ses=2 pid=1234 comm="cat" exe="/usr/bin/cat" sig=0 arch=c000003e syscall=2 compat=0 ip=0x7f1234 code=0x7ffc0000
+type=SECCOMP msg=audit(1700000000.124:457): auid=1000 uid=1000 gid=1000 ses=2 pid=1234 comm="cat" exe="/usr/bin/cat" sig=0 arch=c000003e syscall=2 compat=0 ip=0x7f1234 code=0x7ffc0000
+type=SECCOMP msg=audit(1700000000.125:458): auid=1000 uid=1000 gid=1000 ses=2 pid=1234 comm=6D7920617070 exe="/opt/my app" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f1234 code=0x50001` + "\x1dARCH=x86_64 SYSCALL=mount" + `
+type=SECCOMP msg=audit(1700000000.126:459): auid=1000 uid=1000 gid=1000 ses=2 pid=1234 comm="cat" exe="/usr/bin/cat" sig=0 arch=40000003 syscall=5 compat=1 ip=0x7f1234 code=0x7ffc0000
+type=SECCOMP msg=audit(10/16/2026 10:00:00.127:460) : auid=user uid=user gid=user ses=2 pid=1234 comm=cat exe=/usr/bin/cat sig=0 arch=x86_64 syscall=socket compat=0 ip=0x7f1234 code=log
+type=SECCOMP msg=audit(1700000000.128:461): pid=1234 comm="cat" exe="/usr/bin/cat" sig=0 arch=c000003e syscall=0 compat=0 ip=0x7f1234 code=0x7fff0000
+type=SECCOMP msg=audit(1700000000.129:462): pid=1234 comm="x32" exe="/usr/bin/x32" sig=0 arch=c000003e syscall=1073741825 compat=0 ip=0x7f1234 code=0x7ffc0000
+`
+
+func TestParseAuditRecord(t *testing.T) {
+	lines := strings.Split(testAuditLog, "\n")
+
+	if _, ok, err := ParseAuditRecord(lines[0]); ok || err != nil {
+		t.Errorf("expected SYSCALL record to be skipped, got %v, %v", ok, err)
+	}
+
+	record, ok, err := ParseAuditRecord(lines[3])
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	want := &AuditRecord{Time: time.Unix(1700000000, 125000000), PID: 1234, Comm: "my app", Exe: "/opt/my app", Arch: arch.X86_64, Syscall: "mount", Code: ActionErrno | 1}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("expected %+v, got %+v", want, record)
+	}
+
+	if record, _, _ = ParseAuditRecord(lines[4]); record.Arch != arch.I386 || record.Syscall != "open" {
+		t.Errorf("unexpected i386 record %+v", record)
+	}
+	if record, _, _ = ParseAuditRecord(lines[5]); record.Arch != arch.X86_64 || record.Syscall != "socket" || record.Exe != "/usr/bin/cat" {
+		t.Errorf("unexpected interpreted record %+v", record)
+	}
+	if want := time.Date(2026, 10, 16, 10, 0, 0, 127000000, time.Local); !record.Time.Equal(want) {
+		t.Errorf("expected time %v, got %v", want, record.Time)
+	}
+	if record, _, _ = ParseAuditRecord(lines[7]); record.Arch != arch.X32 || record.Syscall != "write" {
+		t.Errorf("unexpected x32 record %+v", record)
+	}
+
+	dmesg := `[ 4242.123456] audit: type=1326 audit(1700000000.130:90): auid=1000 uid=1000 gid=1000 ses=3 subj=unconfined pid=99 comm="curl" exe="/usr/bin/curl" sig=0 arch=c000003e syscall=41 compat=0 ip=0x7f0000001000 code=0x50001`
+	if record, ok, err = ParseAuditRecord(dmesg); !ok || err != nil || record.Syscall != "socket" || record.Code != ActionErrno|1 {
+		t.Errorf("unexpected dmesg record %+v (%v, %v)", record, ok, err)
+	}
+}
+
+func TestReadAuditLog(t *testing.T) {
+	trace, err := ReadAuditLog(strings.NewReader(testAuditLog), AuditOptions{Arch: "x86_64", Exe: "/usr/bin/cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SyscallProfile{"open": 2, "socket": 1}); !reflect.DeepEqual(trace.Profile, want) {
+		t.Errorf("expected %v, got %v", want, trace.Profile)
+	}
+
+	if trace, err = ReadAuditLog(strings.NewReader(testAuditLog), AuditOptions{Arch: "x86_64"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (SyscallProfile{"open": 2, "socket": 1, "mount": 1}); !reflect.DeepEqual(trace.Profile, want) {
+		t.Errorf("expected %v, got %v", want, trace.Profile)
+	}
+}
+
+func TestPolicyMergeTrace(t *testing.T) {
+	trace := NewSyscallTrace()
+	for _, name := range []string{"open", "mount", "read", "no_such_syscall"} {
+		trace.AddCount(name, 1)
+	}
+
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionLog,
+		Syscalls: []SyscallGroup{
+			{Action: ActionErrno, Names: []string{"mount"}},
+			{Action: ActionAllow, Names: []string{"read"}},
+		},
+	}
+	added := policy.MergeTrace(trace)
+	if want := []string{"open"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("expected %v, got %v", want, added)
+	}
+	if want := []string{"read", "open"}; !reflect.DeepEqual(policy.Syscalls[1].Names, want) {
+		t.Errorf("expected %v, got %v", want, policy.Syscalls[1].Names)
+	}
+
+	policy = &Policy{arch: arch.X86_64, DefaultAction: ActionLog}
+	policy.MergeTrace(trace)
+	if len(policy.Syscalls) != 1 || policy.Syscalls[0].Action != ActionAllow || len(policy.Syscalls[0].Names) != 3 {
+		t.Errorf("unexpected groups %+v", policy.Syscalls)
+	}
+}
@@ -0,0 +1,120 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultAuditLog is the audit log that auditd writes by default.
+const DefaultAuditLog = "/var/log/audit/audit.log"
+
+// AuditHandler is called with every SECCOMP record that a watcher receives.
+// It is called from a single goroutine.
+type AuditHandler func(*AuditRecord)
+
+// auditPollInterval is how often FollowAuditLog checks the log for new
+// records, truncation, and rotation.
+var auditPollInterval = 250 * time.Millisecond
+
+// FollowAuditLog follows the audit log at path, like tail -F, and calls h
+// with every SECCOMP record appended to it until ctx is cancelled. Records
+// that were written before the call are skipped. The log is reopened when
+// logrotate replaces it and read from the start when it is truncated.
+// Records that cannot be parsed are skipped.
+func FollowAuditLog(ctx context.Context, path string, h AuditHandler) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { f.Close() }()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek audit log: %w", err)
+	}
+
+	ticker := time.NewTicker(auditPollInterval)
+	defer ticker.Stop()
+	r := bufio.NewReader(f)
+	var partial string
+	for {
+		line, err := r.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			handleAuditLine(partial+line, h)
+			partial = ""
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+		// Keep the incomplete line until the rest is written.
+		partial += line
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat audit log: %w", err)
+		}
+		if latest, err := os.Stat(path); err == nil && !os.SameFile(current, latest) {
+			// Rotated: read what was appended to the old log since the last
+			// read, then continue with the new one from its start.
+			rotated, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					break
+				}
+				handleAuditLine(partial+line, h)
+				partial = ""
+			}
+			f.Close()
+			f, offset, partial = rotated, 0, ""
+			r.Reset(f)
+		} else if current.Size() < offset {
+			if offset, err = f.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to seek audit log: %w", err)
+			}
+			partial = ""
+			r.Reset(f)
+		}
+	}
+}
+
+// handleAuditLine calls h if the line is a valid SECCOMP record.
+func handleAuditLine(line string, h AuditHandler) {
+	record, found, err := ParseAuditRecord(strings.TrimSuffix(line, "\n"))
+	if found && err == nil {
+		h(record)
+	}
+}
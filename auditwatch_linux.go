@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// auditNetlinkGroupReadLog is AUDIT_NLGRP_READLOG, the multicast group
+	// of the audit netlink socket that receives every audit record.
+	auditNetlinkGroupReadLog = 1
+	// auditTypeSeccomp is the netlink message type of SECCOMP records.
+	auditTypeSeccomp = 1326
+)
+
+// WatchAudit receives the records of the kernel audit subsystem from its
+// netlink multicast group and calls h with every SECCOMP record until ctx is
+// cancelled. Unlike FollowAuditLog it does not depend on auditd writing a
+// log and does not interfere with it, but it requires CAP_AUDIT_READ and
+// Linux 3.16. Records that cannot be parsed are skipped.
+func WatchAudit(ctx context.Context, h AuditHandler) error {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, unix.NETLINK_AUDIT)
+	if err != nil {
+		return fmt.Errorf("failed to open audit netlink socket: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "audit-netlink")
+	defer f.Close()
+
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1 << (auditNetlinkGroupReadLog - 1)}
+	if err = unix.Bind(fd, addr); err != nil {
+		return fmt.Errorf("failed to join the audit multicast group (requires CAP_AUDIT_READ): %w", err)
+	}
+
+	// Interrupt the blocked read when ctx is cancelled.
+	stop := context.AfterFunc(ctx, func() { f.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	buf := make([]byte, os.Getpagesize()*16)
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to receive audit records: %w", err)
+		}
+		for _, record := range parseAuditNetlink(buf[:n]) {
+			h(record)
+		}
+	}
+}
+
+// parseAuditNetlink returns the SECCOMP records of the netlink messages. The
+// payload of a message is a record without the type field of the log.
+func parseAuditNetlink(b []byte) []*AuditRecord {
+	msgs, err := syscall.ParseNetlinkMessage(b)
+	if err != nil {
+		return nil
+	}
+	var records []*AuditRecord
+	for _, msg := range msgs {
+		if msg.Header.Type != auditTypeSeccomp {
+			continue
+		}
+		line := "type=SECCOMP msg=" + strings.TrimRight(string(msg.Data), "\x00\n")
+		if record, found, err := ParseAuditRecord(line); found && err == nil {
+			records = append(records, record)
+		}
+	}
+	return records
+}
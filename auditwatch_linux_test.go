@@ -0,0 +1,52 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestParseAuditNetlink(t *testing.T) {
+	message := func(typ uint16, payload string) []byte {
+		size := 16 + len(payload)
+		b := make([]byte, (size+3)&^3)
+		binary.NativeEndian.PutUint32(b[0:], uint32(size))
+		binary.NativeEndian.PutUint16(b[4:], typ)
+		copy(b[16:], payload)
+		return b
+	}
+
+	var b []byte
+	b = append(b, message(1300, "audit(1700000000.100:10): arch=c000003e syscall=59 success=yes exit=0")...)
+	b = append(b, message(auditTypeSeccomp, "audit(1700000000.123:456): auid=1000 uid=1000 gid=1000 ses=2 pid=1234 "+
+		`comm="cat" exe="/usr/bin/cat" sig=0 arch=c000003e syscall=2 compat=0 ip=0x7f1234 code=0x7ffc0000`)...)
+	b = append(b, message(auditTypeSeccomp, "audit(1700000000.124:457): pid=1234 code=0x7ffc0000\x00")...)
+
+	records := parseAuditNetlink(b)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+	if r.Syscall != "open" || r.PID != 1234 || r.Comm != "cat" || r.Code != ActionLog || r.Time.UnixMilli() != 1700000000123 {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,111 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFollowAuditLog(t *testing.T) {
+	defer func(d time.Duration) { auditPollInterval = d }(auditPollInterval)
+	auditPollInterval = 5 * time.Millisecond
+
+	lines := strings.Split(testAuditLog, "\n")
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte(lines[1]+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	appendLog := func(data string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err = f.WriteString(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records := make(chan *AuditRecord, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- FollowAuditLog(ctx, path, func(r *AuditRecord) { records <- r }) }()
+	next := func() *AuditRecord {
+		t.Helper()
+		select {
+		case r := <-records:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("no record")
+			return nil
+		}
+	}
+
+	// Wait until the log is open so that the existing record is skipped.
+	time.Sleep(50 * time.Millisecond)
+
+	// Other records are skipped and partial lines are completed.
+	appendLog(lines[0] + "\n" + lines[3][:40])
+	time.Sleep(20 * time.Millisecond)
+	appendLog(lines[3][40:] + "\n")
+	if r := next(); r.Syscall != "mount" || r.PID != 1234 {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// Rotation.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendLog(lines[4] + "\n")
+	if r := next(); r.Syscall != "open" || r.Arch == nil || r.Arch.Name != "i386" {
+		t.Errorf("unexpected record after rotation %+v", r)
+	}
+
+	// Truncation.
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	appendLog(lines[6] + "\n")
+	if r := next(); r.Syscall != "read" {
+		t.Errorf("unexpected record after truncation %+v", r)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-records:
+		t.Errorf("unexpected record %+v", r)
+	default:
+	}
+}
+
+func TestFollowAuditLogMissing(t *testing.T) {
+	err := FollowAuditLog(context.Background(), filepath.Join(t.TempDir(), "audit.log"), func(*AuditRecord) {})
+	if err == nil {
+		t.Fatal("expected an error for a missing log")
+	}
+}
@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package autoload installs a seccomp filter when it is initialized, before
+// main runs, so that an existing Go program can be confined by adding a
+// blank import:
+//
+//	import _ "github.com/elastic/go-seccomp-bpf/autoload"
+//
+// The filter is read from the file named by the SECCOMP_POLICY_FILE
+// environment variable. Nothing is installed if it is unset. The file
+// contains a seccomp.Filter in YAML or JSON, for example:
+//
+//	no_new_privs: true
+//	flag: tsync
+//	policy:
+//	  default_action: errno
+//	  syscalls:
+//	    - action: allow
+//	      names: [read, write, ...]
+//
+// no_new_privs and flag default to true and tsync, which confines all threads
+// of the process. The format is derived from the file extension and can be
+// set with SECCOMP_POLICY_FORMAT ("yaml" or "json").
+//
+// If the filter cannot be installed the process exits with status 1, unless
+// SECCOMP_POLICY_OPTIONAL is set to true, in which case the error is written
+// to stderr and the program runs unconfined. SECCOMP_DRY_RUN is honored.
+package autoload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/json"
+	"github.com/elastic/go-ucfg/yaml"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Environment variables read by the package.
+const (
+	PolicyFileEnv     = "SECCOMP_POLICY_FILE"     // Path of the filter file.
+	PolicyFormatEnv   = "SECCOMP_POLICY_FORMAT"   // Format of the filter file.
+	PolicyOptionalEnv = "SECCOMP_POLICY_OPTIONAL" // Run unconfined on errors.
+)
+
+func init() {
+	if err := Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "seccomp autoload: %v\n", err)
+		if optional, _ := strconv.ParseBool(os.Getenv(PolicyOptionalEnv)); !optional {
+			os.Exit(1)
+		}
+	}
+}
+
+// Load installs the filter configured by the environment. It is called by
+// the package's init and returns nil without doing anything if
+// SECCOMP_POLICY_FILE is unset.
+func Load() error {
+	path := os.Getenv(PolicyFileEnv)
+	if path == "" {
+		return nil
+	}
+
+	filter, err := ReadFilter(path, os.Getenv(PolicyFormatEnv))
+	if err != nil {
+		return err
+	}
+	if err = seccomp.LoadFilter(*filter); err != nil {
+		return fmt.Errorf("failed to load filter from %v: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFilter reads a filter from a YAML or JSON file. If format is empty it
+// is derived from the file extension and defaults to YAML.
+func ReadFilter(path, format string) (*seccomp.Filter, error) {
+	if format == "" {
+		format = "yaml"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = "json"
+		}
+	}
+
+	var conf *ucfg.Config
+	var err error
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		conf, err = yaml.NewConfigWithFile(path)
+	case "json":
+		conf, err = json.NewConfigWithFile(path)
+	default:
+		return nil, fmt.Errorf("unsupported policy format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read filter from %v: %w", path, err)
+	}
+
+	filter := &seccomp.Filter{
+		NoNewPrivs: true,
+		Flag:       seccomp.FilterFlagTSync,
+	}
+	if err = conf.Unpack(filter); err != nil {
+		return nil, fmt.Errorf("invalid filter in %v: %w", path, err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package autoload
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFilter(t *testing.T) {
+	yamlPath := writeFile(t, "filter.yml", `
+policy:
+  default_action: allow
+  syscalls:
+    - action: errno
+      names: [getppid]
+`)
+	filter, err := ReadFilter(yamlPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filter.NoNewPrivs || filter.Flag != seccomp.FilterFlagTSync {
+		t.Errorf("defaults were not applied: %+v", filter)
+	}
+	if filter.Policy.DefaultAction != seccomp.ActionAllow || len(filter.Policy.Syscalls) != 1 ||
+		filter.Policy.Syscalls[0].Names[0] != "getppid" {
+		t.Errorf("unexpected policy %+v", filter.Policy)
+	}
+
+	jsonPath := writeFile(t, "filter.json", `{
+		"no_new_privs": false,
+		"flag": "log",
+		"policy": {"default_action": "errno", "syscalls": [{"action": "allow", "names": ["read"]}]}
+	}`)
+	if filter, err = ReadFilter(jsonPath, ""); err != nil {
+		t.Fatal(err)
+	}
+	if filter.NoNewPrivs || filter.Flag != seccomp.FilterFlagLog || filter.Policy.DefaultAction != seccomp.ActionErrno {
+		t.Errorf("unexpected filter %+v", filter)
+	}
+
+	if _, err = ReadFilter(jsonPath, "toml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	invalid := writeFile(t, "invalid.yml", "policy:\n  default_action: nope\n")
+	if _, err = ReadFilter(invalid, ""); err == nil {
+		t.Error("expected error for invalid action")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv(PolicyFileEnv, "")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if runtime.GOOS != "linux" {
+		t.Skip("filters are only checked on Linux")
+	}
+
+	// Dry run checks the filter without confining the test process.
+	t.Setenv(seccomp.DryRunEnv, "true")
+	t.Setenv(PolicyFileEnv, writeFile(t, "filter.yml", `
+policy:
+  default_action: allow
+  syscalls:
+    - action: errno
+      names: [getppid]
+`))
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(PolicyFileEnv, writeFile(t, "unknown.yml", `
+policy:
+  default_action: allow
+  syscalls:
+    - action: errno
+      names: [no_such_syscall]
+`))
+	if err := Load(); err == nil {
+		t.Error("expected error for unknown syscall")
+	}
+}
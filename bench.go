@@ -0,0 +1,174 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/bpf"
+)
+
+// BenchmarkSyscall is a syscall of the mix measured by Benchmark.
+type BenchmarkSyscall struct {
+	Name   string // Syscall name.
+	Weight int    // Relative frequency of the syscall in the mix (defaults to 1).
+}
+
+// DefaultBenchmarkMix is the syscall mix used by Benchmark if none is given.
+// It approximates the syscalls of a typical Go network service.
+var DefaultBenchmarkMix = []BenchmarkSyscall{
+	{Name: "read", Weight: 4},
+	{Name: "write", Weight: 4},
+	{Name: "futex", Weight: 2},
+	{Name: "epoll_pwait", Weight: 2},
+	{Name: "close", Weight: 1},
+	{Name: "fstat", Weight: 1},
+	{Name: "mmap", Weight: 1},
+	{Name: "getpid", Weight: 1},
+}
+
+// BenchmarkOptions configures Benchmark.
+type BenchmarkOptions struct {
+	Syscalls   []BenchmarkSyscall // Syscall mix (defaults to DefaultBenchmarkMix).
+	Iterations int                // Calls of each syscall per round (defaults to 100000).
+	Rounds     int                // Rounds per syscall, the fastest one counts (defaults to 5).
+}
+
+// SyscallLatency is the latency of one syscall with and without the filter.
+type SyscallLatency struct {
+	BenchmarkSyscall
+	Action   Action        // Action taken by the filter for the benchmark calls.
+	Baseline time.Duration // Latency without the filter.
+	Filtered time.Duration // Latency with the filter installed.
+}
+
+// Overhead returns the latency added by the filter.
+func (l SyscallLatency) Overhead() time.Duration {
+	return l.Filtered - l.Baseline
+}
+
+// BenchmarkResult is returned by Benchmark.
+type BenchmarkResult struct {
+	Instructions int              // Number of BPF instructions of the filter.
+	Syscalls     []SyscallLatency // Latency of each syscall of the mix.
+	Baseline     time.Duration    // Weighted mean latency without the filter.
+	Filtered     time.Duration    // Weighted mean latency with the filter.
+}
+
+// Overhead returns the weighted mean latency added by the filter to each
+// syscall of the mix.
+func (r BenchmarkResult) Overhead() time.Duration {
+	return r.Filtered - r.Baseline
+}
+
+// benchmarkUnsafe contains the syscalls that would end, replace, duplicate,
+// or block the benchmark process when called.
+var benchmarkUnsafe = map[string]bool{
+	"clone":           true,
+	"clone3":          true,
+	"execve":          true,
+	"execveat":        true,
+	"exit":            true,
+	"exit_group":      true,
+	"fork":            true,
+	"pause":           true,
+	"restart_syscall": true,
+	"rt_sigreturn":    true,
+	"rt_sigsuspend":   true,
+	"sigreturn":       true,
+	"sigsuspend":      true,
+	"vfork":           true,
+}
+
+// benchmarkReported contains the syscalls that the benchmark process needs
+// after the filter was installed to report the result.
+var benchmarkReported = []string{"write", "exit_group"}
+
+// benchmarkPlan resolves the syscall mix and checks with the emulator that
+// the filter lets the benchmark process survive every call.
+func benchmarkPlan(p *Policy, insts []bpf.Instruction, mix []BenchmarkSyscall) ([]SyscallLatency, []uintptr, error) {
+	emulator, err := NewEmulator(insts)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := p.Arch()
+	action := func(name string) (int, Action, error) {
+		nr, found := info.SyscallNumber(name)
+		if !found {
+			return 0, 0, fmt.Errorf("unknown syscall %v for arch %v", name, info.Name)
+		}
+		data := SeccompData{NR: int32(nr), Arch: uint32(info.ID)}
+		for i := range data.Args {
+			data.Args[i] = argumentLimit(info)
+		}
+		a, err := emulator.Run(data)
+		return nr, a, err
+	}
+
+	for _, name := range benchmarkReported {
+		_, a, err := action(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !(a & actionMask).permits() {
+			return nil, nil, fmt.Errorf("the filter must allow %v to report the result but takes action %v", name, a&actionMask)
+		}
+	}
+
+	latencies := make([]SyscallLatency, 0, len(mix))
+	nrs := make([]uintptr, 0, len(mix))
+	for _, s := range mix {
+		if benchmarkUnsafe[s.Name] {
+			return nil, nil, fmt.Errorf("syscall %v cannot be benchmarked", s.Name)
+		}
+		if s.Weight < 0 {
+			return nil, nil, fmt.Errorf("negative weight %d for syscall %v", s.Weight, s.Name)
+		}
+		if s.Weight == 0 {
+			s.Weight = 1
+		}
+		nr, a, err := action(s.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch a & actionMask {
+		case ActionAllow, ActionLog, ActionErrno:
+		default:
+			return nil, nil, fmt.Errorf("the filter takes action %v for syscall %v", a&actionMask, s.Name)
+		}
+		latencies = append(latencies, SyscallLatency{BenchmarkSyscall: s, Action: a})
+		nrs = append(nrs, uintptr(nr))
+	}
+	return latencies, nrs, nil
+}
+
+// weightedMeans sets the mean latencies of the result from its syscalls.
+func (r *BenchmarkResult) weightedMeans() {
+	var baseline, filtered time.Duration
+	var weights int
+	for _, l := range r.Syscalls {
+		baseline += time.Duration(l.Weight) * l.Baseline
+		filtered += time.Duration(l.Weight) * l.Filtered
+		weights += l.Weight
+	}
+	if weights > 0 {
+		r.Baseline = baseline / time.Duration(weights)
+		r.Filtered = filtered / time.Duration(weights)
+	}
+}
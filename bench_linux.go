@@ -0,0 +1,202 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+var benchmarkShim = registerShim("benchmark", runBenchmarkShim)
+
+// benchmarkRequest is sent to the benchmark child process.
+type benchmarkRequest struct {
+	validateRequest
+	Syscalls   []uintptr `json:"syscalls"`
+	Iterations int       `json:"iterations"`
+	Rounds     int       `json:"rounds"`
+}
+
+// benchmarkResponse is returned by the benchmark child process.
+type benchmarkResponse struct {
+	validateResponse
+	Baseline []time.Duration `json:"baseline"`
+	Filtered []time.Duration `json:"filtered"`
+}
+
+// Benchmark installs the filter in a short-lived child process (a
+// re-executed copy of the current binary) and measures the latency of each
+// syscall of the mix before and after installing it. The policy must be for
+// the native arch.
+//
+// The syscalls are called with invalid arguments so that they fail without
+// side effects. The filter is checked with the emulator before it is
+// installed and must allow the child to survive each call of the mix and to
+// report the result.
+func Benchmark(filter Filter, opts BenchmarkOptions) (*BenchmarkResult, error) {
+	insts, err := filter.Policy.Assemble()
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble policy: %w", err)
+	}
+	native, err := arch.GetInfo("")
+	if err != nil {
+		return nil, err
+	}
+	info := filter.Policy.Arch()
+	if info.ID != native.ID || info.SeccompMask != native.SeccompMask {
+		return nil, fmt.Errorf("cannot benchmark a policy for arch %v on %v", info.Name, native.Name)
+	}
+
+	mix := opts.Syscalls
+	if len(mix) == 0 {
+		for _, s := range DefaultBenchmarkMix {
+			if _, found := info.SyscallNumber(s.Name); found {
+				mix = append(mix, s)
+			}
+		}
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 100000
+	}
+	if opts.Rounds <= 0 {
+		opts.Rounds = 5
+	}
+
+	result := &BenchmarkResult{Instructions: len(insts)}
+	var nrs []uintptr
+	if result.Syscalls, nrs, err = benchmarkPlan(&filter.Policy, insts, mix); err != nil {
+		return nil, err
+	}
+	raw, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := json.Marshal(benchmarkRequest{
+		validateRequest: validateRequest{
+			Flag:                       uint32(filter.Flag),
+			NoNewPrivs:                 filter.NoNewPrivs,
+			SkipNoNewPrivsIfPrivileged: filter.SkipNoNewPrivsIfPrivileged,
+			Instructions:               raw,
+		},
+		Syscalls:   nrs,
+		Iterations: opts.Iterations,
+		Rounds:     opts.Rounds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(selfExe)
+	cmd.Env = append(os.Environ(), shimEnv+"="+benchmarkShim)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if !filter.Policy.IncludeGoRuntime {
+			err = fmt.Errorf("%w (the filter might block syscalls of the Go runtime, consider include_go_runtime)", err)
+		}
+		return nil, fmt.Errorf("benchmark process failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var resp benchmarkResponse
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid response from benchmark process: %w", err)
+	}
+	if resp.Message != "" {
+		return nil, &ValidationError{Message: resp.Message, Errno: resp.Errno}
+	}
+	if len(resp.Baseline) != len(nrs) || len(resp.Filtered) != len(nrs) {
+		return nil, errors.New("invalid response from benchmark process: wrong number of results")
+	}
+
+	for i := range result.Syscalls {
+		result.Syscalls[i].Baseline = resp.Baseline[i]
+		result.Syscalls[i].Filtered = resp.Filtered[i]
+	}
+	result.weightedMeans()
+	return result, nil
+}
+
+// runBenchmarkShim measures the syscalls received on stdin, installs the
+// filter, measures them again, and reports the latencies on stdout.
+func runBenchmarkShim() int {
+	var req benchmarkRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Shims run during init, so the main goroutine is locked to the main
+	// thread and the filter applies to all measurements.
+	// The first round warms up caches and is discarded.
+	var resp benchmarkResponse
+	measureSyscalls(req.Syscalls, req.Iterations, 1)
+	resp.Baseline = measureSyscalls(req.Syscalls, req.Iterations, req.Rounds)
+	filter := Filter{
+		Flag:                       FilterFlag(req.Flag),
+		NoNewPrivs:                 req.NoNewPrivs,
+		SkipNoNewPrivsIfPrivileged: req.SkipNoNewPrivsIfPrivileged,
+	}
+	if _, err := installFilter(filter, req.Instructions); err != nil {
+		resp.Message = err.Error()
+		errors.As(err, &resp.Errno)
+	} else {
+		resp.Filtered = measureSyscalls(req.Syscalls, req.Iterations, req.Rounds)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// measureSyscalls returns the fastest mean latency of each syscall over the
+// rounds.
+func measureSyscalls(nrs []uintptr, iterations, rounds int) []time.Duration {
+	// All bits set as file descriptor, pointer, size, and flags make the
+	// syscalls fail early without side effects, so the latency is mostly
+	// the cost of entering the kernel and evaluating the filter.
+	const a = ^uintptr(0)
+	latencies := make([]time.Duration, len(nrs))
+	for i, nr := range nrs {
+		for round := 0; round < rounds; round++ {
+			start := time.Now()
+			for n := 0; n < iterations; n++ {
+				syscall.RawSyscall6(nr, a, a, a, a, a, a)
+			}
+			latency := time.Since(start) / time.Duration(iterations)
+			if round == 0 || latency < latencies[i] {
+				latencies[i] = latency
+			}
+		}
+	}
+	return latencies
+}
@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestBenchmarkPlan(t *testing.T) {
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{Names: []string{"getppid"}, Action: ActionErrno},
+			{Names: []string{"kill"}, Action: ActionKillProcess},
+		},
+	}
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mix := []BenchmarkSyscall{{Name: "getpid", Weight: 2}, {Name: "getppid"}}
+	latencies, nrs, err := benchmarkPlan(policy, insts, mix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latencies) != 2 || len(nrs) != 2 {
+		t.Fatalf("expected 2 syscalls, got %d and %d", len(latencies), len(nrs))
+	}
+	if nrs[0] != uintptr(syscallNumber(arch.X86_64, "getpid")) {
+		t.Errorf("wrong syscall number %d for getpid", nrs[0])
+	}
+	if latencies[1].Weight != 1 {
+		t.Errorf("expected default weight 1, got %d", latencies[1].Weight)
+	}
+	if latencies[1].Action&actionMask != ActionErrno {
+		t.Errorf("expected errno for getppid, got %v", latencies[1].Action)
+	}
+
+	testCases := []struct {
+		mix []BenchmarkSyscall
+		err string
+	}{
+		{[]BenchmarkSyscall{{Name: "kill"}}, "action kill_process for syscall kill"},
+		{[]BenchmarkSyscall{{Name: "exit_group"}}, "cannot be benchmarked"},
+		{[]BenchmarkSyscall{{Name: "no_such_syscall"}}, "unknown syscall"},
+		{[]BenchmarkSyscall{{Name: "getpid", Weight: -1}}, "negative weight"},
+	}
+	for _, tc := range testCases {
+		if _, _, err = benchmarkPlan(policy, insts, tc.mix); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("expected error containing %q for %v, got %v", tc.err, tc.mix, err)
+		}
+	}
+
+	// The child needs write to report the result.
+	policy.Syscalls[1].Names = []string{"write"}
+	if insts, err = policy.Assemble(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = benchmarkPlan(policy, insts, mix); err == nil || !strings.Contains(err.Error(), "must allow write") {
+		t.Errorf("expected write to be required, got %v", err)
+	}
+}
+
+func TestBenchmarkResultWeightedMeans(t *testing.T) {
+	r := BenchmarkResult{Syscalls: []SyscallLatency{
+		{BenchmarkSyscall: BenchmarkSyscall{Weight: 3}, Baseline: 100, Filtered: 140},
+		{BenchmarkSyscall: BenchmarkSyscall{Weight: 1}, Baseline: 200, Filtered: 200},
+	}}
+	r.weightedMeans()
+	if r.Baseline != 125 || r.Filtered != 155 || r.Overhead() != 30*time.Nanosecond {
+		t.Errorf("wrong means: baseline %v, filtered %v", r.Baseline, r.Filtered)
+	}
+}
@@ -0,0 +1,232 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// policyHashVersion changes when the encoding hashed by Policy.Hash changes
+// or when the assembler changes the program assembled from a policy. It must
+// be bumped with such changes because the version of this module is unknown
+// in development builds.
+const policyHashVersion = 2
+
+// modulePath is used to find the version of this module in the build info.
+const modulePath = "github.com/elastic/go-seccomp-bpf"
+
+// compilerVersion is the version of this module, which is part of the hash
+// of a policy so that programs cached by another version are not reused. It
+// is empty if the version does not identify the code, like for development
+// builds and modules replaced by a local directory.
+var compilerVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, m := range append(info.Deps, &info.Main) {
+		if m.Path != modulePath {
+			continue
+		}
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		if m.Version == "" || m.Version == "(devel)" || strings.HasSuffix(m.Version, "+dirty") {
+			return ""
+		}
+		return m.Version + " " + m.Sum
+	}
+	return ""
+})
+
+// Hash returns a hash of everything that determines the program assembled
+// from the policy: its rules in order, the syscall profile, the arch, which
+// defaults to the native one, and the version of this module. Policies with
+// the same hash assemble into the same program.
+func (p *Policy) Hash() (string, error) {
+	info := p.arch
+	if info == nil {
+		var err error
+		if info, err = arch.GetInfo(""); err != nil {
+			return "", err
+		}
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "policy v%d %q\n", policyHashVersion, compilerVersion())
+	fmt.Fprintf(h, "arch %q\n", info.Name)
+	fmt.Fprintf(h, "default_action %d\n", p.DefaultAction)
+	fmt.Fprintf(h, "include_go_runtime %t\n", p.IncludeGoRuntime)
+	for _, g := range p.Syscalls {
+		fmt.Fprintf(h, "group %d %q\n", g.Action, g.Names)
+		for _, nc := range g.NamesWithCondtions {
+			fmt.Fprintf(h, "name %q %d\n", nc.Name, len(nc.Conditions))
+			for _, c := range nc.Conditions {
+				fmt.Fprintf(h, "condition %d %q %d\n", c.Argument, c.Operation, c.Value)
+			}
+		}
+	}
+	names := make([]string, 0, len(p.Profile))
+	for name := range p.Profile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "profile %q %d\n", name, p.Profile[name])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FilterCache caches the programs assembled from policies by their Hash, so
+// that processes which assemble the same policies repeatedly, for example
+// for every worker, only assemble each once. It is safe for concurrent use.
+// The zero value is an empty cache that is kept in memory. Entries are never
+// evicted, so the cache is meant for a bounded set of policies.
+type FilterCache struct {
+	// Dir keeps the programs as raw filters (arrays of struct sock_filter),
+	// named by the hash of the policy, in the directory if set, so that
+	// they are shared between processes and restarts. The directory must
+	// exist and must only be writable by trusted users, because the cached
+	// programs are installed without being checked against the policy.
+	// Files that cannot be read or written are assembled again. Dir is not
+	// used if the version of this module is unknown, because programs
+	// cached by other code could be reused.
+	Dir string
+
+	mu       sync.Mutex
+	programs map[string][]bpf.RawInstruction
+}
+
+// Assemble returns the program of the policy from the cache, assembling and
+// caching it if it is not cached yet. The program is the disassembly of the
+// raw filter, so its conditional jumps may be encoded differently than by
+// Policy.Assemble, with the same result. Like Policy.Assemble, it sets the
+// arch of the policy to the native one if it is not set. A nil cache
+// assembles the policy.
+func (c *FilterCache) Assemble(p *Policy) ([]bpf.Instruction, error) {
+	if c == nil {
+		return p.Assemble()
+	}
+	key, err := p.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if p.arch == nil {
+		if p.arch, err = arch.GetInfo(""); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	raw, found := c.programs[key]
+	c.mu.Unlock()
+	if !found && c.useDir() {
+		raw, found = c.read(key)
+	}
+	if found {
+		if insts, allDecoded := bpf.Disassemble(raw); allDecoded {
+			return insts, nil
+		}
+	}
+
+	insts, err := p.Assemble()
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = bpf.Assemble(insts); err != nil {
+		return nil, fmt.Errorf("failed to assemble BPF instructions: %w", err)
+	}
+	// Return the program as it is returned from the cache, which differs
+	// in the form of conditional jumps.
+	insts, _ = bpf.Disassemble(raw)
+
+	c.mu.Lock()
+	if c.programs == nil {
+		c.programs = map[string][]bpf.RawInstruction{}
+	}
+	c.programs[key] = raw
+	c.mu.Unlock()
+	if c.useDir() {
+		// A failure only costs assembling the policy again.
+		c.write(key, raw)
+	}
+	return insts, nil
+}
+
+// useDir returns true if programs are cached in Dir.
+func (c *FilterCache) useDir() bool {
+	return c.Dir != "" && compilerVersion() != ""
+}
+
+// path returns the file of the program in Dir.
+func (c *FilterCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".bpf")
+}
+
+// read returns the program from Dir.
+func (c *FilterCache) read(key string) ([]bpf.RawInstruction, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	raw, err := decodeRaw(data)
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	if c.programs == nil {
+		c.programs = map[string][]bpf.RawInstruction{}
+	}
+	c.programs[key] = raw
+	c.mu.Unlock()
+	return raw, true
+}
+
+// write stores the program in Dir. The file is renamed into place so that
+// concurrent readers never see a partial program.
+func (c *FilterCache) write(key string, raw []bpf.RawInstruction) error {
+	f, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(encodeRaw(raw))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(f.Name()))
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/bpf"
+)
+
+var cachePolicy = Policy{
+	DefaultAction: ActionErrno,
+	Syscalls: []SyscallGroup{
+		{Action: ActionAllow, Names: []string{"read", "write"}},
+		{
+			Action: ActionErrno | 13,
+			NamesWithCondtions: []NameWithConditions{
+				{Name: "openat", Conditions: []Condition{{Argument: 2, Operation: BitsSet, Value: 1}}},
+			},
+		},
+	},
+}
+
+func cachePolicyCopy(t *testing.T, archName string) *Policy {
+	t.Helper()
+	p := cachePolicy
+	if err := p.SetArch(archName); err != nil {
+		t.Fatal(err)
+	}
+	return &p
+}
+
+func TestPolicyHash(t *testing.T) {
+	hash := func(p *Policy) string {
+		t.Helper()
+		h, err := p.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	base := hash(cachePolicyCopy(t, "x86_64"))
+	if h := hash(cachePolicyCopy(t, "x86_64")); h != base {
+		t.Errorf("hash is not stable: %v != %v", h, base)
+	}
+	if h := hash(cachePolicyCopy(t, "aarch64")); h == base {
+		t.Error("hash does not depend on the arch")
+	}
+
+	for name, change := range map[string]func(p *Policy){
+		"default action": func(p *Policy) { p.DefaultAction = ActionKillProcess },
+		"group order": func(p *Policy) {
+			p.Syscalls = []SyscallGroup{p.Syscalls[1], p.Syscalls[0]}
+		},
+		"names": func(p *Policy) {
+			p.Syscalls = []SyscallGroup{{Action: ActionAllow, Names: []string{"readwrite"}}, p.Syscalls[1]}
+		},
+		"condition": func(p *Policy) {
+			p.Syscalls = []SyscallGroup{p.Syscalls[0], {
+				Action: ActionErrno | 13,
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "openat", Conditions: []Condition{{Argument: 2, Operation: BitsSet, Value: 2}}},
+				},
+			}}
+		},
+		"profile":    func(p *Policy) { p.Profile = SyscallProfile{"write": 10} },
+		"go runtime": func(p *Policy) { p.IncludeGoRuntime = true },
+	} {
+		p := cachePolicyCopy(t, "x86_64")
+		change(p)
+		if hash(p) == base {
+			t.Errorf("hash does not depend on the %s", name)
+		}
+	}
+}
+
+// cacheProgram returns the program of the policy as it is returned by a
+// FilterCache.
+func cacheProgram(t *testing.T, p *Policy) []bpf.Instruction {
+	t.Helper()
+	insts, err := p.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := bpf.Assemble(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insts, _ = bpf.Disassemble(raw)
+	return insts
+}
+
+func TestFilterCache(t *testing.T) {
+	want := cacheProgram(t, cachePolicyCopy(t, "x86_64"))
+
+	var c FilterCache
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.Assemble(cachePolicyCopy(t, "x86_64"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("unexpected program:\n%v", got)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(c.programs) != 1 {
+		t.Errorf("expected one cached program, got %d", len(c.programs))
+	}
+
+	// The returned program can be changed without affecting the cache.
+	got, err := c.Assemble(cachePolicyCopy(t, "x86_64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got[0] = bpf.RetConstant{}
+	if got, _ = c.Assemble(cachePolicyCopy(t, "x86_64")); !reflect.DeepEqual(got, want) {
+		t.Error("cached program was modified")
+	}
+
+	var nilCache *FilterCache
+	if got, err = nilCache.Assemble(cachePolicyCopy(t, "x86_64")); err != nil || len(got) != len(want) {
+		t.Errorf("nil cache returned %v, %v", got, err)
+	}
+
+	invalid := Policy{DefaultAction: ActionAllow, Syscalls: []SyscallGroup{{Action: ActionErrno, Names: []string{"nope"}}}}
+	if _, err = c.Assemble(&invalid); err == nil {
+		t.Error("expected an error for an invalid policy")
+	}
+}
+
+// setCompilerVersion overrides the version of this module for the test.
+func setCompilerVersion(t *testing.T, version string) {
+	orig := compilerVersion
+	compilerVersion = func() string { return version }
+	t.Cleanup(func() { compilerVersion = orig })
+}
+
+func TestFilterCacheDir(t *testing.T) {
+	setCompilerVersion(t, "v1.7.0 h1:test")
+	dir := t.TempDir()
+	p := cachePolicyCopy(t, "x86_64")
+	want := cacheProgram(t, p)
+	key, err := p.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = (&FilterCache{Dir: dir}).Assemble(p); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != key+".bpf" {
+		t.Fatalf("unexpected cache files: %v", entries)
+	}
+
+	// Another cache, e.g. of another process, reads the program.
+	got, err := (&FilterCache{Dir: dir}).Assemble(cachePolicyCopy(t, "x86_64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected program:\n%v", got)
+	}
+
+	// The file is trusted as long as it is a valid program.
+	path := filepath.Join(dir, key+".bpf")
+	if err = os.WriteFile(path, []byte{0x06, 0, 0, 0, 0, 0, 0xff, 0x7f}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = (&FilterCache{Dir: dir}).Assemble(cachePolicyCopy(t, "x86_64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bpf.Instruction{bpf.RetConstant{Val: uint32(ActionAllow)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the program from the file, got %v", got)
+	}
+
+	// Corrupt files are replaced.
+	if err = os.WriteFile(path, []byte("corrupt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = (&FilterCache{Dir: dir}).Assemble(cachePolicyCopy(t, "x86_64")); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected program %v, %v", got, err)
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) != 8*len(want) {
+		t.Errorf("corrupt file was not replaced: %d bytes, %v", len(data), err)
+	}
+
+	// A missing directory only disables the disk cache.
+	if _, err = (&FilterCache{Dir: filepath.Join(dir, "missing")}).Assemble(p); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFilterCacheDirUnknownVersion(t *testing.T) {
+	// Development builds do not share programs on disk because the
+	// assembler may have changed without changing the version.
+	setCompilerVersion(t, "")
+	dir := t.TempDir()
+	if _, err := (&FilterCache{Dir: dir}).Assemble(cachePolicyCopy(t, "x86_64")); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("unexpected cache files: %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,252 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package cli contains the policy loading and error handling shared by the
+// commands.
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// Formats lists the policy formats accepted by LoadFilter.
+const Formats = "yaml, json, oci, minijail, kafel, or pfc"
+
+// Fatal prints the error and exits with status 1.
+func Fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
+
+// LoadFilter reads a policy file and returns it as a filter for the arch (the
+// native arch if empty). Path "-" reads stdin. An empty format is detected
+// from the file extension: .yml and .yaml are YAML, .json is a policy or an
+// OCI profile depending on its keys, .policy is minijail, .kafel is kafel,
+// and .pfc is pseudo filter code.
+//
+// YAML and JSON documents contain a policy, a filter (with no_new_privs,
+// flag, and policy keys), or a policy under a top-level seccomp key as used
+// by the sandbox command and the Beats.
+func LoadFilter(path, format, archName string) (*seccomp.Filter, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		if format = formatOf(path); format == "" {
+			return nil, fmt.Errorf("unknown format of %v, set it to one of %v", path, Formats)
+		}
+	}
+
+	var filter *seccomp.Filter
+	switch format {
+	case "yaml":
+		filter, err = parseDocument(data, yaml.Unmarshal)
+	case "json":
+		var keys map[string]json.RawMessage
+		if json.Unmarshal(data, &keys) == nil && keys["defaultAction"] != nil {
+			return loadOCI(data, archName)
+		}
+		filter, err = parseDocument(data, json.Unmarshal)
+	case "oci":
+		return loadOCI(data, archName)
+	case "minijail":
+		var policy *seccomp.Policy
+		opts := seccomp.MinijailOptions{Arch: archName}
+		if path != "-" {
+			opts.IncludeDir = filepath.Dir(path)
+		}
+		if policy, err = seccomp.ReadMinijailPolicy(bytes.NewReader(data), opts); err == nil {
+			filter = &seccomp.Filter{NoNewPrivs: true, Policy: *policy}
+		}
+	case "kafel":
+		var policy *seccomp.Policy
+		if policy, err = seccomp.ReadKafelPolicy(bytes.NewReader(data), seccomp.KafelOptions{Arch: archName}); err == nil {
+			filter = &seccomp.Filter{NoNewPrivs: true, Policy: *policy}
+		}
+	case "pfc":
+		var policy *seccomp.Policy
+		if policy, err = seccomp.ReadPFC(bytes.NewReader(data), seccomp.PFCOptions{Arch: archName}); err == nil {
+			filter = &seccomp.Filter{NoNewPrivs: true, Policy: *policy}
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q, must be one of %v", format, Formats)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %w", path, err)
+	}
+
+	if err := filter.Policy.SetArch(archName); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// LoadPolicy is like LoadFilter but only returns the policy.
+func LoadPolicy(path, format, archName string) (*seccomp.Policy, error) {
+	filter, err := LoadFilter(path, format, archName)
+	if err != nil {
+		return nil, err
+	}
+	return &filter.Policy, nil
+}
+
+// ReadRaw reads a raw filter (an array of struct sock_filter) as written by
+// seccomp-gen. All supported architectures are little endian.
+func ReadRaw(path string) ([]bpf.Instruction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("%v is not a raw filter: size %d is not a multiple of 8", path, len(data))
+	}
+
+	raw := make([]bpf.RawInstruction, 0, len(data)/8)
+	for b := data; len(b) > 0; b = b[8:] {
+		raw = append(raw, bpf.RawInstruction{
+			Op: binary.LittleEndian.Uint16(b[0:]),
+			Jt: b[2],
+			Jf: b[3],
+			K:  binary.LittleEndian.Uint32(b[4:]),
+		})
+	}
+	insts, allDecoded := bpf.Disassemble(raw)
+	if !allDecoded {
+		return nil, fmt.Errorf("failed to disassemble %v", path)
+	}
+	return insts, nil
+}
+
+// SeccompData builds the input of a filter from a syscall name or number and
+// up to six arguments in Go syntax (e.g. 0x80000 or -100).
+func SeccompData(info *arch.Info, syscall string, args []string) (seccomp.SeccompData, error) {
+	data := seccomp.SeccompData{Arch: uint32(info.ID)}
+	if nr, found := info.SyscallNumber(syscall); found {
+		data.NR = int32(nr)
+	} else if nr, err := strconv.ParseInt(syscall, 0, 32); err == nil {
+		data.NR = int32(nr)
+	} else {
+		return data, fmt.Errorf("unknown syscall %q for arch %v", syscall, info.Name)
+	}
+	data.NR |= int32(info.SeccompMask)
+
+	if len(args) > len(data.Args) {
+		return data, fmt.Errorf("too many arguments, a syscall has at most %d", len(data.Args))
+	}
+	for i, arg := range args {
+		v, err := parseArg(arg)
+		if err != nil {
+			return data, fmt.Errorf("invalid argument %d: %w", i, err)
+		}
+		data.Args[i] = v
+	}
+	return data, nil
+}
+
+// parseArg parses an unsigned or, for values like AT_FDCWD, a negative
+// number.
+func parseArg(s string) (uint64, error) {
+	if strings.HasPrefix(s, "-") {
+		v, err := strconv.ParseInt(s, 0, 64)
+		return uint64(v), err
+	}
+	return strconv.ParseUint(s, 0, 64)
+}
+
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return "yaml"
+	case ".json":
+		return "json"
+	case ".policy":
+		return "minijail"
+	case ".kafel":
+		return "kafel"
+	case ".pfc":
+		return "pfc"
+	}
+	return ""
+}
+
+func loadOCI(data []byte, archName string) (*seccomp.Filter, error) {
+	profile, err := seccomp.ReadOCIProfile(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	filter, err := profile.Filter(seccomp.OCIOptions{Arch: archName})
+	if err != nil {
+		return nil, err
+	}
+	// OCI runtimes always set no new privs unless the process is privileged.
+	filter.NoNewPrivs = true
+	return filter, nil
+}
+
+// parseDocument decodes a YAML or JSON document that contains a policy, a
+// filter, or a policy under a seccomp key.
+func parseDocument(data []byte, unmarshal func([]byte, interface{}) error) (*seccomp.Filter, error) {
+	var keys map[string]interface{}
+	if err := unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case keys["seccomp"] != nil:
+		var doc struct {
+			Seccomp seccomp.Policy `json:"seccomp" yaml:"seccomp"`
+		}
+		if err := unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return &seccomp.Filter{NoNewPrivs: true, Policy: doc.Seccomp}, nil
+	case keys["policy"] != nil:
+		var filter seccomp.Filter
+		if err := unmarshal(data, &filter); err != nil {
+			return nil, err
+		}
+		return &filter, nil
+	default:
+		var policy seccomp.Policy
+		if err := unmarshal(data, &policy); err != nil {
+			return nil, err
+		}
+		return &seccomp.Filter{NoNewPrivs: true, Policy: policy}, nil
+	}
+}
@@ -0,0 +1,131 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestLoadFilter(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sandbox.yml": `
+seccomp:
+  default_action: allow
+  syscalls:
+  - action: errno
+    names: [connect]
+`,
+		"filter.yaml": `
+no_new_privs: false
+flag: log
+policy:
+  default_action: allow
+  syscalls:
+  - action: errno
+    names: [connect]
+`,
+		"policy.json": `{"default_action": "allow", "syscalls": [{"action": "errno", "names": ["connect"]}]}`,
+		"oci.json":    `{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"action": "SCMP_ACT_ERRNO", "names": ["connect"]}]}`,
+		"jail.policy": "connect: return 1\n",
+		"filter.pfc":  "if ($arch == 3221225534)\n  if ($syscall == 42)\n    action ERRNO(1);\n  action ALLOW;\naction KILL;\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name := range files {
+		filter, err := LoadFilter(filepath.Join(dir, name), "", "x86_64")
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if filter.Policy.Arch() == nil || filter.Policy.Arch().Name != "x86_64" {
+			t.Errorf("%v: expected arch x86_64, got %v", name, filter.Policy.Arch())
+		}
+		if name == "filter.yaml" {
+			if filter.NoNewPrivs || filter.Flag != seccomp.FilterFlagLog {
+				t.Errorf("%v: unexpected filter settings %+v", name, filter)
+			}
+		} else if !filter.NoNewPrivs {
+			t.Errorf("%v: expected no new privs", name)
+		}
+		if _, err := filter.Policy.Assemble(); err != nil {
+			t.Errorf("%v: %v", name, err)
+		}
+	}
+
+	if _, err := LoadFilter(filepath.Join(dir, "sandbox.yml"), "toml", ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestReadRaw(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter.bin")
+	// ld [4]; ret #0x7fff0000
+	raw := []byte{0x20, 0, 0, 0, 4, 0, 0, 0, 0x06, 0, 0, 0, 0, 0, 0xff, 0x7f}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	insts, err := ReadRaw(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bpf.Instruction{bpf.LoadAbsolute{Off: 4, Size: 4}, bpf.RetConstant{Val: 0x7fff0000}}
+	if !reflect.DeepEqual(insts, want) {
+		t.Errorf("expected %v, got %v", want, insts)
+	}
+
+	if err = os.WriteFile(path, raw[:12], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadRaw(path); err == nil {
+		t.Error("expected an error for a truncated filter")
+	}
+}
+
+func TestSeccompData(t *testing.T) {
+	data, err := SeccompData(arch.X86_64, "openat", []string{"-100", "0x10", "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := seccomp.SeccompData{NR: 257, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{1<<64 - 100, 0x10}}
+	if data != want {
+		t.Errorf("expected %+v, got %+v", want, data)
+	}
+
+	if data, err = SeccompData(arch.X32, "1", nil); err != nil || data.NR != 1|int32(arch.X32.SeccompMask) {
+		t.Errorf("unexpected x32 data %+v, %v", data, err)
+	}
+	for _, args := range [][]string{{"no_such_syscall"}, {"read", "x"}, {"read", "1", "2", "3", "4", "5", "6", "7"}} {
+		if _, err := SeccompData(arch.X86_64, args[0], args[1:]); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
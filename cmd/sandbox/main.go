@@ -15,13 +15,30 @@
 // specific language governing permissions and limitations
 // under the License.
 
+//go:build linux
+// +build linux
+
+// Command sandbox runs a program confined by a seccomp policy.
+//
+//	sandbox [flags] command [args...]
+//
+// By default the sandbox installs the filter on itself and replaces itself
+// with the command, so the command has the sandbox's PID and its exit status
+// and signals reach the caller directly. With -fork the sandbox stays
+// unconfined, runs the command as a child, forwards signals to it, and exits
+// like the child.
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
 
 	"github.com/elastic/go-ucfg/yaml"
 
@@ -29,13 +46,29 @@ import (
 )
 
 var (
-	policyFile string
-	noNewPrivs bool
+	policyFile    string
+	noNewPrivs    bool
+	defaultAction string
+	filterFlags   string
+	dropCaps      bool
+	fork          bool
+	rlimits       rlimitFlags
 )
 
+func init() {
+	// Capabilities are per thread. Keep main on one thread so that the
+	// thread that drops them is the one that executes the command.
+	runtime.LockOSThread()
+}
+
 func main() {
 	flag.StringVar(&policyFile, "policy", "seccomp.yml", "seccomp policy file")
 	flag.BoolVar(&noNewPrivs, "no-new-privs", true, "set no new privs bit")
+	flag.StringVar(&defaultAction, "default-action", "", "override the default action of the policy")
+	flag.StringVar(&filterFlags, "flags", "", "filter flags separated by '|' (e.g. log|spec_allow)")
+	flag.BoolVar(&dropCaps, "drop-caps", false, "drop all capabilities before running the command")
+	flag.BoolVar(&fork, "fork", false, "run the command as a child instead of replacing the sandbox")
+	flag.Var(&rlimits, "rlimit", "resource limit as name=soft[:hard] (e.g. nofile=1024), can be repeated")
 	flag.Parse()
 
 	args := flag.Args()
@@ -44,35 +77,67 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Load policy from file.
-	policy, err := parsePolicy()
+	filter, err := buildFilter()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		fatal(err)
 	}
 
-	// Create a filter based on config.
-	filter := seccomp.Filter{
-		NoNewPrivs: noNewPrivs,
-		Flag:       seccomp.FilterFlagTSync,
-		Policy:     *policy,
+	if err = rlimits.apply(); err != nil {
+		fatal(err)
+	}
+	if dropCaps {
+		if err = dropCapabilities(); err != nil {
+			fatal(fmt.Errorf("failed to drop capabilities: %w", err))
+		}
+	}
+
+	if fork {
+		os.Exit(runChild(filter, args))
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		fatal(err)
 	}
 
 	// Load the BPF filter using the seccomp system call.
-	if err = seccomp.LoadFilter(filter); err != nil {
-		fmt.Fprintf(os.Stderr, "error loading filter: %v\n", err)
-		os.Exit(1)
+	filter.Flag |= seccomp.FilterFlagTSync
+	if err = seccomp.LoadFilter(*filter); err != nil {
+		fatal(fmt.Errorf("error loading filter: %w", err))
 	}
 
-	// Execute the specified command (requires execve).
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	if err = cmd.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+	// Replace the sandbox with the command (requires execve).
+	err = syscall.Exec(path, args, os.Environ())
+	fatal(fmt.Errorf("failed to execute %v: %w", path, err))
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
+
+// buildFilter reads the policy file and applies the command line options.
+func buildFilter() (*seccomp.Filter, error) {
+	policy, err := parsePolicy()
+	if err != nil {
+		return nil, err
+	}
+	if defaultAction != "" {
+		if err = policy.DefaultAction.Unpack(defaultAction); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := &seccomp.Filter{
+		NoNewPrivs: noNewPrivs,
+		Policy:     *policy,
 	}
+	if filterFlags != "" {
+		if err = filter.Flag.Unpack(filterFlags); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
 }
 
 func parsePolicy() (*seccomp.Policy, error) {
@@ -92,3 +157,91 @@ func parsePolicy() (*seccomp.Policy, error) {
 
 	return &config.Seccomp, nil
 }
+
+// runChild runs the command with the filter while the sandbox stays
+// unconfined and returns the exit code. If the child was killed by a signal
+// the sandbox kills itself with the same signal.
+func runChild(filter *seccomp.Filter, args []string) int {
+	cmd := seccomp.Command(*filter, args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Forward the signals that a terminal or supervisor typically sends.
+	signals := make(chan os.Signal, 16)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT,
+		syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGWINCH)
+	if err := cmd.Start(); err != nil {
+		fatal(err)
+	}
+	go func() {
+		for sig := range signals {
+			cmd.Process.Signal(sig)
+		}
+	}()
+
+	err := cmd.Wait()
+	signal.Stop(signals)
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		fatal(err)
+	}
+
+	status := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if status.Signaled() {
+		sig := status.Signal()
+		signal.Reset(sig)
+		syscall.Kill(os.Getpid(), sig)
+		// Not reached unless the signal is ignored or does not terminate.
+		return 128 + int(sig)
+	}
+	return status.ExitStatus()
+}
+
+// rlimitFlags is a repeatable flag of resource limits.
+type rlimitFlags []rlimit
+
+type rlimit struct {
+	resource int
+	limit    syscall.Rlimit
+}
+
+func (f *rlimitFlags) String() string {
+	return ""
+}
+
+func (f *rlimitFlags) Set(value string) error {
+	name, limits, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("invalid rlimit %q, expected name=soft[:hard]", value)
+	}
+	resource, found := rlimitNames[strings.ToLower(name)]
+	if !found {
+		return fmt.Errorf("unknown rlimit %q", name)
+	}
+
+	soft, hard, found := strings.Cut(limits, ":")
+	if !found {
+		hard = soft
+	}
+	var r rlimit
+	var err error
+	r.resource = resource
+	if r.limit.Cur, err = parseLimit(soft); err != nil {
+		return err
+	}
+	if r.limit.Max, err = parseLimit(hard); err != nil {
+		return err
+	}
+	*f = append(*f, r)
+	return nil
+}
+
+func (f rlimitFlags) apply() error {
+	for _, r := range f {
+		if err := syscall.Setrlimit(r.resource, &r.limit); err != nil {
+			return fmt.Errorf("failed to set rlimit %d: %w", r.resource, err)
+		}
+	}
+	return nil
+}
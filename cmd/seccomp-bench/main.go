@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-bench measures the syscall latency that a policy adds, so
+// that the cost of large policies can be quantified before rollout.
+//
+//	seccomp-bench [flags] policy-file
+//
+// The filter is installed in a child process that calls each syscall of the
+// mix before and after installing it. The mix is a comma separated list of
+// syscall names with optional weights (e.g. read:4,write:4,getpid).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat string
+	syscallMix  string
+	iterations  int
+	rounds      int
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&syscallMix, "syscalls", "", "syscall mix as name[:weight],... (defaults to a typical Go service)")
+	flag.IntVar(&iterations, "iterations", 100000, "calls of each syscall per round")
+	flag.IntVar(&rounds, "rounds", 5, "rounds per syscall, the fastest one counts")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-bench [flags] policy-file\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	filter, err := cli.LoadFilter(flag.Arg(0), inputFormat, "")
+	if err != nil {
+		cli.Fatal(err)
+	}
+	mix, err := parseMix(syscallMix)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	result, err := seccomp.Benchmark(*filter, seccomp.BenchmarkOptions{
+		Syscalls:   mix,
+		Iterations: iterations,
+		Rounds:     rounds,
+	})
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "syscall\tweight\taction\tbaseline\tfiltered\toverhead\t\n")
+	for _, l := range result.Syscalls {
+		fmt.Fprintf(w, "%v\t%d\t%v\t%v\t%v\t%v\t\n", l.Name, l.Weight, l.Action, l.Baseline, l.Filtered, l.Overhead())
+	}
+	fmt.Fprintf(w, "mean\t\t\t%v\t%v\t%v\t\n", result.Baseline, result.Filtered, result.Overhead())
+	w.Flush()
+	fmt.Printf("\n%d instructions, %v added per syscall\n", result.Instructions, result.Overhead())
+}
+
+// parseMix parses a comma separated list of syscall names with optional
+// weights.
+func parseMix(s string) ([]seccomp.BenchmarkSyscall, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var mix []seccomp.BenchmarkSyscall
+	for _, entry := range strings.Split(s, ",") {
+		name, weight, found := strings.Cut(strings.TrimSpace(entry), ":")
+		syscall := seccomp.BenchmarkSyscall{Name: name}
+		if found {
+			w, err := strconv.Atoi(weight)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight %q for syscall %v", weight, name)
+			}
+			syscall.Weight = w
+		}
+		mix = append(mix, syscall)
+	}
+	return mix, nil
+}
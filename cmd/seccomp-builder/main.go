@@ -0,0 +1,481 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+// Command seccomp-builder is an interactive terminal UI for writing a policy.
+//
+//	seccomp-builder [flags] policy-file
+//
+// It lists the syscalls of the arch by category. Each syscall can be allowed,
+// denied, or forwarded to a supervisor with user notifications, optionally
+// only when argument conditions hold. The size of the compiled filter is
+// shown while editing. If the policy file exists it is loaded, and it is
+// written as YAML when saving.
+//
+// Keys:
+//
+//	up/down, j/k, pgup/pgdn, g/G  move          tab   next category
+//	a / x / n / l                 allow, deny, notify, or log the syscall
+//	space                         cycle the rule    u     remove the rule
+//	c                             add a condition   C     clear conditions
+//	d                             cycle the default action
+//	/                             search            esc   clear the search
+//	w                             write the file    q     quit
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat   string
+	archName      string
+	defaultAction = seccomp.ActionErrno
+	denyAction    = seccomp.ActionErrno
+)
+
+// defaultActions are cycled through with the d key.
+var defaultActions = []seccomp.Action{
+	seccomp.ActionErrno,
+	seccomp.ActionKillProcess,
+	seccomp.ActionKillThread,
+	seccomp.ActionTrap,
+	seccomp.ActionLog,
+	seccomp.ActionAllow,
+}
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "format of an existing policy file ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archName, "arch", "", "policy architecture (e.g. x86_64 or aarch64), defaults to the native architecture")
+	flag.TextVar(&defaultAction, "default-action", seccomp.ActionErrno, "default action of a new policy")
+	flag.TextVar(&denyAction, "deny-action", seccomp.ActionErrno, "action used to deny syscalls")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-builder [flags] policy-file\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	info, err := arch.GetInfo(archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	m := newModel(info, defaultAction, denyAction)
+	path := flag.Arg(0)
+	if _, err = os.Stat(path); err == nil {
+		policy, err := cli.LoadPolicy(path, inputFormat, info.Name)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		m.load(policy)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		cli.Fatal(err)
+	}
+
+	t, err := openTerminal()
+	if err != nil {
+		cli.Fatal(fmt.Errorf("failed to set up the terminal: %w", err))
+	}
+	u := &ui{model: m, term: t, path: path}
+	u.filter()
+	err = u.run()
+	t.Close()
+	if err != nil {
+		cli.Fatal(err)
+	}
+}
+
+// Input modes of the UI.
+const (
+	modeList = iota
+	modeSearch
+	modeCondition
+)
+
+// row is a line of the list, either a category header or a syscall.
+type row struct {
+	category string
+	entry    *entry
+}
+
+type ui struct {
+	model *model
+	term  *terminal
+	path  string
+
+	rows   []row
+	cursor int // Index of the selected row, always a syscall.
+	top    int // Index of the first visible row.
+
+	mode   int
+	query  string
+	input  string
+	status string
+	dirty  bool
+	quit   bool // Quit was pressed once with unsaved changes.
+}
+
+func (u *ui) run() error {
+	for {
+		u.render()
+		if err := u.term.out.Flush(); err != nil {
+			return err
+		}
+		key, err := u.term.readKey()
+		if err != nil {
+			return err
+		}
+		if done := u.handle(key); done {
+			return nil
+		}
+	}
+}
+
+// filter rebuilds the rows for the search query.
+func (u *ui) filter() {
+	var selected *entry
+	if u.cursor < len(u.rows) {
+		selected = u.rows[u.cursor].entry
+	}
+
+	u.rows = u.rows[:0]
+	category := ""
+	for _, e := range u.model.entries {
+		if u.query != "" && !strings.Contains(e.name, u.query) && !strings.Contains(e.category, u.query) {
+			continue
+		}
+		if e.category != category {
+			category = e.category
+			u.rows = append(u.rows, row{category: category})
+		}
+		u.rows = append(u.rows, row{entry: e})
+	}
+
+	u.cursor, u.top = 0, 0
+	for i, r := range u.rows {
+		if r.entry != nil && r.entry == selected {
+			u.cursor = i
+			return
+		}
+	}
+	u.move(0)
+}
+
+// move moves the cursor by delta rows, skipping category headers.
+func (u *ui) move(delta int) {
+	i := u.cursor + delta
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(u.rows) {
+		i = len(u.rows) - 1
+	}
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	for j := i; j >= 0 && j < len(u.rows); j += step {
+		if u.rows[j].entry != nil {
+			u.cursor = j
+			return
+		}
+	}
+	for j := i; j >= 0 && j < len(u.rows); j -= step {
+		if u.rows[j].entry != nil {
+			u.cursor = j
+			return
+		}
+	}
+}
+
+// nextCategory moves the cursor to the first syscall of the next category.
+func (u *ui) nextCategory() {
+	for i := u.cursor + 1; i < len(u.rows); i++ {
+		if u.rows[i].entry == nil {
+			u.move(i + 1 - u.cursor)
+			return
+		}
+	}
+	u.move(-len(u.rows))
+}
+
+func (u *ui) selected() *entry {
+	if u.cursor < len(u.rows) {
+		return u.rows[u.cursor].entry
+	}
+	return nil
+}
+
+// handle processes a key and reports whether the UI should exit.
+func (u *ui) handle(key rune) bool {
+	switch u.mode {
+	case modeSearch, modeCondition:
+		u.edit(key)
+		return false
+	}
+
+	u.status = ""
+	if key != 'q' && key != keyCtrlC {
+		u.quit = false
+	}
+	_, rows := u.term.size()
+	page := rows - 4
+	e := u.selected()
+
+	switch key {
+	case keyUp, 'k':
+		u.move(-1)
+	case keyDown, 'j':
+		u.move(1)
+	case keyPageUp:
+		u.move(-page)
+	case keyPageDown:
+		u.move(page)
+	case keyHome, 'g':
+		u.move(-len(u.rows))
+	case keyEnd, 'G':
+		u.move(len(u.rows))
+	case keyTab:
+		u.nextCategory()
+	case '/':
+		u.mode, u.input = modeSearch, u.query
+	case keyEscape:
+		u.query = ""
+		u.filter()
+	case 'a':
+		u.setAction(e, seccomp.ActionAllow)
+	case 'x':
+		u.setAction(e, u.model.denyAction)
+	case 'n':
+		u.setAction(e, seccomp.ActionUserNotify)
+	case 'l':
+		u.setAction(e, seccomp.ActionLog)
+	case 'u', '-':
+		u.setAction(e, 0)
+	case ' ':
+		if e != nil {
+			switch e.action {
+			case 0:
+				u.setAction(e, seccomp.ActionAllow)
+			case seccomp.ActionAllow:
+				u.setAction(e, u.model.denyAction)
+			case seccomp.ActionUserNotify:
+				u.setAction(e, 0)
+			default:
+				u.setAction(e, seccomp.ActionUserNotify)
+			}
+		}
+	case 'c':
+		if e != nil {
+			u.mode, u.input = modeCondition, ""
+		}
+	case 'C':
+		if e != nil && len(e.conditions) > 0 {
+			e.conditions = nil
+			u.dirty = true
+		}
+	case 'd':
+		next := defaultActions[0]
+		for i, a := range defaultActions {
+			if a == u.model.defaultAction && i+1 < len(defaultActions) {
+				next = defaultActions[i+1]
+			}
+		}
+		u.model.defaultAction = next
+		u.dirty = true
+	case 'w':
+		if err := u.write(); err != nil {
+			u.status = "error: " + err.Error()
+		} else {
+			u.status = "wrote " + u.path
+			u.dirty = false
+		}
+	case 'q', keyCtrlC:
+		if !u.dirty || u.quit {
+			return true
+		}
+		u.quit = true
+		u.status = "unsaved changes, press q again to quit without writing"
+	}
+	return false
+}
+
+func (u *ui) setAction(e *entry, action seccomp.Action) {
+	if e == nil || e.action == action {
+		return
+	}
+	e.action = action
+	if action == 0 {
+		e.conditions = nil
+	}
+	u.dirty = true
+}
+
+// edit processes a key of the search or condition prompt.
+func (u *ui) edit(key rune) {
+	switch key {
+	case keyEscape, keyCtrlC:
+		u.mode = modeList
+	case keyBackspace:
+		if r := []rune(u.input); len(r) > 0 {
+			u.input = string(r[:len(r)-1])
+		}
+	case keyEnter:
+		mode := u.mode
+		u.mode = modeList
+		if mode == modeSearch {
+			u.query = strings.TrimSpace(u.input)
+			u.filter()
+			return
+		}
+		e := u.selected()
+		c, err := parseCondition(u.input)
+		if err != nil {
+			u.status = "error: " + err.Error()
+			return
+		}
+		if len(e.conditions) == 6 {
+			u.status = "error: a rule has at most 6 conditions"
+			return
+		}
+		e.conditions = append(e.conditions, c)
+		if e.action == 0 {
+			e.action = seccomp.ActionAllow
+		}
+		u.dirty = true
+	default:
+		if key >= ' ' && key < keyUp {
+			u.input += string(key)
+		}
+	}
+}
+
+func (u *ui) render() {
+	cols, rows := u.term.size()
+	out := u.term.out
+	out.WriteString("\x1b[H\x1b[2J")
+	line := func(s string) {
+		if r := []rune(s); len(r) > cols {
+			s = string(r[:cols])
+		}
+		out.WriteString(s + "\r\n")
+	}
+
+	allowed, denied, notified := u.model.counts()
+	size := "empty"
+	if n, err := u.model.instructions(); err != nil {
+		size = "error: " + err.Error()
+	} else if n > maxInstructions {
+		size = fmt.Sprintf("%d instructions, over the limit of %d", n, maxInstructions)
+	} else if n > 0 {
+		size = fmt.Sprintf("%d/%d instructions", n, maxInstructions)
+	}
+	modified := ""
+	if u.dirty {
+		modified = " [modified]"
+	}
+	out.WriteString("\x1b[1m")
+	line(fmt.Sprintf("%v (%v)%v  default %v  allow %d  deny %d  notify %d  filter %v",
+		u.path, u.model.arch.Name, modified, u.model.defaultAction, allowed, denied, notified, size))
+	out.WriteString("\x1b[0m")
+	if u.query != "" {
+		line(fmt.Sprintf("search %q: %d syscalls", u.query, u.matches()))
+	} else {
+		line("a allow  x deny  n notify  space cycle  c condition  d default  / search  w write  q quit")
+	}
+
+	// Keep the cursor visible.
+	height := rows - 3
+	if height < 1 {
+		height = 1
+	}
+	if u.cursor < u.top {
+		u.top = u.cursor
+		if u.top > 0 && u.rows[u.top-1].entry == nil {
+			u.top--
+		}
+	}
+	if u.cursor >= u.top+height {
+		u.top = u.cursor - height + 1
+	}
+
+	for i := u.top; i < u.top+height; i++ {
+		if i >= len(u.rows) {
+			line("")
+			continue
+		}
+		r := u.rows[i]
+		if r.entry == nil {
+			line("\x1b[4m" + r.category + "\x1b[0m")
+			continue
+		}
+		text := fmt.Sprintf("  %-14s %-24s %v", r.entry.label(), r.entry.name, formatConditions(r.entry.conditions))
+		if i == u.cursor {
+			out.WriteString("\x1b[7m")
+			line(text + "\x1b[0m")
+			continue
+		}
+		line(text)
+	}
+
+	switch {
+	case u.mode == modeSearch:
+		out.WriteString("/" + u.input + "\x1b[?25h")
+	case u.mode == modeCondition:
+		out.WriteString("condition for " + u.selected().name + " (<arg> <op> <value>, e.g. 1 & 0x80000): " + u.input + "\x1b[?25h")
+	default:
+		out.WriteString("\x1b[?25l" + u.status)
+	}
+}
+
+// matches returns the number of syscalls matching the search.
+func (u *ui) matches() int {
+	n := 0
+	for _, r := range u.rows {
+		if r.entry != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (u *ui) write() error {
+	policy := u.model.policy()
+	if len(policy.Syscalls) == 0 {
+		return errors.New("the policy has no rules")
+	}
+	data, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("# Policy for %v written by seccomp-builder.\n", u.model.arch.Name)
+	return os.WriteFile(u.path, append([]byte(header), data...), 0o644)
+}
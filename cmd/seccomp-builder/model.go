@@ -0,0 +1,217 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// maxInstructions is the instruction limit of the kernel (BPF_MAXINSNS).
+const maxInstructions = 4096
+
+// entry is a syscall of the arch and the rule chosen for it. A zero action
+// means that the syscall has no rule and the default action applies.
+type entry struct {
+	name       string
+	category   string
+	action     seccomp.Action
+	conditions []seccomp.Condition
+}
+
+// model is the policy being edited.
+type model struct {
+	arch          *arch.Info
+	defaultAction seccomp.Action
+	denyAction    seccomp.Action
+	entries       []*entry // Sorted by category and name.
+	byName        map[string]*entry
+}
+
+func newModel(info *arch.Info, defaultAction, denyAction seccomp.Action) *model {
+	m := &model{arch: info, defaultAction: defaultAction, denyAction: denyAction, byName: map[string]*entry{}}
+	for _, name := range info.Syscalls() {
+		e := &entry{name: name, category: seccomp.SyscallCategory(name)}
+		m.entries = append(m.entries, e)
+		m.byName[name] = e
+	}
+	sort.Slice(m.entries, func(i, j int) bool {
+		a, b := m.entries[i], m.entries[j]
+		if a.category != b.category {
+			// The catch-all category goes last like in reports.
+			if (a.category == "other") != (b.category == "other") {
+				return b.category == "other"
+			}
+			return a.category < b.category
+		}
+		return a.name < b.name
+	})
+	return m
+}
+
+// load sets the rules of the syscalls named by the policy. Only the first
+// rule of a syscall is used because later ones never match it, and rules
+// with several condition sets keep the first one.
+func (m *model) load(p *seccomp.Policy) {
+	m.defaultAction = p.DefaultAction
+	for _, group := range p.Syscalls {
+		for _, name := range group.Names {
+			if e := m.byName[name]; e != nil && e.action == 0 {
+				e.action = group.Action
+			}
+		}
+		for _, s := range group.NamesWithCondtions {
+			if e := m.byName[s.Name]; e != nil && e.action == 0 {
+				e.action = group.Action
+				e.conditions = append([]seccomp.Condition(nil), s.Conditions...)
+			}
+		}
+	}
+}
+
+// policy returns the policy with one group per action.
+func (m *model) policy() *seccomp.Policy {
+	p := &seccomp.Policy{DefaultAction: m.defaultAction}
+	groups := map[seccomp.Action]*seccomp.SyscallGroup{}
+	var actions []seccomp.Action
+	for _, e := range m.entries {
+		if e.action == 0 {
+			continue
+		}
+		g, found := groups[e.action]
+		if !found {
+			g = &seccomp.SyscallGroup{Action: e.action}
+			groups[e.action] = g
+			actions = append(actions, e.action)
+		}
+		if len(e.conditions) == 0 {
+			g.Names = append(g.Names, e.name)
+		} else {
+			g.NamesWithCondtions = append(g.NamesWithCondtions, seccomp.NameWithConditions{Name: e.name, Conditions: e.conditions})
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	for _, a := range actions {
+		p.Syscalls = append(p.Syscalls, *groups[a])
+	}
+	return p
+}
+
+// instructions compiles the policy and returns the size of the filter.
+func (m *model) instructions() (int, error) {
+	p := m.policy()
+	if len(p.Syscalls) == 0 {
+		return 0, nil
+	}
+	if err := p.SetArch(m.arch.Name); err != nil {
+		return 0, err
+	}
+	insts, err := p.Assemble()
+	return len(insts), err
+}
+
+// counts returns the number of syscalls that are allowed, denied, and
+// notified by rules.
+func (m *model) counts() (allowed, denied, notified int) {
+	for _, e := range m.entries {
+		switch {
+		case e.action == 0:
+		case e.action == seccomp.ActionUserNotify:
+			notified++
+		case e.action == seccomp.ActionAllow || e.action == seccomp.ActionLog:
+			allowed++
+		default:
+			denied++
+		}
+	}
+	return allowed, denied, notified
+}
+
+// label is the short name of the rule of an entry shown in the list.
+func (e *entry) label() string {
+	switch e.action {
+	case 0:
+		return "-"
+	case seccomp.ActionUserNotify:
+		return "notify"
+	}
+	return e.action.String()
+}
+
+// operators maps the operators accepted in condition prompts to operations.
+var operators = map[string]seccomp.Operation{
+	"==": seccomp.Equal,
+	"!=": seccomp.NotEqual,
+	">":  seccomp.GreaterThan,
+	"<":  seccomp.LessThan,
+	">=": seccomp.GreaterOrEqual,
+	"<=": seccomp.LessOrEqual,
+	"&":  seccomp.BitsSet,
+	"!&": seccomp.BitsNotSet,
+}
+
+// parseCondition parses "<arg> <op> <value>", for example "1 & 0x80000"
+// or "0 equal 2". The operator is a symbol or an operation name.
+func parseCondition(s string) (seccomp.Condition, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return seccomp.Condition{}, fmt.Errorf("expected <arg> <op> <value>, got %q", s)
+	}
+	arg, err := strconv.ParseUint(strings.TrimPrefix(fields[0], "arg"), 10, 32)
+	if err != nil || arg > 5 {
+		return seccomp.Condition{}, fmt.Errorf("invalid argument %q, must be 0 to 5", fields[0])
+	}
+	op, found := operators[fields[1]]
+	if !found {
+		if err = op.Unpack(fields[1]); err != nil {
+			return seccomp.Condition{}, err
+		}
+	}
+	value, err := strconv.ParseUint(fields[2], 0, 64)
+	if err != nil {
+		n, nerr := strconv.ParseInt(fields[2], 0, 64)
+		if nerr != nil {
+			return seccomp.Condition{}, fmt.Errorf("invalid value %q", fields[2])
+		}
+		value = uint64(n)
+	}
+	return seccomp.Condition{Argument: uint32(arg), Operation: op, Value: value}, nil
+}
+
+// formatConditions returns the conditions in the syntax of parseCondition.
+func formatConditions(conditions []seccomp.Condition) string {
+	parts := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		op := string(c.Operation)
+		for symbol, o := range operators {
+			if o == c.Operation {
+				op = symbol
+			}
+		}
+		parts = append(parts, fmt.Sprintf("arg%d %v %#x", c.Argument, op, c.Value))
+	}
+	return strings.Join(parts, " && ")
+}
@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-convert converts a policy between the supported formats.
+//
+//	seccomp-convert [flags] policy-file
+//
+// The input is read in any format that the other commands accept and is
+// written in one of
+//
+//	yaml      native policy in YAML
+//	json      native policy in JSON
+//	oci       OCI runtime profile in JSON (Docker, Podman, Kubernetes)
+//	minijail  minijail .policy file
+//	kafel     kafel policy (nsjail)
+//	pfc       libseccomp pseudo filter code
+//
+// Formats differ in what they can express. Minijail only supports argument
+// conditions that allow a syscall, and pseudo filter code is read back by
+// decompiling it. The conversion fails rather than change the decisions of
+// the policy.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat  string
+	outputFormat string
+	archName     string
+	outFile      string
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "input format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&outputFormat, "to", "yaml", "output format (yaml, json, oci, minijail, kafel, or pfc)")
+	flag.StringVar(&archName, "arch", "", "architecture of the policy, defaults to the native architecture")
+	flag.StringVar(&outFile, "out", "-", "output file")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-convert [flags] policy-file\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	filter, err := cli.LoadFilter(flag.Arg(0), inputFormat, archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	out := os.Stdout
+	if outFile != "-" {
+		if out, err = os.Create(outFile); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	if err = convert(out, filter); err != nil {
+		if outFile != "-" {
+			out.Close()
+			os.Remove(outFile)
+		}
+		cli.Fatal(err)
+	}
+	if err = out.Close(); err != nil {
+		cli.Fatal(err)
+	}
+}
+
+func convert(w io.Writer, filter *seccomp.Filter) error {
+	switch outputFormat {
+	case "yaml":
+		data, err := yaml.Marshal(&filter.Policy)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(&filter.Policy)
+	case "oci":
+		profile, err := seccomp.NewOCIProfile(filter)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(profile)
+	case "minijail":
+		return filter.Policy.WriteMinijailPolicy(w)
+	case "kafel":
+		return filter.Policy.WriteKafelPolicy(w)
+	case "pfc":
+		return filter.Policy.WritePFC(w)
+	default:
+		return fmt.Errorf("unknown output format %q", outputFormat)
+	}
+}
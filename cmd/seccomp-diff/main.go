@@ -0,0 +1,188 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-diff compares the decisions of two seccomp filters and
+// prints the syscalls that they handle differently, per architecture.
+//
+//	seccomp-diff [flags] a b
+//
+// Each operand is a policy file, raw:path for a raw filter as written by
+// seccomp-gen, or pid:N[:index] for a filter installed in a running process
+// (the most recently installed one by default), which requires
+// CAP_SYS_ADMIN. Policies are compiled for every architecture that is
+// compared. It exits with status 1 if the filters differ.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat string
+	archNames   string
+)
+
+// operand is a filter to compare.
+type operand struct {
+	name    string
+	program []bpf.Instruction // Nil for policies, which depend on the arch.
+}
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archNames, "arch", "", "comma separated architectures to compare, defaults to those checked by the filters or the native architecture")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-diff [flags] a b\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	a, err := load(flag.Arg(0))
+	if err != nil {
+		cli.Fatal(err)
+	}
+	b, err := load(flag.Arg(1))
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	arches, err := comparedArches(a, b)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	differ := false
+	for _, info := range arches {
+		progA, err := a.compile(info)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		progB, err := b.compile(info)
+		if err != nil {
+			cli.Fatal(err)
+		}
+
+		diffs, err := seccomp.DiffPrograms(progA, progB, info)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		for _, d := range diffs {
+			fmt.Println(d)
+		}
+		differ = differ || len(diffs) > 0
+	}
+	if differ {
+		os.Exit(1)
+	}
+}
+
+// load reads an operand. Policies are only read when they are compiled.
+func load(name string) (*operand, error) {
+	switch {
+	case strings.HasPrefix(name, "raw:"):
+		insts, err := cli.ReadRaw(strings.TrimPrefix(name, "raw:"))
+		if err != nil {
+			return nil, err
+		}
+		return &operand{name: name, program: insts}, nil
+	case strings.HasPrefix(name, "pid:"):
+		pidIndex := strings.SplitN(strings.TrimPrefix(name, "pid:"), ":", 2)
+		pid, err := strconv.Atoi(pidIndex[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid in %v: %w", name, err)
+		}
+		index := 0
+		if len(pidIndex) == 2 {
+			if index, err = strconv.Atoi(pidIndex[1]); err != nil {
+				return nil, fmt.Errorf("invalid index in %v: %w", name, err)
+			}
+		}
+
+		filters, err := seccomp.GetProcessFilters(pid)
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || index >= len(filters) {
+			return nil, fmt.Errorf("process %d has %d filters", pid, len(filters))
+		}
+		return &operand{name: name, program: filters[index].Instructions}, nil
+	default:
+		return &operand{name: name}, nil
+	}
+}
+
+// compile returns the program of the operand for the arch.
+func (o *operand) compile(info *arch.Info) ([]bpf.Instruction, error) {
+	if o.program != nil {
+		return o.program, nil
+	}
+	policy, err := cli.LoadPolicy(o.name, inputFormat, info.Name)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Assemble()
+}
+
+// comparedArches returns the arches of the -arch flag, the arches checked by
+// the programs, or the native arch.
+func comparedArches(a, b *operand) ([]*arch.Info, error) {
+	var arches []*arch.Info
+	if archNames != "" {
+		for _, name := range strings.Split(archNames, ",") {
+			info, err := arch.GetInfo(strings.TrimSpace(name))
+			if err != nil {
+				return nil, err
+			}
+			arches = append(arches, info)
+		}
+		return arches, nil
+	}
+
+	seen := map[*arch.Info]bool{}
+	for _, o := range []*operand{a, b} {
+		if o.program == nil {
+			continue
+		}
+		for _, info := range seccomp.ProgramArches(o.program) {
+			if !seen[info] {
+				seen[info] = true
+				arches = append(arches, info)
+			}
+		}
+	}
+	if len(arches) > 0 {
+		return arches, nil
+	}
+
+	info, err := arch.GetInfo("")
+	if err != nil {
+		return nil, err
+	}
+	return []*arch.Info{info}, nil
+}
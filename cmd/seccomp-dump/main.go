@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-dump prints the seccomp filters installed in a running
+// process, e.g. a container, for auditing.
+//
+//	seccomp-dump [flags] pid
+//	seccomp-dump [flags] -raw filter-file
+//
+// It attaches to the process with ptrace, which requires CAP_SYS_ADMIN, and
+// disassembles every filter with syscall names. With -decompile it writes
+// each filter as a policy in YAML instead, which can be used with the other
+// commands.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	archName  string
+	index     int
+	rawFile   string
+	decompile bool
+	outFile   string
+)
+
+func main() {
+	flag.StringVar(&archName, "arch", "", "architecture of the filters (e.g. x86_64 or aarch64), detected from the filters by default")
+	flag.IntVar(&index, "index", -1, "dump only the filter at this index (0 is the most recently installed)")
+	flag.StringVar(&rawFile, "raw", "", "read a raw filter (struct sock_filter array) from the file instead of a process")
+	flag.BoolVar(&decompile, "decompile", false, "write the filters as policies in YAML")
+	flag.StringVar(&outFile, "out", "-", "output file")
+	flag.Parse()
+
+	if (rawFile == "") != (flag.NArg() == 1) || flag.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-dump [flags] pid\n       seccomp-dump [flags] -raw filter-file\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	var info *arch.Info
+	if archName != "" {
+		var err error
+		if info, err = arch.GetInfo(archName); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	var filters []seccomp.InstalledFilter
+	source := rawFile
+	if rawFile != "" {
+		insts, err := cli.ReadRaw(rawFile)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		filters = []seccomp.InstalledFilter{{Instructions: insts}}
+	} else {
+		pid, err := strconv.Atoi(flag.Arg(0))
+		if err != nil {
+			cli.Fatal(fmt.Errorf("invalid pid: %w", err))
+		}
+		if filters, err = seccomp.GetProcessFilters(pid); err != nil {
+			cli.Fatal(err)
+		}
+		if len(filters) == 0 {
+			cli.Fatal(fmt.Errorf("process %d has no seccomp filters", pid))
+		}
+		source = "process " + flag.Arg(0)
+	}
+	if index >= 0 {
+		if index >= len(filters) {
+			cli.Fatal(fmt.Errorf("%v has %d filters", source, len(filters)))
+		}
+		filters = filters[index : index+1]
+	}
+
+	out := os.Stdout
+	if outFile != "-" {
+		var err error
+		if out, err = os.Create(outFile); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	bw := bufio.NewWriter(out)
+	for i, filter := range filters {
+		if i > 0 {
+			if decompile {
+				fmt.Fprintf(bw, "---\n")
+			} else {
+				fmt.Fprintf(bw, "\n")
+			}
+		}
+
+		var err error
+		if decompile {
+			err = writePolicy(bw, source, filter, info)
+		} else {
+			err = writeDisassembly(bw, filter, info)
+		}
+		if err != nil {
+			cli.Fatal(fmt.Errorf("filter %d: %w", filter.Index, err))
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		cli.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		cli.Fatal(err)
+	}
+}
+
+func writeDisassembly(w io.Writer, filter seccomp.InstalledFilter, info *arch.Info) error {
+	fmt.Fprintf(w, "# filter %d: %d instructions", filter.Index, len(filter.Instructions))
+	if filter.Flags != 0 {
+		fmt.Fprintf(w, ", flags %v", filter.Flags)
+	}
+	fmt.Fprintf(w, "\n")
+	return seccomp.WriteDisassembly(w, filter.Instructions, info)
+}
+
+func writePolicy(w io.Writer, source string, filter seccomp.InstalledFilter, info *arch.Info) error {
+	d, err := seccomp.Decompile(filter.Instructions, info)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(d.Policy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "# Filter %d of %v for %v.\n", filter.Index, source, d.Policy.Arch().Name)
+	if filter.Flags != 0 {
+		fmt.Fprintf(w, "# Installed with flags %v.\n", filter.Flags)
+	}
+	if len(d.Unresolved) > 0 {
+		fmt.Fprintf(w, "# The checks of these syscalls cannot be expressed as conditions and\n")
+		fmt.Fprintf(w, "# the default action is applied to them: %v\n", strings.Join(d.Unresolved, ", "))
+		fmt.Fprintf(os.Stderr, "warning: filter %d: unresolved syscalls: %v\n", filter.Index, strings.Join(d.Unresolved, ", "))
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,232 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-explain shows why a policy denies a syscall and suggests
+// the smallest change to the policy that allows it.
+//
+//	seccomp-explain [flags] policy-file syscall [arg...]
+//	seccomp-explain [flags] -audit record policy-file [arg...]
+//
+// The syscall is a name or a number and the arguments are numbers in Go
+// syntax. With -audit the syscall is taken from a SECCOMP audit record as
+// written to the audit log or to dmesg, or from the first record on stdin if
+// the record is "-". Audit records do not contain the arguments, they are
+// zero unless given on the command line.
+//
+// The explanation names the rule that denied the syscall, or the rules with
+// argument conditions that did not match if the default action applied. The
+// suggested change is checked by evaluating the changed policy again, and
+// -out writes the changed policy.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat string
+	archName    string
+	dataArch    string
+	auditRecord string
+	outFile     string
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archName, "arch", "", "policy architecture (e.g. x86_64 or aarch64), defaults to the native architecture")
+	flag.StringVar(&dataArch, "syscall-arch", "", "architecture of the syscall (e.g. i386 or x32), defaults to the policy architecture")
+	flag.StringVar(&auditRecord, "audit", "", "SECCOMP audit or dmesg record of the denied syscall, - reads it from stdin")
+	flag.StringVar(&outFile, "out", "", "write the changed policy as YAML to this file")
+	flag.Parse()
+
+	minArgs := 2
+	if auditRecord != "" {
+		minArgs = 1
+	}
+	if flag.NArg() < minArgs {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-explain [flags] policy-file syscall [arg...]\n")
+		fmt.Fprintf(os.Stderr, "       seccomp-explain [flags] -audit record policy-file [arg...]\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	filter, err := cli.LoadFilter(flag.Arg(0), inputFormat, archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	policy := &filter.Policy
+	sim, err := seccomp.NewSimulator(policy)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	info := policy.Arch()
+	if dataArch != "" {
+		if info, err = arch.GetInfo(dataArch); err != nil {
+			cli.Fatal(err)
+		}
+	}
+	var data seccomp.SeccompData
+	var record *seccomp.AuditRecord
+	if auditRecord != "" {
+		if record, err = readRecord(auditRecord); err != nil {
+			cli.Fatal(err)
+		}
+		if record.Arch == nil || record.Syscall == "" {
+			cli.Fatal(errors.New("the syscall of the audit record is unknown"))
+		}
+		info = record.Arch
+		data, err = cli.SeccompData(info, record.Syscall, flag.Args()[1:])
+	} else {
+		data, err = cli.SeccompData(info, flag.Arg(1), flag.Args()[2:])
+	}
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	result, err := sim.Run(data)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	name := result.Syscall
+	if name == "" {
+		if name = info.SyscallName(int(data.NR &^ int32(info.SeccompMask))); name == "" {
+			name = "unknown"
+		}
+	}
+	fmt.Printf("syscall: %s (%d) on %v\n", name, data.NR, info.Name)
+	fmt.Printf("args:    %#x\n", data.Args)
+	fmt.Printf("action:  %v\n", result.Action)
+	fmt.Printf("reason:  %s\n", result.Reason)
+	for _, missed := range result.Missed {
+		fmt.Printf("missed:  rule %d (%v) requires %s, but %s\n", missed.Group,
+			policy.Syscalls[missed.Group].Action, formatConditions(missed.Conditions, " && "),
+			failures(missed.Failed, data.Args))
+	}
+	if record != nil {
+		if record.Code != 0 && record.Code != result.Action {
+			fmt.Fprintf(os.Stderr, "warning: the audit record reports %v, the policy may not be the filter that was installed\n", record.Code)
+		}
+		if flag.NArg() == 1 && hasConditions(policy, result.Syscall) {
+			fmt.Fprintln(os.Stderr, "warning: the audit record has no arguments, pass them on the command line to evaluate the argument conditions")
+		}
+	}
+	if result.Action != result.Intent {
+		fmt.Fprintf(os.Stderr, "warning: the policy intends %v but the filter returns %v\n", result.Intent, result.Action)
+	}
+
+	if permitted(result.Action) {
+		fmt.Println("change:  none, the policy permits the syscall")
+		return
+	}
+	c, err := suggest(policy, result)
+	if err != nil {
+		fmt.Printf("change:  none, %v\n", err)
+		os.Exit(1)
+	}
+
+	verify, err := seccomp.NewSimulator(&c.policy)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	after, err := verify.Run(data)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	fmt.Printf("change:  %s\n", strings.Join(c.steps, ", then "))
+	if !permitted(after.Action) {
+		fmt.Printf("result:  %v, the change is not sufficient\n", after.Action)
+		os.Exit(1)
+	}
+	fmt.Printf("result:  %v\n", after.Action)
+	for _, i := range c.groups {
+		data, err := yaml.Marshal([]seccomp.SyscallGroup{c.policy.Syscalls[i]})
+		if err != nil {
+			cli.Fatal(err)
+		}
+		fmt.Printf("\n# new rule %d\n%s", i, data)
+	}
+
+	if outFile != "" {
+		// Drop the rules that the change left empty.
+		groups := c.policy.Syscalls[:0:0]
+		for _, g := range c.policy.Syscalls {
+			if len(g.Names) > 0 || len(g.NamesWithCondtions) > 0 {
+				groups = append(groups, g)
+			}
+		}
+		c.policy.Syscalls = groups
+		data, err := yaml.Marshal(&c.policy)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		if err := os.WriteFile(outFile, data, 0o644); err != nil {
+			cli.Fatal(err)
+		}
+	}
+}
+
+// readRecord parses the record, or the first SECCOMP record on stdin.
+func readRecord(line string) (*seccomp.AuditRecord, error) {
+	if line != "-" {
+		record, ok, err := seccomp.ParseAuditRecord(line)
+		if err == nil && !ok {
+			err = errors.New("not a SECCOMP audit record")
+		}
+		return record, err
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if record, ok, err := seccomp.ParseAuditRecord(scanner.Text()); ok {
+			return record, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("no SECCOMP audit record on stdin")
+}
+
+// permitted returns true if the action lets the syscall execute without
+// involving a tracer or a supervisor.
+func permitted(action seccomp.Action) bool {
+	return action == seccomp.ActionAllow || action == seccomp.ActionLog
+}
+
+func hasConditions(p *seccomp.Policy, name string) bool {
+	for _, group := range p.Syscalls {
+		for _, s := range group.NamesWithCondtions {
+			if s.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// change is a change to a policy.
+type change struct {
+	policy seccomp.Policy
+	steps  []string // Descriptions of the steps of the change.
+	groups []int    // Indexes of the inserted groups.
+}
+
+// suggest returns the smallest change that allows the syscall, which the
+// policy denies. Rules that deny the syscall are only narrowed: an exception
+// for the exact arguments is put in front of a rule with conditions, and the
+// syscall is removed from a rule without conditions.
+func suggest(p *seccomp.Policy, result *seccomp.Simulation) (*change, error) {
+	info := p.Arch()
+	switch {
+	case result.Data.Arch != uint32(info.ID):
+		return nil, fmt.Errorf("the policy only has rules for %v, load a policy for %v", info.Name, arch.AuditArch(result.Data.Arch))
+	case result.Syscall == "":
+		return nil, fmt.Errorf("syscall %d is unknown on %v", result.Data.NR, info.Name)
+	case info.ID == arch.X86_64.ID && result.Data.NR&int32(arch.X32.SeccompMask) != 0:
+		return nil, fmt.Errorf("the filter rejects all x32 syscalls")
+	}
+
+	name, args := result.Syscall, result.Data.Args
+	c := &change{policy: *p}
+	c.policy.Syscalls = cloneGroups(p.Syscalls)
+	group := result.Group
+	if group >= 0 {
+		if len(result.Conditions) > 0 {
+			exception := seccomp.SyscallGroup{
+				Action: seccomp.ActionAllow,
+				NamesWithCondtions: []seccomp.NameWithConditions{
+					{Name: name, Conditions: exactly(result.Conditions, args)},
+				},
+			}
+			c.insert(group, exception)
+			c.steps = append(c.steps, fmt.Sprintf("allow %s if %s in a new rule before rule %d",
+				name, formatConditions(exception.NamesWithCondtions[0].Conditions, " && "), group))
+			return c, nil
+		}
+
+		g := &c.policy.Syscalls[group]
+		g.Names = remove(g.Names, name)
+		c.steps = append(c.steps, fmt.Sprintf("remove %s from rule %d (%v)", name, group, g.Action))
+		if !c.deniedByDefault(result.Data) {
+			return c, nil
+		}
+	}
+
+	// The syscall gets the default action. Add an exact match to a permitting
+	// rule whose conditions missed, or else allow the syscall.
+	for _, missed := range result.Missed {
+		if missed.Group >= len(c.policy.Syscalls) || !permitted(c.policy.Syscalls[missed.Group].Action) {
+			continue
+		}
+		g := &c.policy.Syscalls[missed.Group]
+		conditions := relax(missed, args)
+		g.NamesWithCondtions = append(g.NamesWithCondtions, seccomp.NameWithConditions{Name: name, Conditions: conditions})
+		c.steps = append(c.steps, fmt.Sprintf("also allow %s if %s in rule %d (%v)",
+			name, formatConditions(conditions, " && "), missed.Group, g.Action))
+		return c, nil
+	}
+	for i := range c.policy.Syscalls {
+		if g := &c.policy.Syscalls[i]; g.Action == seccomp.ActionAllow {
+			g.Names = append(g.Names, name)
+			c.steps = append(c.steps, fmt.Sprintf("add %s to rule %d (%v)", name, i, g.Action))
+			return c, nil
+		}
+	}
+	c.insert(len(c.policy.Syscalls), seccomp.SyscallGroup{Action: seccomp.ActionAllow, Names: []string{name}})
+	c.steps = append(c.steps, fmt.Sprintf("allow %s in a new rule %d", name, len(c.policy.Syscalls)-1))
+	return c, nil
+}
+
+// deniedByDefault returns true if the changed policy still denies the
+// syscall.
+func (c *change) deniedByDefault(data seccomp.SeccompData) bool {
+	sim, err := seccomp.NewSimulator(&c.policy)
+	if err != nil {
+		return true
+	}
+	result, err := sim.Run(data)
+	return err != nil || !permitted(result.Action)
+}
+
+// insert inserts the group at the index.
+func (c *change) insert(i int, group seccomp.SyscallGroup) {
+	groups := append(c.policy.Syscalls[:i:i], group)
+	c.policy.Syscalls = append(groups, c.policy.Syscalls[i:]...)
+	for j := range c.groups {
+		if c.groups[j] >= i {
+			c.groups[j]++
+		}
+	}
+	c.groups = append(c.groups, i)
+}
+
+// exactly returns conditions that only match the arguments that the
+// conditions test.
+func exactly(conditions seccomp.ArgumentConditions, args [6]uint64) seccomp.ArgumentConditions {
+	var exact seccomp.ArgumentConditions
+	seen := map[uint32]bool{}
+	for _, c := range conditions {
+		if !seen[c.Argument] {
+			seen[c.Argument] = true
+			exact = append(exact, seccomp.Condition{Argument: c.Argument, Operation: seccomp.Equal, Value: args[c.Argument]})
+		}
+	}
+	return exact
+}
+
+// relax returns the conditions of the missed rule with the failed conditions
+// replaced by exact matches of the arguments.
+func relax(missed seccomp.MissedRule, args [6]uint64) seccomp.ArgumentConditions {
+	failed := map[uint32]bool{}
+	for _, c := range missed.Failed {
+		failed[c.Argument] = true
+	}
+	var conditions seccomp.ArgumentConditions
+	for _, c := range missed.Conditions {
+		if !failed[c.Argument] {
+			conditions = append(conditions, c)
+		} else if exact := exactly(seccomp.ArgumentConditions{c}, args); !containsCondition(conditions, exact[0]) {
+			conditions = append(conditions, exact[0])
+		}
+	}
+	return conditions
+}
+
+func containsCondition(conditions seccomp.ArgumentConditions, c seccomp.Condition) bool {
+	for _, other := range conditions {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneGroups(groups []seccomp.SyscallGroup) []seccomp.SyscallGroup {
+	clone := make([]seccomp.SyscallGroup, len(groups))
+	for i, g := range groups {
+		g.Names = append([]string(nil), g.Names...)
+		g.NamesWithCondtions = append([]seccomp.NameWithConditions(nil), g.NamesWithCondtions...)
+		clone[i] = g
+	}
+	return clone
+}
+
+func remove(names []string, name string) []string {
+	kept := names[:0]
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+var operators = map[seccomp.Operation]string{
+	seccomp.Equal:          "==",
+	seccomp.NotEqual:       "!=",
+	seccomp.GreaterThan:    ">",
+	seccomp.LessThan:       "<",
+	seccomp.GreaterOrEqual: ">=",
+	seccomp.LessOrEqual:    "<=",
+}
+
+// formatConditions formats conditions such as "arg1 & 0x80000 == 0".
+func formatConditions(conditions seccomp.ArgumentConditions, sep string) string {
+	parts := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		switch c.Operation {
+		case seccomp.BitsSet:
+			parts = append(parts, fmt.Sprintf("arg%d & %#x != 0", c.Argument, c.Value))
+		case seccomp.BitsNotSet:
+			parts = append(parts, fmt.Sprintf("arg%d & %#x == 0", c.Argument, c.Value))
+		default:
+			parts = append(parts, fmt.Sprintf("arg%d %s %#x", c.Argument, operators[c.Operation], c.Value))
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// failures describes the values of the arguments of the failed conditions.
+func failures(failed seccomp.ArgumentConditions, args [6]uint64) string {
+	parts := make([]string, 0, len(failed))
+	for _, c := range failed {
+		parts = append(parts, fmt.Sprintf("arg%d is %#x", c.Argument, args[c.Argument]))
+	}
+	return strings.Join(parts, " and ")
+}
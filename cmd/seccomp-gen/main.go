@@ -0,0 +1,254 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-gen compiles a policy file into a seccomp BPF filter for
+// use outside of Go programs.
+//
+//	seccomp-gen [flags] policy-file
+//
+// The output format is one of
+//
+//	raw   struct sock_filter array as bytes, e.g. for bwrap --seccomp
+//	c     C header defining a struct sock_filter array and sock_fprog
+//	pfc   libseccomp pseudo filter code for review
+//	oci   OCI runtime profile in JSON (Docker, Podman, Kubernetes)
+//	go    Go source declaring a seccomp.Filter with the compiled program
+//
+// The go format compiles the policy at build time, so the program does not
+// assemble it at startup and installs exactly the reviewed filter:
+//
+//	//go:generate go run github.com/elastic/go-seccomp-bpf/cmd/seccomp-gen -o go -arch x86_64 -out filter_amd64.go policy.yml
+//
+// The package of the generated file defaults to $GOPACKAGE, which is set by
+// go generate.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat  string
+	outputFormat string
+	archName     string
+	outFile      string
+	name         string
+	pkgName      string
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&outputFormat, "o", "raw", "output format (raw, c, pfc, oci, or go)")
+	flag.StringVar(&archName, "arch", "", "target architecture (e.g. x86_64 or aarch64), defaults to the native architecture")
+	flag.StringVar(&outFile, "out", "-", "output file")
+	flag.StringVar(&name, "name", "", "name of the C variables (default seccomp_filter) or of the Go variable (default Filter)")
+	flag.StringVar(&pkgName, "package", os.Getenv("GOPACKAGE"), "package of the Go source, defaults to $GOPACKAGE or main")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-gen [flags] policy-file\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	filter, err := cli.LoadFilter(flag.Arg(0), inputFormat, archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	out := os.Stdout
+	if outFile != "-" {
+		if out, err = os.Create(outFile); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	if err = generate(out, filter); err != nil {
+		cli.Fatal(err)
+	}
+	if err = out.Close(); err != nil {
+		cli.Fatal(err)
+	}
+}
+
+func generate(w io.Writer, filter *seccomp.Filter) error {
+	switch outputFormat {
+	case "raw":
+		insts, err := filter.Policy.Assemble()
+		if err != nil {
+			return err
+		}
+		return writeRaw(w, insts)
+	case "c":
+		insts, err := filter.Policy.Assemble()
+		if err != nil {
+			return err
+		}
+		return writeC(w, filter, insts)
+	case "go":
+		return writeGo(w, filter)
+	case "pfc":
+		return filter.Policy.WritePFC(w)
+	case "oci":
+		profile, err := seccomp.NewOCIProfile(filter)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(profile)
+	default:
+		return fmt.Errorf("unknown output format %q", outputFormat)
+	}
+}
+
+// writeRaw writes the instructions as an array of struct sock_filter. All
+// supported architectures are little endian.
+func writeRaw(w io.Writer, insts []bpf.Instruction) error {
+	raw, err := bpf.Assemble(insts)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 0, 8*len(raw))
+	for _, ins := range raw {
+		buf = binary.LittleEndian.AppendUint16(buf, ins.Op)
+		buf = append(buf, ins.Jt, ins.Jf)
+		buf = binary.LittleEndian.AppendUint32(buf, ins.K)
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// writeC writes a C header that defines the filter.
+func writeC(w io.Writer, filter *seccomp.Filter, insts []bpf.Instruction) error {
+	raw, err := bpf.Assemble(insts)
+	if err != nil {
+		return err
+	}
+	ident := cIdentifier(name)
+	if name == "" {
+		ident = "seccomp_filter"
+	}
+	guard := strings.ToUpper(ident) + "_H"
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "/* Code generated by seccomp-gen - DO NOT EDIT. */\n\n")
+	fmt.Fprintf(bw, "/*\n * Architecture: %s\n * Default action: %v\n * No new privs: %v\n",
+		filter.Policy.Arch().Name, filter.Policy.DefaultAction, filter.NoNewPrivs)
+	if filter.Flag != 0 {
+		fmt.Fprintf(bw, " * Filter flags: %v\n", filter.Flag)
+	}
+	fmt.Fprintf(bw, " */\n\n")
+	fmt.Fprintf(bw, "#ifndef %s\n#define %s\n\n", guard, guard)
+	fmt.Fprintf(bw, "#include <linux/filter.h>\n\n")
+	fmt.Fprintf(bw, "static struct sock_filter %s_insns[] = {\n", ident)
+	for i, ins := range raw {
+		fmt.Fprintf(bw, "\t{ 0x%02x, %d, %d, 0x%08x }, /* %d: %v */\n", ins.Op, ins.Jt, ins.Jf, ins.K, i, insts[i])
+	}
+	fmt.Fprintf(bw, "};\n\n")
+	fmt.Fprintf(bw, "static const struct sock_fprog %s = {\n", ident)
+	fmt.Fprintf(bw, "\t.len = sizeof(%s_insns) / sizeof(%s_insns[0]),\n", ident, ident)
+	fmt.Fprintf(bw, "\t.filter = %s_insns,\n", ident)
+	fmt.Fprintf(bw, "};\n\n#endif /* %s */\n", guard)
+	return bw.Flush()
+}
+
+// writeGo writes Go source that declares the filter with its compiled
+// program. The bytes of every instruction are annotated with its
+// disassembly.
+func writeGo(w io.Writer, filter *seccomp.Filter) error {
+	compiled, err := seccomp.CompileProgram(&filter.Policy)
+	if err != nil {
+		return err
+	}
+	insts, err := compiled.Instructions()
+	if err != nil {
+		return err
+	}
+	ident, pkg := name, pkgName
+	if ident == "" {
+		ident = "Filter"
+	}
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by seccomp-gen - DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import seccomp %q\n\n", "github.com/elastic/go-seccomp-bpf")
+	fmt.Fprintf(&b, "// %s is the seccomp filter compiled from %s.\n//\n", ident, filepath.Base(flag.Arg(0)))
+	fmt.Fprintf(&b, "//\tArchitecture: %s\n//\tDefault action: %v\n", compiled.Arch, filter.Policy.DefaultAction)
+	fmt.Fprintf(&b, "var %s = seccomp.Filter{\n", ident)
+	fmt.Fprintf(&b, "NoNewPrivs: %v,\n", filter.NoNewPrivs)
+	if filter.Flag != 0 {
+		fmt.Fprintf(&b, "Flag: seccomp.FilterFlag(%#x), // %v\n", uint32(filter.Flag), filter.Flag)
+	}
+	if filter.SkipNoNewPrivsIfPrivileged {
+		fmt.Fprintf(&b, "SkipNoNewPrivsIfPrivileged: true,\n")
+	}
+	if filter.RequireSingleThread {
+		fmt.Fprintf(&b, "RequireSingleThread: true,\n")
+	}
+	fmt.Fprintf(&b, "Compiled: &seccomp.CompiledProgram{\n")
+	fmt.Fprintf(&b, "Arch: %q,\n", compiled.Arch)
+	fmt.Fprintf(&b, "Raw: []byte{\n")
+	for i, inst := range insts {
+		for _, c := range compiled.Raw[8*i : 8*i+8] {
+			fmt.Fprintf(&b, "0x%02x, ", c)
+		}
+		fmt.Fprintf(&b, "// %d: %v\n", i, inst)
+	}
+	fmt.Fprintf(&b, "},\n},\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format Go source: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// cIdentifier replaces the characters that are not valid in C identifiers.
+func cIdentifier(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, s)
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "_" + s
+	}
+	return s
+}
@@ -0,0 +1,209 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-minimize removes the syscalls that a policy permits but
+// that were never observed in one or more corpora, and reports them.
+//
+//	seccomp-minimize [flags] policy-file corpus...
+//
+// A corpus is one of:
+//
+//	strace:path   output of strace (optionally with -f and timestamps)
+//	audit:path    audit log or ausearch -m SECCOMP output
+//	profile:path  syscall profile with one "<syscall> <count>" per line
+//	path          policy with a profile section (e.g. from seccomp-trace)
+//
+// The minimized policy is written as YAML. Rules that deny syscalls are kept,
+// so the result never permits more than the input.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat string
+	archName    string
+	keepList    string
+	keepFile    string
+	auditExe    string
+	outFile     string
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archName, "arch", "", "policy architecture (e.g. x86_64 or aarch64), defaults to the native architecture")
+	flag.StringVar(&keepList, "keep", "", "comma separated syscalls to keep even if they were not observed")
+	flag.StringVar(&keepFile, "keep-file", "", "file with syscalls to keep, one per line ('#' starts a comment)")
+	flag.StringVar(&auditExe, "exe", "", "only use the audit records of this executable")
+	flag.StringVar(&outFile, "out", "-", "output file")
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-minimize [flags] policy-file corpus...\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	policy, err := cli.LoadPolicy(flag.Arg(0), inputFormat, archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	trace := seccomp.NewSyscallTrace()
+	for _, corpus := range flag.Args()[1:] {
+		t, err := readCorpus(corpus)
+		if err != nil {
+			cli.Fatal(fmt.Errorf("failed to read corpus %v: %w", corpus, err))
+		}
+		trace.Merge(t)
+	}
+	keep, err := keepSyscalls()
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	removed := policy.Minimize(trace, keep...)
+	if err = policy.Validate(); err != nil {
+		cli.Fatal(fmt.Errorf("minimized policy is invalid: %w", err))
+	}
+	if err = writePolicy(policy); err != nil {
+		cli.Fatal(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "observed %d syscalls in %d corpora\n", len(trace.Names()), flag.NArg()-1)
+	switch policy.DefaultAction {
+	case seccomp.ActionAllow, seccomp.ActionLog:
+		fmt.Fprintf(os.Stderr, "warning: the default action %v permits the syscalls that were not observed\n", policy.DefaultAction)
+	}
+	if len(removed) == 0 {
+		fmt.Fprintln(os.Stderr, "removed no syscalls")
+		return
+	}
+	fmt.Fprintf(os.Stderr, "removed %d syscalls: %v\n", len(removed), strings.Join(removed, ", "))
+}
+
+// readCorpus reads the syscalls observed in a corpus.
+func readCorpus(corpus string) (*seccomp.SyscallTrace, error) {
+	kind, path, found := strings.Cut(corpus, ":")
+	if !found {
+		kind, path = "", corpus
+	}
+
+	switch kind {
+	case "strace":
+		return readFile(path, seccomp.ReadStrace)
+	case "audit":
+		return readFile(path, func(r io.Reader) (*seccomp.SyscallTrace, error) {
+			return seccomp.ReadAuditLog(r, seccomp.AuditOptions{Arch: archName, Exe: auditExe})
+		})
+	case "profile":
+		return readFile(path, func(r io.Reader) (*seccomp.SyscallTrace, error) {
+			profile, err := seccomp.ReadSyscallProfile(r)
+			if err != nil {
+				return nil, err
+			}
+			return traceOf(profile), nil
+		})
+	case "":
+		policy, err := cli.LoadPolicy(path, "", archName)
+		if err != nil {
+			return nil, err
+		}
+		if len(policy.Profile) == 0 {
+			return nil, errors.New("the policy has no profile")
+		}
+		return traceOf(policy.Profile), nil
+	default:
+		return nil, fmt.Errorf("unknown corpus type %q, must be strace, audit, or profile", kind)
+	}
+}
+
+func readFile(path string, read func(io.Reader) (*seccomp.SyscallTrace, error)) (*seccomp.SyscallTrace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return read(f)
+}
+
+// traceOf returns a trace with the calls counted by the profile.
+func traceOf(profile seccomp.SyscallProfile) *seccomp.SyscallTrace {
+	trace := seccomp.NewSyscallTrace()
+	for name, n := range profile {
+		trace.AddCount(name, n)
+	}
+	return trace
+}
+
+// keepSyscalls returns the syscalls of -keep and -keep-file.
+func keepSyscalls() ([]string, error) {
+	var keep []string
+	for _, name := range strings.Split(keepList, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			keep = append(keep, name)
+		}
+	}
+	if keepFile == "" {
+		return keep, nil
+	}
+
+	f, err := os.Open(keepFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			keep = append(keep, line)
+		}
+	}
+	return keep, s.Err()
+}
+
+func writePolicy(policy *seccomp.Policy) error {
+	data, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if outFile != "-" {
+		if out, err = os.Create(outFile); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "# %v minimized to the syscalls observed in %v.\n", flag.Arg(0), flag.Args()[1:])
+	if _, err = out.Write(data); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,222 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// config is the rules file of the daemon.
+type config struct {
+	// Rules select the handlers by the UID or cgroup of the notifying
+	// process. They are evaluated in order and the first match is used.
+	Rules []ruleConfig `yaml:"rules"`
+
+	// Default answers the notifications of processes that match no rule.
+	Default string `yaml:"default"`
+}
+
+// ruleConfig is a notify.DispatchRule with the handlers for its processes.
+type ruleConfig struct {
+	Name   string   `yaml:"name"`
+	UIDs   []uint32 `yaml:"uids"`
+	Cgroup string   `yaml:"cgroup"`
+
+	Open    *openConfig    `yaml:"open"`
+	Exec    *execConfig    `yaml:"exec"`
+	Connect *connectConfig `yaml:"connect"`
+	Legacy  []string       `yaml:"legacy"`
+
+	Continue []string          `yaml:"continue"` // Syscalls that continue in the target.
+	Deny     map[string]string `yaml:"deny"`     // Syscalls that fail, with their errno.
+
+	Faults []faultConfig  `yaml:"faults"`
+	Delays []notify.Delay `yaml:"delays"`
+
+	// Default answers the syscalls without a handler in this rule.
+	Default string `yaml:"default"`
+}
+
+type openConfig struct {
+	Paths []notify.PathRule `yaml:"paths"`
+	Errno string            `yaml:"errno"`
+}
+
+type execConfig struct {
+	Binaries []notify.ExecRule `yaml:"binaries"`
+	Errno    string            `yaml:"errno"`
+}
+
+type connectConfig struct {
+	Destinations []notify.NetRule `yaml:"destinations"`
+	Proxy        bool             `yaml:"proxy"`
+	Errno        string           `yaml:"errno"`
+}
+
+type faultConfig struct {
+	Syscall     string  `yaml:"syscall"`
+	Errno       string  `yaml:"errno"`
+	Probability float64 `yaml:"probability"`
+	Every       int     `yaml:"every"`
+	After       int     `yaml:"after"`
+}
+
+// loadConfig reads the rules file and builds the handler for all containers.
+func loadConfig(path string) (notify.Handler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c config
+	if err = yaml.UnmarshalStrict(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid config %v: %w", path, err)
+	}
+
+	d := &notify.Dispatcher{}
+	if d.Default, err = fixedHandler(c.Default); err != nil {
+		return nil, fmt.Errorf("invalid default: %w", err)
+	}
+	for i, rule := range c.Rules {
+		mux, err := rule.mux()
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("invalid rule %v: %w", name, err)
+		}
+		d.Rules = append(d.Rules, notify.DispatchRule{UIDs: rule.UIDs, Cgroup: rule.Cgroup, Handlers: mux})
+	}
+	return d, nil
+}
+
+// mux builds the handlers of the rule. Faults and delays wrap the handler
+// of their syscall, so they can be combined with the brokers.
+func (r *ruleConfig) mux() (*notify.Mux, error) {
+	handlers := map[string]notify.Handler{}
+	handle := func(h notify.Handler, names ...string) {
+		for _, name := range names {
+			handlers[name] = h
+		}
+	}
+
+	if r.Open != nil {
+		errno, err := parseErrno(r.Open.Errno)
+		if err != nil {
+			return nil, err
+		}
+		handle(&notify.OpenBroker{Rules: r.Open.Paths, Errno: errno}, notify.OpenSyscalls...)
+	}
+	if r.Exec != nil {
+		errno, err := parseErrno(r.Exec.Errno)
+		if err != nil {
+			return nil, err
+		}
+		handle(&notify.ExecBroker{Rules: r.Exec.Binaries, Errno: errno}, notify.ExecSyscalls...)
+	}
+	if r.Connect != nil {
+		errno, err := parseErrno(r.Connect.Errno)
+		if err != nil {
+			return nil, err
+		}
+		handle(&notify.ConnectBroker{Rules: r.Connect.Destinations, Proxy: r.Connect.Proxy, Errno: errno}, notify.ConnectSyscalls...)
+	}
+	if len(r.Legacy) > 0 {
+		handle(&notify.LegacyEmulator{Syscalls: r.Legacy}, r.Legacy...)
+	}
+
+	cont, _ := fixedHandler("continue")
+	handle(cont, r.Continue...)
+	for name, errno := range r.Deny {
+		h, err := fixedHandler(errno)
+		if err != nil {
+			return nil, fmt.Errorf("invalid errno for %v: %w", name, err)
+		}
+		handle(h, name)
+	}
+
+	for _, d := range r.Delays {
+		handle(&notify.LatencyInjector{Delays: []notify.Delay{d}, Next: handlers[d.Syscall]}, d.Syscall)
+	}
+	for _, f := range r.Faults {
+		errno, err := parseErrno(f.Errno)
+		if err != nil {
+			return nil, err
+		}
+		if errno == 0 {
+			return nil, fmt.Errorf("fault for %v has no errno", f.Syscall)
+		}
+		fault := notify.Fault{Syscall: f.Syscall, Errno: errno, Probability: f.Probability, Every: f.Every, After: f.After}
+		handle(&notify.FaultInjector{Faults: []notify.Fault{fault}, Next: handlers[f.Syscall]}, f.Syscall)
+	}
+
+	mux := notify.NewMux()
+	for name, h := range handlers {
+		mux.Handle(name, h)
+	}
+	var err error
+	if mux.Default, err = fixedHandler(r.Default); err != nil {
+		return nil, fmt.Errorf("invalid default: %w", err)
+	}
+	return mux, nil
+}
+
+// fixedHandler returns a handler that lets every syscall continue for
+// "continue" or fails it with the given errno. It returns nil for an empty
+// string, so that the error policy of the supervisor applies.
+func fixedHandler(s string) (notify.Handler, error) {
+	switch s {
+	case "":
+		return nil, nil
+	case "continue":
+		return notify.HandlerFunc(func(req *notify.Request) (*notify.Response, error) {
+			return notify.ContinueUnsafe(req.Notification), nil
+		}), nil
+	}
+	errno, err := parseErrno(s)
+	if err != nil {
+		return nil, err
+	}
+	return notify.HandlerFunc(func(req *notify.Request) (*notify.Response, error) {
+		return notify.ReturnErrno(req.Notification, int(errno)), nil
+	}), nil
+}
+
+// parseErrno parses an errno name (e.g. EACCES) or number. An empty string
+// is zero, which selects the default errno of the brokers.
+func parseErrno(s string) (syscall.Errno, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var action seccomp.Action
+	if err := action.Unpack("errno(" + s + ")"); err != nil {
+		return 0, fmt.Errorf("invalid errno %q", s)
+	}
+	return syscall.Errno(action & 0xffff), nil
+}
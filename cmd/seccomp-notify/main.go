@@ -0,0 +1,234 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+// Command seccomp-notify is a seccomp agent daemon that answers the user
+// notifications of containers. OCI runtimes such as runc and crun send it the
+// listener of a container when linux.seccomp.listenerPath in the container
+// configuration is set to its socket:
+//
+//	seccomp-notify -socket /run/seccomp-notify.sock -config rules.yml
+//
+// The rules file selects brokers and fixed answers per syscall for the
+// processes matching a UID or cgroup:
+//
+//	rules:
+//	- name: web
+//	  cgroup: /kubepods
+//	  open:
+//	    paths: [{path: /data, write: true}]
+//	  connect:
+//	    destinations: [{prefix: 10.0.0.0/8, ports: [443]}]
+//	    proxy: true
+//	  exec:
+//	    binaries: [{path: /usr/bin/curl}]
+//	  continue: [getpid]
+//	  deny: {mount: EPERM}
+//	  faults: [{syscall: write, errno: EIO, probability: 0.01}]
+//	  delays: [{syscall: fsync, duration: 10ms}]
+//	  default: EPERM
+//	default: EPERM
+//
+// The rules file is reloaded when it changes or on SIGHUP. Every decision is
+// logged at debug level and counted in the metrics served on -metrics-addr.
+// -log-rate and -log-sample limit the decisions that are logged per syscall
+// so that a container calling a denied syscall in a loop cannot flood the
+// log, without affecting the metrics. -corpus appends the seccomp_data of
+// every notification to a file that seccomp.ReplayCorpus replays against a
+// changed policy.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+var (
+	socketPath  string
+	configPath  string
+	metricsAddr string
+	logLevel    slog.Level
+	logJSON     bool
+	workers     int
+	failOpen    bool
+	logLimit    seccomp.LogLimit
+	corpusPath  string
+)
+
+func main() {
+	flag.StringVar(&socketPath, "socket", "/run/seccomp-notify.sock", "unix socket that OCI runtimes send listeners to")
+	flag.StringVar(&configPath, "config", "", "rules file (required)")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "address to serve metrics on at /metrics (e.g. localhost:9090)")
+	flag.TextVar(&logLevel, "log-level", slog.LevelDebug, "log level, decisions are logged at debug")
+	flag.BoolVar(&logJSON, "log-json", false, "log in JSON instead of text")
+	flag.IntVar(&workers, "workers", notify.DefaultWorkers, "notifications handled concurrently per container")
+	flag.BoolVar(&failOpen, "fail-open", false, "let syscalls continue instead of failing with EPERM if a handler fails")
+	flag.Float64Var(&logLimit.Rate, "log-rate", 0, "decisions logged per second per syscall and container (0 for no limit)")
+	flag.IntVar(&logLimit.Burst, "log-burst", 10, "decisions logged at once per syscall and container before -log-rate applies")
+	flag.IntVar(&logLimit.Sample, "log-sample", 0, "log one of every N decisions per syscall and container")
+	flag.StringVar(&corpusPath, "corpus", "", "file that the seccomp_data of all notifications is appended to")
+	flag.Parse()
+
+	if configPath == "" || flag.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-notify [flags] -config rules-file\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	opts := &slog.HandlerOptions{Level: logLevel}
+	var logger *slog.Logger
+	if logJSON {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	} else {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, opts))
+	}
+
+	if err := run(logger); err != nil {
+		cli.Fatal(err)
+	}
+}
+
+func run(logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	load := func() (notify.Handler, error) { return loadConfig(configPath) }
+	h, err := load()
+	if err != nil {
+		return err
+	}
+	handlers := notify.NewReloadable(h)
+	onError := func(err error) { logger.Error("keeping the current rules", "error", err) }
+	go handlers.ReloadOnSignal(ctx, load, onError)
+	go func() {
+		if err := handlers.WatchFile(ctx, configPath, load, onError); err != nil {
+			logger.Warn("rules are only reloaded on SIGHUP", "error", err)
+		}
+	}()
+
+	var corpus *seccomp.CorpusWriter
+	if corpusPath != "" {
+		f, err := os.OpenFile(corpusPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		corpus = seccomp.NewCorpusWriter(f)
+	}
+
+	m := newMetrics()
+	if metricsAddr != "" {
+		l, err := net.Listen("tcp", metricsAddr)
+		if err != nil {
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m)
+		server := &http.Server{Handler: mux}
+		go server.Serve(l)
+		defer server.Close()
+	}
+
+	if err = removeStaleSocket(socketPath); err != nil {
+		return err
+	}
+	agent, err := notify.ListenAgent(socketPath)
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, func() { agent.Close() })
+	logger.Info("listening for containers", "socket", socketPath)
+
+	group := notify.NewGroup()
+	group.OnExit = func(name string, err error) {
+		m.containerStopped()
+		if err != nil {
+			logger.Warn("stopped supervising container", "name", name, "error", err)
+			return
+		}
+		logger.Info("stopped supervising container", "name", name)
+	}
+	done := make(chan struct{})
+	go func() {
+		group.Run(ctx)
+		close(done)
+	}()
+
+	for {
+		l, state, err := agent.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Warn("failed to accept container", "error", err)
+			continue
+		}
+
+		name := fmt.Sprintf("%v/%d", state.State.ID, state.Pid)
+		s := notify.NewSupervisor(l)
+		s.Default = handlers
+		s.Workers = workers
+		s.Metrics = m
+		s.Logger = logger.With("container", state.State.ID, "container_pid", state.Pid)
+		s.LogLimiter = seccomp.NewLogLimiter(logLimit)
+		s.Corpus = corpus
+		if failOpen {
+			s.ErrorPolicy = notify.FailOpen
+		}
+		if err = group.Add(name, s); err != nil {
+			l.Close()
+			logger.Warn("failed to supervise container", "name", name, "error", err)
+			continue
+		}
+		m.containerStarted()
+		logger.Info("supervising container", "name", name, "metadata", state.Metadata, "bundle", state.State.Bundle)
+	}
+
+	<-done
+	return nil
+}
+
+// removeStaleSocket removes the socket left behind by a previous instance
+// that did not shut down cleanly.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%v exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,155 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// metrics implements notify.Metrics and serves the counters in the
+// Prometheus text format.
+type metrics struct {
+	mu            sync.Mutex
+	containers    int
+	notifications map[string]uint64
+	decisions     map[[2]string]uint64
+	failures      map[string]uint64
+	memoryErrors  map[string]uint64
+	latencySum    map[string]time.Duration
+	latencyCount  map[string]uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		notifications: map[string]uint64{},
+		decisions:     map[[2]string]uint64{},
+		failures:      map[string]uint64{},
+		memoryErrors:  map[string]uint64{},
+		latencySum:    map[string]time.Duration{},
+		latencyCount:  map[string]uint64{},
+	}
+}
+
+func (m *metrics) NotificationReceived(syscall string) {
+	m.mu.Lock()
+	m.notifications[syscall]++
+	m.mu.Unlock()
+}
+
+func (m *metrics) Decided(syscall string, decision notify.Decision) {
+	m.mu.Lock()
+	m.decisions[[2]string{syscall, decision.String()}]++
+	m.mu.Unlock()
+}
+
+func (m *metrics) HandlerLatency(syscall string, d time.Duration) {
+	m.mu.Lock()
+	m.latencySum[syscall] += d
+	m.latencyCount[syscall]++
+	m.mu.Unlock()
+}
+
+func (m *metrics) HandlerFailed(syscall string, _ error) {
+	m.mu.Lock()
+	m.failures[syscall]++
+	m.mu.Unlock()
+}
+
+func (m *metrics) MemoryReadFailed(syscall string, _ error) {
+	m.mu.Lock()
+	m.memoryErrors[syscall]++
+	m.mu.Unlock()
+}
+
+// containerStarted and containerStopped track the supervised containers.
+func (m *metrics) containerStarted() {
+	m.mu.Lock()
+	m.containers++
+	m.mu.Unlock()
+}
+
+func (m *metrics) containerStopped() {
+	m.mu.Lock()
+	m.containers--
+	m.mu.Unlock()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP seccomp_notify_containers Number of supervised containers.\n")
+	fmt.Fprintf(w, "# TYPE seccomp_notify_containers gauge\n")
+	fmt.Fprintf(w, "seccomp_notify_containers %d\n", m.containers)
+
+	writeCounter(w, "seccomp_notify_notifications_total", "Notifications received by syscall.", m.notifications)
+	writeCounter(w, "seccomp_notify_handler_errors_total", "Handler errors by syscall.", m.failures)
+	writeCounter(w, "seccomp_notify_memory_read_errors_total", "Failed reads of target memory by syscall.", m.memoryErrors)
+
+	fmt.Fprintf(w, "# HELP seccomp_notify_decisions_total Answered notifications by syscall and decision.\n")
+	fmt.Fprintf(w, "# TYPE seccomp_notify_decisions_total counter\n")
+	keys := make([][2]string, 0, len(m.decisions))
+	for k := range m.decisions {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "seccomp_notify_decisions_total{syscall=%q,decision=%q} %d\n", k[0], k[1], m.decisions[k])
+	}
+
+	fmt.Fprintf(w, "# HELP seccomp_notify_handler_seconds Time spent in handlers by syscall.\n")
+	fmt.Fprintf(w, "# TYPE seccomp_notify_handler_seconds summary\n")
+	for _, name := range sortedKeys(m.latencyCount) {
+		fmt.Fprintf(w, "seccomp_notify_handler_seconds_sum{syscall=%q} %g\n", name, m.latencySum[name].Seconds())
+		fmt.Fprintf(w, "seccomp_notify_handler_seconds_count{syscall=%q} %d\n", name, m.latencyCount[name])
+	}
+}
+
+func writeCounter(w io.Writer, name, help string, values map[string]uint64) {
+	fmt.Fprintf(w, "# HELP %v %v\n", name, help)
+	fmt.Fprintf(w, "# TYPE %v counter\n", name)
+	for _, syscall := range sortedKeys(values) {
+		fmt.Fprintf(w, "%v{syscall=%q} %d\n", name, syscall, values[syscall])
+	}
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -103,8 +103,8 @@ func (p *parser) Parse(objDump string) ([]Syscall, error) {
 		// Found a syscall. Clear the instruction stack.
 		instructions = instructions[:0]
 
-		name, found := p.SyscallNumbers[syscall.Num]
-		if !found {
+		name := p.SyscallName(syscall.Num)
+		if name == "" {
 			fmt.Fprintf(os.Stderr, "WARN: unknown syscall %d found at %+v\n", syscall.Num, syscall)
 			continue
 		}
@@ -307,7 +307,7 @@ func addWhitelist(archInfo *arch.Info, syscalls []string) ([]string, []string) {
 
 	var added []string
 	for _, s := range allowList {
-		if _, found := archInfo.SyscallNames[s]; found {
+		if _, found := archInfo.SyscallNumber(s); found {
 			_, found := m[s]
 			if !found {
 				m[s] = struct{}{}
@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-sim shows the action that a policy takes for a syscall and
+// the rule that decided it, without loading the filter.
+//
+//	seccomp-sim [flags] policy-file syscall [arg...]
+//
+// The syscall is a name or a number. Arguments are numbers in Go syntax
+// (e.g. 0x80000 or -100).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat string
+	archName    string
+	dataArch    string
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archName, "arch", "", "policy architecture (e.g. x86_64 or aarch64), defaults to the native architecture")
+	flag.StringVar(&dataArch, "syscall-arch", "", "architecture of the syscall (e.g. i386 or x32), defaults to the policy architecture")
+	flag.Parse()
+
+	if flag.NArg() < 2 || flag.NArg() > 8 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-sim [flags] policy-file syscall [arg...]\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	filter, err := cli.LoadFilter(flag.Arg(0), inputFormat, archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	sim, err := seccomp.NewSimulator(&filter.Policy)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	info := filter.Policy.Arch()
+	if dataArch != "" {
+		if info, err = arch.GetInfo(dataArch); err != nil {
+			cli.Fatal(err)
+		}
+	}
+	data, err := cli.SeccompData(info, flag.Arg(1), flag.Args()[2:])
+	if err != nil {
+		cli.Fatal(err)
+	}
+	result, err := sim.Run(data)
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	name := result.Syscall
+	if name == "" {
+		if name = info.SyscallName(int(data.NR &^ int32(info.SeccompMask))); name == "" {
+			name = "unknown"
+		}
+	}
+	fmt.Printf("syscall: %s (%d) on %v\n", name, result.Data.NR, info.Name)
+	fmt.Printf("args:    %#x\n", result.Data.Args)
+	fmt.Printf("action:  %v\n", result.Action)
+	fmt.Printf("reason:  %s\n", result.Reason)
+	if result.Action != result.Intent {
+		fmt.Fprintf(os.Stderr, "warning: the policy intends %v but the filter returns %v\n", result.Intent, result.Action)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,290 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+// Command seccomp-trace runs a program under observation and writes a
+// minimal allowlist policy of the syscalls it used when it exits.
+//
+//	seccomp-trace [flags] program [arg...]
+//
+// In notify mode (Linux 5.5+) every syscall of the program and its children
+// is sent to this command with a user notification and then continues. In
+// log mode (Linux 4.14+) the program runs under a filter that logs every
+// syscall, and the SECCOMP records are read from the audit log after it
+// exits, which requires auditd. The policy is a draft: review it, and run the
+// program through all of its code paths while tracing.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// shimSyscalls are allowed while tracing in notify mode because the shim of
+// seccomp.CommandListener must not be notified for them. They are not
+// recorded.
+var shimSyscalls = []string{"close", "sendmsg"}
+
+var (
+	mode          string
+	outFile       string
+	auditLog      string
+	auditDelay    time.Duration
+	defaultAction = seccomp.ActionErrno
+	verbose       bool
+)
+
+func main() {
+	flag.StringVar(&mode, "mode", "notify", "observation mode (notify or log)")
+	flag.StringVar(&outFile, "out", "-", "policy output file")
+	flag.StringVar(&auditLog, "audit-log", "/var/log/audit/audit.log", "audit log to read in log mode")
+	flag.DurationVar(&auditDelay, "audit-delay", time.Second, "time to wait for auditd to write the records in log mode")
+	flag.TextVar(&defaultAction, "default-action", defaultAction, "default action of the policy")
+	flag.BoolVar(&verbose, "v", false, "print every syscall in notify mode")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-trace [flags] program [arg...]\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	// The program receives the signals of the terminal. Keep running to
+	// write the policy after it exits.
+	signal.Ignore(os.Interrupt)
+
+	var trace *seccomp.SyscallTrace
+	var err error
+	switch mode {
+	case "notify":
+		trace, err = traceNotify(flag.Args())
+	case "log":
+		trace, err = traceLog(flag.Args())
+	default:
+		err = fmt.Errorf("unknown mode %q", mode)
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		cli.Fatal(err)
+	}
+	if exitErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v: %v\n", flag.Arg(0), exitErr)
+	}
+
+	if err := writePolicy(trace); err != nil {
+		cli.Fatal(err)
+	}
+	if exitErr != nil {
+		os.Exit(max(exitErr.ExitCode(), 1))
+	}
+}
+
+// traceNotify runs the program under a filter that notifies every syscall
+// and records them with a notify.Observer. The error of the program is
+// returned with the trace.
+func traceNotify(args []string) (*seccomp.SyscallTrace, error) {
+	features, err := seccomp.KernelSupport()
+	if err != nil {
+		return nil, err
+	}
+	if !features.HasAction(seccomp.ActionUserNotify) {
+		return nil, errors.New("user notifications are not supported by the kernel, use -mode log")
+	}
+
+	filter := seccomp.Filter{
+		NoNewPrivs: true,
+		Policy: seccomp.Policy{
+			DefaultAction: seccomp.ActionUserNotify,
+			Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionAllow, Names: shimSyscalls}},
+		},
+	}
+	cmd, receiver := seccomp.CommandListener(filter, args[0], args[1:]...)
+	defer receiver.Close()
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	file, err := receiver.Receive()
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	listener, err := notify.NewListener(file)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+
+	observer := &notify.Observer{}
+	if verbose {
+		observer.OnRecord = func(r notify.Record) {
+			fmt.Fprintln(os.Stderr, r)
+		}
+	}
+	supervisor := notify.NewSupervisor(listener)
+	observer.Register(supervisor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- supervisor.Run(ctx)
+	}()
+
+	waitErr := cmd.Wait()
+	// Stop observing children that outlive the program.
+	cancel()
+	if err := <-done; err != nil {
+		return nil, err
+	}
+
+	trace := seccomp.NewSyscallTrace()
+	for name, n := range observer.Profile() {
+		trace.AddCount(name, n)
+	}
+	// Almost every program closes files, but the calls were not observed.
+	trace.AddCount("close", 0)
+
+	if unknown := observer.Unknown(); len(unknown) > 0 {
+		nrs := make([]int, 0, len(unknown))
+		for nr := range unknown {
+			nrs = append(nrs, int(nr))
+		}
+		sort.Ints(nrs)
+		fmt.Fprintf(os.Stderr, "warning: syscalls with unknown numbers were used: %v\n", nrs)
+	}
+	return trace, waitErr
+}
+
+// traceLog runs the program under a filter that logs every syscall and
+// reads the records from the audit log. The error of the program is
+// returned with the trace.
+func traceLog(args []string) (*seccomp.SyscallTrace, error) {
+	features, err := seccomp.KernelSupport()
+	if err != nil {
+		return nil, err
+	}
+	if !features.HasAction(seccomp.ActionLog) {
+		return nil, errors.New("the log action is not supported by the kernel")
+	}
+	if logged, err := seccomp.ActionsLogged(); err == nil && !containsAction(logged, seccomp.ActionLog) {
+		return nil, errors.New("the log action is not logged, add it to kernel.seccomp.actions_logged")
+	}
+
+	f, err := os.Open(auditLog)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err = f.Seek(0, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	filter := seccomp.Filter{
+		NoNewPrivs: true,
+		Policy:     seccomp.Policy{DefaultAction: seccomp.ActionLog},
+	}
+	cmd := seccomp.Command(filter, args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	waitErr := cmd.Wait()
+	time.Sleep(auditDelay)
+
+	// The filter is only installed in the program and its children, so the
+	// records of the log action written since the start are theirs, unless
+	// other processes use the log action at the same time.
+	trace := seccomp.NewSyscallTrace()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		record, ok, err := seccomp.ParseAuditRecord(s.Text())
+		if err != nil || !ok || record.Code != seccomp.ActionLog || record.Syscall == "" {
+			continue
+		}
+		if !record.Time.IsZero() && record.Time.Before(start.Truncate(time.Second)) {
+			continue
+		}
+		trace.AddCount(record.Syscall, 1)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(trace.Profile) == 0 {
+		return nil, fmt.Errorf("no SECCOMP records were found in %v, is auditd running?", auditLog)
+	}
+	return trace, waitErr
+}
+
+func containsAction(actions []seccomp.Action, action seccomp.Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func writePolicy(trace *seccomp.SyscallTrace) error {
+	policy, err := trace.Policy(seccomp.DraftOptions{})
+	if err != nil {
+		return err
+	}
+	policy.DefaultAction = defaultAction
+	data, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if outFile != "-" {
+		if out, err = os.Create(outFile); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "# Syscalls used by %v, traced in %v mode.\n", flag.Args(), mode)
+	if mode == "notify" {
+		fmt.Fprintf(out, "# The calls of %v were not observed.\n", shimSyscalls)
+	}
+	if _, err = out.Write(data); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,218 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-validate checks policy files, e.g. in CI, and exits with
+// status 1 if any of them has errors.
+//
+//	seccomp-validate [flags] policy-file...
+//
+// For every architecture each policy is compiled and verified against the
+// emulator. Unknown syscalls, filters that exceed the instruction limit, and
+// policies that need a newer kernel than -min-kernel are errors. Rules that
+// never match are warnings, which -strict turns into errors. With -load the
+// kernel is asked to accept the filter for the native architecture.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+// maxInstructions is the instruction limit of the kernel (BPF_MAXINSNS).
+const maxInstructions = 4096
+
+// allArches are the architectures checked with -arch all.
+var allArches = []string{"x86_64", "i386", "aarch64", "arm"}
+
+var (
+	inputFormat string
+	archNames   string
+	minKernel   string
+	maxInsts    int
+	strict      bool
+	load        bool
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archNames, "arch", "", "comma separated architectures to check or \"all\", defaults to the native architecture")
+	flag.StringVar(&minKernel, "min-kernel", "", "oldest kernel version (e.g. 4.14) that must support the policies")
+	flag.IntVar(&maxInsts, "max-instructions", maxInstructions, "maximum number of BPF instructions")
+	flag.BoolVar(&strict, "strict", false, "treat warnings as errors")
+	flag.BoolVar(&load, "load", false, "check that the kernel accepts the filters for the native architecture")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-validate [flags] policy-file...\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	var target *seccomp.KernelVersion
+	if minKernel != "" {
+		v, err := seccomp.ParseKernelVersion(minKernel)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		target = &v
+	}
+
+	names := []string{""}
+	switch archNames {
+	case "":
+	case "all":
+		names = allArches
+	default:
+		names = strings.Split(archNames, ",")
+	}
+	var arches []*arch.Info
+	for _, name := range names {
+		info, err := arch.GetInfo(strings.TrimSpace(name))
+		if err != nil {
+			cli.Fatal(err)
+		}
+		arches = append(arches, info)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		for _, info := range arches {
+			c := &checker{path: path, arch: info}
+			c.check(target)
+			failed = failed || c.errors > 0 || (strict && c.warnings > 0)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// checker checks one policy file for one arch and prints the problems.
+type checker struct {
+	path     string
+	arch     *arch.Info
+	errors   int
+	warnings int
+}
+
+func (c *checker) errorf(format string, args ...interface{}) {
+	c.errors++
+	fmt.Printf("%v: %v: error: %v\n", c.path, c.arch.Name, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) warnf(format string, args ...interface{}) {
+	c.warnings++
+	fmt.Printf("%v: %v: warning: %v\n", c.path, c.arch.Name, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) check(target *seccomp.KernelVersion) {
+	filter, err := cli.LoadFilter(c.path, inputFormat, c.arch.Name)
+	if err != nil {
+		c.errorf("%v", err)
+		return
+	}
+	policy := &filter.Policy
+
+	if err = policy.Validate(); err != nil {
+		c.errorf("%v", err)
+		return
+	}
+	if unknown := unknownSyscalls(policy); len(unknown) > 0 {
+		c.errorf("unknown syscalls: %v", strings.Join(unknown, ", "))
+		return
+	}
+
+	report, err := seccomp.NewFilterReport(filter)
+	if err != nil {
+		c.errorf("%v", err)
+		return
+	}
+	if err = policy.Verify(); err != nil {
+		c.errorf("%v", err)
+	}
+	if report.Instructions > maxInsts {
+		c.errorf("%d instructions exceed the limit of %d", report.Instructions, maxInsts)
+	}
+	if target != nil && target.Less(report.MinKernel) {
+		var newer []string
+		for _, req := range report.Requirements {
+			if target.Less(req.Version) {
+				newer = append(newer, req.String())
+			}
+		}
+		c.errorf("requires Linux %v but must support Linux %v: %v", report.MinKernel, target, strings.Join(newer, ", "))
+	}
+
+	for _, category := range report.Categories {
+		for _, rule := range category.Rules {
+			if rule.Shadowed {
+				c.warnf("rule for %v (%v) never matches, an earlier rule matches all calls", rule.Syscall, rule.Action)
+			}
+		}
+	}
+	if !filter.NoNewPrivs {
+		c.warnf("no_new_privs is disabled, installing the filter requires CAP_SYS_ADMIN")
+	}
+
+	if load && c.arch == nativeArch() {
+		if err = seccomp.Validate(*filter); err != nil {
+			c.errorf("%v", err)
+		}
+	}
+
+	if c.errors == 0 {
+		fmt.Printf("%v: %v: ok, %d instructions, requires Linux %v\n", c.path, c.arch.Name, report.Instructions, report.MinKernel)
+	}
+}
+
+// unknownSyscalls returns the sorted syscall names of the policy that the
+// arch of the policy does not define.
+func unknownSyscalls(p *seccomp.Policy) []string {
+	info := p.Arch()
+	unknown := map[string]bool{}
+	for _, group := range p.Syscalls {
+		for _, name := range group.Names {
+			if _, found := info.SyscallNumber(name); !found {
+				unknown[name] = true
+			}
+		}
+		for _, nc := range group.NamesWithCondtions {
+			if _, found := info.SyscallNumber(nc.Name); !found {
+				unknown[nc.Name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func nativeArch() *arch.Info {
+	info, _ := arch.GetInfo("")
+	return info
+}
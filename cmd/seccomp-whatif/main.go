@@ -0,0 +1,80 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command seccomp-whatif runs a program under ptrace and reports the
+// syscalls that a policy would have rejected, without loading the filter.
+//
+//	seccomp-whatif [flags] policy-file program [arg...]
+//
+// The syscalls of the program, its threads, and its children are evaluated
+// with the filter in userspace and are not affected by it, so the impact of
+// a policy can be checked before it is deployed. It requires Linux 5.3.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/cmd/internal/cli"
+)
+
+var (
+	inputFormat string
+	archName    string
+)
+
+func main() {
+	flag.StringVar(&inputFormat, "format", "", "policy format ("+cli.Formats+"), detected from the file extension by default")
+	flag.StringVar(&archName, "arch", "", "policy architecture (e.g. x86_64 or aarch64), defaults to the native architecture")
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		fmt.Fprintf(os.Stderr, "usage: seccomp-whatif [flags] policy-file program [arg...]\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	filter, err := cli.LoadFilter(flag.Arg(0), inputFormat, archName)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	insts, err := filter.Policy.Assemble()
+	if err != nil {
+		cli.Fatal(err)
+	}
+
+	cmd := exec.Command(flag.Arg(1), flag.Args()[2:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	report, err := seccomp.WhatIf(cmd, insts)
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		cli.Fatal(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "seccomp-whatif: %d syscalls evaluated, %d would be rejected\n",
+		report.Syscalls, len(report.Denials))
+	for _, d := range report.Denials {
+		fmt.Fprintf(os.Stderr, "  %v\n", d)
+	}
+	if len(report.Denials) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,268 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+var commandShim = registerShim("command", runCommandShim)
+
+// commandRequestFd is the descriptor of the pipe that passes the
+// commandRequest to the command shim. It is the first of cmd.ExtraFiles.
+const commandRequestFd = 3
+
+// commandRequest tells the command shim which filter to install and which
+// program to execute.
+type commandRequest struct {
+	Path                       string               `json:"path"`
+	ListenerSocket             int                  `json:"listener_socket,omitempty"`
+	Flag                       uint32               `json:"flag"`
+	NoNewPrivs                 bool                 `json:"no_new_privs"`
+	SkipNoNewPrivsIfPrivileged bool                 `json:"skip_no_new_privs_if_privileged"`
+	Instructions               []bpf.RawInstruction `json:"instructions"`
+}
+
+// Command returns an *exec.Cmd that runs the named program with the filter
+// installed, like exec.Command. The command starts a re-executed copy of the
+// current binary that installs the filter on its only remaining thread and
+// then executes the program, so any program, including non-Go programs, can
+// be confined without modifying it while the calling process stays
+// unconfined. The policy must therefore allow execve.
+//
+// The environment of the returned command contains the variable that starts
+// the shim and the filter is passed in cmd.ExtraFiles. To change the
+// environment append to cmd.Env instead of replacing it, and append to
+// cmd.ExtraFiles instead of replacing it. Filters that create a listener are only supported by CommandListener.
+// If the filter cannot be compiled the error is returned by Start through
+// cmd.Err. In dry run mode the program runs without the filter.
+func Command(filter Filter, name string, arg ...string) *exec.Cmd {
+	if filter.Flag&FilterFlagNewListener != 0 {
+		cmd := exec.Command(name, arg...)
+		cmd.Err = errors.New("use seccomp.CommandListener for filters with the new_listener flag")
+		return cmd
+	}
+	return command(filter, nil, name, arg...)
+}
+
+// CommandListener is like Command but for filters that use
+// ActionUserNotify. The shim sends the notification listener to the calling
+// process over a socket before it executes the program, so a supervisor in
+// the unconfined parent can handle the notifications of the child. Call
+// ListenerReceiver.Receive after cmd.Start to obtain the listener.
+//
+// The shim calls sendmsg, close, and execve after installing the filter. The
+// policy must not notify sendmsg and close, and notified execve calls are
+// only answered once the listener was received.
+func CommandListener(filter Filter, name string, arg ...string) (*exec.Cmd, *ListenerReceiver) {
+	filter.Flag |= FilterFlagNewListener
+	r := &ListenerReceiver{}
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		cmd := exec.Command(name, arg...)
+		cmd.Err = fmt.Errorf("failed to create listener socket: %w", err)
+		return cmd, r
+	}
+	r.parent = os.NewFile(uintptr(fds[0]), "seccomp-listener-receiver")
+	r.child = os.NewFile(uintptr(fds[1]), "seccomp-listener-sender")
+	return command(filter, r.child, name, arg...), r
+}
+
+// command returns the command that runs the shim. If sock is not nil it is
+// passed to the child to send the listener.
+func command(filter Filter, sock *os.File, name string, arg ...string) *exec.Cmd {
+	cmd := exec.Command(name, arg...)
+	if cmd.Err != nil {
+		return cmd
+	}
+
+	raw, err := compileFilter(filter)
+	debugFilter(filter, raw, err)
+	if err != nil {
+		cmd.Err = err
+		return cmd
+	}
+	if filter.isDryRun() {
+		return cmd
+	}
+
+	req := commandRequest{
+		Path:                       cmd.Path,
+		Flag:                       uint32(filter.Flag),
+		NoNewPrivs:                 filter.NoNewPrivs,
+		SkipNoNewPrivsIfPrivileged: filter.SkipNoNewPrivsIfPrivileged,
+		Instructions:               raw,
+	}
+	if sock != nil {
+		// ExtraFiles start at descriptor 3 in the child, after the request.
+		req.ListenerSocket = commandRequestFd + 1
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		cmd.Err = err
+		return cmd
+	}
+	// The request does not fit into the environment for large filters
+	// because a single variable is limited to MAX_ARG_STRLEN.
+	pipe, err := requestPipe(data)
+	if err != nil {
+		cmd.Err = fmt.Errorf("failed to pass the filter to the command: %w", err)
+		return cmd
+	}
+	// The read end of the parent is closed when the command is collected,
+	// the shim closes its copy before it executes the program.
+	cmd.ExtraFiles = []*os.File{pipe}
+	if sock != nil {
+		cmd.ExtraFiles = append(cmd.ExtraFiles, sock)
+	}
+
+	cmd.Path = selfExe
+	cmd.Env = append(os.Environ(), shimEnv+"="+commandShim)
+	return cmd
+}
+
+// requestPipe returns the read end of a pipe that contains data. The pipe is
+// grown to hold all of data so it can be written before the command starts.
+func requestPipe(data []byte) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	size, err := unix.FcntlInt(w.Fd(), unix.F_GETPIPE_SZ, 0)
+	if err == nil && size < len(data) {
+		_, err = unix.FcntlInt(w.Fd(), unix.F_SETPIPE_SZ, len(data))
+	}
+	if err == nil {
+		_, err = w.Write(data)
+	}
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListenerReceiver receives the notification listener of a command created
+// by CommandListener.
+type ListenerReceiver struct {
+	parent, child *os.File
+}
+
+// Receive waits until the shim installed the filter and returns the
+// listener. It must be called after the command was started. In dry run mode
+// no filter is installed and an error is returned.
+func (r *ListenerReceiver) Receive() (*os.File, error) {
+	if r.parent == nil {
+		return nil, errors.New("no listener socket")
+	}
+	// The child has its own copy now. Closing ours makes the read fail if
+	// the child exits without sending the listener.
+	r.child.Close()
+	defer r.parent.Close()
+
+	buf := make([]byte, 1)
+	oob := make([]byte, unix.CmsgSpace(4))
+	var n, oobn int
+	var err error
+	for {
+		n, oobn, _, _, err = unix.Recvmsg(int(r.parent.Fd()), buf, oob, unix.MSG_CMSG_CLOEXEC)
+		if err != unix.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive listener: %w", err)
+	}
+	if n == 0 {
+		return nil, errors.New("command exited before sending the listener")
+	}
+
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		return nil, fmt.Errorf("invalid listener message: %v", err)
+	}
+	fds, err := unix.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+		return nil, fmt.Errorf("invalid listener message: %v", err)
+	}
+	return os.NewFile(uintptr(fds[0]), "seccomp-notify"), nil
+}
+
+// Close closes the socket. It is not needed after Receive.
+func (r *ListenerReceiver) Close() error {
+	if r.parent == nil {
+		return nil
+	}
+	r.child.Close()
+	return r.parent.Close()
+}
+
+// runCommandShim installs the filter from the request pipe on the calling
+// thread and executes the program, which inherits the filter.
+func runCommandShim() int {
+	var req commandRequest
+	f := os.NewFile(commandRequestFd, "seccomp-command-request")
+	err := json.NewDecoder(f).Decode(&req)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seccomp: invalid command request: %v\n", err)
+		return 127
+	}
+
+	// The program is executed by this thread, so only it needs the filter.
+	runtime.LockOSThread()
+	filter := Filter{
+		Flag:                       FilterFlag(req.Flag),
+		NoNewPrivs:                 req.NoNewPrivs,
+		SkipNoNewPrivsIfPrivileged: req.SkipNoNewPrivsIfPrivileged,
+	}
+	listener, err := installFilter(filter, req.Instructions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seccomp: %v\n", err)
+		return 127
+	}
+	if req.ListenerSocket != 0 {
+		err = unix.Sendmsg(req.ListenerSocket, []byte{0}, unix.UnixRights(int(listener)), nil, 0)
+		unix.Close(int(listener))
+		unix.Close(req.ListenerSocket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seccomp: failed to send listener: %v\n", err)
+			return 127
+		}
+	}
+
+	err = syscall.Exec(req.Path, os.Args, os.Environ())
+	fmt.Fprintf(os.Stderr, "seccomp: failed to execute %s: %v\n", req.Path, err)
+	return 127
+}
@@ -0,0 +1,147 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// CompiledProgram is a filter program that was assembled in advance, for
+// example by seccomp-gen at build time, so that it does not need to be
+// assembled at startup and the installed program is exactly the one that
+// was reviewed. Set it as Filter.Compiled to install it instead of the
+// policy. The generated Go code of seccomp-gen -o go declares the whole
+// Filter; the raw output of seccomp-gen can be embedded instead:
+//
+//	//go:generate seccomp-gen -arch x86_64 -out filter.bpf policy.yml
+//	//go:embed filter.bpf
+//	var program []byte
+//
+//	var filter = seccomp.Filter{
+//		NoNewPrivs: true,
+//		Flag:       seccomp.FilterFlagTSync,
+//		Compiled:   &seccomp.CompiledProgram{Arch: "x86_64", Raw: program},
+//	}
+type CompiledProgram struct {
+	// Arch is the name of the arch that the program was assembled for. The
+	// program is only installed on this arch.
+	Arch string
+
+	// Raw is the program as an array of struct sock_filter in little endian
+	// byte order, the raw output format of seccomp-gen.
+	Raw []byte
+}
+
+// CompileProgram assembles the policy into a CompiledProgram.
+func CompileProgram(p *Policy) (*CompiledProgram, error) {
+	insts, err := p.Assemble()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := bpf.Assemble(insts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble BPF instructions: %w", err)
+	}
+	return &CompiledProgram{Arch: p.Arch().Name, Raw: encodeRaw(raw)}, nil
+}
+
+// Instructions returns the disassembled program.
+func (c *CompiledProgram) Instructions() ([]bpf.Instruction, error) {
+	raw, err := decodeRaw(c.Raw)
+	if err != nil {
+		return nil, err
+	}
+	insts, allDecoded := bpf.Disassemble(raw)
+	if !allDecoded {
+		return nil, fmt.Errorf("failed to disassemble compiled %v program", c.Arch)
+	}
+	return insts, nil
+}
+
+// program returns the raw instructions to install. It fails if the program
+// was assembled for another arch than the native one.
+func (c *CompiledProgram) program() ([]bpf.RawInstruction, error) {
+	native, err := arch.GetInfo("")
+	if err != nil {
+		return nil, err
+	}
+	if c.Arch != native.Name {
+		return nil, fmt.Errorf("compiled program is for %v but the native arch is %v", c.Arch, native.Name)
+	}
+	return decodeRaw(c.Raw)
+}
+
+// actions returns the distinct actions returned by the program.
+func (c *CompiledProgram) actions() []Action {
+	insts, err := c.Instructions()
+	if err != nil {
+		return nil
+	}
+	var actions []Action
+	for _, inst := range insts {
+		ret, ok := inst.(bpf.RetConstant)
+		if !ok {
+			continue
+		}
+		found := false
+		for _, a := range actions {
+			if a == Action(ret.Val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			actions = append(actions, Action(ret.Val))
+		}
+	}
+	return actions
+}
+
+// encodeRaw encodes the program as an array of struct sock_filter. All
+// supported architectures are little endian.
+func encodeRaw(raw []bpf.RawInstruction) []byte {
+	data := make([]byte, 0, 8*len(raw))
+	for _, r := range raw {
+		data = binary.LittleEndian.AppendUint16(data, r.Op)
+		data = append(data, r.Jt, r.Jf)
+		data = binary.LittleEndian.AppendUint32(data, r.K)
+	}
+	return data
+}
+
+// decodeRaw is the inverse of encodeRaw.
+func decodeRaw(data []byte) ([]bpf.RawInstruction, error) {
+	if len(data) == 0 || len(data)%8 != 0 {
+		return nil, fmt.Errorf("raw filter size %d is not a positive multiple of 8", len(data))
+	}
+	raw := make([]bpf.RawInstruction, 0, len(data)/8)
+	for b := data; len(b) > 0; b = b[8:] {
+		raw = append(raw, bpf.RawInstruction{
+			Op: binary.LittleEndian.Uint16(b[0:]),
+			Jt: b[2],
+			Jf: b[3],
+			K:  binary.LittleEndian.Uint32(b[4:]),
+		})
+	}
+	return raw, nil
+}
@@ -0,0 +1,89 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestCompileProgram(t *testing.T) {
+	p := cachePolicyCopy(t, "aarch64")
+	c, err := CompileProgram(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Arch != "aarch64" {
+		t.Errorf("unexpected arch %v", c.Arch)
+	}
+
+	insts, err := c.Instructions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cacheProgram(t, p); !reflect.DeepEqual(insts, want) {
+		t.Errorf("unexpected program:\n%v", insts)
+	}
+
+	want := []Action{ActionAllow, ActionErrno | 13, ActionErrno | 1}
+	if actions := c.actions(); !reflect.DeepEqual(actions, want) {
+		t.Errorf("expected actions %v, got %v", want, actions)
+	}
+
+	invalid := Policy{DefaultAction: ActionAllow, Syscalls: []SyscallGroup{{Action: ActionErrno, Names: []string{"nope"}}}}
+	if _, err = CompileProgram(&invalid); err == nil {
+		t.Error("expected an error for an invalid policy")
+	}
+}
+
+func TestCompiledProgramArch(t *testing.T) {
+	native, err := arch.GetInfo("")
+	if err != nil {
+		t.Skip(err)
+	}
+	allow := &CompiledProgram{Arch: native.Name, Raw: []byte{0x06, 0, 0, 0, 0, 0, 0xff, 0x7f}}
+	raw, err := allow.program()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bpf.RawInstruction{{Op: 0x06, K: uint32(ActionAllow)}}; !reflect.DeepEqual(raw, want) {
+		t.Errorf("unexpected program %v", raw)
+	}
+
+	other := "aarch64"
+	if native.Name == other {
+		other = "x86_64"
+	}
+	foreign := &CompiledProgram{Arch: other, Raw: allow.Raw}
+	if _, err = foreign.program(); err == nil || !strings.Contains(err.Error(), "native arch") {
+		t.Errorf("expected an arch error, got %v", err)
+	}
+}
+
+func TestCompiledProgramInvalid(t *testing.T) {
+	for _, raw := range [][]byte{nil, {0x06, 0, 0}, []byte("not a filter but 32 bytes long!!")} {
+		if _, err := (&CompiledProgram{Arch: "x86_64", Raw: raw}).Instructions(); err == nil {
+			t.Errorf("expected an error for %q", raw)
+		}
+	}
+}
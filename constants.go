@@ -23,6 +23,20 @@ import "github.com/elastic/go-seccomp-bpf/internal/unix"
 // no_new_privs bit.
 const prSetNoNewPrivs = unix.PR_SET_NO_NEW_PRIVS
 
+// prGetNoNewPrivs defines the prctl flag to get the calling thread's
+// no_new_privs bit.
+const prGetNoNewPrivs = unix.PR_GET_NO_NEW_PRIVS
+
+// prSetSeccomp defines the prctl flag to set the calling thread's seccomp
+// mode. It is used on kernels that predate the seccomp syscall.
+const prSetSeccomp = unix.PR_SET_SECCOMP
+
+// Seccomp modes passed to prctl(PR_SET_SECCOMP).
+const (
+	seccompModeStrict = unix.SECCOMP_MODE_STRICT
+	seccompModeFilter = unix.SECCOMP_MODE_FILTER
+)
+
 // Valid operations for seccomp syscall.
 // https://github.com/torvalds/linux/blob/v4.16/include/uapi/linux/seccomp.h#L14-L17
 const (
@@ -34,8 +48,16 @@ const (
 	// Seccomp filter mode where a BPF filter defines what system calls are
 	// allowed.
 	seccompSetModeFilter = unix.SECCOMP_SET_MODE_FILTER
+
+	// Test whether an action is supported by the kernel. Since Linux 4.14.
+	seccompGetActionAvail = unix.SECCOMP_GET_ACTION_AVAIL
 )
 
+// maxInstructions is the maximum number of instructions in a BPF program
+// accepted by the kernel (BPF_MAXINSNS).
+// https://github.com/torvalds/linux/blob/v4.16/include/uapi/linux/bpf_common.h#L53
+const maxInstructions = 4096
+
 // The arch field is not unique for all calling conventions.  The x86-64
 // ABI and the x32 ABI both use AUDIT_ARCH_X86_64 as arch, and they run
 // on the same processors.  Instead, the mask __X32_SYSCALL_BIT is used
@@ -56,6 +78,10 @@ const (
 	ActionUserNotify  Action = unix.SECCOMP_RET_USER_NOTIF   // Forward to user-space supervisor.
 )
 
+// actionMask masks the action from a filter return value, removing the data
+// (e.g. the errno value).
+const actionMask Action = unix.SECCOMP_RET_ACTION_FULL
+
 const (
 	errnoEPERM  = unix.EPERM
 	errnoENOSYS = unix.ENOSYS
@@ -68,7 +94,24 @@ const (
 	// process to the same seccomp filter tree. Since Linux 3.17.
 	FilterFlagTSync FilterFlag = unix.SECCOMP_FILTER_FLAG_TSYNC
 
-	// All filter return actions except SECCOMP_RET_ALLOW should be logged.
-	// Since Linux 4.14.
+	// All filter return actions except SECCOMP_RET_ALLOW should be logged by
+	// the kernel audit system. Combined with a policy that uses ActionLog
+	// this allows report-only deployments. Since Linux 4.14.
 	FilterFlagLog FilterFlag = unix.SECCOMP_FILTER_FLAG_LOG
+
+	// Disable the speculative store bypass mitigation that the kernel enables
+	// implicitly for processes with a seccomp filter. This improves the
+	// performance of workloads that are hurt by the mitigation, but leaves
+	// the process exposed to Spectre variant 4 attacks, so it should only be
+	// used when the code running in the process is trusted. Since Linux 4.17.
+	FilterFlagSpecAllow FilterFlag = unix.SECCOMP_FILTER_FLAG_SPEC_ALLOW
+
+	// Return a file descriptor for receiving user-space notifications for
+	// syscalls that match ActionUserNotify. Use LoadFilterListener to obtain
+	// the descriptor. Since Linux 5.0.
+	FilterFlagNewListener FilterFlag = unix.SECCOMP_FILTER_FLAG_NEW_LISTENER
+
+	// When used with FilterFlagTSync, return ESRCH instead of the ID of the
+	// thread that could not be synchronized. Since Linux 5.7.
+	FilterFlagTSyncESRCH FilterFlag = unix.SECCOMP_FILTER_FLAG_TSYNC_ESRCH
 )
@@ -0,0 +1,222 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// CorpusEntry is a seccomp_data payload recorded from a running program,
+// for example by a notify.Supervisor or a trap handler, together with the
+// action that its filter returned.
+type CorpusEntry struct {
+	Data   SeccompData
+	Action Action
+}
+
+// corpusLine is the JSON encoding of a CorpusEntry in a corpus file. The
+// name of the syscall is only informational.
+type corpusLine struct {
+	Arch    string    `json:"arch"`
+	Syscall string    `json:"syscall,omitempty"`
+	NR      int32     `json:"nr"`
+	Args    [6]uint64 `json:"args"`
+	IP      uint64    `json:"ip,omitempty"`
+	Action  Action    `json:"action"`
+}
+
+// CorpusWriter appends entries to a corpus file, one JSON object per line.
+// It is safe for concurrent use. A nil *CorpusWriter discards all entries.
+type CorpusWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seen map[CorpusEntry]struct{}
+}
+
+// NewCorpusWriter returns a CorpusWriter that writes to w.
+func NewCorpusWriter(w io.Writer) *CorpusWriter {
+	return &CorpusWriter{w: w, seen: map[CorpusEntry]struct{}{}}
+}
+
+// Record writes the payload and the action that the filter returned for it.
+// Payloads that were already recorded with the same action are skipped,
+// ignoring the instruction pointer, so that hot syscalls are recorded once.
+func (c *CorpusWriter) Record(d SeccompData, a Action) error {
+	if c == nil {
+		return nil
+	}
+	key := CorpusEntry{Data: d, Action: a}
+	key.Data.InstructionPointer = 0
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.seen[key]; found {
+		return nil
+	}
+
+	line, err := json.Marshal(corpusLine{
+		Arch:    corpusArchName(d.Arch),
+		Syscall: syscallName(d),
+		NR:      d.NR,
+		Args:    d.Args,
+		IP:      d.InstructionPointer,
+		Action:  a,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = c.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	c.seen[key] = struct{}{}
+	return nil
+}
+
+// ReadCorpus reads the entries of a corpus file written by a CorpusWriter.
+// Empty lines and lines starting with '#' are ignored.
+func ReadCorpus(r io.Reader) ([]CorpusEntry, error) {
+	var entries []CorpusEntry
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var line corpusLine
+		if err := json.Unmarshal([]byte(text), &line); err != nil {
+			return nil, fmt.Errorf("invalid corpus line %d: %w", n, err)
+		}
+		id, err := corpusArchID(line.Arch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid corpus line %d: %w", n, err)
+		}
+		entries = append(entries, CorpusEntry{
+			Data:   SeccompData{NR: line.NR, Arch: id, InstructionPointer: line.IP, Args: line.Args},
+			Action: line.Action,
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CorpusChange is a corpus entry for which a program makes a different
+// decision than the filter that the entry was recorded with.
+type CorpusChange struct {
+	Entry CorpusEntry
+	Got   Action // Action returned by the replayed program.
+}
+
+// Denies returns true if the replayed program rejects a call that the
+// recorded filter let through, and false if it lets through a call that the
+// recorded filter rejected.
+func (c CorpusChange) Denies() bool {
+	return !c.Got.permits()
+}
+
+func (c CorpusChange) String() string {
+	name := syscallName(c.Entry.Data)
+	if name == "" {
+		name = "nr " + strconv.Itoa(int(c.Entry.Data.NR))
+	}
+	args := make([]string, len(c.Entry.Data.Args))
+	for i, a := range c.Entry.Data.Args {
+		args[i] = "0x" + strconv.FormatUint(a, 16)
+	}
+	return fmt.Sprintf("%s %s(%s): %v -> %v", corpusArchName(c.Entry.Data.Arch), name,
+		strings.Join(args, ", "), c.Entry.Action, c.Got)
+}
+
+// ReplayCorpus runs the program in the Emulator for every entry and returns
+// the entries whose call the program lets through, possibly after notifying
+// a tracer or supervisor, while the recorded filter rejected it or the other
+// way around. Changes of the action within these two kinds, such as a
+// different errno, are not reported.
+func ReplayCorpus(insts []bpf.Instruction, entries []CorpusEntry) ([]CorpusChange, error) {
+	emulator, err := NewEmulator(insts)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []CorpusChange
+	for _, e := range entries {
+		got, err := emulator.Run(e.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run filter for nr=%d: %w", e.Data.NR, err)
+		}
+		if got.permits() != e.Action.permits() {
+			changes = append(changes, CorpusChange{Entry: e, Got: got})
+		}
+	}
+	return changes, nil
+}
+
+// corpusArchName returns the name of the audit arch, or its value in hex if
+// it is unknown.
+func corpusArchName(id uint32) string {
+	for _, info := range knownArches {
+		if uint32(info.ID) == id && info != arch.X32 {
+			return info.Name
+		}
+	}
+	return fmt.Sprintf("0x%08x", id)
+}
+
+// corpusArchID is the inverse of corpusArchName.
+func corpusArchID(name string) (uint32, error) {
+	for _, info := range knownArches {
+		if info.Name == name && info != arch.X32 {
+			return uint32(info.ID), nil
+		}
+	}
+	id, err := strconv.ParseUint(name, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unknown arch %q", name)
+	}
+	return uint32(id), nil
+}
+
+// syscallName returns the name of the syscall of the payload, or an empty
+// string if it is unknown. x32 syscalls are prefixed with "x32 ".
+func syscallName(d SeccompData) string {
+	for _, info := range knownArches {
+		if uint32(info.ID) != d.Arch || info == arch.X32 {
+			continue
+		}
+		if info.ID == arch.X86_64.ID && uint32(d.NR)&uint32(arch.X32.SeccompMask) != 0 {
+			if name := arch.X32.SyscallName(int(uint32(d.NR) &^ uint32(arch.X32.SeccompMask))); name != "" {
+				return "x32 " + name
+			}
+			return ""
+		}
+		return info.SyscallName(int(d.NR))
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestCorpusWriter(t *testing.T) {
+	var b strings.Builder
+	c := NewCorpusWriter(&b)
+	openat := SeccompData{NR: 257, Arch: uint32(arch.X86_64.ID), InstructionPointer: 0x401000, Args: [6]uint64{0xffffff9c, 0x7ffd0000, 0x80000}}
+	x32Write := SeccompData{NR: 1 | int32(arch.X32.SeccompMask), Arch: uint32(arch.X86_64.ID)}
+	foreign := SeccompData{NR: 4, Arch: 0x1234}
+	for _, e := range []CorpusEntry{
+		{openat, ActionAllow},
+		{SeccompData{NR: 257, Arch: uint32(arch.X86_64.ID), InstructionPointer: 0x402000, Args: openat.Args}, ActionAllow},
+		{openat, ActionErrno | 13},
+		{x32Write, ActionKillProcess},
+		{foreign, ActionTrap},
+	} {
+		if err := c.Record(e.Data, e.Action); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := `{"arch":"x86_64","syscall":"openat","nr":257,"args":[4294967196,2147287040,524288,0,0,0],"ip":4198400,"action":"allow"}
+{"arch":"x86_64","syscall":"openat","nr":257,"args":[4294967196,2147287040,524288,0,0,0],"ip":4198400,"action":"errno(13)"}
+{"arch":"x86_64","syscall":"x32 write","nr":1073741825,"args":[0,0,0,0,0,0],"action":"kill_process"}
+{"arch":"0x00001234","nr":4,"args":[0,0,0,0,0,0],"action":"trap"}
+`
+	if b.String() != want {
+		t.Errorf("unexpected corpus:\n%s", b.String())
+	}
+
+	entries, err := ReadCorpus(strings.NewReader("# recorded corpus\n\n" + b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEntries := []CorpusEntry{{openat, ActionAllow}, {openat, ActionErrno | 13}, {x32Write, ActionKillProcess}, {foreign, ActionTrap}}
+	if !reflect.DeepEqual(entries, wantEntries) {
+		t.Errorf("expected %+v, got %+v", wantEntries, entries)
+	}
+
+	var nilWriter *CorpusWriter
+	if err := nilWriter.Record(openat, ActionAllow); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadCorpusErrors(t *testing.T) {
+	for in, want := range map[string]string{
+		"{\"arch\":\"x86_64\",\"nr\":1,\"action\":\"allow\"}\n{": "invalid corpus line 2",
+		`{"arch":"vax","nr":1,"action":"allow"}`:                 `unknown arch "vax"`,
+		`{"arch":"x86_64","nr":1,"action":"bogus"}`:              "invalid action",
+	} {
+		if _, err := ReadCorpus(strings.NewReader(in)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error %q for %q, got %v", want, in, err)
+		}
+	}
+}
+
+func TestReplayCorpus(t *testing.T) {
+	policy := Policy{
+		DefaultAction: ActionErrno,
+		Syscalls: []SyscallGroup{
+			{Action: ActionAllow, Names: []string{"read", "write"}},
+			{Action: ActionUserNotify, Names: []string{"mount"}},
+		},
+	}
+	if err := policy.SetArch("x86_64"); err != nil {
+		t.Fatal(err)
+	}
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x86 := uint32(arch.X86_64.ID)
+	entries := []CorpusEntry{
+		{SeccompData{NR: 0, Arch: x86}, ActionAllow},                    // Unchanged.
+		{SeccompData{NR: 1, Arch: x86, Args: [6]uint64{2}}, ActionTrap}, // Now allowed.
+		{SeccompData{NR: 257, Arch: x86}, ActionLog},                    // Now denied.
+		{SeccompData{NR: 165, Arch: x86}, ActionAllow},                  // Notified, still permitted.
+		{SeccompData{NR: 62, Arch: x86}, ActionKillProcess},             // Errno instead of kill.
+	}
+	changes, err := ReplayCorpus(insts, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CorpusChange{
+		{Entry: entries[1], Got: ActionAllow},
+		{Entry: entries[2], Got: ActionErrno | 1},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+	if changes[0].Denies() || !changes[1].Denies() {
+		t.Errorf("unexpected kinds of changes %v", changes)
+	}
+	if s := changes[0].String(); s != "x86_64 write(0x2, 0x0, 0x0, 0x0, 0x0, 0x0): trap -> allow" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
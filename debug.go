@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/bpf"
+)
+
+// DebugEnv is the environment variable that makes LoadFilter,
+// LoadFilterListener, ForkExec, and Command write the report of every filter
+// they load followed by its annotated disassembly, to debug filters in the
+// field without code changes. Set it to a true value (e.g. "1") to write to
+// stderr, or to a path to append to that file.
+const DebugEnv = "SECCOMP_DEBUG"
+
+// debugFilter writes the debug dump of the filter if DebugEnv is set. raw and
+// err are the result of compiling the filter. Failures to write the dump are
+// ignored so that debugging does not change whether the filter loads.
+func debugFilter(filter Filter, raw []bpf.RawInstruction, err error) {
+	dest := os.Getenv(DebugEnv)
+	if dest == "" {
+		return
+	}
+	enabled, parseErr := strconv.ParseBool(dest)
+	if parseErr == nil && !enabled {
+		return
+	}
+
+	var buf bytes.Buffer
+	writeFilterDebug(&buf, filter, raw, err)
+	if parseErr == nil {
+		os.Stderr.Write(buf.Bytes())
+		return
+	}
+	f, openErr := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if openErr != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(buf.Bytes())
+}
+
+// writeFilterDebug writes the debug dump of a filter: a header that
+// identifies the process, the error of compiling the filter if any, the
+// report of the filter, and its disassembly.
+func writeFilterDebug(buf *bytes.Buffer, filter Filter, raw []bpf.RawInstruction, err error) {
+	exe, _ := os.Executable()
+	fmt.Fprintf(buf, "seccomp: loading filter in pid %d (%s) at %s\n\n",
+		os.Getpid(), exe, time.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		fmt.Fprintf(buf, "The filter failed to compile: %v\n\n", err)
+	}
+
+	if report, err := NewFilterReport(&filter); err == nil {
+		report.WriteMarkdown(buf)
+	}
+	if len(raw) == 0 {
+		return
+	}
+	insts, _ := bpf.Disassemble(raw)
+	buf.WriteString("\n## Disassembly\n\n```\n")
+	WriteDisassembly(buf, insts, filter.Policy.Arch())
+	buf.WriteString("```\n\n")
+}
@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFilterDebug(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seccomp.log")
+	t.Setenv(DebugEnv, path)
+
+	filter := Filter{
+		DryRun: true,
+		Flag:   FilterFlagTSync,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"mount"}}},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if err := LoadFilter(filter); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "## Disassembly"); n != 2 {
+		t.Errorf("expected a dump per load, got %d:\n%s", n, data)
+	}
+	if !strings.Contains(string(data), "| mount | errno | always |") {
+		t.Errorf("expected the report of the policy in\n%s", data)
+	}
+
+	t.Setenv(DebugEnv, "0")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadFilter(filter); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected no dump if the variable is false")
+	}
+}
@@ -0,0 +1,68 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/bpf"
+)
+
+func TestWriteFilterDebug(t *testing.T) {
+	filter := Filter{
+		NoNewPrivs: true,
+		Policy: Policy{
+			DefaultAction: ActionErrno,
+			Syscalls:      []SyscallGroup{{Action: ActionAllow, Names: []string{"read"}}},
+		},
+	}
+	insts, err := filter.Policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := bpf.Assemble(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	writeFilterDebug(&buf, filter, raw, nil)
+	out := buf.String()
+	for _, want := range []string{
+		"seccomp: loading filter in pid ",
+		"# Seccomp policy report",
+		"| No new privs | yes |",
+		"| read | allow | always |",
+		"## Disassembly",
+		"# read, goto ",
+		"# allow\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	writeFilterDebug(&buf, Filter{}, nil, errors.New("invalid action"))
+	if out := buf.String(); !strings.Contains(out, "The filter failed to compile: invalid action") || strings.Contains(out, "Disassembly") {
+		t.Errorf("unexpected dump of a failed filter\n%s", out)
+	}
+}
@@ -0,0 +1,799 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// maxDecompilePaths limits the number of paths through the program that are
+// followed for a single syscall.
+const maxDecompilePaths = 1 << 14
+
+// errUnresolved is returned when the checks of a syscall cannot be expressed
+// by a policy.
+var errUnresolved = errors.New("checks cannot be expressed as conditions")
+
+// Decompilation is a policy recovered from a BPF program.
+type Decompilation struct {
+	Policy *Policy
+
+	// Unresolved lists the syscalls whose checks cannot be expressed as
+	// conditions, for example because they depend on the instruction pointer
+	// or on masked comparisons. Policy applies the default action to them.
+	Unresolved []string
+}
+
+// Decompile recovers a policy from a seccomp BPF program, for example one
+// retrieved from a running process with GetProcessFilters. The arch of the
+// policy is detected from the first architecture check of the program if info
+// is nil.
+//
+// Every syscall of the arch is evaluated symbolically: the argument checks
+// along each path through the program are collected and converted to
+// conditions. The recovered policy makes the same decisions as the program
+// for the syscalls of its arch (except the Unresolved ones), but it handles
+// foreign architectures and x32 syscalls like every policy does.
+func Decompile(insts []bpf.Instruction, info *arch.Info) (*Decompilation, error) {
+	if info == nil {
+		arches := ProgramArches(insts)
+		if len(arches) == 0 {
+			return nil, errors.New("failed to detect the arch of the program")
+		}
+		info = arches[0]
+	}
+	d := &decompiler{insts: insts, info: info, limit: argumentLimit(info)}
+
+	// Syscalls that are not in the table of the arch get the default action.
+	maxNR := 0
+	for nr := range info.Syscalls() {
+		maxNR = max(maxNR, nr)
+	}
+	leaves, err := d.syscall(uint32((maxNR + 1) | info.SeccompMask))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the default action: %w", err)
+	}
+	defaultAction := leaves[0].action
+	for _, l := range leaves {
+		if l.action != defaultAction {
+			return nil, errors.New("failed to find the default action: it depends on the arguments")
+		}
+	}
+
+	groups := map[Action]*SyscallGroup{}
+	group := func(action Action) *SyscallGroup {
+		g, found := groups[action]
+		if !found {
+			g = &SyscallGroup{Action: action}
+			groups[action] = g
+		}
+		return g
+	}
+
+	type syscall struct {
+		nr   int
+		name string
+	}
+	syscalls := make([]syscall, 0, info.NumSyscalls())
+	for nr, name := range info.Syscalls() {
+		syscalls = append(syscalls, syscall{nr, name})
+	}
+	sort.Slice(syscalls, func(i, j int) bool { return syscalls[i].name < syscalls[j].name })
+
+	var unresolved []string
+	for _, s := range syscalls {
+		name := s.name
+		leaves, err := d.syscall(uint32(s.nr | info.SeccompMask))
+		var terms []decompiledTerm
+		if err == nil {
+			terms, err = decisionTerms(leaves, defaultAction)
+		}
+		if errors.Is(err, errUnresolved) {
+			unresolved = append(unresolved, name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompile %v: %w", name, err)
+		}
+
+		for _, t := range terms {
+			conditions := t.conditions(d.limit)
+			if len(conditions) == 0 {
+				group(t.action).Names = append(group(t.action).Names, name)
+				continue
+			}
+			group(t.action).NamesWithCondtions = append(group(t.action).NamesWithCondtions,
+				NameWithConditions{Name: name, Conditions: conditions})
+		}
+	}
+
+	// The rules of different actions never overlap, so the order of the
+	// groups does not matter.
+	actions := make([]Action, 0, len(groups))
+	for action := range groups {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+
+	policy := &Policy{DefaultAction: defaultAction, arch: info}
+	for _, action := range actions {
+		policy.Syscalls = append(policy.Syscalls, *groups[action])
+	}
+	return &Decompilation{Policy: policy, Unresolved: unresolved}, nil
+}
+
+// ProgramArches returns the arches that the architecture checks of a seccomp
+// BPF program compare to, in the order of the checks.
+func ProgramArches(insts []bpf.Instruction) []*arch.Info {
+	var arches []*arch.Info
+	seen := map[*arch.Info]bool{}
+	for i := 0; i < len(insts)-1; i++ {
+		if ld, ok := insts[i].(bpf.LoadAbsolute); !ok || ld.Off != archOffset {
+			continue
+		}
+		for _, inst := range insts[i+1:] {
+			jump, ok := inst.(bpf.JumpIf)
+			if !ok {
+				break
+			}
+			for _, info := range knownArches {
+				if uint32(info.ID) == jump.Val && info.SeccompMask == 0 && info.NumSyscalls() > 0 && !seen[info] {
+					seen[info] = true
+					arches = append(arches, info)
+				}
+			}
+		}
+	}
+	return arches
+}
+
+// argumentLimit returns the maximum value of a syscall argument.
+func argumentLimit(info *arch.Info) uint64 {
+	if uint32(info.ID)&auditArch64Bit == 0 {
+		return math.MaxUint32
+	}
+	return math.MaxUint64
+}
+
+// decompiler evaluates a program symbolically.
+type decompiler struct {
+	insts []bpf.Instruction
+	info  *arch.Info
+	limit uint64 // Maximum value of an argument.
+	paths int
+}
+
+// valueKind is the kind of a value in a register or the scratch memory.
+type valueKind int
+
+const (
+	valueUnknown valueKind = iota
+	valueConstant
+	valueArgument
+)
+
+// symbolicValue is a 32-bit value that is either a constant or a word of a
+// syscall argument, possibly masked.
+type symbolicValue struct {
+	kind  valueKind
+	value uint32 // Constant value.
+	arg   int    // Argument index.
+	hi    bool   // Most significant word of the argument.
+	mask  uint32 // Mask applied to the argument word.
+}
+
+func constant(v uint32) symbolicValue {
+	return symbolicValue{kind: valueConstant, value: v}
+}
+
+// symbolicState is the machine state along one path through the program.
+type symbolicState struct {
+	a, x  symbolicValue
+	mem   [16]symbolicValue
+	words [6][2]wordDomain // Constraints on the lo and hi words of the arguments.
+
+	// The path depends on checks that the words do not express.
+	opaque bool
+}
+
+// leaf is a path that ended with a return.
+type leaf struct {
+	action Action
+	words  [6][2]wordDomain
+	opaque bool
+}
+
+// syscall returns the paths of the program for the syscall number.
+func (d *decompiler) syscall(nr uint32) ([]leaf, error) {
+	var s symbolicState
+	for i := range s.words {
+		s.words[i][0] = wordDomain{max: math.MaxUint32}
+		s.words[i][1] = wordDomain{max: uint32(d.limit >> 32)}
+	}
+
+	d.paths = 0
+	var leaves []leaf
+	if err := d.exec(0, s, nr, &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// decisionTerms returns the terms of the paths that do not end with the
+// default action. These must be exact, while the paths of the default action
+// can depend on any checks.
+func decisionTerms(leaves []leaf, defaultAction Action) ([]decompiledTerm, error) {
+	var terms []decompiledTerm
+	for _, l := range leaves {
+		if l.action == defaultAction {
+			continue
+		}
+		if l.opaque {
+			return nil, errUnresolved
+		}
+		t, err := leafTerms(l)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range t {
+			if t.feasible() {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return mergeTerms(terms), nil
+}
+
+// auditArch64Bit is the flag of the audit arch values of 64-bit arches.
+const auditArch64Bit = 0x80000000
+
+// exec follows all paths from the instruction at pc.
+func (d *decompiler) exec(pc int, s symbolicState, nr uint32, leaves *[]leaf) error {
+	for {
+		if pc >= len(d.insts) {
+			return errors.New("program does not end with a return")
+		}
+
+		switch inst := d.insts[pc].(type) {
+		case bpf.LoadAbsolute:
+			s.a = d.load(inst, nr)
+		case bpf.LoadConstant:
+			if inst.Dst == bpf.RegA {
+				s.a = constant(inst.Val)
+			} else {
+				s.x = constant(inst.Val)
+			}
+		case bpf.LoadScratch:
+			if inst.Dst == bpf.RegA {
+				s.a = s.mem[inst.N]
+			} else {
+				s.x = s.mem[inst.N]
+			}
+		case bpf.StoreScratch:
+			if inst.Src == bpf.RegA {
+				s.mem[inst.N] = s.a
+			} else {
+				s.mem[inst.N] = s.x
+			}
+		case bpf.TAX:
+			s.x = s.a
+		case bpf.TXA:
+			s.a = s.x
+		case bpf.ALUOpConstant:
+			v, err := aluOp(s.a, inst.Op, inst.Val)
+			if err != nil {
+				return err
+			}
+			s.a = v
+		case bpf.ALUOpX:
+			if s.x.kind != valueConstant {
+				s.a = symbolicValue{}
+				break
+			}
+			v, err := aluOp(s.a, inst.Op, s.x.value)
+			if err != nil {
+				return err
+			}
+			s.a = v
+		case bpf.NegateA:
+			if s.a.kind == valueConstant {
+				s.a = constant(-s.a.value)
+			} else {
+				s.a = symbolicValue{}
+			}
+		case bpf.Jump:
+			pc += int(inst.Skip)
+		case bpf.JumpIf:
+			return d.jump(pc, s, nr, leaves, inst.Cond, inst.Val, inst.SkipTrue, inst.SkipFalse)
+		case bpf.JumpIfX:
+			if s.x.kind != valueConstant {
+				s.a = symbolicValue{}
+			}
+			return d.jump(pc, s, nr, leaves, inst.Cond, s.x.value, inst.SkipTrue, inst.SkipFalse)
+		case bpf.RetConstant:
+			return d.ret(Action(inst.Val), s, leaves)
+		case bpf.RetA:
+			if s.a.kind != valueConstant {
+				return errUnresolved
+			}
+			return d.ret(Action(s.a.value), s, leaves)
+		default:
+			// LoadIndirect, LoadMemShift, and extensions.
+			s.a = symbolicValue{}
+		}
+		pc++
+	}
+}
+
+// load returns the value of a word of the seccomp_data.
+func (d *decompiler) load(inst bpf.LoadAbsolute, nr uint32) symbolicValue {
+	switch {
+	case inst.Size != sizeOfUint32 || inst.Off%uint32(sizeOfUint32) != 0:
+		return symbolicValue{}
+	case inst.Off == syscallNumOffset:
+		return constant(nr)
+	case inst.Off == archOffset:
+		return constant(uint32(d.info.ID))
+	case inst.Off >= argumentOffset && inst.Off < sizeOfSeccompData:
+		off := inst.Off - argumentOffset
+		second := off%sizeOfUint64 != 0
+		return symbolicValue{
+			kind: valueArgument,
+			arg:  int(off / sizeOfUint64),
+			hi:   second == (nativeEndian == binary.LittleEndian),
+			mask: math.MaxUint32,
+		}
+	default:
+		// The instruction pointer.
+		return symbolicValue{}
+	}
+}
+
+// aluOp applies the operation to the value. Only masking is tracked for
+// argument words.
+func aluOp(v symbolicValue, op bpf.ALUOp, val uint32) (symbolicValue, error) {
+	if v.kind == valueArgument && op == bpf.ALUOpAnd {
+		v.mask &= val
+		return v, nil
+	}
+	if v.kind != valueConstant {
+		return symbolicValue{}, nil
+	}
+
+	a := v.value
+	switch op {
+	case bpf.ALUOpAdd:
+		a += val
+	case bpf.ALUOpSub:
+		a -= val
+	case bpf.ALUOpMul:
+		a *= val
+	case bpf.ALUOpOr:
+		a |= val
+	case bpf.ALUOpAnd:
+		a &= val
+	case bpf.ALUOpShiftLeft:
+		a <<= val
+	case bpf.ALUOpShiftRight:
+		a >>= val
+	case bpf.ALUOpXor:
+		a ^= val
+	case bpf.ALUOpDiv, bpf.ALUOpMod:
+		if val == 0 {
+			return symbolicValue{}, errUnresolved
+		}
+		if op == bpf.ALUOpDiv {
+			a /= val
+		} else {
+			a %= val
+		}
+	default:
+		return symbolicValue{}, nil
+	}
+	return constant(a), nil
+}
+
+// jump follows the branches of a conditional jump that can be taken.
+func (d *decompiler) jump(pc int, s symbolicState, nr uint32, leaves *[]leaf, cond bpf.JumpTest, val uint32, skipTrue, skipFalse uint8) error {
+	switch s.a.kind {
+	case valueConstant:
+		if jumpTaken(cond, s.a.value, val) {
+			return d.exec(pc+1+int(skipTrue), s, nr, leaves)
+		}
+		return d.exec(pc+1+int(skipFalse), s, nr, leaves)
+	case valueArgument:
+		half := 0
+		if s.a.hi {
+			half = 1
+		}
+		word := s.words[s.a.arg][half]
+		for _, result := range []bool{true, false} {
+			next := s
+			w, err := word.constrain(cond, val, s.a.mask, result)
+			if errors.Is(err, errUnresolved) {
+				w, next.opaque = word, true
+			} else if !w.feasible() {
+				continue
+			}
+			next.words[s.a.arg][half] = w
+			skip := skipFalse
+			if result {
+				skip = skipTrue
+			}
+			if err := d.exec(pc+1+int(skip), next, nr, leaves); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		for _, skip := range []uint8{skipTrue, skipFalse} {
+			next := s
+			next.opaque = true
+			if err := d.exec(pc+1+int(skip), next, nr, leaves); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (d *decompiler) ret(action Action, s symbolicState, leaves *[]leaf) error {
+	if d.paths++; d.paths > maxDecompilePaths {
+		return errUnresolved
+	}
+	if action == ActionErrno|Action(errnoEPERM) {
+		// Same as the errno action without data.
+		action = ActionErrno
+	}
+	*leaves = append(*leaves, leaf{action: action, words: s.words, opaque: s.opaque})
+	return nil
+}
+
+// jumpTaken evaluates a jump condition.
+func jumpTaken(cond bpf.JumpTest, a, val uint32) bool {
+	switch cond {
+	case bpf.JumpEqual:
+		return a == val
+	case bpf.JumpNotEqual:
+		return a != val
+	case bpf.JumpGreaterThan:
+		return a > val
+	case bpf.JumpLessThan:
+		return a < val
+	case bpf.JumpGreaterOrEqual:
+		return a >= val
+	case bpf.JumpLessOrEqual:
+		return a <= val
+	case bpf.JumpBitsSet:
+		return a&val != 0
+	case bpf.JumpBitsNotSet:
+		return a&val == 0
+	}
+	return false
+}
+
+// wordDomain is the set of values that a 32-bit argument word can have on a
+// path. The slices are never modified in place because they are shared
+// between paths.
+type wordDomain struct {
+	min, max uint32
+	excluded []uint32 // Values in the range that are excluded.
+	none     uint32   // Bits that are not set.
+	any      []uint32 // Masks of which at least one bit is set.
+}
+
+// constrain returns the domain with the result of the jump condition applied
+// to the masked word.
+func (w wordDomain) constrain(cond bpf.JumpTest, val, mask uint32, result bool) (wordDomain, error) {
+	// Normalize to the positive conditions.
+	switch cond {
+	case bpf.JumpNotEqual:
+		cond, result = bpf.JumpEqual, !result
+	case bpf.JumpLessOrEqual:
+		cond, result = bpf.JumpGreaterThan, !result
+	case bpf.JumpLessThan:
+		cond, result = bpf.JumpGreaterOrEqual, !result
+	case bpf.JumpBitsNotSet:
+		cond, result = bpf.JumpBitsSet, !result
+	}
+
+	if cond == bpf.JumpBitsSet {
+		val &= mask
+		if result {
+			w.any = append(w.any[:len(w.any):len(w.any)], val)
+		} else {
+			w.none |= val
+		}
+		return w, nil
+	}
+
+	if mask != math.MaxUint32 {
+		// Only masked equality is expressed by bits.
+		if cond != bpf.JumpEqual || !result {
+			return w, errUnresolved
+		}
+		if val&^mask != 0 {
+			return wordDomain{min: 1, max: 0}, nil
+		}
+		w.none |= mask &^ val
+		for v := val; v != 0; v &= v - 1 {
+			w.any = append(w.any[:len(w.any):len(w.any)], v&-v)
+		}
+		return w, nil
+	}
+
+	switch {
+	case cond == bpf.JumpEqual && result:
+		w.min, w.max = max(w.min, val), min(w.max, val)
+	case cond == bpf.JumpEqual:
+		w.excluded = append(w.excluded[:len(w.excluded):len(w.excluded)], val)
+	case cond == bpf.JumpGreaterThan && result:
+		if val == math.MaxUint32 {
+			return wordDomain{min: 1, max: 0}, nil
+		}
+		w.min = max(w.min, val+1)
+	case cond == bpf.JumpGreaterThan:
+		w.max = min(w.max, val)
+	case cond == bpf.JumpGreaterOrEqual && result:
+		w.min = max(w.min, val)
+	case cond == bpf.JumpGreaterOrEqual:
+		if val == 0 {
+			return wordDomain{min: 1, max: 0}, nil
+		}
+		w.max = min(w.max, val-1)
+	default:
+		return w, errUnresolved
+	}
+	return w, nil
+}
+
+// feasible returns false if the domain is known to be empty.
+func (w wordDomain) feasible() bool {
+	if w.min > w.max {
+		return false
+	}
+	for _, mask := range w.any {
+		if mask&^w.none == 0 {
+			return false
+		}
+	}
+	if w.min == w.max {
+		return valueMatches(uint64(w.min), toUint64(w.excluded), uint64(w.none), toUint64(w.any))
+	}
+	return true
+}
+
+func toUint64(values []uint32) []uint64 {
+	out := make([]uint64, len(values))
+	for i, v := range values {
+		out[i] = uint64(v)
+	}
+	return out
+}
+
+// valueMatches returns true if the value is not excluded and satisfies the
+// bit constraints.
+func valueMatches(v uint64, excluded []uint64, none uint64, any []uint64) bool {
+	for _, e := range excluded {
+		if v == e {
+			return false
+		}
+	}
+	if v&none != 0 {
+		return false
+	}
+	for _, mask := range any {
+		if v&mask == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// argumentDomain is a set of 64-bit argument values: a range without the
+// excluded values that satisfies the bit constraints.
+type argumentDomain struct {
+	min, max uint64
+	excluded []uint64
+	none     uint64
+	any      []uint64
+}
+
+// decompiledTerm is a decision of the program for a set of arguments.
+type decompiledTerm struct {
+	action Action
+	args   [6]argumentDomain
+}
+
+// leafTerms converts the constraints on the argument words of a path to
+// terms on 64-bit arguments.
+func leafTerms(l leaf) ([]decompiledTerm, error) {
+	terms := []decompiledTerm{{action: l.action}}
+	for i, words := range l.words {
+		alternatives, err := argumentDomains(words[0], words[1])
+		if err != nil {
+			return nil, err
+		}
+
+		product := make([]decompiledTerm, 0, len(terms)*len(alternatives))
+		for _, t := range terms {
+			for _, arg := range alternatives {
+				t.args[i] = arg
+				product = append(product, t)
+			}
+		}
+		terms = product
+	}
+	return terms, nil
+}
+
+// argumentDomains converts the domains of the words of an argument to a
+// union of argument domains.
+func argumentDomains(lo, hi wordDomain) ([]argumentDomain, error) {
+	base := argumentDomain{none: uint64(hi.none)<<32 | uint64(lo.none)}
+	for _, mask := range hi.any {
+		base.any = append(base.any, uint64(mask)<<32)
+	}
+	for _, mask := range lo.any {
+		base.any = append(base.any, uint64(mask))
+	}
+
+	switch {
+	case lo.min == 0 && lo.max == math.MaxUint32 && len(lo.excluded) == 0:
+		// Split the range of the hi word at the excluded values.
+		excluded := append([]uint32(nil), hi.excluded...)
+		sort.Slice(excluded, func(i, j int) bool { return excluded[i] < excluded[j] })
+
+		var domains []argumentDomain
+		start := uint64(hi.min)
+		for _, e := range excluded {
+			if uint64(e) >= start && e <= hi.max {
+				if uint64(e) > start {
+					d := base
+					d.min, d.max = start<<32, uint64(e-1)<<32|math.MaxUint32
+					domains = append(domains, d)
+				}
+				start = uint64(e) + 1
+			}
+		}
+		if start <= uint64(hi.max) {
+			d := base
+			d.min, d.max = start<<32, uint64(hi.max)<<32|math.MaxUint32
+			domains = append(domains, d)
+		}
+		return domains, nil
+	case hi.min == hi.max:
+		h := uint64(hi.min) << 32
+		d := base
+		d.min, d.max = h|uint64(lo.min), h|uint64(lo.max)
+		for _, e := range hi.excluded {
+			if e == hi.min {
+				return nil, nil
+			}
+		}
+		for _, e := range lo.excluded {
+			if e >= lo.min && e <= lo.max {
+				d.excluded = append(d.excluded, h|uint64(e))
+			}
+		}
+		return []argumentDomain{d}, nil
+	default:
+		return nil, errUnresolved
+	}
+}
+
+// mergeTerms joins the terms of the same action whose arguments differ only
+// by adjacent ranges of one argument.
+func mergeTerms(terms []decompiledTerm) []decompiledTerm {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(terms) && !merged; i++ {
+			for j := i + 1; j < len(terms) && !merged; j++ {
+				if t, ok := mergeTerm(terms[i], terms[j]); ok {
+					terms[i] = t
+					terms = append(terms[:j], terms[j+1:]...)
+					merged = true
+				}
+			}
+		}
+	}
+	return terms
+}
+
+func mergeTerm(a, b decompiledTerm) (decompiledTerm, bool) {
+	if a.action != b.action {
+		return a, false
+	}
+	differ := -1
+	for i := range a.args {
+		if reflect.DeepEqual(a.args[i], b.args[i]) {
+			continue
+		}
+		if differ >= 0 {
+			return a, false
+		}
+		differ = i
+	}
+	if differ < 0 {
+		return a, true
+	}
+
+	x, y := a.args[differ], b.args[differ]
+	if x.none != y.none || !reflect.DeepEqual(x.any, y.any) {
+		return a, false
+	}
+	if x.min > y.min {
+		x, y = y, x
+	}
+	if x.max != math.MaxUint64 && x.max+1 < y.min {
+		return a, false
+	}
+	x.max = max(x.max, y.max)
+	x.excluded = append(x.excluded[:len(x.excluded):len(x.excluded)], y.excluded...)
+	sort.Slice(x.excluded, func(i, j int) bool { return x.excluded[i] < x.excluded[j] })
+	a.args[differ] = x
+	return a, true
+}
+
+// feasible returns false if an argument can have no value.
+func (t decompiledTerm) feasible() bool {
+	for _, arg := range t.args {
+		if arg.min > arg.max || (arg.min == arg.max && !valueMatches(arg.min, arg.excluded, arg.none, arg.any)) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditions returns the conditions that match the arguments of the term.
+// Arguments are not compared to the limit, the maximum value of an argument.
+func (t decompiledTerm) conditions(limit uint64) ArgumentConditions {
+	var conditions ArgumentConditions
+	for i, arg := range t.args {
+		n := uint32(i)
+		if arg.min == arg.max {
+			conditions = append(conditions, Condition{Argument: n, Operation: Equal, Value: arg.min})
+			continue
+		}
+		if arg.min > 0 {
+			conditions = append(conditions, Condition{Argument: n, Operation: GreaterOrEqual, Value: arg.min})
+		}
+		if arg.max < limit {
+			conditions = append(conditions, Condition{Argument: n, Operation: LessOrEqual, Value: arg.max})
+		}
+		for _, e := range arg.excluded {
+			conditions = append(conditions, Condition{Argument: n, Operation: NotEqual, Value: e})
+		}
+		if arg.none != 0 {
+			conditions = append(conditions, Condition{Argument: n, Operation: BitsNotSet, Value: arg.none})
+		}
+		for _, mask := range arg.any {
+			conditions = append(conditions, Condition{Argument: n, Operation: BitsSet, Value: mask})
+		}
+	}
+	return conditions
+}
@@ -0,0 +1,202 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestDecompile(t *testing.T) {
+	conditions := func(op Operation, value uint64) []NameWithConditions {
+		return []NameWithConditions{
+			{Name: "write", Conditions: ArgumentConditions{{Argument: 1, Operation: op, Value: value}}},
+		}
+	}
+	policies := map[string]*Policy{
+		"allowlist": {
+			arch:          arch.X86_64,
+			DefaultAction: ActionKillProcess,
+			Syscalls: []SyscallGroup{
+				{Names: []string{"read", "write", "exit_group"}, Action: ActionAllow},
+				{Names: []string{"openat"}, Action: ActionErrno | Action(errnoENOSYS)},
+			},
+		},
+		"arm": {
+			arch:          arch.ARM,
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{
+				{Names: []string{"execve"}, Action: ActionErrno},
+				{Action: ActionLog, NamesWithCondtions: conditions(LessThan, 3)},
+			},
+		},
+		"conditions": {
+			arch:          arch.X86_64,
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{
+				{
+					Action: ActionErrno,
+					NamesWithCondtions: []NameWithConditions{
+						{Name: "clone", Conditions: ArgumentConditions{{Argument: 0, Operation: BitsNotSet, Value: 0x10000000}}},
+						{Name: "socket", Conditions: ArgumentConditions{
+							{Argument: 0, Operation: NotEqual, Value: 1},
+							{Argument: 1, Operation: GreaterThan, Value: 0x100000002},
+						}},
+						{Name: "socket", Conditions: ArgumentConditions{{Argument: 2, Operation: BitsSet, Value: 0x300000000}}},
+					},
+				},
+				{Names: []string{"ptrace"}, Action: ActionKillThread},
+			},
+		},
+	}
+	for _, op := range []Operation{Equal, NotEqual, GreaterThan, GreaterOrEqual, LessThan, LessOrEqual, BitsSet, BitsNotSet} {
+		policies[string(op)] = &Policy{
+			arch:          arch.AARCH64,
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, NamesWithCondtions: conditions(op, 0x500000003)}},
+		}
+	}
+
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			insts, err := policy.Assemble()
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := Decompile(insts, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Policy.arch != policy.arch {
+				t.Errorf("expected arch %v, got %v", policy.arch.Name, d.Policy.arch.Name)
+			}
+			if len(d.Unresolved) > 0 {
+				t.Errorf("unresolved syscalls: %v", d.Unresolved)
+			}
+			if err = d.Policy.VerifyProgram(insts); err != nil {
+				t.Error(err)
+			}
+			if err = policy.VerifyProgram(mustAssemble(t, d.Policy)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestDecompileConditions(t *testing.T) {
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionErrno,
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "socket", Conditions: ArgumentConditions{{Argument: 0, Operation: NotEqual, Value: 1}}},
+					{Name: "write", Conditions: ArgumentConditions{{Argument: 2, Operation: GreaterOrEqual, Value: 0x100000000}}},
+					{Name: "clone", Conditions: ArgumentConditions{{Argument: 0, Operation: BitsNotSet, Value: 0x10000000}}},
+				},
+			},
+		},
+	}
+	d, err := Decompile(mustAssemble(t, policy), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SyscallGroup{
+		{
+			Action: ActionErrno,
+			NamesWithCondtions: []NameWithConditions{
+				{Name: "clone", Conditions: ArgumentConditions{{Argument: 0, Operation: BitsNotSet, Value: 0x10000000}}},
+				{Name: "socket", Conditions: ArgumentConditions{{Argument: 0, Operation: NotEqual, Value: 1}}},
+				{Name: "write", Conditions: ArgumentConditions{{Argument: 2, Operation: GreaterOrEqual, Value: 0x100000000}}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(d.Policy.Syscalls, want) {
+		t.Errorf("expected %+v, got %+v", want, d.Policy.Syscalls)
+	}
+}
+
+func mustAssemble(t *testing.T, p *Policy) []bpf.Instruction {
+	t.Helper()
+	insts, err := p.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return insts
+}
+
+func TestDecompileProgram(t *testing.T) {
+	lo := func(arg uint32) uint32 {
+		if nativeEndian == binary.BigEndian {
+			return argumentOffset + sizeOfUint64*arg + uint32(sizeOfUint32)
+		}
+		return argumentOffset + sizeOfUint64*arg
+	}
+	socket := uint32(syscallNumber(arch.X86_64, "socket"))
+	getpid := uint32(syscallNumber(arch.X86_64, "getpid"))
+	allow := bpf.RetConstant{Val: uint32(ActionAllow)}
+
+	// Written like libseccomp, with a masked comparison and a check of the
+	// instruction pointer.
+	insts := []bpf.Instruction{
+		bpf.LoadAbsolute{Off: archOffset, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(arch.X86_64.ID), SkipFalse: 10},
+		bpf.LoadAbsolute{Off: syscallNumOffset, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: socket, SkipFalse: 4},
+		bpf.LoadAbsolute{Off: lo(0), Size: 4},
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0xff},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 2, SkipFalse: 6},
+		bpf.RetConstant{Val: uint32(ActionErrno | Action(errnoEPERM))},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: getpid, SkipFalse: 4},
+		bpf.LoadAbsolute{Off: 8, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0, SkipFalse: 2},
+		bpf.RetConstant{Val: uint32(ActionKillProcess)},
+		bpf.RetConstant{Val: uint32(ActionKillProcess)},
+		allow,
+		allow,
+	}
+	d, err := Decompile(insts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"getpid"}; !reflect.DeepEqual(d.Unresolved, want) {
+		t.Errorf("expected unresolved %v, got %v", want, d.Unresolved)
+	}
+	want := []SyscallGroup{
+		{
+			Action: ActionErrno,
+			NamesWithCondtions: []NameWithConditions{
+				{Name: "socket", Conditions: ArgumentConditions{
+					{Argument: 0, Operation: BitsNotSet, Value: 0xfd},
+					{Argument: 0, Operation: BitsSet, Value: 0x2},
+				}},
+			},
+		},
+	}
+	if d.Policy.DefaultAction != ActionAllow || !reflect.DeepEqual(d.Policy.Syscalls, want) {
+		t.Errorf("expected %+v, got %v %+v", want, d.Policy.DefaultAction, d.Policy.Syscalls)
+	}
+}
@@ -0,0 +1,252 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// FilterDifference is a syscall that two programs decide differently.
+type FilterDifference struct {
+	Arch    *arch.Info
+	Syscall string      // Name of the syscall, empty for syscall numbers that the arch does not define.
+	Data    SeccompData // Example input for which the decisions differ.
+	A, B    Action      // Actions returned by the programs for Data.
+
+	// Partial is true if the decisions only differ for some arguments.
+	// Conditions describes these arguments if they can be expressed.
+	Partial    bool
+	Conditions ArgumentConditions
+}
+
+func (d FilterDifference) String() string {
+	name := d.Syscall
+	if name == "" {
+		name = "undefined syscalls"
+	}
+	s := fmt.Sprintf("%v %v: %v -> %v", d.Arch.Name, name, d.A, d.B)
+	switch {
+	case len(d.Conditions) > 0:
+		s += " when " + describeConditions(d.Conditions)
+	case d.Partial:
+		s += fmt.Sprintf(" for some arguments, e.g. %#x", d.Data.Args)
+	}
+	return s
+}
+
+// DiffPrograms compares the decisions of two seccomp BPF programs for every
+// syscall of the arches, e.g. a compiled policy and a filter retrieved with
+// GetProcessFilters. If no arches are given, the arches checked by either
+// program are compared. The paths of both programs are evaluated
+// symbolically and each difference is confirmed with an example input in the
+// Emulator.
+func DiffPrograms(a, b []bpf.Instruction, arches ...*arch.Info) ([]FilterDifference, error) {
+	if len(arches) == 0 {
+		seen := map[*arch.Info]bool{}
+		for _, info := range append(ProgramArches(a), ProgramArches(b)...) {
+			if !seen[info] {
+				seen[info] = true
+				arches = append(arches, info)
+			}
+		}
+		if len(arches) == 0 {
+			return nil, fmt.Errorf("failed to detect the arches of the programs")
+		}
+	}
+
+	emulatorA, err := NewEmulator(a)
+	if err != nil {
+		return nil, err
+	}
+	emulatorB, err := NewEmulator(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FilterDifference
+	for _, info := range arches {
+		da := &decompiler{insts: a, info: info, limit: argumentLimit(info)}
+		db := &decompiler{insts: b, info: info, limit: da.limit}
+
+		numbers := make([]int, 0, info.NumSyscalls()+1)
+		for nr := range info.Syscalls() {
+			numbers = append(numbers, nr)
+		}
+		numbers = append(numbers, numbers[len(numbers)-1]+1)
+
+		for _, nr := range numbers {
+			data := SeccompData{NR: int32(nr | info.SeccompMask), Arch: uint32(info.ID)}
+			leavesA, err := da.syscall(uint32(data.NR))
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate syscall %d of %v: %w", nr, info.Name, err)
+			}
+			leavesB, err := db.syscall(uint32(data.NR))
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate syscall %d of %v: %w", nr, info.Name, err)
+			}
+
+			var found []FilterDifference
+			var terms []decompiledTerm // The terms of the differences that have one.
+			hasTerm := map[int]bool{}
+			for _, la := range leavesA {
+				for _, lb := range leavesB {
+					if la.action == lb.action {
+						continue
+					}
+					diff, term, ok := diffLeaves(la, lb)
+					if !ok {
+						continue
+					}
+					diff.Arch, diff.Syscall = info, info.SyscallName(nr)
+					diff.Data.NR, diff.Data.Arch = data.NR, data.Arch
+
+					// Confirm the difference with the actual decisions.
+					if diff.A, err = emulatorA.Run(diff.Data); err != nil {
+						return nil, err
+					}
+					if diff.B, err = emulatorB.Run(diff.Data); err != nil {
+						return nil, err
+					}
+					if diff.A == diff.B {
+						continue
+					}
+					if term == nil {
+						found = append(found, diff)
+						continue
+					}
+					// The terms are merged by the pair of actions, so their
+					// action refers to the first difference with the pair.
+					term.action = Action(len(found))
+					for i, d := range found {
+						if hasTerm[i] && d.A == diff.A && d.B == diff.B {
+							term.action = Action(i)
+							break
+						}
+					}
+					hasTerm[len(found)] = true
+					found = append(found, diff)
+					terms = append(terms, *term)
+				}
+			}
+
+			seen := map[string]bool{}
+			for i, d := range found {
+				if hasTerm[i] {
+					continue
+				}
+				if key := d.String(); !seen[key] {
+					seen[key] = true
+					diffs = append(diffs, d)
+				}
+			}
+			for _, t := range mergeTerms(terms) {
+				d := found[t.action]
+				d.Conditions = t.conditions(da.limit)
+				d.Partial = len(d.Conditions) > 0
+				if key := d.String(); !seen[key] {
+					seen[key] = true
+					diffs = append(diffs, d)
+				}
+			}
+		}
+	}
+	return diffs, nil
+}
+
+// diffLeaves returns an example input for which both paths are taken, and
+// the term of these inputs if it can be expressed. It returns false if no
+// example input is found.
+func diffLeaves(a, b leaf) (FilterDifference, *decompiledTerm, bool) {
+	var diff FilterDifference
+	l := leaf{action: a.action, opaque: a.opaque || b.opaque}
+	for i := range l.words {
+		for half := range l.words[i] {
+			w := a.words[i][half].intersect(b.words[i][half])
+			v, ok := w.sample()
+			if !ok {
+				return diff, nil, false
+			}
+			l.words[i][half] = w
+			if half == 1 {
+				diff.Data.Args[i] |= uint64(v) << 32
+			} else {
+				diff.Data.Args[i] |= uint64(v)
+			}
+		}
+	}
+
+	diff.Partial = true
+	if terms, err := leafTerms(l); err == nil && !l.opaque && len(terms) == 1 {
+		return diff, &terms[0], true
+	}
+	return diff, nil, true
+}
+
+// intersect returns the values that are in both domains.
+func (w wordDomain) intersect(o wordDomain) wordDomain {
+	w.min, w.max = max(w.min, o.min), min(w.max, o.max)
+	w.none |= o.none
+	w.excluded = appendMissing(w.excluded, o.excluded)
+	w.any = appendMissing(w.any, o.any)
+	return w
+}
+
+// appendMissing returns a new slice with the values of b that are not in a
+// appended to a.
+func appendMissing(a, b []uint32) []uint32 {
+	out := a[:len(a):len(a)]
+	for _, v := range b {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// sample returns a value in the domain. It tries a few candidates and may
+// fail for domains that are not empty.
+func (w wordDomain) sample() (uint32, bool) {
+	if w.min > w.max {
+		return 0, false
+	}
+
+	// The smallest value with the required bits.
+	withBits := w.min &^ w.none
+	for _, mask := range w.any {
+		if withBits&mask == 0 {
+			allowed := mask &^ w.none
+			withBits |= allowed & -allowed
+		}
+	}
+
+	candidates := []uint32{w.min, w.max, withBits, w.max &^ w.none}
+	for i := uint32(1); i <= 64 && w.min+i > w.min && w.min+i <= w.max; i++ {
+		candidates = append(candidates, w.min+i)
+	}
+	for _, v := range candidates {
+		if v >= w.min && v <= w.max && valueMatches(uint64(v), toUint64(w.excluded), uint64(w.none), toUint64(w.any)) {
+			return v, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestDiffPrograms(t *testing.T) {
+	policy := func(names []string, value uint64) *Policy {
+		return &Policy{
+			arch:          arch.X86_64,
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{
+				{Names: names, Action: ActionErrno},
+				{
+					Action: ActionLog,
+					NamesWithCondtions: []NameWithConditions{
+						{Name: "write", Conditions: ArgumentConditions{{Argument: 1, Operation: GreaterThan, Value: value}}},
+					},
+				},
+			},
+		}
+	}
+	a := mustAssemble(t, policy([]string{"execve"}, 5))
+	b := mustAssemble(t, policy([]string{"execve", "ptrace"}, 10))
+
+	diffs, err := DiffPrograms(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+
+	diffs, err = DiffPrograms(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"x86_64 write: log -> allow when arg1 is at least 0x6 and arg1 is at most 0xa",
+		"x86_64 ptrace: allow -> errno(1)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if d := diffs[0]; !d.Partial || d.Data.Args[1] < 6 || d.Data.Args[1] > 10 {
+		t.Errorf("unexpected example input %+v", d)
+	}
+
+	// A different default action changes the undefined syscalls too.
+	c := policy([]string{"execve"}, 5)
+	c.DefaultAction = ActionKillProcess
+	diffs, err = DiffPrograms(a, mustAssemble(t, c), arch.X86_64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := diffs[len(diffs)-1].String(); last != "x86_64 undefined syscalls: allow -> kill_process" {
+		t.Errorf("unexpected last difference %q", last)
+	}
+}
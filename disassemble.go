@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// WriteDisassembly writes the instructions of a seccomp BPF program with
+// comments that name the loaded fields, the compared syscalls and arches,
+// the jump targets, and the returned actions. The syscall names are looked
+// up for the arch, which is detected from the program if nil.
+func WriteDisassembly(w io.Writer, insts []bpf.Instruction, info *arch.Info) error {
+	if info == nil {
+		if arches := ProgramArches(insts); len(arches) > 0 {
+			info = arches[0]
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	loaded := -1 // Offset of the field in A, -1 if unknown.
+	for i, inst := range insts {
+		var comment string
+		switch inst := inst.(type) {
+		case bpf.LoadAbsolute:
+			loaded = int(inst.Off)
+			comment = fieldName(inst.Off)
+		case bpf.JumpIf:
+			comment = fmt.Sprintf("goto %d else %d", i+1+int(inst.SkipTrue), i+1+int(inst.SkipFalse))
+			if inst.Cond != bpf.JumpEqual && inst.Cond != bpf.JumpNotEqual {
+				break
+			}
+			if name := comparedName(loaded, inst.Val, info); name != "" {
+				comment = name + ", " + comment
+			}
+		case bpf.Jump:
+			comment = fmt.Sprintf("goto %d", i+1+int(inst.Skip))
+		case bpf.RetConstant:
+			comment = Action(inst.Val).String()
+		default:
+			loaded = -1
+		}
+
+		if comment == "" {
+			fmt.Fprintf(bw, "%4d: %v\n", i, inst)
+		} else {
+			fmt.Fprintf(bw, "%4d: %-32v # %s\n", i, inst, comment)
+		}
+	}
+	return bw.Flush()
+}
+
+// fieldName returns the name of the seccomp_data field at the offset.
+func fieldName(off uint32) string {
+	switch {
+	case off == syscallNumOffset:
+		return "syscall number"
+	case off == archOffset:
+		return "arch"
+	case off < argumentOffset:
+		return "instruction pointer"
+	case off < sizeOfSeccompData:
+		word := "lo"
+		if (off%sizeOfUint64 != 0) == (nativeEndian == binary.LittleEndian) {
+			word = "hi"
+		}
+		return fmt.Sprintf("arg%d %s", (off-argumentOffset)/sizeOfUint64, word)
+	}
+	return ""
+}
+
+// comparedName returns the name of the syscall or arch that is compared to
+// the field at the offset.
+func comparedName(off int, val uint32, info *arch.Info) string {
+	switch off {
+	case syscallNumOffset:
+		if info == nil {
+			return ""
+		}
+		if info.ID == arch.X86_64.ID && val&uint32(arch.X32.SeccompMask) != 0 {
+			if name := arch.X32.SyscallName(int(val &^ uint32(arch.X32.SeccompMask))); name != "" {
+				return "x32 " + name
+			}
+			return ""
+		}
+		return info.SyscallName(int(val))
+	case archOffset:
+		for _, a := range knownArches {
+			if uint32(a.ID) == val {
+				return arch.AuditArch(val).String()
+			}
+		}
+	}
+	return ""
+}
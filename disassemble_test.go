@@ -0,0 +1,53 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestWriteDisassembly(t *testing.T) {
+	policy := &Policy{
+		arch:          arch.AARCH64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{Names: []string{"execve"}, Action: ActionErrno},
+			{
+				Action: ActionLog,
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "write", Conditions: ArgumentConditions{{Argument: 1, Operation: GreaterThan, Value: 5}}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDisassembly(&buf, mustAssemble(t, policy), nil); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, comment := range []string{"# arch\n", "# aarch64, goto", "# syscall number\n", "# execve, goto", "# write, goto", "# arg1 hi\n", "# arg1 lo\n", "# errno(1)\n", "# log\n", "# allow\n"} {
+		if !strings.Contains(out, comment) {
+			t.Errorf("missing %q in\n%v", comment, out)
+		}
+	}
+}
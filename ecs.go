@@ -0,0 +1,207 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// ECSVersion is the version of the Elastic Common Schema that MarshalECS
+// follows.
+const ECSVersion = "8.11.0"
+
+// Sources of violations.
+const (
+	ViolationSourceAudit      = "audit"      // SECCOMP record of the audit log.
+	ViolationSourceSIGSYS     = "sigsys"     // SIGSYS signal raised by ActionTrap.
+	ViolationSourceSupervisor = "supervisor" // User-space notification.
+)
+
+// Violation is a syscall that a seccomp filter did not simply allow.
+type Violation struct {
+	Time    time.Time
+	Source  string     // Reporter of the violation, one of the ViolationSource constants.
+	PID     int        // Thread ID of the caller, 0 if unknown.
+	Comm    string     // Command name of the process.
+	Exe     string     // Path of the executable.
+	Arch    *arch.Info // Architecture of the syscall, nil if unknown.
+	Syscall string     // Name of the syscall, empty if unknown.
+	Action  Action     // Action taken by the filter.
+
+	// Data holds the syscall number and arguments if they are known.
+	Data *SeccompData
+}
+
+// Violation returns the record as a violation.
+func (r *AuditRecord) Violation() Violation {
+	return Violation{
+		Time:    r.Time,
+		Source:  ViolationSourceAudit,
+		PID:     r.PID,
+		Comm:    r.Comm,
+		Exe:     r.Exe,
+		Arch:    r.Arch,
+		Syscall: r.Syscall,
+		Action:  r.Code,
+	}
+}
+
+// ecsDocument is the Elastic Common Schema representation of a violation.
+// Fields that are not defined by ECS are in the seccomp namespace.
+type ecsDocument struct {
+	Timestamp time.Time `json:"@timestamp"`
+	Message   string    `json:"message"`
+	ECS       struct {
+		Version string `json:"version"`
+	} `json:"ecs"`
+	Event struct {
+		Kind     string   `json:"kind"`
+		Category []string `json:"category"`
+		Type     []string `json:"type"`
+		Action   string   `json:"action"`
+		Outcome  string   `json:"outcome"`
+		Module   string   `json:"module"`
+		Dataset  string   `json:"dataset"`
+		Provider string   `json:"provider,omitempty"`
+	} `json:"event"`
+	Process struct {
+		PID        int    `json:"pid,omitempty"`
+		Name       string `json:"name,omitempty"`
+		Executable string `json:"executable,omitempty"`
+	} `json:"process"`
+	Seccomp struct {
+		Action  string `json:"action"`
+		Code    string `json:"code"`
+		Arch    string `json:"arch,omitempty"`
+		Syscall struct {
+			Name   string   `json:"name,omitempty"`
+			Number *int32   `json:"number,omitempty"`
+			Args   []string `json:"args,omitempty"`
+		} `json:"syscall"`
+	} `json:"seccomp"`
+}
+
+// MarshalECS returns the violation as an Elastic Common Schema document in
+// JSON. The event is categorized as denied, except for ActionLog and
+// ActionAllow, which are allowed, and ActionUserNotify, whose outcome is
+// decided by the supervisor.
+func (v Violation) MarshalECS() ([]byte, error) {
+	var doc ecsDocument
+	doc.Timestamp = v.Time.UTC()
+	if v.Time.IsZero() {
+		doc.Timestamp = time.Now().UTC()
+	}
+	doc.ECS.Version = ECSVersion
+
+	doc.Event.Kind = "event"
+	doc.Event.Category = []string{"process"}
+	doc.Event.Action = "seccomp-" + (v.Action & actionMask).String()
+	doc.Event.Module = "seccomp"
+	doc.Event.Dataset = "seccomp.violation"
+	doc.Event.Provider = v.Source
+	switch v.Action & actionMask {
+	case ActionLog, ActionAllow:
+		doc.Event.Type = []string{"allowed"}
+		doc.Event.Outcome = "success"
+	case ActionUserNotify:
+		doc.Event.Type = []string{"info"}
+		doc.Event.Outcome = "unknown"
+	default:
+		doc.Event.Type = []string{"denied"}
+		doc.Event.Outcome = "failure"
+	}
+
+	doc.Process.PID = v.PID
+	doc.Process.Name = v.Comm
+	doc.Process.Executable = v.Exe
+
+	doc.Seccomp.Action = v.Action.String()
+	doc.Seccomp.Code = fmt.Sprintf("0x%08x", uint32(v.Action.returnValue()))
+	if v.Arch != nil {
+		doc.Seccomp.Arch = v.Arch.Name
+	} else if v.Data != nil {
+		doc.Seccomp.Arch = fmt.Sprintf("0x%08x", v.Data.Arch)
+	}
+	doc.Seccomp.Syscall.Name = v.Syscall
+	if v.Data != nil {
+		nr := v.Data.NR
+		doc.Seccomp.Syscall.Number = &nr
+		// Arguments are hex strings because they do not fit into a signed
+		// long field.
+		doc.Seccomp.Syscall.Args = make([]string, len(v.Data.Args))
+		for i, a := range v.Data.Args {
+			doc.Seccomp.Syscall.Args[i] = fmt.Sprintf("0x%x", a)
+		}
+	}
+
+	doc.Message = v.message()
+	return json.Marshal(doc)
+}
+
+// message returns a summary such as "seccomp errno(1) for openat by pid 42 (cat)".
+func (v Violation) message() string {
+	syscall := v.Syscall
+	if syscall == "" {
+		if v.Data != nil {
+			syscall = fmt.Sprintf("syscall %d", v.Data.NR)
+		} else {
+			syscall = "unknown syscall"
+		}
+	}
+	msg := fmt.Sprintf("seccomp %v for %s", v.Action, syscall)
+	if v.PID != 0 {
+		msg += fmt.Sprintf(" by pid %d", v.PID)
+	}
+	if v.Comm != "" {
+		msg += fmt.Sprintf(" (%s)", v.Comm)
+	}
+	return msg
+}
+
+// ECSWriter writes violations as newline delimited ECS documents, the
+// format that Filebeat and Elastic Agent ingest with the ndjson parser. It is
+// safe for concurrent use.
+type ECSWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewECSWriter returns an ECSWriter that writes to w.
+func NewECSWriter(w io.Writer) *ECSWriter {
+	return &ECSWriter{w: w}
+}
+
+// Write writes the violation as one line.
+func (e *ECSWriter) Write(v Violation) error {
+	data, err := v.MarshalECS()
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(data)
+	return err
+}
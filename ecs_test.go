@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestViolationMarshalECS(t *testing.T) {
+	record, _, err := ParseAuditRecord(strings.Split(testAuditLog, "\n")[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := record.Violation().MarshalECS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{
+		"@timestamp": "2023-11-14T22:13:20.125Z",
+		"message": "seccomp errno(1) for mount by pid 1234 (my app)",
+		"ecs": {"version": "8.11.0"},
+		"event": {
+			"kind": "event",
+			"category": ["process"],
+			"type": ["denied"],
+			"action": "seccomp-errno",
+			"outcome": "failure",
+			"module": "seccomp",
+			"dataset": "seccomp.violation",
+			"provider": "audit"
+		},
+		"process": {"pid": 1234, "name": "my app", "executable": "/opt/my app"},
+		"seccomp": {
+			"action": "errno(1)",
+			"code": "0x00050001",
+			"arch": "x86_64",
+			"syscall": {"name": "mount"}
+		}
+	}`
+	assertJSONEqual(t, want, data)
+}
+
+func TestViolationMarshalECSWithData(t *testing.T) {
+	v := Violation{
+		Time:   time.Unix(1700000000, 0),
+		Source: ViolationSourceSIGSYS,
+		PID:    42,
+		Arch:   arch.X86_64,
+		Action: ActionTrap,
+		Data:   &SeccompData{NR: 1000, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{1, 0xffffffffffffffff}},
+	}
+
+	data, err := v.MarshalECS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Message string `json:"message"`
+		Seccomp struct {
+			Syscall struct {
+				Number int32    `json:"number"`
+				Args   []string `json:"args"`
+			} `json:"syscall"`
+		} `json:"seccomp"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if want := "seccomp trap for syscall 1000 by pid 42"; doc.Message != want {
+		t.Errorf("expected message %q, got %q", want, doc.Message)
+	}
+	if doc.Seccomp.Syscall.Number != 1000 {
+		t.Errorf("expected number 1000, got %d", doc.Seccomp.Syscall.Number)
+	}
+	if want := []string{"0x1", "0xffffffffffffffff", "0x0", "0x0", "0x0", "0x0"}; !reflect.DeepEqual(doc.Seccomp.Syscall.Args, want) {
+		t.Errorf("expected args %v, got %v", want, doc.Seccomp.Syscall.Args)
+	}
+}
+
+func TestECSWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewECSWriter(&buf)
+	for _, action := range []Action{ActionLog, ActionKillProcess} {
+		if err := w.Write(Violation{Syscall: "ptrace", Action: action}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	for i, outcome := range []string{"success", "failure"} {
+		var doc struct {
+			Event struct {
+				Outcome string `json:"outcome"`
+			} `json:"event"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Event.Outcome != outcome {
+			t.Errorf("line %d: expected outcome %v, got %v", i, outcome, doc.Event.Outcome)
+		}
+	}
+}
+
+func assertJSONEqual(t *testing.T, want string, got []byte) {
+	t.Helper()
+
+	var w, g interface{}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(w, g) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,630 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// ErrStaticELF is returned by AnalyzeELF for binaries that do not import
+// any symbols, such as statically linked or Go binaries.
+var ErrStaticELF = errors.New("ELF binary is statically linked")
+
+// ELFAnalysis is the result of AnalyzeELF.
+type ELFAnalysis struct {
+	Arch        *arch.Info // Architecture of the binary, nil if unsupported.
+	Interpreter string     // Dynamic loader (PT_INTERP).
+	Libraries   []string   // Needed shared libraries (DT_NEEDED).
+	Imports     []string   // Sorted names of the imported functions.
+
+	// Unmapped are the imported functions that are not known to make
+	// syscalls. Most are pure library functions, but functions of other
+	// libraries than libc are not analyzed.
+	Unmapped []string
+
+	// Indirect is true if the binary imports syscall(3), which makes
+	// arbitrary syscalls that can not be found statically.
+	Indirect bool
+
+	// Trace counts, for each syscall, the imported functions that can make
+	// it. It also contains the syscalls of the dynamic loader and libc
+	// startup.
+	Trace *SyscallTrace
+}
+
+// AnalyzeELFFile opens the binary at path and analyzes it with AnalyzeELF.
+func AnalyzeELFFile(path string) (*ELFAnalysis, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return AnalyzeELF(f)
+}
+
+// AnalyzeELF inspects the functions that a dynamically linked ELF binary
+// imports and maps the known libc wrappers to the syscalls they make. This
+// gives a starting point for the profile of a third-party binary without
+// running it. The analysis over-approximates the syscalls of the wrappers
+// the binary uses, but misses syscalls made by other libraries or through
+// syscall(3) (see Indirect), so the draft policy must be reviewed and
+// tested.
+func AnalyzeELF(r io.ReaderAt) (*ELFAnalysis, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &ELFAnalysis{
+		Arch:  elfArch(f),
+		Trace: NewSyscallTrace(),
+	}
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			data, err := io.ReadAll(prog.Open())
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ELF interpreter: %w", err)
+			}
+			a.Interpreter = strings.TrimRight(string(data), "\x00")
+		}
+	}
+	if a.Libraries, err = f.ImportedLibraries(); err != nil {
+		return nil, fmt.Errorf("failed to read ELF libraries: %w", err)
+	}
+
+	symbols, err := f.ImportedSymbols()
+	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
+		return nil, fmt.Errorf("failed to read ELF symbols: %w", err)
+	}
+	if len(symbols) == 0 {
+		return nil, ErrStaticELF
+	}
+
+	seen := map[string]bool{}
+	for _, sym := range symbols {
+		if seen[sym.Name] {
+			continue
+		}
+		seen[sym.Name] = true
+		a.Imports = append(a.Imports, sym.Name)
+
+		if sym.Name == "syscall" {
+			a.Indirect = true
+			continue
+		}
+		syscalls, found := libcWrapperSyscalls(sym.Name)
+		if !found {
+			a.Unmapped = append(a.Unmapped, sym.Name)
+			continue
+		}
+		for _, name := range syscalls {
+			a.Trace.AddCount(name, 1)
+		}
+	}
+	sort.Strings(a.Imports)
+	sort.Strings(a.Unmapped)
+
+	for _, name := range elfStartupSyscalls {
+		a.Trace.AddCount(name, 1)
+	}
+	return a, nil
+}
+
+// Policy returns a draft policy that allows the syscalls of the analysis.
+// See SyscallTrace.Policy. The arch defaults to the arch of the binary.
+func (a *ELFAnalysis) Policy(opts DraftOptions) (*Policy, error) {
+	if opts.Arch == "" && a.Arch != nil {
+		opts.Arch = a.Arch.Name
+	}
+	return a.Trace.Policy(opts)
+}
+
+// elfArch returns the architecture of the binary or nil if it is not
+// supported.
+func elfArch(f *elf.File) *arch.Info {
+	switch f.Machine {
+	case elf.EM_X86_64:
+		if f.Class == elf.ELFCLASS32 {
+			return arch.X32
+		}
+		return arch.X86_64
+	case elf.EM_386:
+		return arch.I386
+	case elf.EM_ARM:
+		return arch.ARM
+	case elf.EM_AARCH64:
+		return arch.AARCH64
+	}
+	return nil
+}
+
+// libcWrapperSyscalls returns the syscalls that a libc function can make.
+// Fortified (__read_chk), large file (open64), and internal (__open_2)
+// variants map to the syscalls of the base function.
+func libcWrapperSyscalls(symbol string) ([]string, bool) {
+	name := symbol
+	for {
+		if syscalls, found := libcWrappers[name]; found {
+			return syscalls, true
+		}
+		trimmed := strings.TrimPrefix(name, "__")
+		for _, suffix := range []string{"_chk", "_2", "64"} {
+			trimmed = strings.TrimSuffix(trimmed, suffix)
+		}
+		if trimmed == name || trimmed == "" {
+			return nil, false
+		}
+		name = trimmed
+	}
+}
+
+// elfStartupSyscalls are made by the dynamic loader and the libc startup and
+// exit code of every dynamically linked binary.
+var elfStartupSyscalls = []string{
+	"access", "arch_prctl", "brk", "close", "execve", "exit_group", "fstat",
+	"fstat64", "getrandom", "mmap", "mmap2", "mprotect", "munmap",
+	"newfstatat", "openat", "pread64", "prlimit64", "read", "rseq",
+	"set_robust_list", "set_thread_area", "set_tid_address", "statx",
+	"ugetrlimit",
+}
+
+// libcWrappers maps libc functions (glibc and musl) to the syscalls they can
+// make on any architecture. Syscalls that do not exist on an architecture
+// are ignored by SyscallTrace.Policy.
+var libcWrappers = map[string][]string{
+	// Files.
+	"open":            {"open", "openat"},
+	"openat":          {"openat"},
+	"creat":           {"creat", "openat"},
+	"fopen":           {"open", "openat", "fstat", "newfstatat", "fstat64", "statx"},
+	"fdopen":          {"fcntl", "fcntl64"},
+	"freopen":         {"open", "openat", "dup2", "dup3", "close"},
+	"close":           {"close"},
+	"fclose":          {"close"},
+	"read":            {"read"},
+	"fread":           {"read"},
+	"fgets":           {"read"},
+	"fgetc":           {"read"},
+	"getc":            {"read"},
+	"getline":         {"read"},
+	"getdelim":        {"read"},
+	"fscanf":          {"read"},
+	"scanf":           {"read"},
+	"write":           {"write"},
+	"fwrite":          {"write"},
+	"fputs":           {"write"},
+	"fputc":           {"write"},
+	"putc":            {"write"},
+	"puts":            {"write"},
+	"putchar":         {"write"},
+	"printf":          {"write"},
+	"vprintf":         {"write"},
+	"fprintf":         {"write"},
+	"vfprintf":        {"write"},
+	"dprintf":         {"write"},
+	"perror":          {"write"},
+	"fflush":          {"write"},
+	"pread":           {"pread64"},
+	"pwrite":          {"pwrite64"},
+	"readv":           {"readv"},
+	"writev":          {"writev"},
+	"preadv":          {"preadv", "preadv2"},
+	"pwritev":         {"pwritev", "pwritev2"},
+	"lseek":           {"lseek", "_llseek"},
+	"fseek":           {"lseek", "_llseek"},
+	"fseeko":          {"lseek", "_llseek"},
+	"ftell":           {"lseek", "_llseek"},
+	"ftello":          {"lseek", "_llseek"},
+	"rewind":          {"lseek", "_llseek"},
+	"stat":            {"stat", "stat64", "newfstatat", "fstatat64", "statx"},
+	"lstat":           {"lstat", "lstat64", "newfstatat", "fstatat64", "statx"},
+	"fstat":           {"fstat", "fstat64", "newfstatat", "fstatat64", "statx"},
+	"fstatat":         {"newfstatat", "fstatat64", "statx"},
+	"xstat":           {"stat", "stat64", "newfstatat", "fstatat64", "statx"},
+	"lxstat":          {"lstat", "lstat64", "newfstatat", "fstatat64", "statx"},
+	"fxstat":          {"fstat", "fstat64", "newfstatat", "fstatat64", "statx"},
+	"fxstatat":        {"newfstatat", "fstatat64", "statx"},
+	"statx":           {"statx"},
+	"statfs":          {"statfs", "statfs64"},
+	"fstatfs":         {"fstatfs", "fstatfs64"},
+	"statvfs":         {"statfs", "statfs64"},
+	"fstatvfs":        {"fstatfs", "fstatfs64"},
+	"access":          {"access", "faccessat", "faccessat2"},
+	"faccessat":       {"faccessat", "faccessat2"},
+	"euidaccess":      {"access", "faccessat", "faccessat2"},
+	"fcntl":           {"fcntl", "fcntl64"},
+	"ioctl":           {"ioctl"},
+	"isatty":          {"ioctl"},
+	"tcgetattr":       {"ioctl"},
+	"tcsetattr":       {"ioctl"},
+	"dup":             {"dup"},
+	"dup2":            {"dup2", "dup3"},
+	"dup3":            {"dup3"},
+	"pipe":            {"pipe", "pipe2"},
+	"pipe2":           {"pipe2"},
+	"fsync":           {"fsync"},
+	"fdatasync":       {"fdatasync"},
+	"sync":            {"sync"},
+	"syncfs":          {"syncfs"},
+	"truncate":        {"truncate", "truncate64"},
+	"ftruncate":       {"ftruncate", "ftruncate64"},
+	"fallocate":       {"fallocate"},
+	"posix_fadvise":   {"fadvise64", "fadvise64_64", "arm_fadvise64_64"},
+	"sendfile":        {"sendfile", "sendfile64"},
+	"splice":          {"splice"},
+	"tee":             {"tee"},
+	"copy_file_range": {"copy_file_range"},
+	"flock":           {"flock"},
+	"lockf":           {"fcntl", "fcntl64"},
+	"mkstemp":         {"open", "openat"},
+	"mkostemp":        {"open", "openat"},
+	"tmpfile":         {"open", "openat", "unlink", "unlinkat"},
+	"mkdtemp":         {"mkdir", "mkdirat"},
+	"umask":           {"umask"},
+
+	// Directories and paths.
+	"opendir":           {"open", "openat", "fstat", "newfstatat", "fstat64", "statx"},
+	"fdopendir":         {"fstat", "newfstatat", "fstat64", "statx", "fcntl", "fcntl64"},
+	"readdir":           {"getdents", "getdents64"},
+	"readdir_r":         {"getdents", "getdents64"},
+	"closedir":          {"close"},
+	"scandir":           {"open", "openat", "getdents", "getdents64", "close"},
+	"nftw":              {"open", "openat", "getdents", "getdents64", "close", "lstat", "newfstatat", "statx", "fchdir", "chdir"},
+	"ftw":               {"open", "openat", "getdents", "getdents64", "close", "lstat", "newfstatat", "statx", "fchdir", "chdir"},
+	"fts_open":          {"open", "openat", "getdents", "getdents64", "close", "lstat", "newfstatat", "statx", "fchdir"},
+	"fts_read":          {"open", "openat", "getdents", "getdents64", "close", "lstat", "newfstatat", "statx", "fchdir"},
+	"mkdir":             {"mkdir", "mkdirat"},
+	"mkdirat":           {"mkdirat"},
+	"rmdir":             {"rmdir", "unlinkat"},
+	"unlink":            {"unlink", "unlinkat"},
+	"unlinkat":          {"unlinkat"},
+	"remove":            {"unlink", "unlinkat", "rmdir"},
+	"rename":            {"rename", "renameat", "renameat2"},
+	"renameat":          {"renameat", "renameat2"},
+	"renameat2":         {"renameat2"},
+	"link":              {"link", "linkat"},
+	"linkat":            {"linkat"},
+	"symlink":           {"symlink", "symlinkat"},
+	"symlinkat":         {"symlinkat"},
+	"readlink":          {"readlink", "readlinkat"},
+	"readlinkat":        {"readlinkat"},
+	"realpath":          {"readlink", "readlinkat", "lstat", "newfstatat", "statx", "getcwd"},
+	"getcwd":            {"getcwd"},
+	"chdir":             {"chdir"},
+	"fchdir":            {"fchdir"},
+	"chroot":            {"chroot"},
+	"chmod":             {"chmod", "fchmodat"},
+	"fchmod":            {"fchmod"},
+	"fchmodat":          {"fchmodat", "fchmodat2"},
+	"chown":             {"chown", "chown32", "fchownat"},
+	"lchown":            {"lchown", "lchown32", "fchownat"},
+	"fchown":            {"fchown", "fchown32"},
+	"fchownat":          {"fchownat"},
+	"utime":             {"utime", "utimes", "utimensat"},
+	"utimes":            {"utimes", "utimensat"},
+	"utimensat":         {"utimensat"},
+	"futimens":          {"utimensat"},
+	"mknod":             {"mknod", "mknodat"},
+	"mkfifo":            {"mknod", "mknodat"},
+	"inotify_init":      {"inotify_init", "inotify_init1"},
+	"inotify_init1":     {"inotify_init1"},
+	"inotify_add_watch": {"inotify_add_watch"},
+	"inotify_rm_watch":  {"inotify_rm_watch"},
+	"getxattr":          {"getxattr"},
+	"lgetxattr":         {"lgetxattr"},
+	"fgetxattr":         {"fgetxattr"},
+	"setxattr":          {"setxattr"},
+	"lsetxattr":         {"lsetxattr"},
+	"fsetxattr":         {"fsetxattr"},
+	"listxattr":         {"listxattr"},
+	"llistxattr":        {"llistxattr"},
+	"flistxattr":        {"flistxattr"},
+	"removexattr":       {"removexattr"},
+	"mount":             {"mount"},
+	"umount":            {"umount2"},
+	"umount2":           {"umount2"},
+
+	// Memory.
+	"malloc":         {"brk", "mmap", "mmap2", "munmap"},
+	"calloc":         {"brk", "mmap", "mmap2", "munmap"},
+	"realloc":        {"brk", "mmap", "mmap2", "munmap", "mremap"},
+	"free":           {"brk", "munmap", "madvise"},
+	"posix_memalign": {"brk", "mmap", "mmap2", "munmap"},
+	"aligned_alloc":  {"brk", "mmap", "mmap2", "munmap"},
+	"memalign":       {"brk", "mmap", "mmap2", "munmap"},
+	"mmap":           {"mmap", "mmap2"},
+	"munmap":         {"munmap"},
+	"mremap":         {"mremap"},
+	"mprotect":       {"mprotect"},
+	"madvise":        {"madvise"},
+	"posix_madvise":  {"madvise"},
+	"mlock":          {"mlock", "mlock2"},
+	"munlock":        {"munlock"},
+	"mlockall":       {"mlockall"},
+	"munlockall":     {"munlockall"},
+	"msync":          {"msync"},
+	"mincore":        {"mincore"},
+	"brk":            {"brk"},
+	"sbrk":           {"brk"},
+	"shm_open":       {"open", "openat"},
+	"shm_unlink":     {"unlink", "unlinkat"},
+	"shmget":         {"shmget", "ipc"},
+	"shmat":          {"shmat", "ipc"},
+	"shmdt":          {"shmdt", "ipc"},
+	"shmctl":         {"shmctl", "ipc"},
+	"memfd_create":   {"memfd_create"},
+
+	// Processes.
+	"fork":               {"clone", "fork"},
+	"vfork":              {"vfork", "clone"},
+	"clone":              {"clone", "clone3"},
+	"posix_spawn":        {"clone", "clone3", "vfork", "execve", "wait4", "exit_group"},
+	"posix_spawnp":       {"clone", "clone3", "vfork", "execve", "wait4", "exit_group"},
+	"system":             {"clone", "clone3", "vfork", "execve", "wait4", "rt_sigaction", "rt_sigprocmask"},
+	"popen":              {"clone", "clone3", "vfork", "execve", "pipe2", "wait4"},
+	"pclose":             {"wait4", "close"},
+	"execve":             {"execve"},
+	"execv":              {"execve"},
+	"execvp":             {"execve"},
+	"execvpe":            {"execve"},
+	"execl":              {"execve"},
+	"execlp":             {"execve"},
+	"execle":             {"execve"},
+	"fexecve":            {"execveat", "execve"},
+	"exit":               {"exit_group"},
+	"_exit":              {"exit_group"},
+	"_Exit":              {"exit_group"},
+	"abort":              {"rt_sigprocmask", "rt_sigaction", "tgkill", "gettid", "getpid", "exit_group"},
+	"wait":               {"wait4"},
+	"waitpid":            {"wait4"},
+	"wait3":              {"wait4"},
+	"wait4":              {"wait4"},
+	"waitid":             {"waitid"},
+	"getpid":             {"getpid"},
+	"getppid":            {"getppid"},
+	"gettid":             {"gettid"},
+	"getuid":             {"getuid", "getuid32"},
+	"geteuid":            {"geteuid", "geteuid32"},
+	"getgid":             {"getgid", "getgid32"},
+	"getegid":            {"getegid", "getegid32"},
+	"getgroups":          {"getgroups", "getgroups32"},
+	"setgroups":          {"setgroups", "setgroups32"},
+	"initgroups":         {"setgroups", "setgroups32", "open", "openat", "read", "close"},
+	"setuid":             {"setuid", "setuid32"},
+	"seteuid":            {"setresuid", "setresuid32", "setreuid", "setreuid32"},
+	"setreuid":           {"setreuid", "setreuid32"},
+	"setresuid":          {"setresuid", "setresuid32"},
+	"getresuid":          {"getresuid", "getresuid32"},
+	"setgid":             {"setgid", "setgid32"},
+	"setegid":            {"setresgid", "setresgid32", "setregid", "setregid32"},
+	"setregid":           {"setregid", "setregid32"},
+	"setresgid":          {"setresgid", "setresgid32"},
+	"getresgid":          {"getresgid", "getresgid32"},
+	"setsid":             {"setsid"},
+	"getsid":             {"getsid"},
+	"setpgid":            {"setpgid"},
+	"getpgid":            {"getpgid"},
+	"getpgrp":            {"getpgrp", "getpgid"},
+	"setpgrp":            {"setpgid"},
+	"kill":               {"kill"},
+	"killpg":             {"kill"},
+	"raise":              {"tgkill", "gettid", "getpid", "rt_sigprocmask"},
+	"tgkill":             {"tgkill"},
+	"prctl":              {"prctl"},
+	"getrlimit":          {"getrlimit", "ugetrlimit", "prlimit64"},
+	"setrlimit":          {"setrlimit", "prlimit64"},
+	"prlimit":            {"prlimit64"},
+	"getrusage":          {"getrusage"},
+	"getpriority":        {"getpriority"},
+	"setpriority":        {"setpriority"},
+	"nice":               {"getpriority", "setpriority"},
+	"sched_yield":        {"sched_yield"},
+	"sched_getaffinity":  {"sched_getaffinity"},
+	"sched_setaffinity":  {"sched_setaffinity"},
+	"sched_getparam":     {"sched_getparam"},
+	"sched_setparam":     {"sched_setparam"},
+	"sched_getscheduler": {"sched_getscheduler"},
+	"sched_setscheduler": {"sched_setscheduler"},
+	"sysconf":            {"sched_getaffinity", "open", "openat", "read", "close", "getrlimit", "ugetrlimit", "prlimit64", "sysinfo"},
+	"get_nprocs":         {"sched_getaffinity", "open", "openat", "read", "close"},
+	"ptrace":             {"ptrace"},
+	"personality":        {"personality"},
+	"capget":             {"capget"},
+	"capset":             {"capset"},
+	"unshare":            {"unshare"},
+	"setns":              {"setns"},
+	"pidfd_open":         {"pidfd_open"},
+	"pidfd_send_signal":  {"pidfd_send_signal"},
+	"daemon":             {"clone", "fork", "setsid", "chdir", "open", "openat", "dup2", "dup3", "close", "exit_group"},
+
+	// Threads.
+	"pthread_create":          {"clone", "clone3", "mmap", "mmap2", "mprotect", "munmap", "rt_sigprocmask", "set_robust_list", "rseq"},
+	"pthread_join":            {"futex", "futex_time64", "munmap"},
+	"pthread_detach":          {"munmap"},
+	"pthread_exit":            {"exit", "munmap", "madvise"},
+	"pthread_kill":            {"tgkill", "rt_tgsigqueueinfo"},
+	"pthread_sigmask":         {"rt_sigprocmask"},
+	"pthread_mutex_lock":      {"futex", "futex_time64"},
+	"pthread_mutex_unlock":    {"futex", "futex_time64"},
+	"pthread_mutex_timedlock": {"futex", "futex_time64"},
+	"pthread_cond_wait":       {"futex", "futex_time64"},
+	"pthread_cond_timedwait":  {"futex", "futex_time64"},
+	"pthread_cond_signal":     {"futex", "futex_time64"},
+	"pthread_cond_broadcast":  {"futex", "futex_time64"},
+	"pthread_rwlock_rdlock":   {"futex", "futex_time64"},
+	"pthread_rwlock_wrlock":   {"futex", "futex_time64"},
+	"pthread_rwlock_unlock":   {"futex", "futex_time64"},
+	"pthread_once":            {"futex", "futex_time64"},
+	"pthread_barrier_wait":    {"futex", "futex_time64"},
+	"pthread_setname_np":      {"prctl", "open", "openat", "write", "close"},
+	"pthread_getname_np":      {"prctl", "open", "openat", "read", "close"},
+	"pthread_setaffinity_np":  {"sched_setaffinity"},
+	"pthread_getaffinity_np":  {"sched_getaffinity"},
+	"pthread_getattr_np":      {"sched_getaffinity", "open", "openat", "read", "close", "getrlimit", "ugetrlimit", "prlimit64"},
+	"sem_wait":                {"futex", "futex_time64"},
+	"sem_timedwait":           {"futex", "futex_time64"},
+	"sem_post":                {"futex", "futex_time64"},
+	"sem_open":                {"open", "openat", "mmap", "mmap2", "close"},
+
+	// Signals.
+	"signal":       {"rt_sigaction"},
+	"sigaction":    {"rt_sigaction"},
+	"sigprocmask":  {"rt_sigprocmask"},
+	"sigsuspend":   {"rt_sigsuspend"},
+	"sigpending":   {"rt_sigpending"},
+	"sigwait":      {"rt_sigtimedwait", "rt_sigtimedwait_time64"},
+	"sigwaitinfo":  {"rt_sigtimedwait", "rt_sigtimedwait_time64"},
+	"sigtimedwait": {"rt_sigtimedwait", "rt_sigtimedwait_time64"},
+	"sigqueue":     {"rt_sigqueueinfo"},
+	"sigaltstack":  {"sigaltstack"},
+	"signalfd":     {"signalfd", "signalfd4"},
+	"pause":        {"pause", "rt_sigsuspend", "ppoll", "ppoll_time64"},
+	"alarm":        {"alarm", "setitimer"},
+	"setitimer":    {"setitimer"},
+	"getitimer":    {"getitimer"},
+	"siglongjmp":   {"rt_sigprocmask"},
+	"sigsetjmp":    {"rt_sigprocmask"},
+
+	// Time.
+	"time":            {"time", "clock_gettime", "clock_gettime64"},
+	"gettimeofday":    {"gettimeofday", "clock_gettime", "clock_gettime64"},
+	"clock_gettime":   {"clock_gettime", "clock_gettime64"},
+	"clock_getres":    {"clock_getres", "clock_getres_time64"},
+	"clock_settime":   {"clock_settime", "clock_settime64"},
+	"clock_nanosleep": {"clock_nanosleep", "clock_nanosleep_time64"},
+	"nanosleep":       {"nanosleep", "clock_nanosleep", "clock_nanosleep_time64"},
+	"sleep":           {"nanosleep", "clock_nanosleep", "clock_nanosleep_time64"},
+	"usleep":          {"nanosleep", "clock_nanosleep", "clock_nanosleep_time64"},
+	"settimeofday":    {"settimeofday"},
+	"adjtimex":        {"adjtimex"},
+	"localtime":       {"open", "openat", "read", "close", "fstat", "newfstatat", "fstat64", "statx"},
+	"localtime_r":     {"open", "openat", "read", "close", "fstat", "newfstatat", "fstat64", "statx"},
+	"mktime":          {"open", "openat", "read", "close", "fstat", "newfstatat", "fstat64", "statx"},
+	"tzset":           {"open", "openat", "read", "close", "fstat", "newfstatat", "fstat64", "statx"},
+	"timer_create":    {"timer_create"},
+	"timer_settime":   {"timer_settime", "timer_settime64"},
+	"timer_delete":    {"timer_delete"},
+	"timerfd_create":  {"timerfd_create"},
+	"timerfd_settime": {"timerfd_settime", "timerfd_settime64"},
+	"times":           {"times"},
+	"clock":           {"clock_gettime", "clock_gettime64"},
+
+	// I/O multiplexing and events.
+	"select":        {"select", "_newselect", "pselect6", "pselect6_time64"},
+	"pselect":       {"pselect6", "pselect6_time64"},
+	"poll":          {"poll", "ppoll", "ppoll_time64"},
+	"ppoll":         {"ppoll", "ppoll_time64"},
+	"epoll_create":  {"epoll_create", "epoll_create1"},
+	"epoll_create1": {"epoll_create1"},
+	"epoll_ctl":     {"epoll_ctl"},
+	"epoll_wait":    {"epoll_wait", "epoll_pwait"},
+	"epoll_pwait":   {"epoll_pwait"},
+	"epoll_pwait2":  {"epoll_pwait2"},
+	"eventfd":       {"eventfd", "eventfd2"},
+	"eventfd_read":  {"read"},
+	"eventfd_write": {"write"},
+	"io_setup":      {"io_setup"},
+	"io_submit":     {"io_submit"},
+	"io_getevents":  {"io_getevents", "io_pgetevents"},
+
+	// Network.
+	"socket":         {"socket", "socketcall"},
+	"socketpair":     {"socketpair", "socketcall"},
+	"bind":           {"bind", "socketcall"},
+	"listen":         {"listen", "socketcall"},
+	"accept":         {"accept", "accept4", "socketcall"},
+	"accept4":        {"accept4", "socketcall"},
+	"connect":        {"connect", "socketcall"},
+	"shutdown":       {"shutdown", "socketcall"},
+	"send":           {"sendto", "send", "socketcall"},
+	"sendto":         {"sendto", "socketcall"},
+	"sendmsg":        {"sendmsg", "socketcall"},
+	"sendmmsg":       {"sendmmsg", "socketcall"},
+	"recv":           {"recvfrom", "recv", "socketcall"},
+	"recvfrom":       {"recvfrom", "socketcall"},
+	"recvmsg":        {"recvmsg", "socketcall"},
+	"recvmmsg":       {"recvmmsg", "recvmmsg_time64", "socketcall"},
+	"getsockopt":     {"getsockopt", "socketcall"},
+	"setsockopt":     {"setsockopt", "socketcall"},
+	"getsockname":    {"getsockname", "socketcall"},
+	"getpeername":    {"getpeername", "socketcall"},
+	"getaddrinfo":    {"socket", "connect", "sendto", "sendmmsg", "recvfrom", "poll", "ppoll", "open", "openat", "read", "close", "fstat", "newfstatat", "statx", "bind", "getsockname"},
+	"gethostbyname":  {"socket", "connect", "sendto", "sendmmsg", "recvfrom", "poll", "ppoll", "open", "openat", "read", "close", "fstat", "newfstatat", "statx"},
+	"getnameinfo":    {"socket", "connect", "sendto", "sendmmsg", "recvfrom", "poll", "ppoll", "open", "openat", "read", "close", "fstat", "newfstatat", "statx"},
+	"gethostbyaddr":  {"socket", "connect", "sendto", "sendmmsg", "recvfrom", "poll", "ppoll", "open", "openat", "read", "close", "fstat", "newfstatat", "statx"},
+	"getifaddrs":     {"socket", "bind", "getsockname", "sendto", "recvmsg", "close"},
+	"if_nametoindex": {"socket", "ioctl", "close"},
+	"gethostname":    {"uname"},
+	"sethostname":    {"sethostname"},
+	"uname":          {"uname"},
+
+	// Users and groups, which read the files configured in nsswitch.conf.
+	"getpwnam":   {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getpwnam_r": {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getpwuid":   {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getpwuid_r": {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getgrnam":   {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getgrnam_r": {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getgrgid":   {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getgrgid_r": {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "lseek", "socket", "connect"},
+	"getlogin":   {"open", "openat", "read", "close", "readlink", "readlinkat"},
+
+	// Libraries, randomness, and misc.
+	"dlopen":        {"open", "openat", "read", "pread64", "fstat", "newfstatat", "statx", "mmap", "mmap2", "mprotect", "munmap", "close"},
+	"dlclose":       {"munmap"},
+	"getrandom":     {"getrandom"},
+	"getentropy":    {"getrandom"},
+	"arc4random":    {"getrandom"},
+	"syslog":        {"socket", "connect", "sendto", "send", "close", "clock_gettime", "clock_gettime64"},
+	"openlog":       {"socket", "connect"},
+	"closelog":      {"close"},
+	"sysinfo":       {"sysinfo"},
+	"ttyname":       {"readlink", "readlinkat", "ioctl", "fstat", "newfstatat", "statx"},
+	"ttyname_r":     {"readlink", "readlinkat", "ioctl", "fstat", "newfstatat", "statx"},
+	"getauxval":     {},
+	"seccomp":       {"seccomp"},
+	"bpf":           {"bpf"},
+	"reboot":        {"reboot"},
+	"swapon":        {"swapon"},
+	"swapoff":       {"swapoff"},
+	"init_module":   {"init_module"},
+	"delete_module": {"delete_module"},
+	"acct":          {"acct"},
+	"setlocale":     {"open", "openat", "read", "close", "fstat", "newfstatat", "statx", "mmap", "mmap2", "munmap"},
+	"catopen":       {"open", "openat", "read", "close", "mmap", "mmap2"},
+	"mq_open":       {"mq_open"},
+	"mq_send":       {"mq_timedsend", "mq_timedsend_time64"},
+	"mq_receive":    {"mq_timedreceive", "mq_timedreceive_time64"},
+	"mq_unlink":     {"mq_unlink"},
+	"semget":        {"semget", "ipc"},
+	"semop":         {"semop", "semtimedop", "ipc"},
+	"semctl":        {"semctl", "ipc"},
+	"msgget":        {"msgget", "ipc"},
+	"msgsnd":        {"msgsnd", "ipc"},
+	"msgrcv":        {"msgrcv", "ipc"},
+	"msgctl":        {"msgctl", "ipc"},
+}
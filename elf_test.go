@@ -0,0 +1,73 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLibcWrapperSyscalls(t *testing.T) {
+	for symbol, want := range map[string][]string{
+		"open":       {"open", "openat"},
+		"open64":     {"open", "openat"},
+		"__open64_2": {"open", "openat"},
+		"__read_chk": {"read"},
+		"__fxstat64": {"fstat", "fstat64", "newfstatat", "fstatat64", "statx"},
+		"strlen":     nil,
+	} {
+		got, _ := libcWrapperSyscalls(symbol)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: expected %v, got %v", symbol, want, got)
+		}
+	}
+}
+
+func TestAnalyzeELFFile(t *testing.T) {
+	analysis, err := AnalyzeELFFile("/bin/ls")
+	if errors.Is(err, ErrStaticELF) {
+		t.Skip("/bin/ls is statically linked")
+	}
+	if err != nil {
+		t.Skip(err)
+	}
+
+	if analysis.Interpreter == "" || len(analysis.Libraries) == 0 {
+		t.Errorf("expected an interpreter and libraries, got %q and %v", analysis.Interpreter, analysis.Libraries)
+	}
+	if len(analysis.Imports) == 0 {
+		t.Fatal("expected imported symbols")
+	}
+	for _, name := range []string{"close", "execve", "exit_group"} {
+		if analysis.Trace.Profile[name] == 0 {
+			t.Errorf("expected %v in trace %v", name, analysis.Trace.Names())
+		}
+	}
+
+	if analysis.Arch == nil {
+		return
+	}
+	policy, err := analysis.Policy(DraftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := policy.Assemble(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/net/bpf"
+)
+
+// sizeOfSeccompData is the size of struct seccomp_data in bytes.
+const sizeOfSeccompData = 64
+
+// SeccompData is the Go representation of the seccomp_data struct that the
+// kernel passes to a seccomp filter for each system call.
+// https://github.com/torvalds/linux/blob/v4.16/include/uapi/linux/seccomp.h#L53-L65
+type SeccompData struct {
+	NR                 int32     // System call number.
+	Arch               uint32    // AUDIT_ARCH_* value.
+	InstructionPointer uint64    // CPU instruction pointer.
+	Args               [6]uint64 // System call arguments.
+}
+
+// Emulator evaluates assembled seccomp BPF programs in userspace. It allows
+// the decisions of a filter to be inspected without loading it.
+type Emulator struct {
+	vm *bpf.VM
+}
+
+// NewEmulator returns an Emulator for the given instructions.
+func NewEmulator(instructions []bpf.Instruction) (*Emulator, error) {
+	vm, err := bpf.NewVM(instructions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BPF VM: %w", err)
+	}
+	return &Emulator{vm: vm}, nil
+}
+
+// Run evaluates the program against the given data and returns the action
+// (including any action data like the errno value) returned by the filter.
+func (e *Emulator) Run(data SeccompData) (Action, error) {
+	rtn, err := e.vm.Run(data.marshal())
+	if err != nil {
+		return 0, err
+	}
+	return Action(rtn), nil
+}
+
+// marshal encodes the data for the BPF VM. The VM always loads 32-bit words
+// as big-endian, but the kernel loads them in native byte order. The 64-bit
+// fields are split into words in native order so that the LdHi and LdLo
+// offsets used by the Program read the same halves that the kernel would.
+// https://github.com/golang/go/issues/20556
+func (d SeccompData) marshal() []byte {
+	buf := make([]byte, sizeOfSeccompData)
+	binary.BigEndian.PutUint32(buf[0:], uint32(d.NR))
+	binary.BigEndian.PutUint32(buf[4:], d.Arch)
+	putUint64(buf[8:], d.InstructionPointer)
+	for i, arg := range d.Args {
+		putUint64(buf[argumentOffset+sizeOfUint64*uint32(i):], arg)
+	}
+	return buf
+}
+
+func putUint64(buf []byte, v uint64) {
+	hi, lo := uint32(v>>32), uint32(v)
+	if nativeEndian == binary.LittleEndian {
+		hi, lo = lo, hi
+	}
+	binary.BigEndian.PutUint32(buf[0:], hi)
+	binary.BigEndian.PutUint32(buf[sizeOfUint32:], lo)
+}
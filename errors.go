@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import "fmt"
+
+// TSyncError is returned by LoadFilter when the filter could not be
+// synchronized to all threads because a thread has a diverging seccomp
+// state (e.g. it installed its own filter).
+type TSyncError struct {
+	// TID is the ID of the thread that could not be synchronized. It is zero
+	// when FilterFlagTSyncESRCH was used because the kernel does not report
+	// the thread in that case.
+	TID int
+}
+
+func (e *TSyncError) Error() string {
+	if e.TID == 0 {
+		return "failed to synchronize seccomp filter to all threads"
+	}
+	return fmt.Sprintf("failed to synchronize seccomp filter to thread %d", e.TID)
+}
+
+// NoNewPrivsError is returned by LoadFilter when the kernel refused to
+// install the filter (EACCES) because the no new privs bit is not set and the
+// thread does not have CAP_SYS_ADMIN. Set Filter.NoNewPrivs to fix it.
+type NoNewPrivsError struct {
+	Err error // Error returned by the kernel.
+}
+
+func (e *NoNewPrivsError) Error() string {
+	return "no_new_privs must be set or the thread must have CAP_SYS_ADMIN " +
+		"to load a seccomp filter (set Filter.NoNewPrivs): " + e.Err.Error()
+}
+
+func (e *NoNewPrivsError) Unwrap() error {
+	return e.Err
+}
+
+// ThreadCountError is returned by LoadFilter when a filter with
+// RequireSingleThread but without FilterFlagTSync would only confine the
+// calling thread while the process has other threads (e.g. threads created
+// by the Go runtime).
+type ThreadCountError struct {
+	Threads int // Number of threads in the process.
+}
+
+func (e *ThreadCountError) Error() string {
+	return fmt.Sprintf("filter without tsync would only apply to the calling "+
+		"thread but the process has %d threads (use FilterFlagTSync)", e.Threads)
+}
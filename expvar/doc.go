@@ -0,0 +1,29 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package expvar publishes seccomp statistics with the standard library's
+// expvar package, so services that already serve /debug/vars get them without
+// further setup: the filters loaded and their instructions, the notifications
+// handled by a notify.Supervisor, and the syscalls denied by notifications,
+// trap handlers, or as seen in audit records.
+//
+//	stats := expvar.Publish("seccomp")
+//	if err := stats.LoadFilter(filter); err != nil {
+//		return err
+//	}
+//	supervisor.Metrics = stats
+package expvar
@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expvar
+
+import (
+	"expvar"
+	"os"
+	"strconv"
+	"time"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+	"github.com/elastic/go-seccomp-bpf/trap"
+)
+
+// Stats is an expvar.Var with the seccomp statistics of the process. Its
+// JSON object has the fields:
+//
+//   - filters_loaded: filters installed with LoadFilter.
+//   - load_errors: calls of LoadFilter that failed.
+//   - instructions: BPF instructions of the filters installed with
+//     LoadFilter.
+//   - notifications: notifications received by a supervisor.
+//   - notify_decisions: notifications by the way they were answered.
+//   - notify_errors: handler and memory read failures of a supervisor.
+//   - denials: denied syscalls by name ("unknown" for unknown syscalls).
+//   - status: seccomp mode and number of filters of the process, read when
+//     the stats are formatted, or null if unsupported.
+//
+// Stats implements notify.Metrics and is safe for concurrent use.
+type Stats struct {
+	vars *expvar.Map
+
+	filtersLoaded expvar.Int
+	loadErrors    expvar.Int
+	instructions  expvar.Int
+	notifications expvar.Int
+	decisions     expvar.Map
+	notifyErrors  expvar.Int
+	denials       expvar.Map
+}
+
+var _ notify.Metrics = (*Stats)(nil)
+
+// NewStats returns Stats that are not published.
+func NewStats() *Stats {
+	s := &Stats{vars: new(expvar.Map)}
+	s.vars.Set("filters_loaded", &s.filtersLoaded)
+	s.vars.Set("load_errors", &s.loadErrors)
+	s.vars.Set("instructions", &s.instructions)
+	s.vars.Set("notifications", &s.notifications)
+	s.vars.Set("notify_decisions", &s.decisions)
+	s.vars.Set("notify_errors", &s.notifyErrors)
+	s.vars.Set("denials", &s.denials)
+	s.vars.Set("status", expvar.Func(status))
+	return s
+}
+
+// Publish returns new Stats published under the name. Like expvar.Publish,
+// it panics if the name is already in use.
+func Publish(name string) *Stats {
+	s := NewStats()
+	expvar.Publish(name, s)
+	return s
+}
+
+// String implements expvar.Var.
+func (s *Stats) String() string {
+	return s.vars.String()
+}
+
+// LoadFilter loads the filter with seccomp.LoadFilter and counts it and its
+// instructions. Filters in dry run mode are not counted.
+func (s *Stats) LoadFilter(filter seccomp.Filter) error {
+	if err := seccomp.LoadFilter(filter); err != nil {
+		s.loadErrors.Add(1)
+		return err
+	}
+	if dryRun, _ := strconv.ParseBool(os.Getenv(seccomp.DryRunEnv)); filter.DryRun || dryRun {
+		return nil
+	}
+	insts, err := filter.Policy.Assemble()
+	if err != nil {
+		// Not reached, the filter was assembled to be loaded.
+		return err
+	}
+	s.filtersLoaded.Add(1)
+	s.instructions.Add(int64(len(insts)))
+	return nil
+}
+
+// Trap returns a trap.Handler that counts the signal as a denial and then
+// calls next, if not nil.
+func (s *Stats) Trap(next trap.Handler) trap.Handler {
+	return func(sig trap.Signal) {
+		s.denied(sig.Syscall)
+		if next != nil {
+			next(sig)
+		}
+	}
+}
+
+// Audit counts a record of the audit log as a denial unless its action let
+// the syscall execute.
+func (s *Stats) Audit(r *seccomp.AuditRecord) {
+	switch r.Code.Base() {
+	case seccomp.ActionAllow, seccomp.ActionLog, seccomp.ActionTrace, seccomp.ActionUserNotify:
+		return
+	}
+	s.denied(r.Syscall)
+}
+
+// NotificationReceived implements notify.Metrics.
+func (s *Stats) NotificationReceived(string) {
+	s.notifications.Add(1)
+}
+
+// Decided implements notify.Metrics. Notifications that failed with an errno
+// are counted as denials.
+func (s *Stats) Decided(syscall string, decision notify.Decision) {
+	s.decisions.Add(decision.String(), 1)
+	if decision == notify.DecisionErrno {
+		s.denied(syscall)
+	}
+}
+
+// HandlerLatency implements notify.Metrics. The latency is not published.
+func (s *Stats) HandlerLatency(string, time.Duration) {}
+
+// HandlerFailed implements notify.Metrics.
+func (s *Stats) HandlerFailed(string, error) {
+	s.notifyErrors.Add(1)
+}
+
+// MemoryReadFailed implements notify.Metrics.
+func (s *Stats) MemoryReadFailed(string, error) {
+	s.notifyErrors.Add(1)
+}
+
+func (s *Stats) denied(syscall string) {
+	if syscall == "" {
+		syscall = "unknown"
+	}
+	s.denials.Add(syscall, 1)
+}
+
+// status returns the seccomp status of the process, or nil if it cannot be
+// read.
+func status() any {
+	st, err := seccomp.GetStatus()
+	if err != nil {
+		return nil
+	}
+	return map[string]any{"mode": st.Mode.String(), "filters": st.Filters}
+}
@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package expvar
+
+import (
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func TestLoadFilter(t *testing.T) {
+	s := NewStats()
+
+	policy := seccomp.Policy{
+		DefaultAction: seccomp.ActionAllow,
+		Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionErrno, Names: []string{"mount"}}},
+	}
+	if err := s.LoadFilter(seccomp.Filter{DryRun: true, Flag: seccomp.FilterFlagTSync, Policy: policy}); err != nil {
+		t.Fatal(err)
+	}
+	policy.Syscalls[0].Names = []string{"no_such_syscall"}
+	if err := s.LoadFilter(seccomp.Filter{DryRun: true, Flag: seccomp.FilterFlagTSync, Policy: policy}); err == nil {
+		t.Fatal("expected an error for an unknown syscall")
+	}
+
+	v := decodeStats(t, s)
+	if v.FiltersLoaded != 0 || v.Instructions != 0 || v.LoadErrors != 1 {
+		t.Errorf("unexpected load stats %+v", v)
+	}
+	if v.Status == nil || v.Status.Mode == "" {
+		t.Errorf("expected the status of the process, got %+v", v.Status)
+	}
+}
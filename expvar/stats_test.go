@@ -0,0 +1,96 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package expvar
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+	"github.com/elastic/go-seccomp-bpf/trap"
+)
+
+type statsJSON struct {
+	FiltersLoaded   int64            `json:"filters_loaded"`
+	LoadErrors      int64            `json:"load_errors"`
+	Instructions    int64            `json:"instructions"`
+	Notifications   int64            `json:"notifications"`
+	NotifyDecisions map[string]int64 `json:"notify_decisions"`
+	NotifyErrors    int64            `json:"notify_errors"`
+	Denials         map[string]int64 `json:"denials"`
+	Status          *struct {
+		Mode    string `json:"mode"`
+		Filters int    `json:"filters"`
+	} `json:"status"`
+}
+
+func decodeStats(t *testing.T, s *Stats) statsJSON {
+	t.Helper()
+	var v statsJSON
+	if err := json.Unmarshal([]byte(s.String()), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s.String(), err)
+	}
+	return v
+}
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+
+	var trapped int
+	s.Trap(func(trap.Signal) { trapped++ })(trap.Signal{Syscall: "getppid"})
+	s.Trap(nil)(trap.Signal{})
+
+	s.Audit(&seccomp.AuditRecord{Syscall: "ptrace", Code: seccomp.ActionKillProcess})
+	s.Audit(&seccomp.AuditRecord{Syscall: "uname", Code: seccomp.ActionLog})
+
+	s.NotificationReceived("mount")
+	s.Decided("mount", notify.DecisionErrno)
+	s.NotificationReceived("openat")
+	s.Decided("openat", notify.DecisionContinue)
+	s.HandlerFailed("openat", errors.New("failed"))
+
+	v := decodeStats(t, s)
+	if trapped != 1 {
+		t.Errorf("expected the next handler to be called once, got %v", trapped)
+	}
+	if v.Notifications != 2 || v.NotifyErrors != 1 {
+		t.Errorf("unexpected notify stats %+v", v)
+	}
+	if v.NotifyDecisions["errno"] != 1 || v.NotifyDecisions["continue"] != 1 {
+		t.Errorf("unexpected decisions %v", v.NotifyDecisions)
+	}
+	want := map[string]int64{"getppid": 1, "unknown": 1, "ptrace": 1, "mount": 1}
+	if len(v.Denials) != len(want) {
+		t.Errorf("expected denials %v, got %v", want, v.Denials)
+	}
+	for name, n := range want {
+		if v.Denials[name] != n {
+			t.Errorf("expected %v denials of %v, got %v", n, name, v.Denials[name])
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	s := Publish("seccomp_test")
+	if expvar.Get("seccomp_test") != s {
+		t.Error("the stats were not published")
+	}
+}
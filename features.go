@@ -0,0 +1,80 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KernelFeatures describes the seccomp features supported by the running
+// kernel.
+type KernelFeatures struct {
+	Syscall bool          // The seccomp syscall is available.
+	Version KernelVersion // Version of the running kernel.
+	Actions []Action      // Actions supported in filters.
+	Flags   FilterFlag    // Filter flags supported by the seccomp syscall.
+}
+
+// HasAction returns true if the kernel supports the action.
+func (f *KernelFeatures) HasAction(action Action) bool {
+	action &= actionMask
+	for _, a := range f.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFlags returns true if the kernel supports all of the given flags.
+func (f *KernelFeatures) HasFlags(flags FilterFlag) bool {
+	return f.Flags&flags == flags
+}
+
+// CheckSupport returns an error describing the flags and actions used by the
+// filter that the kernel does not support.
+func (f *KernelFeatures) CheckSupport(filter Filter) error {
+	if !f.Syscall {
+		if filter.Flag != 0 {
+			return fmt.Errorf("seccomp syscall is not supported by the kernel "+
+				"(Linux %v) and is required by flags %v", f.Version, filter.Flag)
+		}
+		return nil
+	}
+
+	var problems []string
+	if missing := filter.Flag &^ f.Flags; missing != 0 {
+		problems = append(problems, fmt.Sprintf("flags %v are not supported", missing))
+	}
+	actions := filter.Policy.actions()
+	if filter.Compiled != nil {
+		actions = filter.Compiled.actions()
+	}
+	for _, action := range actions {
+		if !f.HasAction(action) {
+			problems = append(problems, fmt.Sprintf("action %v is not supported", action))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("kernel (Linux %v) does not support the filter: %v",
+			f.Version, strings.Join(problems, ", "))
+	}
+	return nil
+}
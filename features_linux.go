@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// KernelSupport probes the running kernel and reports which seccomp actions
+// and filter flags it supports. Applications can use it to degrade gracefully
+// instead of failing when a filter is loaded.
+func KernelSupport() (*KernelFeatures, error) {
+	features := &KernelFeatures{Syscall: Supported()}
+
+	version, err := runningKernelVersion()
+	if err != nil {
+		return nil, err
+	}
+	features.Version = version
+
+	if !features.Syscall {
+		return features, nil
+	}
+
+	// Prefer the list from procfs (Linux 4.14+) and fallback to asking the
+	// kernel about each action.
+	if actions, err := ActionsAvailable(); err == nil {
+		features.Actions = actions
+	} else {
+		features.Actions = probeActions(actionAvailable)
+	}
+
+	for flag := range filterFlagNames {
+		if filterFlagAvailable(flag) {
+			features.Flags |= flag
+		}
+	}
+
+	return features, nil
+}
+
+// baseActions are the actions supported by every kernel with seccomp filters
+// (Linux 3.5+).
+var baseActions = []Action{ActionKillThread, ActionTrap, ActionErrno, ActionTrace, ActionAllow}
+
+// probeActions asks the kernel about each action using query. Kernels older
+// than 4.14 reject the query itself with EINVAL and are assumed to support
+// the base actions.
+func probeActions(query func(Action) error) []Action {
+	var actions []Action
+	for action := range actionNames {
+		switch query(action) {
+		case nil:
+			actions = append(actions, action)
+		case syscall.EINVAL:
+			return append([]Action(nil), baseActions...)
+		}
+	}
+	return actions
+}
+
+// actionAvailable uses SECCOMP_GET_ACTION_AVAIL to check if the kernel
+// supports the action. It returns EOPNOTSUPP for unsupported actions.
+func actionAvailable(action Action) error {
+	a := uint32(action)
+	_, err := seccomp(seccompGetActionAvail, 0, unsafe.Pointer(&a))
+	return err
+}
+
+// filterFlagAvailable checks if the kernel supports the flag by trying to
+// install a nil filter. The kernel validates the flags before reading the
+// program, so EFAULT indicates that the flag is supported.
+func filterFlagAvailable(flag FilterFlag) bool {
+	_, err := seccomp(seccompSetModeFilter, flag, nil)
+	return err == syscall.EFAULT
+}
@@ -18,9 +18,12 @@
 package seccomp
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/bpf"
@@ -38,8 +41,11 @@ const (
 type FilterFlag uint32
 
 var filterFlagNames = map[FilterFlag]string{
-	FilterFlagTSync: "tsync",
-	FilterFlagLog:   "log",
+	FilterFlagTSync:       "tsync",
+	FilterFlagLog:         "log",
+	FilterFlagSpecAllow:   "spec_allow",
+	FilterFlagNewListener: "new_listener",
+	FilterFlagTSyncESRCH:  "tsync_esrch",
 }
 
 // String returns a string representation of the FilterFlag.
@@ -61,11 +67,71 @@ func (f FilterFlag) String() string {
 	return strings.Join(list, "|")
 }
 
+// Unpack sets the FilterFlag value based on the config value. The value can
+// be a number, a string of flag names separated by '|' (e.g. "tsync|log"), or
+// a list of flag names.
+func (f *FilterFlag) Unpack(v interface{}) error {
+	switch v := v.(type) {
+	case int64:
+		*f = FilterFlag(v)
+	case uint64:
+		*f = FilterFlag(v)
+	case string:
+		var flag FilterFlag
+		for _, name := range strings.Split(v, "|") {
+			if err := flag.unpackName(name); err != nil {
+				return err
+			}
+		}
+		*f = flag
+	case []interface{}:
+		var flag FilterFlag
+		for _, name := range v {
+			s, ok := name.(string)
+			if !ok {
+				return fmt.Errorf("invalid filter flag: %v", name)
+			}
+			if err := flag.unpackName(s); err != nil {
+				return err
+			}
+		}
+		*f = flag
+	default:
+		return fmt.Errorf("invalid filter flag type %T", v)
+	}
+	return nil
+}
+
+// unpackName ORs the flag with the given name into f.
+func (f *FilterFlag) unpackName(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil
+	}
+	for flag, flagName := range filterFlagNames {
+		if flagName == name {
+			*f |= flag
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid filter flag: %v", name)
+}
+
 // MarshalText marshals the value to text.
 func (f FilterFlag) MarshalText() ([]byte, error) {
 	return []byte(f.String()), nil
 }
 
+// UnmarshalText sets the FilterFlag from a number or from flag names
+// separated by '|'.
+func (f *FilterFlag) UnmarshalText(text []byte) error {
+	if v, err := strconv.ParseUint(string(text), 0, 32); err == nil {
+		*f = FilterFlag(v)
+		return nil
+	}
+	return f.Unpack(string(text))
+}
+
 // Action specifies what to do when a syscall matches during filter evaluation.
 type Action uint32
 
@@ -77,46 +143,168 @@ var actionNames = map[Action]string{
 	ActionTrace:       "trace",
 	ActionLog:         "log",
 	ActionAllow:       "allow",
+	ActionUserNotify:  "user_notif",
 }
 
-// Unpack sets the Action value based on the string.
+// Unpack sets the Action value based on the string. The data of errno,
+// trace, and trap, which the kernel passes on, can be given in parentheses
+// (e.g. "errno(13)" or "errno(EACCES)").
 func (a *Action) Unpack(s string) error {
-	s = strings.ToLower(s)
-	for action, name := range actionNames {
-		if name == s {
+	s = strings.ToLower(strings.TrimSpace(s))
+	name, data, hasData := strings.Cut(s, "(")
+	for action, actionName := range actionNames {
+		if actionName != name {
+			continue
+		}
+		if !hasData {
 			*a = action
 			return nil
 		}
+		data, ok := strings.CutSuffix(data, ")")
+		if !ok {
+			break
+		}
+		if action != ActionErrno && action != ActionTrace && action != ActionTrap {
+			return fmt.Errorf("action %v takes no data: %v", actionName, s)
+		}
+		errno, err := parseErrno(strings.ToUpper(data))
+		if err != nil {
+			return fmt.Errorf("invalid action data: %v", s)
+		}
+		*a = action | errno&^actionMask
+		return nil
 	}
 	return fmt.Errorf("invalid action: %v", s)
 }
 
-// String returns a string representation of the Action.
+// Base returns the action without its data, for example ActionErrno for the
+// return value of a filter that returns an errno.
+func (a Action) Base() Action {
+	return a & actionMask
+}
+
+// Data returns the data of the action, for example the errno of ActionErrno
+// or the value of ActionTrace.
+func (a Action) Data() uint16 {
+	return uint16(a &^ actionMask)
+}
+
+// String returns a string representation of the Action. The data of the
+// action, if any, follows in parentheses.
 func (a Action) String() string {
-	name, found := actionNames[a]
-	if found {
-		return name
+	name, found := actionNames[a&actionMask]
+	if !found {
+		return "unknown"
 	}
-	return "unknown"
+	if data := a &^ actionMask; data != 0 {
+		return fmt.Sprintf("%s(%d)", name, data)
+	}
+	return name
+}
+
+// returnValue returns the value that a filter returns for the action. An
+// errno action without data returns EPERM.
+func (a Action) returnValue() Action {
+	if a == ActionErrno {
+		return a | Action(errnoEPERM)
+	}
+	return a
+}
+
+// permits returns true if the action lets the syscall execute (possibly
+// after notifying a tracer, supervisor, or the audit log).
+func (a Action) permits() bool {
+	switch a & actionMask {
+	case ActionAllow, ActionLog, ActionTrace, ActionUserNotify:
+		return true
+	}
+	return false
 }
 
 // MarshalText marshals the value to text.
 func (a Action) MarshalText() ([]byte, error) {
+	if _, found := actionNames[a&actionMask]; !found {
+		return nil, fmt.Errorf("invalid action value %d", a)
+	}
 	return []byte(a.String()), nil
 }
 
+// UnmarshalText unmarshals the value from text.
+func (a *Action) UnmarshalText(text []byte) error {
+	return a.Unpack(string(text))
+}
+
 // Filter contains all the parameters necessary to install a Linux seccomp
 // filter for the process.
 type Filter struct {
-	NoNewPrivs bool       `config:"no_new_privs" json:"no_new_privs"` // Set the process's no new privs bit.
-	Flag       FilterFlag `config:"flag"         json:"flag"`         // Flag to pass to the seccomp call.
-	Policy     Policy     `config:"policy"       json:"policy"`       // Policy that will be assembled into a BPF filter.
+	NoNewPrivs bool       `config:"no_new_privs" json:"no_new_privs" yaml:"no_new_privs"` // Set the process's no new privs bit.
+	Flag       FilterFlag `config:"flag"         json:"flag"         yaml:"flag"`         // Flag to pass to the seccomp call.
+	Policy     Policy     `config:"policy"       json:"policy"       yaml:"policy"`       // Policy that will be assembled into a BPF filter.
+
+	// Do not set the no new privs bit if the thread has CAP_SYS_ADMIN in its
+	// effective set. The kernel allows privileged threads to install filters
+	// without it, and they keep the ability to gain privileges through exec.
+	SkipNoNewPrivsIfPrivileged bool `config:"skip_no_new_privs_if_privileged" json:"skip_no_new_privs_if_privileged" yaml:"skip_no_new_privs_if_privileged"`
+
+	// Compile and validate the filter and check that the kernel supports it,
+	// but do not install it. Dry run can also be enabled by setting the
+	// SECCOMP_DRY_RUN environment variable to true.
+	DryRun bool `config:"dry_run" json:"dry_run" yaml:"dry_run"`
+
+	// Refuse to install the filter without FilterFlagTSync when the process
+	// has more than one thread. Such a filter is only applied to the calling
+	// thread and leaves the other threads (e.g. those of the Go runtime)
+	// unconfined. LoadFilter returns a ThreadCountError in that case.
+	RequireSingleThread bool `config:"require_single_thread" json:"require_single_thread" yaml:"require_single_thread"`
+
+	// Cache is used to assemble the policy if set, so that loading the same
+	// policy repeatedly only assembles it once.
+	Cache *FilterCache `config:",ignore" json:"-" yaml:"-"`
+
+	// Compiled is installed instead of assembling the Policy if set.
+	Compiled *CompiledProgram `config:",ignore" json:"-" yaml:"-"`
+}
+
+// DryRunEnv is the environment variable that enables dry run mode for all
+// filters when set to a true value (e.g. "1" or "true").
+const DryRunEnv = "SECCOMP_DRY_RUN"
+
+// isDryRun returns true if the filter must not be installed.
+func (f Filter) isDryRun() bool {
+	if f.DryRun {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(DryRunEnv))
+	return enabled
+}
+
+// actions returns the distinct actions used by the policy.
+func (p *Policy) actions() []Action {
+	actions := []Action{p.DefaultAction}
+	for _, group := range p.groups() {
+		found := false
+		for _, a := range actions {
+			if a == group.Action {
+				found = true
+				break
+			}
+		}
+		if !found {
+			actions = append(actions, group.Action)
+		}
+	}
+	return actions
 }
 
 // Policy defines the BPF seccomp filter.
 type Policy struct {
-	DefaultAction Action         `config:"default_action" json:"default_action" yaml:"default_action"` // Action when no syscalls match.
-	Syscalls      []SyscallGroup `config:"syscalls"       json:"syscalls"       yaml:"syscalls"`       // Groups of syscalls and actions.
+	DefaultAction Action         `config:"default_action" json:"default_action"    yaml:"default_action"`    // Action when no syscalls match.
+	Syscalls      []SyscallGroup `config:"syscalls"       json:"syscalls"          yaml:"syscalls"`          // Groups of syscalls and actions.
+	Profile       SyscallProfile `config:"profile"        json:"profile,omitempty" yaml:"profile,omitempty"` // Optional syscall frequencies used to order comparisons.
+
+	// Allow the GoRuntimeSyscalls that are not named by any group. They are
+	// checked after all groups.
+	IncludeGoRuntime bool `config:"include_go_runtime" json:"include_go_runtime,omitempty" yaml:"include_go_runtime,omitempty"`
 
 	arch *arch.Info
 }
@@ -124,17 +312,22 @@ type Policy struct {
 // SyscallGroup is a logical block within a Policy that contains a set of
 // syscalls to match against and an action to take.
 type SyscallGroup struct {
-	Names              []string             `config:"names"  json:"names"  yaml:"names"`                              // List of syscall names (all must exist).
-	NamesWithCondtions []NameWithConditions `config:"names_with_args" json:"names_with_args"  yaml:"names_with_args"` // List of syscall with argument filters
-	Action             Action               `config:"action" validate:"required" json:"action" yaml:"action"`         // Action to take upon a match.
+	Names              []string             `config:"names"                      json:"names,omitempty"           yaml:"names,omitempty"`           // List of syscall names (all must exist).
+	NamesWithCondtions []NameWithConditions `config:"names_with_args"            json:"names_with_args,omitempty" yaml:"names_with_args,omitempty"` // List of syscall with argument filters
+	Action             Action               `config:"action" validate:"required" json:"action"                    yaml:"action"`                    // Action to take upon a match.
 
-	arch *arch.Info
+	arch    *arch.Info
+	profile SyscallProfile
 }
 
 // ArgumentConditions consist of a list of up to six conditions for the six arguments.
 type ArgumentConditions []Condition
 
 func (a ArgumentConditions) Validate() []string {
+	if len(a) == 0 {
+		return []string{"arguments must not be empty"}
+	}
+
 	var problems []string
 	for _, condition := range a {
 		if condition.Argument < 0 || condition.Argument > 5 {
@@ -145,14 +338,51 @@ func (a ArgumentConditions) Validate() []string {
 }
 
 type NameWithConditions struct {
-	Name       string             `config:"name" validate:"required" json:"name"  yaml:"name"`
-	Conditions ArgumentConditions `config:"arguments" validate:"required" json:"arguments"  yaml:"arguments"`
+	Name       string             `config:"name"      validate:"required" json:"name"      yaml:"name"`
+	Conditions ArgumentConditions `config:"arguments" validate:"required" json:"arguments" yaml:"arguments"`
 }
 
 type Condition struct {
-	Argument  uint32    `config:"argument" default:"0" json:"position"  yaml:"position"`
-	Operation Operation `config:"operation" validate:"required" json:"operation"  yaml:"operation"`
-	Value     uint64    `config:"value" default:"0" json:"value"  yaml:"value"`
+	Argument  uint32    `config:"argument"  default:"0"         json:"position"  yaml:"position"`
+	Operation Operation `config:"operation" validate:"required" json:"operation" yaml:"operation"`
+	Value     uint64    `config:"value"     default:"0"         json:"value"     yaml:"value"`
+}
+
+// conditionKeys accepts the "argument" key of the config format in JSON and
+// YAML in addition to "position".
+type conditionKeys struct {
+	condition `yaml:",inline"`
+	Argument  *uint32 `json:"argument" yaml:"argument"`
+}
+
+type condition Condition
+
+func (k conditionKeys) value() Condition {
+	c := Condition(k.condition)
+	if k.Argument != nil {
+		c.Argument = *k.Argument
+	}
+	return c
+}
+
+// UnmarshalJSON unmarshals the condition from JSON.
+func (c *Condition) UnmarshalJSON(data []byte) error {
+	var k conditionKeys
+	if err := json.Unmarshal(data, &k); err != nil {
+		return err
+	}
+	*c = k.value()
+	return nil
+}
+
+// UnmarshalYAML unmarshals the condition from YAML.
+func (c *Condition) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var k conditionKeys
+	if err := unmarshal(&k); err != nil {
+		return err
+	}
+	*c = k.value()
+	return nil
 }
 
 type Operation string
@@ -183,20 +413,42 @@ func (o *Operation) Unpack(s string) error {
 	return fmt.Errorf("invalid operation: %v", s)
 }
 
+// UnmarshalText unmarshals the value from text.
+func (o *Operation) UnmarshalText(text []byte) error {
+	return o.Unpack(string(text))
+}
+
 // Validate validates that the configuration has both a default action and a
-// set of syscalls.
+// set of syscalls. The set may be empty if IncludeGoRuntime is set.
 func (p *Policy) Validate() error {
-	if _, found := actionNames[p.DefaultAction]; !found {
+	if _, found := actionNames[p.DefaultAction&actionMask]; !found {
 		return fmt.Errorf("invalid default_action value %d", p.DefaultAction)
 	}
 
-	if len(p.Syscalls) == 0 {
+	if len(p.Syscalls) == 0 && !p.IncludeGoRuntime {
 		return errors.New("syscalls must not be empty")
 	}
 
 	return nil
 }
 
+// SetArch sets the architecture that the policy is assembled for. By default
+// it is assembled for the native architecture.
+func (p *Policy) SetArch(name string) error {
+	info, err := arch.GetInfo(name)
+	if err != nil {
+		return err
+	}
+	p.arch = info
+	return nil
+}
+
+// Arch returns the architecture of the policy, or nil if it was neither set
+// nor assembled yet.
+func (p *Policy) Arch() *arch.Info {
+	return p.arch
+}
+
 // Assemble assembles the policy into a list of BPF instructions. If the policy
 // contains any unknown syscalls or invalid actions an error will be returned.
 func (p *Policy) Assemble() ([]bpf.Instruction, error) {
@@ -215,10 +467,11 @@ func (p *Policy) Assemble() ([]bpf.Instruction, error) {
 
 	// Build the syscall filter.
 	prog := NewProgram()
-	for _, group := range p.Syscalls {
+	for _, group := range p.groups() {
 		if group.arch == nil {
 			group.arch = p.arch
 		}
+		group.profile = p.Profile
 
 		err := group.Assemble(&prog)
 		if err != nil {
@@ -306,7 +559,7 @@ func (g *SyscallGroup) toSyscallsWithConditions() ([]SyscallWithConditions, erro
 		problems []string
 	)
 	for _, name := range g.Names {
-		if num, found := g.arch.SyscallNames[name]; found {
+		if num, found := g.arch.SyscallNumber(name); found {
 			syscall := uint32(num | g.arch.SeccompMask)
 			if getSyscall(syscalls, syscall) == nil {
 				syscalls = append(syscalls, SyscallWithConditions{Num: syscall})
@@ -319,7 +572,7 @@ func (g *SyscallGroup) toSyscallsWithConditions() ([]SyscallWithConditions, erro
 	}
 
 	for _, nc := range g.NamesWithCondtions {
-		if num, found := g.arch.SyscallNames[nc.Name]; found {
+		if num, found := g.arch.SyscallNumber(nc.Name); found {
 			syscall := uint32(num | g.arch.SeccompMask)
 			check := getSyscall(syscalls, syscall)
 
@@ -362,6 +615,7 @@ func (g *SyscallGroup) Assemble(p *Program) error {
 	if err != nil {
 		return err
 	}
+	g.sortByFrequency(syscalls)
 
 	// Create labels for control flow.
 	actionLabel := p.NewLabel()    // Jump here when a syscall in this group matches.
@@ -403,10 +657,14 @@ func (s SyscallWithConditions) Assemble(p *Program, moreSyscalls bool, action, e
 	nextSyscall := nextLabel(p, moreSyscalls, end)
 	p.JmpIfTrue(bpf.JumpNotEqual, s.Num, nextSyscall)
 
+	// The conditions load the arguments, so the syscall number must be
+	// loaded again before continuing with the next syscall.
+	mismatch := p.NewLabel()
+
 	// Process each set of conditions (multiple condition sets are OR'd together)
 	for j, conditions := range s.Conditions {
 		moreConditions := j < len(s.Conditions)-1
-		nextCondition := nextLabel(p, moreConditions, nextSyscall)
+		nextCondition := nextLabel(p, moreConditions, mismatch)
 
 		// All conditions in a set must match (AND logic)
 		for i, c := range conditions {
@@ -484,9 +742,14 @@ func (s SyscallWithConditions) Assemble(p *Program, moreSyscalls bool, action, e
 		}
 	}
 
-	if moreSyscalls {
-		p.SetLabel(nextSyscall)
+	p.SetLabel(mismatch)
+	p.LdNr()
+	if !moreSyscalls {
+		// Jump over the action that follows.
+		p.Jmp(end)
+		return
 	}
+	p.SetLabel(nextSyscall)
 }
 
 // nextLabel returns a new label if more is true. Otherwise, it returns end.
@@ -20,13 +20,17 @@ package seccomp
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
-	"sort"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/elastic/go-ucfg/yaml"
 	"golang.org/x/net/bpf"
+	yamlv2 "gopkg.in/yaml.v2"
 
 	"github.com/elastic/go-seccomp-bpf/arch"
 )
@@ -43,13 +47,6 @@ func init() {
 	nativeEndian = simulatorEndian
 }
 
-type SeccompData struct {
-	NR                 int32
-	Arch               uint32
-	InstructionPointer uint64
-	Args               [6]uint64
-}
-
 type SeccompTest struct {
 	Data SeccompData
 	Rtn  Action
@@ -156,7 +153,7 @@ func TestPolicyAssembleBlacklist(t *testing.T) {
 		{
 			// Attempts to bypass the filter by using X32 syscalls on X86_64
 			// are met with ENOSYS.
-			SeccompData{NR: int32(arch.X32.SyscallNames["execve"] + arch.X32.SeccompMask), Arch: uint32(arch.X86_64.ID)},
+			SeccompData{NR: int32(syscallNumber(arch.X32, "execve") + arch.X32.SeccompMask), Arch: uint32(arch.X86_64.ID)},
 			ActionErrno | Action(errnoENOSYS),
 		},
 	})
@@ -207,12 +204,12 @@ func TestPolicyAssembleWhitelist(t *testing.T) {
 }
 
 func TestPolicyAssembleLongList(t *testing.T) {
-	// Sort syscall numbers to make manual review of filters with -dump easier.
-	syscallNumbers := make([]int, 0, len(arch.X86_64.SyscallNumbers))
-	for nr := range arch.X86_64.SyscallNumbers {
+	// Syscalls are in ascending order of their numbers, which makes manual
+	// review of filters with -dump easier.
+	syscallNumbers := make([]int, 0, arch.X86_64.NumSyscalls())
+	for nr := range arch.X86_64.Syscalls() {
 		syscallNumbers = append(syscallNumbers, nr)
 	}
-	sort.Ints(syscallNumbers)
 
 	for i := 1; i <= len(syscallNumbers); i++ {
 		filterSize := i
@@ -222,7 +219,7 @@ func TestPolicyAssembleLongList(t *testing.T) {
 			var tests []SeccompTest
 
 			for _, nr := range syscallNumbers[:filterSize] {
-				name := arch.X86_64.SyscallNumbers[nr]
+				name := arch.X86_64.SyscallName(nr)
 
 				var action Action
 				if name != "exit" {
@@ -290,15 +287,14 @@ func TestPolicyAssembleDefault(t *testing.T) {
 }
 
 func TestSimpleLongList(t *testing.T) {
-	syscallNumbers := make([]int, 0, len(arch.X86_64.SyscallNumbers))
-	for nr := range arch.X86_64.SyscallNumbers {
+	syscallNumbers := make([]int, 0, arch.X86_64.NumSyscalls())
+	for nr := range arch.X86_64.Syscalls() {
 		syscallNumbers = append(syscallNumbers, nr)
 	}
-	sort.Ints(syscallNumbers)
 
 	names := make([]string, 0, 6)
 	for i := 1; i < 6; i++ {
-		names = append(names, arch.X86_64.SyscallNumbers[i])
+		names = append(names, arch.X86_64.SyscallName(i))
 	}
 	names = append(names, "read")
 
@@ -488,3 +484,338 @@ func TestLongConditions(t *testing.T) {
 		},
 	})
 }
+
+func TestConditionsFollowedBySyscalls(t *testing.T) {
+	// A failed condition must not leave the argument in the accumulator
+	// where it would be compared against the syscall numbers that follow.
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionErrno,
+				NamesWithCondtions: []NameWithConditions{
+					{
+						Name: "clone",
+						Conditions: []Condition{
+							{Argument: 0, Operation: Equal, Value: 1},
+						},
+					},
+				},
+			},
+			{
+				Names:  []string{"read"},
+				Action: ActionKillThread,
+			},
+		},
+	}
+
+	if *dump {
+		policy.Dump(os.Stdout)
+	}
+
+	simulateSyscalls(t, policy, []SeccompTest{
+		{SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{1}}, ActionErrno | Action(errnoEPERM)},
+		{SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{0}}, ActionAllow},
+		{SeccompData{NR: 0, Arch: uint32(arch.X86_64.ID)}, ActionKillThread},
+	})
+}
+
+func TestConditionsFollowedByGroups(t *testing.T) {
+	// A syscall with conditions that is the last of its group must jump
+	// over the group's action with the syscall number in the accumulator.
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionErrno,
+				NamesWithCondtions: []NameWithConditions{
+					{
+						Name: "clone",
+						Conditions: []Condition{
+							{Argument: 0, Operation: Equal, Value: 1},
+						},
+					},
+					{
+						Name: "clone",
+						Conditions: []Condition{
+							{Argument: 1, Operation: Equal, Value: 2},
+						},
+					},
+				},
+			},
+			{
+				Names:  []string{"read", "write"},
+				Action: ActionKillThread,
+			},
+		},
+	}
+
+	if *dump {
+		policy.Dump(os.Stdout)
+	}
+
+	simulateSyscalls(t, policy, []SeccompTest{
+		{SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{1}}, ActionErrno | Action(errnoEPERM)},
+		{SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{0, 2}}, ActionErrno | Action(errnoEPERM)},
+		{SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{0, 1}}, ActionAllow},
+		{SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{1 << 32, 1}}, ActionAllow},
+		{SeccompData{NR: 0, Arch: uint32(arch.X86_64.ID)}, ActionKillThread},
+		{SeccompData{NR: 1, Arch: uint32(arch.X86_64.ID)}, ActionKillThread},
+	})
+}
+
+// TestFailedConditionReloadsSyscallNumber is the regression test for
+// conditions that left the argument in the accumulator when they failed.
+// The checks of the syscalls that followed compared the argument against
+// their numbers, so clone with an argument of 1 was treated like write.
+func TestFailedConditionReloadsSyscallNumber(t *testing.T) {
+	// The syscalls numbered 0 to 15 on x86_64.
+	low := []string{
+		"read", "write", "open", "close", "stat", "fstat", "lstat", "poll",
+		"lseek", "mmap", "mprotect", "munmap", "brk", "rt_sigaction",
+		"rt_sigprocmask", "rt_sigreturn",
+	}
+	var args []uint64
+	for i := uint64(0); i < 16; i++ {
+		args = append(args, i, 1<<32|i, i<<32|2)
+	}
+
+	ops := []Operation{Equal, NotEqual, GreaterThan, GreaterOrEqual, LessThan, LessOrEqual, BitsSet, BitsNotSet}
+	for _, op := range ops {
+		for _, value := range []uint64{2, 1<<32 | 2} {
+			for _, last := range []bool{true, false} {
+				cond := Condition{Argument: 0, Operation: op, Value: value}
+				conditions := []NameWithConditions{{Name: "clone", Conditions: ArgumentConditions{cond}}}
+				if !last {
+					conditions = append(conditions, NameWithConditions{
+						Name: "getppid", Conditions: ArgumentConditions{{Argument: 0, Operation: Equal, Value: 1}},
+					})
+				}
+				policy := &Policy{
+					arch:          arch.X86_64,
+					DefaultAction: ActionAllow,
+					Syscalls: []SyscallGroup{
+						{Action: ActionErrno, NamesWithCondtions: conditions},
+						{Action: ActionKillThread, Names: low},
+					},
+				}
+
+				var tests []SeccompTest
+				for _, arg := range args {
+					want := ActionAllow
+					if cond.matches(arg) {
+						want = ActionErrno | Action(errnoEPERM)
+					}
+					tests = append(tests, SeccompTest{
+						SeccompData{NR: 56, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{arg}}, want,
+					})
+				}
+				simulateSyscalls(t, policy, tests)
+			}
+		}
+	}
+}
+
+func TestFilterFlagUnpack(t *testing.T) {
+	tests := map[string]FilterFlag{
+		"flag: 1":                    FilterFlagTSync,
+		"flag: log":                  FilterFlagLog,
+		"flag: tsync|log":            FilterFlagTSync | FilterFlagLog,
+		"flag: [tsync, TSYNC_ESRCH]": FilterFlagTSync | FilterFlagTSyncESRCH,
+	}
+
+	for in, expected := range tests {
+		conf, err := yaml.NewConfig([]byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var config struct {
+			Flag FilterFlag `config:"flag"`
+		}
+		if err = conf.Unpack(&config); err != nil {
+			t.Fatalf("failed to unpack %q: %v", in, err)
+		}
+		if config.Flag != expected {
+			t.Errorf("expected %v for %q, got %v", expected, in, config.Flag)
+		}
+	}
+
+	var flag FilterFlag
+	if err := flag.Unpack("tsync|bogus"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestFilterMarshalRoundTrip(t *testing.T) {
+	filter := Filter{
+		NoNewPrivs: true,
+		Flag:       FilterFlagTSync | FilterFlagLog,
+		Policy: Policy{
+			DefaultAction: ActionErrno | Action(13),
+			Syscalls: []SyscallGroup{
+				{Action: ActionAllow, Names: []string{"read", "write"}},
+				{
+					Action: ActionTrap,
+					NamesWithCondtions: []NameWithConditions{{
+						Name: "personality",
+						Conditions: ArgumentConditions{
+							{Argument: 0, Operation: BitsSet, Value: 0x8},
+							{Argument: 1, Operation: LessOrEqual, Value: 1 << 40},
+						},
+					}},
+				},
+			},
+			Profile:          SyscallProfile{"read": 10},
+			IncludeGoRuntime: true,
+		},
+		RequireSingleThread: true,
+	}
+
+	codecs := map[string]struct {
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		"json": {json.Marshal, json.Unmarshal},
+		"yaml": {yamlv2.Marshal, yamlv2.Unmarshal},
+	}
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.marshal(filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(data), "position") {
+				t.Errorf("argument not written with the position key:\n%s", data)
+			}
+
+			var decoded Filter
+			if err = codec.unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(filter, decoded) {
+				t.Errorf("round trip through %s changed the filter:\n%s", name, data)
+			}
+		})
+	}
+}
+
+func TestPolicyUnmarshalConfigFormat(t *testing.T) {
+	// The document uses the keys of the config format, and the "position"
+	// key for the argument that JSON and YAML are written with.
+	const doc = `
+default_action: errno(EACCES)
+syscalls:
+  - action: allow
+    names: [read]
+  - action: kill_process
+    names_with_args:
+      - name: personality
+        arguments:
+          - {argument: 1, operation: equal, value: 8}
+          - {position: 2, operation: BitsSet, value: 4}
+`
+	expected := Policy{
+		DefaultAction: ActionErrno | Action(13),
+		Syscalls: []SyscallGroup{
+			{Action: ActionAllow, Names: []string{"read"}},
+			{
+				Action: ActionKillProcess,
+				NamesWithCondtions: []NameWithConditions{{
+					Name: "personality",
+					Conditions: ArgumentConditions{
+						{Argument: 1, Operation: Equal, Value: 8},
+						{Argument: 2, Operation: BitsSet, Value: 4},
+					},
+				}},
+			},
+		},
+	}
+
+	var native Policy
+	if err := yamlv2.Unmarshal([]byte(doc), &native); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, native) {
+		t.Errorf("expected %+v, got %+v", expected, native)
+	}
+
+	var fromJSON Policy
+	if err := json.Unmarshal([]byte(`{"default_action": "errno(13)", "syscalls": [{"action": "kill_process",
+		"names_with_args": [{"name": "personality", "arguments": [{"argument": 1, "operation": "Equal", "value": 8},
+		{"position": 2, "operation": "BitsSet", "value": 4}]}]}, {"action": "allow", "names": ["read"]}]}`), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON.Syscalls[0], fromJSON.Syscalls[1] = fromJSON.Syscalls[1], fromJSON.Syscalls[0]
+	if !reflect.DeepEqual(expected, fromJSON) {
+		t.Errorf("expected %+v, got %+v", expected, fromJSON)
+	}
+
+	conf, err := yaml.NewConfig([]byte(strings.Replace(doc, "position", "argument", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unpacked Policy
+	if err = conf.Unpack(&unpacked); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, unpacked) {
+		t.Errorf("expected %+v, got %+v", expected, unpacked)
+	}
+}
+
+func TestActionText(t *testing.T) {
+	for _, a := range []Action{ActionAllow, ActionErrno, ActionErrno | 1, ActionTrace | 0xffff, ActionTrap | 13, ActionKillThread} {
+		text, err := a.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Action
+		if err = decoded.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if decoded != a {
+			t.Errorf("expected %v, got %v from %q", a, decoded, text)
+		}
+	}
+
+	var a Action
+	for _, text := range []string{"bogus", "errno(", "errno(EWHAT)", "errno(70000)", "allow(5)", "kill_process(1)"} {
+		if err := a.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+	if _, err := Action(0x12340000).MarshalText(); err == nil {
+		t.Error("expected error for invalid action")
+	}
+}
+
+func TestActionBaseData(t *testing.T) {
+	tests := []struct {
+		action Action
+		base   Action
+		data   uint16
+	}{
+		{ActionAllow, ActionAllow, 0},
+		{ActionErrno | 13, ActionErrno, 13},
+		{ActionTrace | 0xffff, ActionTrace, 0xffff},
+		{ActionKillProcess, ActionKillProcess, 0},
+	}
+	for _, tc := range tests {
+		if base, data := tc.action.Base(), tc.action.Data(); base != tc.base || data != tc.data {
+			t.Errorf("%v: expected %v and %d, got %v and %d", tc.action, tc.base, tc.data, base, data)
+		}
+	}
+}
+
+// syscallNumber returns the number of the named syscall of the arch.
+func syscallNumber(info *arch.Info, name string) int {
+	nr, found := info.SyscallNumber(name)
+	if !found {
+		panic("unknown syscall " + name + " on " + info.Name)
+	}
+	return nr
+}
@@ -0,0 +1,273 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The runtime hooks used by the syscall package around fork. They block
+// signals and prevent stack growth while the child shares the parent's
+// memory image.
+
+//go:linkname runtime_BeforeFork syscall.runtime_BeforeFork
+func runtime_BeforeFork()
+
+//go:linkname runtime_AfterFork syscall.runtime_AfterFork
+func runtime_AfterFork()
+
+//go:linkname runtime_AfterForkInChild syscall.runtime_AfterForkInChild
+func runtime_AfterForkInChild()
+
+// ForkExec is like syscall.ForkExec but installs the filter in the child
+// after fork and before execve. Unlike Command it does not re-execute the
+// current binary, and unlike installing a thread local filter before forking
+// it does not confine any thread of the calling process.
+//
+// Between fork and execve the child may only make raw syscalls, so only
+// the Dir, Env, and Files fields of attr are supported. As with
+// syscall.ForkExec a nil Env starts the program with an empty environment.
+// The policy must allow execve. If the child fails to install the filter or
+// to execute the program the error is returned and the child is reaped.
+func ForkExec(filter Filter, argv0 string, argv []string, attr *syscall.ProcAttr) (pid int, err error) {
+	if attr == nil {
+		attr = &syscall.ProcAttr{}
+	}
+	if attr.Sys != nil {
+		return 0, errors.New("seccomp.ForkExec does not support SysProcAttr")
+	}
+	if filter.Flag&FilterFlagNewListener != 0 {
+		return 0, errors.New("seccomp.ForkExec does not support filters with the new_listener flag")
+	}
+
+	raw, err := compileFilter(filter)
+	debugFilter(filter, raw, err)
+	if err != nil {
+		return 0, err
+	}
+	if filter.isDryRun() {
+		return syscall.ForkExec(argv0, argv, attr)
+	}
+
+	noNewPrivs := filter.NoNewPrivs
+	if noNewPrivs && filter.SkipNoNewPrivsIfPrivileged {
+		if privileged, err := HasCapSysAdmin(); err == nil && privileged {
+			noNewPrivs = false
+		}
+	}
+
+	argv0p, err := syscall.BytePtrFromString(argv0)
+	if err != nil {
+		return 0, err
+	}
+	argvp, err := syscall.SlicePtrFromStrings(argv)
+	if err != nil {
+		return 0, err
+	}
+	envvp, err := syscall.SlicePtrFromStrings(attr.Env)
+	if err != nil {
+		return 0, err
+	}
+	var dir *byte
+	if attr.Dir != "" {
+		if dir, err = syscall.BytePtrFromString(attr.Dir); err != nil {
+			return 0, err
+		}
+	}
+
+	fds := make([]int, len(attr.Files))
+	nextfd := len(attr.Files)
+	for i, fd := range attr.Files {
+		fds[i] = int(fd)
+		if nextfd < int(fd) {
+			nextfd = int(fd)
+		}
+	}
+	nextfd++
+
+	sock := sockFilter(raw)
+	prog := &syscall.SockFprog{Len: uint16(len(sock)), Filter: &sock[0]}
+
+	// The child reports its errno on the pipe. A successful execve closes
+	// it.
+	var p [2]int
+	if err = unix.Pipe2(p[:], unix.O_CLOEXEC); err != nil {
+		return 0, err
+	}
+
+	// Prevent other goroutines from creating descriptors without close on
+	// exec while the child is forked.
+	syscall.ForkLock.Lock()
+	r1, errno := forkExecFiltered(argv0p, argvp, envvp, dir, fds, nextfd, noNewPrivs, uintptr(filter.Flag), prog, p[1])
+	syscall.ForkLock.Unlock()
+	runtime.KeepAlive(sock)
+	unix.Close(p[1])
+	defer unix.Close(p[0])
+	if errno != 0 {
+		return 0, errno
+	}
+	pid = int(r1)
+
+	var childErr syscall.Errno
+	buf := (*[unsafe.Sizeof(childErr)]byte)(unsafe.Pointer(&childErr))
+	n, err := readFull(p[0], buf[:])
+	if err != nil || n != 0 {
+		if err == nil {
+			err = childErr
+			if n != len(buf) {
+				err = syscall.EPIPE
+			}
+		}
+		var status syscall.WaitStatus
+		for {
+			if _, werr := syscall.Wait4(pid, &status, 0, nil); werr != syscall.EINTR {
+				break
+			}
+		}
+		return 0, err
+	}
+	return pid, nil
+}
+
+// readFull reads from fd until buf is full or the writer closes the pipe.
+func readFull(fd int, buf []byte) (int, error) {
+	var n int
+	for n < len(buf) {
+		m, err := unix.Read(fd, buf[n:])
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			break
+		}
+		n += m
+	}
+	return n, nil
+}
+
+// forkExecFiltered forks the calling thread and, in the child, arranges the
+// file descriptors, changes the directory, installs the filter, and executes
+// the program. The child may only call functions that do not grow the stack
+// and must not allocate, so it uses raw syscalls and never returns. On
+// failure it writes the errno to pipe and exits.
+//
+//go:noinline
+//go:norace
+//go:nocheckptr
+func forkExecFiltered(argv0 *byte, argv, envv []*byte, dir *byte, fds []int, nextfd int, noNewPrivs bool, flags uintptr, prog *syscall.SockFprog, pipe int) (pid uintptr, err1 syscall.Errno) {
+	// Declare all variables before forking in case any of them would be
+	// allocated on the heap.
+	var i int
+
+	// The flags are the second argument of clone on s390x.
+	a1, a2 := uintptr(syscall.SIGCHLD), uintptr(0)
+	if runtime.GOARCH == "s390x" {
+		a1, a2 = a2, a1
+	}
+
+	runtime_BeforeFork()
+	pid, _, err1 = syscall.RawSyscall6(syscall.SYS_CLONE, a1, a2, 0, 0, 0, 0)
+	if err1 != 0 || pid != 0 {
+		runtime_AfterFork()
+		return pid, err1
+	}
+
+	// Child.
+	runtime_AfterForkInChild()
+
+	// Move the descriptors that would be overwritten out of the way,
+	// including the error pipe.
+	if pipe < nextfd {
+		_, _, err1 = syscall.RawSyscall(syscall.SYS_DUP3, uintptr(pipe), uintptr(nextfd), syscall.O_CLOEXEC)
+		if err1 != 0 {
+			goto fail
+		}
+		pipe = nextfd
+		nextfd++
+	}
+	for i = 0; i < len(fds); i++ {
+		if fds[i] >= 0 && fds[i] < i {
+			if nextfd == pipe {
+				nextfd++
+			}
+			_, _, err1 = syscall.RawSyscall(syscall.SYS_DUP3, uintptr(fds[i]), uintptr(nextfd), syscall.O_CLOEXEC)
+			if err1 != 0 {
+				goto fail
+			}
+			fds[i] = nextfd
+			nextfd++
+		}
+	}
+	for i = 0; i < len(fds); i++ {
+		switch {
+		case fds[i] < 0:
+			syscall.RawSyscall(syscall.SYS_CLOSE, uintptr(i), 0, 0)
+		case fds[i] == i:
+			// Clear close on exec.
+			_, _, err1 = syscall.RawSyscall(syscall.SYS_FCNTL, uintptr(i), syscall.F_SETFD, 0)
+		default:
+			_, _, err1 = syscall.RawSyscall(syscall.SYS_DUP3, uintptr(fds[i]), uintptr(i), 0)
+		}
+		if err1 != 0 {
+			goto fail
+		}
+	}
+
+	if dir != nil {
+		_, _, err1 = syscall.RawSyscall(syscall.SYS_CHDIR, uintptr(unsafe.Pointer(dir)), 0, 0)
+		if err1 != 0 {
+			goto fail
+		}
+	}
+
+	if noNewPrivs {
+		_, _, err1 = syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0)
+		if err1 != 0 {
+			goto fail
+		}
+	}
+	_, _, err1 = syscall.RawSyscall(unix.SYS_SECCOMP, seccompSetModeFilter, flags, uintptr(unsafe.Pointer(prog)))
+	if err1 == syscall.ENOSYS && flags == 0 {
+		_, _, err1 = syscall.RawSyscall6(syscall.SYS_PRCTL, prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(prog)), 0, 0, 0)
+	}
+	if err1 != 0 {
+		goto fail
+	}
+
+	_, _, err1 = syscall.RawSyscall(syscall.SYS_EXECVE,
+		uintptr(unsafe.Pointer(argv0)),
+		uintptr(unsafe.Pointer(&argv[0])),
+		uintptr(unsafe.Pointer(&envv[0])))
+
+fail:
+	syscall.RawSyscall(syscall.SYS_WRITE, uintptr(pipe), uintptr(unsafe.Pointer(&err1)), unsafe.Sizeof(err1))
+	for {
+		syscall.RawSyscall(syscall.SYS_EXIT_GROUP, 253, 0, 0)
+	}
+}
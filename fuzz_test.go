@@ -0,0 +1,202 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+const fuzzPolicy = `
+no_new_privs: true
+flag: tsync
+policy:
+  default_action: errno(EPERM)
+  syscalls:
+  - action: allow
+    names: [read, write, exit_group]
+  - action: kill_process
+    names_with_args:
+    - name: socket
+      arguments:
+      - argument: 0
+        operation: NotEqual
+        value: 1
+    - name: mmap
+      arguments:
+      - argument: 2
+        operation: BitsSet
+        value: 4
+`
+
+const fuzzPolicyJSON = `{"policy":{"default_action":"allow","syscalls":[` +
+	`{"action":"errno(13)","names":["execve"]},` +
+	`{"action":"log","names_with_args":[{"name":"personality","arguments":[{"argument":0,"operation":"GreaterThan","value":4294967296}]}]}]}}`
+
+// fuzzCheck assembles a policy that one of the fuzz targets accepted and
+// checks that the filter agrees with it. Errors from Assemble are fine, they
+// are the compiler rejecting the input.
+func fuzzCheck(t *testing.T, p *Policy) {
+	t.Helper()
+
+	if _, err := p.Assemble(); err != nil {
+		return
+	}
+
+	// Policies with too many argument combinations to test them all are
+	// only reported as incomplete.
+	var verifyErr *VerifyError
+	if err := p.Verify(); errors.As(err, &verifyErr) && len(verifyErr.Mismatches) > 0 {
+		t.Fatalf("filter does not match policy %+v: %v", p, err)
+	}
+}
+
+func FuzzPolicyUnmarshal(f *testing.F) {
+	f.Add([]byte(fuzzPolicy))
+	f.Add([]byte(fuzzPolicyJSON))
+	f.Add([]byte("policy: {default_action: allow}"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if conf, err := yaml.NewConfig(data); err == nil {
+			var filter Filter
+			if err := conf.Unpack(&filter); err == nil {
+				fuzzCheck(t, &filter.Policy)
+			}
+		}
+
+		var filter Filter
+		if err := yamlv2.Unmarshal(data, &filter); err == nil {
+			fuzzCheck(t, &filter.Policy)
+		}
+
+		filter = Filter{}
+		if err := json.Unmarshal(data, &filter); err == nil {
+			fuzzCheck(t, &filter.Policy)
+		}
+	})
+}
+
+func FuzzReadKafelPolicy(f *testing.F) {
+	f.Add(testKafelPolicy)
+	f.Add("ALLOW { read }, LOG { write }")
+
+	f.Fuzz(func(t *testing.T, in string) {
+		p, err := ReadKafelPolicy(strings.NewReader(in), KafelOptions{Arch: "x86_64"})
+		if err != nil {
+			return
+		}
+		fuzzCheck(t, p)
+	})
+}
+
+func FuzzReadMinijailPolicy(f *testing.F) {
+	f.Add(testMinijailPolicy)
+	f.Add("read: 1\nioctl: arg1 == 0x5401 || arg1 & 0x10\n")
+	f.Add("ioctl: arg0 == 1 && arg1 == 2 && arg2 == 3 && arg3 == 4 && arg4 == 5 && arg5 == 6\n")
+
+	f.Fuzz(func(t *testing.T, in string) {
+		p, err := ReadMinijailPolicy(strings.NewReader(in), MinijailOptions{Arch: "x86_64"})
+		if err != nil {
+			return
+		}
+		fuzzCheck(t, p)
+	})
+}
+
+func FuzzReadPFC(f *testing.F) {
+	f.Add(testPFC)
+
+	f.Fuzz(func(t *testing.T, in string) {
+		p, err := ReadPFC(strings.NewReader(in), PFCOptions{Arch: "x86_64"})
+		if err != nil {
+			return
+		}
+		fuzzCheck(t, p)
+	})
+}
+
+func FuzzReadOCIProfile(f *testing.F) {
+	f.Add([]byte(testOCIProfile))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		profile, err := ReadOCIProfile(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		filter, err := profile.Filter(OCIOptions{Arch: "x86_64"})
+		if err != nil {
+			return
+		}
+		fuzzCheck(t, &filter.Policy)
+	})
+}
+
+func FuzzParseSystemdFilter(f *testing.F) {
+	f.Add("@system-service\n~@privileged @resources", "EPERM")
+	f.Add("read write:EACCES", "")
+
+	f.Fuzz(func(t *testing.T, filters, errno string) {
+		p, err := ParseSystemdFilter("x86_64", strings.Split(filters, "\n"), errno)
+		if err != nil {
+			return
+		}
+		fuzzCheck(t, p)
+	})
+}
+
+// FuzzAssemble builds a policy with one group of up to two conditions from
+// the fuzzed values. The names are strings so that name resolution is
+// exercised with arbitrary input.
+func FuzzAssemble(f *testing.F) {
+	f.Add(uint32(ActionErrno), uint32(ActionAllow), "read", "mmap", uint32(2), uint8(6), uint64(4), uint32(0), uint8(1), uint64(1<<32))
+	f.Add(uint32(ActionAllow), uint32(ActionKillProcess), "execve", "socket", uint32(0), uint8(1), uint64(1), uint32(6), uint8(0), uint64(0))
+	f.Add(uint32(ActionLog), uint32(ActionErrno|13), "bogus", "ioctl", uint32(1), uint8(3), uint64(0xffffffff), uint32(1), uint8(4), uint64(0x5401))
+
+	f.Fuzz(func(t *testing.T, defaultAction, action uint32, name, condName string,
+		arg1 uint32, op1 uint8, value1 uint64, arg2 uint32, op2 uint8, value2 uint64,
+	) {
+		p := Policy{
+			DefaultAction: Action(defaultAction),
+			Syscalls: []SyscallGroup{
+				{
+					Names:  []string{name},
+					Action: Action(action),
+					NamesWithCondtions: []NameWithConditions{
+						{
+							Name: condName,
+							Conditions: ArgumentConditions{
+								{Argument: arg1, Operation: Operations[int(op1)%len(Operations)], Value: value1},
+								{Argument: arg2, Operation: Operations[int(op2)%len(Operations)], Value: value2},
+							},
+						},
+					},
+				},
+			},
+		}
+		if err := p.SetArch("x86_64"); err != nil {
+			t.Fatal(err)
+		}
+		fuzzCheck(t, &p)
+	})
+}
@@ -0,0 +1,281 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/gosym"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// ErrNotGoBinary is returned by AnalyzeGoBinary for binaries without a Go
+// function table.
+var ErrNotGoBinary = errors.New("ELF binary is not a Go binary")
+
+// GoBinaryAnalysis is the result of AnalyzeGoBinary.
+type GoBinaryAnalysis struct {
+	Arch      *arch.Info // Architecture of the binary, nil if unsupported.
+	GoVersion string     // Go version that built the binary, if known.
+
+	// Wrappers are the sorted names of the syscall wrapper functions that
+	// are linked into the binary, such as syscall.openat.
+	Wrappers []string
+
+	// Trace counts, for each syscall, the wrappers that can make it. It also
+	// contains the GoRuntimeSyscalls.
+	Trace *SyscallTrace
+}
+
+// AnalyzeGoBinaryFile opens the binary at path and analyzes it with
+// AnalyzeGoBinary.
+func AnalyzeGoBinaryFile(path string) (*GoBinaryAnalysis, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return AnalyzeGoBinary(f)
+}
+
+// AnalyzeGoBinary infers the syscalls of a Go ELF binary from the wrapper
+// functions of the syscall, golang.org/x/sys/unix, and internal/syscall/unix
+// packages that the linker kept because they are reachable. It works for
+// static and stripped binaries because it reads the function table of the Go
+// runtime. Syscalls made with syscall.Syscall and a number computed by the
+// program, or by C code linked with cgo, are not found, so the draft policy
+// must be reviewed and tested.
+func AnalyzeGoBinary(r io.ReaderAt) (*GoBinaryAnalysis, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &GoBinaryAnalysis{
+		Arch:  elfArch(f),
+		Trace: NewSyscallTrace(),
+	}
+	if info, err := buildinfo.Read(r); err == nil {
+		a.GoVersion = info.GoVersion
+	}
+
+	funcs, err := goFunctions(f)
+	if err != nil {
+		return nil, err
+	}
+
+	info := a.Arch
+	if info == nil {
+		if info, err = arch.GetInfo(""); err != nil {
+			return nil, err
+		}
+	}
+	normalized := make(map[string]string, info.NumSyscalls())
+	for _, name := range info.Syscalls() {
+		normalized[strings.ReplaceAll(name, "_", "")] = name
+	}
+
+	for _, fn := range funcs {
+		syscalls := goWrapperSyscalls(fn, normalized)
+		if len(syscalls) == 0 {
+			continue
+		}
+		a.Wrappers = append(a.Wrappers, fn)
+		for _, name := range syscalls {
+			a.Trace.AddCount(name, 1)
+		}
+	}
+	sort.Strings(a.Wrappers)
+
+	for _, name := range GoRuntimeSyscalls {
+		a.Trace.AddCount(name, 1)
+	}
+	return a, nil
+}
+
+// Policy returns a draft policy that allows the syscalls of the analysis.
+// See SyscallTrace.Policy. The arch defaults to the arch of the binary.
+func (a *GoBinaryAnalysis) Policy(opts DraftOptions) (*Policy, error) {
+	if opts.Arch == "" && a.Arch != nil {
+		opts.Arch = a.Arch.Name
+	}
+	return a.Trace.Policy(opts)
+}
+
+// goFunctions returns the names of the functions in the binary. It reads
+// the pclntab of the Go runtime, which stripping keeps, and falls back to
+// the symbol table.
+func goFunctions(f *elf.File) ([]string, error) {
+	pclntab, text := f.Section(".gopclntab"), f.Section(".text")
+	if pclntab != nil && text != nil {
+		data, err := pclntab.Data()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .gopclntab: %w", err)
+		}
+		table, err := gosym.NewTable(nil, gosym.NewLineTable(data, text.Addr))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse .gopclntab: %w", err)
+		}
+		names := make([]string, 0, len(table.Funcs))
+		for _, fn := range table.Funcs {
+			names = append(names, fn.Name)
+		}
+		return names, nil
+	}
+
+	symbols, err := f.Symbols()
+	if err != nil {
+		return nil, ErrNotGoBinary
+	}
+	var names []string
+	isGo := false
+	for _, sym := range symbols {
+		if elf.ST_TYPE(sym.Info) != elf.STT_FUNC {
+			continue
+		}
+		names = append(names, sym.Name)
+		isGo = isGo || sym.Name == "runtime.main"
+	}
+	if !isGo {
+		return nil, ErrNotGoBinary
+	}
+	return names, nil
+}
+
+// goSyscallPackages are the packages whose functions wrap syscalls.
+var goSyscallPackages = []string{"syscall", "golang.org/x/sys/unix", "internal/syscall/unix"}
+
+// goWrapperSyscalls returns the syscalls made by a Go function if it is a
+// syscall wrapper. Wrappers are named after their syscall in camel case
+// (EpollCreate1), so their names are matched against the syscall names of
+// the arch without underscores, unless they have an alias.
+func goWrapperSyscalls(fn string, normalized map[string]string) []string {
+	pkg, name, ok := splitGoFunction(fn)
+	if !ok {
+		return nil
+	}
+	isWrapperPackage := false
+	for _, p := range goSyscallPackages {
+		if pkg == p || strings.HasSuffix(pkg, "/vendor/"+p) || pkg == "vendor/"+p {
+			isWrapperPackage = true
+			break
+		}
+	}
+	if !isWrapperPackage {
+		return nil
+	}
+
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"syscall", "rawsyscall", "rawvforksyscall"} {
+		if strings.HasPrefix(name, prefix) {
+			// Generic entry points, the number is an argument.
+			return nil
+		}
+	}
+	if syscalls, found := goWrapperAliases[name]; found {
+		return syscalls
+	}
+	if syscall, found := normalized[name]; found {
+		return []string{syscall}
+	}
+	for _, family := range goWrapperFamilies {
+		if strings.HasPrefix(name, family) {
+			if syscall, found := normalized[family]; found {
+				return []string{syscall}
+			}
+		}
+	}
+	return nil
+}
+
+// splitGoFunction splits a function name such as
+// golang.org/x/sys/unix.Openat into package and function name. It returns
+// false for methods and closures.
+func splitGoFunction(fn string) (pkg, name string, ok bool) {
+	slash := strings.LastIndexByte(fn, '/') + 1
+	dot := strings.IndexByte(fn[slash:], '.')
+	if dot < 0 {
+		return "", "", false
+	}
+	pkg, name = fn[:slash+dot], fn[slash+dot+1:]
+	if name == "" || strings.ContainsAny(name, ".()*[") {
+		return "", "", false
+	}
+	return pkg, name, true
+}
+
+// goWrapperFamilies are syscalls with several typed wrappers, such as
+// GetsockoptInt and IoctlGetTermios.
+var goWrapperFamilies = []string{
+	"getsockopt", "setsockopt", "sendmsg", "recvmsg", "ptrace", "ioctl",
+	"fcntl", "prctl", "sendto", "recvfrom",
+}
+
+// goWrapperAliases are wrappers whose name differs from the syscalls they
+// make, mostly because Linux implements them with the *at variants.
+var goWrapperAliases = map[string][]string{
+	"open":                {"openat"},
+	"stat":                {"newfstatat", "fstatat64", "stat", "stat64"},
+	"lstat":               {"newfstatat", "fstatat64", "lstat", "lstat64"},
+	"fstatat":             {"newfstatat", "fstatat64"},
+	"fstat":               {"fstat", "fstat64"},
+	"pipe":                {"pipe2", "pipe"},
+	"dup2":                {"dup3", "dup2"},
+	"getdents":            {"getdents64"},
+	"readdirent":          {"getdents64"},
+	"mkdir":               {"mkdirat"},
+	"unlink":              {"unlinkat"},
+	"rmdir":               {"unlinkat"},
+	"rename":              {"renameat", "renameat2"},
+	"link":                {"linkat"},
+	"symlink":             {"symlinkat"},
+	"readlink":            {"readlinkat"},
+	"chmod":               {"fchmodat"},
+	"chown":               {"fchownat"},
+	"lchown":              {"fchownat"},
+	"access":              {"faccessat", "faccessat2"},
+	"faccessat":           {"faccessat", "faccessat2"},
+	"utimes":              {"utimensat"},
+	"utimesnano":          {"utimensat"},
+	"mknod":               {"mknodat"},
+	"accept":              {"accept", "accept4"},
+	"seek":                {"lseek", "_llseek"},
+	"pread":               {"pread64"},
+	"pwrite":              {"pwrite64"},
+	"mmap":                {"mmap", "mmap2"},
+	"getrlimit":           {"getrlimit", "ugetrlimit", "prlimit64"},
+	"setrlimit":           {"setrlimit", "prlimit64"},
+	"forkexec":            goForkExecSyscalls,
+	"forkandexecinchild":  goForkExecSyscalls,
+	"forkandexecinchild1": goForkExecSyscalls,
+}
+
+// goForkExecSyscalls are made by syscall.forkExec, which os/exec uses.
+var goForkExecSyscalls = []string{
+	"clone", "clone3", "vfork", "execve", "wait4", "waitid", "dup3", "pipe2",
+	"close", "exit_group", "setsid", "setpgid", "ioctl", "prctl", "fcntl",
+	"read", "write", "chdir", "setgroups", "setresuid", "setresgid",
+}
@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestGoWrapperSyscalls(t *testing.T) {
+	normalized := map[string]string{}
+	for _, name := range arch.X86_64.Syscalls() {
+		normalized[strings.ReplaceAll(name, "_", "")] = name
+	}
+
+	for fn, want := range map[string][]string{
+		"syscall.Getpid":                                     {"getpid"},
+		"syscall.openat":                                     {"openat"},
+		"syscall.Open":                                       {"openat"},
+		"golang.org/x/sys/unix.EpollCreate1":                 {"epoll_create1"},
+		"golang.org/x/sys/unix.GetsockoptInt":                {"getsockopt"},
+		"vendor/golang.org/x/sys/unix.Getrandom":             {"getrandom"},
+		"example.com/app/vendor/golang.org/x/sys/unix.Mount": {"mount"},
+		"internal/syscall/unix.Fstatat":                      {"newfstatat", "fstatat64"},
+		"syscall.Syscall6":                                   nil,
+		"syscall.(*RawConn).Read":                            nil,
+		"syscall.Open.func1":                                 nil,
+		"os.Getpid":                                          nil,
+		"example.com/syscall.Getpid":                         nil,
+	} {
+		if got := goWrapperSyscalls(fn, normalized); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: expected %v, got %v", fn, want, got)
+		}
+	}
+}
+
+func TestAnalyzeGoBinaryFile(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	analysis, err := AnalyzeGoBinaryFile(exe)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	if !strings.HasPrefix(analysis.GoVersion, "go") {
+		t.Errorf("unexpected Go version %q", analysis.GoVersion)
+	}
+	if len(analysis.Wrappers) == 0 {
+		t.Fatal("expected syscall wrappers")
+	}
+	// The test binary opens files with os.Open.
+	for _, name := range []string{"openat", "futex", "exit_group"} {
+		if analysis.Trace.Profile[name] == 0 {
+			t.Errorf("expected %v in trace %v", name, analysis.Trace.Names())
+		}
+	}
+
+	policy, err := analysis.Policy(DraftOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := policy.Assemble(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := AnalyzeGoBinaryFile("/bin/true"); err != nil && !errors.Is(err, ErrNotGoBinary) && !os.IsNotExist(err) {
+		t.Errorf("expected ErrNotGoBinary for /bin/true, got %v", err)
+	}
+}
@@ -24,11 +24,21 @@ import (
 	linux "golang.org/x/sys/unix"
 )
 
-const PR_SET_NO_NEW_PRIVS = linux.PR_SET_NO_NEW_PRIVS
+const (
+	PR_GET_NO_NEW_PRIVS = linux.PR_GET_NO_NEW_PRIVS
+	PR_SET_NO_NEW_PRIVS = linux.PR_SET_NO_NEW_PRIVS
+	PR_SET_SECCOMP      = linux.PR_SET_SECCOMP
+)
+
+const (
+	SECCOMP_MODE_STRICT = linux.SECCOMP_MODE_STRICT
+	SECCOMP_MODE_FILTER = linux.SECCOMP_MODE_FILTER
+)
 
 const (
-	SECCOMP_SET_MODE_STRICT = linux.SECCOMP_SET_MODE_STRICT
-	SECCOMP_SET_MODE_FILTER = linux.SECCOMP_SET_MODE_FILTER
+	SECCOMP_SET_MODE_STRICT  = linux.SECCOMP_SET_MODE_STRICT
+	SECCOMP_SET_MODE_FILTER  = linux.SECCOMP_SET_MODE_FILTER
+	SECCOMP_GET_ACTION_AVAIL = linux.SECCOMP_GET_ACTION_AVAIL
 )
 
 const (
@@ -40,6 +50,7 @@ const (
 	SECCOMP_RET_LOG          = linux.SECCOMP_RET_LOG
 	SECCOMP_RET_ALLOW        = linux.SECCOMP_RET_ALLOW
 	SECCOMP_RET_USER_NOTIF   = linux.SECCOMP_RET_USER_NOTIF
+	SECCOMP_RET_ACTION_FULL  = linux.SECCOMP_RET_ACTION_FULL
 )
 
 const (
@@ -48,6 +59,9 @@ const (
 )
 
 const (
-	SECCOMP_FILTER_FLAG_TSYNC = linux.SECCOMP_FILTER_FLAG_TSYNC
-	SECCOMP_FILTER_FLAG_LOG   = linux.SECCOMP_FILTER_FLAG_LOG
+	SECCOMP_FILTER_FLAG_TSYNC        = linux.SECCOMP_FILTER_FLAG_TSYNC
+	SECCOMP_FILTER_FLAG_LOG          = linux.SECCOMP_FILTER_FLAG_LOG
+	SECCOMP_FILTER_FLAG_SPEC_ALLOW   = linux.SECCOMP_FILTER_FLAG_SPEC_ALLOW
+	SECCOMP_FILTER_FLAG_NEW_LISTENER = linux.SECCOMP_FILTER_FLAG_NEW_LISTENER
+	SECCOMP_FILTER_FLAG_TSYNC_ESRCH  = linux.SECCOMP_FILTER_FLAG_TSYNC_ESRCH
 )
@@ -20,11 +20,21 @@
 
 package unix
 
-const PR_SET_NO_NEW_PRIVS = 0x26
+const (
+	PR_GET_NO_NEW_PRIVS = 0x27
+	PR_SET_NO_NEW_PRIVS = 0x26
+	PR_SET_SECCOMP      = 0x16
+)
+
+const (
+	SECCOMP_MODE_STRICT = 0x1
+	SECCOMP_MODE_FILTER = 0x2
+)
 
 const (
-	SECCOMP_SET_MODE_STRICT = 0x0
-	SECCOMP_SET_MODE_FILTER = 0x1
+	SECCOMP_SET_MODE_STRICT  = 0x0
+	SECCOMP_SET_MODE_FILTER  = 0x1
+	SECCOMP_GET_ACTION_AVAIL = 0x2
 )
 
 const (
@@ -36,6 +46,7 @@ const (
 	SECCOMP_RET_LOG          = 0x7ffc0000
 	SECCOMP_RET_ALLOW        = 0x7fff0000
 	SECCOMP_RET_USER_NOTIF   = 0x7fc00000
+	SECCOMP_RET_ACTION_FULL  = 0xffff0000
 )
 
 const (
@@ -44,6 +55,9 @@ const (
 )
 
 const (
-	SECCOMP_FILTER_FLAG_TSYNC = 0x1
-	SECCOMP_FILTER_FLAG_LOG   = 0x2
+	SECCOMP_FILTER_FLAG_TSYNC        = 0x1
+	SECCOMP_FILTER_FLAG_LOG          = 0x2
+	SECCOMP_FILTER_FLAG_SPEC_ALLOW   = 0x4
+	SECCOMP_FILTER_FLAG_NEW_LISTENER = 0x8
+	SECCOMP_FILTER_FLAG_TSYNC_ESRCH  = 0x10
 )
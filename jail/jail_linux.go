@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package jail
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Namespaces is a set of Linux namespaces.
+type Namespaces uintptr
+
+// Namespaces that can be created for the process.
+const (
+	User    Namespaces = syscall.CLONE_NEWUSER // Maps the caller to UID and GID.
+	Mount   Namespaces = syscall.CLONE_NEWNS   // Private copy of the mount table.
+	Network Namespaces = syscall.CLONE_NEWNET  // Only an unconfigured loopback device.
+	PID     Namespaces = syscall.CLONE_NEWPID  // The process becomes PID 1.
+	IPC     Namespaces = syscall.CLONE_NEWIPC  // Separate System V IPC and message queues.
+	UTS     Namespaces = syscall.CLONE_NEWUTS  // Separate host and domain name.
+)
+
+// Jail describes the sandbox of a process.
+type Jail struct {
+	// Filter is installed after the namespaces were created, immediately
+	// before the program is executed. Its policy must allow execve.
+	Filter seccomp.Filter
+
+	// Namespaces to create. Creating namespaces other than User requires
+	// CAP_SYS_ADMIN, which the process has in its own user namespace.
+	Namespaces Namespaces
+
+	// UID and GID of the process inside the user namespace. The calling
+	// process's effective IDs are mapped to them. The default is root.
+	UID, GID int
+}
+
+// Command returns an *exec.Cmd that runs the named program in the jail. The
+// command is created with seccomp.Command and the namespaces are created
+// when it is started. The caller must not replace cmd.SysProcAttr.
+func (j *Jail) Command(name string, arg ...string) *exec.Cmd {
+	cmd := seccomp.Command(j.Filter, name, arg...)
+	cmd.SysProcAttr = j.sysProcAttr()
+	return cmd
+}
+
+// CommandListener is like Command for filters that use
+// seccomp.ActionUserNotify. See seccomp.CommandListener.
+func (j *Jail) CommandListener(name string, arg ...string) (*exec.Cmd, *seccomp.ListenerReceiver) {
+	cmd, receiver := seccomp.CommandListener(j.Filter, name, arg...)
+	cmd.SysProcAttr = j.sysProcAttr()
+	return cmd, receiver
+}
+
+func (j *Jail) sysProcAttr() *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{Cloneflags: uintptr(j.Namespaces)}
+	if j.Namespaces&User != 0 {
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: j.UID, HostID: os.Geteuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: j.GID, HostID: os.Getegid(), Size: 1}}
+	}
+	return attr
+}
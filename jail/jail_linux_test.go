@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package jail
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func TestJailCommand(t *testing.T) {
+	if !seccomp.Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+	netns, err := os.Readlink("/proc/self/ns/net")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	j := &Jail{
+		Filter: seccomp.Filter{
+			NoNewPrivs: true,
+			Policy: seccomp.Policy{
+				DefaultAction: seccomp.ActionAllow,
+				Syscalls: []seccomp.SyscallGroup{
+					{Action: seccomp.ActionErrno, Names: []string{"mkdir", "mkdirat"}},
+				},
+			},
+		},
+		Namespaces: User | Mount | Network | PID,
+		UID:        1000,
+		GID:        1000,
+	}
+	cmd := j.Command("sh", "-c", `readlink /proc/self/ns/net; id -u; id -g; grep Seccomp: /proc/self/status; echo $$; mkdir dir`)
+	cmd.Dir = t.TempDir()
+	out, err := cmd.CombinedOutput()
+	if err != nil && cmd.ProcessState == nil {
+		t.Skipf("cannot create namespaces: %v", err)
+	}
+	if err == nil {
+		t.Fatalf("expected mkdir to fail: %s", out)
+	}
+
+	lines := strings.Split(string(out), "\n")
+	if len(lines) < 6 {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if lines[0] == netns {
+		t.Error("expected a new network namespace")
+	}
+	if lines[1] != "1000" || lines[2] != "1000" {
+		t.Errorf("expected uid and gid 1000, got %q and %q", lines[1], lines[2])
+	}
+	if lines[3] != "Seccomp:\t2" {
+		t.Errorf("expected filter mode, got %q", lines[3])
+	}
+	if lines[4] != "1" {
+		t.Errorf("expected PID 1, got %q", lines[4])
+	}
+	if !strings.Contains(lines[5], "Operation not permitted") {
+		t.Errorf("expected mkdir to fail with EPERM, got %q", lines[5])
+	}
+}
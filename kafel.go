@@ -0,0 +1,789 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// KafelOptions configure ReadKafelPolicy.
+type KafelOptions struct {
+	// Arch is the architecture of the policy. It defaults to the native
+	// architecture.
+	Arch string
+
+	// Constants resolves identifiers in expressions in addition to the
+	// policy's #define statements.
+	Constants map[string]uint64
+}
+
+// maxKafelClauses limits the size of an argument expression after it has
+// been converted into OR'ed lists of conditions.
+const maxKafelClauses = 64
+
+// ReadKafelPolicy reads a policy written in kafel, the policy language of
+// nsjail. The entries at the top level and the policies listed by USE are
+// evaluated in order and the first matching rule decides the action. The
+// default action is KILL unless the USE statement sets DEFAULT.
+//
+// Argument names must be declared in the rule (e.g. "openat(dirfd, path,
+// flags) { flags & 0x40 == 0 }"), kafel's built-in names are not known.
+// Comparisons must be between an argument, optionally masked with &, and a
+// constant, and masked equality is only supported against zero or against
+// a single bit. #include is not supported.
+func ReadKafelPolicy(r io.Reader, opts KafelOptions) (*Policy, error) {
+	info, err := arch.GetInfo(opts.Arch)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	defines, src, err := kafelPreprocess(string(data))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := kafelTokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &kafelParser{
+		opts:     opts,
+		arch:     info,
+		tokens:   tokens,
+		defines:  defines,
+		values:   map[string]uint64{},
+		policies: map[string][]SyscallGroup{},
+	}
+	return p.parse()
+}
+
+// kafelPreprocess removes comments and collects #define statements.
+func kafelPreprocess(src string) (map[string]string, string, error) {
+	var out strings.Builder
+	for len(src) > 0 {
+		switch {
+		case strings.HasPrefix(src, "//"):
+			end := strings.IndexByte(src, '\n')
+			if end < 0 {
+				end = len(src)
+			}
+			src = src[end:]
+		case strings.HasPrefix(src, "/*"):
+			end := strings.Index(src, "*/")
+			if end < 0 {
+				return nil, "", errors.New("unterminated comment")
+			}
+			out.WriteByte(' ')
+			src = src[end+2:]
+		default:
+			out.WriteByte(src[0])
+			src = src[1:]
+		}
+	}
+
+	defines := map[string]string{}
+	var lines []string
+	s := bufio.NewScanner(strings.NewReader(out.String()))
+	for s.Scan() {
+		line := s.Text()
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			lines = append(lines, line)
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(trimmed, "#"))
+		if len(fields) < 3 || fields[0] != "define" {
+			return nil, "", fmt.Errorf("unsupported directive %q", trimmed)
+		}
+		defines[fields[1]] = strings.Join(fields[2:], " ")
+		lines = append(lines, "")
+	}
+	return defines, strings.Join(lines, "\n"), s.Err()
+}
+
+func kafelTokenize(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			if i+1 < len(src) {
+				switch op := src[i : i+2]; op {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, op)
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("{}()[],;&|!<>~", c) {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+type kafelParser struct {
+	opts     KafelOptions
+	arch     *arch.Info
+	tokens   []string
+	pos      int
+	defines  map[string]string
+	values   map[string]uint64 // Evaluated defines.
+	policies map[string][]SyscallGroup
+	args     map[string]uint32 // Argument names of the current rule.
+}
+
+func (p *kafelParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *kafelParser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *kafelParser) accept(token string) bool {
+	if p.peek() == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *kafelParser) expect(token string) error {
+	if t := p.next(); t != token {
+		return fmt.Errorf("expected %q but got %q", token, t)
+	}
+	return nil
+}
+
+func (p *kafelParser) parse() (*Policy, error) {
+	policy := &Policy{arch: p.arch, DefaultAction: ActionKillThread}
+	for p.peek() != "" {
+		switch {
+		case p.accept("POLICY"):
+			name := p.next()
+			if _, found := p.policies[name]; found {
+				return nil, fmt.Errorf("duplicate policy %q", name)
+			}
+			if err := p.expect("{"); err != nil {
+				return nil, err
+			}
+			groups, err := p.parseBody("}")
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: %w", name, err)
+			}
+			p.policies[name] = groups
+		default:
+			groups, err := p.parseBody("")
+			if err != nil {
+				return nil, err
+			}
+			policy.Syscalls = append(policy.Syscalls, groups...)
+			if p.accept("DEFAULT") {
+				if policy.DefaultAction, err = p.parseAction(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		p.accept(",")
+		p.accept(";")
+	}
+	if len(policy.Syscalls) == 0 {
+		policy.Syscalls = []SyscallGroup{{Action: policy.DefaultAction}}
+	}
+	return policy, nil
+}
+
+// parseBody parses comma separated action blocks and USE statements until
+// the end token. An empty end token parses the top-level entries that
+// precede a POLICY or DEFAULT.
+func (p *kafelParser) parseBody(end string) ([]SyscallGroup, error) {
+	var groups []SyscallGroup
+	for {
+		switch t := p.peek(); {
+		case end != "" && p.accept(end):
+			return groups, nil
+		case end == "" && (t == "" || t == "POLICY" || t == "DEFAULT"):
+			return groups, nil
+		case p.accept("USE"):
+			for {
+				name := p.next()
+				used, found := p.policies[name]
+				if !found {
+					return nil, fmt.Errorf("undefined policy %q", name)
+				}
+				groups = append(groups, used...)
+				// A comma followed by a policy name continues the list.
+				if p.peek() != "," || p.pos+1 >= len(p.tokens) || p.policies[p.tokens[p.pos+1]] == nil {
+					break
+				}
+				p.next()
+			}
+		default:
+			group, err := p.parseActionBlock()
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, group)
+		}
+		if !p.accept(",") && p.peek() != end && end != "" {
+			return nil, fmt.Errorf("expected ',' or %q but got %q", end, p.peek())
+		}
+	}
+}
+
+func (p *kafelParser) parseAction() (Action, error) {
+	name := p.next()
+	var action Action
+	switch name {
+	case "ALLOW":
+		return ActionAllow, nil
+	case "LOG":
+		return ActionLog, nil
+	case "KILL", "KILL_THREAD", "DENY":
+		return ActionKillThread, nil
+	case "KILL_PROCESS":
+		return ActionKillProcess, nil
+	case "USER_NOTIF":
+		return ActionUserNotify, nil
+	case "ERRNO":
+		action = ActionErrno
+	case "TRAP":
+		action = ActionTrap
+	case "TRACE":
+		action = ActionTrace
+	default:
+		return 0, fmt.Errorf("unknown action %q", name)
+	}
+
+	if err := p.expect("("); err != nil {
+		return 0, err
+	}
+	value, err := p.constant(p.next())
+	if err != nil {
+		return 0, err
+	}
+	if value > 0xffff {
+		return 0, fmt.Errorf("%s value %d is out of range", name, value)
+	}
+	return action | Action(value), p.expect(")")
+}
+
+func (p *kafelParser) parseActionBlock() (SyscallGroup, error) {
+	action, err := p.parseAction()
+	if err != nil {
+		return SyscallGroup{}, err
+	}
+	group := SyscallGroup{Action: action}
+	if err := p.expect("{"); err != nil {
+		return SyscallGroup{}, err
+	}
+	for !p.accept("}") {
+		name, conditions, err := p.parseRule()
+		if err != nil {
+			return SyscallGroup{}, err
+		}
+		if conditions == nil {
+			group.Names = append(group.Names, name)
+		}
+		for _, c := range conditions {
+			group.NamesWithCondtions = append(group.NamesWithCondtions, NameWithConditions{Name: name, Conditions: c})
+		}
+		if !p.accept(",") && p.peek() != "}" {
+			return SyscallGroup{}, fmt.Errorf("expected ',' or '}' but got %q", p.peek())
+		}
+	}
+	return group, nil
+}
+
+func (p *kafelParser) parseRule() (string, []ArgumentConditions, error) {
+	name := p.next()
+	if name == "SYSCALL" {
+		if err := p.expect("["); err != nil {
+			return "", nil, err
+		}
+		num, err := p.constant(p.next())
+		if err != nil {
+			return "", nil, err
+		}
+		if name = p.arch.SyscallName(int(num)); name == "" {
+			return "", nil, fmt.Errorf("unknown syscall number %d", num)
+		}
+		if err := p.expect("]"); err != nil {
+			return "", nil, err
+		}
+	} else if _, found := p.arch.SyscallNumber(name); !found {
+		return "", nil, fmt.Errorf("unknown syscall %q", name)
+	}
+
+	p.args = map[string]uint32{}
+	for i := uint32(0); i < 6; i++ {
+		p.args["arg"+strconv.Itoa(int(i))] = i
+	}
+	if p.accept("(") {
+		for i := uint32(0); !p.accept(")"); i++ {
+			if i > 0 {
+				if err := p.expect(","); err != nil {
+					return "", nil, err
+				}
+			}
+			if i > 5 {
+				return "", nil, fmt.Errorf("%s: too many arguments", name)
+			}
+			p.args[p.next()] = i
+		}
+	}
+
+	if !p.accept("{") {
+		return name, nil, nil
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := p.expect("}"); err != nil {
+		return "", nil, err
+	}
+	conditions, err := expr.dnf(false)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return name, conditions, nil
+}
+
+// kafelExpr is a boolean expression over argument conditions.
+type kafelExpr struct {
+	op       string // "||", "&&", "!", or "" for a condition.
+	operands []*kafelExpr
+	cond     Condition
+}
+
+// dnf converts the expression into OR'ed lists of AND'ed conditions.
+func (e *kafelExpr) dnf(negate bool) ([]ArgumentConditions, error) {
+	op := e.op
+	if negate {
+		// De Morgan's laws.
+		switch op {
+		case "||":
+			op = "&&"
+		case "&&":
+			op = "||"
+		}
+	}
+
+	switch op {
+	case "":
+		c := e.cond
+		if negate {
+			c.Operation = negateOperation[c.Operation]
+		}
+		return []ArgumentConditions{{c}}, nil
+	case "!":
+		return e.operands[0].dnf(!negate)
+	case "||":
+		var out []ArgumentConditions
+		for _, o := range e.operands {
+			clauses, err := o.dnf(negate)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, clauses...)
+		}
+		if len(out) > maxKafelClauses {
+			return nil, errors.New("expression is too complex")
+		}
+		return out, nil
+	default:
+		out := []ArgumentConditions{nil}
+		for _, o := range e.operands {
+			clauses, err := o.dnf(negate)
+			if err != nil {
+				return nil, err
+			}
+			var product []ArgumentConditions
+			for _, a := range out {
+				for _, b := range clauses {
+					product = append(product, append(a[:len(a):len(a)], b...))
+				}
+			}
+			if len(product) > maxKafelClauses {
+				return nil, errors.New("expression is too complex")
+			}
+			out = product
+		}
+		return out, nil
+	}
+}
+
+var negateOperation = map[Operation]Operation{
+	Equal:          NotEqual,
+	NotEqual:       Equal,
+	LessThan:       GreaterOrEqual,
+	GreaterOrEqual: LessThan,
+	GreaterThan:    LessOrEqual,
+	LessOrEqual:    GreaterThan,
+	BitsSet:        BitsNotSet,
+	BitsNotSet:     BitsSet,
+}
+
+func (p *kafelParser) parseOr() (*kafelExpr, error) {
+	return p.parseBinary("||", p.parseAnd)
+}
+
+func (p *kafelParser) parseAnd() (*kafelExpr, error) {
+	return p.parseBinary("&&", p.parseUnary)
+}
+
+func (p *kafelParser) parseBinary(op string, operand func() (*kafelExpr, error)) (*kafelExpr, error) {
+	e, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != op {
+		return e, nil
+	}
+	e = &kafelExpr{op: op, operands: []*kafelExpr{e}}
+	for p.accept(op) {
+		o, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		e.operands = append(e.operands, o)
+	}
+	return e, nil
+}
+
+func (p *kafelParser) parseUnary() (*kafelExpr, error) {
+	if p.accept("!") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &kafelExpr{op: "!", operands: []*kafelExpr{e}}, nil
+	}
+
+	// A parenthesis starts either a boolean expression or a value such as
+	// "(flags & 1) == 0". Try the comparison first.
+	start := p.pos
+	e, err := p.parseComparison()
+	if err == nil || start >= len(p.tokens) || p.tokens[start] != "(" {
+		return e, err
+	}
+	p.pos = start + 1
+	if e, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	return e, p.expect(")")
+}
+
+// kafelValue is an argument (arg >= 0) masked with mask, or a constant.
+type kafelValue struct {
+	arg   int
+	mask  uint64
+	value uint64
+}
+
+func (p *kafelParser) parseComparison() (*kafelExpr, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("expected comparison operator but got %q", op)
+	}
+	right, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if left.arg < 0 {
+		// Put the argument on the left.
+		left, right = right, left
+		switch op {
+		case "<":
+			op = ">"
+		case "<=":
+			op = ">="
+		case ">":
+			op = "<"
+		case ">=":
+			op = "<="
+		}
+	}
+	if left.arg < 0 || right.arg >= 0 {
+		return nil, errors.New("comparisons must be between an argument and a constant")
+	}
+
+	c := Condition{Argument: uint32(left.arg), Value: right.value}
+	if left.mask == ^uint64(0) {
+		c.Operation = map[string]Operation{
+			"==": Equal, "!=": NotEqual, "<": LessThan,
+			"<=": LessOrEqual, ">": GreaterThan, ">=": GreaterOrEqual,
+		}[op]
+		return &kafelExpr{cond: c}, nil
+	}
+
+	// (arg & mask) == value
+	mask, value := left.mask, right.value
+	singleBit := mask != 0 && mask&(mask-1) == 0
+	switch {
+	case op != "==" && op != "!=":
+		return nil, errors.New("masked arguments can only be compared with == and !=")
+	case value == 0:
+		c.Operation, c.Value = BitsNotSet, mask
+	case value == mask && singleBit:
+		c.Operation, c.Value = BitsSet, mask
+	default:
+		return nil, fmt.Errorf("unsupported masked comparison with mask %#x and value %#x", mask, value)
+	}
+	if op == "!=" {
+		c.Operation = negateOperation[c.Operation]
+	}
+	return &kafelExpr{cond: c}, nil
+}
+
+// parseValue parses a value made of arguments and constants combined with
+// & and |.
+func (p *kafelParser) parseValue() (kafelValue, error) {
+	v, err := p.parseTerm()
+	if err != nil {
+		return v, err
+	}
+	for p.peek() == "&" || p.peek() == "|" {
+		op := p.next()
+		w, err := p.parseTerm()
+		if err != nil {
+			return v, err
+		}
+		switch {
+		case v.arg < 0 && w.arg < 0 && op == "&":
+			v.value &= w.value
+		case v.arg < 0 && w.arg < 0:
+			v.value |= w.value
+		case op == "&" && (v.arg < 0) != (w.arg < 0):
+			if v.arg < 0 {
+				v, w = w, v
+			}
+			v.mask &= w.value
+		default:
+			return v, errors.New("unsupported combination of arguments")
+		}
+	}
+	return v, nil
+}
+
+func (p *kafelParser) parseTerm() (kafelValue, error) {
+	t := p.next()
+	switch {
+	case t == "(":
+		v, err := p.parseValue()
+		if err != nil {
+			return v, err
+		}
+		return v, p.expect(")")
+	case t == "~":
+		v, err := p.parseTerm()
+		if err != nil || v.arg >= 0 {
+			return v, errors.New("~ can only be applied to constants")
+		}
+		v.value = ^v.value
+		return v, nil
+	}
+	if arg, found := p.args[t]; found {
+		return kafelValue{arg: int(arg), mask: ^uint64(0)}, nil
+	}
+	value, err := p.constant(t)
+	return kafelValue{arg: -1, value: value}, err
+}
+
+// constant resolves a number, a #define, or a constant from the options.
+func (p *kafelParser) constant(t string) (uint64, error) {
+	if v, err := strconv.ParseUint(t, 0, 64); err == nil {
+		return v, nil
+	}
+	if v, found := p.values[t]; found {
+		return v, nil
+	}
+	if def, found := p.defines[t]; found {
+		tokens, err := kafelTokenize(def)
+		if err != nil {
+			return 0, err
+		}
+		// Evaluate the define with a parser of its own, it cannot refer
+		// to arguments.
+		sub := &kafelParser{opts: p.opts, arch: p.arch, tokens: tokens, defines: p.defines, values: p.values}
+		delete(p.defines, t) // Prevent recursion.
+		v, err := sub.parseValue()
+		p.defines[t] = def
+		if err != nil || v.arg >= 0 || sub.peek() != "" {
+			return 0, fmt.Errorf("invalid value of #define %s", t)
+		}
+		p.values[t] = v.value
+		return v.value, nil
+	}
+	if v, found := p.opts.Constants[t]; found {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown identifier %q", t)
+}
+
+// WriteKafelPolicy writes the policy in kafel's policy language as a single
+// POLICY that is used with the policy's default action. The conditions of a
+// syscall in a group are combined into one rule, and syscalls that a group
+// names without conditions are not repeated with them.
+func (p *Policy) WriteKafelPolicy(w io.Writer) error {
+	defaultAction, err := kafelAction(p.DefaultAction)
+	if err != nil {
+		return err
+	}
+
+	var blocks []string
+	for _, group := range p.groups() {
+		action, err := kafelAction(group.Action)
+		if err != nil {
+			return err
+		}
+
+		var rules []string
+		unconditional := map[string]bool{}
+		for _, name := range group.Names {
+			if !unconditional[name] {
+				rules = append(rules, name)
+				unconditional[name] = true
+			}
+		}
+		var names []string
+		conditions := map[string][]ArgumentConditions{}
+		for _, s := range group.NamesWithCondtions {
+			if unconditional[s.Name] {
+				continue
+			}
+			if _, found := conditions[s.Name]; !found {
+				names = append(names, s.Name)
+			}
+			conditions[s.Name] = append(conditions[s.Name], s.Conditions)
+		}
+		for _, name := range names {
+			rules = append(rules, kafelRule(name, conditions[name]))
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		blocks = append(blocks, fmt.Sprintf("\t%s {\n\t\t%s\n\t}", action, strings.Join(rules, ",\n\t\t")))
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "POLICY policy {\n%s\n}\n", strings.Join(blocks, ",\n"))
+	fmt.Fprintf(bw, "\nUSE policy DEFAULT %s\n", defaultAction)
+	return bw.Flush()
+}
+
+func kafelAction(a Action) (string, error) {
+	value := a.returnValue() &^ actionMask
+	switch a & actionMask {
+	case ActionAllow:
+		return "ALLOW", nil
+	case ActionLog:
+		return "LOG", nil
+	case ActionKillThread:
+		return "KILL", nil
+	case ActionKillProcess:
+		return "KILL_PROCESS", nil
+	case ActionUserNotify:
+		return "USER_NOTIF", nil
+	case ActionErrno:
+		return fmt.Sprintf("ERRNO(%d)", value), nil
+	case ActionTrap:
+		return fmt.Sprintf("TRAP(%d)", value), nil
+	case ActionTrace:
+		return fmt.Sprintf("TRACE(%d)", value), nil
+	}
+	return "", fmt.Errorf("unsupported action %v", a)
+}
+
+// kafelRule returns a rule such as "openat(arg0, arg1, arg2) { arg2 & 0x40
+// == 0 }" that matches if any of the lists of conditions matches.
+func kafelRule(name string, conditions []ArgumentConditions) string {
+	var maxArg uint32
+	var clauses []string
+	for _, list := range conditions {
+		var atoms []string
+		for _, c := range list {
+			maxArg = max(maxArg, c.Argument)
+			atoms = append(atoms, kafelCondition(c))
+		}
+		clause := strings.Join(atoms, " && ")
+		if len(conditions) > 1 && len(atoms) > 1 {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+	}
+
+	args := make([]string, maxArg+1)
+	for i := range args {
+		args[i] = "arg" + strconv.Itoa(i)
+	}
+	return fmt.Sprintf("%s(%s) { %s }", name, strings.Join(args, ", "), strings.Join(clauses, " || "))
+}
+
+func kafelCondition(c Condition) string {
+	arg := "arg" + strconv.Itoa(int(c.Argument))
+	switch c.Operation {
+	case BitsSet:
+		return fmt.Sprintf("%s & %#x != 0", arg, c.Value)
+	case BitsNotSet:
+		return fmt.Sprintf("%s & %#x == 0", arg, c.Value)
+	}
+	return fmt.Sprintf("%s %s %#x", arg, pfcOperators[c.Operation], c.Value)
+}
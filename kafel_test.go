@@ -0,0 +1,202 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+const testKafelPolicy = `
+#define O_CREAT 0x40
+#define PROT_EXEC 4
+#define ALLOWED_FD 1
+
+/* Syscalls needed by every process. */
+POLICY basic {
+	ALLOW {
+		read, write, exit_group,
+		SYSCALL[60] // exit
+	}
+}
+
+POLICY files {
+	ERRNO(13) {
+		openat(dirfd, path, flags) { (flags & O_CREAT) != 0 }
+	},
+	ALLOW {
+		openat, close
+	}
+}
+
+POLICY memory {
+	ALLOW {
+		mmap(addr, len, prot) { !(prot & PROT_EXEC == PROT_EXEC) && len <= 0x100000 },
+		ioctl(fd, request) { fd == ALLOWED_FD || 0x5401 == request }
+	},
+	KILL_PROCESS { ptrace }
+}
+
+USE basic, files, memory DEFAULT ERRNO(38)
+`
+
+func TestReadKafelPolicy(t *testing.T) {
+	policy, err := ReadKafelPolicy(strings.NewReader(testKafelPolicy), KafelOptions{Arch: "x86_64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	enosys := ActionErrno | Action(38)
+	simulateSyscalls(t, policy, []SeccompTest{
+		{data("read"), ActionAllow},
+		{data("exit"), ActionAllow},
+		{data("openat", 0, 0, 0x40), ActionErrno | Action(13)},
+		{data("openat", 0, 0, 0x2), ActionAllow},
+		{data("close"), ActionAllow},
+		{data("mmap", 0, 4096, 0x3), ActionAllow},
+		{data("mmap", 0, 4096, 0x7), enosys},
+		{data("mmap", 0, 0x200000, 0x3), enosys},
+		{data("ioctl", 1, 0), ActionAllow},
+		{data("ioctl", 2, 0x5401), ActionAllow},
+		{data("ioctl", 2, 0x5402), enosys},
+		{data("ptrace"), ActionKillProcess},
+		{data("mount"), enosys},
+	})
+}
+
+func TestReadKafelPolicyTopLevel(t *testing.T) {
+	policy, err := ReadKafelPolicy(strings.NewReader("ALLOW { read }, LOG { write }"), KafelOptions{Arch: "x86_64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{systemdData("read"), ActionAllow},
+		{systemdData("write"), ActionLog},
+		{systemdData("close"), ActionKillThread},
+	})
+}
+
+func TestWriteKafelPolicy(t *testing.T) {
+	policy := Policy{
+		DefaultAction: ActionErrno | Action(38),
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionAllow,
+				Names:  []string{"read", "write"},
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "socket", Conditions: ArgumentConditions{
+						{Argument: 0, Operation: Equal, Value: 1},
+						{Argument: 1, Operation: BitsNotSet, Value: 0x80000},
+					}},
+					{Name: "socket", Conditions: ArgumentConditions{
+						{Argument: 0, Operation: Equal, Value: 2},
+					}},
+				},
+			},
+			{
+				Action: ActionKillProcess,
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "mmap", Conditions: ArgumentConditions{
+						{Argument: 2, Operation: BitsSet, Value: 0x6},
+					}},
+				},
+			},
+		},
+	}
+
+	var buf strings.Builder
+	if err := policy.WriteKafelPolicy(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `POLICY policy {
+	ALLOW {
+		read,
+		write,
+		socket(arg0, arg1) { (arg0 == 0x1 && arg1 & 0x80000 == 0) || arg0 == 0x2 }
+	},
+	KILL_PROCESS {
+		mmap(arg0, arg1, arg2) { arg2 & 0x6 != 0 }
+	}
+}
+
+USE policy DEFAULT ERRNO(38)
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected policy:\n%s", buf.String())
+	}
+}
+
+func TestWriteKafelPolicyRoundTrip(t *testing.T) {
+	policy, err := ReadKafelPolicy(strings.NewReader(testKafelPolicy), KafelOptions{Arch: "x86_64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if err := policy.WriteKafelPolicy(&buf); err != nil {
+		t.Fatal(err)
+	}
+	written, err := ReadKafelPolicy(strings.NewReader(buf.String()), KafelOptions{Arch: "x86_64"})
+	if err != nil {
+		t.Fatalf("failed to read the written policy: %v\n%s", err, buf.String())
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	enosys := ActionErrno | Action(38)
+	simulateSyscalls(t, written, []SeccompTest{
+		{data("read"), ActionAllow},
+		{data("exit"), ActionAllow},
+		{data("openat", 0, 0, 0x40), ActionErrno | Action(13)},
+		{data("openat", 0, 0, 0x2), ActionAllow},
+		{data("mmap", 0, 4096, 0x7), enosys},
+		{data("ioctl", 2, 0x5401), ActionAllow},
+		{data("ioctl", 2, 0x5402), enosys},
+		{data("ptrace"), ActionKillProcess},
+		{data("mount"), enosys},
+	})
+}
+
+func TestReadKafelPolicyErrors(t *testing.T) {
+	for _, policy := range []string{
+		"ALLOW { no_such_syscall }",
+		"ALLOW { read(fd) { fd == UNKNOWN } }",
+		"ALLOW { read(fd) { fd == fd } }",
+		"ALLOW { read(fd) { (fd & 3) == 1 } }",
+		"ALLOW { read(fd) { fd & 3 < 1 } }",
+		"ALLOW { read } USE missing",
+		"MAYBE { read }",
+		"ALLOW { read",
+		"#include \"other.policy\"\nALLOW { read }",
+		"POLICY a { ALLOW { read } } POLICY a { ALLOW { write } }",
+		"ALLOW { SYSCALL[9999] }",
+	} {
+		if _, err := ReadKafelPolicy(strings.NewReader(policy), KafelOptions{Arch: "x86_64"}); err == nil {
+			t.Errorf("expected error for %q", policy)
+		}
+	}
+}
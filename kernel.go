@@ -0,0 +1,284 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KernelVersion is a Linux kernel version.
+type KernelVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseKernelVersion parses the major and minor version from a kernel release
+// string like "5.15.0-91-generic".
+func ParseKernelVersion(release string) (KernelVersion, error) {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return KernelVersion{}, fmt.Errorf("invalid kernel release: %v", release)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return KernelVersion{}, fmt.Errorf("invalid kernel release: %v", release)
+	}
+
+	// The minor version may be followed by a suffix (e.g. "4-rc1").
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	minorNum, err := strconv.Atoi(minor)
+	if err != nil {
+		return KernelVersion{}, fmt.Errorf("invalid kernel release: %v", release)
+	}
+
+	return KernelVersion{Major: major, Minor: minorNum}, nil
+}
+
+// String returns the version as "major.minor".
+func (v KernelVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Less returns true if v is older than other.
+func (v KernelVersion) Less(other KernelVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	return v.Minor < other.Minor
+}
+
+// filterFlagMinKernel contains the first kernel version supporting each flag.
+var filterFlagMinKernel = map[FilterFlag]KernelVersion{
+	FilterFlagTSync:       {3, 17},
+	FilterFlagLog:         {4, 14},
+	FilterFlagSpecAllow:   {4, 17},
+	FilterFlagNewListener: {5, 0},
+	FilterFlagTSyncESRCH:  {5, 7},
+}
+
+// Kernel versions of basic seccomp features.
+var (
+	minKernelFilter  = KernelVersion{3, 5}  // prctl(PR_SET_SECCOMP, SECCOMP_MODE_FILTER)
+	minKernelSyscall = KernelVersion{3, 17} // seccomp(2)
+)
+
+// MinKernelVersion returns the oldest kernel version that supports all of
+// the flags. Filters without flags can be installed since Linux 3.5.
+func (f FilterFlag) MinKernelVersion() KernelVersion {
+	if f == 0 {
+		return minKernelFilter
+	}
+
+	min := minKernelSyscall
+	for flag, version := range filterFlagMinKernel {
+		if f&flag != 0 && min.Less(version) {
+			min = version
+		}
+	}
+	return min
+}
+
+// actionMinKernel contains the first kernel version supporting actions that
+// were added after seccomp filters.
+var actionMinKernel = map[Action]KernelVersion{
+	ActionKillProcess: {4, 14},
+	ActionLog:         {4, 14},
+	ActionUserNotify:  {5, 0},
+}
+
+// syscallMinKernel contains the first kernel version providing syscalls that
+// were added after seccomp filters (Linux 3.5), which can be installed down
+// to that version through the prctl fallback.
+var syscallMinKernel = map[string]KernelVersion{
+	"finit_module":                 {3, 8},
+	"sched_setattr":                {3, 14},
+	"sched_getattr":                {3, 14},
+	"renameat2":                    {3, 15},
+	"seccomp":                      {3, 17},
+	"getrandom":                    {3, 17},
+	"memfd_create":                 {3, 17},
+	"kexec_file_load":              {3, 17},
+	"bpf":                          {3, 18},
+	"execveat":                     {3, 19},
+	"userfaultfd":                  {4, 3},
+	"membarrier":                   {4, 3},
+	"mlock2":                       {4, 4},
+	"copy_file_range":              {4, 5},
+	"preadv2":                      {4, 6},
+	"pwritev2":                     {4, 6},
+	"pkey_mprotect":                {4, 9},
+	"pkey_alloc":                   {4, 9},
+	"pkey_free":                    {4, 9},
+	"statx":                        {4, 11},
+	"io_pgetevents":                {4, 18},
+	"rseq":                         {4, 18},
+	"pidfd_send_signal":            {5, 1},
+	"io_uring_setup":               {5, 1},
+	"io_uring_enter":               {5, 1},
+	"io_uring_register":            {5, 1},
+	"clock_gettime64":              {5, 1},
+	"clock_settime64":              {5, 1},
+	"clock_adjtime64":              {5, 1},
+	"clock_getres_time64":          {5, 1},
+	"clock_nanosleep_time64":       {5, 1},
+	"timer_gettime64":              {5, 1},
+	"timer_settime64":              {5, 1},
+	"timerfd_gettime64":            {5, 1},
+	"timerfd_settime64":            {5, 1},
+	"utimensat_time64":             {5, 1},
+	"pselect6_time64":              {5, 1},
+	"ppoll_time64":                 {5, 1},
+	"io_pgetevents_time64":         {5, 1},
+	"recvmmsg_time64":              {5, 1},
+	"mq_timedsend_time64":          {5, 1},
+	"mq_timedreceive_time64":       {5, 1},
+	"semtimedop_time64":            {5, 1},
+	"rt_sigtimedwait_time64":       {5, 1},
+	"futex_time64":                 {5, 1},
+	"sched_rr_get_interval_time64": {5, 1},
+	"open_tree":                    {5, 2},
+	"move_mount":                   {5, 2},
+	"fsopen":                       {5, 2},
+	"fsconfig":                     {5, 2},
+	"fsmount":                      {5, 2},
+	"fspick":                       {5, 2},
+	"pidfd_open":                   {5, 3},
+	"clone3":                       {5, 3},
+	"openat2":                      {5, 6},
+	"pidfd_getfd":                  {5, 6},
+	"faccessat2":                   {5, 8},
+	"close_range":                  {5, 9},
+	"process_madvise":              {5, 10},
+	"epoll_pwait2":                 {5, 11},
+	"mount_setattr":                {5, 12},
+	"quotactl_fd":                  {5, 14},
+	"landlock_create_ruleset":      {5, 13},
+	"landlock_add_rule":            {5, 13},
+	"landlock_restrict_self":       {5, 13},
+	"memfd_secret":                 {5, 14},
+	"process_mrelease":             {5, 15},
+	"futex_waitv":                  {5, 16},
+	"set_mempolicy_home_node":      {5, 17},
+	"cachestat":                    {6, 5},
+	"fchmodat2":                    {6, 6},
+	"map_shadow_stack":             {6, 6},
+	"futex_wake":                   {6, 7},
+	"futex_wait":                   {6, 7},
+	"futex_requeue":                {6, 7},
+	"statmount":                    {6, 8},
+	"listmount":                    {6, 8},
+	"lsm_get_self_attr":            {6, 8},
+	"lsm_set_self_attr":            {6, 8},
+	"lsm_list_modules":             {6, 8},
+	"mseal":                        {6, 10},
+	"setxattrat":                   {6, 13},
+	"getxattrat":                   {6, 13},
+	"listxattrat":                  {6, 13},
+	"removexattrat":                {6, 13},
+	"open_tree_attr":               {6, 15},
+}
+
+// KernelRequirement is a feature used by a policy or filter together with
+// the first kernel version that supports it.
+type KernelRequirement struct {
+	Feature string        // Description of the feature (e.g. "action user_notif").
+	Version KernelVersion // First kernel version supporting the feature.
+}
+
+func (r KernelRequirement) String() string {
+	return r.Feature + " (Linux " + r.Version.String() + ")"
+}
+
+// KernelRequirements returns the features of the policy that require a
+// kernel newer than Linux 3.5. Syscalls are only taken into account when
+// they are permitted (allow, log, trace, or user_notif) because the
+// application expects them to exist. Denying an unknown syscall works on
+// any kernel.
+func (p *Policy) KernelRequirements() []KernelRequirement {
+	var reqs []KernelRequirement
+	for _, action := range p.actions() {
+		if version, found := actionMinKernel[action&actionMask]; found {
+			reqs = append(reqs, KernelRequirement{Feature: "action " + action.String(), Version: version})
+		}
+	}
+
+	seen := map[string]struct{}{}
+	for _, group := range p.groups() {
+		if !group.Action.permits() {
+			continue
+		}
+
+		names := append([]string{}, group.Names...)
+		for _, nc := range group.NamesWithCondtions {
+			names = append(names, nc.Name)
+		}
+		for _, name := range names {
+			if _, found := seen[name]; found {
+				continue
+			}
+			seen[name] = struct{}{}
+			if version, found := syscallMinKernel[name]; found {
+				reqs = append(reqs, KernelRequirement{Feature: "syscall " + name, Version: version})
+			}
+		}
+	}
+
+	sort.SliceStable(reqs, func(i, j int) bool { return reqs[j].Version.Less(reqs[i].Version) })
+	return reqs
+}
+
+// MinKernelVersion returns the oldest kernel version that supports all of
+// the actions and permitted syscalls used by the policy.
+func (p *Policy) MinKernelVersion() KernelVersion {
+	min := minKernelFilter
+	for _, req := range p.KernelRequirements() {
+		if min.Less(req.Version) {
+			min = req.Version
+		}
+	}
+	return min
+}
+
+// KernelRequirements returns the features of the filter (flags and policy)
+// that require a kernel newer than Linux 3.5.
+func (f Filter) KernelRequirements() []KernelRequirement {
+	var reqs []KernelRequirement
+	if f.Flag != 0 {
+		reqs = append(reqs, KernelRequirement{Feature: "flags " + f.Flag.String(), Version: f.Flag.MinKernelVersion()})
+	}
+	reqs = append(reqs, f.Policy.KernelRequirements()...)
+	sort.SliceStable(reqs, func(i, j int) bool { return reqs[j].Version.Less(reqs[i].Version) })
+	return reqs
+}
+
+// MinKernelVersion returns the oldest kernel version that can install the
+// filter and provides all syscalls it permits.
+func (f Filter) MinKernelVersion() KernelVersion {
+	min := f.Flag.MinKernelVersion()
+	if v := f.Policy.MinKernelVersion(); min.Less(v) {
+		min = v
+	}
+	return min
+}
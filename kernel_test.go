@@ -0,0 +1,92 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import "testing"
+
+func TestParseKernelVersion(t *testing.T) {
+	tests := map[string]KernelVersion{
+		"3.10.0-1160.el7.x86_64": {3, 10},
+		"5.15.0-91-generic":      {5, 15},
+		"6.8":                    {6, 8},
+		"6.9-rc1":                {6, 9},
+	}
+
+	for release, expected := range tests {
+		v, err := ParseKernelVersion(release)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != expected {
+			t.Errorf("expected %v for %q, got %v", expected, release, v)
+		}
+	}
+
+	if _, err := ParseKernelVersion("linux"); err == nil {
+		t.Error("expected error for invalid release")
+	}
+}
+
+func TestFilterFlagMinKernelVersion(t *testing.T) {
+	if v := FilterFlag(0).MinKernelVersion(); v != (KernelVersion{3, 5}) {
+		t.Errorf("unexpected version for no flags: %v", v)
+	}
+	if v := (FilterFlagTSync | FilterFlagSpecAllow).MinKernelVersion(); v != (KernelVersion{4, 17}) {
+		t.Errorf("unexpected version for spec_allow: %v", v)
+	}
+}
+
+func TestPolicyMinKernelVersion(t *testing.T) {
+	policy := Policy{
+		DefaultAction: ActionUserNotify,
+		Syscalls: []SyscallGroup{
+			{Names: []string{"read", "faccessat2"}, Action: ActionAllow},
+			{Names: []string{"mseal"}, Action: ActionErrno},
+		},
+	}
+
+	reqs := policy.KernelRequirements()
+	if len(reqs) != 2 || reqs[0].Feature != "syscall faccessat2" || reqs[1].Feature != "action user_notif" {
+		t.Errorf("unexpected requirements: %v", reqs)
+	}
+	if v := policy.MinKernelVersion(); v != (KernelVersion{5, 8}) {
+		t.Errorf("unexpected policy version: %v", v)
+	}
+
+	filter := Filter{Flag: FilterFlagTSyncESRCH, Policy: policy}
+	if v := filter.MinKernelVersion(); v != (KernelVersion{5, 8}) {
+		t.Errorf("unexpected filter version: %v", v)
+	}
+}
+
+func TestPolicyMinKernelVersionPrctl(t *testing.T) {
+	// Filters load down to Linux 3.5 with prctl, so syscalls that predate
+	// seccomp(2) still raise the requirement.
+	policy := Policy{
+		DefaultAction: ActionErrno,
+		Syscalls:      []SyscallGroup{{Names: []string{"read", "finit_module", "getrandom"}, Action: ActionAllow}},
+	}
+	if v := policy.MinKernelVersion(); v != (KernelVersion{3, 17}) {
+		t.Errorf("unexpected policy version: %v", v)
+	}
+
+	policy.Syscalls[0].Names = []string{"read", "finit_module"}
+	if v := policy.MinKernelVersion(); v != (KernelVersion{3, 8}) {
+		t.Errorf("unexpected policy version: %v", v)
+	}
+}
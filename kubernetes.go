@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KubeletSeccompRoot is the default directory that kubelet resolves
+// Localhost profiles against (<root-dir>/seccomp).
+const KubeletSeccompRoot = "/var/lib/kubelet/seccomp"
+
+// KubernetesProfileType is the type of a Kubernetes seccomp profile.
+type KubernetesProfileType string
+
+// Kubernetes seccomp profile types.
+const (
+	KubernetesRuntimeDefault KubernetesProfileType = "RuntimeDefault"
+	KubernetesUnconfined     KubernetesProfileType = "Unconfined"
+	KubernetesLocalhost      KubernetesProfileType = "Localhost"
+)
+
+// Values of the deprecated seccomp annotations.
+const (
+	kubeAnnotationRuntimeDefault  = "runtime/default"
+	kubeAnnotationDockerDefault   = "docker/default"
+	kubeAnnotationUnconfined      = "unconfined"
+	kubeAnnotationLocalhostPrefix = "localhost/"
+)
+
+// ErrNoRuntimeDefault is returned by KubernetesLoader when a profile
+// resolves to RuntimeDefault but the loader has no runtime default profile.
+var ErrNoRuntimeDefault = errors.New("no runtime default seccomp profile configured")
+
+// KubernetesProfile mirrors the seccompProfile field of a pod or container
+// securityContext.
+type KubernetesProfile struct {
+	Type             KubernetesProfileType `json:"type"`
+	LocalhostProfile *string               `json:"localhostProfile,omitempty"`
+}
+
+// ParseKubernetesAnnotation parses the value of the deprecated
+// seccomp.security.alpha.kubernetes.io/pod and
+// container.seccomp.security.alpha.kubernetes.io/<name> annotations.
+func ParseKubernetesAnnotation(value string) (*KubernetesProfile, error) {
+	switch {
+	case value == kubeAnnotationRuntimeDefault, value == kubeAnnotationDockerDefault:
+		return &KubernetesProfile{Type: KubernetesRuntimeDefault}, nil
+	case value == kubeAnnotationUnconfined:
+		return &KubernetesProfile{Type: KubernetesUnconfined}, nil
+	case strings.HasPrefix(value, kubeAnnotationLocalhostPrefix):
+		name := strings.TrimPrefix(value, kubeAnnotationLocalhostPrefix)
+		return &KubernetesProfile{Type: KubernetesLocalhost, LocalhostProfile: &name}, nil
+	default:
+		return nil, fmt.Errorf("invalid seccomp annotation %q", value)
+	}
+}
+
+// KubernetesLoader resolves Kubernetes seccomp profiles to filters the way
+// kubelet does.
+type KubernetesLoader struct {
+	// Root is the directory that Localhost profiles are relative to. It
+	// defaults to KubeletSeccompRoot.
+	Root string
+
+	// RuntimeDefault is the profile used for RuntimeDefault. In Kubernetes it
+	// is provided by the container runtime.
+	RuntimeDefault *OCIProfile
+
+	// SeccompDefault makes containers without a profile use RuntimeDefault
+	// instead of Unconfined, like the kubelet option of the same name.
+	SeccompDefault bool
+
+	// Options are used to convert the OCI profiles into filters.
+	Options OCIOptions
+}
+
+// Load returns the filter for a container given the seccompProfile of the
+// pod and of the container, either of which may be nil. The container
+// profile takes precedence over the pod profile. Load returns a nil filter
+// when the container is unconfined.
+func (l *KubernetesLoader) Load(pod, container *KubernetesProfile) (*Filter, error) {
+	profile := container
+	if profile == nil {
+		profile = pod
+	}
+	if profile == nil {
+		if !l.SeccompDefault {
+			return nil, nil
+		}
+		profile = &KubernetesProfile{Type: KubernetesRuntimeDefault}
+	}
+
+	switch profile.Type {
+	case KubernetesUnconfined:
+		return nil, nil
+	case KubernetesRuntimeDefault:
+		if l.RuntimeDefault == nil {
+			return nil, ErrNoRuntimeDefault
+		}
+		return l.RuntimeDefault.Filter(l.Options)
+	case KubernetesLocalhost:
+		if profile.LocalhostProfile == nil {
+			return nil, errors.New("localhostProfile must be set for Localhost seccomp profiles")
+		}
+		path, err := l.LocalhostPath(*profile.LocalhostProfile)
+		if err != nil {
+			return nil, err
+		}
+		return l.loadFile(path)
+	default:
+		return nil, fmt.Errorf("unsupported seccomp profile type %q", profile.Type)
+	}
+}
+
+// LocalhostPath returns the path of a Localhost profile. Like the Kubernetes
+// API, it rejects absolute paths and paths containing '..' so that profiles
+// cannot be loaded from outside of the root.
+func (l *KubernetesLoader) LocalhostPath(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("localhostProfile must not be empty")
+	}
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("localhostProfile %q must be a relative path", name)
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(name), "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("localhostProfile %q must not contain '..'", name)
+		}
+	}
+
+	root := l.Root
+	if root == "" {
+		root = KubeletSeccompRoot
+	}
+	return filepath.Join(root, name), nil
+}
+
+func (l *KubernetesLoader) loadFile(path string) (*Filter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load localhost seccomp profile: %w", err)
+	}
+	defer f.Close()
+
+	profile, err := ReadOCIProfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profile.Filter(l.Options)
+}
@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKubernetesLoader(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "profiles"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	profile := `{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"names": ["read"], "action": "SCMP_ACT_ALLOW"}]}`
+	if err := os.WriteFile(filepath.Join(root, "profiles", "read.json"), []byte(profile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runtimeDefault, err := ReadOCIProfile(strings.NewReader(`{"defaultAction": "SCMP_ACT_LOG"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localhost := func(name string) *KubernetesProfile {
+		return &KubernetesProfile{Type: KubernetesLocalhost, LocalhostProfile: &name}
+	}
+	unconfined := &KubernetesProfile{Type: KubernetesUnconfined}
+
+	loader := &KubernetesLoader{Root: root, RuntimeDefault: runtimeDefault, Options: OCIOptions{Arch: "x86_64"}}
+
+	filter, err := loader.Load(nil, localhost("profiles/read.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter == nil || filter.Policy.DefaultAction != ActionErrno {
+		t.Errorf("unexpected localhost filter %+v", filter)
+	}
+
+	// The container profile takes precedence over the pod profile.
+	if filter, err = loader.Load(localhost("profiles/read.json"), unconfined); err != nil || filter != nil {
+		t.Errorf("expected unconfined container, got %+v, %v", filter, err)
+	}
+	if filter, err = loader.Load(unconfined, nil); err != nil || filter != nil {
+		t.Errorf("expected unconfined pod, got %+v, %v", filter, err)
+	}
+
+	if filter, err = loader.Load(nil, nil); err != nil || filter != nil {
+		t.Errorf("expected unconfined by default, got %+v, %v", filter, err)
+	}
+	loader.SeccompDefault = true
+	if filter, err = loader.Load(nil, nil); err != nil || filter == nil || filter.Policy.DefaultAction != ActionLog {
+		t.Errorf("expected runtime default, got %+v, %v", filter, err)
+	}
+
+	loader.RuntimeDefault = nil
+	if _, err = loader.Load(&KubernetesProfile{Type: KubernetesRuntimeDefault}, nil); !errors.Is(err, ErrNoRuntimeDefault) {
+		t.Errorf("expected ErrNoRuntimeDefault, got %v", err)
+	}
+
+	for _, name := range []string{"", "/etc/profile.json", "../read.json", "profiles/../../read.json", "missing.json"} {
+		if _, err = loader.Load(nil, localhost(name)); err == nil {
+			t.Errorf("expected error for localhost profile %q", name)
+		}
+	}
+	if _, err = loader.Load(nil, &KubernetesProfile{Type: KubernetesLocalhost}); err == nil {
+		t.Error("expected error for localhost profile without path")
+	}
+}
+
+func TestKubernetesLocalhostPath(t *testing.T) {
+	path, err := (&KubernetesLoader{}).LocalhostPath("profiles/audit.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := KubeletSeccompRoot + "/profiles/audit.json"; path != want {
+		t.Errorf("expected %q, got %q", want, path)
+	}
+}
+
+func TestParseKubernetesAnnotation(t *testing.T) {
+	tests := map[string]KubernetesProfileType{
+		"runtime/default":        KubernetesRuntimeDefault,
+		"docker/default":         KubernetesRuntimeDefault,
+		"unconfined":             KubernetesUnconfined,
+		"localhost/profile.json": KubernetesLocalhost,
+	}
+	for value, typ := range tests {
+		profile, err := ParseKubernetesAnnotation(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profile.Type != typ {
+			t.Errorf("%q: expected %v, got %v", value, typ, profile.Type)
+		}
+	}
+
+	profile, _ := ParseKubernetesAnnotation("localhost/profile.json")
+	if *profile.LocalhostProfile != "profile.json" {
+		t.Errorf("unexpected localhost profile %q", *profile.LocalhostProfile)
+	}
+	if _, err := ParseKubernetesAnnotation("default"); err == nil {
+		t.Error("expected error for invalid annotation")
+	}
+}
@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/bpf"
+)
+
+// DefaultLayerCostThreshold is the default number of instructions, summed
+// over all layers, above which LayerStack reports a warning.
+const DefaultLayerCostThreshold = 1024
+
+// Layer is a named filter that is installed as part of a LayerStack.
+type Layer struct {
+	Name   string // Name used to attribute decisions to the layer.
+	Filter Filter // Filter to install.
+}
+
+// LayerInfo describes a layer that was installed by a LayerStack.
+type LayerInfo struct {
+	Name         string // Name of the layer.
+	Instructions int    // Number of BPF instructions in the layer.
+
+	program []bpf.Instruction
+}
+
+// LayerStack installs filters in a defined order (e.g. a broad baseline
+// followed by application specific filters) and records them so that
+// decisions can later be attributed to a layer.
+//
+// The kernel evaluates every installed filter for each syscall, so the cost
+// of the layers is cumulative. Warn is called when the total number of
+// instructions exceeds CostThreshold.
+type LayerStack struct {
+	CostThreshold int              // Defaults to DefaultLayerCostThreshold.
+	Warn          func(msg string) // Optional function that receives warnings.
+
+	mu     sync.Mutex
+	layers []LayerInfo
+}
+
+// Load installs the layers in the given order. It stops at the first layer
+// that fails to load. The layers that were installed before the failure
+// remain in effect and are recorded.
+func (s *LayerStack) Load(layers ...Layer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, layer := range layers {
+		if layer.Name == "" {
+			return errors.New("layer name must not be empty")
+		}
+
+		program, err := layer.Filter.Policy.Assemble()
+		if err != nil {
+			return fmt.Errorf("failed to assemble layer %v: %w", layer.Name, err)
+		}
+
+		if err = LoadFilter(layer.Filter); err != nil {
+			return fmt.Errorf("failed to load layer %v: %w", layer.Name, err)
+		}
+
+		if layer.Filter.isDryRun() {
+			continue
+		}
+		s.layers = append(s.layers, LayerInfo{
+			Name:         layer.Name,
+			Instructions: len(program),
+			program:      program,
+		})
+		s.checkCost()
+	}
+	return nil
+}
+
+// checkCost warns if the cumulative size of the layers is above the
+// threshold.
+func (s *LayerStack) checkCost() {
+	if s.Warn == nil {
+		return
+	}
+
+	threshold := s.CostThreshold
+	if threshold <= 0 {
+		threshold = DefaultLayerCostThreshold
+	}
+
+	total := 0
+	for _, layer := range s.layers {
+		total += layer.Instructions
+	}
+	if total > threshold {
+		s.Warn(fmt.Sprintf("seccomp filter stack of %d layers has %d instructions "+
+			"(threshold %d) that are evaluated for every syscall", len(s.layers), total, threshold))
+	}
+}
+
+// Layers returns the installed layers in installation order.
+func (s *LayerStack) Layers() []LayerInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	layers := make([]LayerInfo, len(s.layers))
+	copy(layers, s.layers)
+	return layers
+}
+
+// Attribute evaluates the syscall against all installed layers like the
+// kernel does and returns the layer that determined the resulting action.
+// The kernel runs the most recently installed filter first and applies the
+// action with the highest precedence.
+func (s *LayerStack) Attribute(data SeccompData) (*LayerInfo, Action, error) {
+	layers := s.Layers()
+	if len(layers) == 0 {
+		return nil, ActionAllow, errors.New("no layers installed")
+	}
+
+	var (
+		winner *LayerInfo
+		result Action
+	)
+	for i := len(layers) - 1; i >= 0; i-- {
+		emulator, err := NewEmulator(layers[i].program)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		action, err := emulator.Run(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to evaluate layer %v: %w", layers[i].Name, err)
+		}
+
+		if winner == nil || action.precedes(result) {
+			winner, result = &layers[i], action
+		}
+	}
+	return winner, result, nil
+}
+
+// precedes returns true if the kernel gives the action a higher precedence
+// than other when multiple filters are installed.
+// https://github.com/torvalds/linux/blob/v4.16/kernel/seccomp.c#L210-L213
+func (a Action) precedes(other Action) bool {
+	return int32(a&actionMask) < int32(other&actionMask)
+}
@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestLayerStackAttribute(t *testing.T) {
+	layers := []Layer{
+		{
+			Name: "baseline",
+			Filter: Filter{Policy: Policy{
+				arch:          arch.X86_64,
+				DefaultAction: ActionAllow,
+				Syscalls: []SyscallGroup{
+					{Names: []string{"execve", "ptrace"}, Action: ActionErrno},
+				},
+			}},
+		},
+		{
+			Name: "app",
+			Filter: Filter{Policy: Policy{
+				arch:          arch.X86_64,
+				DefaultAction: ActionAllow,
+				Syscalls: []SyscallGroup{
+					{Names: []string{"execve"}, Action: ActionKillProcess},
+					{Names: []string{"ptrace"}, Action: ActionErrno},
+				},
+			}},
+		},
+	}
+
+	var warnings []string
+	stack := &LayerStack{CostThreshold: 1, Warn: func(msg string) { warnings = append(warnings, msg) }}
+	for _, layer := range layers {
+		program, err := layer.Filter.Policy.Assemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack.layers = append(stack.layers, LayerInfo{Name: layer.Name, Instructions: len(program), program: program})
+		stack.checkCost()
+	}
+	if len(warnings) != 2 {
+		t.Errorf("expected 2 cost warnings, got %v", warnings)
+	}
+
+	tests := []struct {
+		nr     int32
+		layer  string
+		action Action
+	}{
+		{59 /* execve */, "app", ActionKillProcess},
+		{101 /* ptrace */, "app", ActionErrno | Action(errnoEPERM)}, // Most recent layer wins ties.
+		{0 /* read */, "app", ActionAllow},
+	}
+	for _, tc := range tests {
+		layer, action, err := stack.Attribute(SeccompData{NR: tc.nr, Arch: uint32(arch.X86_64.ID)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if layer.Name != tc.layer || action != tc.action {
+			t.Errorf("nr=%d: expected %v from %v, got %v from %v", tc.nr, tc.action, tc.layer, action, layer.Name)
+		}
+	}
+
+	// With the order reversed the baseline is evaluated first and wins ties.
+	stack.layers[0], stack.layers[1] = stack.layers[1], stack.layers[0]
+	layer, _, err := stack.Attribute(SeccompData{NR: 101, Arch: uint32(arch.X86_64.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer.Name != "baseline" {
+		t.Errorf("expected baseline layer to determine ptrace, got %v", layer.Name)
+	}
+}
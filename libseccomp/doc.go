@@ -0,0 +1,26 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package libseccomp converts policies of go-seccomp-bpf into filters of
+// libseccomp-golang and records libseccomp-golang rules into policies. It
+// helps migrating between the two libraries: a policy can be loaded with
+// libseccomp's loader, and the filter that libseccomp compiled can be
+// checked against the policy with Verify.
+//
+// The package is a separate module because libseccomp-golang requires cgo
+// and the libseccomp C library, while go-seccomp-bpf does not.
+package libseccomp
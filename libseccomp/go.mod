@@ -0,0 +1,15 @@
+module github.com/elastic/go-seccomp-bpf/libseccomp
+
+go 1.23.0
+
+require (
+	github.com/elastic/go-seccomp-bpf v1.7.0
+	github.com/seccomp/libseccomp-golang v0.10.0
+	golang.org/x/net v0.41.0
+)
+
+require (
+	github.com/elastic/go-ucfg v0.8.8 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
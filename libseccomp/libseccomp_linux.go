@@ -0,0 +1,412 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package libseccomp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+
+	scmp "github.com/seccomp/libseccomp-golang"
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// sizeOfSockFilter is the size of a struct sock_filter instruction as
+// exported by seccomp_export_bpf.
+const sizeOfSockFilter = 8
+
+// NewFilter converts the filter into a libseccomp filter for the native
+// architecture. The no new privs bit and the TSync, Log and SpecAllow flags
+// are set as filter attributes. The caller must Release the filter.
+func NewFilter(f *seccomp.Filter) (*scmp.ScmpFilter, error) {
+	if f.Flag&(seccomp.FilterFlagNewListener|seccomp.FilterFlagTSyncESRCH) != 0 {
+		return nil, fmt.Errorf("filter flag %v is not supported by libseccomp-golang", f.Flag)
+	}
+
+	filter, err := NewPolicyFilter(&f.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := filter.SetNoNewPrivsBit(f.NoNewPrivs); err != nil {
+		filter.Release()
+		return nil, fmt.Errorf("failed to set no new privs attribute: %w", err)
+	}
+
+	flags := []struct {
+		flag seccomp.FilterFlag
+		set  func(bool) error
+	}{
+		{seccomp.FilterFlagTSync, filter.SetTsync},
+		{seccomp.FilterFlagLog, filter.SetLogBit},
+		{seccomp.FilterFlagSpecAllow, filter.SetSSB},
+	}
+	for _, fl := range flags {
+		if f.Flag&fl.flag == 0 {
+			continue
+		}
+		if err := fl.set(true); err != nil {
+			filter.Release()
+			return nil, fmt.Errorf("failed to set filter flag %v: %w", fl.flag, err)
+		}
+	}
+	return filter, nil
+}
+
+// NewPolicyFilter converts the policy into a libseccomp filter for the native
+// architecture. Groups are added in order and, like in the assembled filter,
+// the first group that matches a syscall decides its action. Inputs from
+// other architectures get the default action. The caller must Release the
+// filter.
+func NewPolicyFilter(p *seccomp.Policy) (*scmp.ScmpFilter, error) {
+	if _, err := p.Assemble(); err != nil {
+		return nil, err
+	}
+
+	defaultAction, err := toScmpAction(p.DefaultAction)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := scmp.NewFilter(defaultAction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create libseccomp filter: %w", err)
+	}
+	if err := filter.SetBadArchAction(defaultAction); err != nil {
+		filter.Release()
+		return nil, fmt.Errorf("failed to set bad arch action: %w", err)
+	}
+
+	b := &builder{
+		filter:        filter,
+		defaultAction: p.DefaultAction,
+		decided:       map[string]bool{},
+		shadowed:      map[string]bool{},
+	}
+	for _, group := range policyGroups(p) {
+		if err := b.addGroup(group); err != nil {
+			filter.Release()
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// policyGroups returns the groups of the policy including the group for the
+// Go runtime syscalls that IncludeGoRuntime adds after them.
+func policyGroups(p *seccomp.Policy) []seccomp.SyscallGroup {
+	if !p.IncludeGoRuntime {
+		return p.Syscalls
+	}
+
+	named := map[string]bool{}
+	for _, group := range p.Syscalls {
+		for _, name := range group.Names {
+			named[name] = true
+		}
+		for _, nc := range group.NamesWithCondtions {
+			named[nc.Name] = true
+		}
+	}
+
+	runtime := seccomp.SyscallGroup{Action: seccomp.ActionAllow}
+	for _, name := range seccomp.GoRuntimeSyscalls {
+		if !named[name] {
+			runtime.Names = append(runtime.Names, name)
+		}
+	}
+	return append(append([]seccomp.SyscallGroup(nil), p.Syscalls...), runtime)
+}
+
+// builder adds the rules of a policy to a libseccomp filter.
+type builder struct {
+	filter        *scmp.ScmpFilter
+	defaultAction seccomp.Action
+
+	// decided holds the syscalls that have an unconditional rule. Later
+	// groups can not match them.
+	decided map[string]bool
+
+	// shadowed holds the syscalls that have a conditional rule with the
+	// default action. libseccomp rejects such rules, so they are only
+	// expressible while no later rule gives the syscall another action.
+	shadowed map[string]bool
+}
+
+func (b *builder) addGroup(group seccomp.SyscallGroup) error {
+	action, err := toScmpAction(group.Action)
+	if err != nil {
+		return err
+	}
+	isDefault := group.Action == b.defaultAction
+
+	for _, name := range group.Names {
+		if b.decided[name] {
+			continue
+		}
+		b.decided[name] = true
+		if isDefault {
+			continue
+		}
+		if b.shadowed[name] {
+			return fmt.Errorf("syscall %v: libseccomp can not express a conditional rule with the default action followed by another action", name)
+		}
+
+		call, err := scmp.GetSyscallFromName(name)
+		if err != nil {
+			return fmt.Errorf("unknown syscall %v: %w", name, err)
+		}
+		if err := b.filter.AddRule(call, action); err != nil {
+			return fmt.Errorf("failed to add rule for %v: %w", name, err)
+		}
+	}
+
+	for _, nc := range group.NamesWithCondtions {
+		if b.decided[nc.Name] {
+			continue
+		}
+		if isDefault {
+			b.shadowed[nc.Name] = true
+			continue
+		}
+		if b.shadowed[nc.Name] {
+			return fmt.Errorf("syscall %v: libseccomp can not express a conditional rule with the default action followed by another action", nc.Name)
+		}
+
+		call, err := scmp.GetSyscallFromName(nc.Name)
+		if err != nil {
+			return fmt.Errorf("unknown syscall %v: %w", nc.Name, err)
+		}
+		alternatives, err := toScmpConditions(nc.Conditions)
+		if err != nil {
+			return fmt.Errorf("syscall %v: %w", nc.Name, err)
+		}
+		for _, conds := range alternatives {
+			if err := b.filter.AddRuleConditional(call, action, conds); err != nil {
+				return fmt.Errorf("failed to add conditional rule for %v: %w", nc.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// toScmpAction converts an action and its data to a libseccomp action.
+func toScmpAction(a seccomp.Action) (scmp.ScmpAction, error) {
+	data := int16(a.Data())
+	switch a.Base() {
+	case seccomp.ActionKillThread:
+		return scmp.ActKillThread, nil
+	case seccomp.ActionKillProcess:
+		return scmp.ActKillProcess, nil
+	case seccomp.ActionTrap:
+		return scmp.ActTrap, nil
+	case seccomp.ActionErrno:
+		if data == 0 {
+			// A bare ActionErrno returns EPERM.
+			data = 1
+		}
+		return scmp.ActErrno.SetReturnCode(data), nil
+	case seccomp.ActionTrace:
+		return scmp.ActTrace.SetReturnCode(data), nil
+	case seccomp.ActionLog:
+		return scmp.ActLog, nil
+	case seccomp.ActionAllow:
+		return scmp.ActAllow, nil
+	case seccomp.ActionUserNotify:
+		return scmp.ActNotify, nil
+	default:
+		return scmp.ActInvalid, fmt.Errorf("action %v is not supported by libseccomp-golang", a)
+	}
+}
+
+// fromScmpAction converts a libseccomp action to an action and its data.
+func fromScmpAction(a scmp.ScmpAction) (seccomp.Action, error) {
+	data := seccomp.Action(uint16(a.GetReturnCode()))
+	switch a.SetReturnCode(0) {
+	case scmp.ActKillThread:
+		return seccomp.ActionKillThread, nil
+	case scmp.ActKillProcess:
+		return seccomp.ActionKillProcess, nil
+	case scmp.ActTrap:
+		return seccomp.ActionTrap, nil
+	case scmp.ActErrno:
+		return seccomp.ActionErrno | data, nil
+	case scmp.ActTrace:
+		return seccomp.ActionTrace | data, nil
+	case scmp.ActLog:
+		return seccomp.ActionLog, nil
+	case scmp.ActAllow:
+		return seccomp.ActionAllow, nil
+	case scmp.ActNotify:
+		return seccomp.ActionUserNotify, nil
+	default:
+		return 0, fmt.Errorf("libseccomp action %v is not supported", a)
+	}
+}
+
+// toScmpConditions converts the conditions of a syscall into libseccomp
+// conditions. libseccomp can not test a BitsSet condition with more than one
+// bit in a single comparison, so the result is a list of alternative rules
+// that are matched if any of them matches. It is empty if the conditions can
+// never match.
+func toScmpConditions(conditions seccomp.ArgumentConditions) ([][]scmp.ScmpCondition, error) {
+	alternatives := [][]scmp.ScmpCondition{nil}
+	seen := map[uint32]bool{}
+	for _, c := range conditions {
+		if seen[c.Argument] {
+			return nil, fmt.Errorf("libseccomp does not support more than one comparison of argument %d in a rule", c.Argument)
+		}
+		seen[c.Argument] = true
+
+		var options []scmp.ScmpCondition
+		switch c.Operation {
+		case seccomp.BitsSet:
+			for v := c.Value; v != 0; v &= v - 1 {
+				bit := uint64(1) << bits.TrailingZeros64(v)
+				cond, err := scmp.MakeCondition(uint(c.Argument), scmp.CompareMaskedEqual, bit, bit)
+				if err != nil {
+					return nil, err
+				}
+				options = append(options, cond)
+			}
+		case seccomp.BitsNotSet:
+			cond, err := scmp.MakeCondition(uint(c.Argument), scmp.CompareMaskedEqual, c.Value, 0)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, cond)
+		default:
+			op, found := scmpCompareOps[c.Operation]
+			if !found {
+				return nil, fmt.Errorf("unknown operation %v", c.Operation)
+			}
+			cond, err := scmp.MakeCondition(uint(c.Argument), op, c.Value)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, cond)
+		}
+
+		var product [][]scmp.ScmpCondition
+		for _, alt := range alternatives {
+			for _, option := range options {
+				product = append(product, append(append([]scmp.ScmpCondition(nil), alt...), option))
+			}
+		}
+		alternatives = product
+	}
+	return alternatives, nil
+}
+
+var scmpCompareOps = map[seccomp.Operation]scmp.ScmpCompareOp{
+	seccomp.Equal:          scmp.CompareEqual,
+	seccomp.NotEqual:       scmp.CompareNotEqual,
+	seccomp.GreaterThan:    scmp.CompareGreater,
+	seccomp.LessThan:       scmp.CompareLess,
+	seccomp.GreaterOrEqual: scmp.CompareGreaterEqual,
+	seccomp.LessOrEqual:    scmp.CompareLessOrEqual,
+}
+
+// Verify exports the BPF program that libseccomp compiled for the filter and
+// checks with seccomp.Policy.VerifyProgram that it makes the same decisions
+// as the policy. It returns a *seccomp.VerifyError if they differ.
+func Verify(p *seccomp.Policy, filter *scmp.ScmpFilter) error {
+	insts, err := ExportProgram(filter)
+	if err != nil {
+		return err
+	}
+
+	err = p.VerifyProgram(insts)
+	var verr *seccomp.VerifyError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	// libseccomp gives x32 syscalls on x86_64 the bad arch action instead of
+	// the default action, which is the same for filters from NewFilter.
+	// Filters built by other code may differ, so only x32 inputs that got the
+	// bad arch action are ignored.
+	badArch, err := filter.GetBadArchAction()
+	if err != nil {
+		return err
+	}
+	badArchAction, err := fromScmpAction(badArch)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []seccomp.VerifyMismatch
+	for _, m := range verr.Mismatches {
+		if m.Data.Arch == uint32(arch.X86_64.ID) && m.Data.NR&int32(arch.X32.SeccompMask) != 0 && m.Got == badArchAction {
+			continue
+		}
+		mismatches = append(mismatches, m)
+	}
+	if len(mismatches) > 0 || len(verr.Incomplete) > 0 {
+		return &seccomp.VerifyError{Mismatches: mismatches, Incomplete: verr.Incomplete}
+	}
+	return nil
+}
+
+// ExportProgram returns the BPF program that libseccomp compiled for the
+// filter.
+func ExportProgram(filter *scmp.ScmpFilter) ([]bpf.Instruction, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		defer w.Close()
+		done <- filter.ExportBPF(w)
+	}()
+
+	data, err := io.ReadAll(r)
+	if exportErr := <-done; exportErr != nil {
+		return nil, fmt.Errorf("failed to export BPF program: %w", exportErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BPF program: %w", err)
+	}
+	if len(data)%sizeOfSockFilter != 0 {
+		return nil, fmt.Errorf("BPF program size %d is not a multiple of %d", len(data), sizeOfSockFilter)
+	}
+
+	raw := make([]bpf.RawInstruction, 0, len(data)/sizeOfSockFilter)
+	for i := 0; i < len(data); i += sizeOfSockFilter {
+		raw = append(raw, bpf.RawInstruction{
+			Op: binary.NativeEndian.Uint16(data[i:]),
+			Jt: data[i+2],
+			Jf: data[i+3],
+			K:  binary.NativeEndian.Uint32(data[i+4:]),
+		})
+	}
+	insts, _ := bpf.Disassemble(raw)
+	return insts, nil
+}
@@ -0,0 +1,157 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package libseccomp
+
+import (
+	"reflect"
+	"testing"
+
+	scmp "github.com/seccomp/libseccomp-golang"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func TestNewPolicyFilter(t *testing.T) {
+	policy := &seccomp.Policy{
+		DefaultAction: seccomp.ActionErrno,
+		Syscalls: []seccomp.SyscallGroup{
+			{
+				Action: seccomp.ActionErrno | 13,
+				NamesWithCondtions: []seccomp.NameWithConditions{
+					{
+						Name: "mmap",
+						Conditions: seccomp.ArgumentConditions{
+							{Argument: 2, Operation: seccomp.BitsSet, Value: 0x6},
+						},
+					},
+				},
+			},
+			{
+				Action: seccomp.ActionAllow,
+				Names:  []string{"read", "write", "mmap"},
+				NamesWithCondtions: []seccomp.NameWithConditions{
+					{
+						Name: "socket",
+						Conditions: seccomp.ArgumentConditions{
+							{Argument: 0, Operation: seccomp.Equal, Value: 1},
+							{Argument: 1, Operation: seccomp.BitsNotSet, Value: 0x80000},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	filter, err := NewPolicyFilter(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer filter.Release()
+
+	if err := Verify(policy, filter); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewPolicyFilterShadowedRule(t *testing.T) {
+	policy := &seccomp.Policy{
+		DefaultAction: seccomp.ActionErrno,
+		Syscalls: []seccomp.SyscallGroup{
+			{
+				Action: seccomp.ActionErrno,
+				NamesWithCondtions: []seccomp.NameWithConditions{
+					{
+						Name: "socket",
+						Conditions: seccomp.ArgumentConditions{
+							{Argument: 0, Operation: seccomp.Equal, Value: 1},
+						},
+					},
+				},
+			},
+			{
+				Action: seccomp.ActionAllow,
+				Names:  []string{"socket"},
+			},
+		},
+	}
+
+	if _, err := NewPolicyFilter(policy); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r, err := NewRecorder(scmp.ActErrno.SetReturnCode(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, _ := scmp.GetSyscallFromName("read")
+	socket, _ := scmp.GetSyscallFromName("socket")
+	if err := r.AddRule(read, scmp.ActAllow); err != nil {
+		t.Fatal(err)
+	}
+	cond, err := scmp.MakeCondition(1, scmp.CompareMaskedEqual, 0x80000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRuleConditional(socket, scmp.ActAllow, []scmp.ScmpCondition{cond}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRule(read, scmp.ActErrno.SetReturnCode(1)); err == nil {
+		t.Fatal("expected an error for a rule with the default action")
+	}
+
+	expected := &seccomp.Policy{
+		DefaultAction: seccomp.ActionErrno | 1,
+		Syscalls: []seccomp.SyscallGroup{
+			{
+				Action: seccomp.ActionAllow,
+				NamesWithCondtions: []seccomp.NameWithConditions{
+					{
+						Name: "socket",
+						Conditions: seccomp.ArgumentConditions{
+							{Argument: 1, Operation: seccomp.BitsNotSet, Value: 0x80000},
+						},
+					},
+				},
+			},
+			{
+				Action: seccomp.ActionAllow,
+				Names:  []string{"read"},
+			},
+		},
+	}
+	policy := r.Policy()
+	if !reflect.DeepEqual(expected, policy) {
+		t.Fatalf("expected %+v, got %+v", expected, policy)
+	}
+
+	filter, err := NewPolicyFilter(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer filter.Release()
+
+	if err := Verify(policy, filter); err != nil {
+		t.Fatal(err)
+	}
+}
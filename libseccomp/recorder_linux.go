@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package libseccomp
+
+import (
+	"fmt"
+	"math/bits"
+
+	scmp "github.com/seccomp/libseccomp-golang"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Recorder records rules that are added with the methods of a libseccomp
+// filter into a policy. It lets code written against libseccomp-golang
+// produce a seccomp.Policy by replacing the *scmp.ScmpFilter with a Recorder.
+//
+// libseccomp checks conditional rules of a syscall before its unconditional
+// rule, so the recorded policy puts the groups of conditional rules first.
+type Recorder struct {
+	defaultAction seccomp.Action
+	conditional   []seccomp.SyscallGroup
+	unconditional []seccomp.SyscallGroup
+	actions       map[string]seccomp.Action // Action of unconditional rules.
+}
+
+// NewRecorder returns a Recorder for a filter with the given default action.
+func NewRecorder(defaultAction scmp.ScmpAction) (*Recorder, error) {
+	action, err := fromScmpAction(defaultAction)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		defaultAction: action,
+		actions:       map[string]seccomp.Action{},
+	}, nil
+}
+
+// AddRule records an unconditional rule for the syscall.
+func (r *Recorder) AddRule(call scmp.ScmpSyscall, action scmp.ScmpAction) error {
+	name, a, err := r.rule(call, action)
+	if err != nil {
+		return err
+	}
+	if existing, found := r.actions[name]; found {
+		if existing == a {
+			return nil
+		}
+		return fmt.Errorf("syscall %v already has a rule with action %v", name, existing)
+	}
+	r.actions[name] = a
+
+	group := findGroup(&r.unconditional, a)
+	group.Names = append(group.Names, name)
+	return nil
+}
+
+// AddRuleConditional records a rule for the syscall that matches when all
+// conditions are true.
+func (r *Recorder) AddRuleConditional(call scmp.ScmpSyscall, action scmp.ScmpAction, conds []scmp.ScmpCondition) error {
+	name, a, err := r.rule(call, action)
+	if err != nil {
+		return err
+	}
+
+	conditions := make(seccomp.ArgumentConditions, 0, len(conds))
+	for _, c := range conds {
+		condition, err := fromScmpCondition(c)
+		if err != nil {
+			return fmt.Errorf("syscall %v: %w", name, err)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	group := findGroup(&r.conditional, a)
+	group.NamesWithCondtions = append(group.NamesWithCondtions, seccomp.NameWithConditions{
+		Name:       name,
+		Conditions: conditions,
+	})
+	return nil
+}
+
+// Policy returns the recorded policy.
+func (r *Recorder) Policy() *seccomp.Policy {
+	syscalls := make([]seccomp.SyscallGroup, 0, len(r.conditional)+len(r.unconditional))
+	syscalls = append(syscalls, r.conditional...)
+	syscalls = append(syscalls, r.unconditional...)
+	return &seccomp.Policy{
+		DefaultAction: r.defaultAction,
+		Syscalls:      syscalls,
+	}
+}
+
+func (r *Recorder) rule(call scmp.ScmpSyscall, action scmp.ScmpAction) (string, seccomp.Action, error) {
+	name, err := call.GetName()
+	if err != nil {
+		return "", 0, fmt.Errorf("unknown syscall %d: %w", call, err)
+	}
+	a, err := fromScmpAction(action)
+	if err != nil {
+		return "", 0, err
+	}
+	if a == r.defaultAction {
+		// Same as libseccomp, which rejects these rules.
+		return "", 0, fmt.Errorf("syscall %v: action %v is the default action", name, a)
+	}
+	return name, a, nil
+}
+
+// findGroup returns the group with the action, adding one if there is none.
+func findGroup(groups *[]seccomp.SyscallGroup, action seccomp.Action) *seccomp.SyscallGroup {
+	for i := range *groups {
+		if (*groups)[i].Action == action {
+			return &(*groups)[i]
+		}
+	}
+	*groups = append(*groups, seccomp.SyscallGroup{Action: action})
+	return &(*groups)[len(*groups)-1]
+}
+
+// fromScmpCondition converts a libseccomp condition. Masked comparisons are
+// converted if they test that all bits of a mask are clear or that a single
+// bit is set.
+func fromScmpCondition(c scmp.ScmpCondition) (seccomp.Condition, error) {
+	condition := seccomp.Condition{Argument: uint32(c.Argument), Value: c.Operand1}
+	switch c.Op {
+	case scmp.CompareMaskedEqual:
+		mask, value := c.Operand1, c.Operand2
+		switch {
+		case value == 0:
+			condition.Operation = seccomp.BitsNotSet
+		case value == mask && bits.OnesCount64(mask) == 1:
+			condition.Operation = seccomp.BitsSet
+		default:
+			return condition, fmt.Errorf("masked comparison of argument %d with mask %#x and value %#x is not supported", c.Argument, mask, value)
+		}
+		return condition, nil
+	default:
+		for op, scmpOp := range scmpCompareOps {
+			if scmpOp == c.Op {
+				condition.Operation = op
+				return condition, nil
+			}
+		}
+		return condition, fmt.Errorf("comparison %v of argument %d is not supported", c.Op, c.Argument)
+	}
+}
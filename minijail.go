@@ -0,0 +1,433 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// MinijailOptions configure ReadMinijailPolicy.
+type MinijailOptions struct {
+	// Arch is the architecture of the policy. It defaults to the native
+	// architecture.
+	Arch string
+
+	// Constants resolves the symbolic names used in argument expressions in
+	// addition to a few architecture independent ones (PROT_*, AF_*).
+	Constants map[string]uint64
+
+	// IncludeDir is the directory that relative @include paths are
+	// resolved against. It defaults to the working directory.
+	IncludeDir string
+}
+
+var minijailConstants = map[string]uint64{
+	"PROT_NONE": 0x0, "PROT_READ": 0x1, "PROT_WRITE": 0x2, "PROT_EXEC": 0x4,
+	"AF_UNSPEC": 0, "AF_UNIX": 1, "AF_LOCAL": 1, "AF_INET": 2, "AF_INET6": 10,
+	"AF_NETLINK": 16, "AF_PACKET": 17, "AF_VSOCK": 40,
+}
+
+var minijailActions = map[string]Action{
+	"1":            ActionAllow,
+	"allow":        ActionAllow,
+	"kill":         ActionKillProcess,
+	"kill-process": ActionKillProcess,
+	"kill-thread":  ActionKillThread,
+	"trap":         ActionTrap,
+	"trace":        ActionTrace,
+	"log":          ActionLog,
+	"user-notify":  ActionUserNotify,
+}
+
+var minijailOperations = map[string]Operation{
+	"==": Equal,
+	"!=": NotEqual,
+	"<":  LessThan,
+	"<=": LessOrEqual,
+	">":  GreaterThan,
+	">=": GreaterOrEqual,
+	"&":  BitsSet,
+}
+
+var minijailAtom = regexp.MustCompile(`^arg([0-5])\s*(==|!=|<=|>=|<|>|&|in)\s*(.+)$`)
+
+// ReadMinijailPolicy reads a policy in the format of minijail's .policy
+// files. Each line maps a syscall to an action ("1", "return <errno>",
+// "kill", "trap", ...) or to an expression of argument comparisons joined
+// by && and ||, optionally followed by "; return <errno>" for when it does
+// not match. Syscalls that are not listed kill the process unless the file
+// starts with @denylist, in which case they are allowed. @include is
+// supported and @frequency is ignored.
+func ReadMinijailPolicy(r io.Reader, opts MinijailOptions) (*Policy, error) {
+	info, err := arch.GetInfo(opts.Arch)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &minijailParser{opts: opts, arch: info, rules: map[string]*minijailRule{}}
+	if err := p.parse(r, "", 0); err != nil {
+		return nil, err
+	}
+	return p.policy(), nil
+}
+
+type minijailRule struct {
+	action     Action
+	conditions []ArgumentConditions // Allow if any of them matches.
+}
+
+type minijailParser struct {
+	opts     MinijailOptions
+	arch     *arch.Info
+	denylist bool
+	names    []string
+	rules    map[string]*minijailRule
+}
+
+func (p *minijailParser) parse(r io.Reader, file string, depth int) error {
+	if depth > 8 {
+		return errors.New("@include nested too deeply")
+	}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := p.parseLine(line, depth); err != nil {
+			if file != "" {
+				return fmt.Errorf("%s:%d: %w", file, n, err)
+			}
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return s.Err()
+}
+
+func (p *minijailParser) parseLine(line string, depth int) error {
+	switch {
+	case line == "@denylist":
+		p.denylist = true
+		return nil
+	case strings.HasPrefix(line, "@frequency"):
+		return nil
+	case strings.HasPrefix(line, "@include"):
+		path := strings.TrimSpace(strings.TrimPrefix(line, "@include"))
+		if !filepath.IsAbs(path) && p.opts.IncludeDir != "" {
+			path = filepath.Join(p.opts.IncludeDir, path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return p.parse(f, path, depth+1)
+	}
+
+	name, value, found := strings.Cut(line, ":")
+	if !found {
+		return fmt.Errorf("expected '<syscall>: <action>' but got %q", line)
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if _, found := p.arch.SyscallNumber(name); !found {
+		return fmt.Errorf("unknown syscall %q", name)
+	}
+	if _, found := p.rules[name]; found {
+		return fmt.Errorf("duplicate syscall %q", name)
+	}
+
+	rule, err := p.parseRule(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	p.rules[name] = rule
+	p.names = append(p.names, name)
+	return nil
+}
+
+func (p *minijailParser) parseRule(value string) (*minijailRule, error) {
+	if action, err := parseMinijailAction(value); err == nil {
+		return &minijailRule{action: action}, nil
+	}
+
+	expr, ret, hasReturn := strings.Cut(value, ";")
+	rule := &minijailRule{action: ActionKillProcess}
+	if hasReturn {
+		action, err := parseMinijailAction(strings.TrimSpace(ret))
+		if err != nil {
+			return nil, err
+		}
+		rule.action = action
+	} else if p.denylist {
+		return nil, errors.New("argument expressions in a @denylist must be followed by a return action")
+	}
+
+	for _, clause := range strings.Split(expr, "||") {
+		var conditions ArgumentConditions
+		for _, atom := range strings.Split(clause, "&&") {
+			c, err := p.parseCondition(strings.TrimSpace(atom))
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, c)
+		}
+		rule.conditions = append(rule.conditions, conditions)
+	}
+	return rule, nil
+}
+
+func parseMinijailAction(s string) (Action, error) {
+	if action, found := minijailActions[s]; found {
+		return action, nil
+	}
+	if errno, found := strings.CutPrefix(s, "return "); found {
+		return parseErrno(strings.TrimSpace(errno))
+	}
+	return 0, fmt.Errorf("invalid action %q", s)
+}
+
+func (p *minijailParser) parseCondition(atom string) (Condition, error) {
+	m := minijailAtom.FindStringSubmatch(atom)
+	if m == nil {
+		return Condition{}, fmt.Errorf("invalid argument expression %q", atom)
+	}
+
+	arg, _ := strconv.Atoi(m[1])
+	value, err := p.parseValue(m[3])
+	if err != nil {
+		return Condition{}, err
+	}
+
+	c := Condition{Argument: uint32(arg), Value: value}
+	if m[2] == "in" {
+		// The argument may only use the bits of the mask.
+		c.Operation, c.Value = BitsNotSet, ^value
+	} else {
+		c.Operation = minijailOperations[m[2]]
+	}
+	return c, nil
+}
+
+// parseValue parses numbers and constants, which can be combined with '|'
+// and negated with '~'.
+func (p *minijailParser) parseValue(s string) (uint64, error) {
+	var value uint64
+	for _, term := range strings.Split(s, "|") {
+		term = strings.TrimSpace(term)
+		negate := strings.HasPrefix(term, "~")
+		term = strings.TrimSpace(strings.TrimPrefix(term, "~"))
+
+		v, err := strconv.ParseUint(term, 0, 64)
+		if err != nil {
+			var found bool
+			if v, found = p.opts.Constants[term]; !found {
+				if v, found = minijailConstants[term]; !found {
+					return 0, fmt.Errorf("unknown constant %q", term)
+				}
+			}
+		}
+		if negate {
+			v = ^v
+		}
+		value |= v
+	}
+	return value, nil
+}
+
+// policy converts the rules. Argument expressions become allow rules that
+// are evaluated before the rules with the action for when they do not match.
+func (p *minijailParser) policy() *Policy {
+	policy := &Policy{arch: p.arch, DefaultAction: ActionKillProcess}
+	if p.denylist {
+		policy.DefaultAction = ActionAllow
+	}
+
+	allow := SyscallGroup{Action: ActionAllow}
+	byAction := map[Action][]string{}
+	var actions []Action
+	for _, name := range p.names {
+		rule := p.rules[name]
+		for _, c := range rule.conditions {
+			allow.NamesWithCondtions = append(allow.NamesWithCondtions, NameWithConditions{Name: name, Conditions: c})
+		}
+		if rule.action == ActionAllow && rule.conditions == nil {
+			allow.Names = append(allow.Names, name)
+			continue
+		}
+		if rule.action == policy.DefaultAction {
+			continue
+		}
+		if byAction[rule.action] == nil {
+			actions = append(actions, rule.action)
+		}
+		byAction[rule.action] = append(byAction[rule.action], name)
+	}
+
+	if len(allow.Names) > 0 || len(allow.NamesWithCondtions) > 0 {
+		policy.Syscalls = append(policy.Syscalls, allow)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	for _, action := range actions {
+		policy.Syscalls = append(policy.Syscalls, SyscallGroup{Action: action, Names: byAction[action]})
+	}
+	if len(policy.Syscalls) == 0 {
+		policy.Syscalls = []SyscallGroup{{Action: policy.DefaultAction}}
+	}
+	return policy
+}
+
+// WriteMinijailPolicy writes the policy in minijail's .policy format. A
+// policy that allows by default is written as a @denylist. Otherwise the
+// file cannot express the default action, for which minijail kills the
+// process. Argument conditions are only supported in groups that allow the
+// syscall; the action for when they do not match is taken from the first
+// later group that names the syscall without conditions.
+func (p *Policy) WriteMinijailPolicy(w io.Writer) error {
+	if p.arch == nil {
+		info, err := arch.GetInfo("")
+		if err != nil {
+			return err
+		}
+		p.arch = info
+	}
+
+	// The first group that names a syscall without conditions decides its
+	// action, groups before it can allow it conditionally.
+	var names []string
+	rules := map[string]*minijailRule{}
+	done := map[string]bool{}
+	rule := func(name string) *minijailRule {
+		r, found := rules[name]
+		if !found {
+			r = &minijailRule{action: p.DefaultAction.returnValue()}
+			rules[name] = r
+			names = append(names, name)
+		}
+		return r
+	}
+	for _, group := range p.groups() {
+		for _, s := range group.NamesWithCondtions {
+			if done[s.Name] {
+				continue
+			}
+			if group.Action != ActionAllow {
+				return fmt.Errorf("minijail policies only support argument conditions that allow a syscall, but %v uses %v", s.Name, group.Action&actionMask)
+			}
+			r := rule(s.Name)
+			r.conditions = append(r.conditions, s.Conditions)
+		}
+		for _, name := range group.Names {
+			if !done[name] {
+				rule(name).action = group.Action.returnValue()
+				done[name] = true
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	if p.DefaultAction == ActionAllow {
+		fmt.Fprintln(bw, "@denylist")
+	}
+	for _, name := range names {
+		r := rules[name]
+		if r.action == ActionAllow && p.DefaultAction == ActionAllow {
+			continue
+		}
+
+		action, err := minijailAction(r.action)
+		if err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		if r.conditions == nil || r.action == ActionAllow {
+			fmt.Fprintf(bw, "%s: %s\n", name, action)
+			continue
+		}
+
+		var clauses []string
+		for _, conditions := range r.conditions {
+			var atoms []string
+			for _, c := range conditions {
+				atoms = append(atoms, minijailCondition(c))
+			}
+			clauses = append(clauses, strings.Join(atoms, " && "))
+		}
+		expr := strings.Join(clauses, " || ")
+		switch {
+		case r.action&actionMask == ActionErrno:
+			expr += "; " + action
+		case r.action != ActionKillProcess || p.DefaultAction == ActionAllow:
+			return fmt.Errorf("%v: minijail policies cannot use %v when the argument conditions do not match", name, r.action&actionMask)
+		}
+		fmt.Fprintf(bw, "%s: %s\n", name, expr)
+	}
+	return bw.Flush()
+}
+
+func minijailAction(a Action) (string, error) {
+	if a&actionMask == ActionErrno {
+		return "return " + errnoName(a), nil
+	}
+	switch a {
+	case ActionAllow:
+		return "1", nil
+	case ActionKillProcess:
+		return "kill-process", nil
+	case ActionKillThread:
+		return "kill-thread", nil
+	case ActionTrap:
+		return "trap", nil
+	case ActionTrace:
+		return "trace", nil
+	case ActionLog:
+		return "log", nil
+	case ActionUserNotify:
+		return "user-notify", nil
+	}
+	return "", fmt.Errorf("unsupported action %v", a)
+}
+
+func minijailCondition(c Condition) string {
+	switch c.Operation {
+	case BitsSet:
+		return fmt.Sprintf("arg%d & %#x", c.Argument, c.Value)
+	case BitsNotSet:
+		return fmt.Sprintf("arg%d in %#x", c.Argument, ^c.Value)
+	}
+	for op, operation := range minijailOperations {
+		if operation == c.Operation {
+			return fmt.Sprintf("arg%d %s %d", c.Argument, op, c.Value)
+		}
+	}
+	return ""
+}
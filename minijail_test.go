@@ -0,0 +1,190 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+const testMinijailPolicy = `# Test policy
+read: 1
+write: allow
+open: return ENOENT
+ptrace: kill-thread
+mmap: arg2 in ~PROT_EXEC
+ioctl: arg1 == 0x5401 || arg1 == FIONREAD; return ENOTTY
+socket: arg0 == AF_UNIX && arg1 != 3 # no raw sockets
+fcntl: arg1 & 0x800; return EPERM
+@frequency ./frequency.txt
+`
+
+func TestReadMinijailPolicy(t *testing.T) {
+	policy, err := ReadMinijailPolicy(strings.NewReader(testMinijailPolicy), MinijailOptions{
+		Arch:      "x86_64",
+		Constants: map[string]uint64{"FIONREAD": 0x541b},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{data("read"), ActionAllow},
+		{data("write"), ActionAllow},
+		{data("open"), ActionErrno | Action(2)},
+		{data("ptrace"), ActionKillThread},
+		{data("mmap", 0, 4096, 0x3), ActionAllow},
+		{data("mmap", 0, 4096, 0x5), ActionKillProcess},
+		{data("ioctl", 0, 0x5401), ActionAllow},
+		{data("ioctl", 0, 0x541b), ActionAllow},
+		{data("ioctl", 0, 0x5402), ActionErrno | Action(25)},
+		{data("socket", 1, 1), ActionAllow},
+		{data("socket", 1, 3), ActionKillProcess},
+		{data("socket", 2, 1), ActionKillProcess},
+		{data("fcntl", 0, 0x801), ActionAllow},
+		{data("fcntl", 0, 0x1), ActionErrno | Action(errnoEPERM)},
+		{data("close"), ActionKillProcess},
+	})
+}
+
+func TestReadMinijailPolicyDenylist(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "common.policy"), []byte("mount: return EPERM\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := ReadMinijailPolicy(strings.NewReader("@denylist\n@include common.policy\nreboot: kill\n"), MinijailOptions{
+		Arch:       "x86_64",
+		IncludeDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{systemdData("read"), ActionAllow},
+		{systemdData("mount"), ActionErrno | Action(errnoEPERM)},
+		{systemdData("reboot"), ActionKillProcess},
+	})
+}
+
+func TestReadMinijailPolicyErrors(t *testing.T) {
+	for _, policy := range []string{
+		"read",
+		"no_such_syscall: 1",
+		"read: 1\nread: 1",
+		"read: maybe",
+		"read: arg6 == 1",
+		"read: arg0 == UNKNOWN",
+		"read: return EWHAT",
+		"@denylist\nread: arg0 == 1",
+		"@include /no/such/file",
+	} {
+		if _, err := ReadMinijailPolicy(strings.NewReader(policy), MinijailOptions{Arch: "x86_64"}); err == nil {
+			t.Errorf("expected error for %q", policy)
+		}
+	}
+}
+
+func TestWriteMinijailPolicy(t *testing.T) {
+	policy := Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionKillProcess,
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionAllow,
+				Names:  []string{"read", "write"},
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "ioctl", Conditions: []Condition{{Argument: 1, Operation: Equal, Value: 0x5401}}},
+					{Name: "ioctl", Conditions: []Condition{{Argument: 1, Operation: Equal, Value: 0x541b}}},
+					{Name: "mmap", Conditions: []Condition{{Argument: 2, Operation: BitsNotSet, Value: 0x4}}},
+					{Name: "fcntl", Conditions: []Condition{{Argument: 1, Operation: BitsSet, Value: 0x800}}},
+				},
+			},
+			{Action: ActionErrno | Action(25), Names: []string{"ioctl"}},
+			{Action: ActionErrno, Names: []string{"open"}},
+			{Action: ActionTrap, Names: []string{"ptrace"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := policy.WriteMinijailPolicy(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `ioctl: arg1 == 21505 || arg1 == 21531; return ENOTTY
+mmap: arg2 in 0xfffffffffffffffb
+fcntl: arg1 & 0x800
+read: 1
+write: 1
+open: return EPERM
+ptrace: trap
+`
+	if buf.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	// The output must read back into an equivalent policy.
+	parsed, err := ReadMinijailPolicy(&buf, MinijailOptions{Arch: "x86_64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulateSyscalls(t, parsed, []SeccompTest{
+		{systemdData("read"), ActionAllow},
+		{SeccompData{NR: 16, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{0, 0x5401}}, ActionAllow},
+		{SeccompData{NR: 16, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{0, 0x5402}}, ActionErrno | Action(25)},
+		{SeccompData{NR: 9, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{0, 0, 0x4}}, ActionKillProcess},
+		{systemdData("open"), ActionErrno | Action(errnoEPERM)},
+		{systemdData("ptrace"), ActionTrap},
+	})
+}
+
+func TestWriteMinijailPolicyDenylist(t *testing.T) {
+	policy := Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{Action: ActionAllow, Names: []string{"umount2"}},
+			{Action: ActionErrno, Names: []string{"mount", "umount2"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := policy.WriteMinijailPolicy(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "@denylist\nmount: return EPERM\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	policy.Syscalls = []SyscallGroup{{
+		Action:             ActionErrno,
+		NamesWithCondtions: []NameWithConditions{{Name: "mount", Conditions: []Condition{{Operation: Equal}}}},
+	}}
+	if err := policy.WriteMinijailPolicy(&bytes.Buffer{}); err == nil {
+		t.Error("expected error for conditions that deny")
+	}
+}
@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import "sort"
+
+// Minimize removes the syscalls that the policy permits but that were not
+// observed in the trace, and returns their sorted names. It is the
+// counterpart of MergeTrace: trace a program under a broad policy, then drop
+// everything it did not use. Only rules whose action permits the syscall
+// (e.g. allow or log) are removed, so the minimized policy never allows more
+// than the original. Syscalls in keep are retained even if they were not
+// observed. Groups left without syscalls and the profile counts of removed
+// syscalls are removed.
+func (p *Policy) Minimize(trace *SyscallTrace, keep ...string) []string {
+	retained := map[string]bool{}
+	for _, name := range trace.Names() {
+		retained[name] = true
+	}
+	for _, name := range keep {
+		retained[name] = true
+	}
+
+	removed := map[string]bool{}
+	groups := p.Syscalls[:0]
+	for _, group := range p.Syscalls {
+		if group.Action.permits() {
+			names := make([]string, 0, len(group.Names))
+			for _, name := range group.Names {
+				if retained[name] {
+					names = append(names, name)
+				} else {
+					removed[name] = true
+				}
+			}
+			group.Names = names
+
+			withConditions := make([]NameWithConditions, 0, len(group.NamesWithCondtions))
+			for _, s := range group.NamesWithCondtions {
+				if retained[s.Name] {
+					withConditions = append(withConditions, s)
+				} else {
+					removed[s.Name] = true
+				}
+			}
+			group.NamesWithCondtions = withConditions
+
+			if len(group.Names) == 0 && len(group.NamesWithCondtions) == 0 {
+				continue
+			}
+		}
+		groups = append(groups, group)
+	}
+	p.Syscalls = groups
+
+	names := make([]string, 0, len(removed))
+	for name := range removed {
+		names = append(names, name)
+		delete(p.Profile, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,72 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestPolicyMinimize(t *testing.T) {
+	trace := NewSyscallTrace()
+	for _, name := range []string{"read", "write", "openat"} {
+		trace.AddCount(name, 1)
+	}
+
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionErrno,
+		Profile:       SyscallProfile{"read": 3, "mmap": 1},
+		Syscalls: []SyscallGroup{
+			{Action: ActionKillProcess, Names: []string{"ptrace", "mount"}},
+			{
+				Action: ActionAllow,
+				Names:  []string{"read", "write", "close", "mmap"},
+				NamesWithCondtions: []NameWithConditions{
+					{Name: "openat", Conditions: []Condition{{Argument: 2, Operation: Equal, Value: 0}}},
+					{Name: "socket", Conditions: []Condition{{Argument: 0, Operation: Equal, Value: 1}}},
+				},
+			},
+			{Action: ActionLog, Names: []string{"connect", "bind"}},
+		},
+	}
+
+	removed := policy.Minimize(trace, "close")
+	if want := []string{"bind", "connect", "mmap", "socket"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("expected %v to be removed, got %v", want, removed)
+	}
+
+	want := []SyscallGroup{
+		{Action: ActionKillProcess, Names: []string{"ptrace", "mount"}},
+		{
+			Action: ActionAllow,
+			Names:  []string{"read", "write", "close"},
+			NamesWithCondtions: []NameWithConditions{
+				{Name: "openat", Conditions: []Condition{{Argument: 2, Operation: Equal, Value: 0}}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(policy.Syscalls, want) {
+		t.Errorf("expected groups %+v, got %+v", want, policy.Syscalls)
+	}
+	if want := (SyscallProfile{"read": 3}); !reflect.DeepEqual(policy.Profile, want) {
+		t.Errorf("expected profile %v, got %v", want, policy.Profile)
+	}
+}
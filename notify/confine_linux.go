@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"sort"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// SupervisorSyscalls are the syscalls used by the Go runtime and by a
+// Supervisor running the handlers of this package. Names that do not exist
+// on the running architecture are ignored.
+var SupervisorSyscalls = append(append([]string(nil), seccomp.GoRuntimeSyscalls...),
+	// Listener, Target, and Memory.
+	"ioctl", "poll", "ppoll", "pidfd_open", "pidfd_getfd",
+	"pidfd_send_signal", "openat", "fstat", "newfstatat", "fstatat64",
+	"readlinkat", "pread64", "pwrite64", "process_vm_readv", "lseek",
+
+	// Handlers.
+	"openat2", "connect", "sendmsg", "sendto", "recvmsg", "getsockopt",
+	"shutdown",
+)
+
+// SupervisorPolicy returns a policy that only allows SupervisorSyscalls and
+// extra. Other syscalls fail with EPERM.
+func SupervisorPolicy(extra ...string) (seccomp.Policy, error) {
+	info, err := arch.GetInfo("")
+	if err != nil {
+		return seccomp.Policy{}, err
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range append(append([]string(nil), SupervisorSyscalls...), extra...) {
+		if _, found := info.SyscallNumber(name); !found || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return seccomp.Policy{
+		DefaultAction: seccomp.ActionErrno,
+		Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionAllow, Names: names}},
+	}, nil
+}
+
+// ConfineSupervisor installs SupervisorPolicy with extra syscalls on all
+// threads of the calling process. It should be called after the listeners
+// were obtained and everything the handlers need was opened, so that a
+// compromised supervisor, which can read and write the memory of the
+// targets, cannot start processes or create sockets. It does not protect
+// the file system: openat is allowed with any flags and the descriptors can
+// be written, because targets are inspected and their memory is written
+// through /proc. Sockets that were already created can still be connected
+// and used.
+//
+// Handlers that need other syscalls, for example a Logger whose handler
+// creates sockets, must name them in extra. The filter cannot be removed and
+// is inherited by children started afterwards, which should therefore be
+// started first. Loading it fails if a thread of the process has installed a
+// filter of its own.
+func ConfineSupervisor(extra ...string) error {
+	policy, err := SupervisorPolicy(extra...)
+	if err != nil {
+		return err
+	}
+	return seccomp.LoadFilter(seccomp.Filter{
+		NoNewPrivs: true,
+		Flag:       seccomp.FilterFlagTSync,
+		Policy:     policy,
+	})
+}
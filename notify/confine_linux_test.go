@@ -0,0 +1,104 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// confineEnv makes TestConfineSupervisor run the confined supervisor in a
+// child process, because the filter cannot be removed.
+const confineEnv = "NOTIFY_TEST_CONFINE"
+
+func TestSupervisorPolicy(t *testing.T) {
+	policy, err := SupervisorPolicy("getppid", "no_such_syscall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = policy.Assemble(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := policy.Syscalls[0].Names
+	found := false
+	for _, name := range names {
+		if name == "no_such_syscall" {
+			t.Error("unknown syscall was not ignored")
+		}
+		found = found || name == "getppid"
+	}
+	if !found {
+		t.Error("extra syscall is missing")
+	}
+}
+
+func TestConfineSupervisor(t *testing.T) {
+	if os.Getenv(confineEnv) == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestConfineSupervisor$", "-test.v")
+		cmd.Env = append(os.Environ(), confineEnv+"=1")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("confined supervisor failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	// The target thread installs its own filter below the supervisor's,
+	// because synchronizing fails for threads with diverging filters. The
+	// target's getppid must be allowed by both filters.
+	if err := ConfineSupervisor("prctl", "seccomp", "uname", "getdents64", "getppid"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0); err != unix.EPERM {
+		t.Errorf("expected socket to fail with EPERM, got %v", err)
+	}
+
+	var ppid uintptr
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid"}, func() {
+		defer close(done)
+		ppid, _, _ = unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+	})
+
+	s := NewSupervisor(l)
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		if _, err := req.Memory(); err != nil {
+			return nil, err
+		}
+		return ReturnValue(req.Notification, 42), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	<-done
+
+	if ppid != 42 {
+		t.Errorf("expected getppid to return 42, got %d", ppid)
+	}
+}
@@ -0,0 +1,445 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxSendtoSize is the maximum payload that ConnectBroker sends on behalf of
+// the target in proxy mode.
+const maxSendtoSize = 1 << 20
+
+// maxControlSize is the maximum ancillary data that ConnectBroker sends on
+// behalf of the target in proxy mode.
+const maxControlSize = 1 << 16
+
+// NetRule allows connections to a destination. An inet rule matches if both
+// Prefix and Ports match, a unix rule matches by Path.
+type NetRule struct {
+	Name   string       // Optional identifier of the rule used in logs.
+	Prefix netip.Prefix // IP range. The zero value matches any address.
+	Ports  []uint16     // Destination ports. Empty matches any port.
+	Path   string       // Unix socket path, "@name" for abstract sockets.
+}
+
+func (r *NetRule) matches(sa unix.Sockaddr) bool {
+	var addr netip.Addr
+	var port int
+	switch sa := sa.(type) {
+	case *unix.SockaddrInet4:
+		addr, port = netip.AddrFrom4(sa.Addr), sa.Port
+	case *unix.SockaddrInet6:
+		addr, port = netip.AddrFrom16(sa.Addr).Unmap(), sa.Port
+	case *unix.SockaddrUnix:
+		return r.Path != "" && r.Path == sa.Name
+	default:
+		return false
+	}
+
+	if r.Path != "" {
+		return false
+	}
+	if r.Prefix.IsValid() && !r.Prefix.Contains(addr) {
+		return false
+	}
+	if len(r.Ports) == 0 {
+		return true
+	}
+	for _, p := range r.Ports {
+		if int(p) == port {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnectBroker is a Handler that allows or denies connect, and sendto,
+// sendmsg, and sendmmsg with a destination address, by destination address.
+// Sends without a destination go to the address the socket was connected to,
+// which was checked by connect. A connect with an AF_UNSPEC address, which
+// dissolves the association of a socket, is always allowed.
+//
+// By default allowed syscalls continue in the target, which is subject to
+// the TOCTOU race described at ContinueUnsafe: another thread of the target
+// can change the address after it was checked. With Proxy the broker
+// performs the syscall itself on a copy of the target's socket obtained with
+// pidfd_getfd (Linux 5.6), so the checked address is the one that is used.
+// Unix socket paths are then resolved in the supervisor's filesystem view.
+// Proxied sends of more than 1 MiB are truncated, and sendmsg and sendmmsg
+// with a destination fail with EOPNOTSUPP if they pass file descriptors or
+// credentials. A proxied connect that does not complete within
+// ConnectTimeout is aborted and fails with ETIMEDOUT.
+type ConnectBroker struct {
+	Rules []NetRule
+
+	// Proxy performs allowed syscalls in the supervisor.
+	Proxy bool
+
+	// ConnectTimeout limits how long a proxied connect may take. Defaults
+	// to DefaultConnectTimeout.
+	ConnectTimeout time.Duration
+
+	// Errno is returned for denied destinations. Defaults to EACCES.
+	Errno syscall.Errno
+}
+
+// DefaultConnectTimeout is the default timeout of connects proxied by
+// ConnectBroker.
+const DefaultConnectTimeout = 30 * time.Second
+
+// ConnectSyscalls are the syscalls handled by ConnectBroker.
+var ConnectSyscalls = []string{"connect", "sendto", "sendmsg", "sendmmsg"}
+
+// Register registers the broker for all ConnectSyscalls.
+func (b *ConnectBroker) Register(s *Supervisor) {
+	for _, name := range ConnectSyscalls {
+		s.Handle(name, b)
+	}
+}
+
+// mmsghdr is struct mmsghdr as passed to sendmmsg.
+type mmsghdr struct {
+	Hdr RemoteMsghdr
+	Len uint32
+}
+
+// Handle implements Handler.
+func (b *ConnectBroker) Handle(req *Request) (*Response, error) {
+	args := req.Data.Args
+	switch req.Syscall {
+	case "connect", "sendto", "sendmsg", "sendmmsg":
+	default:
+		return nil, fmt.Errorf("connect broker cannot handle syscall %q", req.Syscall)
+	}
+	if req.Syscall == "sendto" && args[4] == 0 {
+		// The destination of a connected socket was checked by connect.
+		return ContinueUnsafe(req.Notification), nil
+	}
+
+	mem, err := req.Memory()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the messages and their destinations.
+	var msgs []mmsghdr
+	var dests []unix.Sockaddr
+	switch req.Syscall {
+	case "connect", "sendto":
+		addr, size := args[1], args[2]
+		if req.Syscall == "sendto" {
+			addr, size = args[4], args[5]
+		} else if isUnspec(mem, addr, int(size)) {
+			// Disconnecting has no destination to check.
+			req.LogAttrs(slog.String("addr", "unspec"))
+			dests = append(dests, nil)
+			break
+		}
+		sa, err := mem.ReadSockaddr(addr, int(size))
+		if err != nil {
+			return ReturnErrno(req.Notification, int(unix.EINVAL)), nil
+		}
+		dests = append(dests, sa)
+	case "sendmsg", "sendmmsg":
+		if msgs, err = readMsgs(mem, req); err != nil {
+			return ReturnErrno(req.Notification, int(unix.EFAULT)), nil
+		}
+		for _, msg := range msgs {
+			if msg.Hdr.Name == 0 {
+				dests = append(dests, nil)
+				continue
+			}
+			sa, err := mem.ReadSockaddr(uint64(msg.Hdr.Name), int(msg.Hdr.Namelen))
+			if err != nil {
+				return ReturnErrno(req.Notification, int(unix.EINVAL)), nil
+			}
+			dests = append(dests, sa)
+		}
+	}
+
+	checked := false
+	for _, sa := range dests {
+		if sa == nil {
+			continue
+		}
+		checked = true
+		req.LogAttrs(slog.String("addr", formatSockaddr(sa)))
+		rule := b.match(sa)
+		if rule != nil && rule.Name != "" {
+			req.LogAttrs(slog.String("rule", rule.Name))
+		}
+		if rule == nil {
+			errno := b.Errno
+			if errno == 0 {
+				errno = unix.EACCES
+			}
+			return ReturnErrno(req.Notification, int(errno)), nil
+		}
+	}
+	// A disconnect is proxied, so that the target cannot change it into a
+	// connect after the check.
+	if !b.Proxy || (!checked && req.Syscall != "connect") {
+		return ContinueUnsafe(req.Notification), nil
+	}
+	return b.proxy(req, mem, msgs, dests)
+}
+
+// isUnspec reports whether the socket address of length size at address addr
+// of the target has the AF_UNSPEC family.
+func isUnspec(mem *Memory, addr uint64, size int) bool {
+	var family [2]byte
+	if size < len(family) {
+		return false
+	}
+	if _, err := mem.ReadAt(family[:], int64(addr)); err != nil {
+		return false
+	}
+	return binary.NativeEndian.Uint16(family[:]) == unix.AF_UNSPEC
+}
+
+// readMsgs reads the messages passed to sendmsg or sendmmsg. Only the
+// layout of the native architecture is supported.
+func readMsgs(mem *Memory, req *Request) ([]mmsghdr, error) {
+	args := req.Data.Args
+	if req.Syscall == "sendmsg" {
+		hdr, err := mem.ReadMsghdr(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return []mmsghdr{{Hdr: *hdr}}, nil
+	}
+
+	// The kernel sends at most UIO_MAXIOV messages.
+	vlen := min(int(uint32(args[2])), maxIovecs)
+	if vlen == 0 {
+		return nil, nil
+	}
+	msgs := make([]mmsghdr, vlen)
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&msgs[0])), vlen*int(unsafe.Sizeof(msgs[0])))
+	if _, err := mem.ReadAt(buf, int64(args[1])); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// match returns the first rule that allows the destination.
+func (b *ConnectBroker) match(sa unix.Sockaddr) *NetRule {
+	for i := range b.Rules {
+		if b.Rules[i].matches(sa) {
+			return &b.Rules[i]
+		}
+	}
+	return nil
+}
+
+// formatSockaddr formats a socket address for logs.
+func formatSockaddr(sa unix.Sockaddr) string {
+	switch sa := sa.(type) {
+	case *unix.SockaddrInet4:
+		return netip.AddrPortFrom(netip.AddrFrom4(sa.Addr), uint16(sa.Port)).String()
+	case *unix.SockaddrInet6:
+		return netip.AddrPortFrom(netip.AddrFrom16(sa.Addr), uint16(sa.Port)).String()
+	case *unix.SockaddrUnix:
+		return "unix:" + sa.Name
+	default:
+		return fmt.Sprintf("%T", sa)
+	}
+}
+
+// proxy performs the syscall on a copy of the target's socket. The copy
+// shares the open file description, so connecting it connects the target's
+// socket.
+func (b *ConnectBroker) proxy(req *Request, mem *Memory, msgs []mmsghdr, dests []unix.Sockaddr) (*Response, error) {
+	target, err := req.Target()
+	if err != nil {
+		return nil, err
+	}
+	sock, err := target.GetFd(int(int32(req.Data.Args[0])))
+	if err != nil {
+		if errors.Is(err, unix.EBADF) {
+			return ReturnErrno(req.Notification, int(unix.EBADF)), nil
+		}
+		return nil, err
+	}
+	if req.Syscall == "connect" {
+		// The socket is closed by connect when the attempt ends.
+		err = b.connect(req.Context(), sock, dests[0])
+		return proxyResponse(req, 0, err)
+	}
+	defer sock.Close()
+	conn, err := sock.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	// MSG_DONTWAIT avoids blocking the supervisor on a full socket buffer
+	// of a blocking socket.
+	var n int
+	switch req.Syscall {
+	case "sendto":
+		size := min(req.Data.Args[2], maxSendtoSize)
+		buf := make([]byte, size)
+		if _, err = mem.ReadAt(buf, int64(req.Data.Args[1])); err != nil {
+			return nil, err
+		}
+		conn.Control(func(fd uintptr) {
+			n, err = unix.SendmsgN(int(fd), buf, nil, dests[0], int(req.Data.Args[3])|unix.MSG_DONTWAIT)
+		})
+	case "sendmsg":
+		n, err = sendMsg(conn, mem, &msgs[0].Hdr, dests[0], int(req.Data.Args[2]))
+	case "sendmmsg":
+		flags := int(req.Data.Args[3])
+		for i := range msgs {
+			var sent int
+			if sent, err = sendMsg(conn, mem, &msgs[i].Hdr, dests[i], flags); err != nil {
+				break
+			}
+			// Report the number of bytes sent in msg_len like the kernel.
+			addr := int64(req.Data.Args[1]) + int64(i)*int64(unsafe.Sizeof(msgs[i])) + int64(unsafe.Offsetof(msgs[i].Len))
+			if _, err = mem.WriteAt(binary.NativeEndian.AppendUint32(nil, uint32(sent)), addr); err != nil {
+				break
+			}
+			n++
+		}
+		if n > 0 {
+			// Like the kernel, report the error of a later message
+			// only if no message was sent.
+			err = nil
+		}
+	}
+	return proxyResponse(req, n, err)
+}
+
+// proxyResponse returns the result of a proxied syscall to the target.
+func proxyResponse(req *Request, n int, err error) (*Response, error) {
+	if errno, ok := err.(syscall.Errno); ok {
+		return ReturnErrno(req.Notification, int(errno)), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ReturnValue(req.Notification, int64(n)), nil
+}
+
+// connect connects the socket to sa in another goroutine and closes it
+// afterwards. A nil sa dissolves the association of the socket. If the connection is not established within ConnectTimeout or
+// the supervisor shuts down, the attempt is aborted by shutting the socket
+// down, which also affects the target's socket, and ETIMEDOUT is returned.
+func (b *ConnectBroker) connect(ctx context.Context, sock *os.File, sa unix.Sockaddr) error {
+	conn, err := sock.SyscallConn()
+	if err != nil {
+		sock.Close()
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer sock.Close()
+		var err error
+		conn.Control(func(fd uintptr) {
+			if sa == nil {
+				err = disconnect(int(fd))
+				return
+			}
+			err = unix.Connect(int(fd), sa)
+		})
+		done <- err
+	}()
+
+	timeout := b.ConnectTimeout
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err = <-done:
+		return err
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	conn.Control(func(fd uintptr) {
+		unix.Shutdown(int(fd), unix.SHUT_RDWR)
+	})
+	return unix.ETIMEDOUT
+}
+
+// disconnect dissolves the association of the socket by connecting it to an
+// AF_UNSPEC address, which unix.Connect cannot pass.
+func disconnect(fd int) error {
+	sa := unix.RawSockaddr{Family: unix.AF_UNSPEC}
+	_, _, errno := unix.Syscall(unix.SYS_CONNECT, uintptr(fd), uintptr(unsafe.Pointer(&sa)), unsafe.Sizeof(sa))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// sendMsg sends the message of the target to sa on the socket. File
+// descriptors and credentials cannot be passed on behalf of the target.
+func sendMsg(conn syscall.RawConn, mem *Memory, hdr *RemoteMsghdr, sa unix.Sockaddr, flags int) (int, error) {
+	iovs, err := mem.ReadIovecs(uint64(hdr.Iov), int(hdr.Iovlen))
+	if err != nil {
+		return 0, unix.EINVAL
+	}
+	buf, err := mem.ReadIovecData(iovs, maxSendtoSize)
+	if err != nil {
+		return 0, unix.EFAULT
+	}
+
+	var oob []byte
+	if hdr.Controllen > 0 {
+		if hdr.Controllen > maxControlSize {
+			return 0, unix.ENOBUFS
+		}
+		oob = make([]byte, hdr.Controllen)
+		if _, err = mem.ReadAt(oob, int64(hdr.Control)); err != nil {
+			return 0, unix.EFAULT
+		}
+		cmsgs, err := unix.ParseSocketControlMessage(oob)
+		if err != nil {
+			return 0, unix.EINVAL
+		}
+		for _, cmsg := range cmsgs {
+			if cmsg.Header.Level == unix.SOL_SOCKET {
+				return 0, unix.EOPNOTSUPP
+			}
+		}
+	}
+
+	var n int
+	conn.Control(func(fd uintptr) {
+		n, err = unix.SendmsgN(int(fd), buf, oob, sa, flags|unix.MSG_DONTWAIT)
+	})
+	return n, err
+}
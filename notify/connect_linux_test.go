@@ -0,0 +1,342 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestConnectBroker(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	testCases := []struct {
+		name  string
+		proxy bool
+		addr  [4]byte
+		port  int
+		errno unix.Errno
+	}{
+		{"allowed", false, [4]byte{127, 0, 0, 1}, port, 0},
+		{"allowed proxy", true, [4]byte{127, 0, 0, 1}, port, 0},
+		{"denied port", false, [4]byte{127, 0, 0, 1}, port + 1, unix.EACCES},
+		{"denied address", true, [4]byte{192, 0, 2, 1}, port, unix.EACCES},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer unix.Close(fd)
+
+			var sa unix.RawSockaddrInet4
+			sa.Family = unix.AF_INET
+			sa.Addr = tc.addr
+			*(*[2]byte)(unsafe.Pointer(&sa.Port)) = [2]byte{byte(tc.port >> 8), byte(tc.port)}
+
+			results := make(chan unix.Errno, 1)
+			l := startTarget(t, []string{"connect"}, func() {
+				_, _, errno := unix.Syscall(unix.SYS_CONNECT, uintptr(fd), uintptr(unsafe.Pointer(&sa)), unsafe.Sizeof(sa))
+				results <- errno
+			})
+
+			s := NewSupervisor(l)
+			broker := &ConnectBroker{
+				Proxy: tc.proxy,
+				Rules: []NetRule{{Prefix: netip.MustParsePrefix("127.0.0.0/8"), Ports: []uint16{uint16(port)}}},
+			}
+			broker.Register(s)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+
+			if errno := <-results; errno != tc.errno {
+				t.Fatalf("expected errno %v, got %v", tc.errno, errno)
+			}
+			if tc.errno != 0 {
+				return
+			}
+			if _, err = unix.Getpeername(fd); err != nil {
+				t.Errorf("expected socket to be connected: %v", err)
+			}
+		})
+	}
+}
+
+func TestConnectBrokerDisconnect(t *testing.T) {
+	for _, proxy := range []bool{false, true} {
+		t.Run("proxy="+strconv.FormatBool(proxy), func(t *testing.T) {
+			fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer unix.Close(fd)
+
+			var sa unix.RawSockaddrInet4
+			sa.Family = unix.AF_INET
+			sa.Addr = [4]byte{127, 0, 0, 1}
+			*(*[2]byte)(unsafe.Pointer(&sa.Port)) = [2]byte{0, 53}
+			unspec := unix.RawSockaddr{Family: unix.AF_UNSPEC}
+
+			results := make(chan unix.Errno, 2)
+			l := startTarget(t, []string{"connect"}, func() {
+				_, _, errno := unix.Syscall(unix.SYS_CONNECT, uintptr(fd), uintptr(unsafe.Pointer(&sa)), unsafe.Sizeof(sa))
+				results <- errno
+				_, _, errno = unix.Syscall(unix.SYS_CONNECT, uintptr(fd), uintptr(unsafe.Pointer(&unspec)), unsafe.Sizeof(unspec))
+				results <- errno
+			})
+
+			s := NewSupervisor(l)
+			broker := &ConnectBroker{
+				Proxy: proxy,
+				Rules: []NetRule{{Prefix: netip.MustParsePrefix("127.0.0.0/8"), Ports: []uint16{53}}},
+			}
+			broker.Register(s)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+
+			for _, call := range []string{"connect", "disconnect"} {
+				if errno := <-results; errno != 0 {
+					t.Fatalf("%s failed: %v", call, errno)
+				}
+			}
+			if _, err = unix.Getpeername(fd); err != unix.ENOTCONN {
+				t.Errorf("expected socket to be disconnected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNetRuleMatches(t *testing.T) {
+	rule := NetRule{Prefix: netip.MustParsePrefix("10.0.0.0/8")}
+	if !rule.matches(&unix.SockaddrInet6{Addr: netip.MustParseAddr("::ffff:10.1.2.3").As16()}) {
+		t.Error("expected IPv4-mapped IPv6 address to match")
+	}
+	if rule.matches(&unix.SockaddrUnix{Name: "/run/socket"}) {
+		t.Error("expected inet rule not to match unix socket")
+	}
+
+	rule = NetRule{Path: "@abstract"}
+	if !rule.matches(&unix.SockaddrUnix{Name: "@abstract"}) {
+		t.Error("expected unix rule to match")
+	}
+	if rule.matches(&unix.SockaddrInet4{}) {
+		t.Error("expected unix rule not to match inet address")
+	}
+}
+
+func TestConnectBrokerSendmsg(t *testing.T) {
+	recv, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recv.Close()
+	port := recv.LocalAddr().(*net.UDPAddr).Port
+
+	testCases := []struct {
+		name    string
+		syscall string
+		proxy   bool
+		port    int
+		errno   unix.Errno
+	}{
+		{"sendmsg allowed", "sendmsg", false, port, 0},
+		{"sendmsg allowed proxy", "sendmsg", true, port, 0},
+		{"sendmsg denied", "sendmsg", false, port + 1, unix.EACCES},
+		{"sendmmsg allowed proxy", "sendmmsg", true, port, 0},
+		{"sendmmsg denied", "sendmmsg", true, port + 1, unix.EACCES},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer unix.Close(fd)
+
+			var sa unix.RawSockaddrInet4
+			sa.Family = unix.AF_INET
+			sa.Addr = [4]byte{127, 0, 0, 1}
+			*(*[2]byte)(unsafe.Pointer(&sa.Port)) = [2]byte{byte(tc.port >> 8), byte(tc.port)}
+			data := []byte(tc.name)
+			iov := unix.Iovec{Base: &data[0]}
+			iov.SetLen(len(data))
+			msgs := [2]mmsghdr{}
+			for i := range msgs {
+				msgs[i].Hdr = RemoteMsghdr{
+					Name:    uintptr(unsafe.Pointer(&sa)),
+					Namelen: uint32(unsafe.Sizeof(sa)),
+					Iov:     uintptr(unsafe.Pointer(&iov)),
+					Iovlen:  1,
+				}
+			}
+
+			type result struct {
+				ret   uintptr
+				errno unix.Errno
+			}
+			results := make(chan result, 1)
+			l := startTarget(t, []string{tc.syscall}, func() {
+				var r result
+				if tc.syscall == "sendmsg" {
+					r.ret, _, r.errno = unix.Syscall(unix.SYS_SENDMSG, uintptr(fd), uintptr(unsafe.Pointer(&msgs[0].Hdr)), 0)
+				} else {
+					r.ret, _, r.errno = unix.Syscall6(unix.SYS_SENDMMSG, uintptr(fd), uintptr(unsafe.Pointer(&msgs[0])), uintptr(len(msgs)), 0, 0, 0)
+				}
+				// Keep the memory referenced by the messages on the heap.
+				runtime.KeepAlive(&sa)
+				runtime.KeepAlive(&iov)
+				results <- r
+			})
+
+			s := NewSupervisor(l)
+			broker := &ConnectBroker{
+				Proxy: tc.proxy,
+				Rules: []NetRule{{Prefix: netip.MustParsePrefix("127.0.0.0/8"), Ports: []uint16{uint16(port)}}},
+			}
+			broker.Register(s)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+
+			r := <-results
+			if r.errno != tc.errno {
+				t.Fatalf("expected errno %v, got %v", tc.errno, r.errno)
+			}
+			if tc.errno != 0 {
+				return
+			}
+
+			want := len(data)
+			if tc.syscall == "sendmmsg" {
+				want = len(msgs)
+				for i := range msgs {
+					if int(msgs[i].Len) != len(data) {
+						t.Errorf("expected msg_len %d, got %d", len(data), msgs[i].Len)
+					}
+				}
+			}
+			if int(r.ret) != want {
+				t.Errorf("expected %d, got %d", want, r.ret)
+			}
+			buf := make([]byte, 64)
+			recv.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := recv.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf[:n]) != tc.name {
+				t.Errorf("unexpected datagram %q", buf[:n])
+			}
+		})
+	}
+}
+
+func TestConnectBrokerTimeout(t *testing.T) {
+	// A listener with a full accept queue drops the SYN of further
+	// connections, so that connect blocks.
+	lfd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(lfd)
+	if err = unix.Bind(lfd, &unix.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = unix.Listen(lfd, 0); err != nil {
+		t.Fatal(err)
+	}
+	lsa, err := unix.Getsockname(lfd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := lsa.(*unix.SockaddrInet4).Port
+	for i := 0; i < 2; i++ {
+		c, err := net.DialTimeout("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), 100*time.Millisecond)
+		if err != nil {
+			break
+		}
+		defer c.Close()
+	}
+
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+
+	var sa unix.RawSockaddrInet4
+	sa.Family = unix.AF_INET
+	sa.Addr = [4]byte{127, 0, 0, 1}
+	*(*[2]byte)(unsafe.Pointer(&sa.Port)) = [2]byte{byte(port >> 8), byte(port)}
+
+	results := make(chan unix.Errno, 1)
+	l := startTarget(t, []string{"connect"}, func() {
+		_, _, errno := unix.Syscall(unix.SYS_CONNECT, uintptr(fd), uintptr(unsafe.Pointer(&sa)), unsafe.Sizeof(sa))
+		results <- errno
+	})
+
+	s := NewSupervisor(l)
+	broker := &ConnectBroker{
+		Proxy:          true,
+		ConnectTimeout: 100 * time.Millisecond,
+		Rules:          []NetRule{{Prefix: netip.MustParsePrefix("127.0.0.0/8")}},
+	}
+	broker.Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	select {
+	case errno := <-results:
+		if errno != unix.ETIMEDOUT {
+			t.Fatalf("expected ETIMEDOUT, got %v", errno)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("connect was not aborted")
+	}
+}
@@ -0,0 +1,196 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxIovecs is the maximum number of iovec structures accepted by the kernel
+// (UIO_MAXIOV).
+const maxIovecs = 1024
+
+// ErrStringTooLong is returned when a string in the target's memory is not
+// terminated within the requested maximum length.
+var ErrStringTooLong = errors.New("string exceeds maximum length")
+
+// ReadString reads a NUL-terminated string of at most max bytes (excluding
+// the terminator) from address addr of the target. Use unix.PathMax as max
+// for paths.
+func (m *Memory) ReadString(addr uint64, max int) (string, error) {
+	const pageSize = 4096
+
+	var buf []byte
+	for len(buf) <= max {
+		// Never read across a page boundary in one go because the next page
+		// might not be mapped even though the string ends before it.
+		chunk := make([]byte, pageSize-(addr+uint64(len(buf)))%pageSize)
+		if _, err := m.ReadAt(chunk, int64(addr)+int64(len(buf))); err != nil {
+			return "", err
+		}
+		if i := bytes.IndexByte(chunk, 0); i >= 0 {
+			buf = append(buf, chunk[:i]...)
+			if len(buf) > max {
+				break
+			}
+			return string(buf), nil
+		}
+		buf = append(buf, chunk...)
+	}
+	return "", fmt.Errorf("failed to read string at 0x%x: %w", addr, ErrStringTooLong)
+}
+
+// ReadSockaddr decodes the socket address of length size at address addr of
+// the target, as passed to connect, bind, or sendto. The result is a
+// *unix.SockaddrInet4, *unix.SockaddrInet6, or *unix.SockaddrUnix.
+func (m *Memory) ReadSockaddr(addr uint64, size int) (unix.Sockaddr, error) {
+	var raw unix.RawSockaddrAny
+	if size < int(unsafe.Sizeof(raw.Addr.Family)) || size > int(unsafe.Sizeof(raw)) {
+		return nil, fmt.Errorf("invalid sockaddr length %d", size)
+	}
+	buf := (*[unix.SizeofSockaddrAny]byte)(unsafe.Pointer(&raw))[:size]
+	if _, err := m.ReadAt(buf, int64(addr)); err != nil {
+		return nil, err
+	}
+
+	switch raw.Addr.Family {
+	case unix.AF_INET:
+		if size < unix.SizeofSockaddrInet4 {
+			return nil, fmt.Errorf("invalid AF_INET sockaddr length %d", size)
+		}
+		sa := (*unix.RawSockaddrInet4)(unsafe.Pointer(&raw))
+		p := (*[2]byte)(unsafe.Pointer(&sa.Port))
+		return &unix.SockaddrInet4{Port: int(p[0])<<8 | int(p[1]), Addr: sa.Addr}, nil
+	case unix.AF_INET6:
+		if size < unix.SizeofSockaddrInet6 {
+			return nil, fmt.Errorf("invalid AF_INET6 sockaddr length %d", size)
+		}
+		sa := (*unix.RawSockaddrInet6)(unsafe.Pointer(&raw))
+		p := (*[2]byte)(unsafe.Pointer(&sa.Port))
+		return &unix.SockaddrInet6{Port: int(p[0])<<8 | int(p[1]), ZoneId: sa.Scope_id, Addr: sa.Addr}, nil
+	case unix.AF_UNIX:
+		sa := (*unix.RawSockaddrUnix)(unsafe.Pointer(&raw))
+		n := size - int(unsafe.Sizeof(sa.Family))
+		path := make([]byte, n)
+		for i := 0; i < n; i++ {
+			path[i] = byte(sa.Path[i])
+		}
+		switch {
+		case n > 0 && path[0] == 0:
+			// Abstract socket, same notation as package net.
+			path[0] = '@'
+		default:
+			if i := bytes.IndexByte(path, 0); i >= 0 {
+				path = path[:i]
+			}
+		}
+		return &unix.SockaddrUnix{Name: string(path)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported socket address family %d", raw.Addr.Family)
+	}
+}
+
+// ReadIovecs reads an array of count iovec structures from address addr of
+// the target, as passed to readv, writev, or in a msghdr.
+func (m *Memory) ReadIovecs(addr uint64, count int) ([]unix.RemoteIovec, error) {
+	if count < 0 || count > maxIovecs {
+		return nil, fmt.Errorf("invalid iovec count %d", count)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	iovs := make([]unix.RemoteIovec, count)
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&iovs[0])), count*int(unsafe.Sizeof(iovs[0])))
+	if _, err := m.ReadAt(buf, int64(addr)); err != nil {
+		return nil, err
+	}
+	return iovs, nil
+}
+
+// RemoteMsghdr is struct msghdr of the target. Its addresses refer to the
+// target's memory.
+type RemoteMsghdr struct {
+	Name       uintptr
+	Namelen    uint32
+	Iov        uintptr
+	Iovlen     uintptr
+	Control    uintptr
+	Controllen uintptr
+	Flags      int32
+}
+
+// ReadMsghdr reads the struct msghdr at address addr of the target, as passed
+// to sendmsg or recvmsg.
+func (m *Memory) ReadMsghdr(addr uint64) (*RemoteMsghdr, error) {
+	var hdr RemoteMsghdr
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&hdr)), unsafe.Sizeof(hdr))
+	if _, err := m.ReadAt(buf, int64(addr)); err != nil {
+		return nil, err
+	}
+	return &hdr, nil
+}
+
+// ReadIovecData reads the data referenced by iovs, up to max bytes in total.
+func (m *Memory) ReadIovecData(iovs []unix.RemoteIovec, max int) ([]byte, error) {
+	var data []byte
+	for _, iov := range iovs {
+		n := iov.Len
+		if n > max-len(data) {
+			n = max - len(data)
+		}
+		if n <= 0 {
+			break
+		}
+		buf := make([]byte, n)
+		if _, err := m.ReadAt(buf, int64(iov.Base)); err != nil {
+			return nil, err
+		}
+		data = append(data, buf...)
+	}
+	return data, nil
+}
+
+// ReadOpenHow reads the struct open_how of the given size at address addr
+// of the target, as passed to openat2. Trailing fields unknown to this
+// package must be zero, like the kernel requires.
+func (m *Memory) ReadOpenHow(addr uint64, size int) (*unix.OpenHow, error) {
+	var how unix.OpenHow
+	known := int(unsafe.Sizeof(how))
+	if size < known || size > 4096 {
+		return nil, fmt.Errorf("invalid open_how size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := m.ReadAt(buf, int64(addr)); err != nil {
+		return nil, err
+	}
+	for _, b := range buf[known:] {
+		if b != 0 {
+			return nil, fmt.Errorf("open_how contains unknown non-zero fields")
+		}
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&how)), known), buf)
+	return &how, nil
+}
@@ -0,0 +1,161 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// withPointerMemory starts a target passing data to getppid and calls fn
+// with the memory of the target and the address of data.
+func withPointerMemory(t *testing.T, data []byte, fn func(mem *Memory, addr uint64, size int)) {
+	t.Helper()
+	l := startPointerTarget(t, data)
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Respond(ReturnValue(n, 0))
+
+	target, err := l.OpenTarget(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	mem, err := target.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	fn(mem, n.Data.Args[0], int(n.Data.Args[1]))
+}
+
+func rawBytes(p unsafe.Pointer, size uintptr) []byte {
+	return append([]byte(nil), unsafe.Slice((*byte)(p), size)...)
+}
+
+func TestReadString(t *testing.T) {
+	withPointerMemory(t, []byte("/etc/passwd\x00garbage"), func(mem *Memory, addr uint64, _ int) {
+		s, err := mem.ReadString(addr, unix.PathMax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != "/etc/passwd" {
+			t.Errorf("unexpected string %q", s)
+		}
+
+		if _, err = mem.ReadString(addr, 4); err == nil {
+			t.Error("expected ErrStringTooLong")
+		}
+	})
+}
+
+func TestReadSockaddr(t *testing.T) {
+	inet4 := unix.RawSockaddrInet4{Family: unix.AF_INET, Addr: [4]byte{127, 0, 0, 1}}
+	*(*[2]byte)(unsafe.Pointer(&inet4.Port)) = [2]byte{0x1f, 0x90}
+
+	inet6 := unix.RawSockaddrInet6{Family: unix.AF_INET6, Addr: [16]byte{15: 1}}
+	*(*[2]byte)(unsafe.Pointer(&inet6.Port)) = [2]byte{0, 53}
+
+	unixPath := unix.RawSockaddrUnix{Family: unix.AF_UNIX}
+	for i, c := range "/run/test.sock" {
+		unixPath.Path[i] = int8(c)
+	}
+
+	abstract := unix.RawSockaddrUnix{Family: unix.AF_UNIX}
+	for i, c := range "\x00name" {
+		abstract.Path[i] = int8(c)
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+		want unix.Sockaddr
+	}{
+		{"inet4", rawBytes(unsafe.Pointer(&inet4), unsafe.Sizeof(inet4)), &unix.SockaddrInet4{Port: 8080, Addr: [4]byte{127, 0, 0, 1}}},
+		{"inet6", rawBytes(unsafe.Pointer(&inet6), unsafe.Sizeof(inet6)), &unix.SockaddrInet6{Port: 53, Addr: [16]byte{15: 1}}},
+		{"unix", rawBytes(unsafe.Pointer(&unixPath), unsafe.Sizeof(unixPath)), &unix.SockaddrUnix{Name: "/run/test.sock"}},
+		{"abstract", rawBytes(unsafe.Pointer(&abstract), 2+5), &unix.SockaddrUnix{Name: "@name"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPointerMemory(t, tc.data, func(mem *Memory, addr uint64, size int) {
+				sa, err := mem.ReadSockaddr(addr, size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(sa, tc.want) {
+					t.Errorf("expected %+v, got %+v", tc.want, sa)
+				}
+			})
+		})
+	}
+}
+
+func TestReadIovecs(t *testing.T) {
+	first, second := []byte("hello "), []byte("world")
+	iovs := []unix.RemoteIovec{
+		{Base: uintptr(unsafe.Pointer(&first[0])), Len: len(first)},
+		{Base: uintptr(unsafe.Pointer(&second[0])), Len: len(second)},
+	}
+	data := rawBytes(unsafe.Pointer(&iovs[0]), uintptr(len(iovs))*unsafe.Sizeof(iovs[0]))
+
+	withPointerMemory(t, data, func(mem *Memory, addr uint64, _ int) {
+		got, err := mem.ReadIovecs(addr, len(iovs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, iovs) {
+			t.Errorf("expected %+v, got %+v", iovs, got)
+		}
+
+		buf, err := mem.ReadIovecData(got, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != "hello wo" {
+			t.Errorf("unexpected data %q", buf)
+		}
+	})
+}
+
+func TestReadOpenHow(t *testing.T) {
+	how := unix.OpenHow{Flags: unix.O_RDONLY | unix.O_CLOEXEC, Resolve: unix.RESOLVE_BENEATH}
+	data := rawBytes(unsafe.Pointer(&how), unsafe.Sizeof(how))
+
+	withPointerMemory(t, append(data, make([]byte, 8)...), func(mem *Memory, addr uint64, size int) {
+		got, err := mem.ReadOpenHow(addr, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != how {
+			t.Errorf("expected %+v, got %+v", how, *got)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Credentials identify the process that triggered a notification.
+type Credentials struct {
+	UID, EUID uint32 // Real and effective user ID.
+	GID, EGID uint32 // Real and effective group ID.
+	Cgroup    string // Cgroup v2 path, or the first cgroup v1 path.
+}
+
+// Credentials returns the credentials of the target read from its /proc
+// directory. They are only valid while the notification is pending because
+// the target may change them once it continues.
+func (t *Target) Credentials() (*Credentials, error) {
+	var c Credentials
+	err := t.scanProc("status", func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return
+		}
+		switch fields[0] {
+		case "Uid:":
+			c.UID, c.EUID = parseID(fields[1]), parseID(fields[2])
+		case "Gid:":
+			c.GID, c.EGID = parseID(fields[1]), parseID(fields[2])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.scanProc("cgroup", func(line string) {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			return
+		}
+		if (parts[0] == "0" && parts[1] == "") || c.Cgroup == "" {
+			c.Cgroup = parts[2]
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = t.Valid(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// scanProc calls fn for each line of a file in the target's /proc directory.
+func (t *Target) scanProc(name string, fn func(line string)) error {
+	fd, err := unix.Openat(int(t.proc.Fd()), name, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if err == unix.ESRCH || err == unix.ENOENT {
+			return ErrNotificationGone
+		}
+		return fmt.Errorf("failed to open /proc/%d/%s: %w", t.Pid, name, err)
+	}
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close()
+
+	s := bufio.NewScanner(io.LimitReader(f, 1<<20))
+	for s.Scan() {
+		fn(s.Text())
+	}
+	if err = s.Err(); err != nil {
+		return fmt.Errorf("failed to read /proc/%d/%s: %w", t.Pid, name, err)
+	}
+	return nil
+}
+
+func parseID(s string) uint32 {
+	id, _ := strconv.ParseUint(s, 10, 32)
+	return uint32(id)
+}
+
+// Mux is a set of handlers registered by syscall name, for example the rules
+// of one tenant of a Dispatcher.
+type Mux struct {
+	// Default handles syscalls without a registered handler. If nil, the
+	// ErrorPolicy of the Supervisor is applied to them.
+	Default Handler
+
+	handlers map[string]Handler
+}
+
+// NewMux returns an empty Mux.
+func NewMux() *Mux {
+	return &Mux{handlers: map[string]Handler{}}
+}
+
+// Handle registers the handler for the given syscall name.
+func (m *Mux) Handle(name string, h Handler) {
+	m.handlers[name] = h
+}
+
+// HandleFunc registers the handler function for the given syscall name.
+func (m *Mux) HandleFunc(name string, f func(req *Request) (*Response, error)) {
+	m.Handle(name, HandlerFunc(f))
+}
+
+// Dispatch passes the request to the handler registered for its syscall.
+func (m *Mux) Dispatch(req *Request) (*Response, error) {
+	h := m.handlers[req.Syscall]
+	if h == nil {
+		h = m.Default
+	}
+	if h == nil {
+		return nil, fmt.Errorf("no handler for syscall %q", req.Syscall)
+	}
+	return h.Handle(req)
+}
+
+// DispatchRule selects the handlers for the processes matching it. Empty
+// criteria match all processes.
+type DispatchRule struct {
+	UIDs   []uint32 // Real user IDs.
+	Cgroup string   // Cgroup path prefix, for example "/tenants/a".
+
+	Handlers *Mux
+}
+
+func (r *DispatchRule) matches(c *Credentials) bool {
+	if len(r.UIDs) > 0 {
+		found := false
+		for _, uid := range r.UIDs {
+			if uid == c.UID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.Cgroup != "" {
+		prefix := strings.TrimSuffix(r.Cgroup, "/")
+		if c.Cgroup != prefix && !strings.HasPrefix(c.Cgroup, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// Dispatcher is a Handler that selects a set of handlers based on the UID or
+// cgroup of the notifying process, so that one supervisor can serve many
+// tenants with different rules. Register it as Supervisor.Default.
+type Dispatcher struct {
+	// Rules are evaluated in order and the first match is used.
+	Rules []DispatchRule
+
+	// Default handles processes that match no rule. If nil, the ErrorPolicy
+	// of the Supervisor is applied to them.
+	Default Handler
+}
+
+// Handle implements Handler.
+func (d *Dispatcher) Handle(req *Request) (*Response, error) {
+	target, err := req.Target()
+	if err != nil {
+		return nil, err
+	}
+	creds, err := target.Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range d.Rules {
+		if d.Rules[i].matches(creds) {
+			return d.Rules[i].Handlers.Dispatch(req)
+		}
+	}
+	if d.Default == nil {
+		return nil, fmt.Errorf("no dispatch rule for uid %d in cgroup %q", creds.UID, creds.Cgroup)
+	}
+	return d.Default.Handle(req)
+}
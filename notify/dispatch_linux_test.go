@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestDispatchRuleMatches(t *testing.T) {
+	creds := &Credentials{UID: 1000, Cgroup: "/tenants/a/workload"}
+	testCases := []struct {
+		rule DispatchRule
+		want bool
+	}{
+		{DispatchRule{}, true},
+		{DispatchRule{UIDs: []uint32{0, 1000}}, true},
+		{DispatchRule{UIDs: []uint32{0}}, false},
+		{DispatchRule{Cgroup: "/tenants/a"}, true},
+		{DispatchRule{Cgroup: "/tenants/a/"}, true},
+		{DispatchRule{Cgroup: "/tenants/ab"}, false},
+		{DispatchRule{UIDs: []uint32{1000}, Cgroup: "/tenants/b"}, false},
+	}
+	for _, tc := range testCases {
+		if got := tc.rule.matches(creds); got != tc.want {
+			t.Errorf("%+v: expected %v, got %v", tc.rule, tc.want, got)
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	results := make(chan uintptr, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		ppid, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		results <- ppid
+	})
+
+	other := NewMux()
+	other.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		return ReturnValue(req.Notification, 1), nil
+	})
+	own := NewMux()
+	own.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		target, err := req.Target()
+		if err != nil {
+			return nil, err
+		}
+		creds, err := target.Credentials()
+		if err != nil {
+			return nil, err
+		}
+		if creds.EUID != uint32(os.Geteuid()) || creds.Cgroup == "" {
+			t.Errorf("unexpected credentials %+v", creds)
+		}
+		return ReturnValue(req.Notification, 4242), nil
+	})
+
+	s := NewSupervisor(l)
+	s.Default = &Dispatcher{Rules: []DispatchRule{
+		{UIDs: []uint32{uint32(os.Getuid()) + 1}, Handlers: other},
+		{UIDs: []uint32{uint32(os.Getuid())}, Handlers: own},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	if ppid := <-results; ppid != 4242 {
+		t.Errorf("expected getppid to return 4242, got %d", ppid)
+	}
+}
@@ -0,0 +1,27 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package notify provides access to the seccomp user-space notification
+// mechanism. Syscalls matching a filter rule with seccomp.ActionUserNotify
+// are suspended and forwarded to a supervisor that reads them from the
+// listener file descriptor returned by seccomp.LoadFilterListener and answers
+// them on behalf of the kernel.
+//
+// Supervisor implements the receive loop and dispatches notifications to
+// handlers registered by syscall name. Listener, Target, and Memory provide
+// the lower level building blocks.
+package notify
@@ -0,0 +1,59 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Violation returns the notification as a violation that can be shipped as
+// an ECS document with seccomp.ECSWriter. The command name and executable
+// are read from /proc and are empty if the target has exited.
+func (r *Request) Violation() seccomp.Violation {
+	return newViolation(time.Now(), int(r.Pid), r.Syscall, r.Data)
+}
+
+// Violation returns the record as a violation. See Request.Violation.
+func (r Record) Violation() seccomp.Violation {
+	return newViolation(r.Time, r.Pid, r.Syscall, r.Data)
+}
+
+func newViolation(t time.Time, pid int, name string, data seccomp.SeccompData) seccomp.Violation {
+	info, _ := syscallArch(data)
+	comm, _ := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	exe, _ := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	return seccomp.Violation{
+		Time:    t,
+		Source:  seccomp.ViolationSourceSupervisor,
+		PID:     pid,
+		Comm:    strings.TrimSuffix(string(comm), "\n"),
+		Exe:     exe,
+		Arch:    info,
+		Syscall: name,
+		Action:  seccomp.ActionUserNotify,
+		Data:    &data,
+	}
+}
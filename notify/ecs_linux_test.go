@@ -0,0 +1,56 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"os"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestRecordViolation(t *testing.T) {
+	info, err := arch.GetInfo("")
+	if err != nil {
+		t.Skip(err)
+	}
+	nr, _ := info.SyscallNumber("getpid")
+	r := Record{
+		Pid:     os.Getpid(),
+		Syscall: "getpid",
+		Data:    seccomp.SeccompData{NR: int32(nr), Arch: uint32(info.ID)},
+	}
+
+	v := r.Violation()
+	if v.Source != seccomp.ViolationSourceSupervisor || v.Action != seccomp.ActionUserNotify {
+		t.Errorf("unexpected source %v or action %v", v.Source, v.Action)
+	}
+	if v.Arch != info {
+		t.Errorf("expected arch %v, got %v", info.Name, v.Arch)
+	}
+	if exe, _ := os.Executable(); v.Exe != exe || v.Comm == "" {
+		t.Errorf("expected exe %v and a command name, got %q and %q", exe, v.Exe, v.Comm)
+	}
+	if v.Data == nil || v.Data.NR != r.Data.NR {
+		t.Errorf("unexpected data %+v", v.Data)
+	}
+}
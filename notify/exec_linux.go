@@ -0,0 +1,291 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// ExecRule allows executing a binary.
+type ExecRule struct {
+	Name   string // Optional identifier of the rule used in logs.
+	Path   string // Absolute path of the binary in the target's root with all symbolic links resolved.
+	SHA256 string // Optional hex encoded SHA-256 digest of the binary.
+}
+
+// ExecBroker is a Handler that checks the binary of execve and execveat
+// against an allowlist before letting the syscall continue. The path is
+// resolved by the kernel in the target's root directory relative to the
+// target's working directory or dirfd, symbolic links are followed, and the
+// result must match the Path of a rule. Symbolic links into /proc that
+// refer to the opening process cannot be resolved for the target and are
+// denied, as are relative paths from directories outside the target's root
+// directory. If the
+// rule has a digest, the file found by the supervisor is hashed and must
+// match it. The binary is opened with O_PATH, so binaries that the target
+// may execute but not read are allowed, and hashing them requires the
+// supervisor to be able to read them.
+//
+// The syscall continues with ContinueUnsafe, so the kernel resolves the path
+// again and executes whatever it finds then. Neither the path nor the digest
+// check is reliable if the target can modify the path in its memory from
+// another thread or replace or modify the allowed binaries or the
+// directories leading to them, so the digest is no integrity guarantee for
+// the executed binary. The binaries should be read-only to the target.
+// Executing an already open descriptor with execveat(fd, "", AT_EMPTY_PATH)
+// avoids the second lookup of the file but not modifications of it.
+type ExecBroker struct {
+	Rules []ExecRule
+
+	// Errno is returned for binaries that are not allowed. Defaults to
+	// EACCES.
+	Errno syscall.Errno
+}
+
+// ExecSyscalls are the syscalls handled by ExecBroker.
+var ExecSyscalls = []string{"execve", "execveat"}
+
+// Register registers the broker for all ExecSyscalls.
+func (b *ExecBroker) Register(s *Supervisor) {
+	for _, name := range ExecSyscalls {
+		s.Handle(name, b)
+	}
+}
+
+// Handle implements Handler.
+func (b *ExecBroker) Handle(req *Request) (*Response, error) {
+	mem, err := req.Memory()
+	if err != nil {
+		return nil, err
+	}
+
+	args := req.Data.Args
+	dirfd := unix.AT_FDCWD
+	var pathAddr uint64
+	var flags int
+	switch req.Syscall {
+	case "execve":
+		pathAddr = args[0]
+	case "execveat":
+		dirfd, pathAddr, flags = int(int32(args[0])), args[1], int(args[4])
+	default:
+		return nil, fmt.Errorf("exec broker cannot handle syscall %q", req.Syscall)
+	}
+
+	path, err := mem.ReadString(pathAddr, unix.PathMax)
+	if err != nil {
+		return nil, err
+	}
+	target, err := req.Target()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := openRoot(target)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	var f *os.File
+	if path == "" && flags&unix.AT_EMPTY_PATH != 0 {
+		f, err = target.GetFd(dirfd)
+	} else {
+		f, err = openExecutable(target, root, dirfd, path, flags)
+	}
+	if err != nil {
+		if errno, ok := err.(syscall.Errno); ok {
+			return ReturnErrno(req.Notification, int(errno)), nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	realPath, err := fdPath(int(f.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	realPath, ok := inRoot(root.Name(), realPath)
+	if !ok {
+		req.LogAttrs(slog.String("reason", "binary outside of the root of the target"))
+		return b.deny(req), nil
+	}
+	req.LogAttrs(slog.String("path", realPath))
+
+	rule := b.match(realPath)
+	if rule != nil && rule.Name != "" {
+		req.LogAttrs(slog.String("rule", rule.Name))
+	}
+	if rule == nil {
+		return b.deny(req), nil
+	}
+	if rule.SHA256 != "" {
+		ok, err := verifySHA256(f, rule.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			req.LogAttrs(slog.String("reason", "digest mismatch"))
+			return b.deny(req), nil
+		}
+	}
+
+	if err = req.Valid(); err != nil {
+		return nil, err
+	}
+	return ContinueUnsafe(req.Notification), nil
+}
+
+func (b *ExecBroker) deny(req *Request) *Response {
+	errno := b.Errno
+	if errno == 0 {
+		errno = unix.EACCES
+	}
+	return ReturnErrno(req.Notification, int(errno))
+}
+
+// match returns the rule for the binary at path.
+func (b *ExecBroker) match(path string) *ExecRule {
+	for i := range b.Rules {
+		if filepath.Clean(b.Rules[i].Path) == path {
+			return &b.Rules[i]
+		}
+	}
+	return nil
+}
+
+// openRoot opens the root directory of the target. The name of the file is
+// the path of the directory in the root directory of the supervisor.
+func openRoot(target *Target) (*os.File, error) {
+	fd, err := targetRoot(target)
+	if err != nil {
+		return nil, err
+	}
+	if fd < 0 {
+		if fd, err = unix.Open("/", unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0); err != nil {
+			return nil, err
+		}
+	}
+	path, err := fdPath(fd)
+	if err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
+
+// openExecutable opens the regular file that execveat(dirfd, path, flags)
+// would execute in the target. The path is resolved by the kernel in root,
+// the root directory of the target.
+func openExecutable(target *Target, root *os.File, dirfd int, path string, flags int) (*os.File, error) {
+	if !filepath.IsAbs(path) {
+		// The path is not cleaned, so that ".." follows symbolic links
+		// like in the kernel, and cannot leave the root.
+		var dir string
+		var err error
+		if dirfd == unix.AT_FDCWD {
+			dir, err = target.Cwd()
+		} else {
+			dir, err = target.FdPath(dirfd)
+		}
+		if err != nil {
+			return nil, err
+		}
+		dir, ok := inRoot(root.Name(), dir)
+		if !ok {
+			return nil, unix.EACCES
+		}
+		path = dir + "/" + path
+	}
+
+	// O_PATH does not require read permission, like execve.
+	how := unix.OpenHow{
+		Flags:   unix.O_PATH | unix.O_CLOEXEC,
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_MAGICLINKS,
+	}
+	if flags&unix.AT_SYMLINK_NOFOLLOW != 0 {
+		how.Flags |= unix.O_NOFOLLOW
+	}
+	fd, err := unix.Openat2(int(root.Fd()), path, &how)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), path)
+
+	var st unix.Stat_t
+	if err = unix.Fstat(fd, &st); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFREG {
+		f.Close()
+		return nil, unix.EACCES
+	}
+	return f, nil
+}
+
+// fdPath returns the path of the descriptor fd in the root directory of the
+// supervisor.
+func fdPath(fd int) (string, error) {
+	return os.Readlink("/proc/self/fd/" + strconv.Itoa(fd))
+}
+
+// inRoot returns path, a path in the root directory of the supervisor, as a
+// path in root. It reports false if path is not below root.
+func inRoot(root, path string) (string, bool) {
+	switch {
+	case root == "/":
+		return path, true
+	case path == root:
+		return "/", true
+	case strings.HasPrefix(path, root+"/"):
+		return path[len(root):], true
+	}
+	return "", false
+}
+
+// verifySHA256 reports whether the content of f has the hex encoded digest.
+// f may be opened with O_PATH, so the content is read from a new descriptor
+// opened through /proc/self/fd, which refers to the same file.
+func verifySHA256(f *os.File, digest string) (bool, error) {
+	r, err := os.Open("/proc/self/fd/" + strconv.Itoa(int(f.Fd())))
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(hex.EncodeToString(h.Sum(nil)), digest), nil
+}
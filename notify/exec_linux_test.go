@@ -0,0 +1,197 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestExecBroker(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err = os.Symlink(exe, link); err != nil {
+		t.Fatal(err)
+	}
+	other, err := filepath.EvalSymlinks("/bin/sh")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// Binaries may be executable without being readable.
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	execOnly := filepath.Join(dir, "exec-only")
+	if err = os.WriteFile(execOnly, []byte("#!/bin/sh\n"), 0o111); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name  string
+		rules []ExecRule
+		path  string
+		errno unix.Errno
+	}{
+		// The syscalls that continue fail with EFAULT because of the invalid
+		// argv and never replace the test process.
+		{"allowed", []ExecRule{{Path: exe}}, exe, unix.EFAULT},
+		{"digest", []ExecRule{{Path: exe, SHA256: digest}}, exe, unix.EFAULT},
+		{"symlink", []ExecRule{{Path: exe}}, link, unix.EFAULT},
+		{"execute only", []ExecRule{{Path: execOnly}}, execOnly, unix.EFAULT},
+		{"mismatch", []ExecRule{{Path: exe, SHA256: digest[1:] + "0"}}, exe, unix.EPERM},
+		{"unlisted", []ExecRule{{Path: exe}}, other, unix.EPERM},
+		{"missing", []ExecRule{{Path: exe}}, exe + ".missing", unix.ENOENT},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := unix.BytePtrFromString(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done := make(chan struct{})
+			var errno unix.Errno
+			l := startTarget(t, ExecSyscalls, func() {
+				defer close(done)
+				_, _, errno = unix.Syscall(unix.SYS_EXECVE, uintptr(unsafe.Pointer(path)), 1, 0)
+			})
+
+			s := NewSupervisor(l)
+			b := &ExecBroker{Rules: tc.rules, Errno: unix.EPERM}
+			b.Register(s)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+			<-done
+
+			if errno != tc.errno {
+				t.Errorf("expected %v, got %v", tc.errno, errno)
+			}
+		})
+	}
+}
+
+func TestExecBrokerForeignRoot(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(filepath.Join(dir, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"allowed", "other"} {
+		if err = os.WriteFile(filepath.Join(dir, "bin", name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Points to the rule in the root of the target only.
+	if err = os.Symlink("/bin/allowed", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path  string
+		errno unix.Errno
+	}{
+		// The syscalls that continue fail with EFAULT because of the invalid
+		// argv.
+		{"/bin/allowed", unix.EFAULT},
+		{"/link", unix.EFAULT},
+		{"allowed", unix.EFAULT},
+		{"../bin/allowed", unix.EFAULT},
+		{"/../../bin/allowed", unix.EFAULT},
+		{"/bin/other", unix.EPERM},
+		// The missing directory is not skipped like by a lexical cleanup.
+		{"missing/../allowed", unix.ENOENT},
+		{filepath.Join(dir, "bin", "allowed"), unix.ENOENT},
+	}
+
+	chrooted := make(chan error, 1)
+	results := make(chan unix.Errno, len(testCases))
+	l := startTarget(t, ExecSyscalls, func() {
+		// The thread gets its own root. It is discarded afterwards.
+		err := unix.Unshare(unix.CLONE_FS)
+		if err == nil {
+			err = unix.Chroot(dir)
+		}
+		if err == nil {
+			err = unix.Chdir("/bin")
+		}
+		chrooted <- err
+		if err != nil {
+			return
+		}
+		for _, tc := range testCases {
+			path, err := unix.BytePtrFromString(tc.path)
+			if err != nil {
+				results <- unix.EINVAL
+				continue
+			}
+			_, _, errno := unix.Syscall(unix.SYS_EXECVE, uintptr(unsafe.Pointer(path)), 1, 0)
+			results <- errno
+		}
+	})
+	if err := <-chrooted; err != nil {
+		t.Skipf("cannot change the root directory: %v", err)
+	}
+
+	s := NewSupervisor(l)
+	b := &ExecBroker{Rules: []ExecRule{{Path: "/bin/allowed"}}, Errno: unix.EPERM}
+	b.Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	for _, tc := range testCases {
+		if got := <-results; got != tc.errno {
+			t.Errorf("execve %v: expected %v, got %v", tc.path, tc.errno, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"math/rand/v2"
+	"sync"
+	"syscall"
+)
+
+// Fault makes a syscall fail with an errno.
+type Fault struct {
+	Syscall string        // Name of the syscall.
+	Errno   syscall.Errno // Error returned by the syscall.
+
+	// Probability of failing a call, between 0 and 1.
+	Probability float64
+	// Every fails every nth call in addition to Probability, 0 disables it.
+	Every int
+	// After skips the first calls before any fault is injected.
+	After int
+}
+
+// FaultInjector is a Handler for chaos testing that makes selected syscalls
+// fail with an errno on a schedule or at random, for example 1% of the write
+// calls with EIO. Calls that are not failed are passed to Next.
+type FaultInjector struct {
+	Faults []Fault
+
+	// Next handles the calls that are not failed. Defaults to letting the
+	// syscall continue (see ContinueUnsafe).
+	Next Handler
+
+	// Seed makes the random faults reproducible. Zero uses a random seed.
+	Seed uint64
+
+	once   sync.Once
+	mu     sync.Mutex
+	rnd    *rand.Rand
+	counts map[string]int
+}
+
+// Register registers the injector for the syscalls of its faults.
+func (f *FaultInjector) Register(s *Supervisor) {
+	for _, fault := range f.Faults {
+		s.Handle(fault.Syscall, f)
+	}
+}
+
+// Handle implements Handler.
+func (f *FaultInjector) Handle(req *Request) (*Response, error) {
+	if errno, ok := f.inject(req.Syscall); ok {
+		return ReturnErrno(req.Notification, int(errno)), nil
+	}
+	if f.Next != nil {
+		return f.Next.Handle(req)
+	}
+	return ContinueUnsafe(req.Notification), nil
+}
+
+// inject returns the errno to fail the call with, if any.
+func (f *FaultInjector) inject(name string) (syscall.Errno, bool) {
+	f.once.Do(func() {
+		seed := f.Seed
+		if seed == 0 {
+			seed = rand.Uint64()
+		}
+		f.rnd = rand.New(rand.NewPCG(seed, seed))
+		f.counts = map[string]int{}
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.counts[name]++
+	n := f.counts[name]
+	for _, fault := range f.Faults {
+		if fault.Syscall != name || n <= fault.After {
+			continue
+		}
+		if fault.Every > 0 && (n-fault.After)%fault.Every == 0 {
+			return fault.Errno, true
+		}
+		if fault.Probability > 0 && f.rnd.Float64() < fault.Probability {
+			return fault.Errno, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestFaultInjectorSchedule(t *testing.T) {
+	f := &FaultInjector{Faults: []Fault{{Syscall: "write", Errno: syscall.EIO, Every: 3, After: 2}}}
+
+	var failed []int
+	for i := 1; i <= 10; i++ {
+		if errno, ok := f.inject("write"); ok {
+			if errno != syscall.EIO {
+				t.Fatalf("unexpected errno %v", errno)
+			}
+			failed = append(failed, i)
+		}
+	}
+	if want := []int{5, 8}; len(failed) != len(want) || failed[0] != want[0] || failed[1] != want[1] {
+		t.Errorf("expected calls %v to fail, got %v", want, failed)
+	}
+	if _, ok := f.inject("read"); ok {
+		t.Error("unexpected fault for read")
+	}
+}
+
+func TestFaultInjectorProbability(t *testing.T) {
+	f := &FaultInjector{Seed: 1, Faults: []Fault{{Syscall: "write", Errno: syscall.EIO, Probability: 0.1}}}
+
+	failures := 0
+	for i := 0; i < 10000; i++ {
+		if _, ok := f.inject("write"); ok {
+			failures++
+		}
+	}
+	if failures < 800 || failures > 1200 {
+		t.Errorf("expected about 1000 failures, got %d", failures)
+	}
+}
+
+func TestFaultInjector(t *testing.T) {
+	var errnos [3]unix.Errno
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid"}, func() {
+		defer close(done)
+		for i := range errnos {
+			_, _, errnos[i] = unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		}
+	})
+
+	s := NewSupervisor(l)
+	(&FaultInjector{Faults: []Fault{{Syscall: "getppid", Errno: syscall.EIO, Every: 2}}}).Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	<-done
+
+	if want := [3]unix.Errno{0, unix.EIO, 0}; errnos != want {
+		t.Errorf("expected %v, got %v", want, errnos)
+	}
+}
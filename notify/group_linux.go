@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrGroupStopped is returned when a Supervisor is added to a Group that has
+// stopped running.
+var ErrGroupStopped = errors.New("supervisor group stopped")
+
+// Group runs the Supervisors of many sandboxed children concurrently.
+// Supervisors can be added and removed while the group is running. All
+// listeners are registered with the runtime network poller, which waits for
+// them together using epoll, so an idle child does not occupy a thread.
+//
+// A Supervisor is removed from the group when its Run returns, usually
+// because all threads of the child have exited.
+type Group struct {
+	// OnExit is called with the name of a Supervisor and the error returned
+	// by its Run when it stops, including after Remove.
+	OnExit func(name string, err error)
+
+	mu      sync.Mutex
+	ctx     context.Context
+	stopped bool
+	members map[string]*member
+	wg      sync.WaitGroup
+}
+
+type member struct {
+	supervisor *Supervisor
+	cancel     context.CancelFunc
+	done       chan struct{}
+	err        error
+}
+
+// NewGroup returns an empty Group.
+func NewGroup() *Group {
+	return &Group{members: map[string]*member{}}
+}
+
+// Add registers a Supervisor under a unique name. If the group is running
+// the Supervisor starts immediately, otherwise it starts with Run. The group
+// takes ownership of the Supervisor's listener.
+func (g *Group) Add(name string, s *Supervisor) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stopped {
+		return ErrGroupStopped
+	}
+	if _, found := g.members[name]; found {
+		return fmt.Errorf("supervisor %q already exists", name)
+	}
+	m := &member{supervisor: s, done: make(chan struct{})}
+	g.members[name] = m
+	if g.ctx != nil {
+		g.start(name, m)
+	}
+	return nil
+}
+
+// Remove stops the Supervisor registered under name, waits for it to return
+// and returns its error. Pending notifications are answered according to
+// the Supervisor's Shutdown policy.
+func (g *Group) Remove(name string) error {
+	g.mu.Lock()
+	m, found := g.members[name]
+	delete(g.members, name)
+	g.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("supervisor %q not found", name)
+	}
+	if m.cancel == nil {
+		return m.supervisor.Listener.Close()
+	}
+	m.cancel()
+	<-m.done
+	return m.err
+}
+
+// Names returns the names of the registered Supervisors in sorted order.
+func (g *Group) Names() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, 0, len(g.members))
+	for name := range g.members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run starts the registered Supervisors and blocks until ctx is done. It then
+// stops all Supervisors and waits for them to return. A Group can only be run
+// once.
+func (g *Group) Run(ctx context.Context) error {
+	g.mu.Lock()
+	if g.ctx != nil || g.stopped {
+		g.mu.Unlock()
+		return errors.New("supervisor group already started")
+	}
+	g.ctx = ctx
+	for name, m := range g.members {
+		g.start(name, m)
+	}
+	g.mu.Unlock()
+
+	<-ctx.Done()
+
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+	g.wg.Wait()
+	return nil
+}
+
+// start runs the Supervisor of m. It must be called with g.mu held.
+func (g *Group) start(name string, m *member) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	m.cancel = cancel
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer cancel()
+
+		m.err = m.supervisor.Run(ctx)
+		g.mu.Lock()
+		if g.members[name] == m {
+			delete(g.members, name)
+		}
+		g.mu.Unlock()
+		close(m.done)
+
+		if g.OnExit != nil {
+			g.OnExit(name, m.err)
+		}
+	}()
+}
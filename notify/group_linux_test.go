@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestGroup(t *testing.T) {
+	exited := make(chan string, 3)
+	g := NewGroup()
+	g.OnExit = func(name string, err error) {
+		if err != nil {
+			t.Errorf("supervisor %q failed: %v", name, err)
+		}
+		exited <- name
+	}
+
+	var wg sync.WaitGroup
+	results := map[string]*uintptr{}
+	for _, child := range []struct {
+		name string
+		val  int64
+	}{{"a", 1}, {"b", 2}} {
+		var ppid uintptr
+		results[child.name] = &ppid
+		wg.Add(1)
+		l := startTarget(t, []string{"getppid"}, func() {
+			defer wg.Done()
+			ppid, _, _ = unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		})
+		s := NewSupervisor(l)
+		s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+			return ReturnValue(req.Notification, child.val), nil
+		})
+		if err := g.Add(child.name, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Add("a", NewSupervisor(nil)); err == nil {
+		t.Error("expected duplicate name to be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- g.Run(ctx) }()
+
+	// A child added while the group runs and removed before it exits.
+	blocked := make(chan struct{})
+	var errno unix.Errno
+	l := startTarget(t, []string{"getuid"}, func() {
+		defer close(blocked)
+		_, _, errno = unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+	})
+	s := NewSupervisor(l)
+	s.Shutdown = ShutdownFailClosed
+	received := make(chan struct{})
+	s.HandleFunc("getuid", func(req *Request) (*Response, error) {
+		close(received)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	if err := g.Add("c", s); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+	if *results["a"] != 1 || *results["b"] != 2 {
+		t.Errorf("expected results 1 and 2, got %d and %d", *results["a"], *results["b"])
+	}
+
+	<-received
+	if err := g.Remove("c"); err != nil {
+		t.Fatal(err)
+	}
+	<-blocked
+	if errno != unix.EPERM {
+		t.Errorf("expected EPERM after removal, got %v", errno)
+	}
+
+	names := map[string]bool{}
+	for range 3 {
+		names[<-exited] = true
+	}
+	if !names["a"] || !names["b"] || !names["c"] {
+		t.Errorf("unexpected exits %v", names)
+	}
+	if n := g.Names(); len(n) != 0 {
+		t.Errorf("expected no supervisors left, got %v", n)
+	}
+
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add("d", NewSupervisor(nil)); !errors.Is(err, ErrGroupStopped) {
+		t.Errorf("expected ErrGroupStopped, got %v", err)
+	}
+}
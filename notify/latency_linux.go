@@ -0,0 +1,109 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Delay slows down a syscall.
+type Delay struct {
+	Syscall  string        // Name of the syscall.
+	Duration time.Duration // Delay before the syscall continues.
+	Jitter   time.Duration // Random additional delay up to Jitter.
+
+	// Probability of delaying a call, between 0 and 1. Zero delays every
+	// call.
+	Probability float64
+}
+
+// LatencyInjector is a Handler for chaos testing that delays selected
+// syscalls before passing them to Next, for example to simulate slow disk
+// I/O. The supervisor needs enough workers for the delayed calls (see
+// Supervisor.Workers). Delays are cut short when the supervisor shuts down.
+type LatencyInjector struct {
+	Delays []Delay
+
+	// Next handles the calls after the delay. Defaults to letting the
+	// syscall continue (see ContinueUnsafe).
+	Next Handler
+
+	// Seed makes the random delays reproducible. Zero uses a random seed.
+	Seed uint64
+
+	once sync.Once
+	mu   sync.Mutex
+	rnd  *rand.Rand
+}
+
+// Register registers the injector for the syscalls of its delays.
+func (l *LatencyInjector) Register(s *Supervisor) {
+	for _, d := range l.Delays {
+		s.Handle(d.Syscall, l)
+	}
+}
+
+// Handle implements Handler.
+func (l *LatencyInjector) Handle(req *Request) (*Response, error) {
+	if d := l.delay(req.Syscall); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+		}
+	}
+	if l.Next != nil {
+		return l.Next.Handle(req)
+	}
+	return ContinueUnsafe(req.Notification), nil
+}
+
+// delay returns how long to delay the call.
+func (l *LatencyInjector) delay(name string) time.Duration {
+	l.once.Do(func() {
+		seed := l.Seed
+		if seed == 0 {
+			seed = rand.Uint64()
+		}
+		l.rnd = rand.New(rand.NewPCG(seed, seed))
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var total time.Duration
+	for _, d := range l.Delays {
+		if d.Syscall != name {
+			continue
+		}
+		if d.Probability > 0 && l.rnd.Float64() >= d.Probability {
+			continue
+		}
+		total += d.Duration
+		if d.Jitter > 0 {
+			total += time.Duration(l.rnd.Int64N(int64(d.Jitter)))
+		}
+	}
+	return total
+}
@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestLatencyInjectorDelay(t *testing.T) {
+	l := &LatencyInjector{Seed: 1, Delays: []Delay{
+		{Syscall: "read", Duration: 10 * time.Millisecond, Jitter: 5 * time.Millisecond},
+		{Syscall: "write", Duration: time.Second, Probability: 0.5},
+	}}
+
+	for i := 0; i < 100; i++ {
+		if d := l.delay("read"); d < 10*time.Millisecond || d >= 15*time.Millisecond {
+			t.Fatalf("read delay %v out of range", d)
+		}
+	}
+
+	delayed := 0
+	for i := 0; i < 1000; i++ {
+		if l.delay("write") > 0 {
+			delayed++
+		}
+	}
+	if delayed < 400 || delayed > 600 {
+		t.Errorf("expected about 500 delayed writes, got %d", delayed)
+	}
+
+	if d := l.delay("open"); d != 0 {
+		t.Errorf("unexpected delay %v for open", d)
+	}
+}
+
+func TestLatencyInjector(t *testing.T) {
+	var elapsed time.Duration
+	done := make(chan struct{})
+	lst := startTarget(t, []string{"getppid"}, func() {
+		defer close(done)
+		start := time.Now()
+		unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		elapsed = time.Since(start)
+	})
+
+	s := NewSupervisor(lst)
+	(&LatencyInjector{Delays: []Delay{{Syscall: "getppid", Duration: 50 * time.Millisecond}}}).Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	<-done
+
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("expected getppid to take at least 50ms, took %v", elapsed)
+	}
+}
@@ -0,0 +1,203 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// LegacyEmulator is a Handler that emulates syscalls which were removed from
+// the kernel or that a policy denies, so that old binaries keep working in a
+// strict sandbox. The emulated syscalls are:
+//
+//   - time: returns the current time (used by old binaries on x86_64 instead
+//     of the vDSO).
+//   - ustat: returns the free blocks and inodes of the filesystem of a device,
+//     computed with statfs. It is deprecated and missing on newer
+//     architectures. Only 64-bit targets are supported.
+//   - uselib: always fails with ENOSYS like on kernels without CONFIG_USELIB.
+type LegacyEmulator struct {
+	// Syscalls limits the emulation to the given syscalls. Defaults to
+	// LegacySyscalls.
+	Syscalls []string
+}
+
+// LegacySyscalls are the syscalls emulated by LegacyEmulator.
+var LegacySyscalls = []string{"time", "ustat", "uselib"}
+
+// Register registers the emulator for its syscalls.
+func (e *LegacyEmulator) Register(s *Supervisor) {
+	names := e.Syscalls
+	if len(names) == 0 {
+		names = LegacySyscalls
+	}
+	for _, name := range names {
+		s.Handle(name, e)
+	}
+}
+
+// Handle implements Handler.
+func (e *LegacyEmulator) Handle(req *Request) (*Response, error) {
+	switch req.Syscall {
+	case "time":
+		return emulateTime(req)
+	case "ustat":
+		return emulateUstat(req)
+	case "uselib":
+		return ReturnErrno(req.Notification, int(unix.ENOSYS)), nil
+	default:
+		return nil, fmt.Errorf("legacy emulator cannot handle syscall %q", req.Syscall)
+	}
+}
+
+// emulateTime implements time(time_t *tloc).
+func emulateTime(req *Request) (*Response, error) {
+	now := time.Now().Unix()
+	if tloc := req.Data.Args[0]; tloc != 0 {
+		mem, err := req.Memory()
+		if err != nil {
+			return nil, err
+		}
+		// time_t is 32 bits wide on 32-bit architectures.
+		var buf []byte
+		if is64Bit(req.Data.Arch) {
+			buf = binary.NativeEndian.AppendUint64(nil, uint64(now))
+		} else {
+			buf = binary.NativeEndian.AppendUint32(nil, uint32(now))
+		}
+		if _, err = mem.WriteAt(buf, int64(tloc)); err != nil {
+			return ReturnErrno(req.Notification, int(unix.EFAULT)), nil
+		}
+	}
+	return ReturnValue(req.Notification, now), nil
+}
+
+// ustat is struct ustat of 64-bit architectures.
+type ustat struct {
+	Tfree  int32
+	_      int32
+	Tinode uint64
+	Fname  [6]byte
+	Fpack  [6]byte
+	_      [4]byte
+}
+
+// emulateUstat implements ustat(dev_t dev, struct ustat *ubuf).
+func emulateUstat(req *Request) (*Response, error) {
+	if !is64Bit(req.Data.Arch) {
+		return ReturnErrno(req.Notification, int(unix.ENOSYS)), nil
+	}
+	target, err := req.Target()
+	if err != nil {
+		return nil, err
+	}
+
+	dev := req.Data.Args[0]
+	mountpoint, err := target.mountPoint(unix.Major(dev), unix.Minor(dev))
+	if err != nil {
+		return nil, err
+	}
+	if mountpoint == "" {
+		return ReturnErrno(req.Notification, int(unix.EINVAL)), nil
+	}
+
+	// Resolve the mount point in the target's root directory.
+	fd, err := unix.Openat(int(target.proc.Fd()), "root"+mountpoint, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return ReturnErrno(req.Notification, int(unix.EINVAL)), nil
+	}
+	var st unix.Statfs_t
+	err = unix.Fstatfs(fd, &st)
+	unix.Close(fd)
+	if err != nil {
+		return ReturnErrno(req.Notification, int(unix.EINVAL)), nil
+	}
+
+	u := ustat{Tfree: int32(st.Bfree), Tinode: st.Ffree}
+	mem, err := req.Memory()
+	if err != nil {
+		return nil, err
+	}
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&u)), unsafe.Sizeof(u))
+	if _, err = mem.WriteAt(buf, int64(req.Data.Args[1])); err != nil {
+		return ReturnErrno(req.Notification, int(unix.EFAULT)), nil
+	}
+	return ReturnValue(req.Notification, 0), nil
+}
+
+// mountPoint returns the mount point of the device in the mount namespace
+// of the target or an empty string if it is not mounted.
+func (t *Target) mountPoint(major, minor uint32) (string, error) {
+	fd, err := unix.Openat(int(t.proc.Fd()), "mountinfo", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to open mountinfo of pid %d: %w", t.Pid, err)
+	}
+	f := os.NewFile(uintptr(fd), "mountinfo")
+	defer f.Close()
+
+	want := strconv.FormatUint(uint64(major), 10) + ":" + strconv.FormatUint(uint64(minor), 10)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(s.Text())
+		if len(fields) > 4 && fields[2] == want {
+			return unescapeMountPath(fields[4]), t.Valid()
+		}
+	}
+	if err = s.Err(); err != nil {
+		return "", err
+	}
+	return "", t.Valid()
+}
+
+// unescapeMountPath decodes the octal escapes used in mountinfo.
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// is64Bit returns true for 64-bit audit architectures.
+func is64Bit(auditArch uint32) bool {
+	const auditArch64Bit = 0x80000000 // __AUDIT_ARCH_64BIT
+	return auditArch&auditArch64Bit != 0
+}
@@ -0,0 +1,113 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// runEmulation runs fn on a target whose getppid is handled by h.
+func runEmulation(t *testing.T, h HandlerFunc, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid"}, func() {
+		defer close(done)
+		fn()
+	})
+
+	s := NewSupervisor(l)
+	s.Handle("getppid", h)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	<-done
+}
+
+func TestEmulateTime(t *testing.T) {
+	var ret uintptr
+	var tloc int64
+	runEmulation(t, emulateTime, func() {
+		ret, _, _ = unix.Syscall(unix.SYS_GETPPID, uintptr(unsafe.Pointer(&tloc)), 0, 0)
+	})
+
+	now := time.Now().Unix()
+	if int64(ret) < now-5 || int64(ret) > now {
+		t.Errorf("unexpected time %d, now is %d", ret, now)
+	}
+	if tloc != int64(ret) {
+		t.Errorf("expected tloc to be %d, got %d", ret, tloc)
+	}
+}
+
+func TestEmulateTime32(t *testing.T) {
+	// Pretend that the notification comes from an i386 target.
+	h := func(req *Request) (*Response, error) {
+		req.Data.Arch = uint32(arch.I386.ID)
+		return emulateTime(req)
+	}
+
+	var ret uintptr
+	tloc := [2]uint32{0, 0xdeadbeef}
+	runEmulation(t, h, func() {
+		ret, _, _ = unix.Syscall(unix.SYS_GETPPID, uintptr(unsafe.Pointer(&tloc)), 0, 0)
+	})
+
+	if tloc[0] != uint32(ret) {
+		t.Errorf("expected tloc to be %d, got %d", uint32(ret), tloc[0])
+	}
+	if tloc[1] != 0xdeadbeef {
+		t.Errorf("expected memory after tloc to be unchanged, got %#x", tloc[1])
+	}
+}
+
+func TestEmulateUstat(t *testing.T) {
+	var st unix.Stat_t
+	if err := unix.Stat("/proc", &st); err != nil {
+		t.Fatal(err)
+	}
+
+	var errno unix.Errno
+	u := ustat{Tinode: ^uint64(0)}
+	runEmulation(t, emulateUstat, func() {
+		_, _, errno = unix.Syscall(unix.SYS_GETPPID, uintptr(st.Dev), uintptr(unsafe.Pointer(&u)), 0)
+	})
+
+	if errno != 0 {
+		t.Fatalf("ustat failed: %v", errno)
+	}
+	// procfs reports no free inodes.
+	if u.Tinode != 0 {
+		t.Errorf("unexpected free inodes %d", u.Tinode)
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	if got := unescapeMountPath(`/mnt/with\040space`); got != "/mnt/with space" {
+		t.Errorf("unexpected path %q", got)
+	}
+}
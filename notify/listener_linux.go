@@ -0,0 +1,254 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Listener receives notifications from a seccomp user-space notification
+// file descriptor.
+type Listener struct {
+	file   *os.File
+	closed atomic.Bool
+}
+
+// NewListener returns a Listener for the file returned by
+// seccomp.LoadFilterListener. The Listener takes ownership of the file and
+// switches it to non-blocking mode so that it is serviced by the runtime
+// poller and Receive can be interrupted by Close.
+func NewListener(file *os.File) (*Listener, error) {
+	defer file.Close()
+
+	fd, err := unix.FcntlInt(file.Fd(), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to duplicate seccomp listener: %w", err)
+	}
+	if err = unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to set seccomp listener to non-blocking: %w", err)
+	}
+	return &Listener{file: os.NewFile(uintptr(fd), file.Name())}, nil
+}
+
+// File returns the underlying file.
+func (l *Listener) File() *os.File {
+	return l.file
+}
+
+// Close closes the listener. Pending syscalls of the target fail with ENOSYS.
+// A blocked Receive returns an error that wraps os.ErrClosed.
+func (l *Listener) Close() error {
+	l.closed.Store(true)
+	return l.file.Close()
+}
+
+// Receive blocks until a notification is available. It returns io.EOF when
+// all processes that use the filter have exited.
+func (l *Listener) Receive() (*Notification, error) {
+	conn, err := l.file.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var n Notification
+	var rerr error
+	err = conn.Read(func(fd uintptr) bool {
+		rerr = receive(fd, &n)
+		return rerr != errNotPending
+	})
+	if err != nil {
+		return nil, l.receiveError(err)
+	}
+	if rerr != nil {
+		return nil, l.receiveError(rerr)
+	}
+	return &n, nil
+}
+
+// TryReceive returns the next notification if one is pending and nil
+// otherwise. It never blocks. It returns io.EOF when all processes that use
+// the filter have exited.
+func (l *Listener) TryReceive() (*Notification, error) {
+	conn, err := l.file.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var n Notification
+	var rerr error
+	err = conn.Control(func(fd uintptr) {
+		rerr = receive(fd, &n)
+	})
+	switch {
+	case err != nil:
+		return nil, l.receiveError(err)
+	case rerr == errNotPending:
+		return nil, nil
+	case rerr != nil:
+		return nil, l.receiveError(rerr)
+	}
+	return &n, nil
+}
+
+func (l *Listener) receiveError(err error) error {
+	switch {
+	case err == io.EOF:
+		return err
+	case l.closed.Load():
+		// The runtime poller's error is not os.ErrClosed.
+		return fmt.Errorf("failed to receive seccomp notification: %w", os.ErrClosed)
+	default:
+		return fmt.Errorf("failed to receive seccomp notification: %w", err)
+	}
+}
+
+// errNotPending is returned by receive when no notification is pending.
+var errNotPending = errors.New("no notification pending")
+
+// receive reads a notification into n if one is pending. It returns io.EOF
+// when all processes that use the filter have exited.
+func receive(fd uintptr, n *Notification) error {
+	for {
+		// The RECV ioctl blocks regardless of O_NONBLOCK so only call it
+		// when a notification is pending.
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		if _, err := unix.Poll(fds, 0); err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+			return err
+		}
+		switch {
+		case fds[0].Revents&unix.POLLIN != 0:
+		case fds[0].Revents&unix.POLLHUP != 0:
+			return io.EOF
+		default:
+			return errNotPending
+		}
+
+		// The kernel requires the struct to be zeroed.
+		*n = Notification{}
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, unix.SECCOMP_IOCTL_NOTIF_RECV, uintptr(unsafe.Pointer(n)))
+		switch errno {
+		case 0:
+			return nil
+		case syscall.EINTR, syscall.ENOENT:
+			// ENOENT means the target was killed before the notification
+			// was received.
+			continue
+		default:
+			return errno
+		}
+	}
+}
+
+// Respond sends the response for a notification. ErrNotificationGone is
+// returned if the notification is no longer valid.
+func (l *Listener) Respond(resp *Response) error {
+	err := l.ioctl(unix.SECCOMP_IOCTL_NOTIF_SEND, unsafe.Pointer(resp))
+	switch {
+	case err == syscall.ENOENT:
+		return ErrNotificationGone
+	case err != nil:
+		return fmt.Errorf("failed to send seccomp notification response: %w", err)
+	}
+	return nil
+}
+
+// Valid returns nil if the notification with the given ID is still pending.
+// ErrNotificationGone is returned if the target died or the syscall was
+// interrupted. Any data read from the target, for example from its memory,
+// must only be trusted if Valid succeeds after the read because the PID may
+// have been reused in the meantime. Respond and AddFD do not need this check
+// because the kernel performs it atomically.
+func (l *Listener) Valid(id uint64) error {
+	err := l.ioctl(unix.SECCOMP_IOCTL_NOTIF_ID_VALID, unsafe.Pointer(&id))
+	switch {
+	case err == syscall.ENOENT:
+		return ErrNotificationGone
+	case err != nil:
+		return fmt.Errorf("failed to check seccomp notification id: %w", err)
+	}
+	return nil
+}
+
+// AddFD installs a file descriptor of the supervisor into the file
+// descriptor table of the target and returns its number in the target.
+// ErrNotificationGone is returned if the notification is no longer valid.
+func (l *Listener) AddFD(req *AddFD) (int, error) {
+	fd, err := l.ioctlRet(unix.SECCOMP_IOCTL_NOTIF_ADDFD, unsafe.Pointer(req))
+	switch {
+	case err == syscall.ENOENT:
+		return -1, ErrNotificationGone
+	case err == syscall.EINVAL && req.Flags&AddFDFlagSend != 0:
+		return -1, fmt.Errorf("failed to add fd to seccomp notification target "+
+			"(AddFDFlagSend requires Linux 5.14): %w", err)
+	case err != nil:
+		return -1, fmt.Errorf("failed to add fd to seccomp notification target: %w", err)
+	}
+	return int(fd), nil
+}
+
+// RespondWithFD installs fd into the target and atomically answers the
+// notification so that the syscall returns the new file descriptor number.
+// This is the way to emulate syscalls such as open or socket. It requires
+// Linux 5.14.
+func (l *Listener) RespondWithFD(n *Notification, fd int, cloexec bool) (int, error) {
+	req := &AddFD{ID: n.ID, Flags: AddFDFlagSend, SrcFD: uint32(fd)}
+	if cloexec {
+		req.NewFDFlags = unix.O_CLOEXEC
+	}
+	return l.AddFD(req)
+}
+
+func (l *Listener) ioctl(req uintptr, arg unsafe.Pointer) error {
+	_, err := l.ioctlRet(req, arg)
+	return err
+}
+
+func (l *Listener) ioctlRet(req uintptr, arg unsafe.Pointer) (uintptr, error) {
+	conn, err := l.file.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var r1 uintptr
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		r1, _, errno = unix.Syscall(unix.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return 0, err
+	}
+	if errno != 0 {
+		return 0, errno
+	}
+	return r1, nil
+}
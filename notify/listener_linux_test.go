@@ -0,0 +1,297 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func init() {
+	// Keep the main thread for the main goroutine. Threads confined by a
+	// test must exit when their goroutine returns, which the runtime never
+	// does for the main thread.
+	runtime.LockOSThread()
+}
+
+// startTarget locks a new goroutine to its thread, installs a thread-local
+// filter that forwards the given syscalls to a listener, and then runs fn on
+// the confined thread. The thread is discarded when fn returns.
+func startTarget(t testing.TB, names []string, fn func()) *Listener {
+	t.Helper()
+
+	features, err := seccomp.KernelSupport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !features.HasAction(seccomp.ActionUserNotify) {
+		t.Skip("user notifications not supported by kernel")
+	}
+
+	listeners := make(chan *os.File)
+	errs := make(chan error, 1)
+	go func() {
+		// Never unlock so that the confined thread exits with the goroutine.
+		runtime.LockOSThread()
+
+		f, err := seccomp.LoadFilterListener(seccomp.Filter{
+			NoNewPrivs: true,
+			Policy: seccomp.Policy{
+				DefaultAction: seccomp.ActionAllow,
+				Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionUserNotify, Names: names}},
+			},
+		})
+		if err != nil {
+			errs <- err
+			return
+		}
+		listeners <- f
+		fn()
+	}()
+
+	select {
+	case err := <-errs:
+		t.Fatal(err)
+		return nil
+	case f := <-listeners:
+		l, err := NewListener(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { l.Close() })
+		return l
+	}
+}
+
+func TestListener(t *testing.T) {
+	results := make(chan uintptr, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		ppid, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		results <- ppid
+	})
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Data.NR != unix.SYS_GETPPID {
+		t.Fatalf("expected getppid notification, got nr=%d", n.Data.NR)
+	}
+
+	if err = l.Valid(n.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Respond(ReturnValue(n, 4242)); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Valid(n.ID); err != ErrNotificationGone {
+		t.Errorf("expected ErrNotificationGone after responding, got %v", err)
+	}
+	if ppid := <-results; ppid != 4242 {
+		t.Errorf("expected getppid to return 4242, got %d", ppid)
+	}
+}
+
+func TestListenerContinueUnsafe(t *testing.T) {
+	results := make(chan uintptr, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		ppid, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		results <- ppid
+	})
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Respond(ContinueUnsafe(n)); err != nil {
+		t.Fatal(err)
+	}
+	if ppid := <-results; ppid != uintptr(unix.Getppid()) {
+		t.Errorf("expected getppid to return %d, got %d", unix.Getppid(), ppid)
+	}
+}
+
+func TestListenerRespondWithFD(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	l := startTarget(t, []string{"getppid"}, func() {
+		// The target thread shares the fd table with the test.
+		fd, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		unix.Write(int(fd), []byte("ok"))
+		unix.Close(int(fd))
+	})
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := l.RespondWithFD(n, int(w.Fd()), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fd == int(w.Fd()) {
+		t.Fatal("expected a new file descriptor")
+	}
+
+	w.Close()
+	buf := make([]byte, 2)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ok" {
+		t.Errorf("unexpected data %q", buf)
+	}
+}
+
+func TestOpenTarget(t *testing.T) {
+	results := make(chan int, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		results <- unix.Gettid()
+		unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+	})
+	tid := <-results
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := l.OpenTarget(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	if target.Pid != tid {
+		t.Errorf("expected pid %d, got %d", tid, target.Pid)
+	}
+	if target.Pidfd() < 0 {
+		t.Errorf("invalid pidfd %d", target.Pidfd())
+	}
+	if err = l.Respond(ReturnValue(n, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err = target.Valid(); err != ErrNotificationGone {
+		t.Errorf("expected ErrNotificationGone, got %v", err)
+	}
+}
+
+func TestOpenTargetNoPidfdThread(t *testing.T) {
+	// Simulate a kernel without PIDFD_THREAD (Linux < 6.9) by passing a
+	// flag that pidfd_open rejects with EINVAL.
+	defer func(flag int) { pidfdThread = flag }(pidfdThread)
+	pidfdThread = 1 << 30
+
+	f, err := os.CreateTemp(t.TempDir(), "getfd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fd := f.Fd()
+
+	results := make(chan int, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		// The confined thread is never the thread group leader because
+		// the main thread is locked by init.
+		results <- unix.Gettid()
+		unix.Syscall(unix.SYS_GETPPID, fd, 0, 0)
+	})
+	if tid := <-results; tid == unix.Getpid() {
+		t.Fatal("expected the target not to be the thread group leader")
+	}
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Respond(ReturnValue(n, 0))
+
+	target, err := l.OpenTarget(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	dup, err := target.GetFd(int(n.Data.Args[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dup.Close()
+
+	mem, err := target.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem.Close()
+}
+
+func TestTargetGetFd(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "getfd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fd := f.Fd()
+
+	l := startTarget(t, []string{"getppid"}, func() {
+		unix.Syscall(unix.SYS_GETPPID, fd, 0, 0)
+	})
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Respond(ReturnValue(n, 0))
+
+	target, err := l.OpenTarget(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	dup, err := target.GetFd(int(n.Data.Args[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dup.Close()
+
+	want, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := dup.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(want, got) {
+		t.Error("expected duplicate to refer to the same file")
+	}
+}
@@ -0,0 +1,143 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Memory reads the memory of a notification target. Every read is followed
+// by a check that the notification is still pending so that the data is
+// guaranteed to come from the thread that triggered it.
+//
+// The target (or any thread sharing its memory) can still modify the memory
+// after it was read. Decisions must therefore never rely on the data staying
+// the same once the syscall is allowed to continue.
+type Memory struct {
+	target *Target
+	file   *os.File
+
+	noVMReadv   bool        // process_vm_readv is unavailable for the target.
+	onReadError func(error) // Reports failed reads to the Supervisor's Metrics.
+}
+
+// vmReadvMinSize is the minimum read size for which process_vm_readv is used
+// instead of reading /proc/<pid>/mem. It avoids the overhead of the page
+// table walk per read for large buffers such as write or sendmsg payloads.
+const vmReadvMinSize = 4096
+
+var _ io.ReaderAt = (*Memory)(nil)
+
+// OpenMemory opens /proc/<pid>/mem of the target. It is opened for writing
+// if the supervisor is allowed to, otherwise WriteAt fails.
+func (t *Target) OpenMemory() (*Memory, error) {
+	fd, err := unix.Openat(int(t.proc.Fd()), "mem", unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err == unix.EACCES || err == unix.EPERM {
+		fd, err = unix.Openat(int(t.proc.Fd()), "mem", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	}
+	if err != nil {
+		if err == unix.ESRCH || err == unix.ENOENT {
+			return nil, ErrNotificationGone
+		}
+		return nil, fmt.Errorf("failed to open memory of pid %d: %w", t.Pid, err)
+	}
+	m := &Memory{target: t, file: os.NewFile(uintptr(fd), fmt.Sprintf("/proc/%d/mem", t.Pid))}
+
+	// The /proc directory may already belong to a new process if the target
+	// died before it was opened.
+	if err = t.Valid(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReadAt reads len(p) bytes from address addr of the target. It returns
+// ErrNotificationGone if the notification is no longer pending after the
+// read, in which case the contents of p must be discarded.
+func (m *Memory) ReadAt(p []byte, addr int64) (int, error) {
+	n, err := m.read(p, addr)
+	if verr := m.target.Valid(); verr != nil {
+		err, n = verr, 0
+	} else if err != nil {
+		err = fmt.Errorf("failed to read memory of pid %d at 0x%x: %w", m.target.Pid, addr, err)
+	}
+	if err != nil && m.onReadError != nil {
+		m.onReadError(err)
+	}
+	return n, err
+}
+
+func (m *Memory) read(p []byte, addr int64) (int, error) {
+	var n int
+	if len(p) >= vmReadvMinSize && !m.noVMReadv {
+		var err error
+		n, err = m.readVM(p, addr)
+		switch err {
+		case nil:
+			if n == len(p) {
+				return n, nil
+			}
+		case unix.ENOSYS, unix.EPERM:
+			m.noVMReadv = true
+		}
+	}
+
+	// Fall back to /proc/<pid>/mem for the remainder. A partial
+	// process_vm_readv read is retried because it stops at page boundaries.
+	rn, err := m.file.ReadAt(p[n:], addr+int64(n))
+	return n + rn, err
+}
+
+func (m *Memory) readVM(p []byte, addr int64) (int, error) {
+	local := []unix.Iovec{{Base: &p[0]}}
+	local[0].SetLen(len(p))
+	remote := []unix.RemoteIovec{{Base: uintptr(addr), Len: len(p)}}
+	n, err := unix.ProcessVMReadv(m.target.Pid, local, remote, 0)
+	if n < 0 {
+		n = 0
+	}
+	return n, err
+}
+
+// WriteAt writes p to address addr of the target. It returns
+// ErrNotificationGone without writing if the notification is no longer
+// pending. This is used to return data from emulated syscalls; the target
+// must not be answered before the write completed.
+func (m *Memory) WriteAt(p []byte, addr int64) (int, error) {
+	if err := m.target.Valid(); err != nil {
+		return 0, err
+	}
+	n, err := m.file.WriteAt(p, addr)
+	if err != nil {
+		return n, fmt.Errorf("failed to write memory of pid %d at 0x%x: %w", m.target.Pid, addr, err)
+	}
+	return n, nil
+}
+
+// Close closes the memory file.
+func (m *Memory) Close() error {
+	return m.file.Close()
+}
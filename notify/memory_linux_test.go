@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// startPointerTarget starts a target that passes a pointer to data and its
+// length as the first two arguments of getppid.
+func startPointerTarget(t testing.TB, data []byte) *Listener {
+	t.Helper()
+	return startTarget(t, []string{"getppid"}, func() {
+		unix.Syscall(unix.SYS_GETPPID, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), 0)
+		runtime.KeepAlive(data)
+	})
+}
+
+func TestMemoryReadAt(t *testing.T) {
+	data := []byte("hello target")
+	l := startPointerTarget(t, data)
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Respond(ReturnValue(n, 0))
+
+	target, err := l.OpenTarget(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	mem, err := target.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	buf := make([]byte, n.Data.Args[1])
+	if _, err = mem.ReadAt(buf, int64(n.Data.Args[0])); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != string(data) {
+		t.Errorf("expected %q, got %q", data, buf)
+	}
+
+	if _, err = mem.ReadAt(buf, 0); err == nil {
+		t.Error("expected error when reading unmapped memory")
+	}
+}
+
+func TestMemoryReadAtLarge(t *testing.T) {
+	data := make([]byte, 3*vmReadvMinSize+17)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	l := startPointerTarget(t, data)
+
+	n, err := l.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Respond(ReturnValue(n, 0))
+
+	target, err := l.OpenTarget(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	mem, err := target.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	buf := make([]byte, n.Data.Args[1])
+	if _, err = mem.ReadAt(buf, int64(n.Data.Args[0])); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, data) {
+		t.Error("memory read with process_vm_readv does not match")
+	}
+
+	// Force the /proc/<pid>/mem fallback.
+	mem.noVMReadv = true
+	buf = make([]byte, n.Data.Args[1])
+	if _, err = mem.ReadAt(buf, int64(n.Data.Args[0])); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, data) {
+		t.Error("memory read with /proc/<pid>/mem does not match")
+	}
+}
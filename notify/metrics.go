@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notify
+
+import "time"
+
+// Decision is the way a notification was answered.
+type Decision uint8
+
+// Decisions.
+const (
+	DecisionValue    Decision = iota // The syscall returned a value.
+	DecisionErrno                    // The syscall failed with an errno.
+	DecisionContinue                 // The syscall continued in the target.
+	DecisionHandled                  // The handler answered the notification itself.
+	DecisionGone                     // The target went away before it was answered.
+)
+
+var decisionNames = map[Decision]string{
+	DecisionValue:    "value",
+	DecisionErrno:    "errno",
+	DecisionContinue: "continue",
+	DecisionHandled:  "handled",
+	DecisionGone:     "gone",
+}
+
+func (d Decision) String() string {
+	if name, found := decisionNames[d]; found {
+		return name
+	}
+	return "unknown"
+}
+
+// decisionOf classifies a response. A nil response means that the handler
+// answered the notification itself.
+func decisionOf(resp *Response) Decision {
+	switch {
+	case resp == nil:
+		return DecisionHandled
+	case resp.Flags&FlagContinue != 0:
+		return DecisionContinue
+	case resp.Error != 0:
+		return DecisionErrno
+	default:
+		return DecisionValue
+	}
+}
+
+// Metrics receives events from a Supervisor, for example to export them to
+// Prometheus or StatsD. Implementations must be safe for concurrent use and
+// should not block. The syscall name is empty for unknown syscalls.
+type Metrics interface {
+	// NotificationReceived is called for every notification.
+	NotificationReceived(syscall string)
+	// Decided is called with the way a notification was answered.
+	Decided(syscall string, decision Decision)
+	// HandlerLatency is called with the time spent in the handler.
+	HandlerLatency(syscall string, d time.Duration)
+	// HandlerFailed is called when a handler returns an error.
+	HandlerFailed(syscall string, err error)
+	// MemoryReadFailed is called when reading the target's memory fails.
+	MemoryReadFailed(syscall string, err error)
+}
@@ -0,0 +1,247 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notifytest
+
+import (
+	"context"
+	"math/rand/v2"
+	"os/exec"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// shimSyscalls are called by the shim of seccomp.CommandListener after it
+// installed the filter, before the listener is supervised.
+var shimSyscalls = []string{"close", "sendmsg"}
+
+// Chaos defines the faults and delays that are injected into the syscalls of
+// the program under test.
+type Chaos struct {
+	Faults []notify.Fault
+	Delays []notify.Delay
+
+	// Seed makes the random faults and delays reproducible. Zero uses a
+	// random seed that is logged by the test.
+	Seed uint64
+}
+
+// syscalls returns the names of the syscalls with faults or delays.
+func (c Chaos) syscalls() []string {
+	var names []string
+	for _, f := range c.Faults {
+		names = append(names, f.Syscall)
+	}
+	for _, d := range c.Delays {
+		names = append(names, d.Syscall)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Call is a syscall of the program under test that was sent to the
+// supervisor.
+type Call struct {
+	Syscall string
+	Pid     int           // Thread that made the call.
+	Errno   syscall.Errno // Injected error, 0 if the call continued.
+	Held    time.Duration // Time until the call was answered, including delays.
+}
+
+// Experiment is a program running under a Chaos supervisor. Its methods fail
+// the test on errors.
+type Experiment struct {
+	// Cmd runs the program. It can be configured before Start, except for
+	// the environment, which must be appended to.
+	Cmd *exec.Cmd
+
+	tb       testing.TB
+	chaos    Chaos
+	receiver *seccomp.ListenerReceiver
+	cancel   context.CancelFunc
+	done     chan error // Result of the supervisor, nil before Start.
+	waited   bool
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// New returns an experiment that runs the named program with the faults and
+// delays injected. It skips the test if the kernel does not support user
+// notifications.
+func New(tb testing.TB, c Chaos, name string, arg ...string) *Experiment {
+	tb.Helper()
+	features, err := seccomp.KernelSupport()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if !features.HasAction(seccomp.ActionUserNotify) {
+		tb.Skip("user notifications not supported by kernel")
+	}
+
+	names := c.syscalls()
+	if len(names) == 0 {
+		tb.Fatal("notifytest: no faults or delays")
+	}
+	for _, name := range shimSyscalls {
+		if slices.Contains(names, name) {
+			tb.Fatalf("notifytest: faults and delays cannot be injected into %s", name)
+		}
+	}
+	if c.Seed == 0 {
+		c.Seed = rand.Uint64()
+		tb.Logf("notifytest: injecting with seed %d", c.Seed)
+	}
+
+	filter := seccomp.Filter{
+		NoNewPrivs: true,
+		Policy: seccomp.Policy{
+			DefaultAction: seccomp.ActionAllow,
+			Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionUserNotify, Names: names}},
+		},
+	}
+	e := &Experiment{tb: tb, chaos: c}
+	e.Cmd, e.receiver = seccomp.CommandListener(filter, name, arg...)
+	tb.Cleanup(e.stop)
+	return e
+}
+
+// Start starts the program and the supervisor.
+func (e *Experiment) Start() {
+	e.tb.Helper()
+	if err := e.Cmd.Start(); err != nil {
+		e.tb.Fatal(err)
+	}
+	file, err := e.receiver.Receive()
+	if err != nil {
+		e.Cmd.Process.Kill()
+		e.Cmd.Wait()
+		e.tb.Fatal(err)
+	}
+	listener, err := notify.NewListener(file)
+	if err != nil {
+		e.Cmd.Process.Kill()
+		e.Cmd.Wait()
+		e.tb.Fatal(err)
+	}
+
+	injector := &notify.LatencyInjector{
+		Delays: e.chaos.Delays,
+		Seed:   e.chaos.Seed,
+		Next:   &notify.FaultInjector{Faults: e.chaos.Faults, Seed: e.chaos.Seed},
+	}
+	supervisor := notify.NewSupervisor(listener)
+	for _, name := range e.chaos.syscalls() {
+		supervisor.HandleFunc(name, func(req *notify.Request) (*notify.Response, error) {
+			start := time.Now()
+			resp, err := injector.Handle(req)
+			if err == nil {
+				e.record(Call{
+					Syscall: req.Syscall,
+					Pid:     int(req.Pid),
+					Errno:   syscall.Errno(-resp.Error),
+					Held:    time.Since(start),
+				})
+			}
+			return resp, err
+		})
+	}
+
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	e.done = make(chan error, 1)
+	go func() {
+		e.done <- supervisor.Run(ctx)
+	}()
+}
+
+// Wait waits for the program to exit and returns its error. The supervisor
+// stops answering the calls of children that outlive the program.
+func (e *Experiment) Wait() error {
+	e.tb.Helper()
+	err := e.Cmd.Wait()
+	e.waited = true
+	e.cancel()
+	if serr := <-e.done; serr != nil {
+		e.tb.Errorf("notifytest: supervisor failed: %v", serr)
+	}
+	return err
+}
+
+// Run starts the program and waits for it to exit.
+func (e *Experiment) Run() error {
+	e.tb.Helper()
+	e.Start()
+	return e.Wait()
+}
+
+// stop kills the program if the test did not wait for it.
+func (e *Experiment) stop() {
+	e.receiver.Close()
+	if e.done == nil || e.waited {
+		return
+	}
+	e.Cmd.Process.Kill()
+	e.Wait()
+}
+
+func (e *Experiment) record(c Call) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, c)
+}
+
+// Calls returns the calls of the syscalls with faults or delays in the order
+// in which they were answered.
+func (e *Experiment) Calls() []Call {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.calls)
+}
+
+// Failed returns the number of calls of the syscall that were failed with
+// an injected errno.
+func (e *Experiment) Failed(name string) int {
+	var n int
+	for _, c := range e.Calls() {
+		if c.Syscall == name && c.Errno != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// ExpectFailed fails the test if no call of the syscall was failed with the
+// errno, for example because the program did not reach the code under test.
+func (e *Experiment) ExpectFailed(name string, errno syscall.Errno) {
+	e.tb.Helper()
+	for _, c := range e.Calls() {
+		if c.Syscall == name && c.Errno == errno {
+			return
+		}
+	}
+	e.tb.Errorf("notifytest: no %s call was failed with %v", name, errno)
+}
@@ -0,0 +1,128 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notifytest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// targetEnv makes the test binary run as the program under test.
+const targetEnv = "_NOTIFYTEST_TARGET"
+
+func TestExperiment(t *testing.T) {
+	if os.Getenv(targetEnv) != "" {
+		// Print the errnos of getppid, which the Go runtime does not call.
+		var errnos []string
+		for i := 0; i < 4; i++ {
+			_, _, errno := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+			errnos = append(errnos, fmt.Sprint(int(errno)))
+		}
+		fmt.Println("errnos:", strings.Join(errnos, " "))
+		return
+	}
+
+	e := New(t, Chaos{
+		Faults: []notify.Fault{{Syscall: "getppid", Errno: syscall.EIO, Every: 2}},
+		Delays: []notify.Delay{{Syscall: "getppid", Duration: 10 * time.Millisecond}},
+	}, os.Args[0], "-test.run=^TestExperiment$", "-test.v")
+	e.Cmd.Env = append(e.Cmd.Env, targetEnv+"=1")
+	var out strings.Builder
+	e.Cmd.Stdout = &out
+	if err := e.Run(); err != nil {
+		t.Fatalf("target failed: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), fmt.Sprintf("errnos: 0 %d 0 %d\n", syscall.EIO, syscall.EIO)) {
+		t.Errorf("unexpected target output:\n%s", out.String())
+	}
+	e.ExpectFailed("getppid", syscall.EIO)
+	if n := e.Failed("getppid"); n != 2 {
+		t.Errorf("expected 2 failed calls, got %d", n)
+	}
+	calls := e.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %v", calls)
+	}
+	for _, c := range calls {
+		if c.Held < 10*time.Millisecond {
+			t.Errorf("call was not delayed: %+v", c)
+		}
+	}
+}
+
+func TestExperimentStop(t *testing.T) {
+	e := New(t, Chaos{Faults: []notify.Fault{{Syscall: "getppid", Errno: syscall.EIO, Every: 1}}}, "sleep", "60")
+	e.Start()
+	// The cleanup kills the program.
+}
+
+func TestNewRejectsShimSyscalls(t *testing.T) {
+	r := &recorder{TB: t}
+	func() {
+		defer func() { recover() }()
+		New(r, Chaos{Faults: []notify.Fault{{Syscall: "close", Errno: syscall.EIO}}}, "true")
+	}()
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "close") {
+		t.Errorf("expected faults on close to be rejected, got %q", r.errors)
+	}
+}
+
+func TestExpectFailed(t *testing.T) {
+	r := &recorder{TB: t}
+	e := New(r, Chaos{Faults: []notify.Fault{{Syscall: "getppid", Errno: syscall.EIO, Every: 1}}}, "true")
+	if err := e.Run(); err != nil {
+		t.Fatal(err)
+	}
+	e.ExpectFailed("getppid", syscall.EIO)
+	if len(r.errors) != 1 {
+		t.Errorf("expected one error, got %q", r.errors)
+	}
+}
+
+// recorder is a testing.TB that records failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	panic(r)
+}
+
+func (r *recorder) Fatal(args ...any) {
+	r.Fatalf("%s", fmt.Sprint(args...))
+}
@@ -0,0 +1,40 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package notifytest runs chaos experiments in Go tests. The program under
+// test is started with a filter that sends the selected syscalls to a
+// notify.Supervisor, which fails them with an errno or delays them with a
+// notify.FaultInjector and notify.LatencyInjector, and records every call
+// so that the test can check how the program handled the failures:
+//
+//	func TestDiskFull(t *testing.T) {
+//		e := notifytest.New(t, notifytest.Chaos{
+//			Faults: []notify.Fault{{Syscall: "write", Errno: syscall.ENOSPC, Every: 10}},
+//		}, "./server", "-data", t.TempDir())
+//		e.Cmd.Stderr = os.Stderr
+//		if err := e.Run(); err == nil {
+//			t.Error("expected the server to report the full disk")
+//		}
+//		e.ExpectFailed("write", syscall.ENOSPC)
+//	}
+//
+// ConfineThread confines a thread of the test itself instead, for tests of
+// handlers and supervisor hooks that need a listener but no program.
+//
+// The program runs with no_new_privs set. Tests are skipped if the kernel
+// does not support user notifications, which requires Linux 5.5.
+package notifytest
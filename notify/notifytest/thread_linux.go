@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notifytest
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// ConfineThread locks a new goroutine to its thread, installs a thread-local
+// filter that sends the named syscalls to the returned listener, and then
+// runs fn on the confined thread. The listener is closed when the test ends.
+// It skips the test if the kernel does not support user notifications.
+//
+// The thread is discarded when fn returns, unless it is the main thread,
+// which never exits. Tests must therefore stop the supervisor of the
+// listener once fn is done instead of waiting for the listener to report
+// io.EOF.
+func ConfineThread(tb testing.TB, names []string, fn func()) *notify.Listener {
+	tb.Helper()
+	features, err := seccomp.KernelSupport()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if !features.HasAction(seccomp.ActionUserNotify) {
+		tb.Skip("user notifications not supported by kernel")
+	}
+
+	listeners := make(chan *os.File)
+	errs := make(chan error, 1)
+	go func() {
+		// Never unlock so that the confined thread exits with the goroutine.
+		runtime.LockOSThread()
+
+		f, err := seccomp.LoadFilterListener(seccomp.Filter{
+			NoNewPrivs: true,
+			Policy: seccomp.Policy{
+				DefaultAction: seccomp.ActionAllow,
+				Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionUserNotify, Names: names}},
+			},
+		})
+		if err != nil {
+			errs <- err
+			return
+		}
+		listeners <- f
+		fn()
+	}()
+
+	select {
+	case err := <-errs:
+		tb.Fatal(err)
+		return nil
+	case f := <-listeners:
+		l, err := notify.NewListener(f)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		tb.Cleanup(func() { l.Close() })
+		return l
+	}
+}
@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Record is a syscall seen by an Observer.
+type Record struct {
+	Time    time.Time
+	Pid     int
+	Syscall string // Empty if unknown, see Data.NR.
+	Data    seccomp.SeccompData
+}
+
+// String returns a short summary of the record such as
+// "pid=42 openat(0xffffff9c, 0x7ffd1000, 0x80000, 0x0, 0x0, 0x0)".
+func (r Record) String() string {
+	name := r.Syscall
+	if name == "" {
+		name = fmt.Sprintf("syscall_%d", r.Data.NR)
+	}
+	args := make([]string, len(r.Data.Args))
+	for i, a := range r.Data.Args {
+		args[i] = fmt.Sprintf("0x%x", a)
+	}
+	return fmt.Sprintf("pid=%d %s(%s)", r.Pid, name, strings.Join(args, ", "))
+}
+
+// Observer is a Handler that records every notified syscall and then lets it
+// continue. Used with a filter whose default action is
+// seccomp.ActionUserNotify, it produces an exact usage profile of a running
+// binary that can be turned into a minimal allowlist with Policy.
+type Observer struct {
+	// OnRecord is called for every syscall if set. It must be safe for
+	// concurrent use.
+	OnRecord func(Record)
+
+	mu      sync.Mutex
+	counts  seccomp.SyscallProfile
+	unknown map[int32]uint64
+}
+
+// Register makes the observer the default handler of the supervisor.
+func (o *Observer) Register(s *Supervisor) {
+	s.Default = o
+}
+
+// Handle implements Handler.
+func (o *Observer) Handle(req *Request) (*Response, error) {
+	o.mu.Lock()
+	if o.counts == nil {
+		o.counts = seccomp.SyscallProfile{}
+		o.unknown = map[int32]uint64{}
+	}
+	if req.Syscall != "" {
+		o.counts[req.Syscall]++
+	} else {
+		o.unknown[req.Data.NR]++
+	}
+	o.mu.Unlock()
+
+	if o.OnRecord != nil {
+		o.OnRecord(Record{
+			Time:    time.Now(),
+			Pid:     int(req.Pid),
+			Syscall: req.Syscall,
+			Data:    req.Data,
+		})
+	}
+	return ContinueUnsafe(req.Notification), nil
+}
+
+// Profile returns the number of calls per syscall name.
+func (o *Observer) Profile() seccomp.SyscallProfile {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	profile := make(seccomp.SyscallProfile, len(o.counts))
+	for name, n := range o.counts {
+		profile[name] = n
+	}
+	return profile
+}
+
+// Unknown returns the number of calls per syscall number for syscalls whose
+// name is unknown. They are not part of the Policy.
+func (o *Observer) Unknown() map[int32]uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	unknown := make(map[int32]uint64, len(o.unknown))
+	for nr, n := range o.unknown {
+		unknown[nr] = n
+	}
+	return unknown
+}
+
+// Policy returns a policy that allows exactly the observed syscalls and
+// applies defaultAction to all others. The observed counts are used as the
+// policy's profile so that the most frequent syscalls are checked first.
+func (o *Observer) Policy(defaultAction seccomp.Action) seccomp.Policy {
+	profile := o.Profile()
+	names := make([]string, 0, len(profile))
+	for name := range profile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return seccomp.Policy{
+		DefaultAction: defaultAction,
+		Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionAllow, Names: names}},
+		Profile:       profile,
+	}
+}
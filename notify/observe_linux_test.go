@@ -0,0 +1,80 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func TestObserver(t *testing.T) {
+	var ppid uintptr
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid", "getuid"}, func() {
+		defer close(done)
+		ppid, _, _ = unix.Syscall(unix.SYS_GETPPID, 1, 2, 3)
+		unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+	})
+
+	var mu sync.Mutex
+	var records []Record
+	o := &Observer{OnRecord: func(r Record) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, r)
+	}}
+	s := NewSupervisor(l)
+	s.Workers = 1
+	o.Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	<-done
+
+	if ppid != uintptr(unix.Getppid()) {
+		t.Errorf("expected syscall to continue, got ppid %d", ppid)
+	}
+	if want := (seccomp.SyscallProfile{"getppid": 2, "getuid": 1}); !reflect.DeepEqual(o.Profile(), want) {
+		t.Errorf("expected profile %v, got %v", want, o.Profile())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if s := records[0].String(); !strings.HasSuffix(s, "getppid(0x1, 0x2, 0x3, 0x0, 0x0, 0x0)") {
+		t.Errorf("unexpected record %q", s)
+	}
+
+	policy := o.Policy(seccomp.ActionErrno)
+	if names := policy.Syscalls[0].Names; !reflect.DeepEqual(names, []string{"getppid", "getuid"}) {
+		t.Errorf("unexpected allowlist %v", names)
+	}
+}
@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// SeccompFdName is the name of the listener in ContainerProcessState.Fds.
+const SeccompFdName = "seccompFd"
+
+// maxContainerFds is the maximum number of file descriptors accepted in one
+// message from a container runtime.
+const maxContainerFds = 16
+
+// ContainerState is the state of a container as defined by the OCI runtime
+// specification.
+// https://github.com/opencontainers/runtime-spec/blob/v1.2.0/runtime.md#state
+type ContainerState struct {
+	Version     string            `json:"ociVersion"`
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Pid         int               `json:"pid,omitempty"`
+	Bundle      string            `json:"bundle"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ContainerProcessState is the message sent by OCI runtimes such as runc and
+// crun to the seccomp agent listening on linux.seccomp.listenerPath.
+// https://github.com/opencontainers/runtime-spec/blob/v1.2.0/config-linux.md#containerprocessstate
+type ContainerProcessState struct {
+	Version  string         `json:"ociVersion"`
+	Fds      []string       `json:"fds"`                // Names of the passed file descriptors, in order.
+	Pid      int            `json:"pid"`                // PID of the container process (in the runtime's PID namespace).
+	Metadata string         `json:"metadata,omitempty"` // Value of linux.seccomp.listenerMetadata.
+	State    ContainerState `json:"state"`
+}
+
+// ReceiveContainerListener reads the message of an OCI runtime from conn
+// and returns the listener it contains. The runtime closes the connection
+// after sending the message.
+func ReceiveContainerListener(conn *net.UnixConn) (*Listener, *ContainerProcessState, error) {
+	buf := make([]byte, 64*1024)
+	oob := make([]byte, unix.CmsgSpace(maxContainerFds*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive container process state: %w", err)
+	}
+	fds, err := parseRights(oob[:oobn])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive container process state: %w", err)
+	}
+
+	// Large states with many annotations might not fit into a single read.
+	rest, err := io.ReadAll(conn)
+	if err != nil {
+		closeAll(fds)
+		return nil, nil, fmt.Errorf("failed to receive container process state: %w", err)
+	}
+
+	var state ContainerProcessState
+	dec := json.NewDecoder(io.MultiReader(bytes.NewReader(buf[:n]), bytes.NewReader(rest)))
+	if err = dec.Decode(&state); err != nil {
+		closeAll(fds)
+		return nil, nil, fmt.Errorf("failed to decode container process state: %w", err)
+	}
+	if len(state.Fds) != len(fds) {
+		closeAll(fds)
+		return nil, nil, fmt.Errorf("container process state names %d file descriptors, got %d", len(state.Fds), len(fds))
+	}
+
+	listenerFd := -1
+	for i, name := range state.Fds {
+		if name == SeccompFdName && listenerFd < 0 {
+			listenerFd = fds[i]
+			continue
+		}
+		unix.Close(fds[i])
+	}
+	if listenerFd < 0 {
+		return nil, nil, fmt.Errorf("container process state does not contain %q", SeccompFdName)
+	}
+
+	l, err := NewListener(os.NewFile(uintptr(listenerFd), "seccomp-notify"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, &state, nil
+}
+
+// Agent is a seccomp agent that accepts listeners from OCI runtimes. Its
+// path is used as linux.seccomp.listenerPath in the container configuration.
+type Agent struct {
+	listener *net.UnixListener
+}
+
+// ListenAgent creates the unix socket at path and listens for connections
+// from OCI runtimes.
+func ListenAgent(path string) (*Agent, error) {
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for seccomp agent connections: %w", err)
+	}
+	return &Agent{listener: l}, nil
+}
+
+// Accept waits for the next container and returns its listener and state.
+func (a *Agent) Accept() (*Listener, *ContainerProcessState, error) {
+	conn, err := a.listener.AcceptUnix()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	return ReceiveContainerListener(conn)
+}
+
+// Close stops listening and removes the socket.
+func (a *Agent) Close() error {
+	return a.listener.Close()
+}
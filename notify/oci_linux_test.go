@@ -0,0 +1,113 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestAgent(t *testing.T) {
+	results := make(chan uintptr, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		ppid, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		results <- ppid
+	})
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	agent, err := ListenAgent(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Close()
+
+	state := ContainerProcessState{
+		Version:  "1.2.0",
+		Fds:      []string{"pidFd", SeccompFdName},
+		Pid:      os.Getpid(),
+		Metadata: "tenant-a",
+		State: ContainerState{
+			Version:     "1.2.0",
+			ID:          "container",
+			Status:      "creating",
+			Pid:         os.Getpid(),
+			Bundle:      "/bundle",
+			Annotations: map[string]string{"key": "value"},
+		},
+	}
+
+	// Send the state like runc does: one message carrying all file
+	// descriptors followed by closing the connection.
+	go func() {
+		conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		pidfd, err := unix.PidfdOpen(os.Getpid(), 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer unix.Close(pidfd)
+
+		raw, err := l.File().SyscallConn()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, _ := json.Marshal(state)
+		raw.Control(func(fd uintptr) {
+			_, _, err = conn.WriteMsgUnix(data, unix.UnixRights(pidfd, int(fd)), nil)
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	remote, got, err := agent.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	if !reflect.DeepEqual(*got, state) {
+		t.Errorf("expected state %+v, got %+v", state, *got)
+	}
+
+	n, err := remote.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Respond(ReturnValue(n, 4242)); err != nil {
+		t.Fatal(err)
+	}
+	if ppid := <-results; ppid != 4242 {
+		t.Errorf("expected getppid to return 4242, got %d", ppid)
+	}
+}
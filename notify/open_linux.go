@@ -0,0 +1,268 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// writeFlags are the open flags that require write access.
+const writeFlags = unix.O_WRONLY | unix.O_RDWR | unix.O_CREAT | unix.O_TRUNC | unix.O_APPEND
+
+// modeBits are the bits of the mode of open that are used by the kernel.
+const modeBits = unix.S_ISUID | unix.S_ISGID | unix.S_ISVTX | 0o777
+
+// PathRule grants access to a file or directory tree.
+type PathRule struct {
+	Name  string // Optional identifier of the rule used in logs.
+	Path  string // Absolute path. A directory grants access to everything below it.
+	Write bool   // Allow opening for writing and creating files.
+}
+
+// OpenBroker is a Handler that opens files on behalf of the target, similar
+// to unveil(2) on OpenBSD. It reads the path of open, openat, openat2, and
+// creat, checks it against the rules, opens the file in the supervisor, and
+// injects the file descriptor into the target with Listener.RespondWithFD.
+// The target therefore never resolves the path itself, which makes the
+// check immune to the target changing the path after it was read.
+//
+// Files are opened beneath the directory of the matching rule and symbolic
+// links are resolved by the kernel within it, so links cannot be used to
+// escape a rule. Files are created with the supervisor's umask. Requires
+// Linux 5.14.
+//
+// If the target has another root directory or mount namespace than the
+// supervisor, like a container, the paths of the rules are resolved in the
+// target's root. Relative paths cannot be checked against the rules in that
+// case and are denied.
+type OpenBroker struct {
+	Rules []PathRule
+
+	// Errno is returned for paths that are not covered by any rule. Defaults
+	// to EACCES.
+	Errno syscall.Errno
+}
+
+// OpenSyscalls are the syscalls handled by OpenBroker.
+var OpenSyscalls = []string{"open", "openat", "openat2", "creat"}
+
+// Register registers the broker for all OpenSyscalls.
+func (b *OpenBroker) Register(s *Supervisor) {
+	for _, name := range OpenSyscalls {
+		s.Handle(name, b)
+	}
+}
+
+// Handle implements Handler.
+func (b *OpenBroker) Handle(req *Request) (*Response, error) {
+	mem, err := req.Memory()
+	if err != nil {
+		return nil, err
+	}
+
+	args := req.Data.Args
+	dirfd := unix.AT_FDCWD
+	var pathAddr uint64
+	var how unix.OpenHow
+	// The flags and mode are ints for the other syscalls, so their upper
+	// bits are ignored by the kernel, while openat2 rejects them.
+	switch req.Syscall {
+	case "open":
+		pathAddr, how.Flags, how.Mode = args[0], uint64(uint32(args[1])), args[2]&modeBits
+	case "creat":
+		pathAddr, how.Flags, how.Mode = args[0], unix.O_CREAT|unix.O_WRONLY|unix.O_TRUNC, args[1]&modeBits
+	case "openat":
+		dirfd, pathAddr, how.Flags, how.Mode = int(int32(args[0])), args[1], uint64(uint32(args[2])), args[3]&modeBits
+	case "openat2":
+		h, err := mem.ReadOpenHow(args[2], int(args[3]))
+		if err != nil {
+			return ReturnErrno(req.Notification, int(unix.EINVAL)), nil
+		}
+		dirfd, pathAddr, how = int(int32(args[0])), args[1], *h
+	default:
+		return nil, fmt.Errorf("open broker cannot handle syscall %q", req.Syscall)
+	}
+
+	path, err := mem.ReadString(pathAddr, unix.PathMax)
+	if err != nil {
+		return nil, err
+	}
+	target, err := req.Target()
+	if err != nil {
+		return nil, err
+	}
+	rootfd, err := targetRoot(target)
+	if err != nil {
+		return nil, err
+	}
+	if rootfd >= 0 {
+		defer unix.Close(rootfd)
+		if !filepath.IsAbs(path) {
+			// The working directory and file descriptors of the target
+			// can only be read as paths in the supervisor's root.
+			req.LogAttrs(slog.String("path", path), slog.String("reason", "relative path in foreign root"))
+			return b.deny(req), nil
+		}
+	}
+	path, err = target.ResolvePath(dirfd, path)
+	if err != nil {
+		return nil, err
+	}
+
+	req.LogAttrs(slog.String("path", path), slog.Uint64("flags", how.Flags))
+	rule := b.match(path)
+	if rule != nil {
+		req.LogAttrs(slog.String("rule", rule.name()))
+	}
+	if rule == nil || (how.Flags&writeFlags != 0 && !rule.Write) {
+		return b.deny(req), nil
+	}
+
+	fd, err := openBeneath(rootfd, rule.Path, path, how)
+	if err != nil {
+		if errno, ok := err.(syscall.Errno); ok {
+			return ReturnErrno(req.Notification, int(errno)), nil
+		}
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	if _, err = req.Listener.RespondWithFD(req.Notification, fd, how.Flags&unix.O_CLOEXEC != 0); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *OpenBroker) deny(req *Request) *Response {
+	errno := b.Errno
+	if errno == 0 {
+		errno = unix.EACCES
+	}
+	return ReturnErrno(req.Notification, int(errno))
+}
+
+func (r *PathRule) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Path
+}
+
+// match returns the most specific rule that covers path.
+func (b *OpenBroker) match(path string) *PathRule {
+	var best *PathRule
+	for i := range b.Rules {
+		r := &b.Rules[i]
+		root := filepath.Clean(r.Path)
+		if path != root && !strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			continue
+		}
+		if best == nil || len(root) > len(filepath.Clean(best.Path)) {
+			best = r
+		}
+	}
+	return best
+}
+
+// targetRoot opens the root directory of the target if it differs from the
+// root directory or the mount namespace of the supervisor. Otherwise it
+// returns -1.
+func targetRoot(t *Target) (int, error) {
+	same := true
+	for _, name := range []string{"root", "ns/mnt"} {
+		var own, target unix.Stat_t
+		if err := unix.Stat("/proc/self/"+name, &own); err != nil {
+			return -1, err
+		}
+		if err := unix.Fstatat(int(t.proc.Fd()), name, &target, 0); err != nil {
+			if err == syscall.ESRCH || err == syscall.ENOENT {
+				return -1, ErrNotificationGone
+			}
+			return -1, fmt.Errorf("failed to stat /proc/%d/%s: %w", t.Pid, name, err)
+		}
+		same = same && own.Dev == target.Dev && own.Ino == target.Ino
+	}
+	if same {
+		return -1, nil
+	}
+
+	fd, err := unix.Openat(int(t.proc.Fd()), "root", unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if err == syscall.ESRCH || err == syscall.ENOENT {
+			return -1, ErrNotificationGone
+		}
+		return -1, fmt.Errorf("failed to open /proc/%d/root: %w", t.Pid, err)
+	}
+	if err = t.Valid(); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// openBeneath opens path, which must be root or below it, without allowing
+// the resolution to leave root. If rootfd is not -1, root is resolved in the
+// root directory rootfd instead of the supervisor's.
+func openBeneath(rootfd int, root, path string, how unix.OpenHow) (int, error) {
+	root = filepath.Clean(root)
+	how.Flags |= unix.O_CLOEXEC
+	how.Resolve |= unix.RESOLVE_BENEATH | unix.RESOLVE_NO_MAGICLINKS
+	if how.Flags&(unix.O_CREAT|unix.O_TMPFILE) == 0 {
+		// The mode argument is undefined and openat2 rejects it.
+		how.Mode = 0
+	}
+
+	if path == root {
+		// The rule may name a file. Open it from its parent directory
+		// without following a symbolic link, which could point to a
+		// sibling.
+		how.Resolve |= unix.RESOLVE_NO_SYMLINKS
+		root = filepath.Dir(root)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return -1, err
+	}
+
+	dirfd, err := openDir(rootfd, root)
+	if err != nil {
+		return -1, err
+	}
+	defer unix.Close(dirfd)
+	return unix.Openat2(dirfd, rel, &how)
+}
+
+// openDir opens the directory dir for resolving paths beneath it.
+func openDir(rootfd int, dir string) (int, error) {
+	if rootfd < 0 {
+		return unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	}
+	return unix.Openat2(rootfd, dir, &unix.OpenHow{
+		Flags:   unix.O_PATH | unix.O_DIRECTORY | unix.O_CLOEXEC,
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_MAGICLINKS,
+	})
+}
@@ -0,0 +1,179 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+type openResult struct {
+	data  string
+	errno unix.Errno
+}
+
+func TestOpenBroker(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "allowed")
+	denied := filepath.Join(dir, "denied")
+	for _, d := range []string{allowed, denied} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "file"), []byte("secret "+filepath.Base(d)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(denied, "file"), filepath.Join(allowed, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path  string
+		flags uint64
+		want  openResult
+	}{
+		{filepath.Join(allowed, "file"), unix.O_RDONLY, openResult{data: "secret allowed"}},
+		{filepath.Join(allowed, "..", "allowed", "file"), unix.O_RDONLY, openResult{data: "secret allowed"}},
+		{filepath.Join(allowed, "file"), unix.O_WRONLY, openResult{errno: unix.EACCES}},
+		{filepath.Join(allowed, "missing"), unix.O_RDONLY, openResult{errno: unix.ENOENT}},
+		{filepath.Join(allowed, "escape"), unix.O_RDONLY, openResult{errno: unix.EXDEV}},
+		{filepath.Join(denied, "file"), unix.O_RDONLY, openResult{errno: unix.EACCES}},
+	}
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		// openat ignores the upper bits of the flags.
+		testCases = append(testCases, struct {
+			path  string
+			flags uint64
+			want  openResult
+		}{filepath.Join(allowed, "file"), 0xdead<<32 | unix.O_RDONLY, openResult{data: "secret allowed"}})
+	}
+
+	results := make(chan openResult, len(testCases))
+	atFdcwd := unix.AT_FDCWD
+	l := startTarget(t, []string{"openat"}, func() {
+		for _, tc := range testCases {
+			p, _ := unix.BytePtrFromString(tc.path)
+			fd, _, errno := unix.Syscall6(unix.SYS_OPENAT, uintptr(atFdcwd), uintptr(unsafe.Pointer(p)),
+				uintptr(tc.flags|unix.O_CLOEXEC), 0, 0, 0)
+			if errno != 0 {
+				results <- openResult{errno: errno}
+				continue
+			}
+			buf := make([]byte, 64)
+			n, _ := unix.Read(int(fd), buf)
+			unix.Close(int(fd))
+			results <- openResult{data: string(buf[:n])}
+		}
+	})
+
+	s := NewSupervisor(l)
+	broker := &OpenBroker{Rules: []PathRule{{Path: allowed}}}
+	broker.Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	for _, tc := range testCases {
+		if got := <-results; got != tc.want {
+			t.Errorf("open %v (flags=%#x): expected %+v, got %+v", tc.path, tc.flags, tc.want, got)
+		}
+	}
+}
+
+func TestOpenBrokerForeignRoot(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "allowed"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "allowed", "file"), []byte("secret allowed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Points to the rule in the root of the target only.
+	if err := os.Symlink("/allowed", filepath.Join(dir, "data")); err != nil {
+		t.Fatal(err)
+	}
+	// Leaves the root of the target.
+	if err := os.Symlink(filepath.Join(dir, "allowed"), filepath.Join(dir, "allowed", "host")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path string
+		want openResult
+	}{
+		{"/allowed/file", openResult{data: "secret allowed"}},
+		{"/allowed/../allowed/file", openResult{data: "secret allowed"}},
+		{"/data/file", openResult{data: "secret allowed"}},
+		{"/allowed/host/file", openResult{errno: unix.EXDEV}},
+		{"allowed/file", openResult{errno: unix.EACCES}},
+		{filepath.Join(dir, "allowed", "file"), openResult{errno: unix.EACCES}},
+	}
+
+	chrooted := make(chan error, 1)
+	results := make(chan openResult, len(testCases))
+	l := startTarget(t, []string{"openat"}, func() {
+		// The thread gets its own root. It is discarded afterwards.
+		err := unix.Unshare(unix.CLONE_FS)
+		if err == nil {
+			err = unix.Chroot(dir)
+		}
+		if err == nil {
+			err = unix.Chdir("/")
+		}
+		chrooted <- err
+		if err != nil {
+			return
+		}
+		for _, tc := range testCases {
+			fd, err := unix.Openat(unix.AT_FDCWD, tc.path, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			if err != nil {
+				results <- openResult{errno: err.(unix.Errno)}
+				continue
+			}
+			buf := make([]byte, 64)
+			n, _ := unix.Read(fd, buf)
+			unix.Close(fd)
+			results <- openResult{data: string(buf[:n])}
+		}
+	})
+	if err := <-chrooted; err != nil {
+		t.Skipf("cannot change the root directory: %v", err)
+	}
+
+	s := NewSupervisor(l)
+	broker := &OpenBroker{Rules: []PathRule{{Path: "/allowed"}, {Path: "/data"}}}
+	broker.Register(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	for _, tc := range testCases {
+		if got := <-results; got != tc.want {
+			t.Errorf("open %v: expected %+v, got %+v", tc.path, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Loader builds a new handler, for example by parsing a rules file.
+type Loader func() (Handler, error)
+
+// Reloadable is a Handler whose underlying handler can be swapped atomically
+// at runtime. Notifications that are being handled keep using the handler
+// they started with, so a reload never drops them. Register it as
+// Supervisor.Default.
+type Reloadable struct {
+	current atomic.Pointer[handlerBox]
+}
+
+// handlerBox allows storing an interface in an atomic.Pointer.
+type handlerBox struct {
+	Handler
+}
+
+// NewReloadable returns a Reloadable that starts with the handler h.
+func NewReloadable(h Handler) *Reloadable {
+	r := &Reloadable{}
+	r.Swap(h)
+	return r
+}
+
+// Swap replaces the handler.
+func (r *Reloadable) Swap(h Handler) {
+	r.current.Store(&handlerBox{h})
+}
+
+// Handle implements Handler.
+func (r *Reloadable) Handle(req *Request) (*Response, error) {
+	box := r.current.Load()
+	if box == nil || box.Handler == nil {
+		return nil, errors.New("no handler loaded")
+	}
+	return box.Handle(req)
+}
+
+// reload calls load and swaps in the result. The current handler is kept if
+// load fails.
+func (r *Reloadable) reload(load Loader, onError func(error)) {
+	h, err := load()
+	if err != nil {
+		if onError != nil {
+			onError(fmt.Errorf("failed to reload handlers: %w", err))
+		}
+		return
+	}
+	r.Swap(h)
+}
+
+// ReloadOnSignal calls load and swaps in the result whenever one of the
+// signals (SIGHUP by default) is received, until ctx is cancelled. Errors
+// are passed to onError and keep the current handler.
+func (r *Reloadable) ReloadOnSignal(ctx context.Context, load Loader, onError func(error), sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			r.reload(load, onError)
+		}
+	}
+}
+
+// WatchFile calls load and swaps in the result whenever the file at path is
+// written, created, or replaced (as editors and configuration management
+// do with a rename), until ctx is cancelled. Errors from load are passed to
+// onError and keep the current handler.
+func (r *Reloadable) WatchFile(ctx context.Context, path string, load Loader, onError func(error)) error {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "inotify")
+	defer f.Close()
+
+	// Watch the directory because replacing the file drops a watch on the
+	// file itself.
+	dir, name := filepath.Split(filepath.Clean(path))
+	if dir == "" {
+		dir = "."
+	}
+	const mask = unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_CREATE
+	if _, err = unix.InotifyAddWatch(fd, dir, mask); err != nil {
+		return fmt.Errorf("failed to watch %v: %w", dir, err)
+	}
+
+	stop := context.AfterFunc(ctx, func() { f.Close() })
+	defer stop()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read inotify events: %w", err)
+		}
+
+		changed := false
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(ev.Len)]
+			if evName := string(trimNUL(nameBytes)); evName == name {
+				changed = true
+			}
+			off += unix.SizeofInotifyEvent + int(ev.Len)
+		}
+		if changed {
+			r.reload(load, onError)
+		}
+	}
+}
+
+func trimNUL(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// valueHandler returns a handler that answers with the value v.
+func valueHandler(v int64) Handler {
+	return HandlerFunc(func(req *Request) (*Response, error) {
+		return ReturnValue(req.Notification, v), nil
+	})
+}
+
+func handlerValue(t *testing.T, h Handler) int64 {
+	t.Helper()
+	resp, err := h.Handle(&Request{Notification: &Notification{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Val
+}
+
+func TestReloadableWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules")
+	if err := os.WriteFile(path, []byte("1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func() (Handler, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return valueHandler(v), nil
+	}
+	h, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReloadable(h)
+
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.WatchFile(ctx, path, load, func(err error) { errs <- err }) }()
+
+	waitValue := func(want int64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for handlerValue(t, r) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("handler was not reloaded to %d", want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Give the watch time to be established.
+	time.Sleep(50 * time.Millisecond)
+
+	if err = os.WriteFile(path, []byte("2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitValue(2)
+
+	// Replace the file like an editor does.
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, []byte("3"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	waitValue(3)
+
+	// An invalid file keeps the current handler.
+	if err = os.WriteFile(path, []byte("invalid"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected reload error")
+	}
+	if v := handlerValue(t, r); v != 3 {
+		t.Errorf("expected handler to be kept, got %d", v)
+	}
+
+	cancel()
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,207 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// The remote supervision protocol forwards notifications from a guest (for
+// example a VM) to a supervisor on the host. Messages are JSON objects sent
+// back to back over a stream such as a vsock connection. The guest sends
+// notifications, the host answers them with responses, and the host can ask
+// the guest to read the memory of the target of a pending notification.
+
+// Remote message types.
+const (
+	remoteNotification = "notification" // guest -> host
+	remoteResponse     = "response"     // host -> guest
+	remoteRead         = "read"         // host -> guest
+	remoteData         = "data"         // guest -> host
+)
+
+// errRemoteGone is the error string used for ErrNotificationGone.
+const errRemoteGone = "gone"
+
+type remoteMessage struct {
+	Type         string        `json:"type"`
+	Notification *Notification `json:"notification,omitempty"`
+	Response     *Response     `json:"response,omitempty"`
+	Read         *remoteReadAt `json:"read,omitempty"`
+	Data         *remoteReply  `json:"data,omitempty"`
+}
+
+type remoteReadAt struct {
+	Seq  uint64 `json:"seq"`
+	ID   uint64 `json:"id"` // ID of the notification whose target is read.
+	Addr int64  `json:"addr"`
+	Size int    `json:"size"`
+}
+
+type remoteReply struct {
+	Seq   uint64 `json:"seq"`
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// maxRemoteRead is the maximum size of a single memory read forwarded by
+// the guest.
+const maxRemoteRead = 1 << 20
+
+// RemoteConn is the host side of the remote supervision protocol. It
+// receives the notifications forwarded by the guest with Forward.
+type RemoteConn struct {
+	conn io.ReadWriteCloser
+
+	sendMu sync.Mutex
+	enc    *json.Encoder
+
+	notifications chan *Notification
+	done          chan struct{}
+	err           error // Error that stopped the connection, set before done is closed.
+
+	mu      sync.Mutex
+	seq     uint64
+	pending map[uint64]chan *remoteReply
+}
+
+// NewRemoteConn returns the host side of the connection to a guest.
+func NewRemoteConn(conn io.ReadWriteCloser) *RemoteConn {
+	c := &RemoteConn{
+		conn:          conn,
+		enc:           json.NewEncoder(conn),
+		notifications: make(chan *Notification),
+		done:          make(chan struct{}),
+		pending:       map[uint64]chan *remoteReply{},
+	}
+	go c.readLoop()
+	return c
+}
+
+func (c *RemoteConn) readLoop() {
+	dec := json.NewDecoder(c.conn)
+	var err error
+	for {
+		var msg remoteMessage
+		if err = dec.Decode(&msg); err != nil {
+			break
+		}
+
+		switch {
+		case msg.Type == remoteNotification && msg.Notification != nil:
+			select {
+			case c.notifications <- msg.Notification:
+			case <-c.done:
+			}
+		case msg.Type == remoteData && msg.Data != nil:
+			c.mu.Lock()
+			ch := c.pending[msg.Data.Seq]
+			delete(c.pending, msg.Data.Seq)
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- msg.Data
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// Receive returns the next notification forwarded by the guest. It returns
+// io.EOF when the guest closed the connection.
+func (c *RemoteConn) Receive() (*Notification, error) {
+	select {
+	case n := <-c.notifications:
+		return n, nil
+	case <-c.done:
+		return nil, c.closeErr()
+	}
+}
+
+// Respond forwards the response to the guest.
+func (c *RemoteConn) Respond(resp *Response) error {
+	return c.send(&remoteMessage{Type: remoteResponse, Response: resp})
+}
+
+// ReadMemory reads len(p) bytes at address addr of the target of the pending
+// notification with the given ID. The guest revalidates the notification
+// after the read like Memory.ReadAt.
+func (c *RemoteConn) ReadMemory(id uint64, p []byte, addr int64) (int, error) {
+	if len(p) > maxRemoteRead {
+		return 0, fmt.Errorf("remote memory read of %d bytes exceeds limit of %d", len(p), maxRemoteRead)
+	}
+
+	ch := make(chan *remoteReply, 1)
+	c.mu.Lock()
+	c.seq++
+	seq := c.seq
+	c.pending[seq] = ch
+	c.mu.Unlock()
+
+	err := c.send(&remoteMessage{Type: remoteRead, Read: &remoteReadAt{Seq: seq, ID: id, Addr: addr, Size: len(p)}})
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, seq)
+		c.mu.Unlock()
+		return 0, err
+	}
+
+	select {
+	case reply := <-ch:
+		switch reply.Error {
+		case "":
+			return copy(p, reply.Data), nil
+		case errRemoteGone:
+			return 0, ErrNotificationGone
+		default:
+			return 0, errors.New(reply.Error)
+		}
+	case <-c.done:
+		return 0, c.closeErr()
+	}
+}
+
+// Close closes the connection.
+func (c *RemoteConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *RemoteConn) send(msg *remoteMessage) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if err := c.enc.Encode(msg); err != nil {
+		return fmt.Errorf("failed to send to remote: %w", err)
+	}
+	return nil
+}
+
+func (c *RemoteConn) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		return io.EOF
+	}
+	return c.err
+}
@@ -0,0 +1,169 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Forward is the guest side of the remote supervision protocol. It forwards
+// the notifications of the listener over conn to a RemoteConn on the host,
+// answers the host's memory reads, and delivers its responses. Forward
+// returns when either the listener or conn is closed or no process uses the
+// filter anymore. It closes the listener, and conn if it is an io.Closer.
+func Forward(l *Listener, conn io.ReadWriter) error {
+	defer l.Close()
+	if c, ok := conn.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	f := &forwarder{
+		listener: l,
+		enc:      json.NewEncoder(conn),
+		pending:  map[uint64]*Request{},
+	}
+	defer f.closeAll()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			n, err := l.Receive()
+			if err != nil {
+				recvErr <- err
+				// Unblock the decoder below by making it fail.
+				if c, ok := conn.(io.Closer); ok {
+					c.Close()
+				}
+				return
+			}
+			f.add(n)
+			if err = f.send(&remoteMessage{Type: remoteNotification, Notification: n}); err != nil {
+				recvErr <- err
+				return
+			}
+		}
+	}()
+
+	dec := json.NewDecoder(conn)
+	for {
+		var msg remoteMessage
+		if err := dec.Decode(&msg); err != nil {
+			l.Close()
+			err = <-recvErr
+			if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		switch {
+		case msg.Type == remoteResponse && msg.Response != nil:
+			f.release(msg.Response.ID)
+			if err := l.Respond(msg.Response); err != nil && !errors.Is(err, ErrNotificationGone) {
+				return err
+			}
+		case msg.Type == remoteRead && msg.Read != nil:
+			if err := f.send(&remoteMessage{Type: remoteData, Data: f.read(msg.Read)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type forwarder struct {
+	listener *Listener
+
+	sendMu sync.Mutex
+	enc    *json.Encoder
+
+	// pending holds the forwarded notifications until they are answered.
+	// Their target handles are opened on the first memory read.
+	mu      sync.Mutex
+	pending map[uint64]*Request
+}
+
+func (f *forwarder) add(n *Notification) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending[n.ID] = &Request{Notification: n, Listener: f.listener}
+}
+
+func (f *forwarder) send(msg *remoteMessage) error {
+	f.sendMu.Lock()
+	defer f.sendMu.Unlock()
+	if err := f.enc.Encode(msg); err != nil {
+		return fmt.Errorf("failed to send to remote: %w", err)
+	}
+	return nil
+}
+
+func (f *forwarder) read(r *remoteReadAt) *remoteReply {
+	reply := &remoteReply{Seq: r.Seq}
+	if r.Size < 0 || r.Size > maxRemoteRead {
+		reply.Error = fmt.Sprintf("invalid read size %d", r.Size)
+		return reply
+	}
+
+	f.mu.Lock()
+	req := f.pending[r.ID]
+	f.mu.Unlock()
+	if req == nil {
+		reply.Error = errRemoteGone
+		return reply
+	}
+
+	mem, err := req.Memory()
+	if err == nil {
+		reply.Data = make([]byte, r.Size)
+		_, err = mem.ReadAt(reply.Data, r.Addr)
+	}
+	switch {
+	case errors.Is(err, ErrNotificationGone):
+		f.release(r.ID)
+		reply.Data, reply.Error = nil, errRemoteGone
+	case err != nil:
+		reply.Data, reply.Error = nil, err.Error()
+	}
+	return reply
+}
+
+func (f *forwarder) release(id uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if req := f.pending[id]; req != nil {
+		req.close()
+		delete(f.pending, id)
+	}
+}
+
+func (f *forwarder) closeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, req := range f.pending {
+		req.close()
+		delete(f.pending, id)
+	}
+}
@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestForward(t *testing.T) {
+	data := []byte("remote")
+	l := startPointerTarget(t, data)
+
+	guest, host := unixSocketPair(t, unix.SOCK_STREAM)
+	done := make(chan error, 1)
+	go func() { done <- Forward(l, guest) }()
+
+	remote := NewRemoteConn(host)
+	n, err := remote.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Data.NR != unix.SYS_GETPPID {
+		t.Fatalf("expected getppid notification, got nr=%d", n.Data.NR)
+	}
+
+	buf := make([]byte, n.Data.Args[1])
+	if _, err = remote.ReadMemory(n.ID, buf, int64(n.Data.Args[0])); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != string(data) {
+		t.Errorf("expected %q, got %q", data, buf)
+	}
+
+	if err = remote.Respond(ReturnValue(n, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Close()
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
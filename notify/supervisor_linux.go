@@ -0,0 +1,473 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// ErrorPolicy defines how a Supervisor answers a notification whose handler
+// failed.
+type ErrorPolicy uint8
+
+// Error policies.
+const (
+	// FailClosed makes the syscall fail with EPERM.
+	FailClosed ErrorPolicy = iota
+	// FailOpen lets the syscall continue. See ContinueUnsafe.
+	FailOpen
+)
+
+// ShutdownPolicy defines how a Supervisor answers the notifications that are
+// pending when its context is cancelled.
+type ShutdownPolicy uint8
+
+// Shutdown policies.
+const (
+	// ShutdownDrain answers pending notifications with the handlers.
+	ShutdownDrain ShutdownPolicy = iota
+	// ShutdownFailClosed makes pending syscalls fail with EPERM.
+	ShutdownFailClosed
+	// ShutdownFailOpen lets pending syscalls continue. See ContinueUnsafe.
+	ShutdownFailOpen
+)
+
+// Handler answers a notification. A handler that already answered the
+// notification itself, for example with Listener.RespondWithFD, returns a
+// nil Response.
+type Handler interface {
+	Handle(req *Request) (*Response, error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as
+// handlers.
+type HandlerFunc func(req *Request) (*Response, error)
+
+// Handle calls f(req).
+func (f HandlerFunc) Handle(req *Request) (*Response, error) {
+	return f(req)
+}
+
+// Request is a notification passed to a Handler.
+type Request struct {
+	*Notification
+	Syscall  string    // Name of the syscall, empty if unknown.
+	Listener *Listener // Listener that received the notification.
+
+	ctx     context.Context
+	metrics Metrics
+	attrs   []slog.Attr
+	latency time.Duration // Time spent in the handler.
+	err     error         // Error returned by the handler.
+	logged  bool          // Whether the notification is logged and traced.
+	target  *Target
+	memory  *Memory
+}
+
+// Target returns a handle to the target of the notification. It is opened on
+// first use and closed after the handler returns.
+func (r *Request) Target() (*Target, error) {
+	if r.target == nil {
+		t, err := r.Listener.OpenTarget(r.Notification)
+		if err != nil {
+			return nil, err
+		}
+		r.target = t
+	}
+	return r.target, nil
+}
+
+// Memory returns the memory of the target of the notification. It is opened
+// on first use and closed after the handler returns.
+func (r *Request) Memory() (*Memory, error) {
+	if r.memory == nil {
+		t, err := r.Target()
+		if err != nil {
+			return nil, err
+		}
+		m, err := t.OpenMemory()
+		if err != nil {
+			return nil, err
+		}
+		if r.metrics != nil {
+			m.onReadError = func(err error) { r.metrics.MemoryReadFailed(r.Syscall, err) }
+		}
+		r.memory = m
+	}
+	return r.memory, nil
+}
+
+// Context returns the context of the Supervisor. It is cancelled when the
+// Supervisor shuts down so that long running handlers can give up.
+func (r *Request) Context() context.Context {
+	return r.ctx
+}
+
+// LogAttrs adds attributes to the record that the Supervisor logs for the
+// notification, for example decoded arguments or the identifier of the rule
+// that made the decision.
+func (r *Request) LogAttrs(attrs ...slog.Attr) {
+	r.attrs = append(r.attrs, attrs...)
+}
+
+// Valid returns ErrNotificationGone if the notification is no longer pending.
+func (r *Request) Valid() error {
+	return r.Listener.Valid(r.ID)
+}
+
+func (r *Request) close() {
+	if r.memory != nil {
+		r.memory.Close()
+	}
+	if r.target != nil {
+		r.target.Close()
+	}
+}
+
+// Supervisor answers the notifications of a Listener by dispatching them to
+// handlers registered by syscall name.
+type Supervisor struct {
+	Listener *Listener
+
+	// Default handles syscalls without a registered handler. If nil, the
+	// ErrorPolicy is applied to them.
+	Default Handler
+
+	// ErrorPolicy is applied when a handler returns an error.
+	ErrorPolicy ErrorPolicy
+
+	// Workers is the maximum number of notifications handled concurrently.
+	// The thread that triggered a notification is blocked until it is
+	// answered, so a slow handler must not hold up the others. Handlers
+	// must be safe for concurrent use if Workers is greater than 1. Defaults
+	// to DefaultWorkers.
+	Workers int
+
+	// Shutdown defines how notifications that are pending when the context
+	// passed to Run is cancelled are answered. Handlers that are already
+	// running are always allowed to finish.
+	Shutdown ShutdownPolicy
+
+	// Metrics receives events about the handled notifications if set.
+	Metrics Metrics
+
+	// Tracer follows every notification from its reception to its answer
+	// if set, for example to emit OpenTelemetry spans.
+	Tracer Tracer
+
+	// LogLimiter limits, per syscall, the notifications that are logged by
+	// the Logger and traced by the Tracer if set, so that a target calling a
+	// syscall in a tight loop cannot flood them. Metrics receive all
+	// notifications.
+	LogLimiter *seccomp.LogLimiter
+
+	// Corpus records the seccomp_data of every notification, with
+	// seccomp.ActionUserNotify as the action of the filter, if set. The
+	// corpus can be replayed against a changed policy with
+	// seccomp.ReplayCorpus.
+	Corpus *seccomp.CorpusWriter
+
+	// Logger receives a record for every notification and every error if
+	// set. Decisions are logged at debug level, handler errors at warn
+	// level.
+	Logger *slog.Logger
+
+	handlers map[string]Handler
+}
+
+// DefaultWorkers is the default number of notifications handled
+// concurrently by a Supervisor.
+const DefaultWorkers = 16
+
+// NewSupervisor returns a Supervisor for the given listener.
+func NewSupervisor(l *Listener) *Supervisor {
+	return &Supervisor{Listener: l, handlers: map[string]Handler{}}
+}
+
+// Handle registers the handler for the given syscall name.
+func (s *Supervisor) Handle(name string, h Handler) {
+	s.handlers[name] = h
+}
+
+// HandleFunc registers the handler function for the given syscall name.
+func (s *Supervisor) HandleFunc(name string, f func(req *Request) (*Response, error)) {
+	s.Handle(name, HandlerFunc(f))
+}
+
+// Run receives and answers notifications until ctx is cancelled, the
+// listener is closed, or no process uses the filter anymore. When ctx is
+// cancelled Run stops receiving notifications, answers the pending ones
+// according to the Shutdown policy, and returns nil. Run closes the listener
+// before returning so that syscalls which are notified afterwards fail with
+// ENOSYS instead of blocking forever. If a response cannot be delivered Run
+// returns the error.
+func (s *Supervisor) Run(ctx context.Context) error {
+	defer s.Listener.Close()
+
+	workers := s.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		errFirst error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			errFirst = err
+			s.Listener.Close()
+		})
+	}
+
+	queue := make(chan *Notification)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range queue {
+				if err := s.dispatch(ctx, n); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	// Interrupt Receive when the context is cancelled. The listener cannot
+	// be closed yet because that fails the syscalls that are in progress.
+	stop := context.AfterFunc(ctx, func() {
+		s.Listener.File().SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	for ctx.Err() == nil {
+		n, err := s.Listener.Receive()
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) && ctx.Err() != nil {
+				break
+			}
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				fail(err)
+			}
+			close(queue)
+			wg.Wait()
+			return errFirst
+		}
+		queue <- n
+	}
+
+	for {
+		n, err := s.Listener.TryReceive()
+		if err != nil || n == nil {
+			break
+		}
+		if s.Shutdown == ShutdownDrain {
+			queue <- n
+			continue
+		}
+		resp := ReturnErrno(n, int(syscall.EPERM))
+		if s.Shutdown == ShutdownFailOpen {
+			resp = ContinueUnsafe(n)
+		}
+		err = s.Listener.Respond(resp)
+		if err != nil && !errors.Is(err, ErrNotificationGone) {
+			fail(err)
+		}
+		req := &Request{Notification: n, Syscall: SyscallName(n), Listener: s.Listener, ctx: ctx}
+		if s.Metrics != nil {
+			s.Metrics.NotificationReceived(req.Syscall)
+		}
+		s.start(req)
+		if err != nil {
+			s.decided(req, nil, DecisionGone)
+		} else {
+			s.decided(req, resp, decisionOf(resp))
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return errFirst
+}
+
+// dispatch answers one notification. Errors are only returned if the
+// response cannot be delivered for other reasons than the target going
+// away.
+func (s *Supervisor) dispatch(ctx context.Context, n *Notification) error {
+	req := &Request{
+		Notification: n,
+		Syscall:      SyscallName(n),
+		Listener:     s.Listener,
+		ctx:          ctx,
+		metrics:      s.Metrics,
+	}
+	defer req.close()
+	if s.Metrics != nil {
+		s.Metrics.NotificationReceived(req.Syscall)
+	}
+	s.start(req)
+
+	h := s.handlers[req.Syscall]
+	if h == nil {
+		h = s.Default
+	}
+
+	var resp *Response
+	var err error
+	if h != nil {
+		start := time.Now()
+		resp, err = h.Handle(req)
+		req.latency = time.Since(start)
+		if s.Metrics != nil {
+			s.Metrics.HandlerLatency(req.Syscall, req.latency)
+		}
+	} else {
+		err = fmt.Errorf("no handler for syscall %q", req.Syscall)
+	}
+	if err != nil {
+		req.err = err
+		if errors.Is(err, ErrNotificationGone) {
+			s.decided(req, nil, DecisionGone)
+			return nil
+		}
+		if s.Metrics != nil {
+			s.Metrics.HandlerFailed(req.Syscall, err)
+		}
+		if s.Logger != nil && req.logged {
+			s.Logger.LogAttrs(req.ctx, slog.LevelWarn, "seccomp notification handler failed",
+				append(s.logAttrs(req), slog.Any("error", err))...)
+		}
+		resp = s.errorResponse(n)
+	}
+	if resp == nil {
+		s.decided(req, nil, DecisionHandled)
+		return nil
+	}
+
+	resp.ID = n.ID
+	if err = s.Listener.Respond(resp); err != nil {
+		if errors.Is(err, ErrNotificationGone) {
+			s.decided(req, nil, DecisionGone)
+			return nil
+		}
+		if s.Logger != nil {
+			s.Logger.LogAttrs(req.ctx, slog.LevelError, "failed to answer seccomp notification",
+				append(s.logAttrs(req), slog.Any("error", err))...)
+		}
+		return err
+	}
+	s.decided(req, resp, decisionOf(resp))
+	return nil
+}
+
+// start records a received notification in the Corpus, applies the
+// LogLimiter to it, and starts tracing it.
+func (s *Supervisor) start(req *Request) {
+	if err := s.Corpus.Record(req.Data, seccomp.ActionUserNotify); err != nil && s.Logger != nil {
+		s.Logger.LogAttrs(req.ctx, slog.LevelWarn, "failed to record seccomp notification",
+			append(s.logAttrs(req), slog.Any("error", err))...)
+	}
+	req.logged = s.LogLimiter.Allow(req.Syscall)
+	if s.Tracer != nil && req.logged {
+		req.ctx = s.Tracer.Start(req)
+	}
+}
+
+func (s *Supervisor) decided(req *Request, resp *Response, d Decision) {
+	if s.Metrics != nil {
+		s.Metrics.Decided(req.Syscall, d)
+	}
+	if s.Tracer != nil && req.logged {
+		s.Tracer.End(req, Outcome{Decision: d, Response: resp, Latency: req.latency, Err: req.err})
+	}
+	if s.Logger == nil || !req.logged {
+		return
+	}
+
+	attrs := append(s.logAttrs(req), slog.String("decision", d.String()))
+	switch d {
+	case DecisionValue:
+		attrs = append(attrs, slog.Int64("value", resp.Val))
+	case DecisionErrno:
+		attrs = append(attrs, slog.String("errno", syscall.Errno(-resp.Error).Error()))
+	}
+	s.Logger.LogAttrs(req.ctx, slog.LevelDebug, "seccomp notification", append(attrs, req.attrs...)...)
+}
+
+// logAttrs returns the attributes that identify the notification.
+func (s *Supervisor) logAttrs(req *Request) []slog.Attr {
+	args := make([]any, len(req.Data.Args))
+	for i, a := range req.Data.Args {
+		args[i] = fmt.Sprintf("0x%x", a)
+	}
+	return []slog.Attr{
+		slog.Uint64("id", req.ID),
+		slog.Int("pid", int(req.Pid)),
+		slog.String("syscall", req.Syscall),
+		slog.Int("nr", int(req.Data.NR)),
+		slog.Any("args", args),
+	}
+}
+
+func (s *Supervisor) errorResponse(n *Notification) *Response {
+	if s.ErrorPolicy == FailOpen {
+		return ContinueUnsafe(n)
+	}
+	return ReturnErrno(n, int(syscall.EPERM))
+}
+
+// SyscallName returns the name of the syscall that triggered the
+// notification or an empty string if the architecture or syscall is unknown.
+func SyscallName(n *Notification) string {
+	info, nr := syscallArch(n.Data)
+	if info == nil {
+		return ""
+	}
+	return info.SyscallName(nr)
+}
+
+// syscallArch returns the architecture of the syscall and its number without
+// the architecture's mask, or nil if the architecture is unknown.
+func syscallArch(data seccomp.SeccompData) (*arch.Info, int) {
+	for _, info := range []*arch.Info{arch.X86_64, arch.I386, arch.AARCH64, arch.ARM} {
+		if uint32(info.ID) != data.Arch {
+			continue
+		}
+		nr := int(data.NR)
+		if info == arch.X86_64 && nr&arch.X32.SeccompMask != 0 {
+			info = arch.X32
+			nr &^= arch.X32.SeccompMask
+		}
+		return info, nr
+	}
+	return nil, 0
+}
@@ -0,0 +1,540 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+func TestSupervisor(t *testing.T) {
+	results := make(chan [2]uintptr, 1)
+	l := startTarget(t, []string{"getppid", "getuid"}, func() {
+		ppid, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		_, _, errno := unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+		results <- [2]uintptr{ppid, uintptr(errno)}
+	})
+
+	s := NewSupervisor(l)
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		if req.Syscall != "getppid" {
+			t.Errorf("unexpected syscall name %q", req.Syscall)
+		}
+		return ReturnValue(req.Notification, 4242), nil
+	})
+	s.HandleFunc("getuid", func(req *Request) (*Response, error) {
+		return nil, errors.New("failure")
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	r := <-results
+	if r[0] != 4242 {
+		t.Errorf("expected getppid to return 4242, got %d", r[0])
+	}
+	if unix.Errno(r[1]) != unix.EPERM {
+		t.Errorf("expected getuid to fail with EPERM, got %v", unix.Errno(r[1]))
+	}
+
+	l.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyscallName(t *testing.T) {
+	testCases := []struct {
+		arch uint32
+		nr   int32
+		name string
+	}{
+		{0xc000003e, 39, "getpid"},              // x86_64
+		{0xc000003e, 0x40000000 | 39, "getpid"}, // x32
+		{0x40000003, 20, "getpid"},              // i386
+		{0xc00000b7, 172, "getpid"},             // aarch64
+		{0x1234, 39, ""},
+	}
+
+	for _, tc := range testCases {
+		n := &Notification{}
+		n.Data.Arch, n.Data.NR = tc.arch, tc.nr
+		if name := SyscallName(n); name != tc.name {
+			t.Errorf("arch=0x%x nr=%d: expected %q, got %q", tc.arch, tc.nr, tc.name, name)
+		}
+	}
+}
+
+func TestSupervisorWorkers(t *testing.T) {
+	features, err := seccomp.KernelSupport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !features.HasAction(seccomp.ActionUserNotify) || !features.HasFlags(seccomp.FilterFlagTSyncESRCH) {
+		t.Skip("user notifications with TSYNC not supported by kernel")
+	}
+
+	// Notifications from two threads are needed, so the filter is
+	// installed process wide on a syscall that the runtime never uses.
+	// The syscall fails with ENOSYS once the listener is closed.
+	filter := seccomp.Filter{
+		NoNewPrivs: true,
+		Flag:       seccomp.FilterFlagTSync,
+		Policy: seccomp.Policy{
+			DefaultAction: seccomp.ActionAllow,
+			Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionUserNotify, Names: []string{"sched_getscheduler"}}},
+		},
+	}
+	// Threads confined by earlier tests exit asynchronously and make the
+	// synchronization fail until they are gone.
+	var f *os.File
+	for i := 0; i < 50; i++ {
+		var tsyncErr *seccomp.TSyncError
+		if f, err = seccomp.LoadFilterListener(filter); !errors.As(err, &tsyncErr) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewListener(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	s := NewSupervisor(l)
+	s.Workers = 2
+	s.HandleFunc("sched_getscheduler", func(req *Request) (*Response, error) {
+		if req.Data.Args[0] == 1 {
+			<-release
+		}
+		return ReturnValue(req.Notification, int64(req.Data.Args[0])), nil
+	})
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	results := make(chan uintptr, 2)
+	call := func(arg uintptr) {
+		r, _, _ := unix.Syscall(unix.SYS_SCHED_GETSCHEDULER, arg, 0, 0)
+		results <- r
+	}
+	go call(1)
+	go call(2)
+
+	// The slow handler must not block the fast one.
+	if r := <-results; r != 2 {
+		t.Fatalf("expected the fast syscall to finish first, got %d", r)
+	}
+	close(release)
+	if r := <-results; r != 1 {
+		t.Fatalf("expected the slow syscall to return 1, got %d", r)
+	}
+
+	l.Close()
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSupervisorShutdown(t *testing.T) {
+	testCases := []struct {
+		policy ShutdownPolicy
+		ppid   uintptr
+		errno  unix.Errno
+	}{
+		{ShutdownDrain, 4242, 0},
+		{ShutdownFailClosed, ^uintptr(0), unix.EPERM},
+		{ShutdownFailOpen, uintptr(unix.Getppid()), 0},
+	}
+
+	for _, tc := range testCases {
+		results := make(chan [2]uintptr, 1)
+		l := startTarget(t, []string{"getppid"}, func() {
+			ppid, _, errno := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+			results <- [2]uintptr{ppid, uintptr(errno)}
+		})
+		waitPending(t, l)
+
+		s := NewSupervisor(l)
+		s.Shutdown = tc.policy
+		s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+			return ReturnValue(req.Notification, 4242), nil
+		})
+
+		// The notification is pending before Run starts, so it is answered
+		// according to the shutdown policy.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := s.Run(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		r := <-results
+		if r[0] != tc.ppid || unix.Errno(r[1]) != tc.errno {
+			t.Errorf("policy %d: expected (%d, %v), got (%d, %v)", tc.policy, tc.ppid, tc.errno, r[0], unix.Errno(r[1]))
+		}
+		if _, err := l.Receive(); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("policy %d: expected listener to be closed, got %v", tc.policy, err)
+		}
+	}
+}
+
+// waitPending waits until a notification is pending on the listener.
+func waitPending(t testing.TB, l *Listener) {
+	t.Helper()
+	conn, err := l.File().SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Control(func(fd uintptr) {
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		_, err = unix.Poll(fds, 5000)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testMetrics struct {
+	mu         sync.Mutex
+	received   map[string]int
+	decisions  map[Decision]int
+	failures   int
+	readErrors int
+}
+
+func (m *testMetrics) NotificationReceived(syscall string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received[syscall]++
+}
+
+func (m *testMetrics) Decided(_ string, d Decision) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decisions[d]++
+}
+
+func (m *testMetrics) HandlerLatency(string, time.Duration) {}
+
+func (m *testMetrics) HandlerFailed(string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures++
+}
+
+func (m *testMetrics) MemoryReadFailed(string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readErrors++
+}
+
+func TestSupervisorMetrics(t *testing.T) {
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid", "getuid"}, func() {
+		defer close(done)
+		unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		unix.Syscall(unix.SYS_GETPPID, 1, 0, 0)
+		unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+	})
+
+	metrics := &testMetrics{received: map[string]int{}, decisions: map[Decision]int{}}
+	s := NewSupervisor(l)
+	s.Metrics = metrics
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		if req.Data.Args[0] == 0 {
+			return ReturnValue(req.Notification, 1), nil
+		}
+		mem, err := req.Memory()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = mem.ReadAt(make([]byte, 1), 0); err == nil {
+			t.Error("expected read of address 0 to fail")
+		}
+		return ContinueUnsafe(req.Notification), nil
+	})
+	s.HandleFunc("getuid", func(req *Request) (*Response, error) {
+		return nil, errors.New("failure")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+	<-done
+	// Run returns when the target thread has exited, after the metrics of
+	// the last notification were recorded.
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.received["getppid"] != 2 || metrics.received["getuid"] != 1 {
+		t.Errorf("unexpected notification counts %v", metrics.received)
+	}
+	want := map[Decision]int{DecisionValue: 1, DecisionContinue: 1, DecisionErrno: 1}
+	if !reflect.DeepEqual(metrics.decisions, want) {
+		t.Errorf("expected decisions %v, got %v", want, metrics.decisions)
+	}
+	if metrics.failures != 1 || metrics.readErrors != 1 {
+		t.Errorf("expected 1 handler failure and 1 read error, got %d and %d", metrics.failures, metrics.readErrors)
+	}
+}
+
+type tracerKey struct{}
+
+type testTracer struct {
+	mu       sync.Mutex
+	started  int
+	outcomes map[string]Outcome
+}
+
+func (tr *testTracer) Start(req *Request) context.Context {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.started++
+	return context.WithValue(req.Context(), tracerKey{}, req.ID)
+}
+
+func (tr *testTracer) End(req *Request, o Outcome) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.outcomes[req.Syscall] = o
+}
+
+func TestSupervisorTracer(t *testing.T) {
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid", "getuid"}, func() {
+		defer close(done)
+		unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+	})
+
+	tracer := &testTracer{outcomes: map[string]Outcome{}}
+	s := NewSupervisor(l)
+	s.Tracer = tracer
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		if id, _ := req.Context().Value(tracerKey{}).(uint64); id != req.ID {
+			t.Error("expected the context of the tracer")
+		}
+		time.Sleep(time.Millisecond)
+		return ReturnErrno(req.Notification, int(unix.EACCES)), nil
+	})
+	s.HandleFunc("getuid", func(req *Request) (*Response, error) {
+		return nil, errors.New("failure")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+	<-done
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if tracer.started != 2 || len(tracer.outcomes) != 2 {
+		t.Fatalf("expected 2 traced notifications, got %d started and %v", tracer.started, tracer.outcomes)
+	}
+	o := tracer.outcomes["getppid"]
+	if o.Decision != DecisionErrno || o.Response == nil || o.Response.Error != -int32(unix.EACCES) || o.Latency < time.Millisecond || o.Err != nil {
+		t.Errorf("unexpected outcome of getppid %+v", o)
+	}
+	o = tracer.outcomes["getuid"]
+	if o.Decision != DecisionErrno || o.Err == nil || o.Err.Error() != "failure" {
+		t.Errorf("unexpected outcome of getuid %+v", o)
+	}
+}
+
+func TestSupervisorLogLimiter(t *testing.T) {
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid"}, func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		}
+	})
+
+	var buf bytes.Buffer
+	metrics := &testMetrics{received: map[string]int{}, decisions: map[Decision]int{}}
+	tracer := &testTracer{outcomes: map[string]Outcome{}}
+	s := NewSupervisor(l)
+	s.Workers = 1
+	s.Metrics = metrics
+	s.Tracer = tracer
+	s.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s.LogLimiter = seccomp.NewLogLimiter(seccomp.LogLimit{Sample: 2})
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		return ReturnValue(req.Notification, 1), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+	<-done
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 3 {
+		t.Errorf("expected 3 of 5 notifications to be logged, got %d:\n%s", n, buf.String())
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if tracer.started != 3 {
+		t.Errorf("expected 3 of 5 notifications to be traced, got %d", tracer.started)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.received["getppid"] != 5 {
+		t.Errorf("expected metrics of all notifications, got %v", metrics.received)
+	}
+}
+
+func TestSupervisorCorpus(t *testing.T) {
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid"}, func() {
+		defer close(done)
+		for _, arg := range []uintptr{1, 1, 2} {
+			unix.Syscall(unix.SYS_GETPPID, arg, 0, 0)
+		}
+	})
+
+	var buf bytes.Buffer
+	s := NewSupervisor(l)
+	s.Corpus = seccomp.NewCorpusWriter(&buf)
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		return ContinueUnsafe(req.Notification), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+	<-done
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := seccomp.ReadCorpus(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Data.Args[0] != 1 || entries[1].Data.Args[0] != 2 || entries[0].Action != seccomp.ActionUserNotify {
+		t.Fatalf("expected the two distinct calls of getppid, got %+v", entries)
+	}
+
+	// A policy that denies the second call changes its decision.
+	policy := seccomp.Policy{
+		DefaultAction: seccomp.ActionAllow,
+		Syscalls: []seccomp.SyscallGroup{{
+			Action: seccomp.ActionErrno,
+			NamesWithCondtions: []seccomp.NameWithConditions{{
+				Name:       "getppid",
+				Conditions: seccomp.ArgumentConditions{{Argument: 0, Operation: seccomp.Equal, Value: 2}},
+			}},
+		}},
+	}
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := seccomp.ReplayCorpus(insts, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Entry != entries[1] || !changes[0].Denies() {
+		t.Errorf("expected the second call to be denied, got %v", changes)
+	}
+}
+
+func TestSupervisorLogger(t *testing.T) {
+	done := make(chan struct{})
+	l := startTarget(t, []string{"getppid", "getuid"}, func() {
+		defer close(done)
+		unix.Syscall(unix.SYS_GETPPID, 7, 0, 0)
+		unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+	})
+
+	var buf bytes.Buffer
+	s := NewSupervisor(l)
+	s.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s.HandleFunc("getppid", func(req *Request) (*Response, error) {
+		req.LogAttrs(slog.String("rule", "test"))
+		return ReturnErrno(req.Notification, int(unix.EACCES)), nil
+	})
+	s.HandleFunc("getuid", func(req *Request) (*Response, error) {
+		return nil, errors.New("failure")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+	<-done
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d: %v", len(records), records)
+	}
+
+	r := records[0]
+	if r["level"] != "DEBUG" || r["syscall"] != "getppid" || r["decision"] != "errno" || r["rule"] != "test" {
+		t.Errorf("unexpected record %v", r)
+	}
+	if r["errno"] != unix.EACCES.Error() {
+		t.Errorf("expected errno %q, got %v", unix.EACCES.Error(), r["errno"])
+	}
+	if args, ok := r["args"].([]any); !ok || len(args) == 0 || args[0] != "0x7" {
+		t.Errorf("unexpected args %v", r["args"])
+	}
+	if records[1]["level"] != "WARN" || records[1]["error"] != "failure" {
+		t.Errorf("unexpected record %v", records[1])
+	}
+	if records[2]["syscall"] != "getuid" || records[2]["decision"] != "errno" {
+		t.Errorf("unexpected record %v", records[2])
+	}
+}
@@ -0,0 +1,229 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// pidfdThread is PIDFD_THREAD (Linux 6.9) which allows pidfd_open on a thread
+// that is not the thread group leader. It is a variable so that tests can
+// simulate older kernels.
+var pidfdThread = unix.O_EXCL
+
+// Target is a handle to the thread that triggered a notification. The handle
+// is obtained while the notification is pending so that it is guaranteed to
+// refer to the notifying thread even if its PID is reused later on. All
+// operations on the target should go through the handle instead of the PID.
+type Target struct {
+	Pid int    // Thread ID of the target (in the supervisor's PID namespace).
+	ID  uint64 // ID of the notification.
+
+	listener *Listener
+	pidfd    int      // pidfd referring to the target.
+	proc     *os.File // /proc/<pid> directory of the target.
+}
+
+// OpenTarget returns a handle to the target of the notification. It must be
+// called before responding to the notification. ErrNotificationGone is
+// returned if the target is no longer waiting for the notification.
+func (l *Listener) OpenTarget(n *Notification) (*Target, error) {
+	pid := int(n.Pid)
+	proc, err := os.OpenFile("/proc/"+strconv.Itoa(pid), unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotificationGone
+		}
+		return nil, fmt.Errorf("failed to open /proc for pid %d: %w", pid, err)
+	}
+
+	pidfd, err := openPidfd(pid, proc)
+	if err != nil {
+		proc.Close()
+		if err == syscall.ESRCH || os.IsNotExist(err) {
+			return nil, ErrNotificationGone
+		}
+		return nil, fmt.Errorf("failed to open pidfd for pid %d: %w", pid, err)
+	}
+
+	t := &Target{Pid: pid, ID: n.ID, listener: l, pidfd: pidfd, proc: proc}
+
+	// The PID may have been reused between receiving the notification and
+	// opening the handles. The handles are only valid if the notification
+	// is still pending afterwards.
+	if err = t.Valid(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// openPidfd opens a pidfd for the thread pid. Before Linux 6.9 a pidfd can
+// only refer to a thread group leader, so for other threads the pidfd of
+// their leader is returned instead. It signals the whole thread group and
+// shares the file descriptor table with the thread. The caller must check
+// that the notification is still pending afterwards, which guarantees that
+// the thread, and thus its thread group, has not exited in the meantime.
+func openPidfd(pid int, proc *os.File) (int, error) {
+	pidfd, err := unix.PidfdOpen(pid, 0)
+	if err != syscall.EINVAL && err != syscall.ENOENT {
+		return pidfd, err
+	}
+
+	// The notifying thread is not the thread group leader.
+	pidfd, err = unix.PidfdOpen(pid, pidfdThread)
+	if err != syscall.EINVAL {
+		return pidfd, err
+	}
+
+	// PIDFD_THREAD is not supported by the kernel.
+	tgid, err := readTgid(proc)
+	if err != nil {
+		return -1, err
+	}
+	return unix.PidfdOpen(tgid, 0)
+}
+
+// readTgid returns the thread group ID from the status file in the /proc
+// directory of a thread.
+func readTgid(proc *os.File) (int, error) {
+	fd, err := unix.Openat(int(proc.Fd()), "status", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return 0, err
+	}
+	f := os.NewFile(uintptr(fd), "status")
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if v, ok := strings.CutPrefix(s.Text(), "Tgid:"); ok {
+			return strconv.Atoi(strings.TrimSpace(v))
+		}
+	}
+	if err = s.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no Tgid in %s/status", proc.Name())
+}
+
+// Pidfd returns the pidfd of the target. It remains owned by the Target.
+func (t *Target) Pidfd() int {
+	return t.pidfd
+}
+
+// Valid returns ErrNotificationGone if the notification is no longer pending.
+func (t *Target) Valid() error {
+	return t.listener.Valid(t.ID)
+}
+
+// Signal sends a signal to the target using its pidfd. Before Linux 6.9 the
+// signal is sent to the thread group of the target if it is not the leader.
+func (t *Target) Signal(sig syscall.Signal) error {
+	if err := unix.PidfdSendSignal(t.pidfd, sig, nil, 0); err != nil {
+		return fmt.Errorf("failed to signal pid %d: %w", t.Pid, err)
+	}
+	return nil
+}
+
+// GetFd returns a duplicate of the file descriptor fd of the target, for
+// example to fstat a file descriptor passed to an intercepted syscall. The
+// duplicate shares the open file description with the target. It requires
+// Linux 5.6 and the permission to ptrace the target.
+func (t *Target) GetFd(fd int) (*os.File, error) {
+	nfd, err := unix.PidfdGetfd(t.pidfd, fd, 0)
+	if err != nil {
+		if err == syscall.ESRCH {
+			return nil, ErrNotificationGone
+		}
+		return nil, fmt.Errorf("failed to get fd %d of pid %d: %w", fd, t.Pid, err)
+	}
+	f := os.NewFile(uintptr(nfd), fmt.Sprintf("pid%d-fd%d", t.Pid, fd))
+
+	// The target may have closed or replaced fd after the notification.
+	// The file is only what the notification referred to if the target is
+	// still blocked in the syscall.
+	if err = t.Valid(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Cwd returns the current working directory of the target.
+func (t *Target) Cwd() (string, error) {
+	return t.readlink("cwd")
+}
+
+// FdPath returns the path of the file descriptor fd of the target.
+func (t *Target) FdPath(fd int) (string, error) {
+	return t.readlink("fd/" + strconv.Itoa(fd))
+}
+
+// ResolvePath returns the absolute path of path relative to the file
+// descriptor dirfd of the target, like the *at syscalls interpret it.
+// Symbolic links are not resolved.
+func (t *Target) ResolvePath(dirfd int, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+
+	var dir string
+	var err error
+	if dirfd == unix.AT_FDCWD {
+		dir, err = t.Cwd()
+	} else {
+		dir, err = t.FdPath(dirfd)
+	}
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// readlink reads a symbolic link in the target's /proc directory.
+func (t *Target) readlink(name string) (string, error) {
+	buf := make([]byte, unix.PathMax)
+	n, err := unix.Readlinkat(int(t.proc.Fd()), name, buf)
+	if err != nil {
+		if err == syscall.ESRCH {
+			return "", ErrNotificationGone
+		}
+		return "", fmt.Errorf("failed to read /proc/%d/%s: %w", t.Pid, name, err)
+	}
+	if err = t.Valid(); err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+// Close releases the handle.
+func (t *Target) Close() error {
+	unix.Close(t.pidfd)
+	return t.proc.Close()
+}
@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer follows the notifications of a Supervisor, for example to emit a
+// span per intercepted syscall. Implementations must be safe for concurrent
+// use.
+type Tracer interface {
+	// Start is called when a notification is received, before its handler
+	// runs. The returned context becomes the context of the Request, so that
+	// the work of the handler, such as brokered I/O, can be traced as its
+	// children. It must be derived from req.Context().
+	Start(req *Request) context.Context
+	// End is called once with the outcome of every notification passed to
+	// Start.
+	End(req *Request, o Outcome)
+}
+
+// Outcome is the way a notification ended.
+type Outcome struct {
+	Decision Decision
+	// Response is the answer, nil for DecisionHandled and DecisionGone.
+	Response *Response
+	// Latency is the time spent in the handler, zero if no handler ran.
+	Latency time.Duration
+	// Err is the error returned by the handler, if any.
+	Err error
+}
@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxMetadataSize is the maximum size of the encoded Metadata accepted by
+// ReceiveListener.
+const maxMetadataSize = 64 * 1024
+
+// Metadata describes the sandboxed process that sends its listener to a
+// supervisor.
+type Metadata struct {
+	Pid    int               `json:"pid"`              // PID of the sandboxed process (in its own PID namespace).
+	Name   string            `json:"name,omitempty"`   // Name of the sandboxed process or workload.
+	Labels map[string]string `json:"labels,omitempty"` // Arbitrary labels, for example for selecting handlers.
+}
+
+// SendListener passes the listener file returned by
+// seccomp.LoadFilterListener and the metadata over a unix socket. This allows
+// an unprivileged process to install a filter and delegate the supervision to
+// a privileged daemon. The file can be closed after sending it.
+func SendListener(conn *net.UnixConn, file *os.File, meta Metadata) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	raw, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	err = raw.Control(func(fd uintptr) {
+		_, _, sendErr = conn.WriteMsgUnix(data, unix.UnixRights(int(fd)), nil)
+	})
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send seccomp listener: %w", err)
+	}
+	return nil
+}
+
+// ReceiveListener receives a listener and its metadata sent with
+// SendListener.
+func ReceiveListener(conn *net.UnixConn) (*Listener, *Metadata, error) {
+	data := make([]byte, maxMetadataSize)
+	oob := make([]byte, unix.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(data, oob)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive seccomp listener: %w", err)
+	}
+
+	fds, err := parseRights(oob[:oobn])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to receive seccomp listener: %w", err)
+	}
+	if len(fds) != 1 {
+		closeAll(fds)
+		return nil, nil, fmt.Errorf("failed to receive seccomp listener: expected 1 file descriptor, got %d", len(fds))
+	}
+
+	var meta Metadata
+	if err = json.Unmarshal(data[:n], &meta); err != nil {
+		unix.Close(fds[0])
+		return nil, nil, fmt.Errorf("failed to decode seccomp listener metadata: %w", err)
+	}
+
+	l, err := NewListener(os.NewFile(uintptr(fds[0]), "seccomp-notify"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, &meta, nil
+}
+
+// parseRights returns the file descriptors passed in SCM_RIGHTS control
+// messages.
+func parseRights(oob []byte) ([]int, error) {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+	var fds []int
+	for _, msg := range msgs {
+		rights, err := unix.ParseUnixRights(&msg)
+		if err != nil {
+			continue
+		}
+		fds = append(fds, rights...)
+	}
+	return fds, nil
+}
+
+func closeAll(fds []int) {
+	for _, fd := range fds {
+		unix.Close(fd)
+	}
+}
@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// unixSocketPair returns a connected pair of unix sockets.
+func unixSocketPair(t testing.TB, typ int) (*net.UnixConn, *net.UnixConn) {
+	t.Helper()
+	fds, err := unix.Socketpair(unix.AF_UNIX, typ|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socketpair")
+		c, err := net.FileConn(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+		t.Cleanup(func() { c.Close() })
+	}
+	return conns[0], conns[1]
+}
+
+func TestSendListener(t *testing.T) {
+	results := make(chan uintptr, 1)
+	l := startTarget(t, []string{"getppid"}, func() {
+		ppid, _, _ := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		results <- ppid
+	})
+
+	sandbox, broker := unixSocketPair(t, unix.SOCK_SEQPACKET)
+	meta := Metadata{Pid: os.Getpid(), Name: "test", Labels: map[string]string{"tenant": "a"}}
+	if err := SendListener(sandbox, l.File(), meta); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	remote, got, err := ReceiveListener(broker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	if !reflect.DeepEqual(*got, meta) {
+		t.Errorf("expected metadata %+v, got %+v", meta, *got)
+	}
+
+	n, err := remote.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Respond(ReturnValue(n, 4242)); err != nil {
+		t.Fatal(err)
+	}
+	if ppid := <-results; ppid != 4242 {
+		t.Errorf("expected getppid to return 4242, got %d", ppid)
+	}
+}
@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notify
+
+import (
+	"errors"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// ErrNotificationGone is returned when the notification is no longer valid
+// because the target process died or the syscall was interrupted by a
+// signal.
+var ErrNotificationGone = errors.New("notification is no longer valid")
+
+// Notification is a syscall intercepted by a seccomp filter. It is the Go
+// representation of struct seccomp_notif.
+// https://github.com/torvalds/linux/blob/v5.0/include/uapi/linux/seccomp.h#L65-L70
+type Notification struct {
+	ID    uint64              // Cookie identifying the notification.
+	Pid   uint32              // Thread ID of the process that made the syscall.
+	Flags uint32              // Currently unused.
+	Data  seccomp.SeccompData // Syscall number, arch, and arguments.
+}
+
+// Response is the answer to a Notification. It is the Go representation of
+// struct seccomp_notif_resp.
+// https://github.com/torvalds/linux/blob/v5.0/include/uapi/linux/seccomp.h#L72-L77
+type Response struct {
+	ID    uint64 // ID of the notification that is answered.
+	Val   int64  // Return value of the syscall (if Error is 0).
+	Error int32  // Negative errno value returned by the syscall.
+	Flags uint32 // Response flags.
+}
+
+// Response flags.
+const (
+	// FlagContinue tells the kernel to execute the original syscall. It
+	// requires Linux 5.5.
+	FlagContinue uint32 = 1 // SECCOMP_USER_NOTIF_FLAG_CONTINUE
+)
+
+// AddFD flags.
+const (
+	// AddFDFlagSetFD installs the file descriptor at AddFD.NewFD in the target,
+	// replacing any file descriptor already open at that number.
+	AddFDFlagSetFD uint32 = 1 // SECCOMP_ADDFD_FLAG_SETFD
+	// AddFDFlagSend atomically installs the file descriptor and answers the
+	// notification with the new file descriptor number. It requires Linux 5.14.
+	AddFDFlagSend uint32 = 2 // SECCOMP_ADDFD_FLAG_SEND
+)
+
+// AddFD is a request to install a file descriptor into the target of a
+// notification. It is the Go representation of struct seccomp_notif_addfd.
+// https://github.com/torvalds/linux/blob/v5.14/include/uapi/linux/seccomp.h#L118-L130
+type AddFD struct {
+	ID         uint64 // ID of the notification.
+	Flags      uint32 // AddFDFlagSetFD and AddFDFlagSend.
+	SrcFD      uint32 // File descriptor in the supervisor.
+	NewFD      uint32 // File descriptor number in the target (with AddFDFlagSetFD).
+	NewFDFlags uint32 // Flags for the new file descriptor (only O_CLOEXEC).
+}
+
+// ReturnValue returns a response that makes the syscall return val.
+func ReturnValue(n *Notification, val int64) *Response {
+	return &Response{ID: n.ID, Val: val}
+}
+
+// ReturnErrno returns a response that makes the syscall fail with errno.
+func ReturnErrno(n *Notification, errno int) *Response {
+	return &Response{ID: n.ID, Error: -int32(errno)}
+}
+
+// ContinueUnsafe returns a response that makes the target execute the original
+// syscall. The name is a reminder that this must not be used to implement a
+// security policy: the target (or another thread sharing its memory) can
+// change the arguments that pointers refer to after the supervisor inspected
+// them and before the kernel executes the syscall. Only use it where the
+// decision does not depend on pointer arguments or for auditing.
+func ContinueUnsafe(n *Notification) *Response {
+	return &Response{ID: n.ID, Flags: FlagContinue}
+}
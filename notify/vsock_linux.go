@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package notify
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Package net does not support AF_VSOCK so vsock connections are returned as
+// *os.File, which is serviced by the runtime poller like a net.Conn.
+
+// DialVsock connects to the vsock port of the given context ID, for example
+// unix.VMADDR_CID_HOST from inside a VM.
+func DialVsock(cid, port uint32) (*os.File, error) {
+	fd, err := unix.Socket(unix.AF_VSOCK, unix.SOCK_STREAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vsock socket: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("vsock:%d:%d", cid, port))
+
+	err = unix.Connect(fd, &unix.SockaddrVM{CID: cid, Port: port})
+	if err == unix.EINPROGRESS {
+		err = waitConnect(f)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to connect to vsock %d:%d: %w", cid, port, err)
+	}
+	return f, nil
+}
+
+func waitConnect(f *os.File) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var soErr int
+	var sockErr error
+	first := true
+	err = conn.Write(func(fd uintptr) bool {
+		// The socket becomes writable once the connection is established
+		// or failed, so the first call only registers the wait.
+		if first {
+			first = false
+			return false
+		}
+		soErr, sockErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ERROR)
+		return true
+	})
+	if err == nil {
+		err = sockErr
+	}
+	if err != nil {
+		return err
+	}
+	if soErr != 0 {
+		return syscall.Errno(soErr)
+	}
+	return nil
+}
+
+// VsockListener accepts vsock connections.
+type VsockListener struct {
+	file *os.File
+}
+
+// ListenVsock listens on the vsock port of all local context IDs.
+func ListenVsock(port uint32) (*VsockListener, error) {
+	fd, err := unix.Socket(unix.AF_VSOCK, unix.SOCK_STREAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vsock socket: %w", err)
+	}
+	if err = unix.Bind(fd, &unix.SockaddrVM{CID: unix.VMADDR_CID_ANY, Port: port}); err == nil {
+		err = unix.Listen(fd, unix.SOMAXCONN)
+	}
+	if err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to listen on vsock port %d: %w", port, err)
+	}
+	return &VsockListener{file: os.NewFile(uintptr(fd), fmt.Sprintf("vsock:*:%d", port))}, nil
+}
+
+// Accept waits for the next connection. It returns the connection and the
+// context ID of the peer.
+func (l *VsockListener) Accept() (*os.File, uint32, error) {
+	conn, err := l.file.SyscallConn()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nfd int
+	var sa unix.Sockaddr
+	var acceptErr error
+	err = conn.Read(func(fd uintptr) bool {
+		nfd, sa, acceptErr = unix.Accept4(int(fd), unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC)
+		return acceptErr != unix.EAGAIN
+	})
+	if err == nil {
+		err = acceptErr
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to accept vsock connection: %w", err)
+	}
+
+	var cid uint32
+	if vm, ok := sa.(*unix.SockaddrVM); ok {
+		cid = vm.CID
+	}
+	return os.NewFile(uintptr(nfd), fmt.Sprintf("vsock:%d", cid)), cid, nil
+}
+
+// Close stops listening.
+func (l *VsockListener) Close() error {
+	return l.file.Close()
+}
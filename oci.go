@@ -0,0 +1,441 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// OCIProfile is a seccomp profile in the format of the OCI runtime
+// specification (linux.seccomp in config.json), which is also used by
+// Docker, Podman, and Kubernetes. The includes and excludes of Docker's
+// profiles are supported.
+type OCIProfile struct {
+	DefaultAction    string       `json:"defaultAction"`
+	DefaultErrnoRet  *uint        `json:"defaultErrnoRet,omitempty"`
+	Architectures    []string     `json:"architectures,omitempty"`
+	ArchMap          []OCIArchMap `json:"archMap,omitempty"`
+	Flags            []string     `json:"flags,omitempty"`
+	ListenerPath     string       `json:"listenerPath,omitempty"`
+	ListenerMetadata string       `json:"listenerMetadata,omitempty"`
+	Syscalls         []OCISyscall `json:"syscalls,omitempty"`
+}
+
+// OCIArchMap lists the sub-architectures of an architecture (Docker).
+type OCIArchMap struct {
+	Architecture     string   `json:"architecture"`
+	SubArchitectures []string `json:"subArchitectures"`
+}
+
+// OCISyscall is a rule of an OCIProfile.
+type OCISyscall struct {
+	Names    []string   `json:"names"`
+	Action   string     `json:"action"`
+	ErrnoRet *uint      `json:"errnoRet,omitempty"`
+	Args     []OCIArg   `json:"args,omitempty"`
+	Comment  string     `json:"comment,omitempty"`
+	Includes *OCIFilter `json:"includes,omitempty"`
+	Excludes *OCIFilter `json:"excludes,omitempty"`
+}
+
+// OCIArg is an argument condition of an OCISyscall.
+type OCIArg struct {
+	Index    uint   `json:"index"`
+	Value    uint64 `json:"value"`
+	ValueTwo uint64 `json:"valueTwo,omitempty"`
+	Op       string `json:"op"`
+}
+
+// OCIFilter restricts an OCISyscall to some architectures, capabilities, or
+// kernel versions (Docker).
+type OCIFilter struct {
+	Arches    []string `json:"arches,omitempty"`
+	Caps      []string `json:"caps,omitempty"`
+	MinKernel string   `json:"minKernel,omitempty"`
+}
+
+// OCIOptions describe the environment that the includes and excludes of an
+// OCIProfile are evaluated against.
+type OCIOptions struct {
+	// Arch is the architecture of the policy. It defaults to the native
+	// architecture.
+	Arch string
+
+	// Capabilities are the capabilities of the confined process, for
+	// example "CAP_SYS_ADMIN".
+	Capabilities []string
+
+	// Kernel is the version of the running kernel. If it is nil, rules that
+	// depend on the kernel version are included.
+	Kernel *KernelVersion
+}
+
+var ociActions = map[string]Action{
+	"SCMP_ACT_KILL":         ActionKillThread,
+	"SCMP_ACT_KILL_THREAD":  ActionKillThread,
+	"SCMP_ACT_KILL_PROCESS": ActionKillProcess,
+	"SCMP_ACT_TRAP":         ActionTrap,
+	"SCMP_ACT_ERRNO":        ActionErrno,
+	"SCMP_ACT_TRACE":        ActionTrace,
+	"SCMP_ACT_ALLOW":        ActionAllow,
+	"SCMP_ACT_LOG":          ActionLog,
+	"SCMP_ACT_NOTIFY":       ActionUserNotify,
+}
+
+var ociFlags = map[string]FilterFlag{
+	"SECCOMP_FILTER_FLAG_TSYNC":      FilterFlagTSync,
+	"SECCOMP_FILTER_FLAG_LOG":        FilterFlagLog,
+	"SECCOMP_FILTER_FLAG_SPEC_ALLOW": FilterFlagSpecAllow,
+}
+
+// ReadOCIProfile reads an OCI seccomp profile in JSON format.
+func ReadOCIProfile(r io.Reader) (*OCIProfile, error) {
+	var profile OCIProfile
+	if err := json.NewDecoder(r).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("invalid OCI seccomp profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// Filter converts the profile into a filter. The policy only covers the
+// architecture of the options, like libseccomp it ignores syscalls that do
+// not exist on it. Rules comparing the same argument more than once are
+// split into one rule per comparison, as runc does, so the comparisons are
+// OR'ed. NoNewPrivs is not part of the profile and is left unset.
+func (p *OCIProfile) Filter(opts OCIOptions) (*Filter, error) {
+	info, err := arch.GetInfo(opts.Arch)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter Filter
+	for _, name := range p.Flags {
+		flag, found := ociFlags[name]
+		if !found {
+			return nil, fmt.Errorf("unsupported seccomp flag %q", name)
+		}
+		filter.Flag |= flag
+	}
+
+	filter.Policy.arch = info
+	if filter.Policy.DefaultAction, err = ociAction(p.DefaultAction, p.DefaultErrnoRet); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range p.Syscalls {
+		if !opts.matches(info, rule.Includes, true) || !opts.matches(info, rule.Excludes, false) {
+			continue
+		}
+
+		group, err := rule.group(info)
+		if err != nil {
+			return nil, fmt.Errorf("syscalls[%d]: %w", i, err)
+		}
+		if len(group.Names) > 0 || len(group.NamesWithCondtions) > 0 {
+			filter.Policy.Syscalls = append(filter.Policy.Syscalls, group)
+		}
+	}
+	if len(filter.Policy.Syscalls) == 0 {
+		// A profile that only sets the default action is valid.
+		filter.Policy.Syscalls = []SyscallGroup{{Action: filter.Policy.DefaultAction}}
+	}
+	return &filter, nil
+}
+
+// group converts the rule into a syscall group for the arch.
+func (s *OCISyscall) group(info *arch.Info) (SyscallGroup, error) {
+	action, err := ociAction(s.Action, s.ErrnoRet)
+	if err != nil {
+		return SyscallGroup{}, err
+	}
+	group := SyscallGroup{Action: action}
+
+	var conditions []ArgumentConditions
+	if len(s.Args) > 0 {
+		indexes := map[uint]bool{}
+		var all ArgumentConditions
+		for _, arg := range s.Args {
+			c, err := arg.condition()
+			if err != nil {
+				return SyscallGroup{}, err
+			}
+			all = append(all, c)
+			indexes[arg.Index] = true
+		}
+		if len(indexes) == len(all) {
+			conditions = []ArgumentConditions{all}
+		} else {
+			for _, c := range all {
+				conditions = append(conditions, ArgumentConditions{c})
+			}
+		}
+	}
+
+	for _, name := range s.Names {
+		if _, found := info.SyscallNumber(name); !found {
+			continue
+		}
+		if conditions == nil {
+			group.Names = append(group.Names, name)
+			continue
+		}
+		for _, c := range conditions {
+			group.NamesWithCondtions = append(group.NamesWithCondtions, NameWithConditions{Name: name, Conditions: c})
+		}
+	}
+	return group, nil
+}
+
+// condition converts the argument comparison.
+func (a OCIArg) condition() (Condition, error) {
+	if a.Index > 5 {
+		return Condition{}, fmt.Errorf("invalid argument index %d", a.Index)
+	}
+	c := Condition{Argument: uint32(a.Index), Value: a.Value}
+	switch a.Op {
+	case "SCMP_CMP_EQ":
+		c.Operation = Equal
+	case "SCMP_CMP_NE":
+		c.Operation = NotEqual
+	case "SCMP_CMP_LT":
+		c.Operation = LessThan
+	case "SCMP_CMP_LE":
+		c.Operation = LessOrEqual
+	case "SCMP_CMP_GT":
+		c.Operation = GreaterThan
+	case "SCMP_CMP_GE":
+		c.Operation = GreaterOrEqual
+	case "SCMP_CMP_MASKED_EQ":
+		// arg & value == valueTwo can be expressed if no bit of the mask
+		// may be set, or if the mask has a single bit that must be set.
+		switch {
+		case a.ValueTwo == 0:
+			c.Operation = BitsNotSet
+		case a.ValueTwo == a.Value && a.Value&(a.Value-1) == 0:
+			c.Operation = BitsSet
+		default:
+			return Condition{}, fmt.Errorf("unsupported SCMP_CMP_MASKED_EQ with mask %#x and value %#x", a.Value, a.ValueTwo)
+		}
+	default:
+		return Condition{}, fmt.Errorf("unsupported argument comparison %q", a.Op)
+	}
+	return c, nil
+}
+
+// ociAction converts an action. errnoRet defaults to EPERM.
+func ociAction(name string, errnoRet *uint) (Action, error) {
+	action, found := ociActions[name]
+	if !found {
+		return 0, fmt.Errorf("unsupported seccomp action %q", name)
+	}
+	if action == ActionErrno && errnoRet != nil {
+		if *errnoRet > 0xffff {
+			return 0, fmt.Errorf("invalid errnoRet %d", *errnoRet)
+		}
+		action |= Action(*errnoRet)
+	}
+	return action, nil
+}
+
+// matches evaluates the includes (include is true) or excludes of a rule
+// like Docker. A rule is included if all criteria of includes hold. It is
+// excluded if any criterion of excludes holds, where the capabilities hold
+// if any of them is present.
+func (o OCIOptions) matches(info *arch.Info, f *OCIFilter, include bool) bool {
+	if f == nil {
+		return true
+	}
+
+	var results []bool
+	if len(f.Arches) > 0 {
+		found := false
+		for _, name := range f.Arches {
+			if a, err := arch.GetInfo(name); err == nil && a == info {
+				found = true
+			}
+		}
+		results = append(results, found)
+	}
+	if len(f.Caps) > 0 {
+		present := 0
+		for _, c := range f.Caps {
+			for _, have := range o.Capabilities {
+				if strings.EqualFold(c, have) {
+					present++
+					break
+				}
+			}
+		}
+		if include {
+			results = append(results, present == len(f.Caps))
+		} else {
+			results = append(results, present > 0)
+		}
+	}
+	if f.MinKernel != "" && o.Kernel != nil {
+		min, err := ParseKernelVersion(f.MinKernel)
+		results = append(results, err == nil && !o.Kernel.Less(min))
+	}
+
+	for _, r := range results {
+		if r != include {
+			return false
+		}
+	}
+	return true
+}
+
+// ociArchNames are the libseccomp names of the architectures.
+var ociArchNames = map[string]string{
+	"x86_64":  "SCMP_ARCH_X86_64",
+	"x32":     "SCMP_ARCH_X32",
+	"i386":    "SCMP_ARCH_X86",
+	"arm":     "SCMP_ARCH_ARM",
+	"aarch64": "SCMP_ARCH_AARCH64",
+}
+
+var ociOperations = map[Operation]string{
+	Equal:          "SCMP_CMP_EQ",
+	NotEqual:       "SCMP_CMP_NE",
+	LessThan:       "SCMP_CMP_LT",
+	LessOrEqual:    "SCMP_CMP_LE",
+	GreaterThan:    "SCMP_CMP_GT",
+	GreaterOrEqual: "SCMP_CMP_GE",
+}
+
+// NewOCIProfile converts the filter into an OCI profile for the policy's
+// architecture. libseccomp, which the OCI runtimes use, checks rules with
+// argument conditions before rules without them, so a policy that relies on
+// an unconditional rule taking precedence over a later conditional rule for
+// the same syscall is not reproduced exactly. BitsSet conditions with more
+// than one bit become one rule per bit.
+func NewOCIProfile(f *Filter) (*OCIProfile, error) {
+	p := &f.Policy
+	if _, err := p.Assemble(); err != nil {
+		return nil, err
+	}
+
+	profile := &OCIProfile{}
+	if name, found := ociArchNames[p.arch.Name]; found {
+		profile.Architectures = []string{name}
+	}
+	for name, flag := range ociFlags {
+		if f.Flag&flag != 0 {
+			profile.Flags = append(profile.Flags, name)
+		}
+	}
+	sort.Strings(profile.Flags)
+
+	var err error
+	if profile.DefaultAction, profile.DefaultErrnoRet, err = ociActionName(p.DefaultAction); err != nil {
+		return nil, err
+	}
+
+	for _, group := range p.groups() {
+		action, errnoRet, err := ociActionName(group.Action)
+		if err != nil {
+			return nil, err
+		}
+		if len(group.Names) > 0 {
+			profile.Syscalls = append(profile.Syscalls, OCISyscall{
+				Names:    append([]string(nil), group.Names...),
+				Action:   action,
+				ErrnoRet: errnoRet,
+			})
+		}
+		for _, nc := range group.NamesWithCondtions {
+			alternatives, err := ociArgs(nc.Conditions)
+			if err != nil {
+				return nil, fmt.Errorf("syscall %v: %w", nc.Name, err)
+			}
+			for _, args := range alternatives {
+				profile.Syscalls = append(profile.Syscalls, OCISyscall{
+					Names:    []string{nc.Name},
+					Action:   action,
+					ErrnoRet: errnoRet,
+					Args:     args,
+				})
+			}
+		}
+	}
+	return profile, nil
+}
+
+// ociActionName returns the OCI name of the action and its errnoRet.
+func ociActionName(a Action) (string, *uint, error) {
+	for name, action := range ociActions {
+		if action != a&actionMask || name == "SCMP_ACT_KILL" {
+			continue
+		}
+		switch data := uint(a &^ actionMask); {
+		case action == ActionErrno && data != 0:
+			return name, &data, nil
+		case data != 0:
+			return "", nil, fmt.Errorf("action %v has data that OCI profiles can not express", a)
+		}
+		return name, nil, nil
+	}
+	return "", nil, fmt.Errorf("invalid action %v", a)
+}
+
+// ociArgs converts conditions into alternative lists of argument
+// comparisons.
+func ociArgs(conditions ArgumentConditions) ([][]OCIArg, error) {
+	alternatives := [][]OCIArg{nil}
+	seen := map[uint32]bool{}
+	for _, c := range conditions {
+		if seen[c.Argument] {
+			// runc splits such rules into one rule per comparison, which
+			// would OR the comparisons.
+			return nil, fmt.Errorf("argument %d is compared more than once", c.Argument)
+		}
+		seen[c.Argument] = true
+
+		var options []OCIArg
+		switch c.Operation {
+		case BitsSet:
+			for v := c.Value; v != 0; v &= v - 1 {
+				bit := v & -v
+				options = append(options, OCIArg{Index: uint(c.Argument), Value: bit, ValueTwo: bit, Op: "SCMP_CMP_MASKED_EQ"})
+			}
+		case BitsNotSet:
+			options = append(options, OCIArg{Index: uint(c.Argument), Value: c.Value, Op: "SCMP_CMP_MASKED_EQ"})
+		default:
+			op, found := ociOperations[c.Operation]
+			if !found {
+				return nil, fmt.Errorf("unknown operation %v", c.Operation)
+			}
+			options = append(options, OCIArg{Index: uint(c.Argument), Value: c.Value, Op: op})
+		}
+
+		var product [][]OCIArg
+		for _, alt := range alternatives {
+			for _, option := range options {
+				product = append(product, append(append([]OCIArg(nil), alt...), option))
+			}
+		}
+		alternatives = product
+	}
+	return alternatives, nil
+}
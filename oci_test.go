@@ -0,0 +1,212 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+const testOCIProfile = `{
+	"defaultAction": "SCMP_ACT_ERRNO",
+	"defaultErrnoRet": 38,
+	"architectures": ["SCMP_ARCH_X86_64", "SCMP_ARCH_X86", "SCMP_ARCH_X32"],
+	"flags": ["SECCOMP_FILTER_FLAG_LOG"],
+	"syscalls": [
+		{"names": ["read", "write", "arm_fadvise64_64", "no_such_syscall"], "action": "SCMP_ACT_ALLOW"},
+		{"names": ["mkdir"], "action": "SCMP_ACT_ERRNO", "errnoRet": 13},
+		{
+			"names": ["personality"],
+			"action": "SCMP_ACT_ALLOW",
+			"args": [{"index": 0, "value": 0, "op": "SCMP_CMP_EQ"}]
+		},
+		{
+			"names": ["personality"],
+			"action": "SCMP_ACT_ALLOW",
+			"args": [
+				{"index": 0, "value": 8, "op": "SCMP_CMP_EQ"},
+				{"index": 0, "value": 131072, "op": "SCMP_CMP_EQ"}
+			]
+		},
+		{
+			"names": ["clone"],
+			"action": "SCMP_ACT_ALLOW",
+			"args": [{"index": 0, "value": 2114060288, "valueTwo": 0, "op": "SCMP_CMP_MASKED_EQ"}],
+			"excludes": {"caps": ["CAP_SYS_ADMIN"], "arches": ["s390", "s390x"]}
+		},
+		{"names": ["mount"], "action": "SCMP_ACT_ALLOW", "includes": {"caps": ["CAP_SYS_ADMIN"]}},
+		{"names": ["arch_prctl"], "action": "SCMP_ACT_ALLOW", "includes": {"arches": ["amd64", "x32"]}},
+		{"names": ["io_uring_setup"], "action": "SCMP_ACT_ALLOW", "includes": {"minKernel": "5.1"}}
+	]
+}`
+
+func TestOCIProfile(t *testing.T) {
+	profile, err := ReadOCIProfile(strings.NewReader(testOCIProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := profile.Filter(OCIOptions{Arch: "x86_64", Kernel: &KernelVersion{4, 19}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Flag != FilterFlagLog {
+		t.Errorf("unexpected flags %v", filter.Flag)
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	enosys := ActionErrno | Action(38)
+	simulateSyscalls(t, &filter.Policy, []SeccompTest{
+		{data("read"), ActionAllow},
+		{data("mkdir"), ActionErrno | Action(13)},
+		{data("getppid"), enosys},
+		{data("personality", 0), ActionAllow},
+		{data("personality", 8), ActionAllow},
+		{data("personality", 131072), ActionAllow},
+		{data("personality", 1), enosys},
+		{data("clone", 0x11), ActionAllow},
+		{data("clone", 0x10000000 /* CLONE_NEWUSER */), enosys},
+		{data("mount"), enosys},
+		{data("arch_prctl"), ActionAllow},
+		{data("io_uring_setup"), enosys},
+	})
+
+	// The excludes and includes depend on the capabilities and kernel.
+	filter, err = profile.Filter(OCIOptions{Arch: "x86_64", Capabilities: []string{"CAP_SYS_ADMIN"}, Kernel: &KernelVersion{6, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulateSyscalls(t, &filter.Policy, []SeccompTest{
+		{data("clone", 0x10000000), enosys},
+		{data("mount"), ActionAllow},
+		{data("io_uring_setup"), ActionAllow},
+	})
+
+	if filter, err = profile.Filter(OCIOptions{Arch: "aarch64"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range filter.Policy.Syscalls {
+		for _, name := range group.Names {
+			if name == "arch_prctl" {
+				t.Error("arch_prctl must not be included on aarch64")
+			}
+		}
+	}
+}
+
+func TestOCIProfileErrors(t *testing.T) {
+	for _, profile := range []string{
+		`{"defaultAction": "SCMP_ACT_UNKNOWN"}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "flags": ["SECCOMP_FILTER_FLAG_UNKNOWN"]}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"names": ["clone"], "action": "SCMP_ACT_ERRNO",
+			"args": [{"index": 0, "value": 3, "valueTwo": 1, "op": "SCMP_CMP_MASKED_EQ"}]}]}`,
+		`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"names": ["read"], "action": "SCMP_ACT_ERRNO",
+			"args": [{"index": 6, "value": 0, "op": "SCMP_CMP_EQ"}]}]}`,
+	} {
+		p, err := ReadOCIProfile(strings.NewReader(profile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = p.Filter(OCIOptions{Arch: "x86_64"}); err == nil {
+			t.Errorf("expected error for %s", profile)
+		}
+	}
+}
+
+func TestNewOCIProfile(t *testing.T) {
+	filter := &Filter{
+		Flag: FilterFlagLog,
+		Policy: Policy{
+			DefaultAction: ActionErrno | 38,
+			Syscalls: []SyscallGroup{
+				{
+					Action: ActionAllow,
+					Names:  []string{"read", "write"},
+					NamesWithCondtions: []NameWithConditions{
+						{
+							Name: "socket",
+							Conditions: ArgumentConditions{
+								{Argument: 0, Operation: Equal, Value: 1},
+								{Argument: 1, Operation: BitsSet, Value: 0x3},
+							},
+						},
+						{
+							Name: "openat",
+							Conditions: ArgumentConditions{
+								{Argument: 2, Operation: BitsNotSet, Value: 0x3},
+							},
+						},
+					},
+				},
+				{Action: ActionKillProcess, Names: []string{"ptrace"}},
+			},
+		},
+	}
+	if err := filter.Policy.SetArch("x86_64"); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := NewOCIProfile(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.DefaultAction != "SCMP_ACT_ERRNO" || profile.DefaultErrnoRet == nil || *profile.DefaultErrnoRet != 38 {
+		t.Errorf("unexpected default action %v %v", profile.DefaultAction, profile.DefaultErrnoRet)
+	}
+	// BitsSet with two bits is split into two rules.
+	if len(profile.Syscalls) != 5 {
+		t.Errorf("expected 5 rules, got %+v", profile.Syscalls)
+	}
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ReadOCIProfile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip, err := parsed.Filter(OCIOptions{Arch: "x86_64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roundTrip.Flag != filter.Flag {
+		t.Errorf("expected flag %v, got %v", filter.Flag, roundTrip.Flag)
+	}
+	insts, err := roundTrip.Policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := filter.Policy.VerifyProgram(insts); err != nil {
+		t.Fatal(err)
+	}
+
+	filter.Policy.Syscalls[0].NamesWithCondtions[1].Conditions = append(filter.Policy.Syscalls[0].NamesWithCondtions[1].Conditions,
+		Condition{Argument: 2, Operation: NotEqual, Value: 0})
+	if _, err := NewOCIProfile(filter); err == nil {
+		t.Error("expected an error for an argument that is compared twice")
+	}
+}
@@ -0,0 +1,28 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package otel traces the notifications of a notify.Supervisor with
+// OpenTelemetry. Every intercepted syscall becomes a span with the syscall,
+// the target pid, the decision, and the handler latency. The context of the
+// span is the context of the notify.Request, so the work of the handler, such
+// as brokered I/O, shows up as its children in distributed traces.
+//
+//	supervisor.Tracer = otel.NewTracer(otel.Options{})
+//
+// The package is a separate module so that go-seccomp-bpf does not depend on
+// OpenTelemetry.
+package otel
@@ -0,0 +1,22 @@
+module github.com/elastic/go-seccomp-bpf/otel
+
+go 1.23.0
+
+require (
+	github.com/elastic/go-seccomp-bpf v1.7.0
+	go.opentelemetry.io/otel v1.36.0
+	go.opentelemetry.io/otel/sdk v1.36.0
+	go.opentelemetry.io/otel/trace v1.36.0
+	golang.org/x/sys v0.33.0
+)
+
+require (
+	github.com/elastic/go-ucfg v0.8.8 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.36.0 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
@@ -0,0 +1,116 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package otel
+
+import (
+	"context"
+	"strconv"
+	"syscall"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/elastic/go-seccomp-bpf/notify"
+)
+
+// instrumentationName is the name of the OpenTelemetry tracer.
+const instrumentationName = "github.com/elastic/go-seccomp-bpf/otel"
+
+// Attributes of the spans.
+const (
+	SyscallKey        = attribute.Key("seccomp.syscall")          // Name of the syscall, empty if unknown.
+	SyscallNrKey      = attribute.Key("seccomp.syscall.nr")       // Number of the syscall.
+	NotificationIDKey = attribute.Key("seccomp.notification.id")  // Identifier of the notification.
+	PIDKey            = attribute.Key("seccomp.pid")              // Pid of the thread that made the syscall.
+	DecisionKey       = attribute.Key("seccomp.decision")         // The way the notification was answered.
+	ValueKey          = attribute.Key("seccomp.return_value")     // Return value for the value decision.
+	ErrnoKey          = attribute.Key("seccomp.errno")            // Errno for the errno decision.
+	HandlerLatencyKey = attribute.Key("seccomp.handler.duration") // Seconds spent in the handler.
+)
+
+// Options configure a Tracer.
+type Options struct {
+	// TracerProvider creates the tracer. The global provider is used if nil.
+	TracerProvider trace.TracerProvider
+}
+
+// Tracer is a notify.Tracer that emits a span for every notification.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ notify.Tracer = (*Tracer)(nil)
+
+// NewTracer returns a Tracer.
+func NewTracer(opts Options) *Tracer {
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otelapi.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+// Start implements notify.Tracer. It starts a span named after the syscall.
+func (t *Tracer) Start(req *notify.Request) context.Context {
+	ctx := req.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	name := req.Syscall
+	if name == "" {
+		name = "syscall_" + strconv.Itoa(int(req.Data.NR))
+	}
+	ctx, _ = t.tracer.Start(ctx, "seccomp_notify "+name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			SyscallKey.String(req.Syscall),
+			SyscallNrKey.Int(int(req.Data.NR)),
+			NotificationIDKey.String(strconv.FormatUint(req.ID, 10)),
+			PIDKey.Int(int(req.Pid)),
+		))
+	return ctx
+}
+
+// End implements notify.Tracer. It ends the span with the decision. Handler
+// errors are recorded and set the status of the span to error.
+func (t *Tracer) End(req *notify.Request, o notify.Outcome) {
+	span := trace.SpanFromContext(req.Context())
+
+	attrs := []attribute.KeyValue{
+		DecisionKey.String(o.Decision.String()),
+		HandlerLatencyKey.Float64(o.Latency.Seconds()),
+	}
+	switch o.Decision {
+	case notify.DecisionValue:
+		attrs = append(attrs, ValueKey.Int64(o.Response.Val))
+	case notify.DecisionErrno:
+		attrs = append(attrs, ErrnoKey.String(syscall.Errno(-o.Response.Error).Error()))
+	}
+	span.SetAttributes(attrs...)
+
+	if o.Err != nil {
+		span.RecordError(o.Err)
+		span.SetStatus(codes.Error, o.Err.Error())
+	}
+	span.End()
+}
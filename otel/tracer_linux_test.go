@@ -0,0 +1,103 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/sys/unix"
+
+	"github.com/elastic/go-seccomp-bpf/notify"
+	"github.com/elastic/go-seccomp-bpf/notify/notifytest"
+)
+
+func TestTracer(t *testing.T) {
+	done := make(chan struct{})
+	var tid int
+	l := notifytest.ConfineThread(t, []string{"getppid", "getuid"}, func() {
+		defer close(done)
+		tid = unix.Gettid()
+		unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+		unix.Syscall(unix.SYS_GETUID, 0, 0, 0)
+	})
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	s := notify.NewSupervisor(l)
+	s.Tracer = NewTracer(Options{TracerProvider: provider})
+	s.HandleFunc("getppid", func(req *notify.Request) (*notify.Response, error) {
+		_, span := provider.Tracer("test").Start(req.Context(), "broker")
+		span.End()
+		return notify.ReturnErrno(req.Notification, int(unix.EACCES)), nil
+	})
+	s.HandleFunc("getuid", func(req *notify.Request) (*notify.Response, error) {
+		return nil, errors.New("failure")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Run(ctx) }()
+	// The target thread might be the main thread, which never exits, so
+	// stop the supervisor instead of waiting for the listener to close.
+	<-done
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %v", spans)
+	}
+
+	getppid := spans["seccomp_notify getppid"]
+	if getppid == nil {
+		t.Fatalf("no span for getppid in %v", spans)
+	}
+	attrs := attribute.NewSet(getppid.Attributes()...)
+	for key, want := range map[attribute.Key]attribute.Value{
+		SyscallKey:  attribute.StringValue("getppid"),
+		DecisionKey: attribute.StringValue("errno"),
+		ErrnoKey:    attribute.StringValue(unix.EACCES.Error()),
+		PIDKey:      attribute.IntValue(tid),
+	} {
+		if v, found := attrs.Value(key); !found || v != want {
+			t.Errorf("expected %v=%v, got %v", key, want.Emit(), v.Emit())
+		}
+	}
+	if broker := spans["broker"]; broker == nil || broker.Parent().SpanID() != getppid.SpanContext().SpanID() {
+		t.Error("expected the span of the handler to be a child of the notification")
+	}
+
+	getuid := spans["seccomp_notify getuid"]
+	if getuid == nil || getuid.Status().Code != codes.Error || len(getuid.Events()) != 1 {
+		t.Errorf("expected the handler error to be recorded, got %v", getuid)
+	}
+}
@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+var (
+	// 0.034 ( 0.002 ms): cat/1234 openat(dfd: CWD, filename: 0x1b2f6, flags: RDONLY|CLOEXEC) = 3
+	perfTraceCall = regexp.MustCompile(`^[\d.]+\s+\([^)]*\):\s+\S+\s+(\w+)\((.*)$`)
+
+	// cat 1234 [002] 1234.567890: raw_syscalls:sys_enter: NR 0 (3, 7ffc5b0d8a10, 20000, 0, 0, 0)
+	// cat 1234 [002] 1234.567890: syscalls:sys_enter_read: fd: 0x00000003, buf: 0x7ffc5b0d8a10, count: 0x00020000
+	perfScriptEvent = regexp.MustCompile(`\s(?:raw_syscalls:sys_enter:\s+NR\s+(\d+)\s+\(([^)]*)\)|syscalls:sys_enter_(\w+):\s*(.*))$`)
+
+	perfArgName = regexp.MustCompile(`^\w+:\s+`)
+)
+
+// ReadPerfTrace reads the output of perf trace, including its -s summary,
+// or of perf script for raw_syscalls:sys_enter or syscalls:sys_enter_*
+// events. The syscall numbers of raw_syscalls events are resolved for the
+// arch, which defaults to the native architecture. perf trace prints many
+// arguments symbolically, which are recorded as unknown values.
+func ReadPerfTrace(r io.Reader, archName string) (*SyscallTrace, error) {
+	info, err := arch.GetInfo(archName)
+	if err != nil {
+		return nil, err
+	}
+	trace := NewSyscallTrace()
+
+	var summary bool
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		fields := strings.Fields(line)
+
+		// syscall            calls  errors  total       min  ...
+		if len(fields) >= 2 && fields[0] == "syscall" && fields[1] == "calls" {
+			summary = true
+			continue
+		}
+		if summary {
+			if len(fields) == 0 {
+				summary = false
+				continue
+			}
+			if calls, err := strconv.ParseUint(fields[min(1, len(fields)-1)], 10, 64); err == nil {
+				trace.AddCount(fields[0], calls)
+			}
+			continue
+		}
+
+		if m := perfTraceCall.FindStringSubmatch(line); m != nil {
+			args, ok := splitStraceArgs(m[2])
+			if !ok {
+				trace.AddCount(m[1], 1)
+				continue
+			}
+			for i, arg := range args {
+				args[i] = perfArgName.ReplaceAllString(arg, "")
+			}
+			trace.Add(m[1], args...)
+			continue
+		}
+
+		m := perfScriptEvent.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+		case m[1] != "":
+			num, _ := strconv.Atoi(m[1])
+			name := info.SyscallName(num)
+			if name == "" {
+				continue
+			}
+			var args []string
+			for _, arg := range strings.Split(m[2], ",") {
+				// The values are printed in hex without a prefix.
+				args = append(args, "0x"+strings.TrimSpace(arg))
+			}
+			trace.Add(name, args...)
+		default:
+			var args []string
+			if m[4] != "" {
+				for _, arg := range strings.Split(m[4], ",") {
+					args = append(args, perfArgName.ReplaceAllString(strings.TrimSpace(arg), ""))
+				}
+			}
+			trace.Add(m[3], args...)
+		}
+	}
+	return trace, s.Err()
+}
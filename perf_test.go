@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPerfTrace = `     0.000 ( 0.004 ms): cat/12345 brk() = 0x55d0c0a5e000
+     0.034 ( 0.002 ms): cat/12345 openat(dfd: CWD, filename: 0x1b2f6, flags: RDONLY|CLOEXEC) = 3
+     0.040 ( 0.001 ms): cat/12345 read(fd: 3, buf: 0x7ffc5b0d8a10, count: 832) = 832
+     0.050 (         ): cat/12345 exit_group() ...
+
+ Summary of events:
+
+ cat (12345), 12 events, 100.0%
+
+   syscall            calls  errors  total       min       avg       max       stddev
+                                     (msec)    (msec)    (msec)    (msec)        (%)
+   --------------- --------  ------ -------- --------- --------- ---------     ------
+   mmap                  3      0     0.009     0.002     0.003     0.004     20.51%
+   close                 2      0     0.002     0.001     0.001     0.001      0.00%
+
+`
+
+const testPerfScript = `             cat 12345 [002]  1234.567890: raw_syscalls:sys_enter: NR 0 (3, 7ffc5b0d8a10, 20000, 0, 0, 0)
+             cat 12345 [002]  1234.567891: raw_syscalls:sys_enter: NR 0 (3, 7ffc5b0d8a10, 20000, 0, 0, 0)
+             cat 12345 [002]  1234.567892: raw_syscalls:sys_enter: NR 99999 (0, 0, 0, 0, 0, 0)
+             cat 12345 [002]  1234.567893: syscalls:sys_enter_close: fd: 0x00000003
+             cat 12345 [002]  1234.567894: syscalls:sys_enter_getpid:
+`
+
+func TestReadPerfTrace(t *testing.T) {
+	trace, err := ReadPerfTrace(strings.NewReader(testPerfTrace), "x86_64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SyscallProfile{"brk": 1, "openat": 1, "read": 1, "exit_group": 1, "mmap": 3, "close": 2}
+	if !reflect.DeepEqual(trace.Profile, want) {
+		t.Fatalf("expected %v, got %v", want, trace.Profile)
+	}
+
+	conditions := trace.conditions("read", 1)
+	if len(conditions) != 1 || len(conditions[0]) != 3 || conditions[0][0].Value != 3 || conditions[0][2].Value != 832 {
+		t.Errorf("unexpected read conditions %v", conditions)
+	}
+}
+
+func TestReadPerfScript(t *testing.T) {
+	trace, err := ReadPerfTrace(strings.NewReader(testPerfScript), "x86_64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SyscallProfile{"read": 2, "close": 1, "getpid": 1}
+	if !reflect.DeepEqual(trace.Profile, want) {
+		t.Fatalf("expected %v, got %v", want, trace.Profile)
+	}
+
+	conditions := trace.conditions("read", 1)
+	if len(conditions) != 1 || len(conditions[0]) != 6 || conditions[0][2].Value != 0x20000 {
+		t.Errorf("unexpected read conditions %v", conditions)
+	}
+	if conditions = trace.conditions("close", 1); len(conditions) != 1 || conditions[0][0].Value != 3 {
+		t.Errorf("unexpected close conditions %v", conditions)
+	}
+}
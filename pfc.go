@@ -0,0 +1,542 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+var pfcActionNames = map[Action]string{
+	ActionKillThread:  "KILL",
+	ActionKillProcess: "KILL_PROCESS",
+	ActionTrap:        "TRAP",
+	ActionErrno:       "ERRNO",
+	ActionTrace:       "TRACE",
+	ActionLog:         "LOG",
+	ActionAllow:       "ALLOW",
+	ActionUserNotify:  "NOTIFY",
+}
+
+var pfcOperators = map[Operation]string{
+	Equal:          "==",
+	NotEqual:       "!=",
+	GreaterThan:    ">",
+	LessThan:       "<",
+	GreaterOrEqual: ">=",
+	LessOrEqual:    "<=",
+}
+
+// WritePFC writes the policy as pseudo filter code (PFC), the human readable
+// form of a filter that libseccomp's seccomp_export_pfc produces. Rules are
+// written in the order in which the assembled filter checks them and
+// argument comparisons use the full 64-bit values.
+func (p *Policy) WritePFC(w io.Writer) error {
+	if _, err := p.Assemble(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "#\n# pseudo filter code start\n#\n")
+	fmt.Fprintf(bw, "# filter for arch %s (%d)\n", p.arch.Name, uint32(p.arch.ID))
+	fmt.Fprintf(bw, "if ($arch == %d)\n", uint32(p.arch.ID))
+	if p.arch.ID == arch.X86_64.ID {
+		fmt.Fprint(bw, "  # x32 syscalls\n")
+		fmt.Fprintf(bw, "  if ($syscall >= %d)\n", arch.X32.SeccompMask)
+		fmt.Fprintf(bw, "    action %s;\n", pfcAction(ActionErrno|Action(errnoENOSYS)))
+	}
+
+	for _, group := range p.groups() {
+		action := pfcAction(group.Action)
+		for _, name := range group.Names {
+			nr, found := p.arch.SyscallNumber(name)
+			if !found {
+				return fmt.Errorf("unknown syscall %v on %v", name, p.arch.Name)
+			}
+			fmt.Fprintf(bw, "  # filter for syscall %q (%d)\n", name, nr)
+			fmt.Fprintf(bw, "  if ($syscall == %d)\n", nr|p.arch.SeccompMask)
+			fmt.Fprintf(bw, "    action %s;\n", action)
+		}
+		for _, nc := range group.NamesWithCondtions {
+			nr, found := p.arch.SyscallNumber(nc.Name)
+			if !found {
+				return fmt.Errorf("unknown syscall %v on %v", nc.Name, p.arch.Name)
+			}
+			fmt.Fprintf(bw, "  # filter for syscall %q (%d)\n", nc.Name, nr)
+			fmt.Fprintf(bw, "  if ($syscall == %d)\n", nr|p.arch.SeccompMask)
+			indent := "    "
+			for _, c := range nc.Conditions {
+				fmt.Fprintf(bw, "%sif (%s)\n", indent, pfcCondition(c))
+				indent += "  "
+			}
+			fmt.Fprintf(bw, "%saction %s;\n", indent, action)
+		}
+	}
+
+	fmt.Fprint(bw, "  # default action\n")
+	fmt.Fprintf(bw, "  action %s;\n", pfcAction(p.DefaultAction))
+	fmt.Fprint(bw, "# invalid architecture action\n")
+	fmt.Fprintf(bw, "action %s;\n", pfcAction(p.DefaultAction))
+	fmt.Fprint(bw, "#\n# pseudo filter code end\n#\n")
+	return bw.Flush()
+}
+
+// pfcAction returns the PFC name of the action, such as ERRNO(1).
+func pfcAction(a Action) string {
+	name, found := pfcActionNames[a&actionMask]
+	if !found {
+		return fmt.Sprintf("0x%08x", uint32(a))
+	}
+	switch a & actionMask {
+	case ActionErrno, ActionTrace:
+		return fmt.Sprintf("%s(%d)", name, a.returnValue()&^actionMask)
+	}
+	return name
+}
+
+// pfcCondition returns the comparison of the condition, such as
+// "$a1 & 0x80000 == 0".
+func pfcCondition(c Condition) string {
+	arg := fmt.Sprintf("$a%d", c.Argument)
+	switch c.Operation {
+	case BitsSet:
+		return fmt.Sprintf("%s & %#x != 0", arg, c.Value)
+	case BitsNotSet:
+		return fmt.Sprintf("%s & %#x == 0", arg, c.Value)
+	}
+	op, found := pfcOperators[c.Operation]
+	if !found {
+		op = strings.ToLower(string(c.Operation))
+	}
+	return fmt.Sprintf("%s %s %#x", arg, op, c.Value)
+}
+
+// PFCOptions configure ReadPFC.
+type PFCOptions struct {
+	// Arch is the architecture of the policy. It defaults to the arch of the
+	// first $arch check of the file, or to the native architecture if there
+	// is none.
+	Arch string
+}
+
+var pfcJumps = map[string]bpf.JumpTest{
+	"==": bpf.JumpEqual,
+	"!=": bpf.JumpNotEqual,
+	">":  bpf.JumpGreaterThan,
+	"<":  bpf.JumpLessThan,
+	">=": bpf.JumpGreaterOrEqual,
+	"<=": bpf.JumpLessOrEqual,
+}
+
+// ReadPFC reads pseudo filter code, as written by WritePFC or by libseccomp's
+// seccomp_export_pfc. The statements are compiled to a BPF program, from
+// which the policy is recovered with Decompile. Comparisons of the 32-bit
+// halves of an argument ($a0.hi32, $a0.lo32) are supported as well as full
+// 64-bit comparisons. Masked arguments can only be compared with == and !=.
+func ReadPFC(r io.Reader, opts PFCOptions) (*Policy, error) {
+	var lines []pfcLine
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed = strings.TrimSpace(trimmed); trimmed == "" {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		lines = append(lines, pfcLine{number: n, indent: indent, text: trimmed})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("empty pseudo filter code")
+	}
+
+	parser := &pfcParser{lines: lines}
+	stmts, err := parser.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(lines) {
+		l := lines[parser.pos]
+		return nil, fmt.Errorf("line %d: unexpected indentation", l.number)
+	}
+
+	c := &pfcCompiler{prog: NewProgram()}
+	through, err := c.block(stmts)
+	if err != nil {
+		return nil, err
+	}
+	if through {
+		return nil, errors.New("pseudo filter code does not end with an action")
+	}
+	insts, err := c.prog.Assemble()
+	if err != nil {
+		return nil, err
+	}
+
+	var info *arch.Info
+	if opts.Arch != "" || len(ProgramArches(insts)) == 0 {
+		if info, err = arch.GetInfo(opts.Arch); err != nil {
+			return nil, err
+		}
+	}
+	d, err := Decompile(insts, info)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Unresolved) > 0 {
+		return nil, fmt.Errorf("the checks of %v cannot be expressed as conditions", strings.Join(d.Unresolved, ", "))
+	}
+	return d.Policy, nil
+}
+
+type pfcLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// pfcStmt is an if statement or, if cond is empty, an action.
+type pfcStmt struct {
+	cond   string
+	then   []pfcStmt
+	orElse []pfcStmt
+	action Action
+	line   int
+}
+
+type pfcParser struct {
+	lines []pfcLine
+	pos   int
+}
+
+// block parses the statements at the indentation.
+func (p *pfcParser) block(indent int) ([]pfcStmt, error) {
+	var stmts []pfcStmt
+	for p.pos < len(p.lines) && p.lines[p.pos].indent >= indent {
+		l := p.lines[p.pos]
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.number)
+		}
+		p.pos++
+
+		switch {
+		case strings.HasPrefix(l.text, "action "):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(l.text, "action "), ";"))
+			action, err := parsePFCAction(name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", l.number, err)
+			}
+			stmts = append(stmts, pfcStmt{action: action, line: l.number})
+		case strings.HasPrefix(l.text, "if ") || strings.HasPrefix(l.text, "if("):
+			s := pfcStmt{cond: strings.TrimSpace(strings.TrimPrefix(l.text, "if")), line: l.number}
+			var err error
+			if s.then, err = p.nested(l); err != nil {
+				return nil, err
+			}
+			if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && p.lines[p.pos].text == "else" {
+				p.pos++
+				if s.orElse, err = p.nested(p.lines[p.pos-1]); err != nil {
+					return nil, err
+				}
+			}
+			stmts = append(stmts, s)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", l.number, l.text)
+		}
+	}
+	return stmts, nil
+}
+
+// nested parses the statements that are indented below the line.
+func (p *pfcParser) nested(l pfcLine) ([]pfcStmt, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= l.indent {
+		return nil, fmt.Errorf("line %d: %q has no statements", l.number, l.text)
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+func parsePFCAction(name string) (Action, error) {
+	for action, n := range pfcActionNames {
+		if n == name {
+			return action, nil
+		}
+	}
+	if name == "KILL_THREAD" {
+		return ActionKillThread, nil
+	}
+	if open := strings.IndexByte(name, '('); open > 0 && strings.HasSuffix(name, ")") {
+		var action Action
+		switch name[:open] {
+		case "ERRNO":
+			action = ActionErrno
+		case "TRACE":
+			action = ActionTrace
+		case "TRAP":
+			action = ActionTrap
+		default:
+			return 0, fmt.Errorf("unknown action %q", name)
+		}
+		value, err := strconv.ParseUint(name[open+1:len(name)-1], 0, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid action %q: %w", name, err)
+		}
+		return action | Action(value), nil
+	}
+	if value, err := strconv.ParseUint(name, 0, 32); err == nil {
+		return Action(value), nil
+	}
+	return 0, fmt.Errorf("unknown action %q", name)
+}
+
+// pfcCompiler compiles statements into a BPF program. A condition jumps to
+// the then statements or to the else statements, and both continue with the
+// statements that follow the if.
+type pfcCompiler struct {
+	prog Program
+}
+
+// block compiles the statements and returns true if the program can continue
+// after them.
+func (c *pfcCompiler) block(stmts []pfcStmt) (bool, error) {
+	for _, s := range stmts {
+		if s.cond == "" {
+			c.prog.Ret(s.action)
+			return false, nil
+		}
+
+		thenLabel, elseLabel, after := c.prog.NewLabel(), c.prog.NewLabel(), c.prog.NewLabel()
+		if err := c.condition(s.cond, thenLabel, elseLabel); err != nil {
+			return false, fmt.Errorf("line %d: %w", s.line, err)
+		}
+		c.prog.SetLabel(thenLabel)
+		thenThrough, err := c.block(s.then)
+		if err != nil {
+			return false, err
+		}
+		if thenThrough && len(s.orElse) > 0 {
+			c.jump(after, after)
+		}
+		c.prog.SetLabel(elseLabel)
+		elseThrough, err := c.block(s.orElse)
+		if err != nil {
+			return false, err
+		}
+		c.prog.SetLabel(after)
+		if !thenThrough && !elseThrough {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// jump inserts a jump that does not depend on the syscall. The syscall number
+// is loaded so that the decompiler can evaluate it.
+func (c *pfcCompiler) jump(trueLabel, falseLabel Label) {
+	c.prog.LdNr()
+	c.prog.JmpIf(bpf.JumpGreaterOrEqual, 0, trueLabel, falseLabel)
+}
+
+// condition compiles a condition such as "($a1 & 0x80000 == 0)".
+func (c *pfcCompiler) condition(cond string, trueLabel, falseLabel Label) error {
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(cond))
+	var mask uint64 = math.MaxUint64
+	if len(fields) == 5 && fields[1] == "&" {
+		var err error
+		if mask, err = strconv.ParseUint(fields[2], 0, 64); err != nil {
+			return fmt.Errorf("invalid mask %q", fields[2])
+		}
+		fields = append(fields[:1], fields[3:]...)
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid condition %q", cond)
+	}
+	field, op, valueStr := fields[0], fields[1], fields[2]
+	jump, found := pfcJumps[op]
+	if !found {
+		return fmt.Errorf("unknown operator %q", op)
+	}
+	value, err := strconv.ParseUint(valueStr, 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %q", valueStr)
+	}
+	if mask != math.MaxUint64 && op != "==" && op != "!=" {
+		return errors.New("masked arguments can only be compared with == and !=")
+	}
+	if op == "!=" {
+		// Compile the equality with the labels swapped.
+		op, trueLabel, falseLabel = "==", falseLabel, trueLabel
+	}
+
+	switch {
+	case field == "$arch" || field == "$syscall":
+		if mask != math.MaxUint64 || value > math.MaxUint32 {
+			return fmt.Errorf("invalid condition %q", cond)
+		}
+		if field == "$arch" {
+			c.prog.instructions = append(c.prog.instructions, bpf.LoadAbsolute{Off: archOffset, Size: sizeOfUint32})
+		} else {
+			c.prog.LdNr()
+		}
+		if op == "==" {
+			jump = bpf.JumpEqual
+		}
+		c.prog.JmpIf(jump, uint32(value), trueLabel, falseLabel)
+		return nil
+	case !strings.HasPrefix(field, "$a"):
+		return fmt.Errorf("unknown field %q", field)
+	}
+
+	name, half, _ := strings.Cut(field[2:], ".")
+	arg, err := strconv.ParseUint(name, 10, 32)
+	if err != nil || arg > 5 {
+		return fmt.Errorf("unknown field %q", field)
+	}
+	var words []pfcWord
+	switch half {
+	case "":
+		words = []pfcWord{
+			{hi: true, mask: uint32(mask >> 32), value: uint32(value >> 32)},
+			{mask: uint32(mask), value: uint32(value)},
+		}
+	case "hi32", "lo32":
+		if mask != math.MaxUint64 && mask > math.MaxUint32 || value > math.MaxUint32 {
+			return fmt.Errorf("invalid condition %q", cond)
+		}
+		words = []pfcWord{{hi: half == "hi32", mask: uint32(mask), value: uint32(value)}}
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+
+	if op == "==" {
+		return c.equal(uint32(arg), words, trueLabel, falseLabel)
+	}
+	if len(words) == 1 {
+		c.load(uint32(arg), words[0].hi)
+		c.prog.JmpIf(jump, words[0].value, trueLabel, falseLabel)
+		return nil
+	}
+
+	// a < v is !(a >= v) and a <= v is !(a > v).
+	switch jump {
+	case bpf.JumpLessThan:
+		jump, trueLabel, falseLabel = bpf.JumpGreaterOrEqual, falseLabel, trueLabel
+	case bpf.JumpLessOrEqual:
+		jump, trueLabel, falseLabel = bpf.JumpGreaterThan, falseLabel, trueLabel
+	}
+	hi, lo := words[0].value, words[1].value
+	equal, compareLo := c.prog.NewLabel(), c.prog.NewLabel()
+	c.prog.LdHi(uint32(arg))
+	c.prog.JmpIf(bpf.JumpGreaterThan, hi, trueLabel, equal)
+	c.prog.SetLabel(equal)
+	c.prog.JmpIf(bpf.JumpEqual, hi, compareLo, falseLabel)
+	c.prog.SetLabel(compareLo)
+	c.prog.LdLo(uint32(arg))
+	c.prog.JmpIf(jump, lo, trueLabel, falseLabel)
+	return nil
+}
+
+// pfcWord is a masked comparison of a 32-bit word of an argument.
+type pfcWord struct {
+	hi          bool
+	mask, value uint32
+}
+
+type pfcCheck struct {
+	cond bpf.JumpTest
+	val  uint32
+	want bool
+}
+
+// equal compiles the masked equality of the words. The bits of the mask that
+// are not in the value must not be set and the bits of the value must be set,
+// which the decompiler can express as conditions.
+func (c *pfcCompiler) equal(arg uint32, words []pfcWord, trueLabel, falseLabel Label) error {
+	type wordChecks struct {
+		hi     bool
+		checks []pfcCheck
+	}
+	var all []wordChecks
+	for _, w := range words {
+		if w.value&^w.mask != 0 {
+			// The masked word can never be equal to the value.
+			c.jump(falseLabel, trueLabel)
+			return nil
+		}
+		var checks []pfcCheck
+		switch {
+		case w.mask == math.MaxUint32:
+			checks = []pfcCheck{{bpf.JumpEqual, w.value, true}}
+		default:
+			if w.mask&^w.value != 0 {
+				checks = append(checks, pfcCheck{bpf.JumpBitsSet, w.mask &^ w.value, false})
+			}
+			for v := w.value; v != 0; v &= v - 1 {
+				checks = append(checks, pfcCheck{bpf.JumpBitsSet, v & -v, true})
+			}
+		}
+		if len(checks) > 0 {
+			all = append(all, wordChecks{hi: w.hi, checks: checks})
+		}
+	}
+	if len(all) == 0 {
+		c.jump(trueLabel, falseLabel)
+		return nil
+	}
+
+	for i, w := range all {
+		c.load(arg, w.hi)
+		for j, check := range w.checks {
+			next := trueLabel
+			last := i == len(all)-1 && j == len(w.checks)-1
+			if !last {
+				next = c.prog.NewLabel()
+			}
+			if check.want {
+				c.prog.JmpIf(check.cond, check.val, next, falseLabel)
+			} else {
+				c.prog.JmpIf(check.cond, check.val, falseLabel, next)
+			}
+			if !last {
+				c.prog.SetLabel(next)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *pfcCompiler) load(arg uint32, hi bool) {
+	if hi {
+		c.prog.LdHi(arg)
+	} else {
+		c.prog.LdLo(arg)
+	}
+}
@@ -0,0 +1,178 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+const testPFC = `#
+# pseudo filter code start
+#
+# filter for arch aarch64 (3221225655)
+if ($arch == 3221225655)
+  # filter for syscall "read" (63)
+  if ($syscall == 63)
+    action ALLOW;
+  # filter for syscall "socket" (198)
+  if ($syscall == 198)
+    if ($a0 == 0x1)
+      if ($a1 & 0x80000 == 0)
+        action ALLOW;
+  # filter for syscall "ptrace" (117)
+  if ($syscall == 117)
+    action KILL_PROCESS;
+  # default action
+  action ERRNO(1);
+# invalid architecture action
+action ERRNO(1);
+#
+# pseudo filter code end
+#
+`
+
+func TestPolicyWritePFC(t *testing.T) {
+	policy := Policy{
+		DefaultAction: ActionErrno,
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionAllow,
+				Names:  []string{"read"},
+				NamesWithCondtions: []NameWithConditions{
+					{
+						Name: "socket",
+						Conditions: ArgumentConditions{
+							{Argument: 0, Operation: Equal, Value: 1},
+							{Argument: 1, Operation: BitsNotSet, Value: 0x80000},
+						},
+					},
+				},
+			},
+			{Action: ActionKillProcess, Names: []string{"ptrace"}},
+		},
+	}
+	if err := policy.SetArch("aarch64"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := policy.WritePFC(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != testPFC {
+		t.Errorf("unexpected PFC:\n%s", buf.String())
+	}
+}
+
+// libseccompPFC is written in the style of seccomp_export_pfc, which compares
+// the halves of the arguments.
+const libseccompPFC = `#
+# pseudo filter code start
+#
+# filter for arch x86_64 (3221225534)
+if ($arch == 3221225534)
+  # filter for syscall "openat" (257) [priority: 65533]
+  if ($syscall == 257)
+    if ($a2.hi32 & 0x00000000 == 0)
+      if ($a2.lo32 & 0x00000040 == 0)
+        action ALLOW;
+  # filter for syscall "read" (0) [priority: 65535]
+  if ($syscall == 0)
+    if ($a0.hi32 > 0)
+      action ALLOW;
+    else
+      if ($a0.hi32 == 0)
+        if ($a0.lo32 >= 3)
+          action ALLOW;
+    action ERRNO(9);
+  # default action
+  action KILL_PROCESS;
+# invalid architecture action
+action KILL;
+#
+# pseudo filter code end
+#
+`
+
+func TestReadPFC(t *testing.T) {
+	policy, err := ReadPFC(strings.NewReader(testPFC), PFCOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.arch.Name != "aarch64" {
+		t.Fatalf("expected the arch of the file, got %v", policy.arch.Name)
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.AARCH64, name)), Arch: uint32(arch.AARCH64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	eperm := ActionErrno | Action(errnoEPERM)
+	simulateSyscalls(t, policy, []SeccompTest{
+		{data("read"), ActionAllow},
+		{data("socket", 1, 0), ActionAllow},
+		{data("socket", 1, 0x80000), eperm},
+		{data("socket", 2, 0), eperm},
+		{data("ptrace"), ActionKillProcess},
+		{data("write"), eperm},
+	})
+}
+
+func TestReadPFCLibseccomp(t *testing.T) {
+	policy, err := ReadPFC(strings.NewReader(libseccompPFC), PFCOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{data("openat", 0, 0, 0x2), ActionAllow},
+		{data("openat", 0, 0, 0x42), ActionKillProcess},
+		{data("read", 3), ActionAllow},
+		{data("read", 1<<32), ActionAllow},
+		{data("read", 2), ActionErrno | Action(9)},
+		{data("write"), ActionKillProcess},
+	})
+}
+
+func TestReadPFCErrors(t *testing.T) {
+	for _, pfc := range []string{
+		"",
+		"if ($syscall == 0)\n  action ALLOW;",
+		"action MAYBE;",
+		"if ($syscall == 0)\naction ALLOW;",
+		"if ($a0 & 0x3 > 1)\n  action ALLOW;\naction KILL;",
+		"if ($a0 & 0x3 == 1)\n  action ALLOW;\naction KILL;",
+		"if ($a9 == 1)\n  action ALLOW;\naction KILL;",
+		"if ($syscall ~ 1)\n  action ALLOW;\naction KILL;",
+		"  action ALLOW;\naction KILL;",
+	} {
+		if _, err := ReadPFC(strings.NewReader(pfc), PFCOptions{Arch: "x86_64"}); err == nil {
+			t.Errorf("expected error for %q", pfc)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SyscallProfile maps syscall names to the number of times they were observed
+// (e.g. exported from perf or a previous run). It is used to order the
+// comparisons within a syscall group so that the hottest syscalls are matched
+// with the fewest instructions.
+type SyscallProfile map[string]uint64
+
+// ReadSyscallProfile reads a profile from r. Each line contains a syscall
+// name and a count separated by whitespace. Empty lines and lines starting
+// with '#' are ignored.
+func ReadSyscallProfile(r io.Reader) (SyscallProfile, error) {
+	profile := SyscallProfile{}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid profile line %d: expected '<syscall> <count>'", n)
+		}
+		count, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid count on profile line %d: %w", n, err)
+		}
+		profile[fields[0]] += count
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// sortByFrequency orders the syscalls from most to least frequently used.
+// Syscalls not found in the profile keep their relative order at the end.
+// Reordering within a group does not change the filter's decisions because
+// each syscall number appears only once in a group.
+func (g *SyscallGroup) sortByFrequency(syscalls []SyscallWithConditions) {
+	if len(g.profile) == 0 {
+		return
+	}
+
+	freq := func(num uint32) uint64 {
+		return g.profile[g.arch.SyscallName(int(num)&^g.arch.SeccompMask)]
+	}
+	sort.SliceStable(syscalls, func(i, j int) bool {
+		return freq(syscalls[i].Num) > freq(syscalls[j].Num)
+	})
+}
@@ -0,0 +1,134 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestReadSyscallProfile(t *testing.T) {
+	profile, err := ReadSyscallProfile(strings.NewReader(`
+# syscall count
+read 100
+write 25
+read 5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile["read"] != 105 || profile["write"] != 25 || len(profile) != 2 {
+		t.Errorf("unexpected profile: %v", profile)
+	}
+
+	if _, err = ReadSyscallProfile(strings.NewReader("read ten")); err == nil {
+		t.Error("expected error for invalid count")
+	}
+}
+
+func TestPolicyAssembleProfile(t *testing.T) {
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionKillProcess,
+		Syscalls: []SyscallGroup{
+			{
+				Names:  []string{"execve", "openat", "read", "futex"},
+				Action: ActionAllow,
+			},
+		},
+		Profile: SyscallProfile{"futex": 1000, "read": 500, "openat": 1},
+	}
+
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []uint32
+	for _, inst := range insts {
+		if jump, ok := inst.(bpf.JumpIf); ok && jump.Cond == bpf.JumpEqual && jump.Val < uint32(arch.X32.SeccompMask) {
+			order = append(order, jump.Val)
+		}
+	}
+
+	expected := []uint32{202 /* futex */, 0 /* read */, 257 /* openat */, 59 /* execve */}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v comparisons, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected comparison order %v, got %v", expected, order)
+		}
+	}
+
+	if err = policy.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkPolicyProfile(b *testing.B) {
+	var names []string
+	for nr := 0; nr < 300; nr++ {
+		if name := arch.X86_64.SyscallName(nr); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	profile := SyscallProfile{}
+	var mix []SeccompData
+	for _, s := range DefaultBenchmarkMix {
+		profile[s.Name] = uint64(s.Weight)
+		for i := 0; i < s.Weight; i++ {
+			mix = append(mix, SeccompData{
+				NR:   int32(syscallNumber(arch.X86_64, s.Name)),
+				Arch: uint32(arch.X86_64.ID),
+			})
+		}
+	}
+
+	for _, p := range []SyscallProfile{nil, profile} {
+		b.Run("profile="+strconv.FormatBool(p != nil), func(b *testing.B) {
+			policy := &Policy{
+				arch:          arch.X86_64,
+				DefaultAction: ActionErrno,
+				Syscalls:      []SyscallGroup{{Names: names, Action: ActionAllow}},
+				Profile:       p,
+			}
+			insts, err := policy.Assemble()
+			if err != nil {
+				b.Fatal(err)
+			}
+			emulator, err := NewEmulator(insts)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err = emulator.Run(mix[i%len(mix)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
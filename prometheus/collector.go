@@ -0,0 +1,213 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+	"github.com/elastic/go-seccomp-bpf/trap"
+)
+
+// Sources of the violations, used as the source label.
+const (
+	SourceNotify = "notify" // Notifications answered by a notify.Supervisor.
+	SourceTrap   = "trap"   // Signals of a trap handler.
+	SourceAudit  = "audit"  // SECCOMP records of the audit log.
+)
+
+// Options configure a Collector.
+type Options struct {
+	// Namespace of the metric names, seccomp if empty.
+	Namespace string
+	// ConstLabels are added to all metrics.
+	ConstLabels prom.Labels
+}
+
+// Collector is a prometheus.Collector of seccomp metrics:
+//
+//   - seccomp_violations_total{source,syscall,action}: syscalls that a
+//     filter did not allow. The action is the decision of the supervisor for
+//     notifications, trap for trapped syscalls, and the action of the record
+//     for audit records.
+//   - seccomp_notify_handler_duration_seconds{syscall}: time spent in the
+//     handlers of a supervisor.
+//   - seccomp_notify_errors_total{syscall,kind}: handler and memory read
+//     failures of a supervisor.
+//   - seccomp_filter_instructions: size of the filter passed to SetFilter.
+//   - seccomp_confined and seccomp_filters: seccomp status of the process,
+//     read at collection time.
+//
+// A Collector implements notify.Metrics and is safe for concurrent use.
+type Collector struct {
+	violations   *prom.CounterVec
+	latency      *prom.HistogramVec
+	errors       *prom.CounterVec
+	instructions prom.Gauge
+	confined     *prom.Desc
+	filters      *prom.Desc
+
+	status func() (*seccomp.Status, error)
+}
+
+var _ notify.Metrics = (*Collector)(nil)
+
+// NewCollector returns a Collector. Register it with a prometheus.Registerer.
+func NewCollector(opts Options) *Collector {
+	ns := opts.Namespace
+	if ns == "" {
+		ns = "seccomp"
+	}
+	return &Collector{
+		violations: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   ns,
+			Name:        "violations_total",
+			Help:        "Syscalls that a seccomp filter did not allow.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"source", "syscall", "action"}),
+		latency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   ns,
+			Subsystem:   "notify",
+			Name:        "handler_duration_seconds",
+			Help:        "Time spent in the handlers of seccomp notifications.",
+			ConstLabels: opts.ConstLabels,
+			Buckets:     prom.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"syscall"}),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   ns,
+			Subsystem:   "notify",
+			Name:        "errors_total",
+			Help:        "Failures while handling seccomp notifications.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"syscall", "kind"}),
+		instructions: prom.NewGauge(prom.GaugeOpts{
+			Namespace:   ns,
+			Name:        "filter_instructions",
+			Help:        "Number of BPF instructions of the seccomp filter.",
+			ConstLabels: opts.ConstLabels,
+		}),
+		confined: prom.NewDesc(prom.BuildFQName(ns, "", "confined"),
+			"Whether seccomp is enabled for the process (1) or not (0).", nil, opts.ConstLabels),
+		filters: prom.NewDesc(prom.BuildFQName(ns, "", "filters"),
+			"Number of seccomp filters installed for the process.", nil, opts.ConstLabels),
+		status: seccomp.GetStatus,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	c.violations.Describe(ch)
+	c.latency.Describe(ch)
+	c.errors.Describe(ch)
+	c.instructions.Describe(ch)
+	ch <- c.confined
+	ch <- c.filters
+}
+
+// Collect implements prometheus.Collector. The status metrics are left out if
+// the seccomp status cannot be read, like on other operating systems.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	c.violations.Collect(ch)
+	c.latency.Collect(ch)
+	c.errors.Collect(ch)
+	c.instructions.Collect(ch)
+
+	status, err := c.status()
+	if err != nil {
+		return
+	}
+	var confined float64
+	if status.Confined() {
+		confined = 1
+	}
+	ch <- prom.MustNewConstMetric(c.confined, prom.GaugeValue, confined)
+	if status.Filters >= 0 {
+		ch <- prom.MustNewConstMetric(c.filters, prom.GaugeValue, float64(status.Filters))
+	}
+}
+
+// SetFilter sets the size of the filter that the process loads.
+func (c *Collector) SetFilter(f *seccomp.Filter) error {
+	insts, err := f.Policy.Assemble()
+	if err != nil {
+		return err
+	}
+	c.instructions.Set(float64(len(insts)))
+	return nil
+}
+
+// Trap returns a trap.Handler that counts the signal and then calls next, if
+// not nil.
+func (c *Collector) Trap(next trap.Handler) trap.Handler {
+	return func(s trap.Signal) {
+		c.violation(SourceTrap, s.Syscall, "trap")
+		if next != nil {
+			next(s)
+		}
+	}
+}
+
+// Audit counts a record of the audit log. Records of ActionAllow, which the
+// kernel emits only for FilterFlagLog, are ignored.
+func (c *Collector) Audit(r *seccomp.AuditRecord) {
+	action := r.Code.Base()
+	if action == seccomp.ActionAllow {
+		return
+	}
+	c.violation(SourceAudit, r.Syscall, action.String())
+}
+
+// NotificationReceived implements notify.Metrics. Notifications are counted
+// when they are decided.
+func (c *Collector) NotificationReceived(string) {}
+
+// Decided implements notify.Metrics.
+func (c *Collector) Decided(syscall string, decision notify.Decision) {
+	c.violation(SourceNotify, syscall, decision.String())
+}
+
+// HandlerLatency implements notify.Metrics.
+func (c *Collector) HandlerLatency(syscall string, d time.Duration) {
+	c.latency.WithLabelValues(syscallLabel(syscall)).Observe(d.Seconds())
+}
+
+// HandlerFailed implements notify.Metrics.
+func (c *Collector) HandlerFailed(syscall string, _ error) {
+	c.errors.WithLabelValues(syscallLabel(syscall), "handler").Inc()
+}
+
+// MemoryReadFailed implements notify.Metrics.
+func (c *Collector) MemoryReadFailed(syscall string, _ error) {
+	c.errors.WithLabelValues(syscallLabel(syscall), "memory").Inc()
+}
+
+func (c *Collector) violation(source, syscall, action string) {
+	c.violations.WithLabelValues(source, syscallLabel(syscall), action).Inc()
+}
+
+// syscallLabel returns the syscall label of a syscall name, which is empty
+// for unknown syscalls.
+func syscallLabel(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
@@ -0,0 +1,104 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/notify"
+	"github.com/elastic/go-seccomp-bpf/trap"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector(Options{})
+	c.status = func() (*seccomp.Status, error) {
+		return &seccomp.Status{Mode: seccomp.ModeFilter, Filters: 2}, nil
+	}
+
+	var trapped []string
+	handler := c.Trap(func(s trap.Signal) { trapped = append(trapped, s.Syscall) })
+	handler(trap.Signal{Syscall: "getppid"})
+	handler(trap.Signal{Syscall: "getppid"})
+	c.Trap(nil)(trap.Signal{})
+
+	c.Audit(&seccomp.AuditRecord{Syscall: "ptrace", Code: seccomp.ActionErrno | 1})
+	c.Audit(&seccomp.AuditRecord{Syscall: "uname", Code: seccomp.ActionAllow})
+
+	c.NotificationReceived("mount")
+	c.Decided("mount", notify.DecisionErrno)
+	c.HandlerLatency("mount", time.Millisecond)
+	c.HandlerFailed("mount", errors.New("failed"))
+
+	filter := &seccomp.Filter{Policy: seccomp.Policy{
+		DefaultAction: seccomp.ActionErrno,
+		Syscalls:      []seccomp.SyscallGroup{{Action: seccomp.ActionAllow, Names: []string{"read"}}},
+	}}
+	if err := c.SetFilter(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trapped) != 2 {
+		t.Errorf("expected the next handler to be called twice, got %v", trapped)
+	}
+
+	want := `
+# HELP seccomp_confined Whether seccomp is enabled for the process (1) or not (0).
+# TYPE seccomp_confined gauge
+seccomp_confined 1
+# HELP seccomp_filters Number of seccomp filters installed for the process.
+# TYPE seccomp_filters gauge
+seccomp_filters 2
+# HELP seccomp_notify_errors_total Failures while handling seccomp notifications.
+# TYPE seccomp_notify_errors_total counter
+seccomp_notify_errors_total{kind="handler",syscall="mount"} 1
+# HELP seccomp_violations_total Syscalls that a seccomp filter did not allow.
+# TYPE seccomp_violations_total counter
+seccomp_violations_total{action="errno",source="audit",syscall="ptrace"} 1
+seccomp_violations_total{action="errno",source="notify",syscall="mount"} 1
+seccomp_violations_total{action="trap",source="trap",syscall="getppid"} 2
+seccomp_violations_total{action="trap",source="trap",syscall="unknown"} 1
+`
+	names := []string{"seccomp_confined", "seccomp_filters", "seccomp_notify_errors_total", "seccomp_violations_total"}
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.ToFloat64(c.instructions); n == 0 {
+		t.Error("expected the size of the filter")
+	}
+	if n := testutil.CollectAndCount(c, "seccomp_notify_handler_duration_seconds"); n != 1 {
+		t.Errorf("expected one latency histogram, got %v", n)
+	}
+}
+
+func TestCollectorUnsupported(t *testing.T) {
+	c := NewCollector(Options{Namespace: "app"})
+	c.status = func() (*seccomp.Status, error) { return nil, errors.ErrUnsupported }
+
+	if n := testutil.CollectAndCount(c, "app_confined", "app_filters"); n != 0 {
+		t.Errorf("expected no status metrics, got %v", n)
+	}
+	if n := testutil.CollectAndCount(c, "app_filter_instructions"); n != 1 {
+		t.Errorf("expected the filter size gauge, got %v", n)
+	}
+}
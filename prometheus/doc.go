@@ -0,0 +1,25 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package prometheus exports seccomp metrics to Prometheus. A Collector counts
+// the syscalls that a filter rejected, as reported by a notify.Supervisor, a
+// trap handler, or records of the audit log, and reports the size of the
+// loaded filter and the seccomp status of the process.
+//
+// The package is a separate module so that go-seccomp-bpf does not depend on
+// the Prometheus client.
+package prometheus
@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// seccompMetadata is struct seccomp_metadata.
+// https://github.com/torvalds/linux/blob/v4.16/include/uapi/linux/ptrace.h#L69-L72
+type seccompMetadata struct {
+	FilterOff uint64
+	Flags     uint64
+}
+
+// GetProcessFilters attaches to the process with ptrace, retrieves its
+// installed seccomp filters, and detaches. It requires CAP_SYS_ADMIN and
+// Linux 4.4 (4.16 for the flags).
+func GetProcessFilters(pid int) ([]InstalledFilter, error) {
+	// All ptrace requests must be made by the thread that attached.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := unix.PtraceAttach(pid); err != nil {
+		return nil, fmt.Errorf("failed to attach to process %d: %w", pid, err)
+	}
+	defer unix.PtraceDetach(pid)
+
+	var status unix.WaitStatus
+	if _, err := unix.Wait4(pid, &status, unix.WALL, nil); err != nil {
+		return nil, fmt.Errorf("failed waiting for process %d to stop: %w", pid, err)
+	}
+
+	return GetTracedFilters(pid)
+}
+
+// GetTracedFilters retrieves the seccomp filters of a process that is
+// already traced and stopped by the calling thread.
+func GetTracedFilters(pid int) ([]InstalledFilter, error) {
+	var filters []InstalledFilter
+	for i := 0; ; i++ {
+		raw, err := ptraceSeccompGetFilter(pid, i)
+		if errors.Is(err, syscall.ENOENT) {
+			return filters, nil
+		}
+		if err != nil {
+			if i == 0 && errors.Is(err, syscall.EINVAL) {
+				// The process has no filters.
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get filter %d of process %d: %w", i, pid, err)
+		}
+
+		insts, allDecoded := bpf.Disassemble(raw)
+		if !allDecoded {
+			return nil, fmt.Errorf("failed to disassemble filter %d of process %d", i, pid)
+		}
+
+		filter := InstalledFilter{Index: i, Instructions: insts}
+		if flags, err := ptraceSeccompGetMetadata(pid, i); err == nil {
+			filter.Flags = FilterFlag(flags)
+		}
+		filters = append(filters, filter)
+	}
+}
+
+// ptraceSeccompGetFilter returns the raw program of the filter at index.
+func ptraceSeccompGetFilter(pid, index int) ([]bpf.RawInstruction, error) {
+	// The first call returns the number of instructions.
+	n, _, e := unix.Syscall6(unix.SYS_PTRACE, unix.PTRACE_SECCOMP_GET_FILTER,
+		uintptr(pid), uintptr(index), 0, 0, 0)
+	if e != 0 {
+		return nil, e
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	filter := make([]syscall.SockFilter, n)
+	_, _, e = unix.Syscall6(unix.SYS_PTRACE, unix.PTRACE_SECCOMP_GET_FILTER,
+		uintptr(pid), uintptr(index), uintptr(unsafe.Pointer(&filter[0])), 0, 0)
+	if e != 0 {
+		return nil, e
+	}
+
+	raw := make([]bpf.RawInstruction, 0, len(filter))
+	for _, f := range filter {
+		raw = append(raw, bpf.RawInstruction{Op: f.Code, Jt: f.Jt, Jf: f.Jf, K: f.K})
+	}
+	return raw, nil
+}
+
+// ptraceSeccompGetMetadata returns the flags of the filter at index.
+func ptraceSeccompGetMetadata(pid, index int) (uint64, error) {
+	md := seccompMetadata{FilterOff: uint64(index)}
+	_, _, e := unix.Syscall6(unix.SYS_PTRACE, unix.PTRACE_SECCOMP_GET_METADATA,
+		uintptr(pid), unsafe.Sizeof(md), uintptr(unsafe.Pointer(&md)), 0, 0)
+	if e != 0 {
+		return 0, e
+	}
+	return md.Flags, nil
+}
@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"sync"
+	"time"
+)
+
+// LogLimit configures a LogLimiter. The limits apply to each syscall on its
+// own, so a loop hitting one denied syscall does not hide the others.
+type LogLimit struct {
+	// Rate is the number of violations per second that are logged. Zero
+	// means no rate limit.
+	Rate float64 `config:"rate" json:"rate" yaml:"rate"`
+	// Burst is the number of violations that are logged at once before the
+	// rate applies. Defaults to 1.
+	Burst int `config:"burst" json:"burst" yaml:"burst"`
+	// Sample logs one of every Sample violations, before the rate limit is
+	// applied. Zero or one logs all violations.
+	Sample int `config:"sample" json:"sample" yaml:"sample"`
+}
+
+// LogLimiter decides which violations are logged, with a token bucket and
+// sampling per syscall. A nil LogLimiter allows all violations. It is safe for
+// concurrent use.
+type LogLimiter struct {
+	limit LogLimit
+	now   func() time.Time
+
+	mu       sync.Mutex
+	syscalls map[string]*logBucket
+	dropped  uint64
+}
+
+type logBucket struct {
+	tokens float64
+	last   time.Time
+	seen   uint64
+}
+
+// NewLogLimiter returns a LogLimiter with the given limits.
+func NewLogLimiter(limit LogLimit) *LogLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &LogLimiter{limit: limit, now: time.Now, syscalls: map[string]*logBucket{}}
+}
+
+// Allow reports whether a violation of the syscall is logged. The name is
+// empty for unknown syscalls, which share their limits.
+func (l *LogLimiter) Allow(syscall string) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.syscalls[syscall]
+	if b == nil {
+		b = &logBucket{tokens: float64(l.limit.Burst), last: now}
+		l.syscalls[syscall] = b
+	}
+	b.seen++
+	if l.limit.Sample > 1 && (b.seen-1)%uint64(l.limit.Sample) != 0 {
+		l.dropped++
+		return false
+	}
+	if l.limit.Rate > 0 {
+		b.tokens = min(float64(l.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+		b.last = now
+		if b.tokens < 1 {
+			l.dropped++
+			return false
+		}
+		b.tokens--
+	}
+	return true
+}
+
+// Dropped returns the number of violations that were not logged.
+func (l *LogLimiter) Dropped() uint64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dropped
+}
+
+// limitedSink is returned by LimitSink.
+type limitedSink struct {
+	sink    ViolationSink
+	limiter *LogLimiter
+}
+
+// LimitSink returns a sink that writes the violations that the limiter
+// allows to sink and drops the others.
+func LimitSink(sink ViolationSink, l *LogLimiter) ViolationSink {
+	return &limitedSink{sink: sink, limiter: l}
+}
+
+func (s *limitedSink) Write(v Violation) error {
+	if !s.limiter.Allow(v.Syscall) {
+		return nil
+	}
+	return s.sink.Write(v)
+}
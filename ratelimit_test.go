@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogLimiterRate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewLogLimiter(LogLimit{Rate: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	allowed := func(name string, n int) int {
+		var count int
+		for i := 0; i < n; i++ {
+			if l.Allow(name) {
+				count++
+			}
+		}
+		return count
+	}
+
+	if n := allowed("openat", 10); n != 3 {
+		t.Errorf("expected the burst of 3 violations, got %d", n)
+	}
+	if n := allowed("mount", 1); n != 1 {
+		t.Errorf("expected other syscalls to have their own limit, got %d", n)
+	}
+	now = now.Add(time.Second)
+	if n := allowed("openat", 10); n != 2 {
+		t.Errorf("expected 2 violations after a second, got %d", n)
+	}
+	now = now.Add(time.Hour)
+	if n := allowed("openat", 10); n != 3 {
+		t.Errorf("expected no more than the burst after an hour, got %d", n)
+	}
+	if d := l.Dropped(); d != 22 {
+		t.Errorf("expected 22 dropped violations, got %d", d)
+	}
+}
+
+func TestLogLimiterSample(t *testing.T) {
+	l := NewLogLimiter(LogLimit{Sample: 3})
+	var got []bool
+	for i := 0; i < 7; i++ {
+		got = append(got, l.Allow(""))
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestLogLimiterNil(t *testing.T) {
+	var l *LogLimiter
+	if !l.Allow("openat") || l.Dropped() != 0 {
+		t.Error("expected a nil limiter to allow all violations")
+	}
+}
+
+type testSink struct {
+	violations []Violation
+}
+
+func (s *testSink) Write(v Violation) error {
+	s.violations = append(s.violations, v)
+	return nil
+}
+
+func TestLimitSink(t *testing.T) {
+	sink := &testSink{}
+	limited := LimitSink(sink, NewLogLimiter(LogLimit{Sample: 2}))
+	for _, name := range []string{"ptrace", "ptrace", "mount"} {
+		if err := limited.Write(Violation{Syscall: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sink.violations) != 2 || sink.violations[0].Syscall != "ptrace" || sink.violations[1].Syscall != "mount" {
+		t.Errorf("unexpected violations %+v", sink.violations)
+	}
+
+	failing := LimitSink(failingSink{}, nil)
+	if err := failing.Write(Violation{}); err == nil {
+		t.Error("expected the error of the sink")
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(Violation) error { return errors.New("failed") }
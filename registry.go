@@ -0,0 +1,247 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrProfileNotFound is returned when a profile is not registered.
+var ErrProfileNotFound = errors.New("seccomp profile not found")
+
+// DefaultRegistry is the registry used by Register, LookupProfile, and
+// MergeProfiles.
+var DefaultRegistry = NewRegistry()
+
+// Registry holds named, versioned policies so that packages can publish the
+// profiles they need and a program can assemble its filter from them at
+// startup. Profiles are referenced as "name" or "name@version". It is safe
+// for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	profiles map[string]map[string]Policy // Name to version to policy.
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{profiles: map[string]map[string]Policy{}}
+}
+
+// Register adds the policy as "name" or "name@version". It returns an error
+// if the reference is invalid, the policy does not validate, or the version
+// is already registered.
+func (r *Registry) Register(ref string, p Policy) error {
+	name, version, err := parseProfileRef(ref)
+	if err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid profile %v: %w", ref, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, found := r.profiles[name]
+	if !found {
+		versions = map[string]Policy{}
+		r.profiles[name] = versions
+	}
+	if _, found := versions[version]; found {
+		return fmt.Errorf("seccomp profile %v is already registered", ref)
+	}
+	versions[version] = p.clone()
+	return nil
+}
+
+// Lookup returns a copy of the profile. Without a version it returns the
+// highest registered version, where an unversioned profile is the lowest.
+func (r *Registry) Lookup(ref string) (Policy, error) {
+	name, version, err := parseProfileRef(ref)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.profiles[name]
+	if version == "" && !strings.Contains(ref, "@") {
+		if sorted := sortedVersions(versions); len(sorted) > 0 {
+			version = sorted[len(sorted)-1]
+		}
+	}
+	p, found := versions[version]
+	if !found {
+		return Policy{}, fmt.Errorf("%w: %v", ErrProfileNotFound, ref)
+	}
+	return p.clone(), nil
+}
+
+// Names returns the sorted names of the registered profiles.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.profiles))
+	for name := range r.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Versions returns the registered versions of a profile from lowest to
+// highest. An unversioned profile is returned as "".
+func (r *Registry) Versions(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedVersions(r.profiles[name])
+}
+
+// Merge looks up the profiles and merges them into one policy. The groups
+// are concatenated in the given order, so for a syscall named by several
+// profiles the first one decides. All profiles must have the same default
+// action. The Go runtime syscalls are included if any profile includes them
+// and the syscall profiles are summed.
+func (r *Registry) Merge(refs ...string) (Policy, error) {
+	if len(refs) == 0 {
+		return Policy{}, errors.New("no profiles to merge")
+	}
+
+	var merged Policy
+	for i, ref := range refs {
+		p, err := r.Lookup(ref)
+		if err != nil {
+			return Policy{}, err
+		}
+		if i == 0 {
+			merged.DefaultAction = p.DefaultAction
+		} else if p.DefaultAction != merged.DefaultAction {
+			return Policy{}, fmt.Errorf("profile %v has default action %v but %v has %v",
+				ref, p.DefaultAction, refs[0], merged.DefaultAction)
+		}
+
+		merged.Syscalls = append(merged.Syscalls, p.Syscalls...)
+		merged.IncludeGoRuntime = merged.IncludeGoRuntime || p.IncludeGoRuntime
+		for name, n := range p.Profile {
+			if merged.Profile == nil {
+				merged.Profile = SyscallProfile{}
+			}
+			merged.Profile[name] += n
+		}
+	}
+	return merged, nil
+}
+
+// Register adds the policy to DefaultRegistry. It is meant to be called from
+// init functions and panics if the policy can not be registered.
+func Register(ref string, p Policy) {
+	if err := DefaultRegistry.Register(ref, p); err != nil {
+		panic(err)
+	}
+}
+
+// LookupProfile returns a profile of DefaultRegistry.
+func LookupProfile(ref string) (Policy, error) {
+	return DefaultRegistry.Lookup(ref)
+}
+
+// MergeProfiles merges profiles of DefaultRegistry.
+func MergeProfiles(refs ...string) (Policy, error) {
+	return DefaultRegistry.Merge(refs...)
+}
+
+// parseProfileRef splits "name@version" into its parts.
+func parseProfileRef(ref string) (name, version string, err error) {
+	name, version, _ = strings.Cut(ref, "@")
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return "", "", fmt.Errorf("invalid profile name %q", ref)
+	}
+	return name, version, nil
+}
+
+// sortedVersions returns the versions from lowest to highest.
+func sortedVersions(versions map[string]Policy) []string {
+	sorted := make([]string, 0, len(versions))
+	for v := range versions {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareVersions(sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+// compareVersions compares dot separated versions such as "v1.10.2"
+// numerically. Parts that are not numbers are compared as strings.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// clone returns a copy of the policy that shares no slices or maps with it.
+func (p *Policy) clone() Policy {
+	c := *p
+	if p.Syscalls != nil {
+		c.Syscalls = make([]SyscallGroup, len(p.Syscalls))
+	}
+	for i, group := range p.Syscalls {
+		c.Syscalls[i] = group
+		c.Syscalls[i].Names = append([]string(nil), group.Names...)
+		if group.NamesWithCondtions == nil {
+			continue
+		}
+		c.Syscalls[i].NamesWithCondtions = make([]NameWithConditions, len(group.NamesWithCondtions))
+		for j, nc := range group.NamesWithCondtions {
+			c.Syscalls[i].NamesWithCondtions[j] = NameWithConditions{
+				Name:       nc.Name,
+				Conditions: append(ArgumentConditions(nil), nc.Conditions...),
+			}
+		}
+	}
+	if p.Profile != nil {
+		c.Profile = make(SyscallProfile, len(p.Profile))
+		for name, n := range p.Profile {
+			c.Profile[name] = n
+		}
+	}
+	return c
+}
@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	base := Policy{
+		DefaultAction: ActionErrno,
+		Syscalls:      []SyscallGroup{{Action: ActionAllow, Names: []string{"read", "write"}}},
+	}
+	for ref, names := range map[string][]string{
+		"worker":        {"read"},
+		"worker@1.2.0":  {"read", "write"},
+		"worker@1.10.0": {"read", "write", "close"},
+		"net":           {"socket", "connect"},
+	} {
+		p := base
+		p.Syscalls = []SyscallGroup{{Action: ActionAllow, Names: names}}
+		if err := r.Register(ref, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.Register("worker@1.2.0", base); err == nil {
+		t.Error("expected an error for a duplicate version")
+	}
+	if err := r.Register("invalid", Policy{DefaultAction: ActionAllow}); err == nil {
+		t.Error("expected an error for an invalid policy")
+	}
+	if _, err := r.Lookup("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+
+	if want := []string{"net", "worker"}; !reflect.DeepEqual(r.Names(), want) {
+		t.Errorf("expected names %v, got %v", want, r.Names())
+	}
+	if want := []string{"", "1.2.0", "1.10.0"}; !reflect.DeepEqual(r.Versions("worker"), want) {
+		t.Errorf("expected versions %v, got %v", want, r.Versions("worker"))
+	}
+
+	for ref, want := range map[string]int{"worker": 3, "worker@1.2.0": 2, "worker@": 1} {
+		p, err := r.Lookup(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(p.Syscalls[0].Names); got != want {
+			t.Errorf("%v: expected %d names, got %d", ref, want, got)
+		}
+	}
+
+	// Changes to a looked up policy do not affect the registry.
+	p, _ := r.Lookup("net")
+	p.Syscalls[0].Names[0] = "ptrace"
+	if p, _ = r.Lookup("net"); p.Syscalls[0].Names[0] != "socket" {
+		t.Error("registered policy was modified")
+	}
+}
+
+func TestRegistryMerge(t *testing.T) {
+	r := NewRegistry()
+	mustRegister := func(ref string, p Policy) {
+		t.Helper()
+		if err := r.Register(ref, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustRegister("a", Policy{
+		DefaultAction: ActionErrno,
+		Syscalls:      []SyscallGroup{{Action: ActionAllow, Names: []string{"read"}}},
+		Profile:       SyscallProfile{"read": 2},
+	})
+	mustRegister("b", Policy{
+		DefaultAction:    ActionErrno,
+		Syscalls:         []SyscallGroup{{Action: ActionErrno | 13, Names: []string{"open"}}},
+		Profile:          SyscallProfile{"read": 1, "open": 1},
+		IncludeGoRuntime: true,
+	})
+	mustRegister("c", Policy{
+		DefaultAction: ActionKillProcess,
+		Syscalls:      []SyscallGroup{{Action: ActionAllow, Names: []string{"write"}}},
+	})
+
+	merged, err := r.Merge("a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Policy{
+		DefaultAction: ActionErrno,
+		Syscalls: []SyscallGroup{
+			{Action: ActionAllow, Names: []string{"read"}},
+			{Action: ActionErrno | 13, Names: []string{"open"}},
+		},
+		Profile:          SyscallProfile{"read": 3, "open": 1},
+		IncludeGoRuntime: true,
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("expected %+v, got %+v", want, merged)
+	}
+	if _, err := merged.Assemble(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Merge("a", "c"); err == nil {
+		t.Error("expected an error for different default actions")
+	}
+}
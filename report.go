@@ -0,0 +1,312 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Report describes a policy for security review: the syscalls it permits and
+// denies grouped by category, argument conditions in plain language, and the
+// kernel it requires.
+type Report struct {
+	Arch          string
+	DefaultAction Action
+	Instructions  int // Number of BPF instructions of the assembled filter.
+
+	// Filter settings, only set for reports of a Filter.
+	IsFilter   bool
+	NoNewPrivs bool
+	Flag       FilterFlag
+
+	MinKernel    KernelVersion
+	Requirements []KernelRequirement
+	Categories   []ReportCategory
+}
+
+// ReportCategory is a group of syscalls in a report. Categories are named
+// after the systemd syscall sets (e.g. @file-system). Syscalls that are in
+// none of them are in the category "other".
+type ReportCategory struct {
+	Name  string
+	Rules []ReportRule
+}
+
+// ReportRule is a rule of the policy for one syscall. A syscall has several
+// rules if it is named by several groups. They are listed in the order in
+// which the filter evaluates them.
+type ReportRule struct {
+	Syscall    string
+	Action     Action
+	Conditions string // Plain language description, empty if unconditional.
+
+	// Shadowed is true if the rule never matches because an earlier rule
+	// matches all calls of the syscall.
+	Shadowed bool
+}
+
+// Permits returns true if the rule permits the syscall to run.
+func (r ReportRule) Permits() bool {
+	return r.Action.permits()
+}
+
+// NewReport returns a report of the policy. The policy is assembled to
+// validate it and count its instructions.
+func NewReport(p *Policy) (*Report, error) {
+	insts, err := p.Assemble()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Arch:          p.arch.Name,
+		DefaultAction: p.DefaultAction,
+		Instructions:  len(insts),
+		MinKernel:     p.MinKernelVersion(),
+		Requirements:  p.KernelRequirements(),
+	}
+
+	categories := map[string]*ReportCategory{}
+	unconditional := map[string]bool{}
+	for _, group := range p.groups() {
+		rules := make([]ReportRule, 0, len(group.Names)+len(group.NamesWithCondtions))
+		for _, name := range group.Names {
+			rules = append(rules, ReportRule{Syscall: name, Action: group.Action, Shadowed: unconditional[name]})
+			unconditional[name] = true
+		}
+		for _, nc := range group.NamesWithCondtions {
+			rules = append(rules, ReportRule{
+				Syscall:    nc.Name,
+				Action:     group.Action,
+				Conditions: describeConditions(nc.Conditions),
+				Shadowed:   unconditional[nc.Name],
+			})
+		}
+
+		for _, rule := range rules {
+			name := SyscallCategory(rule.Syscall)
+			c, found := categories[name]
+			if !found {
+				c = &ReportCategory{Name: name}
+				categories[name] = c
+			}
+			c.Rules = append(c.Rules, rule)
+		}
+	}
+
+	for _, c := range categories {
+		sort.SliceStable(c.Rules, func(i, j int) bool { return c.Rules[i].Syscall < c.Rules[j].Syscall })
+		r.Categories = append(r.Categories, *c)
+	}
+	sort.Slice(r.Categories, func(i, j int) bool {
+		// The catch-all category goes last.
+		if (r.Categories[i].Name == "other") != (r.Categories[j].Name == "other") {
+			return r.Categories[j].Name == "other"
+		}
+		return r.Categories[i].Name < r.Categories[j].Name
+	})
+	return r, nil
+}
+
+// NewFilterReport returns a report of the filter, including its flags and
+// the kernel they require.
+func NewFilterReport(f *Filter) (*Report, error) {
+	r, err := NewReport(&f.Policy)
+	if err != nil {
+		return nil, err
+	}
+	r.IsFilter = true
+	r.NoNewPrivs = f.NoNewPrivs
+	r.Flag = f.Flag
+	r.MinKernel = f.MinKernelVersion()
+	r.Requirements = f.KernelRequirements()
+	return r, nil
+}
+
+// describeConditions returns the conditions in plain language, such as
+// "arg0 is 0x1 and arg2 has none of the bits 0x80000 set".
+func describeConditions(conditions ArgumentConditions) string {
+	parts := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		var desc string
+		switch c.Operation {
+		case Equal:
+			desc = "is %#x"
+		case NotEqual:
+			desc = "is not %#x"
+		case GreaterThan:
+			desc = "is greater than %#x"
+		case LessThan:
+			desc = "is less than %#x"
+		case GreaterOrEqual:
+			desc = "is at least %#x"
+		case LessOrEqual:
+			desc = "is at most %#x"
+		case BitsSet:
+			desc = "has any of the bits %#x set"
+		case BitsNotSet:
+			desc = "has none of the bits %#x set"
+		default:
+			desc = string(c.Operation) + " %#x"
+		}
+		parts = append(parts, fmt.Sprintf("arg%d "+desc, c.Argument, c.Value))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// syscallCategories maps syscalls to the first systemd syscall set, in name
+// order, that lists them directly. @system-service is only used for
+// syscalls that are in no other set because it includes most other sets.
+var syscallCategories = func() map[string]string {
+	names := make([]string, 0, len(SystemdSyscallSets))
+	for name := range SystemdSyscallSets {
+		if name != "@system-service" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append(names, "@system-service")
+
+	categories := map[string]string{}
+	for _, set := range names {
+		for _, name := range SystemdSyscallSets[set] {
+			if _, found := categories[name]; !found && !strings.HasPrefix(name, "@") {
+				categories[name] = set
+			}
+		}
+	}
+	return categories
+}()
+
+// SyscallCategory returns the name of the systemd syscall set (e.g.
+// "@file-system") that the syscall is grouped under in reports, or "other"
+// if it is in none.
+func SyscallCategory(name string) string {
+	if category, found := syscallCategories[name]; found {
+		return category
+	}
+	return "other"
+}
+
+var reportFuncs = map[string]interface{}{
+	"yesno": func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	},
+}
+
+var markdownReport = template.Must(template.New("report").Funcs(reportFuncs).Parse(`# Seccomp policy report
+
+| Property | Value |
+|---|---|
+| Architecture | {{.Arch}} |
+| Default action | {{.DefaultAction}} |
+{{- if .IsFilter}}
+| No new privs | {{yesno .NoNewPrivs}} |
+| Filter flags | {{if .Flag}}{{.Flag}}{{else}}none{{end}} |
+{{- end}}
+| BPF instructions | {{.Instructions}} |
+| Minimum kernel | Linux {{.MinKernel}} |
+{{- if .Requirements}}
+
+## Kernel requirements
+{{range .Requirements}}
+- {{.}}
+{{- end}}
+{{- end}}
+
+## Syscalls
+
+Syscalls that are not listed get the default action **{{.DefaultAction}}**.
+{{- range .Categories}}
+
+### {{.Name}}
+
+| Syscall | Action | Condition |
+|---|---|---|
+{{- range .Rules}}
+| {{.Syscall}} | {{.Action}} | {{if .Shadowed}}never matches, an earlier rule matches all calls{{else if .Conditions}}{{.Conditions}}{{else}}always{{end}} |
+{{- end}}
+{{- end}}
+`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Seccomp policy report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; }
+tr.permit td.action { color: #1a7f37; }
+tr.deny td.action { color: #cf222e; }
+tr.shadowed { color: #888; }
+</style>
+</head>
+<body>
+<h1>Seccomp policy report</h1>
+<table>
+<tr><th>Architecture</th><td>{{.Arch}}</td></tr>
+<tr><th>Default action</th><td>{{.DefaultAction}}</td></tr>
+{{- if .IsFilter}}
+<tr><th>No new privs</th><td>{{yesno .NoNewPrivs}}</td></tr>
+<tr><th>Filter flags</th><td>{{if .Flag}}{{.Flag}}{{else}}none{{end}}</td></tr>
+{{- end}}
+<tr><th>BPF instructions</th><td>{{.Instructions}}</td></tr>
+<tr><th>Minimum kernel</th><td>Linux {{.MinKernel}}</td></tr>
+</table>
+{{- if .Requirements}}
+<h2>Kernel requirements</h2>
+<ul>
+{{- range .Requirements}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<h2>Syscalls</h2>
+<p>Syscalls that are not listed get the default action <strong>{{.DefaultAction}}</strong>.</p>
+{{- range .Categories}}
+<h3>{{.Name}}</h3>
+<table>
+<tr><th>Syscall</th><th>Action</th><th>Condition</th></tr>
+{{- range .Rules}}
+<tr class="{{if .Shadowed}}shadowed{{else if .Permits}}permit{{else}}deny{{end}}"><td>{{.Syscall}}</td><td class="action">{{.Action}}</td><td>{{if .Shadowed}}never matches, an earlier rule matches all calls{{else if .Conditions}}{{.Conditions}}{{else}}always{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteMarkdown writes the report as Markdown.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	return markdownReport.Execute(w, r)
+}
+
+// WriteHTML writes the report as a standalone HTML page.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlReport.Execute(w, r)
+}
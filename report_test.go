@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testReportFilter() *Filter {
+	return &Filter{
+		NoNewPrivs: true,
+		Flag:       FilterFlagLog,
+		Policy: Policy{
+			DefaultAction: ActionErrno,
+			Syscalls: []SyscallGroup{
+				{
+					Action: ActionAllow,
+					Names:  []string{"read", "write"},
+					NamesWithCondtions: []NameWithConditions{
+						{
+							Name: "socket",
+							Conditions: ArgumentConditions{
+								{Argument: 0, Operation: Equal, Value: 1},
+								{Argument: 1, Operation: BitsNotSet, Value: 0x80000},
+							},
+						},
+					},
+				},
+				{Action: ActionKillProcess, Names: []string{"ptrace", "read"}},
+			},
+		},
+	}
+}
+
+const testReportMarkdown = `# Seccomp policy report
+
+| Property | Value |
+|---|---|
+| Architecture | x86_64 |
+| Default action | errno |
+| No new privs | yes |
+| Filter flags | log |
+| BPF instructions | 23 |
+| Minimum kernel | Linux 4.14 |
+
+## Kernel requirements
+
+- flags log (Linux 4.14)
+- action kill_process (Linux 4.14)
+
+## Syscalls
+
+Syscalls that are not listed get the default action **errno**.
+
+### @basic-io
+
+| Syscall | Action | Condition |
+|---|---|---|
+| read | allow | always |
+| read | kill_process | never matches, an earlier rule matches all calls |
+| write | allow | always |
+
+### @debug
+
+| Syscall | Action | Condition |
+|---|---|---|
+| ptrace | kill_process | always |
+
+### @network-io
+
+| Syscall | Action | Condition |
+|---|---|---|
+| socket | allow | arg0 is 0x1 and arg1 has none of the bits 0x80000 set |
+`
+
+func TestReportMarkdown(t *testing.T) {
+	r, err := NewFilterReport(testReportFilter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Arch != "x86_64" {
+		t.Skip("golden report is for x86_64")
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != testReportMarkdown {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestReportHTML(t *testing.T) {
+	r, err := NewReport(&testReportFilter().Policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.IsFilter {
+		t.Error("policy report must not include filter settings")
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<tr class="permit"><td>write</td><td class="action">allow</td><td>always</td></tr>`,
+		`<tr class="shadowed"><td>read</td>`,
+		`<tr class="deny"><td>ptrace</td>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in report:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "No new privs") {
+		t.Error("policy report must not include filter settings")
+	}
+}
@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import "github.com/elastic/go-seccomp-bpf/arch"
+
+// GoRuntimeSyscalls are the syscalls that the Go runtime can make at any time
+// after a program started, for example from the scheduler, the garbage
+// collector, the network poller, timers, and signal handling. Blocking one of
+// them usually crashes the program in a place unrelated to its own code.
+// Names that do not exist on an architecture are ignored.
+var GoRuntimeSyscalls = []string{
+	"arch_prctl", "brk", "clock_gettime", "clock_nanosleep", "clone",
+	"clone3", "close", "epoll_create1", "epoll_ctl", "epoll_pwait",
+	"epoll_pwait2", "epoll_wait", "eventfd2", "exit", "exit_group", "fcntl",
+	"fcntl64", "futex", "getpid", "getrandom", "getrlimit", "gettid",
+	"gettimeofday", "madvise", "mmap", "mmap2", "mprotect", "munmap",
+	"nanosleep", "pipe2", "prlimit64", "read", "restart_syscall", "rseq",
+	"rt_sigaction", "rt_sigprocmask", "rt_sigreturn", "sched_getaffinity",
+	"sched_yield", "set_robust_list", "setitimer", "sigaltstack", "sigreturn",
+	"tgkill", "timer_create", "timer_delete", "timer_settime", "write",
+}
+
+// goRuntimeGroup returns a group that allows the GoRuntimeSyscalls that exist
+// on the policy's arch and that are not named by any group of the policy, so
+// explicit rules, including argument conditions, keep precedence. It returns
+// false if all of them are named or if the arch is unknown.
+func (p *Policy) goRuntimeGroup() (SyscallGroup, bool) {
+	info := p.arch
+	if info == nil {
+		var err error
+		if info, err = arch.GetInfo(""); err != nil {
+			return SyscallGroup{}, false
+		}
+	}
+
+	named := map[string]bool{}
+	for _, group := range p.Syscalls {
+		for _, name := range group.Names {
+			named[name] = true
+		}
+		for _, s := range group.NamesWithCondtions {
+			named[s.Name] = true
+		}
+	}
+
+	var names []string
+	for _, name := range GoRuntimeSyscalls {
+		if _, found := info.SyscallNumber(name); found && !named[name] {
+			names = append(names, name)
+		}
+	}
+	return SyscallGroup{Action: ActionAllow, Names: names, arch: p.arch}, len(names) > 0
+}
+
+// groups returns the syscall groups of the policy including the Go runtime
+// group if it is enabled. The Go runtime group is built for the native arch
+// if the arch of the policy is not initialized yet.
+func (p *Policy) groups() []SyscallGroup {
+	if !p.IncludeGoRuntime {
+		return p.Syscalls
+	}
+	group, ok := p.goRuntimeGroup()
+	if !ok {
+		return p.Syscalls
+	}
+	return append(p.Syscalls[:len(p.Syscalls):len(p.Syscalls)], group)
+}
@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestPolicyIncludeGoRuntime(t *testing.T) {
+	const cloneNewUser = 0x10000000
+	policy := &Policy{
+		arch:             arch.X86_64,
+		DefaultAction:    ActionKillProcess,
+		IncludeGoRuntime: true,
+		Syscalls: []SyscallGroup{
+			{Action: ActionAllow, Names: []string{"openat"}},
+			{Action: ActionErrno, Names: []string{"madvise"}},
+			{
+				Action: ActionAllow,
+				NamesWithCondtions: []NameWithConditions{{
+					Name:       "clone",
+					Conditions: []Condition{{Argument: 0, Operation: BitsNotSet, Value: cloneNewUser}},
+				}},
+			},
+		},
+	}
+
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{data("futex"), ActionAllow},
+		{data("epoll_pwait"), ActionAllow},
+		{data("openat"), ActionAllow},
+		// Explicit rules take precedence over the runtime syscalls.
+		{data("madvise"), ActionErrno | Action(errnoEPERM)},
+		{data("clone", 0), ActionAllow},
+		{data("clone", cloneNewUser), ActionKillProcess},
+		{data("socket"), ActionKillProcess},
+	})
+
+	if err := policy.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the runtime syscalls are allowed.
+	policy = &Policy{arch: arch.AARCH64, DefaultAction: ActionErrno, IncludeGoRuntime: true}
+	if _, err := policy.Assemble(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPolicyIncludeGoRuntimeKernelRequirements(t *testing.T) {
+	arches := []*arch.Info{arch.X86_64}
+	if _, err := arch.GetInfo(""); err == nil {
+		// The runtime syscalls of the native arch are used before assembling.
+		arches = append(arches, nil)
+	}
+	for _, info := range arches {
+		policy := &Policy{arch: info, DefaultAction: ActionErrno, IncludeGoRuntime: true}
+		if v := policy.MinKernelVersion(); v.Less(KernelVersion{5, 11}) {
+			t.Errorf("expected the runtime syscalls to require Linux 5.11 (epoll_pwait2), got %v", v)
+		}
+		found := false
+		for _, req := range policy.KernelRequirements() {
+			found = found || req.Feature == "syscall clone3"
+		}
+		if !found {
+			t.Errorf("expected clone3 in the requirements, got %v", policy.KernelRequirements())
+		}
+		if policy.arch != info {
+			t.Error("the arch of the policy was changed")
+		}
+	}
+}
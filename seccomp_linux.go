@@ -21,7 +21,9 @@
 package seccomp
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"syscall"
 	"unsafe"
 
@@ -33,7 +35,7 @@ import (
 func Supported() bool {
 	// Strict mode requires that flags be set to 0, but we are sending 1 so
 	// this will return EINVAL if the syscall exists and is allowed.
-	if err := seccomp(seccompSetModeStrict, 1, nil); err == syscall.EINVAL {
+	if _, err := seccomp(seccompSetModeStrict, 1, nil); err == syscall.EINVAL {
 		return true
 	}
 
@@ -46,39 +48,227 @@ func SetNoNewPrivs() error {
 	return prctl(prSetNoNewPrivs, 1)
 }
 
+// GetNoNewPrivs returns the value of the calling thread's no_new_privs bit.
+func GetNoNewPrivs() (bool, error) {
+	r1, _, e := syscall.Syscall6(syscall.SYS_PRCTL, prGetNoNewPrivs, 0, 0, 0, 0, 0)
+	if e != 0 {
+		return false, e
+	}
+	return r1 == 1, nil
+}
+
+// HasCapSysAdmin returns true if CAP_SYS_ADMIN is in the calling thread's
+// effective capability set.
+func HasCapSysAdmin() (bool, error) {
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &data[0]); err != nil {
+		return false, err
+	}
+	return data[unix.CAP_SYS_ADMIN/32].Effective&(1<<(unix.CAP_SYS_ADMIN%32)) != 0, nil
+}
+
+// setNoNewPrivs sets the no_new_privs bit for the filter unless it is
+// already set or it is not needed.
+func setNoNewPrivs(filter Filter) error {
+	if !filter.NoNewPrivs {
+		return nil
+	}
+
+	if filter.SkipNoNewPrivsIfPrivileged {
+		if privileged, err := HasCapSysAdmin(); err == nil && privileged {
+			return nil
+		}
+	}
+
+	if set, err := GetNoNewPrivs(); err == nil && set {
+		return nil
+	}
+
+	return SetNoNewPrivs()
+}
+
 // LoadFilter will install seccomp using native methods.
 func LoadFilter(filter Filter) error {
-	insts, err := filter.Policy.Assemble()
+	if filter.Flag&FilterFlagNewListener != 0 {
+		return errors.New("use LoadFilterListener to load a filter with the new_listener flag")
+	}
+
+	_, err := loadFilter(filter)
+	return err
+}
+
+// LoadFilterListener installs the filter with FilterFlagNewListener and
+// returns the user-space notification file descriptor for syscalls matching
+// ActionUserNotify. The caller is responsible for closing the file. In dry
+// run mode the returned file is nil.
+//
+// Combining the listener with FilterFlagTSync requires Linux 5.7 because
+// FilterFlagTSyncESRCH must be used, which is added automatically.
+func LoadFilterListener(filter Filter) (*os.File, error) {
+	filter.Flag |= FilterFlagNewListener
+	if filter.Flag&FilterFlagTSync != 0 {
+		if running, err := runningKernelVersion(); err == nil && running.Less(KernelVersion{5, 7}) {
+			return nil, fmt.Errorf("combining tsync with new_listener requires "+
+				"Linux 5.7 but running %v", running)
+		}
+		filter.Flag |= FilterFlagTSyncESRCH
+	}
+
+	fd, err := loadFilter(filter)
+	if err != nil || filter.isDryRun() {
+		return nil, err
+	}
+	return os.NewFile(fd, "seccomp-notify"), nil
+}
+
+// EnterStrictMode switches the calling thread into strict seccomp mode. After
+// this only read(2), write(2), _exit(2) (but not exit_group(2)), and
+// sigreturn(2) are permitted and any other syscall kills the thread.
+//
+// Strict mode only applies to the calling thread and cannot be undone. The
+// Go runtime makes other syscalls (e.g. futex, mmap) at any time, so the
+// goroutine should be locked to its thread with runtime.LockOSThread and
+// restrict itself to reading and writing already opened file descriptors.
+func EnterStrictMode() error {
+	_, err := seccomp(seccompSetModeStrict, 0, nil)
+	if err == syscall.ENOSYS {
+		err = prctl(prSetSeccomp, seccompModeStrict)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to assemble policy: %w", err)
+		return fmt.Errorf("failed to enter strict seccomp mode: %w", err)
 	}
+	return nil
+}
 
-	raw, err := bpf.Assemble(insts)
+// loadFilter installs the filter and returns the value returned by the
+// seccomp syscall. In dry run mode the filter is validated but not installed.
+func loadFilter(filter Filter) (uintptr, error) {
+	raw, err := compileFilter(filter)
+	debugFilter(filter, raw, err)
 	if err != nil {
-		return fmt.Errorf("failed to assemble BPF instructions: %w", err)
+		return 0, err
 	}
 
+	if filter.Flag&FilterFlagTSync == 0 && filter.RequireSingleThread {
+		threads, err := threadCount()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count threads: %w", err)
+		}
+		if threads > 1 {
+			return 0, &ThreadCountError{Threads: threads}
+		}
+	}
+
+	if filter.isDryRun() {
+		features, err := KernelSupport()
+		if err != nil {
+			return 0, fmt.Errorf("failed to probe kernel support: %w", err)
+		}
+		return 0, features.CheckSupport(filter)
+	}
+
+	return installFilter(filter, raw)
+}
+
+// compileFilter assembles the filter's policy into raw BPF instructions, or
+// returns its compiled program.
+func compileFilter(filter Filter) ([]bpf.RawInstruction, error) {
+	var raw []bpf.RawInstruction
+	if filter.Compiled != nil {
+		var err error
+		if raw, err = filter.Compiled.program(); err != nil {
+			return nil, err
+		}
+	} else {
+		insts, err := filter.Cache.Assemble(&filter.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assemble policy: %w", err)
+		}
+
+		raw, err = bpf.Assemble(insts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assemble BPF instructions: %w", err)
+		}
+	}
+
+	if len(raw) > maxInstructions {
+		return nil, fmt.Errorf("filter has %d instructions but the kernel "+
+			"limit is %d", len(raw), maxInstructions)
+	}
+	return raw, nil
+}
+
+// installFilter sets no_new_privs as configured by the filter and installs
+// the raw program with the filter's flags.
+func installFilter(filter Filter, raw []bpf.RawInstruction) (uintptr, error) {
 	sockFilter := sockFilter(raw)
 	program := &syscall.SockFprog{
 		Len:    uint16(len(sockFilter)),
 		Filter: &sockFilter[0],
 	}
 
-	if filter.NoNewPrivs {
-		if err = SetNoNewPrivs(); err != nil {
-			return fmt.Errorf("failed to set no_new_privs with prctl: %w", err)
-		}
+	if err := setNoNewPrivs(filter); err != nil {
+		return 0, fmt.Errorf("failed to set no_new_privs with prctl: %w", err)
 	}
 
-	if err = seccomp(seccompSetModeFilter, filter.Flag, unsafe.Pointer(program)); err != nil {
-		if err == syscall.ENOSYS {
-			return fmt.Errorf("failed loading seccomp filter: seccomp "+
-				"is not supported by the kernel: %w", err)
+	rtn, err := seccomp(seccompSetModeFilter, filter.Flag, unsafe.Pointer(program))
+	if err != nil {
+		switch {
+		case err == syscall.EACCES:
+			return 0, fmt.Errorf("failed loading seccomp filter: %w", &NoNewPrivsError{Err: err})
+		case err == syscall.ENOSYS && filter.Flag == 0:
+			// The seccomp syscall was added in Linux 3.17 (or it might be
+			// blocked). Without flags the filter can be installed with prctl.
+			if err = prctl(prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(program))); err != nil {
+				if err == syscall.EACCES {
+					err = &NoNewPrivsError{Err: err}
+				}
+				return 0, fmt.Errorf("failed loading seccomp filter with prctl: %w", err)
+			}
+			return 0, nil
+		case err == syscall.ENOSYS:
+			return 0, fmt.Errorf("failed loading seccomp filter: seccomp "+
+				"is not supported by the kernel and flags %v cannot be used "+
+				"with the prctl fallback: %w", filter.Flag, err)
+		case err == syscall.ESRCH && filter.Flag&FilterFlagTSyncESRCH != 0:
+			return 0, fmt.Errorf("failed loading seccomp filter: %w", &TSyncError{})
+		case err == syscall.EINVAL:
+			if running, verr := runningKernelVersion(); verr == nil {
+				if required := filter.Flag.MinKernelVersion(); running.Less(required) {
+					return 0, fmt.Errorf("failed loading seccomp filter: flags %v "+
+						"require Linux %v but running %v: %w", filter.Flag, required, running, err)
+				}
+			}
 		}
-		return fmt.Errorf("failed loading seccomp filter: %w", err)
+		return 0, fmt.Errorf("failed loading seccomp filter: %w", err)
 	}
 
-	return nil
+	// With TSYNC the kernel returns the ID of the thread that could not be
+	// synchronized instead of an error.
+	if rtn != 0 && filter.Flag&FilterFlagTSync != 0 && filter.Flag&FilterFlagNewListener == 0 {
+		return 0, fmt.Errorf("failed loading seccomp filter: %w", &TSyncError{TID: int(rtn)})
+	}
+
+	return rtn, nil
+}
+
+// threadCount returns the number of threads in the process.
+func threadCount() (int, error) {
+	entries, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// runningKernelVersion returns the version of the running kernel.
+func runningKernelVersion() (KernelVersion, error) {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return KernelVersion{}, err
+	}
+	return ParseKernelVersion(unix.ByteSliceToString(uts.Release[:]))
 }
 
 func sockFilter(raw []bpf.RawInstruction) []syscall.SockFilter {
@@ -109,10 +299,10 @@ func prctl(option uintptr, args ...uintptr) error {
 }
 
 // seccomp syscall wrapper.
-func seccomp(op uintptr, flags FilterFlag, uargs unsafe.Pointer) error {
-	_, _, e := syscall.Syscall(unix.SYS_SECCOMP, op, uintptr(flags), uintptr(uargs))
+func seccomp(op uintptr, flags FilterFlag, uargs unsafe.Pointer) (uintptr, error) {
+	r1, _, e := syscall.Syscall(unix.SYS_SECCOMP, op, uintptr(flags), uintptr(uargs))
 	if e != 0 {
-		return e
+		return 0, e
 	}
-	return nil
+	return r1, nil
 }
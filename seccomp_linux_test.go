@@ -22,18 +22,390 @@
 package seccomp
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/bpf"
 	"golang.org/x/sys/unix"
 
 	"github.com/elastic/go-seccomp-bpf/arch"
 )
 
+// testFilterPolicy is installed by the filtered test shim.
+var testFilterPolicy = Policy{
+	DefaultAction: ActionAllow,
+	Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"getppid"}}},
+}
+
+// The filtered test shim installs testFilterPolicy and then blocks until
+// stdin is closed.
+var testFilteredShim = registerShim("test-filtered", func() int {
+	err := LoadFilter(Filter{NoNewPrivs: true, Flag: FilterFlagTSync | FilterFlagLog, Policy: testFilterPolicy})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("ready")
+	io.Copy(io.Discard, os.Stdin)
+	return 0
+})
+
+// TestGetProcessFilters must run before TestLoadFilter installs a filter that
+// blocks execve in the test process.
+func TestGetProcessFilters(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+	if privileged, _ := HasCapSysAdmin(); !privileged {
+		t.Skip("requires CAP_SYS_ADMIN")
+	}
+
+	cmd := exec.Command(selfExe)
+	cmd.Env = append(os.Environ(), shimEnv+"="+testFilteredShim)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	defer stdin.Close()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil || line != "ready\n" {
+		t.Fatalf("child did not load the filter: %q %v", line, err)
+	}
+
+	filters, err := GetProcessFilters(cmd.Process.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := compileFilter(Filter{Policy: testFilterPolicy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.NotEmpty(t, filters) {
+		// Compare raw instructions because conditional jumps are normalized
+		// by the disassembler.
+		actual, err := bpf.Assemble(filters[0].Instructions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 0, filters[0].Index)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, FilterFlagLog, filters[0].Flags)
+	}
+}
+
+// TestValidate must run before TestLoadFilter installs a filter that blocks
+// execve in the test process.
+func TestValidate(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	filter := Filter{
+		NoNewPrivs: true,
+		Flag:       FilterFlagTSync,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"getppid"}}},
+		},
+	}
+	if err := Validate(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	// Default deny policies kill or crash the child once the filter is
+	// installed, which must not be reported as a failure.
+	for _, action := range []Action{ActionErrno, ActionKillProcess, ActionTrap} {
+		deny := Filter{
+			NoNewPrivs: true,
+			Policy: Policy{
+				DefaultAction: action,
+				Syscalls:      []SyscallGroup{{Action: ActionAllow, Names: []string{"read"}}},
+			},
+		}
+		assert.NoError(t, Validate(deny), "default action %v", action)
+	}
+
+	// An unknown flag is rejected by the kernel.
+	filter.Flag |= 1 << 30
+	err := Validate(filter)
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, unix.EINVAL, validationErr.Errno)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	filter := Filter{
+		NoNewPrivs: true,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"getppid"}}},
+		},
+	}
+	opts := BenchmarkOptions{
+		Syscalls:   []BenchmarkSyscall{{Name: "getpid", Weight: 3}, {Name: "getppid"}},
+		Iterations: 1000,
+		Rounds:     2,
+	}
+	result, err := Benchmark(filter, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, result.Syscalls, 2) {
+		assert.Equal(t, ActionAllow, result.Syscalls[0].Action)
+		assert.Equal(t, 3, result.Syscalls[0].Weight)
+		assert.Equal(t, ActionErrno|Action(unix.EPERM), result.Syscalls[1].Action)
+		assert.Equal(t, 1, result.Syscalls[1].Weight)
+		for _, l := range result.Syscalls {
+			assert.Positive(t, l.Baseline)
+			assert.Positive(t, l.Filtered)
+		}
+	}
+	assert.Positive(t, result.Instructions)
+	assert.Positive(t, result.Filtered)
+
+	// The child cannot survive a syscall that kills it.
+	filter.Policy.Syscalls[0].Action = ActionKillProcess
+	_, err = Benchmark(filter, opts)
+	assert.ErrorContains(t, err, "kill_process for syscall getppid")
+}
+
+// TestCommand must run before TestLoadFilter installs a filter that blocks
+// execve in the test process.
+func TestCommand(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	filter := Filter{
+		NoNewPrivs: true,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"mkdir", "mkdirat"}}},
+		},
+	}
+
+	out, err := Command(filter, "cat", "/proc/self/status").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(out), "Seccomp:\t2\n")
+
+	dir := filepath.Join(t.TempDir(), "dir")
+	out, err = Command(filter, "mkdir", dir).CombinedOutput()
+	assert.Error(t, err)
+	assert.Contains(t, string(out), "Operation not permitted")
+	assert.NoDirExists(t, dir)
+
+	filter.Policy.Syscalls[0].Names = []string{"no_such_syscall"}
+	assert.Error(t, Command(filter, "true").Run())
+}
+
+// TestCommandLargeFilter must run before TestLoadFilter installs a filter
+// that blocks execve in the test process.
+func TestCommandLargeFilter(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	// Grow the filter close to the instruction limit of the kernel. Its
+	// encoding is larger than a single environment variable can be.
+	filter := Filter{NoNewPrivs: true, Policy: Policy{DefaultAction: ActionAllow}}
+	filler := SyscallGroup{Action: ActionErrno}
+	for i := uint64(0xffffffff_80000000); ; i++ {
+		filler.NamesWithCondtions = append(filler.NamesWithCondtions, NameWithConditions{
+			Name: "ioctl", Conditions: ArgumentConditions{{Argument: 1, Operation: Equal, Value: i}},
+		})
+		filter.Policy.Syscalls = []SyscallGroup{
+			filler,
+			{Action: ActionErrno, Names: []string{"mkdir", "mkdirat"}},
+		}
+		insts, err := filter.Policy.Assemble()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(insts) > maxInstructions-16 {
+			break
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "dir")
+	out, err := Command(filter, "mkdir", dir).CombinedOutput()
+	assert.Error(t, err)
+	assert.Contains(t, string(out), "Operation not permitted")
+	assert.NoDirExists(t, dir)
+}
+
+// TestCommandListener must run before TestLoadFilter installs a filter that
+// blocks execve in the test process.
+func TestCommandListener(t *testing.T) {
+	features, err := KernelSupport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !features.HasAction(ActionUserNotify) {
+		t.Skip("user notifications not supported by kernel")
+	}
+
+	filter := Filter{
+		NoNewPrivs: true,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionUserNotify, Names: []string{"mkdir", "mkdirat"}}},
+		},
+	}
+	dir := filepath.Join(t.TempDir(), "dir")
+	cmd, receiver := CommandListener(filter, "mkdir", dir)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := receiver.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", listener.Fd()))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "anon_inode:seccomp notify", link)
+	}
+
+	// Without a supervisor the notified syscalls fail with ENOSYS.
+	listener.Close()
+	assert.Error(t, cmd.Wait())
+	assert.Contains(t, out.String(), "Function not implemented")
+	assert.NoDirExists(t, dir)
+}
+
+// TestForkExec must run before TestLoadFilter installs a filter that blocks
+// execve in the test process.
+func TestForkExec(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	filter := Filter{
+		NoNewPrivs: true,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"mkdir", "mkdirat"}}},
+		},
+	}
+	run := func(argv ...string) (string, syscall.WaitStatus) {
+		t.Helper()
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			t.Skip(err)
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+
+		pid, err := ForkExec(filter, path, argv, &syscall.ProcAttr{
+			Dir:   t.TempDir(),
+			Env:   os.Environ(),
+			Files: []uintptr{0, w.Fd(), w.Fd()},
+		})
+		w.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var status syscall.WaitStatus
+		if _, err = syscall.Wait4(pid, &status, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+		return string(out), status
+	}
+
+	out, status := run("cat", "/proc/self/status")
+	assert.Zero(t, status.ExitStatus())
+	assert.Contains(t, out, "Seccomp:\t2\n")
+	assert.Contains(t, out, "NoNewPrivs:\t1\n")
+
+	out, status = run("mkdir", "dir")
+	assert.NotZero(t, status.ExitStatus())
+	assert.Contains(t, out, "Operation not permitted")
+
+	_, err := ForkExec(filter, "/nonexistent", []string{"nonexistent"}, nil)
+	assert.ErrorIs(t, err, syscall.ENOENT)
+}
+
+// TestWhatIf must run before TestLoadFilter installs a filter that blocks
+// execve in the test process.
+func TestWhatIf(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip(err)
+	}
+
+	policy := Policy{
+		DefaultAction: ActionAllow,
+		Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"mkdir", "mkdirat"}}},
+	}
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shell runs mkdir in a child process.
+	dir := filepath.Join(t.TempDir(), "dir")
+	report, err := WhatIf(exec.Command("sh", "-c", `mkdir "$1" && true`, "sh", dir), insts)
+	if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EIO) {
+		t.Skip("ptrace not permitted:", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is enforced.
+	assert.DirExists(t, dir)
+	if assert.Len(t, report.Denials, 1) {
+		d := report.Denials[0]
+		assert.Contains(t, []string{"mkdir", "mkdirat"}, d.Syscall)
+		assert.Equal(t, ActionErrno|Action(syscall.EPERM), d.Action)
+		assert.EqualValues(t, 1, d.Count)
+		assert.NotEqual(t, os.Getpid(), d.Pid)
+		assert.Greater(t, report.Syscalls, d.Count)
+	}
+
+	report, err = WhatIf(exec.Command("sh", "-c", "exit 3"), insts)
+	var exitErr *exec.ExitError
+	if assert.ErrorAs(t, err, &exitErr) {
+		assert.Equal(t, 3, exitErr.ExitCode())
+	}
+	assert.Empty(t, report.Denials)
+}
+
 func TestLoadFilter(t *testing.T) {
 	if !Supported() {
 		t.Skip("seccomp not supported by kernel")
@@ -135,3 +507,194 @@ func TestLoadFilter(t *testing.T) {
 		t.Error("expected to receive an EPERM error when exec'ing")
 	}
 }
+
+func TestLoadFilterRejectsNewListener(t *testing.T) {
+	err := LoadFilter(Filter{
+		Flag: FilterFlagNewListener,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionUserNotify, Names: []string{"getpid"}}},
+		},
+	})
+	assert.ErrorContains(t, err, "LoadFilterListener")
+}
+
+func TestKernelSupport(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	features, err := KernelSupport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, features.Syscall)
+	assert.True(t, features.HasAction(ActionAllow))
+	assert.True(t, features.HasAction(ActionErrno|Action(errnoEPERM)))
+	assert.True(t, features.HasFlags(FilterFlagTSync))
+}
+
+func TestProbeActions(t *testing.T) {
+	actions := probeActions(func(action Action) error {
+		if action == ActionUserNotify {
+			return unix.EOPNOTSUPP
+		}
+		return nil
+	})
+	assert.Contains(t, actions, ActionLog)
+	assert.NotContains(t, actions, ActionUserNotify)
+
+	// Kernels older than 4.14 do not support SECCOMP_GET_ACTION_AVAIL.
+	actions = probeActions(func(Action) error { return unix.EINVAL })
+	assert.ElementsMatch(t, baseActions, actions)
+	features := KernelFeatures{Syscall: true, Actions: actions}
+	assert.True(t, features.HasAction(ActionErrno|Action(errnoEPERM)))
+	assert.False(t, features.HasAction(ActionKillProcess))
+}
+
+func TestReadActionNames(t *testing.T) {
+	path := t.TempDir() + "/actions_avail"
+	if err := os.WriteFile(path, []byte("kill_process kill_thread trap errno user_notif trace log allow future\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	actions, err := readActionNames(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Action{
+		ActionKillProcess, ActionKillThread, ActionTrap, ActionErrno,
+		ActionUserNotify, ActionTrace, ActionLog, ActionAllow,
+	}, actions)
+}
+
+func TestGetNoNewPrivs(t *testing.T) {
+	if err := SetNoNewPrivs(); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := GetNoNewPrivs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, set)
+}
+
+func TestReadStatus(t *testing.T) {
+	path := t.TempDir() + "/status"
+	if err := os.WriteFile(path, []byte("Name:\tcat\nNoNewPrivs:\t1\nSeccomp:\t2\nSeccomp_filters:\t3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := readStatus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &Status{Mode: ModeFilter, Filters: 3}, status)
+
+	_, err = GetStatus()
+	assert.NoError(t, err)
+}
+
+func TestLoadFilterDryRun(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	filter := Filter{
+		NoNewPrivs: true,
+		Flag:       FilterFlagTSync,
+		DryRun:     true,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"getppid"}}},
+		},
+	}
+	if err := LoadFilter(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, errno := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0)
+	assert.Zero(t, errno, "filter must not be installed in dry run mode")
+
+	features := &KernelFeatures{Syscall: true, Actions: []Action{ActionAllow}, Flags: FilterFlagTSync}
+	assert.ErrorContains(t, features.CheckSupport(filter), "action errno is not supported")
+
+	compiled, err := CompileProgram(&filter.Policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter.Policy, filter.Compiled = Policy{}, compiled
+	assert.NoError(t, LoadFilter(filter))
+	assert.ErrorContains(t, features.CheckSupport(filter), "action errno(1) is not supported")
+}
+
+func TestLoadFilterThreadCount(t *testing.T) {
+	filter := Filter{
+		DryRun: true,
+		Policy: Policy{
+			DefaultAction: ActionAllow,
+			Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"getppid"}}},
+		},
+	}
+	assert.NoError(t, LoadFilter(filter))
+
+	filter.RequireSingleThread = true
+	err := LoadFilter(filter)
+	var threadErr *ThreadCountError
+	if assert.ErrorAs(t, err, &threadErr) {
+		assert.Greater(t, threadErr.Threads, 1)
+	}
+
+	filter.Flag = FilterFlagTSync
+	assert.NoError(t, LoadFilter(filter))
+}
+
+func TestLoadFilterPrctlFallback(t *testing.T) {
+	if !Supported() {
+		t.Skip("seccomp not supported by kernel")
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		// Never unlock so that the confined thread exits with the goroutine.
+		runtime.LockOSThread()
+
+		// Hide the seccomp syscall from the filter that follows.
+		err := LoadFilter(Filter{
+			NoNewPrivs: true,
+			Policy: Policy{
+				DefaultAction: ActionAllow,
+				Syscalls:      []SyscallGroup{{Action: ActionErrno | Action(unix.ENOSYS), Names: []string{"seccomp"}}},
+			},
+		})
+		if err != nil {
+			errs <- err
+			return
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_SECCOMP, 0, 0, 0); errno != unix.ENOSYS {
+			errs <- fmt.Errorf("seccomp returned %v, want ENOSYS", errno)
+			return
+		}
+
+		err = LoadFilter(Filter{
+			Policy: Policy{
+				DefaultAction: ActionAllow,
+				Syscalls:      []SyscallGroup{{Action: ActionErrno, Names: []string{"getppid"}}},
+			},
+		})
+		if err != nil {
+			errs <- err
+			return
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_GETPPID, 0, 0, 0); errno != unix.EPERM {
+			errs <- fmt.Errorf("getppid returned %v, want EPERM", errno)
+			return
+		}
+		errs <- nil
+	}()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -20,6 +20,15 @@
 
 package seccomp
 
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+
+	"golang.org/x/net/bpf"
+)
+
 // Supported returns true if the seccomp syscall is supported.
 //
 // This is a stub for non-Linux systems. It always returns false.
@@ -35,9 +44,212 @@ func SetNoNewPrivs() error {
 	return nil
 }
 
+// GetNoNewPrivs returns the value of the calling thread's no_new_privs bit.
+//
+// This is a stub for non-Linux systems. It always returns false.
+func GetNoNewPrivs() (bool, error) {
+	return false, nil
+}
+
+// HasCapSysAdmin returns true if CAP_SYS_ADMIN is in the calling thread's
+// effective capability set.
+//
+// This is a stub for non-Linux systems. It always returns false.
+func HasCapSysAdmin() (bool, error) {
+	return false, nil
+}
+
 // LoadFilter will install seccomp using native methods.
 //
 // This is a stub for non-Linux systems. It never returns an error.
 func LoadFilter(_ Filter) error {
 	return nil
 }
+
+// EnterStrictMode switches the calling thread into strict seccomp mode.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func EnterStrictMode() error {
+	return errors.ErrUnsupported
+}
+
+// LoadFilterListener installs the filter and returns the user-space
+// notification file descriptor.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func LoadFilterListener(_ Filter) (*os.File, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// KernelSupport probes the running kernel and reports which seccomp actions
+// and filter flags it supports.
+//
+// This is a stub for non-Linux systems. It always reports that seccomp is
+// not supported.
+func KernelSupport() (*KernelFeatures, error) {
+	return &KernelFeatures{}, nil
+}
+
+// ActionsAvailable returns the actions supported by the kernel.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func ActionsAvailable() ([]Action, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ActionsLogged returns the actions that the kernel is allowed to log.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func ActionsLogged() ([]Action, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// SetActionsLogged sets the actions that the kernel is allowed to log.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func SetActionsLogged(_ []Action) error {
+	return errors.ErrUnsupported
+}
+
+// GetStatus returns the seccomp state of the calling thread.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func GetStatus() (*Status, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// GetProcessStatus returns the seccomp state of the main thread of the given
+// process.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func GetProcessStatus(_ int) (*Status, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Validate installs the filter in a child process to check that the kernel
+// accepts it.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func Validate(_ Filter) error {
+	return errors.ErrUnsupported
+}
+
+// Benchmark measures the syscall latency added by the filter in a child
+// process.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func Benchmark(_ Filter, _ BenchmarkOptions) (*BenchmarkResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// GetProcessFilters retrieves the seccomp filters installed in a process.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func GetProcessFilters(_ int) ([]InstalledFilter, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Command returns an *exec.Cmd that runs the named program with the filter
+// installed.
+//
+// This is a stub for non-Linux systems. Starting the command always returns
+// an error.
+func Command(_ Filter, name string, arg ...string) *exec.Cmd {
+	cmd := exec.Command(name, arg...)
+	cmd.Err = errors.ErrUnsupported
+	return cmd
+}
+
+// CommandListener returns an *exec.Cmd that runs the named program with a
+// filter that notifies the calling process.
+//
+// This is a stub for non-Linux systems. Starting the command always returns
+// an error.
+func CommandListener(_ Filter, name string, arg ...string) (*exec.Cmd, *ListenerReceiver) {
+	return Command(Filter{}, name, arg...), &ListenerReceiver{}
+}
+
+// ListenerReceiver receives the notification listener of a command created
+// by CommandListener.
+type ListenerReceiver struct{}
+
+// Receive returns the listener of the command.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func (r *ListenerReceiver) Receive() (*os.File, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Close closes the socket.
+//
+// This is a stub for non-Linux systems. It never returns an error.
+func (r *ListenerReceiver) Close() error {
+	return nil
+}
+
+// JournalWriter writes violations to the systemd journal.
+//
+// This is a stub for non-Linux systems.
+type JournalWriter struct{}
+
+// NewJournalWriter connects to the journal.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func NewJournalWriter(_ string) (*JournalWriter, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Write writes the violation as one entry.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func (j *JournalWriter) Write(_ Violation) error {
+	return errors.ErrUnsupported
+}
+
+// Close closes the connection to the journal.
+//
+// This is a stub for non-Linux systems. It never returns an error.
+func (j *JournalWriter) Close() error {
+	return nil
+}
+
+// SyslogWriter writes violations to syslog.
+//
+// This is a stub for non-Linux systems.
+type SyslogWriter struct{}
+
+// NewSyslogWriter connects to the syslog daemon.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func NewSyslogWriter(_, _, _ string) (*SyslogWriter, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Write writes the violation as one message.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func (s *SyslogWriter) Write(_ Violation) error {
+	return errors.ErrUnsupported
+}
+
+// Close closes the connection to syslog.
+//
+// This is a stub for non-Linux systems. It never returns an error.
+func (s *SyslogWriter) Close() error {
+	return nil
+}
+
+// WatchAudit calls h with the SECCOMP records of the kernel audit subsystem.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func WatchAudit(_ context.Context, _ AuditHandler) error {
+	return errors.ErrUnsupported
+}
+
+// WhatIf runs the command and reports the syscalls that the filter would
+// have rejected.
+//
+// This is a stub for non-Linux systems. It always returns an error.
+func WhatIf(_ *exec.Cmd, _ []bpf.Instruction) (*WhatIfReport, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,44 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package seccomptest evaluates seccomp filters in pure Go for unit tests.
+// The filter is assembled and run by a BPF interpreter against synthetic
+// seccomp_data, so policy tests run on any operating system, including
+// macOS and Windows, without loading the filter:
+//
+//	func TestPolicy(t *testing.T) {
+//		f := seccomptest.New(t, policy)
+//		f.Allows("read")
+//		f.Denies("ptrace")
+//		f.Expect(seccomp.ActionErrno|seccomp.Action(syscall.EACCES), "openat", 0, 0, syscall.O_WRONLY)
+//	}
+//
+// Filters are assembled for the arch of the policy, which defaults to the
+// native one. Use Policy.SetArch to test the filter of another arch.
+//
+// Sound checks that no syscall outside of the allowlist of the policy is
+// allowed for any arch, including spoofed arch values and syscall numbers
+// with the x32 bit, to catch compiler soundness regressions. AllowsOnly
+// checks the same for an explicit allowlist.
+//
+// Golden compares the compiled program with a checked-in golden file, so that
+// the filter changes caused by a policy edit show up in code review:
+//
+//	seccomptest.New(t, policy).Golden("testdata/policy.golden")
+//
+// Run the tests with -seccomptest.update to write the golden files.
+package seccomptest
@@ -0,0 +1,188 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// UpdateFlag is the name of the test flag that makes Golden write the golden
+// files instead of comparing against them.
+const UpdateFlag = "seccomptest.update"
+
+var update = flag.Bool(UpdateFlag, false, "write seccomptest golden files instead of comparing against them")
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+// Render returns the canonical text of a program: a header with the arch and
+// the number of instructions followed by the annotated disassembly. It only
+// changes when the program does, so it can be checked in and reviewed.
+func Render(insts []bpf.Instruction, info *arch.Info) (string, error) {
+	var buf bytes.Buffer
+	if info != nil {
+		fmt.Fprintf(&buf, "# arch: %s\n", info.Name)
+	}
+	fmt.Fprintf(&buf, "# instructions: %d\n\n", len(insts))
+	if err := seccomp.WriteDisassembly(&buf, insts, info); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Golden compares the canonical text of the filter, see Render, with the
+// golden file at path and fails the test with a line diff if they differ.
+// Run the test with -seccomptest.update to write the golden file, then
+// review the change of the filter together with the policy edit that caused
+// it.
+func (f *Filter) Golden(path string) {
+	f.tb.Helper()
+	got, err := Render(f.insts, f.arch)
+	if err != nil {
+		f.tb.Fatalf("failed to render filter: %v", err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			f.tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			f.tb.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			f.tb.Fatalf("golden file %s does not exist, run the test with -%s to create it", path, UpdateFlag)
+		}
+		f.tb.Fatal(err)
+	}
+
+	// Git may check out the file with CRLF line endings on Windows.
+	wantText := strings.ReplaceAll(string(want), "\r\n", "\n")
+	if wantText != got {
+		f.tb.Errorf("filter differs from golden file %s (-want +got), run the test with -%s to accept it:\n%s",
+			path, UpdateFlag, diffLines(wantText, got))
+	}
+}
+
+// diffLines returns a line diff of the texts with a few lines of context
+// around each change.
+func diffLines(a, b string) string {
+	x := strings.SplitAfter(a, "\n")
+	y := strings.SplitAfter(b, "\n")
+
+	// Only the lines between the common prefix and suffix need the LCS.
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	xm, ym := x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of xm[i:]
+	// and ym[j:].
+	lcs := make([][]int, len(xm)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(ym)+1)
+	}
+	for i := len(xm) - 1; i >= 0; i-- {
+		for j := len(ym) - 1; j >= 0; j-- {
+			if xm[i] == ym[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	lines := make([]line, 0, len(x)+len(y))
+	for _, s := range x[:prefix] {
+		lines = append(lines, line{' ', s})
+	}
+	i, j := 0, 0
+	for i < len(xm) || j < len(ym) {
+		switch {
+		case i < len(xm) && j < len(ym) && xm[i] == ym[j]:
+			lines = append(lines, line{' ', xm[i]})
+			i++
+			j++
+		case j == len(ym) || (i < len(xm) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', xm[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', ym[j]})
+			j++
+		}
+	}
+	for _, s := range x[len(x)-suffix:] {
+		lines = append(lines, line{' ', s})
+	}
+
+	// Print the changes with their context, eliding the other lines.
+	var sb strings.Builder
+	last := -1 // Index of the last printed line.
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		start := max(k-diffContext, last+1)
+		if last >= 0 && start > last+1 {
+			sb.WriteString("...\n")
+		}
+		for ; start <= k; start++ {
+			writeDiffLine(&sb, lines[start].op, lines[start].text)
+		}
+		last = k
+		for n := 0; n < diffContext && last+1 < len(lines) && lines[last+1].op == ' '; n++ {
+			last++
+			writeDiffLine(&sb, ' ', lines[last].text)
+		}
+	}
+	return sb.String()
+}
+
+func writeDiffLine(sb *strings.Builder, op byte, text string) {
+	if text == "" {
+		return
+	}
+	sb.WriteByte(op)
+	sb.WriteString(strings.TrimSuffix(text, "\n"))
+	sb.WriteByte('\n')
+}
@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testFilter(t *testing.T, tb *recorder) *Filter {
+	policy := testPolicy
+	if err := policy.SetArch("x86_64"); err != nil {
+		t.Fatal(err)
+	}
+	if tb == nil {
+		return New(t, policy)
+	}
+	return New(tb, policy)
+}
+
+func TestGolden(t *testing.T) {
+	testFilter(t, nil).Golden("testdata/policy_x86_64.golden")
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	want, err := os.ReadFile("testdata/policy_x86_64.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "policy.golden")
+	edited := strings.Replace(string(want), "allow", "kill_process", 1)
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(edited, "\n", "\r\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+	testFilter(t, r).Golden(path)
+	if len(r.errors) != 1 {
+		t.Fatalf("expected 1 failure, got %q", r.errors)
+	}
+	msg := r.errors[0]
+	if !strings.Contains(msg, "\n-") || !strings.Contains(msg, "# kill_process\n+") || !strings.Contains(msg, "# allow\n") {
+		t.Errorf("expected the diff of the return instruction, got:\n%s", msg)
+	}
+	if strings.Contains(msg, "# instructions") {
+		t.Errorf("expected lines far from the change to be elided, got:\n%s", msg)
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	defer func(v bool) { *update = v }(*update)
+	*update = true
+
+	path := filepath.Join(t.TempDir(), "testdata", "policy.golden")
+	f := testFilter(t, nil)
+	f.Golden(path)
+
+	*update = false
+	f.Golden(path)
+}
+
+func TestGoldenMissing(t *testing.T) {
+	r := &recorder{TB: t}
+	func() {
+		defer func() {
+			if recover() != r {
+				t.Error("expected a missing golden file to fail the test")
+			}
+		}()
+		testFilter(t, r).Golden(filepath.Join(t.TempDir(), "missing.golden"))
+	}()
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "-seccomptest.update") {
+		t.Errorf("expected a hint to create the file, got %q", r.errors)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := " 1\n 2\n-3\n+three\n 4\n 5\n 6\n...\n 10\n 11\n 12\n+13\n"
+	if got := diffLines(a, b); got != want {
+		t.Errorf("unexpected diff:\n%s", got)
+	}
+}
@@ -0,0 +1,65 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"os"
+	"strings"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Replay runs the filter against the payloads of the corpus file at path,
+// see seccomp.CorpusWriter, that were recorded on the arch of the filter. It
+// fails the test if the filter lets through a call that the filter it was
+// recorded with rejected, or the other way around, so that traffic recorded
+// in production becomes a regression test for policy changes.
+func (f *Filter) Replay(path string) {
+	f.tb.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		f.tb.Fatal(err)
+	}
+	defer file.Close()
+	entries, err := seccomp.ReadCorpus(file)
+	if err != nil {
+		f.tb.Fatalf("failed to read corpus %s: %v", path, err)
+	}
+
+	var own []seccomp.CorpusEntry
+	for _, e := range entries {
+		if e.Data.Arch == uint32(f.arch.ID) {
+			own = append(own, e)
+		}
+	}
+	changes, err := seccomp.ReplayCorpus(f.insts, own)
+	if err != nil {
+		f.tb.Fatalf("failed to replay corpus %s: %v", path, err)
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	for _, c := range changes {
+		sb.WriteString("\n")
+		sb.WriteString(c.String())
+		sb.WriteString(f.explainData(c.Entry.Data))
+	}
+	f.tb.Errorf("filter changes the decision for %d of %d recorded calls in %s:%s", len(changes), len(own), path, sb.String())
+}
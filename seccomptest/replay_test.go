@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func writeCorpus(t *testing.T, entries ...seccomp.CorpusEntry) string {
+	var b strings.Builder
+	c := seccomp.NewCorpusWriter(&b)
+	for _, e := range entries {
+		if err := c.Record(e.Data, e.Action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReplay(t *testing.T) {
+	read, _ := Data(arch.X86_64, "read")
+	ptrace, _ := Data(arch.X86_64, "ptrace")
+	openat, _ := Data(arch.X86_64, "openat", 0, 0, 1)
+	aarch64, _ := Data(arch.AARCH64, "ptrace")
+	path := writeCorpus(t,
+		seccomp.CorpusEntry{Data: read, Action: seccomp.ActionAllow},
+		seccomp.CorpusEntry{Data: openat, Action: seccomp.ActionErrno | 13},
+		seccomp.CorpusEntry{Data: aarch64, Action: seccomp.ActionAllow},
+	)
+	testFilter(t, nil).Replay(path)
+
+	path = writeCorpus(t,
+		seccomp.CorpusEntry{Data: read, Action: seccomp.ActionAllow},
+		seccomp.CorpusEntry{Data: ptrace, Action: seccomp.ActionUserNotify},
+	)
+	r := &recorder{TB: t}
+	testFilter(t, r).Replay(path)
+	if len(r.errors) != 1 {
+		t.Fatalf("expected 1 failure, got %q", r.errors)
+	}
+	if want := "of 2 recorded calls in " + path + ":\nx86_64 ptrace(0x0, 0x0, 0x0, 0x0, 0x0, 0x0): user_notif -> errno(38) ("; !strings.Contains(r.errors[0], want) {
+		t.Errorf("expected %q in the failure, got %q", want, r.errors[0])
+	}
+}
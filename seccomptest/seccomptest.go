@@ -0,0 +1,183 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// Filter is an assembled filter under test. Its methods fail the test when
+// the filter does not behave as expected.
+type Filter struct {
+	tb        testing.TB
+	arch      *arch.Info
+	insts     []bpf.Instruction
+	emulator  *seccomp.Emulator
+	policy    *seccomp.Policy    // Nil if the filter has no policy.
+	simulator *seccomp.Simulator // Nil if the filter has no policy.
+}
+
+// New assembles the policy and returns the filter under test. It fails the
+// test if the policy is invalid.
+func New(tb testing.TB, p seccomp.Policy) *Filter {
+	tb.Helper()
+	insts, err := p.Assemble()
+	if err != nil {
+		tb.Fatalf("failed to assemble policy: %v", err)
+	}
+	f := NewProgram(tb, insts, p.Arch())
+	f.policy = &p
+	if f.simulator, err = seccomp.NewSimulator(&p); err != nil {
+		tb.Fatalf("failed to simulate policy: %v", err)
+	}
+	return f
+}
+
+// NewProgram returns a filter under test for instructions that were not
+// assembled from a policy, such as a filter read from a process. The names
+// of the syscalls are looked up for the arch, which defaults to the native
+// one if nil.
+func NewProgram(tb testing.TB, insts []bpf.Instruction, info *arch.Info) *Filter {
+	tb.Helper()
+	if info == nil {
+		var err error
+		if info, err = arch.GetInfo(""); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	e, err := seccomp.NewEmulator(insts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &Filter{tb: tb, arch: info, insts: insts, emulator: e}
+}
+
+// Data returns the seccomp_data of a call of the syscall with the arguments
+// on the arch. The number of x32 syscalls includes the x32 bit, and their
+// arch is x86_64, as the kernel reports them. It returns false if the arch
+// does not have the syscall.
+func Data(info *arch.Info, name string, args ...uint64) (seccomp.SeccompData, bool) {
+	nr, found := info.SyscallNumber(name)
+	if !found {
+		return seccomp.SeccompData{}, false
+	}
+	d := seccomp.SeccompData{NR: int32(nr | info.SeccompMask), Arch: uint32(info.ID)}
+	if info == arch.X32 {
+		d.Arch = uint32(arch.X86_64.ID)
+	}
+	copy(d.Args[:], args)
+	return d, true
+}
+
+// Run returns the action that the filter returns for a call of the syscall
+// with the arguments, including the data of the action such as the errno.
+func (f *Filter) Run(name string, args ...uint64) seccomp.Action {
+	f.tb.Helper()
+	d, found := Data(f.arch, name, args...)
+	if !found {
+		f.tb.Fatalf("unknown syscall %q on %v", name, f.arch.Name)
+	}
+	return f.RunData(d)
+}
+
+// RunData returns the action that the filter returns for the data.
+func (f *Filter) RunData(d seccomp.SeccompData) seccomp.Action {
+	f.tb.Helper()
+	action, err := f.emulator.Run(d)
+	if err != nil {
+		f.tb.Fatalf("failed to run filter: %v", err)
+	}
+	return action
+}
+
+// Expect checks that the filter returns the action for a call of the
+// syscall with the arguments. An errno action without data is expected as
+// EPERM, which is what the filter returns for it.
+func (f *Filter) Expect(want seccomp.Action, name string, args ...uint64) {
+	f.tb.Helper()
+	if want == seccomp.ActionErrno {
+		want |= seccomp.Action(errnoEPERM)
+	}
+	if got := f.Run(name, args...); got != want {
+		f.tb.Errorf("expected %v for %v, got %v%s", want, call(name, args), got, f.explain(name, args))
+	}
+}
+
+// Allows checks that the filter lets a call of the syscall with the
+// arguments run, with ActionAllow or ActionLog.
+func (f *Filter) Allows(name string, args ...uint64) {
+	f.tb.Helper()
+	if got := f.Run(name, args...); !allows(got) {
+		f.tb.Errorf("expected %v to be allowed, got %v%s", call(name, args), got, f.explain(name, args))
+	}
+}
+
+// Denies checks that the filter does not let a call of the syscall with the
+// arguments run unsupervised, that is that it returns neither ActionAllow
+// nor ActionLog.
+func (f *Filter) Denies(name string, args ...uint64) {
+	f.tb.Helper()
+	if got := f.Run(name, args...); allows(got) {
+		f.tb.Errorf("expected %v to be denied, got %v%s", call(name, args), got, f.explain(name, args))
+	}
+}
+
+// errnoEPERM is the errno that the filter returns for errno actions without
+// data.
+const errnoEPERM = 1
+
+func allows(a seccomp.Action) bool {
+	return a == seccomp.ActionAllow || a == seccomp.ActionLog
+}
+
+// explain returns the reason of the policy for the decision, or nothing for
+// filters without a policy.
+func (f *Filter) explain(name string, args []uint64) string {
+	d, _ := Data(f.arch, name, args...)
+	return f.explainData(d)
+}
+
+// explainData is like explain for the data.
+func (f *Filter) explainData(d seccomp.SeccompData) string {
+	if f.simulator == nil {
+		return ""
+	}
+	sim, err := f.simulator.Run(d)
+	if err != nil {
+		return ""
+	}
+	return " (" + sim.Reason + ")"
+}
+
+// call formats a call for failure messages.
+func call(name string, args []uint64) string {
+	s := name + "("
+	for i, a := range args {
+		if i > 0 {
+			s += ", "
+		}
+		s += "0x" + strconv.FormatUint(a, 16)
+	}
+	return s + ")"
+}
@@ -0,0 +1,154 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// recorder is a testing.TB that records failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	panic(r)
+}
+
+func (r *recorder) Fatal(args ...any) {
+	r.Fatalf("%s", fmt.Sprint(args...))
+}
+
+var testPolicy = seccomp.Policy{
+	DefaultAction: seccomp.ActionErrno | 38,
+	Syscalls: []seccomp.SyscallGroup{
+		{
+			Action: seccomp.ActionAllow,
+			Names:  []string{"read", "write"},
+		},
+		{
+			Action: seccomp.ActionErrno | 13,
+			NamesWithCondtions: []seccomp.NameWithConditions{
+				{Name: "openat", Conditions: []seccomp.Condition{{Argument: 2, Operation: seccomp.BitsSet, Value: 1}}},
+			},
+		},
+		{
+			Action: seccomp.ActionLog,
+			Names:  []string{"openat"},
+		},
+		{
+			Action: seccomp.ActionErrno,
+			Names:  []string{"mount"},
+		},
+	},
+}
+
+func TestFilter(t *testing.T) {
+	for _, name := range []string{"x86_64", "aarch64", "i386", "arm"} {
+		t.Run(name, func(t *testing.T) {
+			policy := testPolicy
+			if err := policy.SetArch(name); err != nil {
+				t.Fatal(err)
+			}
+			f := New(t, policy)
+			f.Allows("read")
+			f.Allows("openat", 0, 0, 0)
+			f.Denies("openat", 0, 0, 1)
+			f.Denies("ptrace")
+			f.Expect(seccomp.ActionErrno|13, "openat", 0, 0, 1)
+			f.Expect(seccomp.ActionErrno, "mount")
+			f.Expect(seccomp.ActionErrno|38, "ptrace")
+		})
+	}
+}
+
+func TestFilterFailures(t *testing.T) {
+	policy := testPolicy
+	if err := policy.SetArch("x86_64"); err != nil {
+		t.Fatal(err)
+	}
+	r := &recorder{TB: t}
+	f := New(r, policy)
+	f.Allows("ptrace")
+	f.Denies("read")
+	f.Expect(seccomp.ActionAllow, "openat", 0, 0, 1)
+
+	if len(r.errors) != 3 {
+		t.Fatalf("expected 3 failures, got %q", r.errors)
+	}
+	for i, want := range []string{
+		"expected ptrace() to be allowed, got errno(38) (",
+		"expected read() to be denied, got allow (",
+		"expected allow for openat(0x0, 0x0, 0x1), got errno(13) (",
+	} {
+		if !strings.HasPrefix(r.errors[i], want) {
+			t.Errorf("expected failure %q, got %q", want, r.errors[i])
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() != r {
+				t.Error("expected unknown syscalls to fail the test")
+			}
+		}()
+		f.Run("no_such_syscall")
+	}()
+}
+
+func TestNewProgram(t *testing.T) {
+	policy := testPolicy
+	if err := policy.SetArch("aarch64"); err != nil {
+		t.Fatal(err)
+	}
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+	f := NewProgram(r, insts, arch.AARCH64)
+	f.Allows("write")
+	f.Allows("mount")
+	if len(r.errors) != 1 || r.errors[0] != "expected mount() to be allowed, got errno(1)" {
+		t.Errorf("expected a failure without explanation, got %q", r.errors)
+	}
+}
+
+func TestData(t *testing.T) {
+	d, found := Data(arch.X32, "write", 1)
+	if !found || d.NR != 1|int32(arch.X32.SeccompMask) || d.Arch != uint32(arch.X86_64.ID) || d.Args[0] != 1 {
+		t.Errorf("unexpected x32 data %+v", d)
+	}
+	if _, found = Data(arch.AARCH64, "open"); found {
+		t.Error("expected aarch64 not to have open")
+	}
+}
@@ -0,0 +1,305 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// SeedFlag is the name of the test flag that sets the seed of the random
+// inputs of AllowsOnly, to reproduce a failure.
+const SeedFlag = "seccomptest.seed"
+
+var seed = flag.Uint64(SeedFlag, 0, "seed of the random seccomp_data generated by seccomptest, zero picks one")
+
+// randomTrials is the number of random inputs that AllowsOnly runs in
+// addition to the systematic ones.
+const randomTrials = 20000
+
+// maxSoundnessFailures limits the number of inputs listed in a failure.
+const maxSoundnessFailures = 10
+
+// auditArches are the arches whose audit arch values are used as foreign and
+// spoofed arches.
+var auditArches = []*arch.Info{
+	arch.ARM, arch.AARCH64, arch.I386, arch.X32, arch.X86_64,
+	arch.PPC, arch.PPC64, arch.PPC64LE, arch.S390, arch.S390X,
+	arch.MIPS, arch.MIPSEL, arch.MIPS64, arch.MIPS64N32, arch.MIPSEL64, arch.MIPSEL64N32,
+}
+
+// Audit arch bits that are flipped to spoof the arch.
+const (
+	auditArch64Bit = 0x80000000
+	auditArchLE    = 0x40000000
+)
+
+// Sound checks AllowsOnly with the syscalls that the policy permits, that is
+// the syscalls of groups with ActionAllow or ActionLog and the Go runtime
+// syscalls if they are included. It fails the test if the default action of
+// the policy permits calls, because then there is no allowlist to check.
+func (f *Filter) Sound() {
+	f.tb.Helper()
+	if f.policy == nil {
+		f.tb.Fatal("Sound requires a filter assembled from a policy, use AllowsOnly")
+	}
+	if allows(f.policy.DefaultAction) {
+		f.tb.Fatalf("the default action %v of the policy permits all syscalls", f.policy.DefaultAction)
+	}
+
+	var names []string
+	named := map[string]bool{}
+	for _, group := range f.policy.Syscalls {
+		for _, name := range group.Names {
+			named[name] = true
+		}
+		for _, nc := range group.NamesWithCondtions {
+			named[nc.Name] = true
+		}
+		if !allows(group.Action) {
+			continue
+		}
+		names = append(names, group.Names...)
+		for _, nc := range group.NamesWithCondtions {
+			names = append(names, nc.Name)
+		}
+	}
+	if f.policy.IncludeGoRuntime {
+		// The runtime syscalls that the policy does not name are allowed.
+		for _, name := range seccomp.GoRuntimeSyscalls {
+			if _, found := f.arch.SyscallNumber(name); found && !named[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	f.AllowsOnly(names...)
+}
+
+// AllowsOnly checks that the filter returns ActionAllow or ActionLog only for
+// calls of the named syscalls on its arch. It runs the filter for every
+// syscall number of the arch and boundary numbers, also with the x32 bit and
+// the top bit set, for the audit arch values of all known and of spoofed
+// arches, and with argument values that are syscall numbers or compared by
+// the filter. It then runs random inputs, whose seed is reported on failure
+// and can be set with -seccomptest.seed.
+//
+// Unlike Policy.Verify, which checks the filter against the decisions the
+// policy intends for every input, AllowsOnly only relies on the names, so it
+// also catches a compiler that is wrong about the intent of the policy.
+func (f *Filter) AllowsOnly(names ...string) {
+	f.tb.Helper()
+	allowed := map[uint32]bool{}
+	for _, name := range names {
+		d, found := Data(f.arch, name)
+		if !found {
+			f.tb.Fatalf("unknown syscall %q on %v", name, f.arch.Name)
+		}
+		allowed[uint32(d.NR)] = true
+	}
+
+	s := *seed
+	if s == 0 {
+		s = rand.Uint64()
+	}
+
+	// Failures are reported once per arch and syscall number.
+	var failures []seccomp.SeccompData
+	failed := map[[2]uint32]bool{}
+	check := func(d seccomp.SeccompData) {
+		key := [2]uint32{d.Arch, uint32(d.NR)}
+		if len(failures) == maxSoundnessFailures || failed[key] {
+			return
+		}
+		if allows(f.RunData(d)) && (d.Arch != uint32(f.arch.ID) || !allowed[uint32(d.NR)]) {
+			failed[key] = true
+			failures = append(failures, d)
+		}
+	}
+
+	nrs, compared, values := f.soundnessInputs(allowed)
+	args := [][6]uint64{{}, {math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64}}
+	for _, v := range values {
+		args = append(args, [6]uint64{v, v, v, v, v, v})
+	}
+
+	// Arguments are only tried in full for the numbers that the filter
+	// compares, the filter does not load them for the others.
+	for _, nr := range nrs {
+		for _, a := range args[:2] {
+			check(seccomp.SeccompData{NR: int32(nr), Arch: uint32(f.arch.ID), Args: a})
+		}
+	}
+	for _, nr := range compared {
+		for _, a := range args[2:] {
+			check(seccomp.SeccompData{NR: int32(nr), Arch: uint32(f.arch.ID), Args: a})
+		}
+	}
+	arches := spoofedArches(f.arch)
+	for _, id := range arches {
+		for _, nr := range compared {
+			for _, a := range args[:2] {
+				check(seccomp.SeccompData{NR: int32(nr), Arch: id, Args: a})
+			}
+		}
+	}
+
+	// Random inputs mix the values above with arbitrary ones.
+	rnd := rand.New(rand.NewPCG(s, s))
+	arches = append(arches, uint32(f.arch.ID))
+	pick := func(from []uint32) uint32 {
+		if rnd.IntN(4) == 0 {
+			return rnd.Uint32()
+		}
+		return from[rnd.IntN(len(from))]
+	}
+	for i := 0; i < randomTrials && len(failures) < maxSoundnessFailures; i++ {
+		d := seccomp.SeccompData{NR: int32(pick(nrs)), Arch: pick(arches)}
+		for j := range d.Args {
+			if len(values) > 0 && rnd.IntN(2) == 0 {
+				d.Args[j] = values[rnd.IntN(len(values))]
+			} else {
+				d.Args[j] = rnd.Uint64()
+			}
+		}
+		check(d)
+	}
+
+	if len(failures) > 0 {
+		var sb strings.Builder
+		for _, d := range failures {
+			fmt.Fprintf(&sb, "\n%v nr=%#x arch=0x%08x args=%#x: %v", describeData(d), uint32(d.NR), d.Arch, d.Args, f.RunData(d))
+		}
+		f.tb.Errorf("filter permits syscalls outside of the allowlist (-%s=%d):%s", SeedFlag, s, sb.String())
+	}
+}
+
+// soundnessInputs returns the syscall numbers to run, the subset of them
+// that the filter compares or that are allowed, and the argument values to
+// try. Each number is also varied with the x32 bit and the top bit.
+func (f *Filter) soundnessInputs(allowed map[uint32]bool) (nrs, compared []uint32, values []uint64) {
+	all := map[uint32]struct{}{}
+	some := map[uint32]struct{}{0: {}, math.MaxUint32: {}}
+	add := func(nr uint32, important bool) {
+		for _, v := range []uint32{nr, nr | uint32(arch.X32.SeccompMask), nr | 1<<31, nr ^ uint32(f.arch.SeccompMask)} {
+			all[v] = struct{}{}
+			if important {
+				some[v] = struct{}{}
+			}
+		}
+	}
+
+	maxNR := 0
+	for _, info := range auditArches {
+		for nr := range info.Syscalls() {
+			if nr < 1024 && nr > maxNR {
+				maxNR = nr
+			}
+		}
+	}
+	for nr := 0; nr <= maxNR+1; nr++ {
+		add(uint32(nr), false)
+	}
+	for nr := range f.arch.Syscalls() {
+		add(uint32(nr), false)
+	}
+	for _, nr := range []uint32{math.MaxInt16, math.MaxUint16, math.MaxInt32, math.MaxUint32} {
+		add(nr, true)
+	}
+
+	for nr := range allowed {
+		add(nr, true)
+		values = append(values, uint64(nr), uint64(nr)|1<<32)
+	}
+
+	// The values compared by the filter, including argument values.
+	for _, inst := range f.insts {
+		if jump, ok := inst.(bpf.JumpIf); ok {
+			add(jump.Val, true)
+			values = append(values, uint64(jump.Val), uint64(jump.Val)-1, uint64(jump.Val)+1, uint64(jump.Val)<<32)
+		}
+	}
+
+	for nr := range all {
+		nrs = append(nrs, nr)
+	}
+	for nr := range some {
+		compared = append(compared, nr)
+	}
+	sort.Slice(nrs, func(i, j int) bool { return nrs[i] < nrs[j] })
+	sort.Slice(compared, func(i, j int) bool { return compared[i] < compared[j] })
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return nrs, compared, dedup(values)
+}
+
+// spoofedArches returns the audit arch values of the known arches other than
+// info, the value of info with the 64-bit and little-endian bits flipped or
+// with only its machine type, and values that no arch uses.
+func spoofedArches(info *arch.Info) []uint32 {
+	own := uint32(info.ID)
+	set := map[uint32]struct{}{
+		0:                    {},
+		math.MaxUint32:       {},
+		own ^ auditArch64Bit: {},
+		own ^ auditArchLE:    {},
+		own & math.MaxUint16: {},
+		own + 1:              {},
+	}
+	for _, other := range auditArches {
+		set[uint32(other.ID)] = struct{}{}
+	}
+	delete(set, own)
+
+	arches := make([]uint32, 0, len(set))
+	for id := range set {
+		arches = append(arches, id)
+	}
+	sort.Slice(arches, func(i, j int) bool { return arches[i] < arches[j] })
+	return arches
+}
+
+// describeData names the syscall of the data on its arch, if known.
+func describeData(d seccomp.SeccompData) string {
+	for _, info := range auditArches {
+		if uint32(info.ID) != d.Arch || uint32(d.NR)&uint32(arch.X32.SeccompMask) != uint32(info.SeccompMask) {
+			continue
+		}
+		if name := info.SyscallName(int(uint32(d.NR) &^ uint32(info.SeccompMask))); name != "" {
+			return info.Name + " " + name
+		}
+	}
+	return "unknown syscall"
+}
+
+func dedup(values []uint64) []uint64 {
+	var out []uint64
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
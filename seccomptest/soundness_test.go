@@ -0,0 +1,103 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomptest
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestSound(t *testing.T) {
+	for _, name := range []string{"x86_64", "x32", "aarch64", "i386", "arm"} {
+		t.Run(name, func(t *testing.T) {
+			policy := testPolicy
+			policy.IncludeGoRuntime = true
+			if err := policy.SetArch(name); err != nil {
+				t.Fatal(err)
+			}
+			New(t, policy).Sound()
+		})
+	}
+}
+
+func TestAllowsOnlyFailure(t *testing.T) {
+	r := &recorder{TB: t}
+	testFilter(t, r).AllowsOnly("read", "openat")
+	if len(r.errors) != 1 {
+		t.Fatalf("expected 1 failure, got %q", r.errors)
+	}
+	if !strings.Contains(r.errors[0], "-seccomptest.seed=") || !strings.Contains(r.errors[0], "\nx86_64 write nr=0x1 ") {
+		t.Errorf("expected write to be reported, got %q", r.errors[0])
+	}
+}
+
+func TestAllowsOnlyUnsoundProgram(t *testing.T) {
+	allowRead := []bpf.Instruction{
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0, SkipFalse: 1},
+		bpf.RetConstant{Val: uint32(seccomp.ActionAllow)},
+		bpf.RetConstant{Val: uint32(seccomp.ActionErrno)},
+	}
+	tests := map[string]struct {
+		insts []bpf.Instruction
+		want  string
+	}{
+		"no arch check": {
+			insts: append([]bpf.Instruction{bpf.LoadAbsolute{Off: 0, Size: 4}}, allowRead...),
+			want:  "\nunknown syscall nr=0x0 arch=0x00000000 ",
+		},
+		"x32 bit masked": {
+			insts: append([]bpf.Instruction{
+				bpf.LoadAbsolute{Off: 4, Size: 4},
+				bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(arch.X86_64.ID), SkipTrue: 1},
+				bpf.RetConstant{Val: uint32(seccomp.ActionKillProcess)},
+				bpf.LoadAbsolute{Off: 0, Size: 4},
+				bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: ^uint32(arch.X32.SeccompMask)},
+			}, allowRead...),
+			want: "\nx32 read nr=0x40000000 arch=0xc000003e ",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			NewProgram(r, tc.insts, arch.X86_64).AllowsOnly("read")
+			if len(r.errors) != 1 || !strings.Contains(r.errors[0], tc.want) {
+				t.Errorf("expected %q to be reported, got %q", tc.want, r.errors)
+			}
+		})
+	}
+}
+
+func TestSoundRequiresAllowlist(t *testing.T) {
+	policy := testPolicy
+	policy.DefaultAction = seccomp.ActionAllow
+	r := &recorder{TB: t}
+	func() {
+		defer func() {
+			if recover() != r {
+				t.Error("expected a policy that allows by default to fail the test")
+			}
+		}()
+		New(r, policy).Sound()
+	}()
+}
@@ -0,0 +1,59 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"fmt"
+	"os"
+)
+
+// shimEnv is the environment variable that tells a re-executed copy of the
+// current binary which shim to run instead of main.
+const shimEnv = "_GO_SECCOMP_BPF_SHIM"
+
+// shims contains the functions that can be run in a re-executed copy of the
+// current binary. The returned value is used as exit code.
+var shims = map[string]func() int{}
+
+// registerShim registers a shim. It must be called from a package level
+// variable initializer so the shim is registered before init runs.
+func registerShim(name string, shim func() int) string {
+	shims[name] = shim
+	return name
+}
+
+func init() {
+	name, found := os.LookupEnv(shimEnv)
+	if !found {
+		return
+	}
+	os.Unsetenv(shimEnv)
+
+	shim, found := shims[name]
+	if !found {
+		fmt.Fprintf(os.Stderr, "seccomp: unknown shim %q\n", name)
+		os.Exit(127)
+	}
+	os.Exit(shim())
+}
+
+// selfExe is the path used to re-execute the current binary.
+const selfExe = "/proc/self/exe"
@@ -0,0 +1,162 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// Simulation describes the decision of a policy for one syscall.
+type Simulation struct {
+	Data    SeccompData // Input given to the filter.
+	Syscall string      // Name of the syscall, empty if it is not a syscall of the policy's arch (or x32).
+	Action  Action      // Action returned by the assembled filter.
+	Intent  Action      // Action intended by the policy. It differs from Action only if the filter is wrong.
+
+	// Group is the index of the matching group in Policy.Syscalls or -1 if no
+	// group matched. It equals len(Policy.Syscalls) for the Go runtime group.
+	Group      int
+	GoRuntime  bool               // The Go runtime group matched.
+	Conditions ArgumentConditions // Conditions of the matching rule, nil if it has none.
+	Reason     string             // Explanation of which part of the policy decided.
+
+	// Missed lists the rules for the syscall that come before the matching
+	// group, or all of them if no group matched, and whose conditions the
+	// arguments do not satisfy.
+	Missed []MissedRule
+}
+
+// MissedRule is a rule with argument conditions that did not match.
+type MissedRule struct {
+	Group      int                // Index of the group of the rule.
+	Conditions ArgumentConditions // Conditions of the rule.
+	Failed     ArgumentConditions // Conditions that the arguments do not satisfy.
+}
+
+// Simulator evaluates a policy for single syscalls in the Emulator and
+// reports which rule decided, e.g. for debugging a policy.
+type Simulator struct {
+	policy   *Policy
+	emulator *Emulator
+	groups   []compiledGroup
+}
+
+// NewSimulator assembles the policy and returns a Simulator for it.
+func NewSimulator(p *Policy) (*Simulator, error) {
+	insts, err := p.Assemble()
+	if err != nil {
+		return nil, err
+	}
+	emulator, err := NewEmulator(insts)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := p.compileGroups()
+	if err != nil {
+		return nil, err
+	}
+	return &Simulator{policy: p, emulator: emulator, groups: groups}, nil
+}
+
+// Run evaluates the syscall described by data. An Arch of zero is replaced
+// by the audit arch of the policy.
+func (s *Simulator) Run(data SeccompData) (*Simulation, error) {
+	p := s.policy
+	if data.Arch == 0 {
+		data.Arch = uint32(p.arch.ID)
+	}
+
+	action, err := s.emulator.Run(data)
+	if err != nil {
+		return nil, err
+	}
+
+	nr := uint32(data.NR)
+	sim := &Simulation{
+		Data:   data,
+		Action: action,
+		Intent: p.intent(s.groups, data),
+		Group:  -1,
+	}
+	if data.Arch == uint32(p.arch.ID) {
+		sim.Syscall = p.arch.SyscallName(int(nr))
+	}
+
+	switch {
+	case data.Arch != uint32(p.arch.ID):
+		sim.Reason = fmt.Sprintf("arch %v is not the policy arch %v, the default action applies",
+			arch.AuditArch(data.Arch), p.arch.Name)
+	case p.arch.ID == arch.X86_64.ID && nr >= uint32(arch.X32.SeccompMask):
+		sim.Syscall = arch.X32.SyscallName(int(nr &^ uint32(arch.X32.SeccompMask)))
+		sim.Reason = "x32 syscalls are rejected with ENOSYS"
+	default:
+		sim.Group, sim.Conditions = matchGroup(s.groups, nr, data.Args)
+		sim.GoRuntime = sim.Group == len(p.Syscalls)
+		sim.Missed = s.missed(sim.Group, nr, data.Args)
+		sim.Reason = s.reason(sim)
+	}
+	return sim, nil
+}
+
+// missed returns the rules for the syscall in the groups before the matching
+// group whose conditions do not match.
+func (s *Simulator) missed(matched int, nr uint32, args [6]uint64) []MissedRule {
+	var missed []MissedRule
+	for i, group := range s.groups {
+		if i == matched {
+			break
+		}
+		syscall := getSyscall(group.syscalls, nr)
+		if syscall == nil {
+			continue
+		}
+		for _, conditions := range syscall.Conditions {
+			var failed ArgumentConditions
+			for _, c := range conditions {
+				if !c.matches(args[c.Argument]) {
+					failed = append(failed, c)
+				}
+			}
+			missed = append(missed, MissedRule{Group: i, Conditions: conditions, Failed: failed})
+		}
+	}
+	return missed
+}
+
+func (s *Simulator) reason(sim *Simulation) string {
+	name := sim.Syscall
+	if name == "" {
+		name = fmt.Sprintf("syscall %d", sim.Data.NR)
+	}
+
+	var reason string
+	switch {
+	case sim.Group < 0:
+		return fmt.Sprintf("no rule matches %v, the default action applies", name)
+	case sim.GoRuntime:
+		reason = fmt.Sprintf("the Go runtime rule matches %v", name)
+	default:
+		reason = fmt.Sprintf("rule %d (%v) matches %v", sim.Group, s.groups[sim.Group].action, name)
+	}
+	if len(sim.Conditions) > 0 {
+		reason += " because " + describeConditions(sim.Conditions)
+	}
+	return reason
+}
@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestSimulator(t *testing.T) {
+	policy := &Policy{
+		arch:             arch.X86_64,
+		DefaultAction:    ActionErrno,
+		IncludeGoRuntime: true,
+		Syscalls: []SyscallGroup{
+			{
+				Action: ActionKillProcess,
+				NamesWithCondtions: []NameWithConditions{
+					{
+						Name: "socket",
+						Conditions: []Condition{
+							{Argument: 0, Operation: Equal, Value: 17},
+						},
+					},
+				},
+			},
+			{Names: []string{"socket", "connect"}, Action: ActionLog},
+		},
+	}
+	sim, err := NewSimulator(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nr := func(name string) int32 { return int32(syscallNumber(arch.X86_64, name)) }
+	testCases := []struct {
+		data      SeccompData
+		action    Action
+		group     int
+		goRuntime bool
+		reason    string
+		missed    []MissedRule
+	}{
+		{
+			data:   SeccompData{NR: nr("socket"), Args: [6]uint64{17}},
+			action: ActionKillProcess,
+			group:  0,
+			reason: "rule 0 (kill_process) matches socket because arg0 is 0x11",
+		},
+		{
+			data:   SeccompData{NR: nr("socket"), Args: [6]uint64{2}},
+			action: ActionLog,
+			group:  1,
+			reason: "rule 1 (log) matches socket",
+			missed: []MissedRule{{
+				Group:      0,
+				Conditions: ArgumentConditions{{Argument: 0, Operation: Equal, Value: 17}},
+				Failed:     ArgumentConditions{{Argument: 0, Operation: Equal, Value: 17}},
+			}},
+		},
+		{
+			data:      SeccompData{NR: nr("futex")},
+			action:    ActionAllow,
+			group:     2,
+			goRuntime: true,
+			reason:    "the Go runtime rule matches futex",
+		},
+		{
+			data:   SeccompData{NR: nr("ptrace")},
+			action: ActionErrno | Action(errnoEPERM),
+			group:  -1,
+			reason: "no rule matches ptrace, the default action applies",
+		},
+		{
+			data:   SeccompData{NR: nr("read") | int32(arch.X32.SeccompMask)},
+			action: ActionErrno | Action(errnoENOSYS),
+			group:  -1,
+			reason: "x32 syscalls are rejected with ENOSYS",
+		},
+		{
+			data:   SeccompData{NR: nr("read"), Arch: uint32(arch.I386.ID)},
+			action: ActionErrno | Action(errnoEPERM),
+			group:  -1,
+			reason: "arch i386 is not the policy arch x86_64, the default action applies",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := sim.Run(tc.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Action != tc.action || got.Intent != tc.action {
+			t.Errorf("nr=%d: expected %v, got action %v and intent %v", tc.data.NR, tc.action, got.Action, got.Intent)
+		}
+		if got.Group != tc.group || got.GoRuntime != tc.goRuntime {
+			t.Errorf("nr=%d: expected group %d, got %d (Go runtime %v)", tc.data.NR, tc.group, got.Group, got.GoRuntime)
+		}
+		if got.Reason != tc.reason {
+			t.Errorf("nr=%d: expected reason %q, got %q", tc.data.NR, tc.reason, got.Reason)
+		}
+		if !reflect.DeepEqual(got.Missed, tc.missed) {
+			t.Errorf("nr=%d: expected missed rules %+v, got %+v", tc.data.NR, tc.missed, got.Missed)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ViolationSink receives violations, for example to keep an audit trail.
+// ECSWriter, JournalWriter, and SyslogWriter are sinks.
+type ViolationSink interface {
+	Write(v Violation) error
+}
+
+var _ ViolationSink = (*ECSWriter)(nil)
+
+// denied returns true unless the action let the syscall execute or left the
+// decision to a supervisor.
+func (v Violation) denied() bool {
+	switch v.Action & actionMask {
+	case ActionLog, ActionAllow, ActionUserNotify:
+		return false
+	}
+	return true
+}
+
+// violationField is a structured field of a violation, written by the
+// journal and syslog sinks.
+type violationField struct {
+	name, value string
+}
+
+// fields returns the known fields of the violation.
+func (v Violation) fields() []violationField {
+	fields := []violationField{
+		{"source", v.Source},
+		{"action", v.Action.String()},
+	}
+	if !v.Time.IsZero() {
+		fields = append(fields, violationField{"time", v.Time.UTC().Format(time.RFC3339Nano)})
+	}
+	if v.Syscall != "" {
+		fields = append(fields, violationField{"syscall", v.Syscall})
+	}
+	if v.Arch != nil {
+		fields = append(fields, violationField{"arch", v.Arch.Name})
+	}
+	if v.Data != nil {
+		args := make([]string, len(v.Data.Args))
+		for i, a := range v.Data.Args {
+			args[i] = fmt.Sprintf("0x%x", a)
+		}
+		fields = append(fields,
+			violationField{"syscall_nr", strconv.Itoa(int(v.Data.NR))},
+			violationField{"args", strings.Join(args, ",")})
+	}
+	if v.PID != 0 {
+		fields = append(fields, violationField{"pid", strconv.Itoa(v.PID)})
+	}
+	if v.Comm != "" {
+		fields = append(fields, violationField{"comm", v.Comm})
+	}
+	if v.Exe != "" {
+		fields = append(fields, violationField{"exe", v.Exe})
+	}
+	return fields
+}
+
+// syslogMessage returns the summary of the violation followed by its fields
+// as key=value pairs, quoting values that contain spaces or quotes.
+func (v Violation) syslogMessage() string {
+	var b strings.Builder
+	b.WriteString(v.message())
+	b.WriteByte(':')
+	for _, f := range v.fields() {
+		value := f.value
+		if value == "" || strings.ContainsAny(value, " \"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, " %s=%s", f.name, value)
+	}
+	return b.String()
+}
@@ -0,0 +1,144 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log/syslog"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// journalSocket is the socket of the native protocol of systemd-journald.
+const journalSocket = "/run/systemd/journal/socket"
+
+// Journal priorities, as in syslog.
+const (
+	journalPriorityWarning = 4
+	journalPriorityInfo    = 6
+)
+
+// JournalWriter writes violations to the systemd journal. Every field of the
+// violation is a SECCOMP_ field of the entry (e.g. SECCOMP_SYSCALL,
+// SECCOMP_ACTION, SECCOMP_PID), so entries can be matched with journalctl.
+// Denied syscalls are logged with warning priority, others with info
+// priority. It is safe for concurrent use.
+type JournalWriter struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+// NewJournalWriter connects to the journal. The identifier is the
+// SYSLOG_IDENTIFIER of the entries, it is left out if empty.
+func NewJournalWriter(identifier string) (*JournalWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the journal: %w", err)
+	}
+	return newJournalWriter(conn, identifier), nil
+}
+
+func newJournalWriter(conn *net.UnixConn, identifier string) *JournalWriter {
+	return &JournalWriter{conn: conn, identifier: identifier}
+}
+
+// Write writes the violation as one entry.
+func (j *JournalWriter) Write(v Violation) error {
+	priority := journalPriorityInfo
+	if v.denied() {
+		priority = journalPriorityWarning
+	}
+
+	b := appendJournalField(nil, "MESSAGE", v.message())
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(priority))
+	if j.identifier != "" {
+		b = appendJournalField(b, "SYSLOG_IDENTIFIER", j.identifier)
+	}
+	for _, f := range v.fields() {
+		b = appendJournalField(b, "SECCOMP_"+strings.ToUpper(f.name), f.value)
+	}
+	if _, err := j.conn.Write(b); err != nil {
+		return fmt.Errorf("failed to write to the journal: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the journal.
+func (j *JournalWriter) Close() error {
+	return j.conn.Close()
+}
+
+// appendJournalField appends a field in the native journal protocol. Values
+// with newlines are written with their size instead of as a line.
+// https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+func appendJournalField(b []byte, name, value string) []byte {
+	b = append(b, name...)
+	if !strings.Contains(value, "\n") {
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// SyslogWriter writes violations to syslog with the auth facility. The
+// message is a summary followed by the fields of the violation as key=value
+// pairs. Denied syscalls are logged with warning severity, others with info
+// severity. It is safe for concurrent use.
+type SyslogWriter struct {
+	w syslogger
+}
+
+// syslogger is implemented by syslog.Writer.
+type syslogger interface {
+	Warning(m string) error
+	Info(m string) error
+	Close() error
+}
+
+// NewSyslogWriter connects to the syslog daemon at raddr on the network, or
+// to the local daemon if network is empty, like syslog.Dial. The tag is the
+// program name of the messages.
+func NewSyslogWriter(network, raddr, tag string) (*SyslogWriter, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_AUTH|syslog.LOG_WARNING, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogWriter{w: w}, nil
+}
+
+// Write writes the violation as one message.
+func (s *SyslogWriter) Write(v Violation) error {
+	if v.denied() {
+		return s.w.Warning(v.syslogMessage())
+	}
+	return s.w.Info(v.syslogMessage())
+}
+
+// Close closes the connection to syslog.
+func (s *SyslogWriter) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// journalPair returns a JournalWriter and the socket that receives its
+// entries. A socket pair is used because TestLoadFilter denies bind.
+func journalPair(t *testing.T, identifier string) (*JournalWriter, *net.UnixConn) {
+	t.Helper()
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "journal")
+		c, err := net.FileConn(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+	}
+	t.Cleanup(func() { conns[1].Close() })
+	return newJournalWriter(conns[0], identifier), conns[1]
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 64*1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestJournalWriter(t *testing.T) {
+	w, conn := journalPair(t, "app")
+	defer w.Close()
+
+	v := Violation{Source: ViolationSourceAudit, PID: 42, Arch: arch.X86_64, Syscall: "mount", Action: ActionErrno | 1}
+	if err := w.Write(v); err != nil {
+		t.Fatal(err)
+	}
+	want := "MESSAGE=seccomp errno(1) for mount by pid 42\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=app\n" +
+		"SECCOMP_SOURCE=audit\n" +
+		"SECCOMP_ACTION=errno(1)\n" +
+		"SECCOMP_SYSCALL=mount\n" +
+		"SECCOMP_ARCH=x86_64\n" +
+		"SECCOMP_PID=42\n"
+	if got := readDatagram(t, conn); got != want {
+		t.Errorf("expected entry\n%s\ngot\n%s", want, got)
+	}
+
+	if err := w.Write(Violation{Source: ViolationSourceAudit, Syscall: "uname", Action: ActionLog}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readDatagram(t, conn); !strings.Contains(got, "\nPRIORITY=6\n") {
+		t.Errorf("expected info priority for a logged syscall, got\n%s", got)
+	}
+}
+
+func TestAppendJournalField(t *testing.T) {
+	got := appendJournalField(nil, "SECCOMP_COMM", "a\nb")
+	want := append([]byte("SECCOMP_COMM\n"), binary.LittleEndian.AppendUint64(nil, 3)...)
+	want = append(want, "a\nb\n"...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+type testSyslogger struct {
+	messages []string
+}
+
+func (s *testSyslogger) Warning(m string) error {
+	s.messages = append(s.messages, "warning: "+m)
+	return nil
+}
+
+func (s *testSyslogger) Info(m string) error {
+	s.messages = append(s.messages, "info: "+m)
+	return nil
+}
+
+func (s *testSyslogger) Close() error { return nil }
+
+func TestSyslogWriter(t *testing.T) {
+	logger := &testSyslogger{}
+	w := &SyslogWriter{w: logger}
+	for _, v := range []Violation{
+		{Source: ViolationSourceSIGSYS, Syscall: "getppid", Action: ActionTrap},
+		{Source: ViolationSourceAudit, Syscall: "uname", Action: ActionLog},
+	} {
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"warning: seccomp trap for getppid: source=sigsys action=trap syscall=getppid",
+		"info: seccomp log for uname: source=audit action=log syscall=uname",
+	}
+	if !reflect.DeepEqual(logger.messages, want) {
+		t.Errorf("expected messages %q, got %q", want, logger.messages)
+	}
+}
@@ -0,0 +1,58 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestViolationSyslogMessage(t *testing.T) {
+	record, _, err := ParseAuditRecord(strings.Split(testAuditLog, "\n")[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `seccomp errno(1) for mount by pid 1234 (my app): source=audit action=errno(1) ` +
+		`time=2023-11-14T22:13:20.125Z syscall=mount arch=x86_64 pid=1234 comm="my app" exe="/opt/my app"`
+	if got := record.Violation().syslogMessage(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	v := Violation{Source: ViolationSourceSIGSYS, Action: ActionTrap, Data: &SeccompData{NR: 1000, Args: [6]uint64{1}}}
+	const wantData = `seccomp trap for syscall 1000: source=sigsys action=trap syscall_nr=1000 args=0x1,0x0,0x0,0x0,0x0,0x0`
+	if got := v.syslogMessage(); got != wantData {
+		t.Errorf("expected\n%s\ngot\n%s", wantData, got)
+	}
+}
+
+func TestViolationDenied(t *testing.T) {
+	for action, want := range map[Action]bool{
+		ActionAllow:         false,
+		ActionLog:           false,
+		ActionUserNotify:    false,
+		ActionErrno | 1:     true,
+		ActionTrap:          true,
+		ActionKillProcess:   true,
+		ActionTrace | 0x100: true,
+	} {
+		if got := (Violation{Action: action}).denied(); got != want {
+			t.Errorf("expected denied=%v for %v", want, action)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"strconv"
+
+	"golang.org/x/net/bpf"
+)
+
+// Mode is the seccomp mode of a thread.
+type Mode int
+
+// List of seccomp modes.
+// https://github.com/torvalds/linux/blob/v4.16/include/uapi/linux/seccomp.h#L9-L12
+const (
+	ModeDisabled Mode = 0 // Seccomp is not enabled.
+	ModeStrict   Mode = 1 // Only read, write, _exit, and sigreturn are allowed.
+	ModeFilter   Mode = 2 // BPF filters are installed.
+)
+
+var modeNames = map[Mode]string{
+	ModeDisabled: "disabled",
+	ModeStrict:   "strict",
+	ModeFilter:   "filter",
+}
+
+// String returns a string representation of the Mode.
+func (m Mode) String() string {
+	if name, found := modeNames[m]; found {
+		return name
+	}
+	return "unknown(" + strconv.Itoa(int(m)) + ")"
+}
+
+// Status is the seccomp state of a thread.
+type Status struct {
+	Mode    Mode // Seccomp mode.
+	Filters int  // Number of installed filters (-1 if not reported, Linux 5.9+).
+}
+
+// Confined returns true if seccomp is enabled.
+func (s Status) Confined() bool {
+	return s.Mode != ModeDisabled
+}
+
+// InstalledFilter is a seccomp filter retrieved from a running process.
+type InstalledFilter struct {
+	Index        int               // Position in the filter stack (0 is the most recently installed).
+	Flags        FilterFlag        // Flags the filter was installed with (only FilterFlagLog is reported).
+	Instructions []bpf.Instruction // Disassembled BPF program.
+}
@@ -0,0 +1,92 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetStatus returns the seccomp state of the calling thread by reading the
+// Seccomp and Seccomp_filters fields from /proc/thread-self/status. It can
+// be used to detect that the process is already confined (e.g. by a
+// container runtime's default profile) and how many filters are stacked.
+func GetStatus() (*Status, error) {
+	status, err := readStatus("/proc/thread-self/status")
+	if errors.Is(err, os.ErrNotExist) {
+		// /proc/thread-self was added in Linux 3.17.
+		status, err = readStatus("/proc/self/status")
+	}
+	return status, err
+}
+
+// GetProcessStatus returns the seccomp state of the main thread of the given
+// process.
+func GetProcessStatus(pid int) (*Status, error) {
+	return readStatus(fmt.Sprintf("/proc/%d/status", pid))
+}
+
+func readStatus(path string) (*Status, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	status := &Status{Filters: -1}
+	var foundMode bool
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		key, value, found := strings.Cut(s.Text(), ":")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "Seccomp":
+			mode, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Seccomp value in %v: %w", path, err)
+			}
+			status.Mode = Mode(mode)
+			foundMode = true
+		case "Seccomp_filters":
+			filters, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Seccomp_filters value in %v: %w", path, err)
+			}
+			status.Filters = filters
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if !foundMode {
+		return nil, fmt.Errorf("seccomp state not found in %v (requires Linux 3.8+ with CONFIG_SECCOMP)", path)
+	}
+	return status, nil
+}
@@ -0,0 +1,121 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// straceCall matches a syscall line of strace after the optional pid and
+// timestamp prefixes (-f, -t, -tt, -ttt, -r).
+var straceCall = regexp.MustCompile(`^(?:\[pid\s+\d+\]\s*)?(?:[\d:.]+\s+)*(?:<\.\.\. (\w+) resumed>|(\w+)\((.*))$`)
+
+// ReadStrace reads the output of strace, optionally with -f and timestamps,
+// and of its -c summary. Arguments are only recorded for calls that
+// completed in one line. strace prints most flags symbolically, use
+// "-X raw" or "-e raw=all" to obtain numeric values that can be used for
+// argument conditions.
+func ReadStrace(r io.Reader) (*SyscallTrace, error) {
+	trace := NewSyscallTrace()
+
+	var summary bool
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "% time") {
+			summary = true
+			continue
+		}
+		if summary {
+			// % time     seconds  usecs/call     calls    errors syscall
+			fields := strings.Fields(line)
+			if len(fields) >= 5 && fields[len(fields)-1] != "total" {
+				if calls, err := strconv.ParseUint(fields[3], 10, 64); err == nil {
+					trace.AddCount(fields[len(fields)-1], calls)
+				}
+			}
+			continue
+		}
+
+		m := straceCall.FindStringSubmatch(line)
+		if m == nil || m[1] != "" {
+			// Resumed calls were counted when they started.
+			continue
+		}
+		name, rest := m[2], m[3]
+		if strings.HasSuffix(rest, "<unfinished ...>") {
+			trace.AddCount(name, 1)
+			continue
+		}
+		args, ok := splitStraceArgs(rest)
+		if !ok {
+			trace.AddCount(name, 1)
+			continue
+		}
+		trace.Add(name, args...)
+	}
+	return trace, s.Err()
+}
+
+// splitStraceArgs splits the arguments of a call up to the closing
+// parenthesis, ignoring commas in strings, arrays, and structures.
+func splitStraceArgs(s string) ([]string, bool) {
+	var (
+		args   []string
+		depth  int
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ')' && depth == 0:
+			if arg := strings.TrimSpace(s[start:i]); arg != "" || len(args) > 0 {
+				args = append(args, arg)
+			}
+			return args, true
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return nil, false
+}
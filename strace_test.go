@@ -0,0 +1,100 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+const testStrace = `execve("/bin/cat", ["cat", "a, b"], 0x7ffd6b0c8f08 /* 20 vars */) = 0
+brk(NULL)                               = 0x55d0c0a5e000
+[pid  1235] 12:00:01.000001 openat(0xffffff9c, "/etc/ld.so.cache", 0x80000) = 3
+[pid  1235] 12:00:01.000002 openat(0xffffff9c, "/etc/passwd", 0) = 3
+1236  0.000123 read(3, "root:x:0:0:root:/root:/bin/bash\n"..., 131072) = 1234
+[pid  1236] wait4(-1,  <unfinished ...>
+[pid  1237] <... wait4 resumed>[{WIFEXITED(s) && WEXITSTATUS(s) == 0}], 0, NULL) = 1237
+--- SIGCHLD {si_signo=SIGCHLD, si_code=CLD_EXITED, si_pid=1237} ---
+close(3)                                = 0
+exit_group(0)                           = ?
++++ exited with 0 +++
+% time     seconds  usecs/call     calls    errors syscall
+------ ----------- ----------- --------- --------- ----------------
+ 50.00    0.000010          10         4           mmap
+ 50.00    0.000010           5         2         1 access
+------ ----------- ----------- --------- --------- ----------------
+100.00    0.000020           3         6         1 total
+`
+
+func TestReadStrace(t *testing.T) {
+	trace, err := ReadStrace(strings.NewReader(testStrace))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := SyscallProfile{
+		"execve": 1, "brk": 1, "openat": 2, "read": 1, "wait4": 1,
+		"close": 1, "exit_group": 1, "mmap": 4, "access": 2,
+	}
+	if !reflect.DeepEqual(trace.Profile, want) {
+		t.Fatalf("expected %v, got %v", want, trace.Profile)
+	}
+
+	policy, err := trace.Policy(DraftOptions{Arch: "x86_64", ArgumentValues: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := func(name string, args ...uint64) SeccompData {
+		d := SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+		copy(d.Args[:], args)
+		return d
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{data("brk"), ActionAllow},
+		{data("mmap", 1, 2, 3), ActionAllow},
+		{data("openat", 0xffffff9c, 0, 0x80000), ActionAllow},
+		{data("openat", 0xffffff9c, 0, 0), ActionAllow},
+		{data("openat", 0xffffff9c, 0, 0x40), ActionErrno | Action(errnoEPERM)},
+		{data("read", 3, 0, 131072), ActionAllow},
+		{data("read", 4, 0, 131072), ActionErrno | Action(errnoEPERM)},
+		{data("close", 3), ActionAllow},
+		{data("write"), ActionErrno | Action(errnoEPERM)},
+	})
+}
+
+func TestSyscallTraceMerge(t *testing.T) {
+	a, b := NewSyscallTrace(), NewSyscallTrace()
+	a.Add("read", "3")
+	b.Add("read", "4")
+	b.AddCount("write", 2)
+	a.Merge(b)
+
+	if want := (SyscallProfile{"read": 2, "write": 2}); !reflect.DeepEqual(a.Profile, want) {
+		t.Fatalf("expected %v, got %v", want, a.Profile)
+	}
+	conditions := a.conditions("read", 2)
+	if len(conditions) != 2 || conditions[0][0].Value != 3 || conditions[1][0].Value != 4 {
+		t.Errorf("unexpected conditions %v", conditions)
+	}
+	if a.conditions("read", 1) != nil || a.conditions("write", 2) != nil {
+		t.Error("expected no conditions")
+	}
+}
@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// Paths of the seccomp sysctl interfaces.
+// https://www.kernel.org/doc/html/v6.15/userspace-api/seccomp_filter.html#sysctls
+const (
+	actionsAvailPath  = "/proc/sys/kernel/seccomp/actions_avail"
+	actionsLoggedPath = "/proc/sys/kernel/seccomp/actions_logged"
+)
+
+// ActionsAvailable returns the actions supported by the kernel as reported by
+// /proc/sys/kernel/seccomp/actions_avail (Linux 4.14+).
+func ActionsAvailable() ([]Action, error) {
+	return readActionNames(actionsAvailPath)
+}
+
+// ActionsLogged returns the actions that the kernel is allowed to log as
+// reported by /proc/sys/kernel/seccomp/actions_logged (Linux 4.14+).
+func ActionsLogged() ([]Action, error) {
+	return readActionNames(actionsLoggedPath)
+}
+
+// SetActionsLogged writes the list of actions that the kernel is allowed to
+// log to /proc/sys/kernel/seccomp/actions_logged. This requires root
+// privileges. ActionAllow cannot be logged.
+func SetActionsLogged(actions []Action) error {
+	names := make([]string, 0, len(actions))
+	for _, action := range actions {
+		action &= actionMask
+		if action == ActionAllow {
+			return errors.New("the allow action cannot be logged")
+		}
+		name, found := actionNames[action]
+		if !found {
+			return errors.New("invalid action: " + action.String())
+		}
+		names = append(names, name)
+	}
+
+	return os.WriteFile(actionsLoggedPath, []byte(strings.Join(names, " ")), 0o644)
+}
+
+// readActionNames reads a space separated list of action names. Unknown
+// action names are ignored.
+func readActionNames(path string) ([]Action, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	for _, name := range strings.Fields(string(data)) {
+		var action Action
+		if err := action.Unpack(name); err != nil {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
@@ -0,0 +1,505 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// SystemdSyscallSets are the syscall sets of systemd's SystemCallFilter=
+// (see systemd-analyze syscall-filter). Sets can contain other sets.
+var SystemdSyscallSets = map[string][]string{
+	"@default": {
+		"arch_prctl", "brk", "cacheflush", "clock_getres", "clock_getres_time64",
+		"clock_gettime", "clock_gettime64", "clock_nanosleep", "clock_nanosleep_time64",
+		"execve", "exit", "exit_group", "futex", "futex_time64", "futex_waitv",
+		"get_robust_list", "get_thread_area", "getegid", "getegid32", "geteuid",
+		"geteuid32", "getgid", "getgid32", "getgroups", "getgroups32", "getpgid",
+		"getpgrp", "getpid", "getppid", "getrandom", "getresgid", "getresgid32",
+		"getresuid", "getresuid32", "getrlimit", "getsid", "gettid", "gettimeofday",
+		"getuid", "getuid32", "membarrier", "mmap", "mmap2", "mprotect", "munmap",
+		"nanosleep", "pause", "prlimit64", "restart_syscall", "riscv_flush_icache",
+		"rseq", "rt_sigreturn", "sched_getaffinity", "sched_yield", "set_robust_list",
+		"set_thread_area", "set_tid_address", "set_tls", "sigreturn", "time",
+		"ugetrlimit",
+	},
+	"@aio": {
+		"io_cancel", "io_destroy", "io_getevents", "io_pgetevents",
+		"io_pgetevents_time64", "io_setup", "io_submit", "io_uring_enter",
+		"io_uring_register", "io_uring_setup",
+	},
+	"@basic-io": {
+		"_llseek", "close", "close_range", "dup", "dup2", "dup3", "lseek", "pread64",
+		"preadv", "preadv2", "pwrite64", "pwritev", "pwritev2", "read", "readv",
+		"write", "writev",
+	},
+	"@chown": {
+		"chown", "chown32", "fchown", "fchown32", "fchownat", "lchown", "lchown32",
+	},
+	"@clock": {
+		"adjtimex", "clock_adjtime", "clock_adjtime64", "clock_settime",
+		"clock_settime64", "settimeofday",
+	},
+	"@cpu-emulation": {
+		"modify_ldt", "subpage_prot", "switch_endian", "vm86", "vm86old",
+	},
+	"@debug": {
+		"lookup_dcookie", "perf_event_open", "pidfd_getfd", "ptrace", "rtas",
+		"s390_runtime_instr", "sys_debug_setcontext",
+	},
+	"@file-system": {
+		"access", "chdir", "chmod", "close", "creat", "faccessat", "faccessat2",
+		"fallocate", "fchdir", "fchmod", "fchmodat", "fchmodat2", "fcntl", "fcntl64",
+		"fgetxattr", "flistxattr", "fremovexattr", "fsetxattr", "fstat", "fstat64",
+		"fstatat64", "fstatfs", "fstatfs64", "ftruncate", "ftruncate64", "futimesat",
+		"getcwd", "getdents", "getdents64", "getxattr", "inotify_add_watch",
+		"inotify_init", "inotify_init1", "inotify_rm_watch", "lgetxattr", "link",
+		"linkat", "listxattr", "llistxattr", "lremovexattr", "lsetxattr", "lstat",
+		"lstat64", "mkdir", "mkdirat", "mknod", "mknodat", "newfstatat", "oldfstat",
+		"oldlstat", "oldstat", "open", "openat", "openat2", "readlink", "readlinkat",
+		"removexattr", "rename", "renameat", "renameat2", "rmdir", "setxattr", "stat",
+		"stat64", "statfs", "statfs64", "statx", "symlink", "symlinkat", "truncate",
+		"truncate64", "unlink", "unlinkat", "utime", "utimensat", "utimensat_time64",
+		"utimes",
+	},
+	"@io-event": {
+		"_newselect", "epoll_create", "epoll_create1", "epoll_ctl", "epoll_ctl_old",
+		"epoll_pwait", "epoll_pwait2", "epoll_wait", "epoll_wait_old", "eventfd",
+		"eventfd2", "poll", "ppoll", "ppoll_time64", "pselect6", "pselect6_time64",
+		"select",
+	},
+	"@ipc": {
+		"ipc", "memfd_create", "mq_getsetattr", "mq_notify", "mq_open",
+		"mq_timedreceive", "mq_timedreceive_time64", "mq_timedsend",
+		"mq_timedsend_time64", "mq_unlink", "msgctl", "msgget", "msgrcv", "msgsnd",
+		"pipe", "pipe2", "process_madvise", "process_vm_readv", "process_vm_writev",
+		"semctl", "semget", "semop", "semtimedop", "semtimedop_time64", "shmat",
+		"shmctl", "shmdt", "shmget",
+	},
+	"@keyring": {
+		"add_key", "keyctl", "request_key",
+	},
+	"@memlock": {
+		"mlock", "mlock2", "mlockall", "munlock", "munlockall",
+	},
+	"@module": {
+		"delete_module", "finit_module", "init_module",
+	},
+	"@mount": {
+		"chroot", "fsconfig", "fsmount", "fsopen", "fspick", "mount", "mount_setattr",
+		"move_mount", "open_tree", "pivot_root", "umount", "umount2",
+	},
+	"@network-io": {
+		"accept", "accept4", "bind", "connect", "getpeername", "getsockname",
+		"getsockopt", "listen", "recv", "recvfrom", "recvmmsg", "recvmmsg_time64",
+		"recvmsg", "send", "sendmmsg", "sendmsg", "sendto", "setsockopt", "shutdown",
+		"socket", "socketcall", "socketpair",
+	},
+	"@obsolete": {
+		"_sysctl", "afs_syscall", "bdflush", "break", "create_module", "ftime",
+		"get_kernel_syms", "getpmsg", "gtty", "idle", "lock", "mpx", "prof", "profil",
+		"putpmsg", "query_module", "security", "sgetmask", "ssetmask", "stime",
+		"stty", "sysfs", "tuxcall", "ulimit", "uselib", "ustat", "vserver",
+	},
+	"@pkey": {
+		"pkey_alloc", "pkey_free", "pkey_mprotect",
+	},
+	"@privileged": {
+		"@chown", "@clock", "@module", "@raw-io", "@reboot", "@swap", "_sysctl",
+		"acct", "bpf", "capset", "chroot", "fanotify_init", "fanotify_mark",
+		"nfsservctl", "open_by_handle_at", "pivot_root", "quotactl", "quotactl_fd",
+		"setdomainname", "setfsuid", "setfsuid32", "setgroups", "setgroups32",
+		"sethostname", "setresuid", "setresuid32", "setreuid", "setreuid32",
+		"setuid", "setuid32", "vhangup",
+	},
+	"@process": {
+		"capget", "clone", "clone3", "execveat", "fork", "getrusage", "kill",
+		"pidfd_open", "pidfd_send_signal", "prctl", "rt_sigqueueinfo",
+		"rt_tgsigqueueinfo", "setns", "swapcontext", "tgkill", "times", "tkill",
+		"unshare", "vfork", "wait4", "waitid", "waitpid",
+	},
+	"@raw-io": {
+		"ioperm", "iopl", "pciconfig_iobase", "pciconfig_read", "pciconfig_write",
+		"s390_pci_mmio_read", "s390_pci_mmio_write",
+	},
+	"@reboot": {
+		"kexec_file_load", "kexec_load", "reboot",
+	},
+	"@resources": {
+		"ioprio_set", "mbind", "migrate_pages", "move_pages", "nice",
+		"sched_setaffinity", "sched_setattr", "sched_setparam", "sched_setscheduler",
+		"set_mempolicy", "set_mempolicy_home_node", "setpriority", "setrlimit",
+	},
+	"@sandbox": {
+		"landlock_add_rule", "landlock_create_ruleset", "landlock_restrict_self",
+		"seccomp",
+	},
+	"@setuid": {
+		"setgid", "setgid32", "setgroups", "setgroups32", "setregid", "setregid32",
+		"setresgid", "setresgid32", "setresuid", "setresuid32", "setreuid",
+		"setreuid32", "setuid", "setuid32",
+	},
+	"@signal": {
+		"rt_sigaction", "rt_sigpending", "rt_sigprocmask", "rt_sigsuspend",
+		"rt_sigtimedwait", "rt_sigtimedwait_time64", "sigaction", "sigaltstack",
+		"signal", "signalfd", "signalfd4", "sigpending", "sigprocmask", "sigsuspend",
+	},
+	"@swap": {
+		"swapoff", "swapon",
+	},
+	"@sync": {
+		"fdatasync", "fsync", "msync", "sync", "sync_file_range", "sync_file_range2",
+		"syncfs",
+	},
+	"@system-service": {
+		"@aio", "@basic-io", "@chown", "@default", "@file-system", "@io-event",
+		"@ipc", "@keyring", "@memlock", "@network-io", "@process", "@resources",
+		"@setuid", "@signal", "@sync", "@timer", "arm_fadvise64_64", "capget",
+		"capset", "copy_file_range", "fadvise64", "fadvise64_64", "flock",
+		"get_mempolicy", "getcpu", "getpriority", "ioctl", "ioprio_get", "kcmp",
+		"madvise", "mremap", "name_to_handle_at", "oldolduname", "olduname",
+		"personality", "readahead", "readdir", "remap_file_pages",
+		"sched_get_priority_max", "sched_get_priority_min", "sched_getattr",
+		"sched_getparam", "sched_getscheduler", "sched_rr_get_interval",
+		"sched_rr_get_interval_time64", "sched_yield", "sendfile", "sendfile64",
+		"setfsgid", "setfsgid32", "setfsuid", "setfsuid32", "setpgid", "setsid",
+		"splice", "sysinfo", "tee", "umask", "uname", "userfaultfd", "vmsplice",
+	},
+	"@timer": {
+		"alarm", "getitimer", "setitimer", "timer_create", "timer_delete",
+		"timer_getoverrun", "timer_gettime", "timer_gettime64", "timer_settime",
+		"timer_settime64", "timerfd_create", "timerfd_gettime", "timerfd_gettime64",
+		"timerfd_settime", "timerfd_settime64", "times",
+	},
+}
+
+// errnoNames maps errno names to their values in asm-generic, which is used
+// by all architectures supported by this package except MIPS.
+var errnoNames = map[string]uint16{
+	"EPERM": 1, "ENOENT": 2, "ESRCH": 3, "EINTR": 4, "EIO": 5, "ENXIO": 6,
+	"E2BIG": 7, "ENOEXEC": 8, "EBADF": 9, "ECHILD": 10, "EAGAIN": 11,
+	"ENOMEM": 12, "EACCES": 13, "EFAULT": 14, "EBUSY": 16, "EEXIST": 17,
+	"EXDEV": 18, "ENODEV": 19, "ENOTDIR": 20, "EISDIR": 21, "EINVAL": 22,
+	"ENFILE": 23, "EMFILE": 24, "ENOTTY": 25, "ETXTBSY": 26, "EFBIG": 27,
+	"ENOSPC": 28, "ESPIPE": 29, "EROFS": 30, "EMLINK": 31, "EPIPE": 32,
+	"ERANGE": 34, "ENAMETOOLONG": 36, "ENOSYS": 38, "ENOTSUP": 95,
+	"EOPNOTSUPP": 95, "EAFNOSUPPORT": 97, "EADDRINUSE": 98,
+	"ECONNREFUSED": 111,
+}
+
+// ExpandSystemdSyscalls expands systemd syscall sets into the sorted list of
+// syscall names that they contain. Names that are not sets are returned
+// unchanged.
+func ExpandSystemdSyscalls(names ...string) ([]string, error) {
+	set := map[string]bool{}
+	if err := expandSystemdSyscalls(names, set, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	syscalls := make([]string, 0, len(set))
+	for name := range set {
+		syscalls = append(syscalls, name)
+	}
+	sort.Strings(syscalls)
+	return syscalls, nil
+}
+
+func expandSystemdSyscalls(names []string, out, visited map[string]bool) error {
+	for _, name := range names {
+		if !strings.HasPrefix(name, "@") {
+			out[name] = true
+			continue
+		}
+		if visited[name] {
+			continue
+		}
+		members, found := SystemdSyscallSets[name]
+		if !found {
+			return fmt.Errorf("unknown systemd syscall set %q", name)
+		}
+		visited[name] = true
+		if err := expandSystemdSyscalls(members, out, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSystemdFilter converts the values of a unit's SystemCallFilter=
+// directives, in the order they appear, and its SystemCallErrorNumber= into
+// a policy for the arch (the native one if empty). It follows systemd: the
+// first directive decides whether the filter is an allow list or, if it
+// starts with '~', a deny list; allow lists implicitly contain @default;
+// an empty value resets the filter; deny list entries may carry an errno
+// suffix (e.g. "@mount:EPERM"); and syscalls that do not exist on the arch
+// are ignored. Without SystemCallErrorNumber= denied syscalls kill the
+// process.
+func ParseSystemdFilter(archName string, filters []string, errorNumber string) (*Policy, error) {
+	info, err := arch.GetInfo(archName)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultAction := ActionKillProcess
+	if errorNumber != "" {
+		if defaultAction, err = parseSystemdErrorNumber(errorNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		configured bool
+		allowList  bool
+		syscalls   = map[string]Action{}
+	)
+	for _, filter := range filters {
+		filter = strings.TrimSpace(filter)
+		if filter == "" {
+			configured = false
+			syscalls = map[string]Action{}
+			continue
+		}
+
+		invert := strings.HasPrefix(filter, "~")
+		filter = strings.TrimPrefix(filter, "~")
+		if !configured {
+			configured = true
+			allowList = !invert
+			if allowList {
+				names, _ := ExpandSystemdSyscalls("@default")
+				for _, name := range names {
+					syscalls[name] = ActionAllow
+				}
+			}
+		}
+		// Entries are added to the list if the directive has the list's
+		// polarity and removed from it otherwise.
+		add := invert != allowList
+
+		for _, entry := range strings.Fields(filter) {
+			action := defaultAction
+			if name, errno, found := strings.Cut(entry, ":"); found {
+				if allowList || !add {
+					return nil, fmt.Errorf("errno suffix of %q is only supported in deny lists", entry)
+				}
+				if action, err = parseErrno(errno); err != nil {
+					return nil, err
+				}
+				entry = name
+			}
+			if allowList {
+				action = ActionAllow
+			}
+
+			names, err := ExpandSystemdSyscalls(entry)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				if add {
+					syscalls[name] = action
+				} else {
+					delete(syscalls, name)
+				}
+			}
+		}
+	}
+	if !configured {
+		return nil, errors.New("no SystemCallFilter= is configured")
+	}
+
+	policy := &Policy{arch: info, DefaultAction: ActionAllow}
+	if allowList {
+		policy.DefaultAction = defaultAction
+	}
+
+	byAction := map[Action][]string{}
+	var actions []Action
+	for name, action := range syscalls {
+		if _, found := info.SyscallNumber(name); !found {
+			continue
+		}
+		if byAction[action] == nil {
+			actions = append(actions, action)
+		}
+		byAction[action] = append(byAction[action], name)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	for _, action := range actions {
+		names := byAction[action]
+		sort.Strings(names)
+		policy.Syscalls = append(policy.Syscalls, SyscallGroup{Action: action, Names: names})
+	}
+	if len(policy.Syscalls) == 0 {
+		policy.Syscalls = []SyscallGroup{{Action: policy.DefaultAction}}
+	}
+	return policy, nil
+}
+
+// WriteSystemdDropIn writes the policy as a systemd drop-in containing
+// SystemCallFilter= and SystemCallErrorNumber= for the [Service] section.
+// Only policies without argument conditions can be expressed. Policies that
+// allow by default become deny lists, all others allow lists, which systemd
+// extends by @default. The policy's actions must be ActionAllow, ActionErrno,
+// ActionLog, or ActionKillProcess, and a deny list can only use one of the
+// latter two besides errno values.
+func (p *Policy) WriteSystemdDropIn(w io.Writer) error {
+	if p.arch == nil {
+		info, err := arch.GetInfo("")
+		if err != nil {
+			return err
+		}
+		p.arch = info
+	}
+
+	// The first group that names a syscall decides its action.
+	var names []string
+	actions := map[string]Action{}
+	for _, group := range p.groups() {
+		if len(group.NamesWithCondtions) > 0 {
+			return errors.New("systemd filters do not support argument conditions")
+		}
+		for _, name := range group.Names {
+			if _, found := actions[name]; !found {
+				actions[name] = group.Action.returnValue()
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	defaultAction := p.DefaultAction.returnValue()
+	var (
+		entries     []string
+		errorAction = defaultAction
+	)
+	if defaultAction == ActionAllow {
+		// Deny list: if it uses several errno values they become suffixes
+		// so that SystemCallErrorNumber= is left for kill or log.
+		errorAction = ActionKillProcess
+		var errorActionSet bool
+		errnos := map[Action]bool{}
+		for _, name := range names {
+			if a := actions[name]; a&actionMask == ActionErrno {
+				errnos[a] = true
+			}
+		}
+		for _, name := range names {
+			action := actions[name]
+			switch {
+			case action == ActionAllow:
+				continue
+			case action&actionMask == ActionErrno && len(errnos) > 1:
+				entries = append(entries, name+":"+errnoName(action))
+				continue
+			case errorActionSet && errorAction != action:
+				return fmt.Errorf("systemd deny lists cannot use both %v and %v", errorAction&actionMask, action&actionMask)
+			}
+			errorAction, errorActionSet = action, true
+			entries = append(entries, name)
+		}
+		if len(entries) == 0 {
+			return errors.New("policy does not deny any syscalls")
+		}
+		entries[0] = "~" + entries[0]
+	} else {
+		for _, name := range names {
+			switch action := actions[name]; action {
+			case ActionAllow:
+				entries = append(entries, name)
+			case defaultAction:
+			default:
+				return fmt.Errorf("systemd allow lists cannot use %v for %v", action&actionMask, name)
+			}
+		}
+	}
+
+	errorNumber, err := systemdErrorNumber(errorAction)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "[Service]")
+	fmt.Fprintf(w, "SystemCallFilter=%s\n", strings.Join(entries, " "))
+	if errorNumber != "" {
+		fmt.Fprintf(w, "SystemCallErrorNumber=%s\n", errorNumber)
+	}
+	return nil
+}
+
+// parseSystemdErrorNumber parses a SystemCallErrorNumber= value.
+func parseSystemdErrorNumber(s string) (Action, error) {
+	switch s {
+	case "kill":
+		return ActionKillProcess, nil
+	case "log":
+		return ActionLog, nil
+	default:
+		return parseErrno(s)
+	}
+}
+
+// parseErrno parses an errno name or number into an errno action.
+func parseErrno(s string) (Action, error) {
+	if errno, found := errnoNames[s]; found {
+		return ActionErrno | Action(errno), nil
+	}
+	errno, err := strconv.ParseUint(s, 10, 16)
+	if err != nil || errno == 0 {
+		return 0, fmt.Errorf("invalid errno %q", s)
+	}
+	return ActionErrno | Action(errno), nil
+}
+
+// systemdErrorNumber returns the SystemCallErrorNumber= value of an action.
+// It is empty for ActionKillProcess, systemd's default.
+func systemdErrorNumber(a Action) (string, error) {
+	switch a & actionMask {
+	case ActionKillProcess:
+		return "", nil
+	case ActionLog:
+		return "log", nil
+	case ActionErrno:
+		return errnoName(a), nil
+	default:
+		return "", fmt.Errorf("systemd filters do not support action %v", a&actionMask)
+	}
+}
+
+// errnoName returns the name of the errno of an errno action.
+func errnoName(a Action) string {
+	errno := uint16(a &^ actionMask)
+	var name string
+	for n, v := range errnoNames {
+		// Aliases resolve to the first name in alphabetical order.
+		if v == errno && (name == "" || n < name) {
+			name = n
+		}
+	}
+	if name == "" {
+		return strconv.Itoa(int(errno))
+	}
+	return name
+}
@@ -0,0 +1,202 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestExpandSystemdSyscalls(t *testing.T) {
+	names, err := ExpandSystemdSyscalls("@privileged", "read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, name := range names {
+		if strings.HasPrefix(name, "@") {
+			t.Errorf("set %v was not expanded", name)
+		}
+		found[name] = true
+	}
+	for _, name := range []string{"read", "reboot", "chown", "settimeofday", "acct"} {
+		if !found[name] {
+			t.Errorf("expected %v in expanded syscalls", name)
+		}
+	}
+
+	if _, err = ExpandSystemdSyscalls("@no-such-set"); err == nil {
+		t.Error("expected error for unknown set")
+	}
+}
+
+func systemdData(name string) SeccompData {
+	return SeccompData{NR: int32(syscallNumber(arch.X86_64, name)), Arch: uint32(arch.X86_64.ID)}
+}
+
+func TestParseSystemdFilterAllowList(t *testing.T) {
+	policy, err := ParseSystemdFilter("x86_64", []string{"@system-service", "~@privileged @resources"}, "EPERM")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eperm := ActionErrno | Action(errnoEPERM)
+	simulateSyscalls(t, policy, []SeccompTest{
+		{systemdData("read"), ActionAllow},
+		{systemdData("getpid"), ActionAllow},
+		{systemdData("socket"), ActionAllow},
+		{systemdData("setuid"), eperm},
+		{systemdData("setpriority"), eperm},
+		{systemdData("mount"), eperm},
+	})
+}
+
+func TestParseSystemdFilterDenyList(t *testing.T) {
+	policy, err := ParseSystemdFilter("x86_64", []string{"~@mount", "~@reboot:EACCES", "umount2"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	simulateSyscalls(t, policy, []SeccompTest{
+		{systemdData("read"), ActionAllow},
+		{systemdData("umount2"), ActionAllow},
+		{systemdData("mount"), ActionKillProcess},
+		{systemdData("reboot"), ActionErrno | Action(13)},
+	})
+}
+
+func TestParseSystemdFilterReset(t *testing.T) {
+	policy, err := ParseSystemdFilter("x86_64", []string{"~@mount", "", "read"}, "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulateSyscalls(t, policy, []SeccompTest{
+		{systemdData("read"), ActionAllow},
+		{systemdData("mount"), ActionLog},
+		{systemdData("write"), ActionLog},
+	})
+}
+
+func TestParseSystemdFilterErrors(t *testing.T) {
+	tests := []struct {
+		filters     []string
+		errorNumber string
+	}{
+		{nil, ""},
+		{[]string{"@no-such-set"}, ""},
+		{[]string{"read:EPERM"}, ""},
+		{[]string{"~mount:EWHAT"}, ""},
+		{[]string{"read"}, "0"},
+	}
+	for _, tc := range tests {
+		if _, err := ParseSystemdFilter("x86_64", tc.filters, tc.errorNumber); err == nil {
+			t.Errorf("expected error for %q %q", tc.filters, tc.errorNumber)
+		}
+	}
+}
+
+func TestWriteSystemdDropIn(t *testing.T) {
+	tests := []struct {
+		policy Policy
+		want   string
+	}{
+		{
+			Policy{
+				DefaultAction: ActionAllow,
+				Syscalls: []SyscallGroup{
+					{Action: ActionAllow, Names: []string{"umount2"}},
+					{Action: ActionErrno, Names: []string{"umount2", "mount", "reboot"}},
+				},
+			},
+			"[Service]\nSystemCallFilter=~mount reboot\nSystemCallErrorNumber=EPERM\n",
+		},
+		{
+			Policy{
+				DefaultAction: ActionAllow,
+				Syscalls: []SyscallGroup{
+					{Action: ActionKillProcess, Names: []string{"ptrace"}},
+					{Action: ActionErrno | Action(13), Names: []string{"mount"}},
+					{Action: ActionErrno, Names: []string{"reboot"}},
+				},
+			},
+			"[Service]\nSystemCallFilter=~mount:EACCES ptrace reboot:EPERM\n",
+		},
+		{
+			Policy{
+				DefaultAction: ActionErrno | Action(errnoENOSYS),
+				Syscalls: []SyscallGroup{
+					{Action: ActionAllow, Names: []string{"write", "read"}},
+				},
+			},
+			"[Service]\nSystemCallFilter=read write\nSystemCallErrorNumber=ENOSYS\n",
+		},
+	}
+	for _, tc := range tests {
+		policy := tc.policy
+		policy.arch = arch.X86_64
+
+		var buf bytes.Buffer
+		if err := policy.WriteSystemdDropIn(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("expected %q, got %q", tc.want, buf.String())
+		}
+	}
+}
+
+func TestWriteSystemdDropInErrors(t *testing.T) {
+	policies := []Policy{
+		{
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{
+				{Action: ActionKillProcess, Names: []string{"ptrace"}},
+				{Action: ActionLog, Names: []string{"mount"}},
+			},
+		},
+		{
+			DefaultAction: ActionErrno,
+			Syscalls: []SyscallGroup{
+				{Action: ActionTrap, Names: []string{"mount"}},
+			},
+		},
+		{
+			DefaultAction: ActionErrno,
+			Syscalls: []SyscallGroup{{
+				Action: ActionAllow,
+				NamesWithCondtions: []NameWithConditions{{
+					Name:       "personality",
+					Conditions: []Condition{{Argument: 0, Operation: Equal, Value: 0}},
+				}},
+			}},
+		},
+		{
+			DefaultAction: ActionTrap,
+			Syscalls:      []SyscallGroup{{Action: ActionAllow, Names: []string{"read"}}},
+		},
+	}
+	for i, policy := range policies {
+		policy.arch = arch.X86_64
+		if err := policy.WriteSystemdDropIn(&bytes.Buffer{}); err == nil {
+			t.Errorf("policy %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// maxTraceTuples limits the number of distinct argument tuples recorded per
+// syscall. Syscalls with more tuples are allowed without conditions.
+const maxTraceTuples = 1024
+
+// SyscallTrace collects the syscalls, and their numeric argument values,
+// observed while tracing a program (e.g. with ReadStrace). It can be turned
+// into a draft allow list with Policy.
+type SyscallTrace struct {
+	// Profile counts the calls of each syscall.
+	Profile SyscallProfile
+
+	tuples map[string]map[traceTuple]bool // nil if the syscall has too many tuples.
+}
+
+// traceValue is an argument value. Arguments that were not numeric or not
+// observed are unknown.
+type traceValue struct {
+	known bool
+	value uint64
+}
+
+type traceTuple [6]traceValue
+
+// NewSyscallTrace returns an empty trace.
+func NewSyscallTrace() *SyscallTrace {
+	return &SyscallTrace{
+		Profile: SyscallProfile{},
+		tuples:  map[string]map[traceTuple]bool{},
+	}
+}
+
+// Add records a call of the syscall with the arguments as printed by the
+// tracer. Arguments that are not unsigned numbers (decimal, hex, or octal)
+// are treated as unknown.
+func (t *SyscallTrace) Add(name string, args ...string) {
+	var tuple traceTuple
+	for i, arg := range args {
+		if i >= len(tuple) {
+			break
+		}
+		if v, err := strconv.ParseUint(arg, 0, 64); err == nil {
+			tuple[i] = traceValue{known: true, value: v}
+		}
+	}
+	t.add(name, 1, tuple)
+}
+
+// AddCount records n calls of the syscall with unknown arguments.
+func (t *SyscallTrace) AddCount(name string, n uint64) {
+	t.add(name, n, traceTuple{})
+}
+
+func (t *SyscallTrace) add(name string, n uint64, tuple traceTuple) {
+	tuples, found := t.tuples[name]
+	if !found {
+		tuples = map[traceTuple]bool{}
+		t.tuples[name] = tuples
+	}
+	if tuples != nil {
+		tuples[tuple] = true
+		if len(tuples) > maxTraceTuples {
+			t.tuples[name] = nil
+		}
+	}
+	t.Profile[name] += n
+}
+
+// Merge adds the calls of another trace.
+func (t *SyscallTrace) Merge(other *SyscallTrace) {
+	for name, n := range other.Profile {
+		tuples := other.tuples[name]
+		if tuples == nil {
+			t.add(name, n, traceTuple{})
+			continue
+		}
+		for tuple := range tuples {
+			t.add(name, 0, tuple)
+		}
+		t.Profile[name] += n
+	}
+}
+
+// Names returns the sorted names of the traced syscalls.
+func (t *SyscallTrace) Names() []string {
+	names := make([]string, 0, len(t.Profile))
+	for name := range t.Profile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DraftOptions configure SyscallTrace.Policy.
+type DraftOptions struct {
+	// Arch is the architecture of the policy. It defaults to the native
+	// architecture.
+	Arch string
+
+	// ArgumentValues is the maximum number of distinct values an argument
+	// may have been observed with to be restricted to them. Zero disables
+	// argument conditions.
+	ArgumentValues int
+}
+
+// Policy returns a draft policy that allows the traced syscalls and returns
+// EPERM for all others. Syscalls that do not exist on the arch are ignored.
+// If ArgumentValues is set, the arguments that only took a few numeric
+// values are restricted to the observed combinations of them. The draft is
+// a starting point that should be reviewed, since a trace rarely covers all
+// code paths of a program.
+func (t *SyscallTrace) Policy(opts DraftOptions) (*Policy, error) {
+	info, err := arch.GetInfo(opts.Arch)
+	if err != nil {
+		return nil, err
+	}
+
+	group := SyscallGroup{Action: ActionAllow}
+	for _, name := range t.Names() {
+		if _, found := info.SyscallNumber(name); !found {
+			continue
+		}
+		conditions := t.conditions(name, opts.ArgumentValues)
+		if conditions == nil {
+			group.Names = append(group.Names, name)
+			continue
+		}
+		for _, c := range conditions {
+			group.NamesWithCondtions = append(group.NamesWithCondtions, NameWithConditions{Name: name, Conditions: c})
+		}
+	}
+
+	return &Policy{
+		arch:          info,
+		DefaultAction: ActionErrno,
+		Syscalls:      []SyscallGroup{group},
+		Profile:       t.Profile,
+	}, nil
+}
+
+// conditions returns the observed combinations of the arguments that were
+// always known and took at most max values, or nil if there are none or
+// there are more than max combinations.
+func (t *SyscallTrace) conditions(name string, max int) []ArgumentConditions {
+	tuples := t.tuples[name]
+	if max <= 0 || tuples == nil {
+		return nil
+	}
+
+	var positions []int
+	for i := 0; i < len(traceTuple{}); i++ {
+		values := map[uint64]bool{}
+		for tuple := range tuples {
+			if !tuple[i].known {
+				values = nil
+				break
+			}
+			values[tuple[i].value] = true
+		}
+		if values != nil && len(values) <= max {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+
+	combinations := map[traceTuple]bool{}
+	for tuple := range tuples {
+		var c traceTuple
+		for _, i := range positions {
+			c[i] = tuple[i]
+		}
+		combinations[c] = true
+	}
+	if len(combinations) > max {
+		return nil
+	}
+
+	var out []ArgumentConditions
+	for tuple := range combinations {
+		var c ArgumentConditions
+		for _, i := range positions {
+			c = append(c, Condition{Argument: uint32(i), Operation: Equal, Value: tuple[i].value})
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		for k := range out[i] {
+			if out[i][k].Value != out[j][k].Value {
+				return out[i][k].Value < out[j][k].Value
+			}
+		}
+		return false
+	})
+	return out
+}
@@ -0,0 +1,55 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package trap
+
+import (
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// SeccompData returns the seccomp_data of the trapped syscall, as the filter
+// saw it. It returns false if the arch is not supported.
+func (s Signal) SeccompData() (seccomp.SeccompData, bool) {
+	if s.Arch == nil {
+		return seccomp.SeccompData{}, false
+	}
+	id := s.Arch.ID
+	if s.Arch == arch.X32 {
+		id = arch.X86_64.ID
+	}
+	return seccomp.SeccompData{
+		NR:                 int32(s.Nr | s.Arch.SeccompMask),
+		Arch:               uint32(id),
+		InstructionPointer: uint64(s.CallAddr),
+		Args:               s.Args,
+	}, true
+}
+
+// Record returns a Handler that records the seccomp_data of every trapped
+// syscall in the corpus and then calls h, if it is not nil. Errors of the
+// corpus are ignored.
+func Record(c *seccomp.CorpusWriter, h Handler) Handler {
+	return func(s Signal) {
+		if d, ok := s.SeccompData(); ok {
+			_ = c.Record(d, seccomp.ActionTrap|seccomp.Action(s.Data))
+		}
+		if h != nil {
+			h(s)
+		}
+	}
+}
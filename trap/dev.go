@@ -0,0 +1,150 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package trap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// Develop returns a copy of the policy for development in which every action
+// that rejects a syscall is replaced by seccomp.ActionTrap, so that Panic or
+// Log reports where the program made it. Errno actions keep their errno as
+// the data of the trap, and kill actions fail the syscall with the errno of
+// the Options instead of killing the program.
+func Develop(p seccomp.Policy) seccomp.Policy {
+	p.DefaultAction = developAction(p.DefaultAction)
+	groups := make([]seccomp.SyscallGroup, len(p.Syscalls))
+	for i, group := range p.Syscalls {
+		group.Action = developAction(group.Action)
+		groups[i] = group
+	}
+	p.Syscalls = groups
+	return p
+}
+
+// errnoEPERM is the errno that the filter returns for errno actions without
+// data.
+const errnoEPERM = 1
+
+func developAction(a seccomp.Action) seccomp.Action {
+	switch a.Base() {
+	case seccomp.ActionErrno:
+		if a == seccomp.ActionErrno {
+			return seccomp.ActionTrap | errnoEPERM
+		}
+		return seccomp.ActionTrap | seccomp.Action(a.Data())
+	case seccomp.ActionKillThread, seccomp.ActionKillProcess:
+		return seccomp.ActionTrap
+	}
+	return a
+}
+
+// Violation is the value that Panic panics with.
+type Violation struct {
+	Signal
+}
+
+func (v *Violation) Error() string {
+	return "seccomp violation: " + v.Signal.String() + "\n\n" + v.Signal.Stack()
+}
+
+// Panic is a Handler that panics with a *Violation, which crashes the
+// program and prints the stack of the goroutine that made the syscall.
+func Panic(s Signal) {
+	panic(&Violation{Signal: s})
+}
+
+// Log returns a Handler that writes the syscall and the stack of the
+// goroutine that made it to w.
+func Log(w io.Writer) Handler {
+	return func(s Signal) {
+		fmt.Fprintf(w, "seccomp violation: %v\n%s\n", s, s.Stack())
+	}
+}
+
+// Limit returns a Handler that calls h with the signals that the limiter
+// allows, for example so that Log does not flood its writer when a loop hits
+// a trapped syscall.
+func Limit(h Handler, l *seccomp.LogLimiter) Handler {
+	return func(s Signal) {
+		if l.Allow(s.Syscall) {
+			h(s)
+		}
+	}
+}
+
+// String returns the name, number, and arch of the syscall.
+func (s Signal) String() string {
+	name, archName := s.Syscall, "unknown arch"
+	if name == "" {
+		name = "unknown syscall"
+	}
+	if s.Arch != nil {
+		archName = s.Arch.Name
+	}
+	return fmt.Sprintf("%s (%d) on %s", name, s.Nr, archName)
+}
+
+// Violation returns the signal as a violation that can be written to a
+// seccomp.ViolationSink. The thread that made the syscall is not known.
+func (s Signal) Violation() seccomp.Violation {
+	exe, _ := os.Executable()
+	var data *seccomp.SeccompData
+	if d, ok := s.SeccompData(); ok {
+		data = &d
+	}
+	return seccomp.Violation{
+		Time:    time.Now(),
+		Source:  seccomp.ViolationSourceSIGSYS,
+		Comm:    filepath.Base(os.Args[0]),
+		Exe:     exe,
+		Arch:    s.Arch,
+		Syscall: s.Syscall,
+		Action:  seccomp.ActionTrap | seccomp.Action(s.Data),
+		Data:    data,
+	}
+}
+
+// Frames returns the symbolized Callers.
+func (s Signal) Frames() *runtime.Frames {
+	return runtime.CallersFrames(s.Callers)
+}
+
+// Stack formats the Callers like a goroutine in a Go traceback. Addresses
+// that are not in Go code are left out.
+func (s Signal) Stack() string {
+	var b strings.Builder
+	frames := s.Frames()
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&b, "%s(...)\n\t%s:%d +%#x\n", frame.Function, frame.File, frame.Line, frame.PC-frame.Entry)
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
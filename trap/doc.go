@@ -0,0 +1,40 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package trap handles the SIGSYS signal that the kernel sends to a thread
+// whose syscall matched a filter rule with seccomp.ActionTrap.
+//
+// The Go runtime treats SIGSYS as fatal and os/signal cannot deliver it with
+// the siginfo that describes the syscall. Install replaces the runtime's
+// SIGSYS handler with a small handler written in assembly, so no cgo is
+// required. The handler makes the trapped syscall fail with an errno and
+// passes the si_syscall, si_arch, si_call_addr, and si_errno fields of the
+// siginfo to a goroutine that calls the Handler with a decoded Signal.
+//
+// The handler also records the arguments of the syscall and the return
+// addresses of the interrupted goroutine by following the frame pointers.
+// Record writes the seccomp_data of trapped syscalls to a seccomp corpus.
+// During development, load the policy
+// returned by Develop and install Panic or Log to get the stack of every
+// syscall that the policy would reject:
+//
+//	if _, err := trap.Install(trap.Log(os.Stderr), trap.Options{}); err != nil {
+//		return err
+//	}
+//	filter.Policy = trap.Develop(filter.Policy)
+//	return seccomp.LoadFilter(filter)
+package trap
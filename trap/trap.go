@@ -0,0 +1,106 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package trap
+
+import (
+	"encoding/binary"
+	"syscall"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// Signal is a syscall that a filter rejected with seccomp.ActionTrap.
+type Signal struct {
+	Arch     *arch.Info // Architecture of the syscall (si_arch), nil if unsupported.
+	Nr       int        // Number of the syscall without the mask of the architecture (si_syscall).
+	Syscall  string     // Name of the syscall, empty if unknown.
+	CallAddr uintptr    // Address of the instruction following the syscall (si_call_addr).
+	Data     uint16     // Data of the trap action (si_errno).
+
+	// Args are the arguments of the syscall, read from the registers of the
+	// interrupted thread.
+	Args [6]uint64
+
+	// Callers are the return addresses of the interrupted goroutine,
+	// starting with CallAddr, as found by following the frame pointers in
+	// the signal handler. Frames symbolizes them.
+	Callers []uintptr
+}
+
+// Handler is called for every trapped syscall. It is called from a single
+// goroutine after the trapped syscall has already returned.
+type Handler func(Signal)
+
+// Options configure Install.
+type Options struct {
+	// Errno is returned by trapped syscalls of rules without data. Rules
+	// with data, such as ActionTrap|Action(EPERM), return the data as
+	// errno. It defaults to ENOSYS.
+	Errno syscall.Errno
+}
+
+// auditArch returns the architecture of a syscall number as reported in
+// si_syscall, and the number without the architecture's mask.
+func auditArch(id uint32, nr int) (*arch.Info, int) {
+	for _, info := range []*arch.Info{arch.X86_64, arch.I386, arch.AARCH64, arch.ARM} {
+		if uint32(info.ID) != id {
+			continue
+		}
+		if info == arch.X86_64 && nr&arch.X32.SeccompMask != 0 {
+			return arch.X32, nr &^ arch.X32.SeccompMask
+		}
+		return info, nr
+	}
+	return nil, nr
+}
+
+// The signal handler writes records of recordSize bytes in the byte order of
+// the supported architectures: the si_call_addr, si_syscall, si_arch,
+// si_errno, and si_code fields of the siginfo, the number of callers,
+// maxCallers return addresses from offset 32, and the six syscall arguments
+// from argsOffset.
+const (
+	maxCallers = 32
+	argsOffset = 32 + 8*maxCallers
+	recordSize = argsOffset + 8*6
+)
+
+// decode decodes a record written by the signal handler.
+func decode(record []byte) Signal {
+	le := binary.LittleEndian
+	s := Signal{
+		CallAddr: uintptr(le.Uint64(record[0:])),
+		Data:     uint16(le.Uint32(record[16:])),
+	}
+	s.Arch, s.Nr = auditArch(le.Uint32(record[12:]), int(int32(le.Uint32(record[8:]))))
+	if s.Arch != nil {
+		s.Syscall = s.Arch.SyscallName(s.Nr)
+	}
+
+	for i := range s.Args {
+		s.Args[i] = le.Uint64(record[argsOffset+8*i:])
+	}
+
+	n := min(int(le.Uint32(record[24:])), maxCallers)
+	s.Callers = make([]uintptr, 0, n+1)
+	s.Callers = append(s.Callers, s.CallAddr)
+	for i := 0; i < n; i++ {
+		s.Callers = append(s.Callers, uintptr(le.Uint64(record[32+8*i:])))
+	}
+	return s
+}
@@ -0,0 +1,178 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package trap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Flags of struct sigaction that are not defined by x/sys/unix.
+const (
+	saSigInfo  = 0x4
+	saOnStack  = 0x8000000
+	saRestorer = 0x4000000
+)
+
+// sysSeccomp is the si_code of signals sent by seccomp.
+const sysSeccomp = 1
+
+// sigaction is the kernel's struct sigaction of rt_sigaction.
+type sigaction struct {
+	handler  uintptr
+	flags    uint64
+	restorer uintptr
+	mask     uint64
+}
+
+// Implemented in assembly.
+func sigsysHandler()
+func sigreturn()
+
+// addrs returns the address of the signal handler and of the code that
+// returns from it, or zero if the kernel provides it.
+func addrs() (handler, restorer uintptr)
+
+// Read by the signal handler.
+var (
+	recordFD     int32 = -1 // Write end of the pipe that the records are sent to.
+	defaultErrno int32      // Errno of trapped syscalls of rules without data.
+)
+
+var (
+	mu        sync.Mutex
+	installed *Trap
+	handler   Handler
+
+	readerOnce sync.Once
+	readerErr  error
+)
+
+// Trap is an installed SIGSYS handler.
+type Trap struct {
+	old sigaction
+}
+
+// Install installs the SIGSYS handler. Only one handler can be installed at
+// a time. Syscalls that trap before Install or after Close kill the process
+// as the Go runtime handles them.
+//
+// The signal handler writes to a pipe, which fails if the write syscall
+// traps too. os/signal must not be used for SIGSYS because it reinstalls the
+// runtime's handler.
+func Install(h Handler, opts Options) (*Trap, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if installed != nil {
+		return nil, errors.New("a SIGSYS handler is already installed")
+	}
+	if readerOnce.Do(startReader); readerErr != nil {
+		return nil, readerErr
+	}
+
+	errno := opts.Errno
+	if errno == 0 {
+		errno = syscall.ENOSYS
+	}
+	atomic.StoreInt32(&defaultErrno, int32(errno))
+
+	pc, restorer := addrs()
+	act := sigaction{handler: pc, flags: saSigInfo | saOnStack, mask: ^uint64(0)}
+	if restorer != 0 {
+		act.flags |= saRestorer
+		act.restorer = restorer
+	}
+	t := &Trap{}
+	if err := rtSigaction(&act, &t.old); err != nil {
+		return nil, err
+	}
+	installed, handler = t, h
+	return t, nil
+}
+
+// Close restores the SIGSYS handler of the Go runtime. Signals that were
+// received before are still passed to the Handler.
+func (t *Trap) Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if installed != t {
+		return nil
+	}
+	if err := rtSigaction(&t.old, nil); err != nil {
+		return err
+	}
+	installed = nil
+	return nil
+}
+
+func rtSigaction(act, old *sigaction) error {
+	_, _, errno := unix.RawSyscall6(unix.SYS_RT_SIGACTION, uintptr(unix.SIGSYS),
+		uintptr(unsafe.Pointer(act)), uintptr(unsafe.Pointer(old)), unsafe.Sizeof(act.mask), 0, 0)
+	if errno != 0 {
+		return os.NewSyscallError("rt_sigaction", errno)
+	}
+	return nil
+}
+
+// startReader creates the pipe and starts the goroutine that reads the
+// records from it. The pipe is never closed, so that a signal handler that
+// runs concurrently with Close cannot write to a reused descriptor. A full
+// pipe drops the records because the handler must not block.
+func startReader() {
+	var fds [2]int
+	if err := unix.Pipe2(fds[:], unix.O_CLOEXEC|unix.O_NONBLOCK); err != nil {
+		readerErr = os.NewSyscallError("pipe2", err)
+		return
+	}
+	atomic.StoreInt32(&recordFD, int32(fds[1]))
+	go read(os.NewFile(uintptr(fds[0]), "sigsys"))
+}
+
+func read(r io.Reader) {
+	record := make([]byte, recordSize)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			return
+		}
+		if binary.LittleEndian.Uint32(record[20:]) != sysSeccomp {
+			continue
+		}
+
+		mu.Lock()
+		h := handler
+		if installed == nil {
+			h = nil
+		}
+		mu.Unlock()
+		if h != nil {
+			h(decode(record))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+#include "textflag.h"
+
+// Layout of the record, see decode.
+#define RECORD_SIZE 336
+#define ARGS_OFFSET 288
+#define MAX_CALLERS 32
+
+// func sigsysHandler()
+// Called by the kernel with the C calling convention: DI is the signal
+// number, SI points to the siginfo, and DX to the ucontext.
+TEXT ·sigsysHandler(SB),NOSPLIT,$0
+	SUBQ	$RECORD_SIZE, SP
+	MOVQ	104(DX), AX	// uc_mcontext.gregs[REG_RDI]
+	MOVQ	AX, (ARGS_OFFSET+0)(SP)
+	MOVQ	112(DX), AX	// uc_mcontext.gregs[REG_RSI]
+	MOVQ	AX, (ARGS_OFFSET+8)(SP)
+	MOVQ	136(DX), AX	// uc_mcontext.gregs[REG_RDX]
+	MOVQ	AX, (ARGS_OFFSET+16)(SP)
+	MOVQ	56(DX), AX	// uc_mcontext.gregs[REG_R10]
+	MOVQ	AX, (ARGS_OFFSET+24)(SP)
+	MOVQ	40(DX), AX	// uc_mcontext.gregs[REG_R8]
+	MOVQ	AX, (ARGS_OFFSET+32)(SP)
+	MOVQ	48(DX), AX	// uc_mcontext.gregs[REG_R9]
+	MOVQ	AX, (ARGS_OFFSET+40)(SP)
+	MOVQ	16(SI), AX	// si_call_addr
+	MOVQ	AX, 0(SP)
+	MOVQ	24(SI), AX	// si_syscall and si_arch
+	MOVQ	AX, 8(SP)
+	MOVL	4(SI), AX	// si_errno
+	MOVL	AX, 16(SP)
+	MOVL	8(SI), CX	// si_code
+	MOVL	CX, 20(SP)
+	MOVL	$0, 24(SP)
+	CMPL	CX, $1	// SYS_SECCOMP
+	JNE	write
+
+	// The syscall returns the value of RAX in the interrupted context.
+	TESTL	AX, AX
+	JNZ	fail
+	MOVL	·defaultErrno(SB), AX
+fail:
+	MOVLQSX	AX, AX
+	NEGQ	AX
+	MOVQ	AX, 144(DX)	// uc_mcontext.gregs[REG_RAX]
+
+	// The syscall instructions of Go are in functions without a frame, so
+	// the stack pointer points to the return address into the caller. The
+	// callers of that function are found by following the frame pointers.
+	MOVQ	160(DX), R8	// uc_mcontext.gregs[REG_RSP]
+	MOVQ	120(DX), R9	// uc_mcontext.gregs[REG_RBP]
+	MOVQ	0(R8), AX
+	MOVQ	AX, 32(SP)
+	MOVQ	$1, CX
+walk:
+	CMPQ	CX, $MAX_CALLERS
+	JAE	walked
+	TESTQ	R9, R9
+	JZ	walked
+	TESTQ	$7, R9
+	JNZ	walked
+	CMPQ	R9, R8	// Frames are above the stack pointer.
+	JB	walked
+	MOVQ	8(R9), AX	// Return address.
+	MOVQ	AX, 32(SP)(CX*8)
+	INCQ	CX
+	MOVQ	0(R9), R10	// Frame pointer of the caller.
+	MOVQ	R10, AX
+	SUBQ	R9, AX
+	JBE	walked	// The caller's frame must be above.
+	CMPQ	AX, $0x100000
+	JAE	walked
+	MOVQ	R10, R9
+	JMP	walk
+walked:
+	MOVL	CX, 24(SP)
+
+write:
+	MOVLQSX	·recordFD(SB), DI
+	MOVQ	SP, SI
+	MOVQ	$RECORD_SIZE, DX
+	MOVQ	$1, AX	// SYS_write
+	SYSCALL
+	ADDQ	$RECORD_SIZE, SP
+	RET
+
+// func sigreturn()
+TEXT ·sigreturn(SB),NOSPLIT,$0
+	MOVQ	$15, AX	// SYS_rt_sigreturn
+	SYSCALL
+	INT	$3
+
+// func addrs() (handler, restorer uintptr)
+TEXT ·addrs(SB),NOSPLIT,$0-16
+	MOVQ	$·sigsysHandler(SB), AX
+	MOVQ	AX, handler+0(FP)
+	MOVQ	$·sigreturn(SB), AX
+	MOVQ	AX, restorer+8(FP)
+	RET
@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+#include "textflag.h"
+
+// Layout of the record, see decode.
+#define RECORD_SIZE 336
+#define ARGS_OFFSET 288
+#define MAX_CALLERS 32
+
+// func sigsysHandler()
+// Called by the kernel with the C calling convention: R0 is the signal
+// number, R1 points to the siginfo, and R2 to the ucontext.
+TEXT ·sigsysHandler(SB),NOSPLIT|NOFRAME,$0
+	SUB	$RECORD_SIZE, RSP
+	MOVD	184(R2), R3	// uc_mcontext.regs[0]
+	MOVD	R3, (ARGS_OFFSET+0)(RSP)
+	MOVD	192(R2), R3	// uc_mcontext.regs[1]
+	MOVD	R3, (ARGS_OFFSET+8)(RSP)
+	MOVD	200(R2), R3	// uc_mcontext.regs[2]
+	MOVD	R3, (ARGS_OFFSET+16)(RSP)
+	MOVD	208(R2), R3	// uc_mcontext.regs[3]
+	MOVD	R3, (ARGS_OFFSET+24)(RSP)
+	MOVD	216(R2), R3	// uc_mcontext.regs[4]
+	MOVD	R3, (ARGS_OFFSET+32)(RSP)
+	MOVD	224(R2), R3	// uc_mcontext.regs[5]
+	MOVD	R3, (ARGS_OFFSET+40)(RSP)
+	MOVD	16(R1), R3	// si_call_addr
+	MOVD	R3, 0(RSP)
+	MOVD	24(R1), R3	// si_syscall and si_arch
+	MOVD	R3, 8(RSP)
+	MOVW	4(R1), R3	// si_errno
+	MOVW	R3, 16(RSP)
+	MOVW	8(R1), R4	// si_code
+	MOVW	R4, 20(RSP)
+	MOVW	ZR, 24(RSP)
+	CMPW	$1, R4	// SYS_SECCOMP
+	BNE	write
+
+	// The syscall returns the value of X0 in the interrupted context.
+	CBNZW	R3, fail
+	MOVW	·defaultErrno(SB), R3
+fail:
+	NEG	R3, R3
+	MOVD	R3, 184(R2)	// uc_mcontext.regs[0]
+
+	// The syscall instructions of Go are in leaf functions that keep the
+	// return address into the caller in the link register. The callers of
+	// that function are found by following the frame pointers.
+	MOVD	424(R2), R5	// uc_mcontext.regs[30]
+	MOVD	R5, 32(RSP)
+	MOVD	432(R2), R6	// uc_mcontext.sp
+	MOVD	416(R2), R7	// uc_mcontext.regs[29]
+	ADD	$32, RSP, R10
+	MOVD	$1, R9
+walk:
+	CMP	$MAX_CALLERS, R9
+	BHS	walked
+	CBZ	R7, walked
+	TST	$7, R7
+	BNE	walked
+	CMP	R6, R7	// Frames are above the stack pointer.
+	BLO	walked
+	MOVD	8(R7), R5	// Return address.
+	MOVD	R5, (R10)(R9<<3)
+	ADD	$1, R9
+	MOVD	(R7), R8	// Frame pointer of the caller.
+	CMP	R7, R8	// The caller's frame must be above.
+	BLS	walked
+	SUB	R7, R8, R11
+	CMP	$0x100000, R11
+	BHS	walked
+	MOVD	R8, R7
+	B	walk
+walked:
+	MOVW	R9, 24(RSP)
+
+write:
+	MOVW	·recordFD(SB), R0
+	MOVD	RSP, R1
+	MOVD	$RECORD_SIZE, R2
+	MOVD	$64, R8	// SYS_write
+	SVC
+	ADD	$RECORD_SIZE, RSP
+	RET
+
+// func sigreturn()
+// Unused, the kernel returns through the vDSO.
+TEXT ·sigreturn(SB),NOSPLIT|NOFRAME,$0
+	MOVD	$139, R8	// SYS_rt_sigreturn
+	SVC
+
+// func addrs() (handler, restorer uintptr)
+TEXT ·addrs(SB),NOSPLIT,$0-16
+	MOVD	$·sigsysHandler(SB), R0
+	MOVD	R0, handler+0(FP)
+	MOVD	ZR, restorer+8(FP)
+	RET
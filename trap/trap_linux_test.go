@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package trap
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// installEnv makes TestInstall run in a child process, because the filter
+// cannot be removed.
+const installEnv = "TRAP_TEST_INSTALL"
+
+func TestInstall(t *testing.T) {
+	if os.Getenv(installEnv) == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestInstall$", "-test.v")
+		cmd.Env = append(os.Environ(), installEnv+"=1")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("trapped child failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	signals := make(chan Signal, 2)
+	trap, err := Install(func(s Signal) { signals <- s }, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trap.Close()
+	if _, err := Install(func(Signal) {}, Options{}); err == nil {
+		t.Error("expected an error for a second handler")
+	}
+
+	filter := seccomp.Filter{
+		NoNewPrivs: true,
+		Flag:       seccomp.FilterFlagTSync,
+		Policy: seccomp.Policy{
+			DefaultAction: seccomp.ActionAllow,
+			Syscalls: []seccomp.SyscallGroup{
+				{Action: seccomp.ActionTrap, Names: []string{"getppid"}},
+				{Action: seccomp.ActionTrap | seccomp.Action(unix.EPERM), Names: []string{"getpgid"}},
+			},
+		},
+	}
+	if err := seccomp.LoadFilter(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, errno := unix.RawSyscall6(unix.SYS_GETPPID, 1, 2, 3, 4, 5, 6); errno != unix.ENOSYS {
+		t.Errorf("expected getppid to fail with ENOSYS, got %v", errno)
+	}
+	if _, err := unix.Getpgid(0); err != unix.EPERM {
+		t.Errorf("expected getpgid to fail with EPERM, got %v", err)
+	}
+
+	for _, want := range []struct {
+		name string
+		data uint16
+		args [6]uint64
+	}{{"getppid", 0, [6]uint64{1, 2, 3, 4, 5, 6}}, {"getpgid", uint16(unix.EPERM), [6]uint64{}}} {
+		select {
+		case s := <-signals:
+			if s.Syscall != want.name || s.Data != want.data || s.Arch == nil || s.Arch.Name != nativeArch() || s.CallAddr == 0 {
+				t.Errorf("unexpected signal %+v for %v", s, want.name)
+			}
+			// Getpgid leaves the registers of the unused arguments as they are.
+			if s.Args[0] != want.args[0] || (want.name == "getppid" && s.Args != want.args) {
+				t.Errorf("unexpected args %#x for %v", s.Args, want.name)
+			}
+			if stack := s.Stack(); !strings.Contains(stack, "trap.TestInstall(...)") {
+				t.Errorf("expected TestInstall in the stack of %v, got\n%s", want.name, stack)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no signal for %v", want.name)
+		}
+	}
+}
+
+func nativeArch() string {
+	if runtime.GOARCH == "arm64" {
+		return "aarch64"
+	}
+	return "x86_64"
+}
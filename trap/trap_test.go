@@ -0,0 +1,187 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package trap
+
+import (
+	"encoding/binary"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	seccomp "github.com/elastic/go-seccomp-bpf"
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestDecode(t *testing.T) {
+	record := func(addr uint64, nr int32, id arch.AuditArch, errno uint32, pcs ...uint64) []byte {
+		b := make([]byte, recordSize)
+		binary.LittleEndian.PutUint64(b[0:], addr)
+		binary.LittleEndian.PutUint32(b[8:], uint32(nr))
+		binary.LittleEndian.PutUint32(b[12:], uint32(id))
+		binary.LittleEndian.PutUint32(b[16:], errno)
+		binary.LittleEndian.PutUint32(b[20:], 1)
+		binary.LittleEndian.PutUint32(b[24:], uint32(len(pcs)))
+		for i, pc := range pcs {
+			binary.LittleEndian.PutUint64(b[32+8*i:], pc)
+		}
+		for i := 0; i < 6; i++ {
+			binary.LittleEndian.PutUint64(b[argsOffset+8*i:], uint64(i+1)<<32|uint64(i))
+		}
+		return b
+	}
+
+	s := decode(record(0x401000, 110, arch.X86_64.ID, 13, 0x402000, 0x403000))
+	if s.Arch != arch.X86_64 || s.Nr != 110 || s.Syscall != "getppid" || s.CallAddr != 0x401000 || s.Data != 13 {
+		t.Errorf("unexpected signal %+v", s)
+	}
+	if s.Args != [6]uint64{0x100000000, 0x200000001, 0x300000002, 0x400000003, 0x500000004, 0x600000005} {
+		t.Errorf("unexpected args %#x", s.Args)
+	}
+	if len(s.Callers) != 3 || s.Callers[0] != 0x401000 || s.Callers[1] != 0x402000 || s.Callers[2] != 0x403000 {
+		t.Errorf("unexpected callers %#x", s.Callers)
+	}
+	if s.String() != "getppid (110) on x86_64" {
+		t.Errorf("unexpected string %q", s.String())
+	}
+	s = decode(record(0, 1|int32(arch.X32.SeccompMask), arch.X86_64.ID, 0))
+	if s.Arch != arch.X32 || s.Nr != 1 || s.Syscall != "write" {
+		t.Errorf("unexpected x32 signal %+v", s)
+	}
+	s = decode(record(0, 64, arch.AARCH64.ID, 0))
+	if s.Arch != arch.AARCH64 || s.Syscall != "write" {
+		t.Errorf("unexpected aarch64 signal %+v", s)
+	}
+	if s = decode(record(0, 1, 0x1234, 0)); s.Arch != nil || s.Syscall != "" || s.Nr != 1 {
+		t.Errorf("unexpected signal of an unknown arch %+v", s)
+	}
+	if s.String() != "unknown syscall (1) on unknown arch" {
+		t.Errorf("unexpected string %q", s.String())
+	}
+}
+
+func TestSignalViolation(t *testing.T) {
+	v := Signal{Arch: arch.X86_64, Nr: 110, Syscall: "getppid", Data: 13, Args: [6]uint64{7}}.Violation()
+	if v.Source != seccomp.ViolationSourceSIGSYS || v.Syscall != "getppid" || v.Arch != arch.X86_64 || v.Action != seccomp.ActionTrap|13 {
+		t.Errorf("unexpected violation %+v", v)
+	}
+	if v.Data == nil || v.Data.NR != 110 || v.Data.Args[0] != 7 {
+		t.Errorf("expected the seccomp_data of the violation, got %+v", v.Data)
+	}
+	if v.Time.IsZero() || v.Comm == "" {
+		t.Errorf("expected the time and process of the violation, got %+v", v)
+	}
+}
+
+func TestSignalSeccompData(t *testing.T) {
+	d, ok := Signal{Arch: arch.X32, Nr: 1, CallAddr: 0x401000, Args: [6]uint64{2}}.SeccompData()
+	want := seccomp.SeccompData{NR: 1 | int32(arch.X32.SeccompMask), Arch: uint32(arch.X86_64.ID), InstructionPointer: 0x401000, Args: [6]uint64{2}}
+	if !ok || d != want {
+		t.Errorf("expected %+v, got %+v", want, d)
+	}
+	if _, ok = (Signal{Nr: 1}).SeccompData(); ok {
+		t.Error("expected no seccomp_data for an unknown arch")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	var b strings.Builder
+	var handled int
+	h := Record(seccomp.NewCorpusWriter(&b), func(Signal) { handled++ })
+	h(Signal{Arch: arch.X86_64, Nr: 110, Syscall: "getppid", Data: 13, Args: [6]uint64{1}})
+	h(Signal{Nr: 110})
+
+	entries, err := seccomp.ReadCorpus(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []seccomp.CorpusEntry{{
+		Data:   seccomp.SeccompData{NR: 110, Arch: uint32(arch.X86_64.ID), Args: [6]uint64{1}},
+		Action: seccomp.ActionTrap | 13,
+	}}
+	if !reflect.DeepEqual(entries, want) || handled != 2 {
+		t.Errorf("expected %+v and 2 handled signals, got %+v and %d", want, entries, handled)
+	}
+	Record(nil, nil)(Signal{Arch: arch.X86_64, Nr: 110})
+}
+
+func TestLimit(t *testing.T) {
+	var handled []string
+	h := Limit(func(s Signal) { handled = append(handled, s.Syscall) }, seccomp.NewLogLimiter(seccomp.LogLimit{Sample: 2}))
+	for _, name := range []string{"getppid", "getppid", "getppid", "getpgid"} {
+		h(Signal{Syscall: name})
+	}
+	if want := []string{"getppid", "getppid", "getpgid"}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("expected %v, got %v", want, handled)
+	}
+}
+
+func TestDevelop(t *testing.T) {
+	policy := seccomp.Policy{
+		DefaultAction: seccomp.ActionKillProcess,
+		Syscalls: []seccomp.SyscallGroup{
+			{Action: seccomp.ActionAllow, Names: []string{"read"}},
+			{Action: seccomp.ActionErrno, Names: []string{"open"}},
+			{Action: seccomp.ActionErrno | 13, Names: []string{"mount"}},
+			{Action: seccomp.ActionKillThread, Names: []string{"ptrace"}},
+			{Action: seccomp.ActionLog, Names: []string{"uname"}},
+		},
+	}
+	dev := Develop(policy)
+
+	want := []seccomp.Action{
+		seccomp.ActionAllow,
+		seccomp.ActionTrap | 1,
+		seccomp.ActionTrap | 13,
+		seccomp.ActionTrap,
+		seccomp.ActionLog,
+	}
+	for i, group := range dev.Syscalls {
+		if group.Action != want[i] {
+			t.Errorf("expected %v for %v, got %v", want[i], group.Names, group.Action)
+		}
+	}
+	if dev.DefaultAction != seccomp.ActionTrap {
+		t.Errorf("expected trap as default action, got %v", dev.DefaultAction)
+	}
+	if policy.Syscalls[1].Action != seccomp.ActionErrno || policy.DefaultAction != seccomp.ActionKillProcess {
+		t.Error("the policy was modified")
+	}
+}
+
+func TestLog(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(0)
+	var b strings.Builder
+	Log(&b)(Signal{Arch: arch.X86_64, Nr: 110, Syscall: "getppid", Callers: []uintptr{0, pc + 1}})
+
+	out := b.String()
+	if !strings.HasPrefix(out, "seccomp violation: getppid (110) on x86_64\n") {
+		t.Errorf("unexpected log %q", out)
+	}
+	if !strings.Contains(out, "trap.TestLog(...)\n\t") || !strings.Contains(out, "trap_test.go:") {
+		t.Errorf("expected the stack of TestLog, got %q", out)
+	}
+
+	defer func() {
+		v, ok := recover().(*Violation)
+		if !ok || v.Syscall != "getppid" || !strings.Contains(v.Error(), "trap.TestLog") {
+			t.Errorf("unexpected panic %v", v)
+		}
+	}()
+	Panic(Signal{Arch: arch.X86_64, Nr: 110, Syscall: "getppid", Callers: []uintptr{pc + 1}})
+}
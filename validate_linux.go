@@ -0,0 +1,166 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/bpf"
+)
+
+var validateShim = registerShim("validate", runValidateShim)
+
+// validateStarted is written by the validation child process before it
+// installs the filter. Once the filter is installed the child runs under it
+// and cannot report anything, so the parent treats a child that got this far
+// without reporting an error as success, however it exited.
+const validateStarted = '+'
+
+// validateTimeout bounds the lifetime of the validation child process. A
+// filter that blocks exit_group and the signals used by the runtime to crash
+// the process may leave it running.
+const validateTimeout = 10 * time.Second
+
+// validateRequest is sent to the validation child process.
+type validateRequest struct {
+	Flag                       uint32               `json:"flag"`
+	NoNewPrivs                 bool                 `json:"no_new_privs"`
+	SkipNoNewPrivsIfPrivileged bool                 `json:"skip_no_new_privs_if_privileged"`
+	Instructions               []bpf.RawInstruction `json:"instructions"`
+}
+
+// validateResponse is returned by the validation child process.
+type validateResponse struct {
+	Errno   syscall.Errno `json:"errno"`
+	Message string        `json:"message"`
+}
+
+// ValidationError is returned by Validate when the kernel rejected the
+// filter.
+type ValidationError struct {
+	Message string        // Error reported by LoadFilter in the child.
+	Errno   syscall.Errno // Error returned by the kernel (0 if unknown).
+}
+
+func (e *ValidationError) Error() string {
+	return "kernel rejected the filter: " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	if e.Errno == 0 {
+		return nil
+	}
+	return e.Errno
+}
+
+// Validate compiles the filter and installs it in a short-lived child
+// process (a re-executed copy of the current binary) to check that the
+// kernel accepts it. This catches problems that the userspace checks miss
+// without confining the calling process. A *ValidationError containing the
+// kernel's error is returned if the filter was rejected.
+func Validate(filter Filter) error {
+	raw, err := compileFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	req, err := json.Marshal(validateRequest{
+		Flag:                       uint32(filter.Flag),
+		NoNewPrivs:                 filter.NoNewPrivs,
+		SkipNoNewPrivsIfPrivileged: filter.SkipNoNewPrivsIfPrivileged,
+		Instructions:               raw,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, selfExe)
+	cmd.Env = append(os.Environ(), shimEnv+"="+validateShim)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	out := stdout.Bytes()
+	if len(out) == 0 || out[0] != validateStarted {
+		if err == nil {
+			err = errors.New("no response")
+		}
+		return fmt.Errorf("validation process failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	out = bytes.TrimSpace(out[1:])
+	if len(out) == 0 {
+		// The kernel accepted the filter. The exit status is irrelevant
+		// because the filter may have killed the child.
+		return nil
+	}
+
+	var resp validateResponse
+	if err = json.Unmarshal(out, &resp); err != nil {
+		return fmt.Errorf("invalid response from validation process: %w", err)
+	}
+	if resp.Message != "" {
+		return &ValidationError{Message: resp.Message, Errno: resp.Errno}
+	}
+	return nil
+}
+
+// runValidateShim installs the filter received on stdin and reports an error
+// on stdout if the kernel rejected it.
+func runValidateShim() int {
+	var req validateRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	filter := Filter{
+		Flag:                       FilterFlag(req.Flag),
+		NoNewPrivs:                 req.NoNewPrivs,
+		SkipNoNewPrivsIfPrivileged: req.SkipNoNewPrivsIfPrivileged,
+	}
+	if _, err := os.Stdout.Write([]byte{validateStarted}); err != nil {
+		return 1
+	}
+	if _, err := installFilter(filter, req.Instructions); err != nil {
+		resp := validateResponse{Message: err.Error()}
+		errors.As(err, &resp.Errno)
+		if err = json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	// Exiting may be blocked by the filter, Validate ignores the exit status.
+	return 0
+}
@@ -0,0 +1,409 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+// maxVerifyArgVectors limits the number of argument combinations that are
+// tested for a single syscall with argument conditions. Syscalls with more
+// combinations are reported in VerifyError.Incomplete.
+const maxVerifyArgVectors = 4096
+
+// knownArches is the list of architectures whose audit arch values are used
+// by Verify to check that foreign architectures are handled.
+var knownArches = []*arch.Info{
+	arch.ARM, arch.AARCH64, arch.I386, arch.X32, arch.X86_64,
+	arch.PPC, arch.PPC64, arch.PPC64LE, arch.S390, arch.S390X,
+	arch.MIPS, arch.MIPSEL, arch.MIPS64, arch.MIPS64N32, arch.MIPSEL64, arch.MIPSEL64N32,
+}
+
+// VerifyMismatch describes an input for which the assembled filter returned a
+// different action than the policy intends.
+type VerifyMismatch struct {
+	Data SeccompData // Input given to the filter.
+	Want Action      // Action intended by the policy.
+	Got  Action      // Action returned by the filter.
+}
+
+func (m VerifyMismatch) String() string {
+	return fmt.Sprintf("nr=%d arch=0x%08x args=%#x: want %v (0x%08x), got %v (0x%08x)",
+		m.Data.NR, m.Data.Arch, m.Data.Args, m.Want, uint32(m.Want), m.Got, uint32(m.Got))
+}
+
+// VerifyError is returned by Verify when the assembled filter does not match
+// the policy, or when it could not be checked completely.
+type VerifyError struct {
+	Mismatches []VerifyMismatch
+
+	// Syscalls whose argument conditions have too many combinations of
+	// boundary values to test all of them. Only the arguments compared by
+	// the same rule are combined for them, so the filter may still differ
+	// from the policy if no mismatches are reported.
+	Incomplete []string
+}
+
+func (e *VerifyError) Error() string {
+	const maxListed = 10
+
+	if len(e.Mismatches) == 0 {
+		return "filter could not be verified for all argument combinations of " + strings.Join(e.Incomplete, ", ")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "filter does not match policy for %d inputs", len(e.Mismatches))
+	if len(e.Incomplete) > 0 {
+		fmt.Fprintf(&sb, " (not all argument combinations of %s were tested)", strings.Join(e.Incomplete, ", "))
+	}
+	for i, m := range e.Mismatches {
+		if i == maxListed {
+			fmt.Fprintf(&sb, "\n... and %d more", len(e.Mismatches)-maxListed)
+			break
+		}
+		sb.WriteString("\n")
+		sb.WriteString(m.String())
+	}
+	return sb.String()
+}
+
+// Verify assembles the policy and runs the filter in the Emulator against
+// every syscall number of the policy's architecture, boundary values, and
+// foreign architectures. It returns a *VerifyError if any decision made by
+// the filter differs from the intent of the policy, or if a syscall has too
+// many argument conditions to test every combination of their boundaries.
+func (p *Policy) Verify() error {
+	insts, err := p.Assemble()
+	if err != nil {
+		return err
+	}
+	return p.verify(insts)
+}
+
+// VerifyProgram is like Verify but checks the given program instead of the
+// one assembled from the policy, for example a filter that another library
+// compiled from it. It returns a *VerifyError if any decision made by the
+// program differs from the intent of the policy.
+func (p *Policy) VerifyProgram(insts []bpf.Instruction) error {
+	// Assemble validates the policy and initializes its arch.
+	if _, err := p.Assemble(); err != nil {
+		return err
+	}
+	return p.verify(insts)
+}
+
+func (p *Policy) verify(insts []bpf.Instruction) error {
+	emulator, err := NewEmulator(insts)
+	if err != nil {
+		return err
+	}
+
+	groups, err := p.compileGroups()
+	if err != nil {
+		return err
+	}
+
+	inputs, incomplete := p.verifyInputs(groups)
+	var mismatches []VerifyMismatch
+	for _, data := range inputs {
+		got, err := emulator.Run(data)
+		if err != nil {
+			return fmt.Errorf("failed to run filter for nr=%d: %w", data.NR, err)
+		}
+		if want := p.intent(groups, data); got != want {
+			mismatches = append(mismatches, VerifyMismatch{Data: data, Want: want, Got: got})
+		}
+	}
+
+	if len(mismatches) > 0 || len(incomplete) > 0 {
+		return &VerifyError{Mismatches: mismatches, Incomplete: incomplete}
+	}
+	return nil
+}
+
+// compiledGroup is a SyscallGroup with its names resolved to numbers.
+type compiledGroup struct {
+	syscalls []SyscallWithConditions
+	action   Action
+}
+
+// compileGroups resolves the syscalls of every group for the policy's arch.
+// Assemble must have been called before to initialize the arch.
+func (p *Policy) compileGroups() ([]compiledGroup, error) {
+	groups := make([]compiledGroup, 0, len(p.Syscalls)+1)
+	for _, group := range p.groups() {
+		if group.arch == nil {
+			group.arch = p.arch
+		}
+
+		syscalls, err := group.toSyscallsWithConditions()
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, compiledGroup{syscalls: syscalls, action: group.Action})
+	}
+	return groups, nil
+}
+
+// intent returns the action that the policy intends for the input.
+func (p *Policy) intent(groups []compiledGroup, data SeccompData) Action {
+	if data.Arch != uint32(p.arch.ID) {
+		return p.DefaultAction.returnValue()
+	}
+
+	nr := uint32(data.NR)
+	if p.arch.ID == arch.X86_64.ID && nr >= uint32(arch.X32.SeccompMask) {
+		return ActionErrno | Action(errnoENOSYS)
+	}
+
+	if i, _ := matchGroup(groups, nr, data.Args); i >= 0 {
+		return groups[i].action.returnValue()
+	}
+	return p.DefaultAction.returnValue()
+}
+
+// matchGroup returns the index of the first group that matches the syscall
+// and the conditions that matched, or -1 if no group matches.
+func matchGroup(groups []compiledGroup, nr uint32, args [6]uint64) (int, ArgumentConditions) {
+	for i, group := range groups {
+		if s := getSyscall(group.syscalls, nr); s != nil {
+			if conditions, ok := s.matches(args); ok {
+				return i, conditions
+			}
+		}
+	}
+	return -1, nil
+}
+
+// matches returns true and the matching conditions if the arguments satisfy
+// any of the conditions. A syscall without conditions always matches.
+func (s SyscallWithConditions) matches(args [6]uint64) (ArgumentConditions, bool) {
+	if len(s.Conditions) == 0 {
+		return nil, true
+	}
+	for _, conditions := range s.Conditions {
+		if conditions.matches(args) {
+			return conditions, true
+		}
+	}
+	return nil, false
+}
+
+// matches returns true if the arguments satisfy all conditions.
+func (a ArgumentConditions) matches(args [6]uint64) bool {
+	for _, c := range a {
+		if !c.matches(args[c.Argument]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches evaluates the condition against the argument value.
+func (c Condition) matches(arg uint64) bool {
+	switch c.Operation {
+	case Equal:
+		return arg == c.Value
+	case NotEqual:
+		return arg != c.Value
+	case GreaterThan:
+		return arg > c.Value
+	case GreaterOrEqual:
+		return arg >= c.Value
+	case LessThan:
+		return arg < c.Value
+	case LessOrEqual:
+		return arg <= c.Value
+	case BitsSet:
+		return arg&c.Value != 0
+	case BitsNotSet:
+		return arg&c.Value == 0
+	}
+	return false
+}
+
+// verifyInputs returns the list of inputs that Verify checks and the names of
+// the syscalls whose argument combinations are not all included.
+func (p *Policy) verifyInputs(groups []compiledGroup) ([]SeccompData, []string) {
+	boundaries := []uint32{
+		math.MaxInt32, math.MaxInt32 + 1, math.MaxUint32,
+		uint32(arch.X32.SeccompMask) - 1, uint32(arch.X32.SeccompMask),
+	}
+
+	// Every number of the native arch and the numbers used in the policy.
+	maxNR := 0
+	for nr := range p.arch.Syscalls() {
+		maxNR = max(maxNR, nr)
+	}
+	numbers := make(map[uint32]struct{}, maxNR+len(boundaries))
+	for nr := 0; nr <= maxNR+1; nr++ {
+		numbers[uint32(nr)] = struct{}{}
+	}
+	for _, nr := range boundaries {
+		numbers[nr] = struct{}{}
+	}
+	if p.arch.ID == arch.X86_64.ID {
+		for nr := range arch.X32.Syscalls() {
+			numbers[uint32(nr|arch.X32.SeccompMask)] = struct{}{}
+		}
+	}
+	conditional := map[uint32][]ArgumentConditions{}
+	for _, group := range groups {
+		for _, s := range group.syscalls {
+			numbers[s.Num] = struct{}{}
+			conditional[s.Num] = append(conditional[s.Num], s.Conditions...)
+		}
+	}
+
+	sorted := make([]uint32, 0, len(numbers))
+	for nr := range numbers {
+		sorted = append(sorted, nr)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var inputs []SeccompData
+	var incomplete []string
+	for _, nr := range sorted {
+		vectors, complete := argVectors(conditional[nr])
+		if !complete {
+			name := syscallName(SeccompData{NR: int32(nr), Arch: uint32(p.arch.ID)})
+			if name == "" {
+				name = strconv.FormatUint(uint64(nr), 10)
+			}
+			incomplete = append(incomplete, name)
+		}
+		for _, args := range vectors {
+			inputs = append(inputs, SeccompData{NR: int32(nr), Arch: uint32(p.arch.ID), Args: args})
+		}
+	}
+
+	// Foreign arches must never be matched by the syscall rules.
+	foreign := []uint32{0, math.MaxUint32}
+	for _, info := range knownArches {
+		if info.ID != p.arch.ID && info != arch.X32 {
+			foreign = append(foreign, uint32(info.ID))
+		}
+	}
+	for _, id := range foreign {
+		inputs = append(inputs, SeccompData{NR: 0, Arch: id}, SeccompData{NR: -1, Arch: id})
+		for _, group := range groups {
+			for _, s := range group.syscalls {
+				inputs = append(inputs, SeccompData{NR: int32(s.Num), Arch: id})
+			}
+		}
+	}
+	return inputs, incomplete
+}
+
+// argVectors returns argument combinations that exercise the boundaries of
+// the conditions. If there are too many combinations, only the arguments
+// compared by the same conditions are combined, with the others set to 0,
+// and each argument is also varied on its own. It returns false in that case.
+func argVectors(conditions []ArgumentConditions) ([][6]uint64, bool) {
+	if len(conditions) == 0 {
+		return [][6]uint64{{}}, true
+	}
+
+	var values [6][]uint64
+	for _, set := range conditions {
+		for _, c := range set {
+			values[c.Argument] = append(values[c.Argument], c.Value-1, c.Value, c.Value+1, ^c.Value)
+		}
+	}
+	all := [6]bool{true, true, true, true, true, true}
+	for i := range values {
+		if len(values[i]) == 0 {
+			// The filter does not load arguments that are not compared.
+			values[i] = []uint64{0}
+			continue
+		}
+		values[i] = dedupUint64(append(values[i], 0, math.MaxUint64))
+	}
+	if vectors, ok := argProduct(values, all); ok {
+		return vectors, true
+	}
+
+	var vectors [][6]uint64
+	for _, set := range conditions {
+		var compared [6]bool
+		for _, c := range set {
+			compared[c.Argument] = true
+		}
+		if product, ok := argProduct(values, compared); ok {
+			vectors = append(vectors, product...)
+		}
+	}
+	for i, vals := range values {
+		for _, v := range vals {
+			var args [6]uint64
+			args[i] = v
+			vectors = append(vectors, args)
+		}
+	}
+	return vectors, false
+}
+
+// argProduct returns every combination of the values of the selected
+// arguments with the other arguments set to 0. It returns false if there are
+// more than maxVerifyArgVectors combinations.
+func argProduct(values [6][]uint64, selected [6]bool) ([][6]uint64, bool) {
+	total := 1
+	for i, vals := range values {
+		if selected[i] {
+			if total *= len(vals); total > maxVerifyArgVectors {
+				return nil, false
+			}
+		}
+	}
+
+	vectors := make([][6]uint64, 1, total)
+	for i, vals := range values {
+		if !selected[i] {
+			continue
+		}
+		next := make([][6]uint64, 0, total)
+		for _, args := range vectors {
+			for _, v := range vals {
+				args[i] = v
+				next = append(next, args)
+			}
+		}
+		vectors = next
+	}
+	return vectors, true
+}
+
+func dedupUint64(in []uint64) []uint64 {
+	seen := make(map[uint64]struct{}, len(in))
+	out := in[:0]
+	for _, v := range in {
+		if _, found := seen[v]; !found {
+			seen[v] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/elastic/go-seccomp-bpf/arch"
+)
+
+func TestPolicyVerify(t *testing.T) {
+	policies := map[string]*Policy{
+		"blacklist": {
+			arch:          arch.X86_64,
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{
+				{Names: []string{"execve", "fork"}, Action: ActionKillThread},
+				{Names: []string{"bind", "listen"}, Action: ActionErrno},
+			},
+		},
+		"whitelist": {
+			arch:          arch.ARM,
+			DefaultAction: ActionKillProcess,
+			Syscalls: []SyscallGroup{
+				{Names: []string{"read", "write", "exit"}, Action: ActionAllow},
+			},
+		},
+		"conditions": {
+			arch:          arch.X86_64,
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{
+				{
+					Action: ActionErrno,
+					NamesWithCondtions: []NameWithConditions{
+						{
+							Name: "clone",
+							Conditions: []Condition{
+								{Argument: 0, Operation: BitsNotSet, Value: 0x10000000},
+							},
+						},
+						{
+							Name: "write",
+							Conditions: []Condition{
+								{Argument: 0, Operation: GreaterThan, Value: 2},
+								{Argument: 2, Operation: LessOrEqual, Value: testArgument1},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			if err := policy.Verify(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestPolicyVerifyMismatch(t *testing.T) {
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{Names: []string{"execve"}, Action: ActionKillThread},
+		},
+	}
+
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a codegen bug that swaps the group action.
+	for i, inst := range insts {
+		if ret, ok := inst.(bpf.RetConstant); ok && Action(ret.Val) == ActionKillThread {
+			insts[i] = bpf.RetConstant{Val: uint32(ActionAllow)}
+		}
+	}
+
+	err = policy.verify(insts)
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("expected VerifyError, got %v", err)
+	}
+	if len(verifyErr.Mismatches) != 1 || verifyErr.Mismatches[0].Data.NR != 59 /* execve */ {
+		t.Errorf("unexpected mismatches: %v", verifyErr)
+	}
+}
+
+func TestPolicyVerifyProgram(t *testing.T) {
+	policy := &Policy{
+		arch:          arch.X86_64,
+		DefaultAction: ActionAllow,
+		Syscalls: []SyscallGroup{
+			{Names: []string{"execve"}, Action: ActionErrno},
+		},
+	}
+
+	insts, err := policy.Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = policy.VerifyProgram(insts); err != nil {
+		t.Fatal(err)
+	}
+
+	err = policy.VerifyProgram([]bpf.Instruction{bpf.RetConstant{Val: uint32(ActionAllow)}})
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("expected VerifyError, got %v", err)
+	}
+	found := false
+	for _, m := range verifyErr.Mismatches {
+		if m.Data.NR == 59 /* execve */ && m.Want == ActionErrno|Action(errnoEPERM) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected mismatch for execve: %v", verifyErr)
+	}
+}
+
+func TestPolicyVerifyManyArguments(t *testing.T) {
+	// rule compares the arguments starting at first with the values.
+	rule := func(first int, values ...uint64) NameWithConditions {
+		var conditions []Condition
+		for i, v := range values {
+			conditions = append(conditions, Condition{Argument: uint32(first + i), Operation: Equal, Value: v})
+		}
+		return NameWithConditions{Name: "socket", Conditions: conditions}
+	}
+	policy := func(rules ...NameWithConditions) *Policy {
+		return &Policy{
+			arch:          arch.X86_64,
+			DefaultAction: ActionAllow,
+			Syscalls: []SyscallGroup{{
+				Action:             ActionErrno,
+				NamesWithCondtions: rules,
+			}},
+		}
+	}
+
+	// All combinations of four arguments are tested. The arguments that
+	// are not compared do not add combinations.
+	if err := policy(rule(0, 1, 2, 3, 4)).Verify(); err != nil {
+		t.Fatal(err)
+	}
+	other, err := policy(rule(0, 1, 2, 3, 5)).Assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var verifyErr *VerifyError
+	if err = policy(rule(0, 1, 2, 3, 4)).VerifyProgram(other); !errors.As(err, &verifyErr) || len(verifyErr.Incomplete) != 0 {
+		t.Fatalf("expected mismatches, got %v", err)
+	}
+
+	// All six arguments have too many combinations, but the arguments of
+	// a rule are still tested together.
+	err = policy(rule(0, 1, 2, 3, 4), rule(4, 5, 6)).Verify()
+	if !errors.As(err, &verifyErr) || len(verifyErr.Mismatches) != 0 ||
+		len(verifyErr.Incomplete) != 1 || verifyErr.Incomplete[0] != "socket" {
+		t.Fatalf("expected socket to be incomplete, got %v", err)
+	}
+	if other, err = policy(rule(0, 1, 2, 3, 5), rule(4, 5, 6)).Assemble(); err != nil {
+		t.Fatal(err)
+	}
+	err = policy(rule(0, 1, 2, 3, 4), rule(4, 5, 6)).VerifyProgram(other)
+	if !errors.As(err, &verifyErr) || len(verifyErr.Mismatches) == 0 {
+		t.Fatalf("expected mismatches, got %v", err)
+	}
+	for _, m := range verifyErr.Mismatches {
+		if m.Data.Args != [6]uint64{1, 2, 3, 4} && m.Data.Args != [6]uint64{1, 2, 3, 5} {
+			t.Errorf("unexpected mismatch %v", m)
+		}
+	}
+}
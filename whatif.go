@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package seccomp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WhatIfDenial is a syscall that the filter would have rejected while a
+// program ran under WhatIf. Calls are grouped by syscall and action.
+type WhatIfDenial struct {
+	Syscall string      // Name of the syscall, empty if it is unknown.
+	Action  Action      // Action returned by the filter.
+	Data    SeccompData // Payload of the first rejected call.
+	Pid     int         // Thread that made the first rejected call.
+	Count   uint64      // Number of rejected calls.
+}
+
+func (d WhatIfDenial) String() string {
+	name := d.Syscall
+	if name == "" {
+		name = "nr " + strconv.Itoa(int(d.Data.NR))
+	}
+	args := make([]string, len(d.Data.Args))
+	for i, a := range d.Data.Args {
+		args[i] = "0x" + strconv.FormatUint(a, 16)
+	}
+	return fmt.Sprintf("%s %s(%s): %v (pid %d, %d calls)", corpusArchName(d.Data.Arch), name,
+		strings.Join(args, ", "), d.Action, d.Pid, d.Count)
+}
+
+// WhatIfReport is the result of running a program under WhatIf.
+type WhatIfReport struct {
+	// Syscalls is the number of syscalls evaluated with the filter.
+	Syscalls uint64
+
+	// Denials contains the rejected syscalls in the order of their first
+	// call.
+	Denials []WhatIfDenial
+
+	index map[whatIfKey]int
+}
+
+type whatIfKey struct {
+	arch uint32
+	nr   int32
+	act  Action
+}
+
+// record adds the decision of the filter for a call.
+func (r *WhatIfReport) record(pid int, d SeccompData, a Action) {
+	r.Syscalls++
+	if a.permits() {
+		return
+	}
+
+	key := whatIfKey{d.Arch, d.NR, a}
+	if i, found := r.index[key]; found {
+		r.Denials[i].Count++
+		return
+	}
+	if r.index == nil {
+		r.index = map[whatIfKey]int{}
+	}
+	r.index[key] = len(r.Denials)
+	r.Denials = append(r.Denials, WhatIfDenial{
+		Syscall: syscallName(d),
+		Action:  a,
+		Data:    d,
+		Pid:     pid,
+		Count:   1,
+	})
+}
@@ -0,0 +1,213 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// ptraceSyscallInfo is struct ptrace_syscall_info with the seccomp member of
+// the union, which is the largest.
+// https://github.com/torvalds/linux/blob/v5.3/include/uapi/linux/ptrace.h#L73-L100
+type ptraceSyscallInfo struct {
+	Op      uint8
+	_       [3]uint8
+	Arch    uint32
+	IP      uint64
+	SP      uint64
+	NR      uint64
+	Args    [6]uint64
+	RetData uint32
+	_       uint32
+}
+
+// ptraceSyscallInfoEntry is the op of ptraceSyscallInfo at syscall entry.
+const ptraceSyscallInfoEntry = 1
+
+// si_code values of SIGCHLD for a child that terminated.
+const (
+	cldExited = 1
+	cldKilled = 2
+	cldDumped = 3
+)
+
+// siginfoPidOffset is the offset of si_pid in siginfo_t. The union that
+// contains it is aligned to the size of a pointer.
+const siginfoPidOffset = (12 + unsafe.Sizeof(uintptr(0)) - 1) &^ (unsafe.Sizeof(uintptr(0)) - 1)
+
+// WhatIf starts the command under ptrace and evaluates every syscall made
+// by the program, its threads, and its children with the filter in the
+// Emulator, without installing it. The syscalls are not affected: the
+// report lists the calls that the filter would have rejected, so the impact
+// of a policy can be assessed before it is deployed. A program confined by
+// the filter may not get to later calls after the first rejected one. The
+// execve that starts the program is not evaluated.
+//
+// WhatIf returns when the program exits, with the error of cmd.Wait. Any
+// children that are still running are detached. WhatIf waits for all child
+// processes of the calling process while the program runs, so other
+// children must not be started and waited for concurrently. It requires
+// Linux 5.3 and permission to trace the program.
+func WhatIf(cmd *exec.Cmd, insts []bpf.Instruction) (*WhatIfReport, error) {
+	emulator, err := NewEmulator(insts)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Ptrace = true
+
+	report := &WhatIfReport{}
+	done := make(chan error, 1)
+	go func() {
+		// All ptrace requests must be made by the thread that started the
+		// program. The thread is not unlocked so that it exits with the
+		// goroutine, which detaches the remaining tracees.
+		runtime.LockOSThread()
+		done <- traceWhatIf(cmd, emulator, report)
+	}()
+	if err := <-done; err != nil {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}
+		return nil, err
+	}
+	return report, cmd.Wait()
+}
+
+// traceWhatIf starts the command and records its syscalls until the program
+// exits. The exit of the program is not consumed so that cmd.Wait can reap
+// it.
+func traceWhatIf(cmd *exec.Cmd, emulator *Emulator, report *WhatIfReport) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	pid := cmd.Process.Pid
+
+	// The program stops with SIGTRAP after execve.
+	var status unix.WaitStatus
+	if _, err := unix.Wait4(pid, &status, unix.WALL, nil); err != nil {
+		return fmt.Errorf("failed waiting for process %d to start: %w", pid, err)
+	}
+	if !status.Stopped() {
+		return fmt.Errorf("process %d did not stop after execve", pid)
+	}
+	opts := unix.PTRACE_O_TRACESYSGOOD | unix.PTRACE_O_TRACEFORK | unix.PTRACE_O_TRACEVFORK |
+		unix.PTRACE_O_TRACECLONE | unix.PTRACE_O_TRACEEXEC
+	if err := unix.PtraceSetOptions(pid, opts); err != nil {
+		return fmt.Errorf("failed to set ptrace options of process %d: %w", pid, err)
+	}
+	if err := unix.PtraceSyscall(pid, 0); err != nil {
+		return fmt.Errorf("failed to resume process %d: %w", pid, err)
+	}
+
+	started := map[int]bool{pid: true}
+	for {
+		var info unix.Siginfo
+		err := unix.Waitid(unix.P_ALL, 0, &info, unix.WEXITED|unix.WSTOPPED|unix.WALL|unix.WNOWAIT, nil)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed waiting for traced processes: %w", err)
+		}
+		tid := int(*(*int32)(unsafe.Add(unsafe.Pointer(&info), siginfoPidOffset)))
+		if tid == pid && (info.Code == cldExited || info.Code == cldKilled || info.Code == cldDumped) {
+			return nil
+		}
+		if _, err = unix.Wait4(tid, &status, unix.WALL, nil); err != nil {
+			return fmt.Errorf("failed waiting for thread %d: %w", tid, err)
+		}
+		if !status.Stopped() {
+			delete(started, tid)
+			continue
+		}
+
+		var sig int
+		switch stop := status.StopSignal(); {
+		case stop == unix.SIGTRAP|0x80:
+			if err = whatIfSyscall(tid, emulator, report); err != nil {
+				return err
+			}
+		case status.TrapCause() > 0:
+			// Fork, clone, and exec events.
+		case stop == unix.SIGSTOP && !started[tid]:
+			// New tracees start with SIGSTOP.
+		case isSignalDeliveryStop(tid):
+			sig = int(stop)
+		}
+		started[tid] = true
+
+		if err = unix.PtraceSyscall(tid, sig); err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("failed to resume thread %d: %w", tid, err)
+		}
+	}
+}
+
+// whatIfSyscall records the decision of the filter for the syscall of a
+// thread in a syscall stop.
+func whatIfSyscall(tid int, emulator *Emulator, report *WhatIfReport) error {
+	var info ptraceSyscallInfo
+	_, _, e := unix.Syscall6(unix.SYS_PTRACE, unix.PTRACE_GET_SYSCALL_INFO,
+		uintptr(tid), unsafe.Sizeof(info), uintptr(unsafe.Pointer(&info)), 0, 0)
+	if e == unix.ESRCH {
+		// The thread was killed.
+		return nil
+	}
+	if e != 0 {
+		return fmt.Errorf("failed to get the syscall of thread %d (requires Linux 5.3): %w", tid, e)
+	}
+	if info.Op != ptraceSyscallInfoEntry {
+		return nil
+	}
+
+	data := SeccompData{
+		NR:                 int32(info.NR),
+		Arch:               info.Arch,
+		InstructionPointer: info.IP,
+		Args:               info.Args,
+	}
+	action, err := emulator.Run(data)
+	if err != nil {
+		return fmt.Errorf("failed to run filter for nr=%d: %w", data.NR, err)
+	}
+	report.record(tid, data, action)
+	return nil
+}
+
+// isSignalDeliveryStop returns false if the thread is in a group-stop, for
+// which PTRACE_GETSIGINFO fails.
+func isSignalDeliveryStop(tid int) bool {
+	var info unix.Siginfo
+	_, _, e := unix.Syscall6(unix.SYS_PTRACE, unix.PTRACE_GETSIGINFO,
+		uintptr(tid), 0, uintptr(unsafe.Pointer(&info)), 0, 0)
+	return e == 0
+}